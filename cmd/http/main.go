@@ -2,28 +2,55 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 	"time"
 
 	"go.uber.org/zap"
 
 	"testtask/config"
+	addressbookrepo "testtask/internal/adapters/addressbook"
+	auditrepo "testtask/internal/adapters/audit"
 	"testtask/internal/adapters/cache"
 	coingeckoadapter "testtask/internal/adapters/coingecko"
+	ensadapter "testtask/internal/adapters/ens"
 	etherscanadapter "testtask/internal/adapters/etherscan"
+	exchangeadapter "testtask/internal/adapters/exchange"
 	httpserver "testtask/internal/adapters/http/server"
+	incomerepo "testtask/internal/adapters/income"
 	loggeradapter "testtask/internal/adapters/logger"
 	portfoliorepo "testtask/internal/adapters/portfolio"
+	priceadapter "testtask/internal/adapters/price"
+	sqliteadapter "testtask/internal/adapters/sqlite"
+	taxrepo "testtask/internal/adapters/tax"
+	tokenrepo "testtask/internal/adapters/token"
+	transactionrepo "testtask/internal/adapters/transaction"
+	userrepo "testtask/internal/adapters/user"
+	addressbookservice "testtask/internal/application/addressbook"
+	auditservice "testtask/internal/application/audit"
+	authservice "testtask/internal/application/auth"
+	ensservice "testtask/internal/application/ens"
+	exchangeservice "testtask/internal/application/exchange"
+	incomeservice "testtask/internal/application/income"
 	portfolioservice "testtask/internal/application/portfolio"
 	priceservice "testtask/internal/application/price"
 	"testtask/internal/application/ratelimiter"
+	"testtask/internal/application/scheduler"
+	sharingservice "testtask/internal/application/sharing"
+	taxservice "testtask/internal/application/tax"
+	tokenservice "testtask/internal/application/token"
 	transactionservice "testtask/internal/application/transaction"
 	"testtask/internal/domain"
+	"testtask/internal/domain/ens"
+	"testtask/internal/domain/exchange"
 	domainPrice "testtask/internal/domain/price"
 	"testtask/internal/domain/token"
+	"testtask/internal/domain/user"
 )
 
 func main() {
@@ -70,6 +97,23 @@ func main() {
 		}
 	}()
 
+	// Initialize token registry and custom tokens database (SQLite)
+	registryDB, err := sqliteadapter.Open(cfg.Database.Path)
+	if err != nil {
+		logger.Fatal("Failed to open token registry database", zap.Error(err))
+	}
+	defer func() {
+		if err := registryDB.Close(); err != nil {
+			logger.Error("Failed to close token registry database", zap.Error(err))
+		}
+	}()
+
+	customTokenRepo := tokenrepo.NewSQLiteCustomRepository(registryDB)
+	priceOverrideRepo := priceadapter.NewSQLiteOverrideRepository(registryDB)
+
+	// Append-only audit log of state-changing operations
+	auditService := auditservice.NewService(auditrepo.NewSQLiteRepository(registryDB), logger)
+
 	// The portfolio repository also implements holding repository interface
 	holdingRepo := portfolioRepo
 
@@ -92,7 +136,7 @@ func main() {
 	// Initialize CoinGecko price provider
 	// For now, we'll use an empty symbolToID map - in production this should be loaded from a file or API
 	symbolToID := make(map[string]string)
-	coingeckoPriceProvider := coingeckoadapter.NewPriceRepository(coingeckoClient, symbolToID)
+	coingeckoPriceProvider := coingeckoadapter.NewPriceRepository(coingeckoClient, symbolToID, logger)
 
 	// Initialize mock price provider as fallback
 	mockPriceProvider := coingeckoadapter.NewMockProvider()
@@ -123,7 +167,10 @@ func main() {
 		priceCacheAdapter,
 		coingeckoPriceProvider,
 		fallbackProvider,
+		tokenrepo.NewManualPriceProvider(customTokenRepo),
+		priceOverrideRepo,
 		priceRateLimiter,
+		auditService,
 		logger,
 	)
 
@@ -148,26 +195,151 @@ func main() {
 		logger.Warn("Etherscan API key not set, transaction features may be limited")
 	}
 
-	// Initialize transaction service
-	transactionService := transactionservice.NewService(transactionRepo, logger)
+	// Counterparty labels from each user's address book and the well-known addresses
+	addressRegistry, err := addressbookrepo.LoadRegistry(cfg.App.AddressesPath)
+	if err != nil {
+		logger.Warn("Failed to load address registry, counterparties are only labeled from address books", zap.String("path", cfg.App.AddressesPath), zap.Error(err))
+	}
+	addressBookService := addressbookservice.NewService(addressRegistry, addressbookrepo.NewSQLiteRepository(registryDB), auditService, logger)
+
+	// ENS names in place of addresses and as counterparty names, resolved over JSON-RPC
+	var ensResolver ens.Resolver
+	if cfg.ENS.RPCURL != "" {
+		ensClient := ensadapter.NewClient(&http.Client{Timeout: cfg.ENS.RequestTimeout}, cfg.ENS.RPCURL)
+		ensResolver = ensadapter.NewResolver(ensClient, cfg.ENS.RegistryAddr)
+	} else {
+		logger.Warn("ENS_RPC_URL not set, ENS names are not resolved")
+	}
+	ensService := ensservice.NewService(ensResolver, cache.NewCache[string, ens.Result](1000), cfg.ENS.CacheTTL, logger)
+
+	// Initialize transaction service; annotations and manual transactions live in the registry
+	annotationRepo := transactionrepo.NewSQLiteRepository(registryDB)
+	transactionService := transactionservice.NewService(transactionRepo, annotationRepo, annotationRepo, portfolioRepo, addressBookService, auditService, logger)
 
-	// Initialize token repository (mock - loads from static file)
-	tokenRepo, err := initializeTokenRepository(cfg, logger)
+	// Token registry, seeded from the static token list
+	tokenRepo := tokenrepo.NewSQLiteRepository(registryDB)
+
+	tokenLogos, err := coingeckoadapter.LoadTokenLogos(cfg.App.ChainsPath, 1)
 	if err != nil {
-		logger.Warn("Failed to initialize token repository, continuing without it", zap.Error(err))
+		logger.Warn("Failed to load token logos, continuing without them", zap.String("path", cfg.App.ChainsPath), zap.Error(err))
+	}
+
+	var tokenList token.ListSource
+	if cfg.App.TokensPath != "" {
+		tokenList = coingeckoadapter.NewTokenFile(cfg.App.TokensPath)
+	} else {
+		logger.Warn("Tokens path not configured, token registry will not be seeded")
+	}
+
+	tokenMetadataProvider := coingeckoadapter.NewMetadataRepository(coingeckoClient)
+	tokenService := tokenservice.NewService(
+		tokenRepo,
+		customTokenRepo,
+		tokenMetadataProvider,
+		tokenList,
+		tokenLogos,
+		cfg.Token.RefreshBatch,
+		cfg.Token.RefreshRetry,
+		auditService,
+		logger,
+	)
+	if _, err := tokenService.ReloadTokens(context.Background(), token.ReloadTriggerStartup); err != nil {
+		logger.Warn("Failed to load token list, continuing with stored tokens", zap.Error(err))
+		if err := tokenService.RebuildIndex(context.Background()); err != nil {
+			logger.Warn("Failed to build token search index", zap.Error(err))
+		}
+	}
+
+	// Background jobs
+	jobScheduler := scheduler.NewScheduler(logger)
+	jobScheduler.Add("token-metadata-refresh", cfg.Token.RefreshInterval, tokenService.RefreshMetadata)
+	if cfg.Token.ReloadPoll > 0 {
+		jobScheduler.Add("token-list-watch", cfg.Token.ReloadPoll, tokenService.WatchTokenList)
+	}
+
+	stopReloadSignal := reloadTokensOnSignal(tokenService, logger)
+	defer stopReloadSignal()
+
+	// Native assets and equivalent tokens per chain
+	aliases, err := tokenrepo.LoadAliasRegistry(cfg.App.AliasesPath)
+	if err != nil {
+		logger.Warn("Failed to load alias registry, native balances will not be priced", zap.String("path", cfg.App.AliasesPath), zap.Error(err))
+	}
+
+	// Users and API authentication
+	userRepo := userrepo.NewSQLiteRepository(registryDB)
+	authService := authservice.NewService(userRepo, cfg.Auth.JWTSecret, cfg.Auth.TokenTTL, cfg.Auth.AdminIDs, auditService, logger)
+	sharingService := sharingservice.NewService(portfoliorepo.NewSQLiteAccessRepository(registryDB), userRepo, auditService, logger)
+	if cfg.Auth.JWTSecret == "" {
+		logger.Warn("AUTH_JWT_SECRET not set, access tokens are disabled and only API keys are accepted")
 	}
 
-	// Create token service adapter that implements TokensService interface
-	tokenService := &TokenServiceAdapter{repo: tokenRepo}
+	// Exchange statement imports and connected exchange accounts count towards portfolio valuation
+	var credentialCipher exchange.CredentialCipher
+	if cfg.Exchange.CredentialsKey == "" {
+		logger.Warn("EXCHANGE_CREDENTIALS_KEY not set, exchange API connections are disabled")
+	} else if key, err := base64.StdEncoding.DecodeString(cfg.Exchange.CredentialsKey); err != nil {
+		logger.Fatal("Invalid EXCHANGE_CREDENTIALS_KEY", zap.Error(err))
+	} else if credentialCipher, err = exchangeadapter.NewAESGCMCipher(key); err != nil {
+		logger.Fatal("Invalid EXCHANGE_CREDENTIALS_KEY", zap.Error(err))
+	}
+	connectors := map[exchange.Exchange]exchange.Connector{
+		exchange.Binance: exchangeadapter.NewBinanceConnector(nil, cfg.Exchange.BinanceBaseURL),
+	}
+	exchangeService := exchangeservice.NewService(
+		exchangeadapter.NewSQLiteRepository(registryDB),
+		exchangeadapter.NewSQLiteConnectionRepository(registryDB),
+		portfolioRepo,
+		exchangeadapter.NewParser,
+		connectors,
+		credentialCipher,
+		tokenService,
+		aliases,
+		auditService,
+		logger,
+	)
+	if credentialCipher != nil {
+		jobScheduler.Add("exchange-sync", cfg.Exchange.SyncInterval, exchangeService.SyncAll)
+	}
+
+	// Income detection by known distributors and rebasing token growth
+	incomeRules, err := incomerepo.LoadRuleSet(cfg.App.IncomeRulesPath)
+	if err != nil {
+		logger.Warn("Failed to load income rules, income is only classified by hand", zap.String("path", cfg.App.IncomeRulesPath), zap.Error(err))
+	}
+	incomeService := incomeservice.NewService(incomeRules, incomerepo.NewSQLiteRepository(registryDB), portfolioRepo, transactionRepo, auditService, logger)
+	if cfg.Transaction.RebaseInterval > 0 {
+		jobScheduler.Add("income-rebase-observe", cfg.Transaction.RebaseInterval, incomeService.ObserveAll)
+	}
+	// Jobs work across all users, so they run unscoped
+	jobScheduler.Start(user.AsSystem(context.Background()))
+	defer jobScheduler.Stop()
 
 	// Initialize portfolio service
-	portfolioService := portfolioservice.NewService(portfolioRepo, holdingRepo, transactionRepo, tokenRepo, priceService, logger)
+	portfolioService := portfolioservice.NewService(portfolioRepo, holdingRepo, holdingRepo, portfolioRepo, transactionRepo, exchangeService, portfoliorepo.NewSQLiteManualRepository(registryDB), tokenRepo, tokenService, priceService, aliases, auditService, logger)
+
+	// Capital gains reports under the rules of each supported jurisdiction
+	taxRules, err := taxrepo.LoadRuleSet(cfg.App.TaxRulesPath)
+	if err != nil {
+		logger.Warn("Failed to load tax rules, tax reports are unavailable", zap.String("path", cfg.App.TaxRulesPath), zap.Error(err))
+	}
+	taxService := taxservice.NewService(taxRules, portfolioRepo, transactionService, exchangeService, holdingRepo, incomeService, priceService, aliases, logger)
+
 	// Initialize HTTP handler adapter
 	handlerAdapter := httpserver.NewHandlerAdapter(
 		transactionService,
 		portfolioService,
 		priceService,
 		tokenService,
+		tokenService,
+		authService,
+		sharingService,
+		auditService,
+		exchangeService,
+		taxService,
+		incomeService,
+		addressBookService,
+		ensService,
 		logger,
 	)
 
@@ -232,44 +404,29 @@ func initializeDatabase(cfg *config.Config, logger *loggeradapter.Logger) error
 	return nil
 }
 
-// initializeTokenRepository initializes the token repository from file
-func initializeTokenRepository(cfg *config.Config, logger *loggeradapter.Logger) (*coingeckoadapter.MockTokenRepository, error) {
-	if cfg.App.TokensPath == "" {
-		logger.Warn("Tokens path not configured, token repository will be empty")
-		return nil, nil
-	}
-
-	// Check if tokens file exists
-	if _, err := os.Stat(cfg.App.TokensPath); os.IsNotExist(err) {
-		logger.Warn("Tokens file not found", zap.String("path", cfg.App.TokensPath))
-		return nil, nil
-	}
-
-	tokenRepo, err := coingeckoadapter.NewMockTokenRepository(cfg.App.TokensPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load token repository: %w", err)
-	}
-
-	logger.Info("Token repository initialized", zap.String("path", cfg.App.TokensPath))
-	return tokenRepo, nil
-}
-
-// TokenServiceAdapter adapts token.Repository to TokensService interface
-type TokenServiceAdapter struct {
-	repo *coingeckoadapter.MockTokenRepository
-}
-
-func (t *TokenServiceAdapter) GetTokenByAddress(ctx context.Context, address string) (*token.Token, bool) {
-	if t.repo == nil {
-		return nil, false
-	}
+// reloadTokensOnSignal reloads the token registry on SIGHUP until the returned stop function is called
+func reloadTokensOnSignal(tokenService *tokenservice.Service, logger *loggeradapter.Logger) func() {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGHUP)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-sigChan:
+				logger.Info("Received SIGHUP, reloading token registry")
+				// Errors are logged and kept as the last reload result
+				_, _ = tokenService.ReloadTokens(context.Background(), token.ReloadTriggerSignal)
+			case <-done:
+				return
+			}
+		}
+	}()
 
-	tok, err := t.repo.GetByAddress(ctx, address)
-	if err != nil {
-		return nil, false
+	return func() {
+		signal.Stop(sigChan)
+		close(done)
 	}
-
-	return tok, true
 }
 
 // getEnv gets an environment variable or returns a default value
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"testtask/internal/domain/chain"
+)
+
+// CoinGeckoToken represents a coin from the /coins/list?include_platform=true endpoint
+type CoinGeckoToken struct {
+	ID        string            `json:"id"`
+	Symbol    string            `json:"symbol"`
+	Name      string            `json:"name"`
+	Platforms map[string]string `json:"platforms"`
+}
+
+// AddressCollision records a contract address claimed by more than one CoinGecko coin
+type AddressCollision struct {
+	ChainID  string
+	Address  string
+	CoinIDs  []string
+	Selected string
+}
+
+// SymbolCollision records a symbol shared by several contracts on the same chain
+type SymbolCollision struct {
+	ChainID   string
+	Symbol    string
+	Addresses []string
+}
+
+// MergeResult is the collected registry together with data quality findings
+type MergeResult struct {
+	Tokens             []Token
+	Unmatched          []Token // tokens without a CoinGecko coin on their platform
+	AddressCollisions  []AddressCollision
+	SymbolCollisions   []SymbolCollision
+	SkippedDuplicates  int
+	SkippedUnsupported int
+}
+
+// platformKey identifies a contract on a CoinGecko asset platform
+func platformKey(platform, address string) string {
+	return platform + ":" + strings.ToLower(address)
+}
+
+// indexCoinsByContract maps platform contracts to the CoinGecko coins listing them
+func indexCoinsByContract(coins []CoinGeckoToken) map[string][]CoinGeckoToken {
+	index := make(map[string][]CoinGeckoToken)
+	for _, coin := range coins {
+		for platform, address := range coin.Platforms {
+			if platform == "" || address == "" {
+				continue
+			}
+			key := platformKey(platform, address)
+			index[key] = append(index[key], coin)
+		}
+	}
+	return index
+}
+
+// mergeTokens joins token list entries with CoinGecko coins on (platform, contract address).
+// Symbols are never used to pick a coin ID, only to break ties between coins claiming the same contract.
+func mergeTokens(chainTokens map[*chain.Chain][]EthereumToken, coins []CoinGeckoToken) MergeResult {
+	var result MergeResult
+	byContract := indexCoinsByContract(coins)
+
+	for _, c := range chain.Supported {
+		entries, ok := chainTokens[c]
+		if !ok {
+			continue
+		}
+
+		seen := make(map[string]bool)
+		for _, entry := range entries {
+			if fmt.Sprintf("%d", entry.ChainID) != c.ChianID {
+				result.SkippedUnsupported++
+				continue
+			}
+
+			address := strings.ToLower(entry.Address)
+			if seen[address] {
+				result.SkippedDuplicates++
+				continue
+			}
+			seen[address] = true
+
+			t := Token{
+				Address: address,
+				Name:    entry.Name,
+				Symbol:  entry.Symbol,
+				Decimal: entry.Decimals,
+				ChainID: c.ChianID,
+			}
+
+			candidates := byContract[platformKey(c.Platform, address)]
+			switch len(candidates) {
+			case 0:
+				result.Unmatched = append(result.Unmatched, t)
+			case 1:
+				t.ID = candidates[0].ID
+			default:
+				t.ID = selectCoin(candidates, entry.Symbol)
+				result.AddressCollisions = append(result.AddressCollisions, AddressCollision{
+					ChainID:  c.ChianID,
+					Address:  address,
+					CoinIDs:  coinIDs(candidates),
+					Selected: t.ID,
+				})
+			}
+
+			result.Tokens = append(result.Tokens, t)
+		}
+	}
+
+	result.SymbolCollisions = findSymbolCollisions(result.Tokens)
+	return result
+}
+
+// selectCoin picks the coin whose symbol matches the token list entry, falling back to the first ID alphabetically
+func selectCoin(candidates []CoinGeckoToken, symbol string) string {
+	ids := coinIDs(candidates)
+	for _, id := range ids {
+		for _, coin := range candidates {
+			if coin.ID == id && strings.EqualFold(coin.Symbol, symbol) {
+				return id
+			}
+		}
+	}
+	return ids[0]
+}
+
+func coinIDs(coins []CoinGeckoToken) []string {
+	ids := make([]string, 0, len(coins))
+	for _, coin := range coins {
+		ids = append(ids, coin.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func findSymbolCollisions(tokens []Token) []SymbolCollision {
+	groups := make(map[string][]string)
+	for _, t := range tokens {
+		key := t.ChainID + ":" + strings.ToUpper(t.Symbol)
+		groups[key] = append(groups[key], t.Address)
+	}
+
+	var collisions []SymbolCollision
+	for key, addresses := range groups {
+		if len(addresses) < 2 {
+			continue
+		}
+		chainID, symbol, _ := strings.Cut(key, ":")
+		sort.Strings(addresses)
+		collisions = append(collisions, SymbolCollision{ChainID: chainID, Symbol: symbol, Addresses: addresses})
+	}
+
+	sort.Slice(collisions, func(i, j int) bool {
+		if collisions[i].ChainID != collisions[j].ChainID {
+			return collisions[i].ChainID < collisions[j].ChainID
+		}
+		return collisions[i].Symbol < collisions[j].Symbol
+	})
+	return collisions
+}
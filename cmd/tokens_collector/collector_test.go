@@ -0,0 +1,89 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"testtask/internal/domain/chain"
+)
+
+const wbtcAddress = "0x2260fac5e5542a773aa44fbc8cfe7ed6a2a8e0e3"
+
+func TestMergeTokens(t *testing.T) {
+	ethereum := chain.Supported[0]
+
+	coins := []CoinGeckoToken{
+		// Same symbol on another platform must not be picked for the Ethereum contract
+		{ID: "wrapped-bitcoin-pulsechain", Symbol: "wbtc", Platforms: map[string]string{"pulsechain": wbtcAddress}},
+		{ID: "wrapped-bitcoin", Symbol: "wbtc", Platforms: map[string]string{"ethereum": strings.ToUpper(wbtcAddress)}},
+		{ID: "usd-coin", Symbol: "usdc", Platforms: map[string]string{"ethereum": "0xa0b8"}},
+		{ID: "usd-coin-bridged", Symbol: "usdc.e", Platforms: map[string]string{"ethereum": "0xa0b8"}},
+	}
+	list := []EthereumToken{
+		{ChainID: 1, Address: wbtcAddress, Symbol: "WBTC", Decimals: 8},
+		{ChainID: 1, Address: "0xA0B8", Symbol: "USDC", Decimals: 6},
+		{ChainID: 1, Address: "0xa0b8", Symbol: "USDC", Decimals: 6},
+		{ChainID: 1, Address: "0xfake", Symbol: "USDC", Decimals: 18},
+		{ChainID: 137, Address: "0xpoly", Symbol: "POL", Decimals: 18},
+	}
+
+	result := mergeTokens(map[*chain.Chain][]EthereumToken{ethereum: list}, coins)
+
+	ids := make(map[string]string)
+	for _, tok := range result.Tokens {
+		ids[tok.Address] = tok.ID
+	}
+
+	if ids[wbtcAddress] != "wrapped-bitcoin" {
+		t.Errorf("WBTC ID = %q, want wrapped-bitcoin", ids[wbtcAddress])
+	}
+	if ids["0xa0b8"] != "usd-coin" {
+		t.Errorf("USDC ID = %q, want usd-coin", ids["0xa0b8"])
+	}
+	if id, ok := ids["0xfake"]; !ok || id != "" {
+		t.Errorf("unmatched token ID = %q (present %v), want empty ID without symbol fallback", id, ok)
+	}
+	if len(result.Unmatched) != 1 {
+		t.Errorf("Unmatched = %d, want 1", len(result.Unmatched))
+	}
+	if result.SkippedDuplicates != 1 || result.SkippedUnsupported != 1 {
+		t.Errorf("skipped duplicates/unsupported = %d/%d, want 1/1", result.SkippedDuplicates, result.SkippedUnsupported)
+	}
+	if len(result.AddressCollisions) != 1 || result.AddressCollisions[0].Selected != "usd-coin" {
+		t.Errorf("AddressCollisions = %+v, want one collision resolved to usd-coin", result.AddressCollisions)
+	}
+	if len(result.SymbolCollisions) != 1 || result.SymbolCollisions[0].Symbol != "USDC" {
+		t.Errorf("SymbolCollisions = %+v, want one USDC collision", result.SymbolCollisions)
+	}
+}
+
+func TestDiffRegistries(t *testing.T) {
+	current := []Token{
+		{ID: "WBTC", Symbol: "WBTC", Address: wbtcAddress, Decimal: 8},
+		{ID: "old", Symbol: "OLD", Address: "0x01", Decimal: 18, ChainID: "1"},
+		{ID: "dec", Symbol: "DEC", Address: "0x02", Decimal: 18, ChainID: "1"},
+	}
+	collected := []Token{
+		{ID: "wrapped-bitcoin", Symbol: "WBTC", Address: wbtcAddress, Decimal: 8, ChainID: "1"},
+		{ID: "dec", Symbol: "DEC", Address: "0x02", Decimal: 6, ChainID: "1"},
+		{ID: "new", Symbol: "NEW", Address: "0x03", Decimal: 18, ChainID: "1"},
+	}
+
+	diff := diffRegistries(current, collected)
+
+	if len(diff.Added) != 1 || diff.Added[0].Symbol != "NEW" {
+		t.Errorf("Added = %+v, want NEW", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].Symbol != "OLD" {
+		t.Errorf("Removed = %+v, want OLD", diff.Removed)
+	}
+	if len(diff.DecimalChanges) != 1 || diff.DecimalChanges[0].From != 18 || diff.DecimalChanges[0].To != 6 {
+		t.Errorf("DecimalChanges = %+v, want DEC 18 -> 6", diff.DecimalChanges)
+	}
+	if len(diff.IDChanges) != 1 || diff.IDChanges[0].To != "wrapped-bitcoin" {
+		t.Errorf("IDChanges = %+v, want WBTC -> wrapped-bitcoin", diff.IDChanges)
+	}
+	if diffRegistries(collected, collected).Empty() != true {
+		t.Errorf("diff of identical registries should be empty")
+	}
+}
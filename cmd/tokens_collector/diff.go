@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// DecimalChange records a token whose decimals differ from the current registry
+type DecimalChange struct {
+	Token Token
+	From  int
+	To    int
+}
+
+// IDChange records a token whose CoinGecko ID differs from the current registry
+type IDChange struct {
+	Token Token
+	From  string
+	To    string
+}
+
+// RegistryDiff describes how a collected registry differs from the current one
+type RegistryDiff struct {
+	Added          []Token
+	Removed        []Token
+	DecimalChanges []DecimalChange
+	IDChanges      []IDChange
+}
+
+func (d RegistryDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.DecimalChanges) == 0 && len(d.IDChanges) == 0
+}
+
+func registryKey(t Token) string {
+	return t.ChainID + ":" + strings.ToLower(t.Address)
+}
+
+// diffRegistries compares tokens by chain and contract address
+func diffRegistries(current, collected []Token) RegistryDiff {
+	var diff RegistryDiff
+
+	currentByKey := make(map[string]Token, len(current))
+	for _, t := range current {
+		if t.ChainID == "" {
+			// Registries written before chain IDs were recorded are Ethereum only
+			t.ChainID = "1"
+		}
+		currentByKey[registryKey(t)] = t
+	}
+
+	collectedKeys := make(map[string]bool, len(collected))
+	for _, t := range collected {
+		key := registryKey(t)
+		collectedKeys[key] = true
+
+		old, exists := currentByKey[key]
+		if !exists {
+			diff.Added = append(diff.Added, t)
+			continue
+		}
+		if old.Decimal != t.Decimal {
+			diff.DecimalChanges = append(diff.DecimalChanges, DecimalChange{Token: t, From: old.Decimal, To: t.Decimal})
+		}
+		if old.ID != t.ID {
+			diff.IDChanges = append(diff.IDChanges, IDChange{Token: t, From: old.ID, To: t.ID})
+		}
+	}
+
+	for key, t := range currentByKey {
+		if !collectedKeys[key] {
+			diff.Removed = append(diff.Removed, t)
+		}
+	}
+
+	sortTokens(diff.Added)
+	sortTokens(diff.Removed)
+	sort.Slice(diff.DecimalChanges, func(i, j int) bool {
+		return lessToken(diff.DecimalChanges[i].Token, diff.DecimalChanges[j].Token)
+	})
+	sort.Slice(diff.IDChanges, func(i, j int) bool {
+		return lessToken(diff.IDChanges[i].Token, diff.IDChanges[j].Token)
+	})
+
+	return diff
+}
+
+func sortTokens(tokens []Token) {
+	sort.Slice(tokens, func(i, j int) bool { return lessToken(tokens[i], tokens[j]) })
+}
+
+func lessToken(a, b Token) bool {
+	if a.Symbol != b.Symbol {
+		return a.Symbol < b.Symbol
+	}
+	return a.Address < b.Address
+}
+
+// writeReport prints a human-readable summary of the merge findings and registry diff
+func writeReport(w io.Writer, result MergeResult, diff RegistryDiff) {
+	fmt.Fprintf(w, "Collected %d tokens (%d without CoinGecko ID, %d duplicates skipped, %d on unsupported chains skipped)\n",
+		len(result.Tokens), len(result.Unmatched), result.SkippedDuplicates, result.SkippedUnsupported)
+
+	if len(result.AddressCollisions) > 0 {
+		fmt.Fprintf(w, "\nContracts listed by several CoinGecko coins (%d):\n", len(result.AddressCollisions))
+		for _, c := range result.AddressCollisions {
+			fmt.Fprintf(w, "  chain %s %s: %s -> using %s\n", c.ChainID, c.Address, strings.Join(c.CoinIDs, ", "), c.Selected)
+		}
+	}
+
+	if len(result.SymbolCollisions) > 0 {
+		fmt.Fprintf(w, "\nSymbols shared by several contracts (%d):\n", len(result.SymbolCollisions))
+		for _, c := range result.SymbolCollisions {
+			fmt.Fprintf(w, "  chain %s %s: %s\n", c.ChainID, c.Symbol, strings.Join(c.Addresses, ", "))
+		}
+	}
+
+	fmt.Fprintln(w)
+	if diff.Empty() {
+		fmt.Fprintln(w, "No changes against the current registry")
+		return
+	}
+
+	fmt.Fprintf(w, "Changes against the current registry: %d added, %d removed, %d decimals changed, %d IDs changed\n",
+		len(diff.Added), len(diff.Removed), len(diff.DecimalChanges), len(diff.IDChanges))
+	for _, t := range diff.Added {
+		fmt.Fprintf(w, "  + %-12s %s (%s, %d decimals)\n", t.Symbol, t.Address, displayID(t.ID), t.Decimal)
+	}
+	for _, t := range diff.Removed {
+		fmt.Fprintf(w, "  - %-12s %s (%s)\n", t.Symbol, t.Address, displayID(t.ID))
+	}
+	for _, c := range diff.DecimalChanges {
+		fmt.Fprintf(w, "  ~ %-12s %s decimals %d -> %d\n", c.Token.Symbol, c.Token.Address, c.From, c.To)
+	}
+	for _, c := range diff.IDChanges {
+		fmt.Fprintf(w, "  ~ %-12s %s id %s -> %s\n", c.Token.Symbol, c.Token.Address, displayID(c.From), displayID(c.To))
+	}
+}
+
+func displayID(id string) string {
+	if id == "" {
+		return "no id"
+	}
+	return id
+}
@@ -3,17 +3,23 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"path/filepath"
 	"time"
 
 	"testtask/internal/adapters/coingecko"
+	"testtask/internal/domain/chain"
 
 	"github.com/joho/godotenv"
 )
 
+const defaultRegistryPath = "./static/tokens.json"
+
 // Token represents the merged token data
 type Token struct {
 	ID      string `json:"ID"`
@@ -24,14 +30,7 @@ type Token struct {
 	ChainID string `json:"ChainID,omitempty"`
 }
 
-// CoinGeckoToken represents a token from /coins/list endpoint
-type CoinGeckoToken struct {
-	ID     string `json:"id"`
-	Symbol string `json:"symbol"`
-	Name   string `json:"name"`
-}
-
-// EthereumToken represents a token from /token_lists/ethereum/all.json endpoint
+// EthereumToken represents a token from the /token_lists/{platform}/all.json endpoint
 type EthereumToken struct {
 	ChainID  int    `json:"chainId"`
 	Address  string `json:"address"`
@@ -41,7 +40,7 @@ type EthereumToken struct {
 	LogoURI  string `json:"logoURI,omitempty"`
 }
 
-// TokenListResponse represents the response from /token_lists/ethereum/all.json
+// TokenListResponse represents the response from /token_lists/{platform}/all.json
 type TokenListResponse struct {
 	Name    string          `json:"name"`
 	Tokens  []EthereumToken `json:"tokens"`
@@ -53,6 +52,11 @@ type TokenListResponse struct {
 }
 
 func main() {
+	dryRun := flag.Bool("dry-run", false, "print the diff against the current registry without writing it")
+	outputPath := flag.String("output", defaultRegistryPath, "path to write the collected registry to")
+	registryPath := flag.String("registry", defaultRegistryPath, "path of the current registry to diff against")
+	flag.Parse()
+
 	// Load environment variables
 	if err := godotenv.Load(); err != nil {
 		log.Printf("Warning: .env file not found: %v", err)
@@ -76,74 +80,80 @@ func main() {
 	}
 	client := coingecko.NewClient(httpClient, baseURL, apiKey)
 
-	log.Println("Fetching token list from Ethereum...")
-	var tokenListResp TokenListResponse
-	if err := client.Get(ctx, "token_lists/ethereum/all.json", &tokenListResp); err != nil {
-		log.Fatalf("Failed to fetch Ethereum token list: %v", err)
+	chainTokens := make(map[*chain.Chain][]EthereumToken, len(chain.Supported))
+	for _, c := range chain.Supported {
+		log.Printf("Fetching token list for %s...", c.Name)
+		var tokenListResp TokenListResponse
+		if err := client.Get(ctx, fmt.Sprintf("token_lists/%s/all.json", c.Platform), &tokenListResp); err != nil {
+			log.Fatalf("Failed to fetch %s token list: %v", c.Name, err)
+		}
+		log.Printf("Fetched %d %s tokens", len(tokenListResp.Tokens), c.Name)
+		chainTokens[c] = tokenListResp.Tokens
 	}
-	log.Printf("Fetched %d Ethereum tokens", len(tokenListResp.Tokens))
 
-	log.Println("Fetching coins list from CoinGecko...")
+	log.Println("Fetching coins list with platforms from CoinGecko...")
 	var coinsList []CoinGeckoToken
-	if err := client.Get(ctx, "coins/list", &coinsList); err != nil {
+	if err := client.Get(ctx, "coins/list?include_platform=true", &coinsList); err != nil {
 		log.Fatalf("Failed to fetch coins list: %v", err)
 	}
 	log.Printf("Fetched %d coins from CoinGecko", len(coinsList))
 
-	// Create a map of CoinGecko tokens by symbol for quick lookup
-	coinsMap := make(map[string]CoinGeckoToken)
-	for _, coin := range coinsList {
-		// Use symbol as key (lowercase for case-insensitive matching)
-		key := fmt.Sprintf("%s", coin.Symbol)
-		coinsMap[key] = coin
+	result := mergeTokens(chainTokens, coinsList)
+
+	current, err := loadRegistry(*registryPath)
+	if err != nil {
+		log.Fatalf("Failed to load current registry: %v", err)
 	}
 
-	// Merge the data
-	tokens := make([]Token, 0, len(tokenListResp.Tokens))
-	seenAddresses := make(map[string]bool)
+	diff := diffRegistries(current, result.Tokens)
+	writeReport(os.Stdout, result, diff)
 
-	for _, ethToken := range tokenListResp.Tokens {
-		// Skip if we've already seen this address
-		addressKey := fmt.Sprintf("%s-%d", ethToken.Address, ethToken.ChainID)
-		if seenAddresses[addressKey] {
-			continue
-		}
-		seenAddresses[addressKey] = true
-
-		token := Token{
-			Address: ethToken.Address,
-			Name:    ethToken.Name,
-			Symbol:  ethToken.Symbol,
-			Decimal: ethToken.Decimals,
-			ChainID: fmt.Sprintf("%d", ethToken.ChainID),
-		}
+	if *dryRun {
+		log.Println("Dry run, registry not written")
+		return
+	}
 
-		// Try to find matching CoinGecko ID by symbol
-		if cgToken, exists := coinsMap[ethToken.Symbol]; exists {
-			token.ID = cgToken.ID
-		} else {
-			// If no match found, use symbol as ID (fallback)
-			token.ID = ethToken.Symbol
-		}
+	if err := writeRegistry(*outputPath, result.Tokens); err != nil {
+		log.Fatalf("Failed to write registry: %v", err)
+	}
 
-		tokens = append(tokens, token)
+	log.Printf("Successfully wrote %d tokens to %s", len(result.Tokens), *outputPath)
+}
+
+// loadRegistry reads a registry file; a missing file is an empty registry
+func loadRegistry(path string) ([]Token, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	log.Printf("Merged into %d unique tokens", len(tokens))
+	var tokens []Token
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %w", path, err)
+	}
+	return tokens, nil
+}
 
-	// Write to file
-	outputPath := "./static/tokens.json"
-	file, err := os.Create(outputPath)
+// writeRegistry writes the registry through a temporary file so readers never see a partial file
+func writeRegistry(path string, tokens []Token) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
 	if err != nil {
-		log.Fatalf("Failed to create output file: %v", err)
+		return fmt.Errorf("failed to create output file: %w", err)
 	}
-	defer file.Close()
+	defer os.Remove(tmp.Name())
 
-	encoder := json.NewEncoder(file)
+	encoder := json.NewEncoder(tmp)
 	encoder.SetIndent("", "  ")
 	if err := encoder.Encode(tokens); err != nil {
-		log.Fatalf("Failed to write JSON: %v", err)
+		tmp.Close()
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close output file: %w", err)
 	}
 
-	log.Printf("Successfully wrote %d tokens to %s", len(tokens), outputPath)
+	return os.Rename(tmp.Name(), path)
 }
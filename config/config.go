@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -11,13 +12,22 @@ type Config struct {
 	Price       PriceConfig
 	Transaction TransactionConfig
 	Database    DatabaseConfig
+	Token       TokenConfig
+	Auth        AuthConfig
+	Exchange    ExchangeConfig
+	ENS         ENSConfig
 	App         AppConfig
 }
 
 type AppConfig struct {
-	Environment string // "development" or "production"
-	TokensPath  string // Path to tokens JSON file
-	LogLevel    string // "debug", "info", "warn", "error"
+	Environment     string // "development" or "production"
+	TokensPath      string // Path to tokens JSON file
+	ChainsPath      string // Path to CoinGecko token list with logo URIs
+	AliasesPath     string // Path to native asset and token alias registry
+	TaxRulesPath    string // Path to capital gains rules per jurisdiction
+	IncomeRulesPath string // Path to known income distributors and rebasing tokens
+	AddressesPath   string // Path to well-known addresses used as counterparty labels
+	LogLevel        string // "debug", "info", "warn", "error"
 }
 
 type ServerConfig struct {
@@ -43,12 +53,39 @@ type TransactionConfig struct {
 	RateLimitRPS     int
 	EtherscanAPIKey  string
 	EtherscanBaseURL string
+	RebaseInterval   time.Duration // How often rebasing token balances are checked for income, 0 disables
 }
 
 type DatabaseConfig struct {
 	Path string // SQLite database file path
 }
 
+type TokenConfig struct {
+	RefreshInterval time.Duration // How often unresolved tokens are backfilled
+	RefreshBatch    int           // Tokens resolved per refresh run
+	RefreshRetry    time.Duration // Minimum delay before retrying an unresolved token
+	ReloadPoll      time.Duration // How often the tokens file is checked for changes, 0 disables
+}
+
+type AuthConfig struct {
+	JWTSecret   string        // HS256 signing secret for access tokens, empty disables JWT authentication
+	TokenTTL    time.Duration // Lifetime of issued access tokens
+	AdminIDs    []string      // IDs of the users allowed on the admin endpoints
+}
+
+type ExchangeConfig struct {
+	CredentialsKey string        // Base64 encoded 32 byte key sealing exchange API credentials, empty disables connections
+	SyncInterval   time.Duration // How often connected exchange accounts are synced
+	BinanceBaseURL string
+}
+
+type ENSConfig struct {
+	RPCURL         string        // Ethereum JSON-RPC endpoint for ENS resolution, empty disables ENS
+	RegistryAddr   string        // ENS registry contract, the mainnet registry when empty
+	CacheTTL       time.Duration // How long resolved names and addresses are cached
+	RequestTimeout time.Duration
+}
+
 // Load loads configuration from environment variables
 func Load() *Config {
 	return &Config{
@@ -73,14 +110,42 @@ func Load() *Config {
 			RateLimitRPS:     getIntEnv("TRANSACTION_RATE_LIMIT_RPS", 5),
 			EtherscanAPIKey:  getEnv("ETHERSCAN_API_KEY", ""),
 			EtherscanBaseURL: getEnv("ETHERSCAN_BASE_URL", "https://api.etherscan.io/api"),
+			RebaseInterval:   getDurationEnv("INCOME_REBASE_INTERVAL", 24*time.Hour),
 		},
 		Database: DatabaseConfig{
 			Path: getEnv("DB_PATH", "./data/portfolio.db"),
 		},
+		Token: TokenConfig{
+			RefreshInterval: getDurationEnv("TOKEN_REFRESH_INTERVAL", time.Hour),
+			RefreshBatch:    getIntEnv("TOKEN_REFRESH_BATCH", 20),
+			RefreshRetry:    getDurationEnv("TOKEN_REFRESH_RETRY", 24*time.Hour),
+			ReloadPoll:      getDurationEnv("TOKEN_RELOAD_POLL_INTERVAL", 30*time.Second),
+		},
+		Auth: AuthConfig{
+			JWTSecret:   getEnv("AUTH_JWT_SECRET", ""),
+			TokenTTL:    getDurationEnv("AUTH_TOKEN_TTL", time.Hour),
+			AdminIDs:    getListEnv("AUTH_ADMIN_USER_IDS"),
+		},
+		Exchange: ExchangeConfig{
+			CredentialsKey: getEnv("EXCHANGE_CREDENTIALS_KEY", ""),
+			SyncInterval:   getDurationEnv("EXCHANGE_SYNC_INTERVAL", 15*time.Minute),
+			BinanceBaseURL: getEnv("BINANCE_BASE_URL", "https://api.binance.com"),
+		},
+		ENS: ENSConfig{
+			RPCURL:         getEnv("ENS_RPC_URL", ""),
+			RegistryAddr:   getEnv("ENS_REGISTRY_ADDRESS", ""),
+			CacheTTL:       getDurationEnv("ENS_CACHE_TTL", time.Hour),
+			RequestTimeout: getDurationEnv("ENS_REQUEST_TIMEOUT", 10*time.Second),
+		},
 		App: AppConfig{
-			Environment: getEnv("APP_ENV", "development"),
-			TokensPath:  getEnv("TOKENS_PATH", "./static/tokens.json"),
-			LogLevel:    getEnv("LOG_LEVEL", "info"),
+			Environment:     getEnv("APP_ENV", "development"),
+			TokensPath:      getEnv("TOKENS_PATH", "./static/tokens.json"),
+			ChainsPath:      getEnv("CHAINS_PATH", "./static/chains.json"),
+			AliasesPath:     getEnv("ALIASES_PATH", "./static/aliases.json"),
+			TaxRulesPath:    getEnv("TAX_RULES_PATH", "./static/tax_rules.json"),
+			IncomeRulesPath: getEnv("INCOME_RULES_PATH", "./static/income_rules.json"),
+			AddressesPath:   getEnv("ADDRESSES_PATH", "./static/address_labels.json"),
+			LogLevel:        getEnv("LOG_LEVEL", "info"),
 		},
 	}
 }
@@ -94,6 +159,17 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getListEnv splits a comma separated variable, skipping empty items
+func getListEnv(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func getIntEnv(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
 		if intValue, err := strconv.Atoi(value); err == nil {
@@ -0,0 +1,42 @@
+package addressbook
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"testtask/internal/domain/addressbook"
+)
+
+// registryFile is the JSON layout of the address registry file, see static/address_labels.json
+type registryFile struct {
+	Addresses []struct {
+		Address  string `json:"address"`
+		Label    string `json:"label"`
+		Category string `json:"category"`
+	} `json:"addresses"`
+}
+
+// LoadRegistry reads the well-known addresses from a JSON file
+func LoadRegistry(path string) (*addressbook.Registry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read address registry: %w", err)
+	}
+
+	var file registryFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal address registry: %w", err)
+	}
+
+	known := make([]*addressbook.KnownAddress, 0, len(file.Addresses))
+	for _, a := range file.Addresses {
+		known = append(known, &addressbook.KnownAddress{
+			Address:  a.Address,
+			Label:    a.Label,
+			Category: addressbook.Category(a.Category),
+		})
+	}
+
+	return addressbook.NewRegistry(known)
+}
@@ -0,0 +1,23 @@
+package addressbook
+
+import (
+	"testing"
+
+	"testtask/internal/domain/addressbook"
+)
+
+func TestLoadRegistry_Bundled(t *testing.T) {
+	registry, err := LoadRegistry("../../../static/address_labels.json")
+	if err != nil {
+		t.Fatalf("LoadRegistry() error = %v", err)
+	}
+
+	if k, ok := registry.Lookup("0x7A250D5630B4CF539739DF2C5DACB4C659F2488D"); !ok || k.Category != addressbook.CategoryDEX {
+		t.Errorf("Lookup(Uniswap V2 router) = %+v, %v, want a dex", k, ok)
+	}
+	for _, category := range []addressbook.Category{addressbook.CategoryDEX, addressbook.CategoryBridge, addressbook.CategoryExchange, addressbook.CategoryStaking} {
+		if len(registry.List(category)) == 0 {
+			t.Errorf("List(%s) is empty", category)
+		}
+	}
+}
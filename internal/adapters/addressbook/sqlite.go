@@ -0,0 +1,133 @@
+package addressbook
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	sqliteadapter "testtask/internal/adapters/sqlite"
+	"testtask/internal/domain/addressbook"
+)
+
+const entryColumns = `id, user_id, address, label, note, created_at, updated_at`
+
+// SQLiteRepository implements addressbook.Repository on top of the address_book table
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteRepository(db *sql.DB) *SQLiteRepository {
+	return &SQLiteRepository{db: db}
+}
+
+// List returns the user's entries sorted by label
+func (r *SQLiteRepository) List(ctx context.Context, userID string) ([]*addressbook.Entry, error) {
+	query := `SELECT ` + entryColumns + ` FROM address_book WHERE user_id = ? ORDER BY label COLLATE NOCASE ASC, address ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list address book: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]*addressbook.Entry, 0)
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan address book entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list address book: %w", err)
+	}
+	return entries, nil
+}
+
+func (r *SQLiteRepository) Get(ctx context.Context, userID, id string) (*addressbook.Entry, error) {
+	query := `SELECT ` + entryColumns + ` FROM address_book WHERE id = ? AND user_id = ?`
+
+	e, err := scanEntry(r.db.QueryRowContext(ctx, query, id, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: id=%s", addressbook.ErrEntryNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to get address book entry: %w", err)
+	}
+	return e, nil
+}
+
+func (r *SQLiteRepository) Create(ctx context.Context, e *addressbook.Entry) error {
+	query := `INSERT INTO address_book (` + entryColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		e.ID, e.UserID, e.Address, e.Label, e.Note,
+		e.CreatedAt.UTC().Format(time.RFC3339Nano), e.UpdatedAt.UTC().Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		if sqliteadapter.IsUniqueViolation(err) {
+			return fmt.Errorf("%w: address=%s", addressbook.ErrEntryExists, e.Address)
+		}
+		return fmt.Errorf("failed to create address book entry: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLiteRepository) Update(ctx context.Context, e *addressbook.Entry) error {
+	query := `UPDATE address_book SET address = ?, label = ?, note = ?, updated_at = ? WHERE id = ? AND user_id = ?`
+
+	result, err := r.db.ExecContext(ctx, query, e.Address, e.Label, e.Note, e.UpdatedAt.UTC().Format(time.RFC3339Nano), e.ID, e.UserID)
+	if err != nil {
+		if sqliteadapter.IsUniqueViolation(err) {
+			return fmt.Errorf("%w: address=%s", addressbook.ErrEntryExists, e.Address)
+		}
+		return fmt.Errorf("failed to update address book entry: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update address book entry: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w: id=%s", addressbook.ErrEntryNotFound, e.ID)
+	}
+	return nil
+}
+
+func (r *SQLiteRepository) Delete(ctx context.Context, userID, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM address_book WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete address book entry: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete address book entry: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w: id=%s", addressbook.ErrEntryNotFound, id)
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanEntry(row rowScanner) (*addressbook.Entry, error) {
+	var (
+		e                    addressbook.Entry
+		createdAt, updatedAt string
+	)
+	if err := row.Scan(&e.ID, &e.UserID, &e.Address, &e.Label, &e.Note, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if e.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return nil, fmt.Errorf("failed to parse created_at: %w", err)
+	}
+	if e.UpdatedAt, err = time.Parse(time.RFC3339Nano, updatedAt); err != nil {
+		return nil, fmt.Errorf("failed to parse updated_at: %w", err)
+	}
+	return &e, nil
+}
@@ -0,0 +1,110 @@
+package addressbook
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"testtask/internal/domain/addressbook"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// setupTestDB creates an in-memory SQLite database with the address book schema
+func setupTestDB(t *testing.T) (*SQLiteRepository, func()) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	// Every connection to :memory: is a separate database
+	db.SetMaxOpenConns(1)
+
+	schema := `
+	CREATE TABLE IF NOT EXISTS address_book (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		address TEXT NOT NULL,
+		label TEXT NOT NULL,
+		note TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		UNIQUE (user_id, address)
+	);
+	`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	return NewSQLiteRepository(db), func() { db.Close() }
+}
+
+func newEntry(t *testing.T, userID, address, label string) *addressbook.Entry {
+	t.Helper()
+	e, err := addressbook.NewEntry(userID, address, label, "")
+	if err != nil {
+		t.Fatalf("NewEntry() error = %v", err)
+	}
+	e.CreatedAt = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	e.UpdatedAt = e.CreatedAt
+	return e
+}
+
+func TestSQLiteRepository_Entries(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	bob := newEntry(t, "user-1", "0x1111111111111111111111111111111111111111", "bob")
+	alice := newEntry(t, "user-1", "0x2222222222222222222222222222222222222222", "Alice")
+	other := newEntry(t, "user-2", "0x1111111111111111111111111111111111111111", "Someone else's Bob")
+	for _, e := range []*addressbook.Entry{bob, alice, other} {
+		if err := repo.Create(ctx, e); err != nil {
+			t.Fatalf("Create(%s) error = %v", e.Label, err)
+		}
+	}
+
+	duplicate := newEntry(t, "user-1", bob.Address, "Bob again")
+	if err := repo.Create(ctx, duplicate); !errors.Is(err, addressbook.ErrEntryExists) {
+		t.Errorf("Create(duplicate) error = %v, want ErrEntryExists", err)
+	}
+
+	entries, err := repo.List(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].Label != "Alice" || entries[1].Label != "bob" {
+		t.Fatalf("List() = %+v, want Alice and bob sorted by label", entries)
+	}
+	if !entries[0].CreatedAt.Equal(alice.CreatedAt) {
+		t.Errorf("CreatedAt = %v, want %v", entries[0].CreatedAt, alice.CreatedAt)
+	}
+
+	bob.Label = "Bob"
+	bob.UpdatedAt = bob.CreatedAt.Add(time.Hour)
+	if err := repo.Update(ctx, bob); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	got, err := repo.Get(ctx, "user-1", bob.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Label != "Bob" || !got.UpdatedAt.Equal(bob.UpdatedAt) {
+		t.Errorf("Get() = %+v, want the updated label and time", got)
+	}
+
+	if _, err := repo.Get(ctx, "user-2", bob.ID); !errors.Is(err, addressbook.ErrEntryNotFound) {
+		t.Errorf("Get(other user) error = %v, want ErrEntryNotFound", err)
+	}
+	if err := repo.Delete(ctx, "user-2", bob.ID); !errors.Is(err, addressbook.ErrEntryNotFound) {
+		t.Errorf("Delete(other user) error = %v, want ErrEntryNotFound", err)
+	}
+	if err := repo.Delete(ctx, "user-1", bob.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if entries, _ := repo.List(ctx, "user-1"); len(entries) != 1 {
+		t.Errorf("List() after delete = %d entries, want 1", len(entries))
+	}
+}
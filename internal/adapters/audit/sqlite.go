@@ -0,0 +1,115 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"testtask/internal/domain/audit"
+)
+
+// SQLiteRepository implements audit.Repository on top of the append-only audit_log table
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteRepository(db *sql.DB) *SQLiteRepository {
+	return &SQLiteRepository{db: db}
+}
+
+func (r *SQLiteRepository) Append(ctx context.Context, e *audit.Entry) error {
+	query := `
+		INSERT INTO audit_log (id, actor_id, action, resource_type, resource_id, portfolio_id, before_value, after_value, request_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		e.ID, e.ActorID, e.Action, e.ResourceType, e.ResourceID,
+		nullString(e.PortfolioID), nullJSON(e.Before), nullJSON(e.After),
+		e.RequestID, e.CreatedAt.UTC().Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to append audit entry: %w", err)
+	}
+	return nil
+}
+
+// List returns matching entries, newest first
+func (r *SQLiteRepository) List(ctx context.Context, filter audit.Filter) ([]*audit.Entry, error) {
+	query := `
+		SELECT id, actor_id, action, resource_type, resource_id, COALESCE(portfolio_id, ''),
+			COALESCE(before_value, ''), COALESCE(after_value, ''), request_id, created_at
+		FROM audit_log
+		WHERE 1 = 1
+	`
+	var args []interface{}
+	if filter.PortfolioID != "" {
+		query += " AND portfolio_id = ?"
+		args = append(args, filter.PortfolioID)
+	}
+	if filter.ActorID != "" {
+		query += " AND actor_id = ?"
+		args = append(args, filter.ActorID)
+	}
+	// RFC3339Nano strings do not sort lexically across precisions, so compare as dates
+	if filter.From != nil {
+		query += " AND julianday(created_at) >= julianday(?)"
+		args = append(args, filter.From.UTC().Format(time.RFC3339Nano))
+	}
+	if filter.To != nil {
+		query += " AND julianday(created_at) < julianday(?)"
+		args = append(args, filter.To.UTC().Format(time.RFC3339Nano))
+	}
+	query += " ORDER BY julianday(created_at) DESC, id ASC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*audit.Entry
+	for rows.Next() {
+		var (
+			e                 audit.Entry
+			before, after, at string
+		)
+		if err := rows.Scan(&e.ID, &e.ActorID, &e.Action, &e.ResourceType, &e.ResourceID, &e.PortfolioID, &before, &after, &e.RequestID, &at); err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		if before != "" {
+			e.Before = []byte(before)
+		}
+		if after != "" {
+			e.After = []byte(after)
+		}
+		if e.CreatedAt, err = time.Parse(time.RFC3339Nano, at); err != nil {
+			return nil, fmt.Errorf("failed to parse audit entry time: %w", err)
+		}
+		entries = append(entries, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func nullJSON(b []byte) interface{} {
+	if len(b) == 0 {
+		return nil
+	}
+	return string(b)
+}
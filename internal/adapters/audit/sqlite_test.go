@@ -0,0 +1,146 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"testtask/internal/domain/audit"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// setupTestDB creates an in-memory SQLite database with the audit_log schema
+func setupTestDB(t *testing.T) (*SQLiteRepository, *sql.DB, func()) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+
+	schema := `
+	CREATE TABLE IF NOT EXISTS audit_log (
+		id TEXT PRIMARY KEY,
+		actor_id TEXT NOT NULL,
+		action TEXT NOT NULL,
+		resource_type TEXT NOT NULL,
+		resource_id TEXT NOT NULL,
+		portfolio_id TEXT,
+		before_value TEXT,
+		after_value TEXT,
+		request_id TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE TRIGGER IF NOT EXISTS audit_log_no_update
+	BEFORE UPDATE ON audit_log
+	BEGIN
+		SELECT RAISE(ABORT, 'audit_log is append-only');
+	END;
+	`
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	return NewSQLiteRepository(db), db, func() { db.Close() }
+}
+
+func TestSQLiteRepository_AppendAndList(t *testing.T) {
+	repo, db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	entries := []struct {
+		action      string
+		actor       string
+		portfolioID string
+		at          time.Time
+	}{
+		{audit.ActionHoldingCreate, "alice", "p1", base},
+		{audit.ActionHoldingUpdate, "alice", "p1", base.Add(500 * time.Millisecond)},
+		{audit.ActionHoldingDelete, "bob", "p1", base.Add(2 * time.Hour)},
+		{audit.ActionAPIKeyCreate, "bob", "", base.Add(3 * time.Hour)},
+		{audit.ActionPortfolioCreate, "carol", "p2", base.Add(4 * time.Hour)},
+	}
+	for _, e := range entries {
+		entry := audit.NewEntry(e.action, audit.ResourceHolding, "h1", e.portfolioID)
+		entry.ActorID = e.actor
+		entry.CreatedAt = e.at
+		entry.RequestID = "req-" + e.action
+		if e.action == audit.ActionHoldingUpdate {
+			entry.Before = json.RawMessage(`{"amount":"1"}`)
+			entry.After = json.RawMessage(`{"amount":"2"}`)
+		}
+		if err := repo.Append(ctx, entry); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	from := base.Add(100 * time.Millisecond)
+	to := base.Add(2 * time.Hour)
+
+	tests := []struct {
+		name        string
+		filter      audit.Filter
+		wantActions []string
+	}{
+		{
+			name:        "portfolio newest first",
+			filter:      audit.Filter{PortfolioID: "p1"},
+			wantActions: []string{audit.ActionHoldingDelete, audit.ActionHoldingUpdate, audit.ActionHoldingCreate},
+		},
+		{
+			name:        "actor",
+			filter:      audit.Filter{ActorID: "bob"},
+			wantActions: []string{audit.ActionAPIKeyCreate, audit.ActionHoldingDelete},
+		},
+		{
+			name:        "time range is half-open",
+			filter:      audit.Filter{PortfolioID: "p1", From: &from, To: &to},
+			wantActions: []string{audit.ActionHoldingUpdate},
+		},
+		{
+			name:        "limit",
+			filter:      audit.Filter{Limit: 2},
+			wantActions: []string{audit.ActionPortfolioCreate, audit.ActionAPIKeyCreate},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.List(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if len(got) != len(tt.wantActions) {
+				t.Fatalf("List() returned %d entries, want %d", len(got), len(tt.wantActions))
+			}
+			for i, e := range got {
+				if e.Action != tt.wantActions[i] {
+					t.Errorf("List()[%d].Action = %s, want %s", i, e.Action, tt.wantActions[i])
+				}
+			}
+		})
+	}
+
+	got, err := repo.List(ctx, audit.Filter{PortfolioID: "p1", From: &from, To: &to})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	e := got[0]
+	if string(e.Before) != `{"amount":"1"}` || string(e.After) != `{"amount":"2"}` {
+		t.Errorf("List() before/after = %s/%s", e.Before, e.After)
+	}
+	if e.ActorID != "alice" || e.RequestID != "req-"+audit.ActionHoldingUpdate || !e.CreatedAt.Equal(base.Add(500*time.Millisecond)) {
+		t.Errorf("List() entry = %+v", e)
+	}
+
+	if _, err := db.Exec(`UPDATE audit_log SET actor_id = 'mallory'`); err == nil {
+		t.Error("UPDATE audit_log succeeded, want append-only error")
+	}
+}
@@ -3,11 +3,15 @@ package coingecko
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 )
 
+// ErrNotFound is returned when CoinGecko does not know the requested resource
+var ErrNotFound = errors.New("CoinGecko resource not found")
+
 type Client struct {
 	client  *http.Client
 	baseURL string
@@ -33,6 +37,10 @@ func (c *Client) Get(ctx context.Context, endpoint string, out interface{}) erro
 
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", ErrNotFound, endpoint)
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		return fmt.Errorf("CoinGecko API error: status %d, body: %s", resp.StatusCode, string(body))
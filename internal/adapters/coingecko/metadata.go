@@ -0,0 +1,58 @@
+package coingecko
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"testtask/internal/domain/token"
+)
+
+// CoinGeckoContractResponse represents the subset of /coins/{platform}/contract/{address} we use
+type CoinGeckoContractResponse struct {
+	ID              string `json:"id"`
+	Symbol          string `json:"symbol"`
+	Name            string `json:"name"`
+	DetailPlatforms map[string]struct {
+		DecimalPlace    *uint8 `json:"decimal_place"`
+		ContractAddress string `json:"contract_address"`
+	} `json:"detail_platforms"`
+}
+
+// MetadataRepository implements token.MetadataProvider using CoinGecko contract lookups
+type MetadataRepository struct {
+	coingeckoClient *Client
+	platform        string
+}
+
+func NewMetadataRepository(coingeckoClient *Client) *MetadataRepository {
+	return &MetadataRepository{coingeckoClient: coingeckoClient, platform: "ethereum"}
+}
+
+// GetMetadata returns token metadata for a contract address, or token.ErrTokenNotFound
+// when CoinGecko does not list the contract.
+func (m *MetadataRepository) GetMetadata(ctx context.Context, address string) (*token.Token, error) {
+	address = strings.ToLower(address)
+	path := fmt.Sprintf("coins/%s/contract/%s", m.platform, address)
+
+	var data CoinGeckoContractResponse
+	if err := m.coingeckoClient.Get(ctx, path, &data); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("%w: address=%s", token.ErrTokenNotFound, address)
+		}
+		return nil, fmt.Errorf("failed to fetch token metadata: %w", err)
+	}
+
+	t := &token.Token{
+		ID:      data.ID,
+		Name:    data.Name,
+		Symbol:  strings.ToUpper(data.Symbol),
+		Address: address,
+	}
+	if detail, ok := data.DetailPlatforms[m.platform]; ok && detail.DecimalPlace != nil {
+		t.Decimal = *detail.DecimalPlace
+	}
+
+	return t, nil
+}
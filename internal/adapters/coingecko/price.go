@@ -7,18 +7,38 @@ import (
 	"testtask/internal/domain/token"
 	"time"
 
+	loggeradapter "testtask/internal/adapters/logger"
 	"testtask/internal/domain/price"
+
+	"go.uber.org/zap"
 )
 
 type CoinGeckoSimplePriceResponse map[string]map[string]float64
 
+// CoinGeckoMarket represents an item of the /coins/markets response
+type CoinGeckoMarket struct {
+	ID                       string   `json:"id"`
+	PriceChangePercentage24h *float64 `json:"price_change_percentage_24h_in_currency"`
+	PriceChangePercentage7d  *float64 `json:"price_change_percentage_7d_in_currency"`
+}
+
+// CoinGeckoMarketChartResponse represents the /market_chart/range response;
+// each price point is a [unix millis, price] pair
+type CoinGeckoMarketChartResponse struct {
+	Prices [][]float64 `json:"prices"`
+}
+
 type PriceRepository struct {
 	coingeckoClient *Client
 	symbolToID      map[string]string // Cache for symbol to CoinGecko ID mapping
+	logger          *loggeradapter.Logger
 }
 
-func NewPriceRepository(coingeckoClient *Client, symbolToID map[string]string) *PriceRepository {
-	return &PriceRepository{coingeckoClient: coingeckoClient, symbolToID: symbolToID}
+func NewPriceRepository(coingeckoClient *Client, symbolToID map[string]string, logger *loggeradapter.Logger) *PriceRepository {
+	if logger == nil {
+		logger = loggeradapter.NewNopLogger()
+	}
+	return &PriceRepository{coingeckoClient: coingeckoClient, symbolToID: symbolToID, logger: logger}
 }
 
 func (a *PriceRepository) GetPrices(
@@ -35,9 +55,9 @@ func (a *PriceRepository) GetPrices(
 		currency = "usd"
 	}
 
-	tokenMap := make(map[string]*token.Token) // Map from CoinGecko ID to Token
+	tokenMap := make(map[string]*token.Token) // Map from lowercase contract address to Token
 	for _, token := range tokens {
-		tokenMap[token.ID] = token
+		tokenMap[strings.ToLower(token.Address)] = token
 	}
 
 	const maxBatchSize = 250
@@ -61,6 +81,12 @@ func (a *PriceRepository) GetPrices(
 			return nil, err
 		}
 
+		// 7d change is not part of the simple price endpoint; it is best effort
+		// and a failure here must not drop the prices we already have.
+		if err := a.enrichWeeklyChange(ctx, batchResults, currency); err != nil {
+			a.logger.Warn("Failed to fetch 7d price change", zap.String("currency", currency), zap.Error(err))
+		}
+
 		for token, price := range batchResults {
 			results[token] = price
 		}
@@ -77,7 +103,7 @@ func (a *PriceRepository) fetchPricesBatch(
 	tokenMap map[string]*token.Token,
 ) (map[*token.Token]*price.Price, error) {
 	idsParam := strings.Join(tokenIDs, ",")
-	path := fmt.Sprintf("/simple/token_price/ethereum?contract_addresses=%s&vs_currencies=%s&include_last_updated_at=true&include_tokens=all&precision=8", idsParam, currency)
+	path := fmt.Sprintf("/simple/token_price/ethereum?contract_addresses=%s&vs_currencies=%s&include_market_cap=true&include_24hr_vol=true&include_24hr_change=true&include_last_updated_at=true&include_tokens=all&precision=8", idsParam, currency)
 
 	var data CoinGeckoSimplePriceResponse
 
@@ -89,7 +115,7 @@ func (a *PriceRepository) fetchPricesBatch(
 	results := make(map[*token.Token]*price.Price)
 	for tokenID, priceData := range data {
 		if priceValue, ok := priceData[currency]; ok {
-			t, ok := tokenMap[strings.ToUpper(tokenID)]
+			t, ok := tokenMap[strings.ToLower(tokenID)]
 			if !ok {
 				symbol := a.getSymbolFromID(tokenID)
 				t = &token.Token{
@@ -109,6 +135,8 @@ func (a *PriceRepository) fetchPricesBatch(
 			p := price.NewPrice(t, priceAmount, strings.ToUpper(currency))
 
 			p.LastUpdated = lastUpdated
+			p.Market = parseMarketData(priceData, currency)
+			p.Source = price.SourceCoinGecko
 			results[t] = &p
 		}
 	}
@@ -116,6 +144,156 @@ func (a *PriceRepository) fetchPricesBatch(
 	return results, nil
 }
 
+// GetHistoricalPrices fetches the price of each token closest to the given time.
+// CoinGecko has no batch endpoint for historical contract prices, so tokens are
+// fetched one by one; tokens that fail or have no data around that time are
+// omitted. An error is returned only when no token could be fetched.
+func (a *PriceRepository) GetHistoricalPrices(
+	ctx context.Context,
+	tokens []*token.Token,
+	currency string,
+	at time.Time,
+) (map[*token.Token]*price.Price, error) {
+	results := make(map[*token.Token]*price.Price)
+	if len(tokens) == 0 {
+		return results, nil
+	}
+
+	currency = strings.ToLower(currency)
+	if currency == "" {
+		currency = "usd"
+	}
+
+	var lastErr error
+	for _, t := range tokens {
+		p, err := a.fetchHistoricalPrice(ctx, t, currency, at)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			a.logger.Warn("Failed to fetch historical price", zap.String("address", t.Address), zap.Error(err))
+			lastErr = err
+			continue
+		}
+		if p != nil {
+			results[t] = p
+		}
+	}
+	if len(results) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+
+	return results, nil
+}
+
+// fetchHistoricalPrice fetches the market chart around the given time and
+// picks the data point closest to it
+func (a *PriceRepository) fetchHistoricalPrice(
+	ctx context.Context,
+	t *token.Token,
+	currency string,
+	at time.Time,
+) (*price.Price, error) {
+	const window = 12 * time.Hour
+	path := fmt.Sprintf("/coins/ethereum/contract/%s/market_chart/range?vs_currency=%s&from=%d&to=%d&precision=8",
+		strings.ToLower(t.Address), currency, at.Add(-window).Unix(), at.Add(window).Unix())
+
+	var data CoinGeckoMarketChartResponse
+	if err := a.coingeckoClient.Get(ctx, path, &data); err != nil {
+		return nil, fmt.Errorf("failed to fetch historical price for %s: %w", t.Address, err)
+	}
+
+	var (
+		closest   []float64
+		closestTo time.Duration
+	)
+	for _, point := range data.Prices {
+		if len(point) < 2 {
+			continue
+		}
+		distance := time.UnixMilli(int64(point[0])).Sub(at).Abs()
+		if closest == nil || distance < closestTo {
+			closest = point
+			closestTo = distance
+		}
+	}
+	if closest == nil {
+		return nil, nil
+	}
+
+	p := price.NewPrice(t, convertFloatToBigInt(closest[1], price.CurrencyDecimal), strings.ToUpper(currency))
+	p.LastUpdated = time.UnixMilli(int64(closest[0]))
+	p.Source = price.SourceCoinGecko
+	return &p, nil
+}
+
+// enrichWeeklyChange adds the 7d percentage change from /coins/markets to
+// prices of tokens that have a CoinGecko ID.
+func (a *PriceRepository) enrichWeeklyChange(
+	ctx context.Context,
+	prices map[*token.Token]*price.Price,
+	currency string,
+) error {
+	byID := make(map[string]*price.Price)
+	for t, p := range prices {
+		if t.ID != "" {
+			byID[strings.ToLower(t.ID)] = p
+		}
+	}
+	if len(byID) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(byID))
+	for id := range byID {
+		ids = append(ids, id)
+	}
+
+	path := fmt.Sprintf("/coins/markets?vs_currency=%s&ids=%s&price_change_percentage=24h,7d&per_page=%d", currency, strings.Join(ids, ","), len(ids))
+
+	var markets []CoinGeckoMarket
+	if err := a.coingeckoClient.Get(ctx, path, &markets); err != nil {
+		return fmt.Errorf("failed to fetch markets: %w", err)
+	}
+
+	for _, m := range markets {
+		p, ok := byID[strings.ToLower(m.ID)]
+		if !ok || m.PriceChangePercentage7d == nil {
+			continue
+		}
+		if p.Market == nil {
+			p.Market = &price.MarketData{}
+		}
+		p.Market.Change7d = convertFloatToBigInt(*m.PriceChangePercentage7d, price.ChangeDecimal)
+	}
+
+	return nil
+}
+
+// parseMarketData extracts the optional market fields of a simple price entry
+func parseMarketData(priceData map[string]float64, currency string) *price.MarketData {
+	var market price.MarketData
+	found := false
+
+	if v, ok := priceData[currency+"_24h_change"]; ok {
+		market.Change24h = convertFloatToBigInt(v, price.ChangeDecimal)
+		found = true
+	}
+	if v, ok := priceData[currency+"_24h_vol"]; ok {
+		market.Volume24h = convertFloatToBigInt(v, price.CurrencyDecimal)
+		found = true
+	}
+	if v, ok := priceData[currency+"_market_cap"]; ok {
+		market.MarketCap = convertFloatToBigInt(v, price.CurrencyDecimal)
+		found = true
+	}
+
+	if !found {
+		return nil
+	}
+	return &market
+}
+
 // getSymbolFromID gets symbol from token ID using reverse lookup
 func (a *PriceRepository) getSymbolFromID(tokenID string) string {
 	// Reverse lookup in symbolToID map
@@ -28,6 +28,7 @@ func (p *MockProvider) GetPrices(
 			Value:       big.NewInt(1000000000),
 			Currency:    currency,
 			LastUpdated: time.Now(),
+			Source:      price.SourceMock,
 		}
 	}
 
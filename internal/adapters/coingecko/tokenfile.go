@@ -0,0 +1,35 @@
+package coingecko
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"testtask/internal/domain/token"
+)
+
+// TokenFile implements token.ListSource over the JSON file written by the token collector
+type TokenFile struct {
+	path string
+}
+
+func NewTokenFile(path string) *TokenFile {
+	return &TokenFile{path: path}
+}
+
+func (f *TokenFile) Load(_ context.Context) ([]*token.Token, error) {
+	return loadTokensFromFile(f.path)
+}
+
+func (f *TokenFile) ModTime() (time.Time, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to stat tokens file: %w", err)
+	}
+	return info.ModTime(), nil
+}
+
+func (f *TokenFile) Location() string {
+	return f.path
+}
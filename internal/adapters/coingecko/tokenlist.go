@@ -0,0 +1,47 @@
+package coingecko
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// TokenList represents a token list in the Uniswap token list format, as published by CoinGecko
+type TokenList struct {
+	Name   string           `json:"name"`
+	Tokens []TokenListEntry `json:"tokens"`
+}
+
+type TokenListEntry struct {
+	ChainID  int    `json:"chainId"`
+	Address  string `json:"address"`
+	Name     string `json:"name"`
+	Symbol   string `json:"symbol"`
+	Decimals uint8  `json:"decimals"`
+	LogoURI  string `json:"logoURI"`
+}
+
+// LoadTokenLogos reads a token list file and returns logo URIs keyed by lowercase
+// contract address for the given chain.
+func LoadTokenLogos(path string, chainID int) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token list: %w", err)
+	}
+
+	var list TokenList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal token list: %w", err)
+	}
+
+	logos := make(map[string]string, len(list.Tokens))
+	for _, t := range list.Tokens {
+		if t.ChainID != chainID || t.LogoURI == "" {
+			continue
+		}
+		logos[strings.ToLower(t.Address)] = t.LogoURI
+	}
+
+	return logos, nil
+}
@@ -0,0 +1,98 @@
+package ens
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"testtask/internal/domain/ens"
+)
+
+// Client calls contracts through the eth_call method of an Ethereum JSON-RPC endpoint
+type Client struct {
+	httpClient *http.Client
+	url        string
+}
+
+func NewClient(httpClient *http.Client, url string) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+	return &Client{
+		httpClient: httpClient,
+		url:        url,
+	}
+}
+
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      int           `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type callParams struct {
+	To   string `json:"to"`
+	Data string `json:"data"`
+}
+
+type rpcResponse struct {
+	Result string `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Call executes a read-only call of the contract at the latest block and returns the
+// ABI-encoded return data. Failures wrap ens.ErrUnavailable.
+func (c *Client) Call(ctx context.Context, to string, data []byte) ([]byte, error) {
+	body, err := json.Marshal(rpcRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "eth_call",
+		Params:  []interface{}{callParams{To: to, Data: "0x" + hex.EncodeToString(data)}, "latest"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: encode request: %v", ens.ErrUnavailable, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("%w: build request: %v", ens.ErrUnavailable, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: do request: %v", ens.ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: read body: %v", ens.ErrUnavailable, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d, body: %s", ens.ErrUnavailable, resp.StatusCode, string(respBody))
+	}
+
+	var out rpcResponse
+	if err := json.Unmarshal(respBody, &out); err != nil {
+		return nil, fmt.Errorf("%w: decode body: %v", ens.ErrUnavailable, err)
+	}
+	if out.Error != nil {
+		return nil, fmt.Errorf("%w: rpc error %d: %s", ens.ErrUnavailable, out.Error.Code, out.Error.Message)
+	}
+
+	result, err := hex.DecodeString(strings.TrimPrefix(out.Result, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: decode result: %v", ens.ErrUnavailable, err)
+	}
+	return result, nil
+}
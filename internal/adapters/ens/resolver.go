@@ -0,0 +1,160 @@
+package ens
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"testtask/internal/domain/ens"
+	"testtask/internal/domain/token"
+)
+
+// Function selectors of the registry and public resolver methods used for resolution
+var (
+	resolverSelector = selector("resolver(bytes32)")
+	addrSelector     = selector("addr(bytes32)")
+	nameSelector     = selector("name(bytes32)")
+)
+
+func selector(signature string) []byte {
+	return ens.Keccak256([]byte(signature))[:4]
+}
+
+// callData encodes a call of a method taking a single bytes32 node
+func callData(selector []byte, node [32]byte) []byte {
+	data := make([]byte, 0, len(selector)+len(node))
+	data = append(data, selector...)
+	return append(data, node[:]...)
+}
+
+// Caller executes read-only contract calls, see Client
+type Caller interface {
+	Call(ctx context.Context, to string, data []byte) ([]byte, error)
+}
+
+// Resolver implements ens.Resolver by asking the registry for the resolver contract of a
+// node and then the resolver for the node's address or name
+type Resolver struct {
+	caller   Caller
+	registry string
+}
+
+// NewResolver creates a resolver against the registry at the given address, the mainnet
+// registry when it is empty
+func NewResolver(caller Caller, registry string) *Resolver {
+	if registry == "" {
+		registry = ens.RegistryAddress
+	}
+	return &Resolver{caller: caller, registry: registry}
+}
+
+// Resolve returns the address set on the name's resolver
+func (r *Resolver) Resolve(ctx context.Context, name string) (string, error) {
+	name, err := ens.Normalize(name)
+	if err != nil {
+		return "", err
+	}
+	node := ens.Namehash(name)
+
+	resolver, err := r.resolverOf(ctx, node)
+	if err != nil {
+		return "", err
+	}
+	if resolver == "" {
+		return "", fmt.Errorf("%w: %s has no resolver", ens.ErrNotResolved, name)
+	}
+
+	result, err := r.caller.Call(ctx, resolver, callData(addrSelector, node))
+	if err != nil {
+		return "", err
+	}
+	address := decodeAddress(result)
+	if address == "" {
+		return "", fmt.Errorf("%w: %s has no address", ens.ErrNotResolved, name)
+	}
+	return address, nil
+}
+
+// LookupAddress returns the name the address's reverse record points to, provided the
+// name resolves back to the address; reverse records are set by their owner and are not
+// trusted on their own
+func (r *Resolver) LookupAddress(ctx context.Context, address string) (string, error) {
+	if !token.IsAddress(address) {
+		return "", nil
+	}
+	node := ens.Namehash(ens.ReverseName(address))
+
+	resolver, err := r.resolverOf(ctx, node)
+	if err != nil || resolver == "" {
+		return "", err
+	}
+	result, err := r.caller.Call(ctx, resolver, callData(nameSelector, node))
+	if err != nil {
+		return "", err
+	}
+	name, err := decodeString(result)
+	if err != nil || name == "" {
+		// A malformed reverse record is no name rather than a failure of resolution
+		return "", nil
+	}
+
+	resolved, err := r.Resolve(ctx, name)
+	if errors.Is(err, ens.ErrNotResolved) || errors.Is(err, ens.ErrInvalidName) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if resolved != strings.ToLower(address) {
+		return "", nil
+	}
+	normalized, _ := ens.Normalize(name)
+	return normalized, nil
+}
+
+// resolverOf returns the resolver contract of the node, empty when none is set
+func (r *Resolver) resolverOf(ctx context.Context, node [32]byte) (string, error) {
+	result, err := r.caller.Call(ctx, r.registry, callData(resolverSelector, node))
+	if err != nil {
+		return "", err
+	}
+	return decodeAddress(result), nil
+}
+
+// decodeAddress reads an ABI-encoded address; empty for the zero address and for no data,
+// which is what calling an address without code returns
+func decodeAddress(data []byte) string {
+	if len(data) < 32 {
+		return ""
+	}
+	word := data[:32]
+	for _, b := range word[:12] {
+		if b != 0 {
+			return ""
+		}
+	}
+	if new(big.Int).SetBytes(word[12:]).Sign() == 0 {
+		return ""
+	}
+	return "0x" + hex.EncodeToString(word[12:])
+}
+
+// decodeString reads an ABI-encoded dynamic string return value
+func decodeString(data []byte) (string, error) {
+	if len(data) < 64 {
+		return "", fmt.Errorf("string result of %d bytes is too short", len(data))
+	}
+	offset := new(big.Int).SetBytes(data[:32])
+	if !offset.IsInt64() || offset.Int64() > int64(len(data)-32) {
+		return "", fmt.Errorf("string offset %s is out of range", offset)
+	}
+	start := int(offset.Int64())
+	length := new(big.Int).SetBytes(data[start : start+32])
+	if !length.IsInt64() || length.Int64() > int64(len(data)-start-32) {
+		return "", fmt.Errorf("string length %s is out of range", length)
+	}
+	return string(data[start+32 : start+32+int(length.Int64())]), nil
+}
@@ -0,0 +1,145 @@
+package ens
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"testtask/internal/domain/ens"
+)
+
+const (
+	vitalikAddress  = "0xd8da6bf26964af9d7eed9e03e53415d37aa96045"
+	spooferAddress  = "0x1111111111111111111111111111111111111111"
+	resolverAddress = "0x231b0ee14048e9dccd1d247744d114a4eb5e8e63"
+)
+
+// stubRPC answers eth_call requests from a table of contract address and call data to
+// result; unknown calls return no data, like a call to an address without code
+type stubRPC struct {
+	results map[string][]byte
+}
+
+func (s *stubRPC) set(to string, data, result []byte) {
+	s.results[to+":"+hex.EncodeToString(data)] = result
+}
+
+func (s *stubRPC) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Method != "eth_call" || len(req.Params) != 2 {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	var call struct {
+		To   string `json:"to"`
+		Data string `json:"data"`
+	}
+	if err := json.Unmarshal(req.Params[0], &call); err != nil {
+		http.Error(w, "bad call", http.StatusBadRequest)
+		return
+	}
+
+	result := s.results[strings.ToLower(call.To)+":"+strings.TrimPrefix(call.Data, "0x")]
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"result":  "0x" + hex.EncodeToString(result),
+	})
+}
+
+func addressWord(address string) []byte {
+	word := make([]byte, 32)
+	b, _ := hex.DecodeString(strings.TrimPrefix(address, "0x"))
+	copy(word[12:], b)
+	return word
+}
+
+func stringResult(s string) []byte {
+	result := make([]byte, 64)
+	result[31] = 32
+	result[63] = byte(len(s))
+	padded := make([]byte, (len(s)+31)/32*32)
+	copy(padded, s)
+	return append(result, padded...)
+}
+
+// newStubResolver serves vitalik.eth with a reverse record, and a spoofer whose reverse
+// record also claims vitalik.eth
+func newStubResolver(t *testing.T) *Resolver {
+	t.Helper()
+	stub := &stubRPC{results: make(map[string][]byte)}
+
+	name := ens.Namehash("vitalik.eth")
+	stub.set(ens.RegistryAddress, callData(resolverSelector, name), addressWord(resolverAddress))
+	stub.set(resolverAddress, callData(addrSelector, name), addressWord(vitalikAddress))
+
+	for _, address := range []string{vitalikAddress, spooferAddress} {
+		reverse := ens.Namehash(ens.ReverseName(address))
+		stub.set(ens.RegistryAddress, callData(resolverSelector, reverse), addressWord(resolverAddress))
+		stub.set(resolverAddress, callData(nameSelector, reverse), stringResult("vitalik.eth"))
+	}
+
+	server := httptest.NewServer(stub)
+	t.Cleanup(server.Close)
+	return NewResolver(NewClient(server.Client(), server.URL), "")
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	resolver := newStubResolver(t)
+	ctx := context.Background()
+
+	got, err := resolver.Resolve(ctx, "Vitalik.eth")
+	if err != nil || got != vitalikAddress {
+		t.Errorf("Resolve(vitalik.eth) = %q, %v, want %s", got, err, vitalikAddress)
+	}
+	if _, err := resolver.Resolve(ctx, "nobody.eth"); !errors.Is(err, ens.ErrNotResolved) {
+		t.Errorf("Resolve(nobody.eth) error = %v, want ErrNotResolved", err)
+	}
+	if _, err := resolver.Resolve(ctx, "vitalik..eth"); !errors.Is(err, ens.ErrInvalidName) {
+		t.Errorf("Resolve(vitalik..eth) error = %v, want ErrInvalidName", err)
+	}
+}
+
+func TestResolver_LookupAddress(t *testing.T) {
+	resolver := newStubResolver(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		address string
+		want    string
+	}{
+		{"verified", "0xD8DA6BF26964AF9D7EED9E03E53415D37AA96045", "vitalik.eth"},
+		{"claims a name of another address", spooferAddress, ""},
+		{"no reverse record", "0x2222222222222222222222222222222222222222", ""},
+		{"not an address", "0x1234", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolver.LookupAddress(ctx, tt.address)
+			if err != nil || got != tt.want {
+				t.Errorf("LookupAddress() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_Call_RPCError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"execution reverted"}}`))
+	}))
+	defer server.Close()
+
+	resolver := NewResolver(NewClient(server.Client(), server.URL), "")
+	if _, err := resolver.Resolve(context.Background(), "vitalik.eth"); !errors.Is(err, ens.ErrUnavailable) {
+		t.Errorf("Resolve() error = %v, want ErrUnavailable", err)
+	}
+}
@@ -58,6 +58,7 @@ type tokenTx struct {
 	From            string `json:"from"`
 	To              string `json:"to"`
 	ContractAddress string `json:"contractAddress"`
+	TokenName       string `json:"tokenName"`
 	TokenSymbol     string `json:"tokenSymbol"`
 	TokenDecimal    string `json:"tokenDecimal"`
 	Value           string `json:"value"`
@@ -188,6 +189,33 @@ func (p *Provider) GetNativeBalance(ctx context.Context, address string) (*big.I
 	return balance, nil
 }
 
+func (p *Provider) TokenBalance(ctx context.Context, address, contract string) (*big.Int, error) {
+	if err := p.allow(ctx); err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("module", "account")
+	params.Set("action", "tokenbalance")
+	params.Set("contractaddress", strings.ToLower(strings.TrimSpace(contract)))
+	params.Set("address", strings.ToLower(strings.TrimSpace(address)))
+	params.Set("tag", "latest")
+
+	var resp apiResponse[string]
+	if err := p.client.get(ctx, params, &resp); err != nil {
+		return nil, fmt.Errorf("etherscan token balance: %w", err)
+	}
+	if resp.Status != "1" {
+		return nil, fmt.Errorf("etherscan token balance: status=%s message=%s", resp.Status, resp.Message)
+	}
+
+	balance, ok := new(big.Int).SetString(resp.Result, 10)
+	if !ok {
+		return nil, fmt.Errorf("etherscan token balance: invalid result %q", resp.Result)
+	}
+	return balance, nil
+}
+
 func (p *Provider) allow(ctx context.Context) error {
 	if p.rateLimiter == nil {
 		return nil
@@ -293,6 +321,8 @@ func mapTokenTxs(items []tokenTx, address string) []*transaction.Transaction {
 			To:           strings.ToLower(it.To),
 			TokenAddress: strings.ToLower(it.ContractAddress),
 			TokenSymbol:  it.TokenSymbol,
+			TokenName:    it.TokenName,
+			TokenDecimal: parseDecimals(it.TokenDecimal),
 			Amount:       amount,
 			Status:       transaction.TransactionStatusSuccess, // Etherscan token transfers are only for successful txs
 			Timestamp:    ts,
@@ -333,3 +363,12 @@ func parseBlockNumber(s string) int64 {
 	}
 	return n
 }
+
+func parseDecimals(s string) *uint8 {
+	n, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return nil
+	}
+	d := uint8(n)
+	return &d
+}
@@ -0,0 +1,184 @@
+package exchange
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"testtask/internal/domain/exchange"
+)
+
+// BinanceParser reads Binance exports: the spot trade history, in its current
+// (Pair, Side, Executed, Amount, Fee) and legacy (Market, Type, Amount, Total, Fee Coin)
+// layouts, and the transaction statement (UTC_Time, Operation, Coin, Change).
+// Neither export carries row IDs, so the trade history and the statement of the same
+// period should not both be imported.
+type BinanceParser struct{}
+
+func (BinanceParser) Parse(r io.Reader) ([]*exchange.Record, error) {
+	t, err := readTable(r, func(c map[string]int) bool {
+		return has(c, "date(utc)", "side", "executed") || has(c, "date(utc)", "market", "fee coin") || has(c, "utc_time", "operation", "coin", "change")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := rowIDs{}
+	var records []*exchange.Record
+	for i, row := range t.rows {
+		var parsed []*exchange.Record
+		switch {
+		case has(t.columns, "utc_time"):
+			parsed, err = parseBinanceStatementRow(t, row, ids)
+		case has(t.columns, "executed"):
+			parsed, err = parseBinanceTradeRow(t, row, ids)
+		default:
+			parsed, err = parseBinanceLegacyTradeRow(t, row, ids)
+		}
+		if err != nil {
+			return nil, rowError(t.lines[i], err)
+		}
+		records = append(records, parsed...)
+	}
+	return validate(records)
+}
+
+func parseBinanceTradeRow(t *table, row []string, ids rowIDs) ([]*exchange.Record, error) {
+	at, err := parseTime(t.field(row, "date(utc)"))
+	if err != nil {
+		return nil, err
+	}
+	base, quote, _ := splitPair(t.field(row, "pair"))
+	baseAmount, baseAsset, err := splitQuantity(t.field(row, "executed"), base)
+	if err != nil {
+		return nil, err
+	}
+	quoteAmount, quoteAsset, err := splitQuantity(t.field(row, "amount"), quote)
+	if err != nil {
+		return nil, err
+	}
+	var fee *big.Int
+	var feeAsset string
+	if value := t.field(row, "fee"); value != "" {
+		if fee, feeAsset, err = splitQuantity(value, base, quote, "BNB"); err != nil {
+			return nil, err
+		}
+	}
+
+	side := strings.ToUpper(t.field(row, "side"))
+	if err := applySide(side, baseAmount, quoteAmount); err != nil {
+		return nil, err
+	}
+	id := ids.next(string(exchange.Binance), strings.Join(row, ","))
+	return tradeRecords(exchange.Binance, id, at, baseAsset, baseAmount, quoteAsset, quoteAmount, feeAsset, fee, t.field(row, "price"))
+}
+
+func parseBinanceLegacyTradeRow(t *table, row []string, ids rowIDs) ([]*exchange.Record, error) {
+	at, err := parseTime(t.field(row, "date(utc)"))
+	if err != nil {
+		return nil, err
+	}
+	base, quote, ok := splitPair(t.field(row, "market"))
+	if !ok {
+		return nil, fmt.Errorf("unknown market %q", t.field(row, "market"))
+	}
+	baseAmount, err := parseAmount(t.field(row, "amount"))
+	if err != nil {
+		return nil, err
+	}
+	quoteAmount, err := parseAmount(t.field(row, "total"))
+	if err != nil {
+		return nil, err
+	}
+	var fee *big.Int
+	if value := t.field(row, "fee"); value != "" {
+		if fee, err = parseAmount(value); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := applySide(strings.ToUpper(t.field(row, "type")), baseAmount, quoteAmount); err != nil {
+		return nil, err
+	}
+	id := ids.next(string(exchange.Binance), strings.Join(row, ","))
+	return tradeRecords(exchange.Binance, id, at, base, baseAmount, quote, quoteAmount, strings.ToUpper(t.field(row, "fee coin")), fee, t.field(row, "price"))
+}
+
+// applySide signs the legs of a trade: a buy receives the base asset and spends the quote
+func applySide(side string, base, quote *big.Int) error {
+	base.Abs(base)
+	quote.Abs(quote)
+	switch side {
+	case "BUY":
+		quote.Neg(quote)
+	case "SELL":
+		base.Neg(base)
+	default:
+		return fmt.Errorf("unknown side %q", side)
+	}
+	return nil
+}
+
+// binanceTradeOperations are statement operations that are legs of a trade or conversion
+var binanceTradeOperations = map[string]bool{
+	"buy":                         true,
+	"sell":                        true,
+	"transaction related":         true,
+	"transaction buy":             true,
+	"transaction spend":           true,
+	"transaction sold":            true,
+	"transaction revenue":         true,
+	"small assets exchange bnb":   true,
+	"binance convert":             true,
+	"large otc trading":           true,
+	"auto-invest transaction":     true,
+	"asset conversion transfer":   true,
+	"stablecoins auto-conversion": true,
+}
+
+func parseBinanceStatementRow(t *table, row []string, ids rowIDs) ([]*exchange.Record, error) {
+	operation := t.field(row, "operation")
+	op := strings.ToLower(operation)
+	// Moves between the user's own Binance wallets do not change the balance
+	if strings.HasPrefix(op, "transfer between") {
+		return nil, nil
+	}
+
+	at, err := parseTime(t.field(row, "utc_time"))
+	if err != nil {
+		return nil, err
+	}
+	amount, err := parseAmount(t.field(row, "change"))
+	if err != nil {
+		return nil, err
+	}
+	if amount.Sign() == 0 {
+		return nil, nil
+	}
+
+	record := &exchange.Record{
+		Exchange:   exchange.Binance,
+		ExternalID: ids.next(string(exchange.Binance), strings.Join(row, ",")),
+		Asset:      strings.ToUpper(t.field(row, "coin")),
+		Amount:     amount,
+		Note:       strings.TrimSpace(strings.Join([]string{operation, t.field(row, "remark")}, " ")),
+		OccurredAt: at,
+	}
+	switch {
+	case op == "deposit":
+		record.Kind = exchange.KindDeposit
+	case op == "withdraw" || op == "withdrawal":
+		record.Kind = exchange.KindWithdrawal
+	case strings.Contains(op, "fee") && amount.Sign() < 0:
+		record.Kind = exchange.KindFee
+		record.TradeID = exchange.RowID(string(exchange.Binance), t.field(row, "user_id"), t.field(row, "utc_time"))
+	case binanceTradeOperations[op]:
+		record.Kind = exchange.KindTrade
+		record.TradeID = exchange.RowID(string(exchange.Binance), t.field(row, "user_id"), t.field(row, "utc_time"))
+	default:
+		// Rewards, interest, airdrops and the like
+		record.Kind = transferKind(amount)
+	}
+	return []*exchange.Record{record}, nil
+}
@@ -0,0 +1,309 @@
+package exchange
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"testtask/internal/domain/exchange"
+)
+
+const (
+	// BinanceBaseURL is the production REST endpoint
+	BinanceBaseURL = "https://api.binance.com"
+
+	binanceRecvWindow = "10000"
+	binanceTradeLimit = 1000
+)
+
+// binanceAuthCodes are the API error codes for unknown keys, bad signatures and keys
+// without the required permission
+var binanceAuthCodes = map[int]bool{-1022: true, -2014: true, -2015: true}
+
+// BinanceConnector reads a Binance account through the REST API. Only GET endpoints are
+// used, each signed with HMAC-SHA256 of the query string under the API secret.
+type BinanceConnector struct {
+	httpClient *http.Client
+	baseURL    string
+	now        func() time.Time
+}
+
+func NewBinanceConnector(httpClient *http.Client, baseURL string) *BinanceConnector {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	if baseURL == "" {
+		baseURL = BinanceBaseURL
+	}
+	return &BinanceConnector{
+		httpClient: httpClient,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		now:        time.Now,
+	}
+}
+
+type binanceRestrictions struct {
+	EnableSpotAndMarginTrading bool `json:"enableSpotAndMarginTrading"`
+	EnableWithdrawals          bool `json:"enableWithdrawals"`
+	EnableInternalTransfer     bool `json:"enableInternalTransfer"`
+	EnableMargin               bool `json:"enableMargin"`
+	EnableFutures              bool `json:"enableFutures"`
+	PermitsUniversalTransfer   bool `json:"permitsUniversalTransfer"`
+}
+
+// VerifyReadOnly rejects keys that may trade, withdraw or move funds between accounts
+func (c *BinanceConnector) VerifyReadOnly(ctx context.Context, creds exchange.Credentials) error {
+	var restrictions binanceRestrictions
+	if err := c.signedGet(ctx, creds, "/sapi/v1/account/apiRestrictions", url.Values{}, &restrictions); err != nil {
+		return err
+	}
+
+	var enabled []string
+	for name, on := range map[string]bool{
+		"trading":            restrictions.EnableSpotAndMarginTrading,
+		"withdrawals":        restrictions.EnableWithdrawals,
+		"internal transfers": restrictions.EnableInternalTransfer,
+		"margin":             restrictions.EnableMargin,
+		"futures":            restrictions.EnableFutures,
+		"universal transfer": restrictions.PermitsUniversalTransfer,
+	} {
+		if on {
+			enabled = append(enabled, name)
+		}
+	}
+	if len(enabled) > 0 {
+		sort.Strings(enabled)
+		return fmt.Errorf("%w: %s enabled", exchange.ErrNotReadOnly, strings.Join(enabled, ", "))
+	}
+	return nil
+}
+
+type binanceAccount struct {
+	Balances []struct {
+		Asset  string `json:"asset"`
+		Free   string `json:"free"`
+		Locked string `json:"locked"`
+	} `json:"balances"`
+}
+
+// Balances returns the spot account's non-zero balances
+func (c *BinanceConnector) Balances(ctx context.Context, creds exchange.Credentials) ([]*exchange.Balance, error) {
+	var account binanceAccount
+	params := url.Values{}
+	params.Set("omitZeroBalances", "true")
+	if err := c.signedGet(ctx, creds, "/api/v3/account", params, &account); err != nil {
+		return nil, err
+	}
+
+	balances := make([]*exchange.Balance, 0, len(account.Balances))
+	for _, b := range account.Balances {
+		free, err := parseAmount(b.Free)
+		if err != nil {
+			return nil, fmt.Errorf("binance: balance of %s: %w", b.Asset, err)
+		}
+		locked, err := parseAmount(b.Locked)
+		if err != nil {
+			return nil, fmt.Errorf("binance: balance of %s: %w", b.Asset, err)
+		}
+		if free.Sign() == 0 && locked.Sign() == 0 {
+			continue
+		}
+		balances = append(balances, &exchange.Balance{Asset: strings.ToUpper(b.Asset), Free: free, Locked: locked})
+	}
+	return balances, nil
+}
+
+type binanceSymbol struct {
+	Symbol     string `json:"symbol"`
+	BaseAsset  string `json:"baseAsset"`
+	QuoteAsset string `json:"quoteAsset"`
+}
+
+type binanceTrade struct {
+	Symbol          string `json:"symbol"`
+	ID              int64  `json:"id"`
+	Price           string `json:"price"`
+	Qty             string `json:"qty"`
+	QuoteQty        string `json:"quoteQty"`
+	Commission      string `json:"commission"`
+	CommissionAsset string `json:"commissionAsset"`
+	Time            int64  `json:"time"`
+	IsBuyer         bool   `json:"isBuyer"`
+}
+
+// Trades returns the trades after the cursor in every market between two of the given
+// assets. Binance only serves trade history per market, so markets are limited to those
+// whose base and quote asset the account holds or has held.
+func (c *BinanceConnector) Trades(ctx context.Context, creds exchange.Credentials, assets []string, cursor exchange.Cursor) ([]*exchange.Record, exchange.Cursor, error) {
+	symbols, err := c.symbols(ctx, assets)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	next := make(exchange.Cursor, len(cursor))
+	for k, v := range cursor {
+		next[k] = v
+	}
+
+	var records []*exchange.Record
+	for _, s := range symbols {
+		for {
+			params := url.Values{}
+			params.Set("symbol", s.Symbol)
+			params.Set("limit", strconv.Itoa(binanceTradeLimit))
+			if last, ok := next[s.Symbol]; ok {
+				params.Set("fromId", strconv.FormatInt(last+1, 10))
+			}
+			var trades []binanceTrade
+			if err := c.signedGet(ctx, creds, "/api/v3/myTrades", params, &trades); err != nil {
+				return nil, nil, err
+			}
+			for _, t := range trades {
+				recs, err := binanceTradeRecords(s, t)
+				if err != nil {
+					return nil, nil, fmt.Errorf("binance: trade %d of %s: %w", t.ID, s.Symbol, err)
+				}
+				records = append(records, recs...)
+				if t.ID > next[s.Symbol] {
+					next[s.Symbol] = t.ID
+				}
+			}
+			if len(trades) < binanceTradeLimit {
+				break
+			}
+		}
+	}
+	return records, next, nil
+}
+
+// symbols lists the trading markets whose base and quote asset are both among assets
+func (c *BinanceConnector) symbols(ctx context.Context, assets []string) ([]binanceSymbol, error) {
+	held := make(map[string]bool, len(assets))
+	for _, a := range assets {
+		held[strings.ToUpper(a)] = true
+	}
+	if len(held) < 2 {
+		return nil, nil
+	}
+
+	var info struct {
+		Symbols []binanceSymbol `json:"symbols"`
+	}
+	if err := c.get(ctx, "/api/v3/exchangeInfo", url.Values{}, nil, &info); err != nil {
+		return nil, err
+	}
+	var symbols []binanceSymbol
+	for _, s := range info.Symbols {
+		if held[strings.ToUpper(s.BaseAsset)] && held[strings.ToUpper(s.QuoteAsset)] {
+			symbols = append(symbols, s)
+		}
+	}
+	sort.Slice(symbols, func(i, j int) bool { return symbols[i].Symbol < symbols[j].Symbol })
+	return symbols, nil
+}
+
+// binanceTradeRecords maps a fill to its base and quote legs plus the commission
+func binanceTradeRecords(s binanceSymbol, t binanceTrade) ([]*exchange.Record, error) {
+	base, err := parseAmount(t.Qty)
+	if err != nil {
+		return nil, err
+	}
+	quote, err := parseAmount(t.QuoteQty)
+	if err != nil {
+		return nil, err
+	}
+	side := "SELL"
+	if t.IsBuyer {
+		side = "BUY"
+	}
+	if err := applySide(side, base, quote); err != nil {
+		return nil, err
+	}
+	var fee *big.Int
+	if t.Commission != "" {
+		if fee, err = parseAmount(t.Commission); err != nil {
+			return nil, err
+		}
+	}
+
+	id := fmt.Sprintf("api:%s:%d", s.Symbol, t.ID)
+	at := time.UnixMilli(t.Time).UTC()
+	return tradeRecords(exchange.Binance, id, at,
+		strings.ToUpper(s.BaseAsset), base, strings.ToUpper(s.QuoteAsset), quote,
+		strings.ToUpper(t.CommissionAsset), fee, t.Price)
+}
+
+// signedGet issues an authenticated GET, adding the timestamp and signature to params
+func (c *BinanceConnector) signedGet(ctx context.Context, creds exchange.Credentials, path string, params url.Values, out interface{}) error {
+	params.Set("recvWindow", binanceRecvWindow)
+	params.Set("timestamp", strconv.FormatInt(c.now().UnixMilli(), 10))
+	query := params.Encode()
+
+	mac := hmac.New(sha256.New, []byte(creds.APISecret))
+	mac.Write([]byte(query))
+	params.Set("signature", hex.EncodeToString(mac.Sum(nil)))
+
+	return c.get(ctx, path, params, http.Header{"X-MBX-APIKEY": []string{creds.APIKey}}, out)
+}
+
+func (c *BinanceConnector) get(ctx context.Context, path string, params url.Values, header http.Header, out interface{}) error {
+	u := c.baseURL + path
+	if len(params) > 0 {
+		// The signature must come last and cover the query exactly as sent
+		signature := params.Get("signature")
+		params.Del("signature")
+		u += "?" + params.Encode()
+		if signature != "" {
+			u += "&signature=" + signature
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return fmt.Errorf("binance: build request: %w", err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("binance: do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("binance: read body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Code int    `json:"code"`
+			Msg  string `json:"msg"`
+		}
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Msg != "" {
+			if resp.StatusCode == http.StatusUnauthorized || binanceAuthCodes[apiErr.Code] {
+				return fmt.Errorf("%w: binance: %s", exchange.ErrInvalidConnection, apiErr.Msg)
+			}
+			return fmt.Errorf("binance: status %d, code %d: %s", resp.StatusCode, apiErr.Code, apiErr.Msg)
+		}
+		return fmt.Errorf("binance: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("binance: decode body: %w", err)
+	}
+	return nil
+}
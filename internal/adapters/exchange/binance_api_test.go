@@ -0,0 +1,200 @@
+package exchange
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"testtask/internal/domain/exchange"
+)
+
+var testCredentials = exchange.Credentials{APIKey: "key-1234", APISecret: "secret"}
+
+// binanceStub serves canned responses per path, checking every signed request the way
+// the exchange does
+func binanceStub(t *testing.T, responses map[string]func(r *http.Request) (int, string)) *BinanceConnector {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("%s %s, want only GET requests", r.Method, r.URL.Path)
+		}
+		if r.URL.Path != "/api/v3/exchangeInfo" {
+			query, signature, _ := strings.Cut(r.URL.RawQuery, "&signature=")
+			mac := hmac.New(sha256.New, []byte(testCredentials.APISecret))
+			mac.Write([]byte(query))
+			if signature != hex.EncodeToString(mac.Sum(nil)) || r.Header.Get("X-MBX-APIKEY") != testCredentials.APIKey {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"code":-1022,"msg":"Signature for this request is not valid."}`))
+				return
+			}
+			if r.URL.Query().Get("timestamp") != "1704067200000" {
+				t.Errorf("timestamp = %s, want the connector clock", r.URL.Query().Get("timestamp"))
+			}
+		}
+		respond, ok := responses[r.URL.Path]
+		if !ok {
+			t.Errorf("unexpected request %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		status, body := respond(r)
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	c := NewBinanceConnector(server.Client(), server.URL)
+	c.now = func() time.Time { return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) }
+	return c
+}
+
+func fixed(body string) func(*http.Request) (int, string) {
+	return func(*http.Request) (int, string) { return http.StatusOK, body }
+}
+
+func TestBinanceConnector_VerifyReadOnly(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		creds   exchange.Credentials
+		wantErr error
+	}{
+		{
+			name:   "read-only key",
+			status: http.StatusOK,
+			body:   `{"ipRestrict":false,"enableReading":true,"enableSpotAndMarginTrading":false,"enableWithdrawals":false}`,
+			creds:  testCredentials,
+		},
+		{
+			name:    "trading key",
+			status:  http.StatusOK,
+			body:    `{"enableReading":true,"enableSpotAndMarginTrading":true,"enableWithdrawals":true}`,
+			creds:   testCredentials,
+			wantErr: exchange.ErrNotReadOnly,
+		},
+		{
+			name:    "wrong secret",
+			creds:   exchange.Credentials{APIKey: testCredentials.APIKey, APISecret: "other"},
+			wantErr: exchange.ErrInvalidConnection,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := binanceStub(t, map[string]func(*http.Request) (int, string){
+				"/sapi/v1/account/apiRestrictions": func(*http.Request) (int, string) { return tt.status, tt.body },
+			})
+			err := c.VerifyReadOnly(context.Background(), tt.creds)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("VerifyReadOnly() error = %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifyReadOnly() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBinanceConnector_Balances(t *testing.T) {
+	c := binanceStub(t, map[string]func(*http.Request) (int, string){
+		"/api/v3/account": fixed(`{"balances":[
+			{"asset":"BTC","free":"0.50000000","locked":"0.10000000"},
+			{"asset":"USDT","free":"1250.5","locked":"0.00000000"},
+			{"asset":"BNB","free":"0.00000000","locked":"0.00000000"}
+		]}`),
+	})
+
+	balances, err := c.Balances(context.Background(), testCredentials)
+	if err != nil {
+		t.Fatalf("Balances() error = %v", err)
+	}
+	if len(balances) != 2 {
+		t.Fatalf("Balances() returned %d balances, want 2 non-zero", len(balances))
+	}
+	if b := balances[0]; b.Asset != "BTC" || b.Total().String() != "600000000000000000" {
+		t.Errorf("balances[0] = %s %s, want BTC 0.6", b.Asset, b.Total())
+	}
+}
+
+func TestBinanceConnector_Trades(t *testing.T) {
+	var fromIDs []string
+	c := binanceStub(t, map[string]func(*http.Request) (int, string){
+		"/api/v3/exchangeInfo": fixed(`{"symbols":[
+			{"symbol":"BTCUSDT","baseAsset":"BTC","quoteAsset":"USDT"},
+			{"symbol":"ETHUSDT","baseAsset":"ETH","quoteAsset":"USDT"},
+			{"symbol":"ETHBTC","baseAsset":"ETH","quoteAsset":"BTC"}
+		]}`),
+		"/api/v3/myTrades": func(r *http.Request) (int, string) {
+			if r.URL.Query().Get("symbol") != "BTCUSDT" {
+				t.Errorf("myTrades symbol = %s, want only markets between held assets", r.URL.Query().Get("symbol"))
+			}
+			fromIDs = append(fromIDs, r.URL.Query().Get("fromId"))
+			return http.StatusOK, `[
+				{"symbol":"BTCUSDT","id":42,"price":"42000.00","qty":"0.01","quoteQty":"420.00","commission":"0.00001","commissionAsset":"BTC","time":1704103200000,"isBuyer":true},
+				{"symbol":"BTCUSDT","id":43,"price":"43000.00","qty":"0.005","quoteQty":"215.00","commission":"0.215","commissionAsset":"USDT","time":1704189600000,"isBuyer":false}
+			]`
+		},
+	})
+
+	records, cursor, err := c.Trades(context.Background(), testCredentials, []string{"BTC", "USDT"}, exchange.Cursor{"BTCUSDT": 41})
+	if err != nil {
+		t.Fatalf("Trades() error = %v", err)
+	}
+
+	want := []string{
+		"trade BTC 0.0100", "trade USDT -420.0000", "fee BTC -0.0000",
+		"trade BTC -0.0050", "trade USDT 215.0000", "fee USDT -0.2150",
+	}
+	got := make([]string, len(records))
+	for i, r := range records {
+		got[i] = describe(r)
+	}
+	if strings.Join(got, "; ") != strings.Join(want, "; ") {
+		t.Errorf("Trades() =\n  %s\nwant\n  %s", strings.Join(got, "; "), strings.Join(want, "; "))
+	}
+	if records[0].ExternalID != "api:BTCUSDT:42:BTC" || records[0].Price.String() != "4200000000000" {
+		t.Errorf("records[0] = %s at %v, want api:BTCUSDT:42:BTC at 42000", records[0].ExternalID, records[0].Price)
+	}
+	if len(fromIDs) != 1 || fromIDs[0] != "42" {
+		t.Errorf("fromId = %v, want [42] after the cursor", fromIDs)
+	}
+	if cursor["BTCUSDT"] != 43 {
+		t.Errorf("cursor = %v, want BTCUSDT at 43", cursor)
+	}
+}
+
+func TestAESGCMCipher(t *testing.T) {
+	key := []byte(strings.Repeat("k", 32))
+	c, err := NewAESGCMCipher(key)
+	if err != nil {
+		t.Fatalf("NewAESGCMCipher() error = %v", err)
+	}
+
+	sealed, err := c.Encrypt([]byte("secret"))
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if strings.Contains(sealed, "secret") {
+		t.Errorf("Encrypt() = %q, want the plaintext hidden", sealed)
+	}
+	plain, err := c.Decrypt(sealed)
+	if err != nil || string(plain) != "secret" {
+		t.Errorf("Decrypt() = %q, %v, want secret", plain, err)
+	}
+
+	other, _ := NewAESGCMCipher([]byte(strings.Repeat("o", 32)))
+	if _, err := other.Decrypt(sealed); err == nil {
+		t.Error("Decrypt() with another key succeeded, want an error")
+	}
+	if _, err := NewAESGCMCipher([]byte("short")); err == nil {
+		t.Error("NewAESGCMCipher() with a short key succeeded, want an error")
+	}
+}
@@ -0,0 +1,144 @@
+package exchange
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"regexp"
+	"strings"
+
+	"testtask/internal/domain/exchange"
+)
+
+// CoinbaseParser reads the Coinbase transaction history. The report's preamble above the
+// header is skipped, and both the current layout (ID, Price Currency, Price at
+// Transaction, Fees and/or Spread) and the legacy one (Spot Price Currency, Spot Price at
+// Transaction, Fees) are accepted.
+type CoinbaseParser struct{}
+
+func (CoinbaseParser) Parse(r io.Reader) ([]*exchange.Record, error) {
+	t, err := readTable(r, func(c map[string]int) bool {
+		return has(c, "timestamp", "transaction type", "asset", "quantity transacted")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := rowIDs{}
+	var records []*exchange.Record
+	for i, row := range t.rows {
+		parsed, err := parseCoinbaseRow(t, row, ids)
+		if err != nil {
+			return nil, rowError(t.lines[i], err)
+		}
+		records = append(records, parsed...)
+	}
+	return validate(records)
+}
+
+// coinbaseConvertNote reads the target of a conversion, e.g. "Converted 0.5 ETH to 1,000 USDC"
+var coinbaseConvertNote = regexp.MustCompile(`(?i)converted\s+[\d.,]+\s+\S+\s+to\s+([\d.,]+)\s+(\S+)`)
+
+func parseCoinbaseRow(t *table, row []string, ids rowIDs) ([]*exchange.Record, error) {
+	at, err := parseTime(strings.TrimSuffix(t.field(row, "timestamp"), " UTC"))
+	if err != nil {
+		return nil, err
+	}
+	asset := strings.ToUpper(t.field(row, "asset"))
+	quantity, err := parseAmount(t.field(row, "quantity transacted"))
+	if err != nil {
+		return nil, err
+	}
+	quantity.Abs(quantity)
+	currency := strings.ToUpper(t.field(row, "price currency", "spot price currency"))
+	unitPrice := t.field(row, "price at transaction", "spot price at transaction")
+	notes := t.field(row, "notes")
+
+	id := t.field(row, "id")
+	if id == "" {
+		id = ids.next(string(exchange.Coinbase), strings.Join(row, ","))
+	}
+
+	var fee *big.Int
+	if value := t.field(row, "fees and/or spread", "fees"); value != "" {
+		if fee, err = parseAmount(value); err != nil {
+			return nil, err
+		}
+		fee.Abs(fee)
+	}
+
+	txType := strings.ToLower(t.field(row, "transaction type"))
+	switch txType {
+	case "buy", "sell", "advanced trade buy", "advanced trade sell":
+		subtotal, err := parseAmount(t.field(row, "subtotal"))
+		if err != nil {
+			return nil, err
+		}
+		side := "BUY"
+		if strings.HasSuffix(txType, "sell") {
+			side = "SELL"
+		}
+		if err := applySide(side, quantity, subtotal); err != nil {
+			return nil, err
+		}
+		return tradeRecords(exchange.Coinbase, id, at, asset, quantity, currency, subtotal, currency, fee, unitPrice)
+
+	case "convert":
+		match := coinbaseConvertNote.FindStringSubmatch(notes)
+		if match == nil {
+			return nil, fmt.Errorf("cannot read conversion target from %q", notes)
+		}
+		received, err := parseAmount(match[1])
+		if err != nil {
+			return nil, err
+		}
+		records, err := tradeRecords(exchange.Coinbase, id, at, strings.ToUpper(match[2]), received, asset, quantity.Neg(quantity), currency, fee, "")
+		if err != nil {
+			return nil, err
+		}
+		// The report prices the converted asset, which is the quote leg here
+		if c, ok := quoteCurrencies[currency]; ok && unitPrice != "" {
+			if records[1].Price, err = parsePrice(unitPrice); err != nil {
+				return nil, err
+			}
+			records[1].Currency = c
+		}
+		return records, nil
+	}
+
+	record := &exchange.Record{
+		Exchange:   exchange.Coinbase,
+		ExternalID: id,
+		Asset:      asset,
+		Amount:     quantity,
+		Note:       notes,
+		OccurredAt: at,
+	}
+	switch txType {
+	case "send", "withdrawal", "withdraw":
+		record.Kind = exchange.KindWithdrawal
+		record.Amount.Neg(record.Amount)
+	case "receive", "deposit":
+		record.Kind = exchange.KindDeposit
+	default:
+		// Rewards income, staking income, learning rewards and the like
+		record.Kind = exchange.KindDeposit
+		if record.Note == "" {
+			record.Note = t.field(row, "transaction type")
+		}
+	}
+	if record.Kind == exchange.KindDeposit {
+		if c, ok := quoteCurrencies[currency]; ok && unitPrice != "" {
+			if record.Price, err = parsePrice(unitPrice); err != nil {
+				return nil, err
+			}
+			record.Currency = c
+		}
+	}
+
+	records := []*exchange.Record{record}
+	if fee != nil && fee.Sign() != 0 && currency != "" {
+		records = append(records, feeRecord(exchange.Coinbase, id, at, currency, fee))
+	}
+	return records, nil
+}
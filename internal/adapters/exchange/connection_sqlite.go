@@ -0,0 +1,180 @@
+package exchange
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	sqliteadapter "testtask/internal/adapters/sqlite"
+	"testtask/internal/domain/exchange"
+)
+
+// SQLiteConnectionRepository implements exchange.ConnectionRepository on top of the
+// exchange_connections table
+type SQLiteConnectionRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteConnectionRepository(db *sql.DB) *SQLiteConnectionRepository {
+	return &SQLiteConnectionRepository{db: db}
+}
+
+const connectionColumns = `id, portfolio_id, exchange, label, api_key_hint, credentials, cursor, last_synced_at, last_error, created_at, updated_at`
+
+func (r *SQLiteConnectionRepository) CreateConnection(ctx context.Context, c *exchange.Connection) error {
+	cursor, err := json.Marshal(c.Cursor)
+	if err != nil {
+		return fmt.Errorf("failed to encode sync cursor: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO exchange_connections (`+connectionColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		c.ID, c.PortfolioID, string(c.Exchange), c.Label, c.KeyHint, c.Credentials, string(cursor),
+		optionalTime(c.LastSyncedAt), c.LastError,
+		c.CreatedAt.UTC().Format(time.RFC3339), c.UpdatedAt.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		if sqliteadapter.IsUniqueViolation(err) {
+			return fmt.Errorf("%w: portfolio_id=%s exchange=%s", exchange.ErrConnectionExists, c.PortfolioID, c.Exchange)
+		}
+		return fmt.Errorf("failed to create exchange connection: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLiteConnectionRepository) GetConnection(ctx context.Context, portfolioID, id string) (*exchange.Connection, error) {
+	scope, scopeArgs := sqliteadapter.PortfolioScope(ctx, "portfolio_id")
+	query := `SELECT ` + connectionColumns + ` FROM exchange_connections WHERE id = ? AND portfolio_id = ?` + scope
+	args := append([]interface{}{id, portfolioID}, scopeArgs...)
+
+	c, err := scanConnection(r.db.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: id=%s", exchange.ErrConnectionNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange connection: %w", err)
+	}
+	return c, nil
+}
+
+func (r *SQLiteConnectionRepository) ListConnections(ctx context.Context, portfolioID string) ([]*exchange.Connection, error) {
+	scope, scopeArgs := sqliteadapter.PortfolioScope(ctx, "portfolio_id")
+	query := `SELECT ` + connectionColumns + ` FROM exchange_connections WHERE portfolio_id = ?` + scope +
+		` ORDER BY created_at ASC, id ASC`
+	return r.list(ctx, query, append([]interface{}{portfolioID}, scopeArgs...)...)
+}
+
+func (r *SQLiteConnectionRepository) ListAllConnections(ctx context.Context) ([]*exchange.Connection, error) {
+	scope, scopeArgs := sqliteadapter.PortfolioScope(ctx, "portfolio_id")
+	query := `SELECT ` + connectionColumns + ` FROM exchange_connections WHERE 1 = 1` + scope +
+		` ORDER BY created_at ASC, id ASC`
+	return r.list(ctx, query, scopeArgs...)
+}
+
+func (r *SQLiteConnectionRepository) list(ctx context.Context, query string, args ...interface{}) ([]*exchange.Connection, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exchange connections: %w", err)
+	}
+	defer rows.Close()
+
+	connections := make([]*exchange.Connection, 0)
+	for rows.Next() {
+		c, err := scanConnection(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan exchange connection: %w", err)
+		}
+		connections = append(connections, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list exchange connections: %w", err)
+	}
+	return connections, nil
+}
+
+func (r *SQLiteConnectionRepository) UpdateSyncState(ctx context.Context, c *exchange.Connection) error {
+	cursor, err := json.Marshal(c.Cursor)
+	if err != nil {
+		return fmt.Errorf("failed to encode sync cursor: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE exchange_connections
+		SET cursor = ?, last_synced_at = ?, last_error = ?, updated_at = ?
+		WHERE id = ? AND portfolio_id = ?
+	`, string(cursor), optionalTime(c.LastSyncedAt), c.LastError, c.UpdatedAt.UTC().Format(time.RFC3339), c.ID, c.PortfolioID)
+	if err != nil {
+		return fmt.Errorf("failed to update exchange connection: %w", err)
+	}
+	return requireAffected(result, c.ID)
+}
+
+func (r *SQLiteConnectionRepository) DeleteConnection(ctx context.Context, portfolioID, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM exchange_connections WHERE id = ? AND portfolio_id = ?`, id, portfolioID)
+	if err != nil {
+		return fmt.Errorf("failed to delete exchange connection: %w", err)
+	}
+	return requireAffected(result, id)
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanConnection(row rowScanner) (*exchange.Connection, error) {
+	var (
+		c                    exchange.Connection
+		exch, cursor         string
+		createdAt, updatedAt string
+		lastSynced           sql.NullString
+	)
+	if err := row.Scan(&c.ID, &c.PortfolioID, &exch, &c.Label, &c.KeyHint, &c.Credentials, &cursor,
+		&lastSynced, &c.LastError, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	c.Exchange = exchange.Exchange(exch)
+
+	if err := json.Unmarshal([]byte(cursor), &c.Cursor); err != nil {
+		return nil, fmt.Errorf("failed to decode sync cursor: %w", err)
+	}
+	if c.Cursor == nil {
+		c.Cursor = exchange.Cursor{}
+	}
+	var err error
+	if c.CreatedAt, err = time.Parse(time.RFC3339, createdAt); err != nil {
+		return nil, fmt.Errorf("failed to parse created_at: %w", err)
+	}
+	if c.UpdatedAt, err = time.Parse(time.RFC3339, updatedAt); err != nil {
+		return nil, fmt.Errorf("failed to parse updated_at: %w", err)
+	}
+	if lastSynced.Valid {
+		t, err := time.Parse(time.RFC3339, lastSynced.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse last_synced_at: %w", err)
+		}
+		c.LastSyncedAt = &t
+	}
+	return &c, nil
+}
+
+func requireAffected(result sql.Result, id string) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: id=%s", exchange.ErrConnectionNotFound, id)
+	}
+	return nil
+}
+
+func optionalTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,96 @@
+package exchange
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"testtask/internal/domain/exchange"
+	"testtask/internal/domain/user"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// setupConnectionTestDB creates an in-memory SQLite database with the exchange connections schema
+func setupConnectionTestDB(t *testing.T) *SQLiteConnectionRepository {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	schema := `
+	CREATE TABLE IF NOT EXISTS exchange_connections (
+		id TEXT PRIMARY KEY,
+		portfolio_id TEXT NOT NULL,
+		exchange TEXT NOT NULL,
+		label TEXT NOT NULL DEFAULT '',
+		api_key_hint TEXT NOT NULL DEFAULT '',
+		credentials TEXT NOT NULL,
+		cursor TEXT NOT NULL DEFAULT '{}',
+		last_synced_at DATETIME,
+		last_error TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_exchange_connections_portfolio_exchange ON exchange_connections(portfolio_id, exchange);
+	`
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+	return NewSQLiteConnectionRepository(db)
+}
+
+func TestSQLiteConnectionRepository(t *testing.T) {
+	repo := setupConnectionTestDB(t)
+	ctx := user.AsSystem(context.Background())
+
+	c := exchange.NewConnection("portfolio-1", exchange.Binance, "main", "1234", "sealed")
+	if err := repo.CreateConnection(ctx, c); err != nil {
+		t.Fatalf("CreateConnection() error = %v", err)
+	}
+	duplicate := exchange.NewConnection("portfolio-1", exchange.Binance, "second", "5678", "sealed")
+	if err := repo.CreateConnection(ctx, duplicate); !errors.Is(err, exchange.ErrConnectionExists) {
+		t.Fatalf("CreateConnection() duplicate error = %v, want ErrConnectionExists", err)
+	}
+	if err := repo.CreateConnection(ctx, exchange.NewConnection("portfolio-2", exchange.Binance, "", "9999", "sealed")); err != nil {
+		t.Fatalf("CreateConnection() other portfolio error = %v", err)
+	}
+
+	synced := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c.Cursor, c.LastSyncedAt, c.LastError, c.UpdatedAt = exchange.Cursor{"BTCUSDT": 43}, &synced, "", synced
+	if err := repo.UpdateSyncState(ctx, c); err != nil {
+		t.Fatalf("UpdateSyncState() error = %v", err)
+	}
+
+	got, err := repo.GetConnection(ctx, "portfolio-1", c.ID)
+	if err != nil {
+		t.Fatalf("GetConnection() error = %v", err)
+	}
+	if got.Label != "main" || got.KeyHint != "1234" || got.Credentials != "sealed" || got.Cursor["BTCUSDT"] != 43 ||
+		got.LastSyncedAt == nil || !got.LastSyncedAt.Equal(synced) {
+		t.Errorf("GetConnection() = %+v, want the synced connection", got)
+	}
+	if _, err := repo.GetConnection(ctx, "portfolio-2", c.ID); !errors.Is(err, exchange.ErrConnectionNotFound) {
+		t.Errorf("GetConnection() from another portfolio error = %v, want ErrConnectionNotFound", err)
+	}
+
+	listed, err := repo.ListConnections(ctx, "portfolio-1")
+	if err != nil || len(listed) != 1 {
+		t.Fatalf("ListConnections() = %d, %v, want 1", len(listed), err)
+	}
+	all, err := repo.ListAllConnections(ctx)
+	if err != nil || len(all) != 2 {
+		t.Fatalf("ListAllConnections() = %d, %v, want 2", len(all), err)
+	}
+
+	if err := repo.DeleteConnection(ctx, "portfolio-1", c.ID); err != nil {
+		t.Fatalf("DeleteConnection() error = %v", err)
+	}
+	if err := repo.DeleteConnection(ctx, "portfolio-1", c.ID); !errors.Is(err, exchange.ErrConnectionNotFound) {
+		t.Errorf("DeleteConnection() twice error = %v, want ErrConnectionNotFound", err)
+	}
+}
@@ -0,0 +1,307 @@
+package exchange
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+
+	"testtask/internal/domain/exchange"
+	"testtask/internal/domain/price"
+)
+
+// NewParser returns the parser for an exchange's statement exports. The mapping is
+// required for generic statements and ignored otherwise.
+func NewParser(e exchange.Exchange, mapping *exchange.Mapping) (exchange.Parser, error) {
+	switch e {
+	case exchange.Binance:
+		return BinanceParser{}, nil
+	case exchange.Coinbase:
+		return CoinbaseParser{}, nil
+	case exchange.Kraken:
+		return KrakenParser{}, nil
+	case exchange.Generic:
+		if err := mapping.Validate(); err != nil {
+			return nil, err
+		}
+		return GenericParser{Mapping: *mapping}, nil
+	}
+	return nil, fmt.Errorf("%w: %q", exchange.ErrUnsupportedExchange, e)
+}
+
+// quoteCurrencies maps the quote assets whose trades give a unit price usable for cost
+// basis to price currencies; stablecoins count as the currency they track
+var quoteCurrencies = map[string]string{
+	"USD":  "usd",
+	"USDT": "usd",
+	"USDC": "usd",
+	"BUSD": "usd",
+	"DAI":  "usd",
+	"EUR":  "eur",
+	"GBP":  "gbp",
+	"CAD":  "cad",
+	"AUD":  "aud",
+	"CHF":  "chf",
+	"JPY":  "jpy",
+}
+
+// knownQuotes are tried longest first to split concatenated pairs such as BTCUSDT
+var knownQuotes = []string{"USDT", "USDC", "BUSD", "FDUSD", "TUSD", "DAI", "USD", "EUR", "GBP", "CAD", "AUD", "CHF", "JPY", "TRY", "BTC", "ETH", "BNB", "XBT"}
+
+// splitPair splits a concatenated pair by its quote asset. Quotes leaving a base of at
+// least three characters win, so DOTUSD is DOT/USD rather than DO/TUSD.
+func splitPair(pair string) (base, quote string, ok bool) {
+	pair = strings.ToUpper(strings.NewReplacer("/", "", "-", "", "_", "").Replace(pair))
+	for _, q := range knownQuotes {
+		if !strings.HasSuffix(pair, q) || len(pair) == len(q) {
+			continue
+		}
+		if b := pair[:len(pair)-len(q)]; len(b) >= 3 || !ok {
+			base, quote, ok = b, q, true
+			if len(b) >= 3 {
+				break
+			}
+		}
+	}
+	return base, quote, ok
+}
+
+// table is a CSV statement with its header resolved to column positions
+type table struct {
+	columns map[string]int // lowercase header name -> position
+	rows    [][]string
+	lines   []int
+}
+
+// readTable reads a CSV statement. Lines before the first one isHeader accepts are
+// skipped, which drops the preambles some exchanges put above the header.
+func readTable(r io.Reader, isHeader func(columns map[string]int) bool) (*table, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	cr.LazyQuotes = true
+
+	t := &table{}
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("%w: %v", exchange.ErrInvalidStatement, err)
+		}
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+
+		if t.columns == nil {
+			columns := make(map[string]int, len(record))
+			for i, name := range record {
+				name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+				if _, seen := columns[name]; !seen {
+					columns[name] = i
+				}
+			}
+			if isHeader(columns) {
+				t.columns = columns
+			}
+			continue
+		}
+
+		line, _ := cr.FieldPos(0)
+		t.rows = append(t.rows, record)
+		t.lines = append(t.lines, line)
+	}
+	if t.columns == nil {
+		return nil, fmt.Errorf("%w: header not found", exchange.ErrInvalidStatement)
+	}
+	return t, nil
+}
+
+// has reports whether all named columns are present
+func has(columns map[string]int, names ...string) bool {
+	for _, name := range names {
+		if _, ok := columns[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// column returns the first of the named columns present in the header
+func (t *table) column(names ...string) (int, bool) {
+	for _, name := range names {
+		if i, ok := t.columns[strings.ToLower(name)]; ok {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// field returns the trimmed value of the first named column present in the row
+func (t *table) field(row []string, names ...string) string {
+	i, ok := t.column(names...)
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+// rowError prefixes a parse error with its line
+func rowError(line int, err error) error {
+	return fmt.Errorf("%w: line %d: %v", exchange.ErrInvalidStatement, line, err)
+}
+
+// rowIDs derives external IDs for rows without one; identical rows of one file get
+// distinct IDs so that repeated fills are kept while re-imports are still detected
+type rowIDs map[string]int
+
+func (ids rowIDs) next(fields ...string) string {
+	key := exchange.RowID(fields...)
+	ids[key]++
+	if n := ids[key]; n > 1 {
+		return exchange.RowID(append(fields, fmt.Sprint(n))...)
+	}
+	return key
+}
+
+// amountScale is 10^AmountDecimals
+var amountScale = new(big.Int).Exp(big.NewInt(10), big.NewInt(exchange.AmountDecimals), nil)
+
+// parseAmount parses a signed quantity, tolerating thousands separators, currency symbols
+// and exponents, into an integer scaled by AmountDecimals
+func parseAmount(s string) (*big.Int, error) {
+	r, err := parseNumber(s)
+	if err != nil {
+		return nil, err
+	}
+	scaled := r.Mul(r, new(big.Rat).SetInt(amountScale))
+	if !scaled.IsInt() {
+		return nil, fmt.Errorf("amount %q has more than %d fractional digits", s, exchange.AmountDecimals)
+	}
+	return new(big.Int).Set(scaled.Num()), nil
+}
+
+// parsePrice parses a unit price into an integer scaled by price.CurrencyDecimal,
+// truncating digits beyond that precision
+func parsePrice(s string) (*big.Int, error) {
+	r, err := parseNumber(s)
+	if err != nil {
+		return nil, err
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(price.CurrencyDecimal), nil)
+	scaled := r.Mul(r, new(big.Rat).SetInt(scale))
+	return new(big.Int).Quo(scaled.Num(), scaled.Denom()), nil
+}
+
+func parseNumber(s string) (*big.Rat, error) {
+	cleaned := strings.NewReplacer(",", "", "$", "", "€", "", "£", "", " ", "").Replace(strings.TrimSpace(s))
+	if cleaned == "" {
+		return nil, fmt.Errorf("missing number")
+	}
+	r, ok := new(big.Rat).SetString(cleaned)
+	if !ok {
+		return nil, fmt.Errorf("invalid number %q", s)
+	}
+	return r, nil
+}
+
+// splitQuantity splits a quantity with an asset suffix such as "0.01BTC". The expected
+// assets are matched first since tickers may start with a digit, e.g. 1INCH.
+func splitQuantity(s string, expected ...string) (*big.Int, string, error) {
+	s = strings.TrimSpace(s)
+	for _, asset := range expected {
+		if asset != "" && len(s) > len(asset) && strings.EqualFold(s[len(s)-len(asset):], asset) {
+			amount, err := parseAmount(s[:len(s)-len(asset)])
+			return amount, strings.ToUpper(asset), err
+		}
+	}
+	i := strings.LastIndexAny(s, "0123456789.")
+	if i < 0 || i == len(s)-1 {
+		return nil, "", fmt.Errorf("quantity %q has no asset", s)
+	}
+	amount, err := parseAmount(s[:i+1])
+	if err != nil {
+		return nil, "", err
+	}
+	return amount, strings.ToUpper(s[i+1:]), nil
+}
+
+// timeLayouts are tried in order when a format does not fix its time layout
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"01/02/2006 15:04:05",
+	"2006-01-02",
+}
+
+// parseTime parses a time in UTC unless the value carries its own zone
+func parseTime(s string, layouts ...string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if len(layouts) == 0 {
+		layouts = timeLayouts
+	}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", s)
+}
+
+// tradeRecords builds the legs and fee of a trade. base and quote are the signed changes
+// of the two assets; fee is positive and may be nil.
+func tradeRecords(e exchange.Exchange, tradeID string, at time.Time, baseAsset string, base *big.Int, quoteAsset string, quote *big.Int, feeAsset string, fee *big.Int, unitPrice string) ([]*exchange.Record, error) {
+	records := []*exchange.Record{
+		{Exchange: e, ExternalID: tradeID + ":" + baseAsset, TradeID: tradeID, Kind: exchange.KindTrade, Asset: baseAsset, Amount: base, OccurredAt: at},
+		{Exchange: e, ExternalID: tradeID + ":" + quoteAsset, TradeID: tradeID, Kind: exchange.KindTrade, Asset: quoteAsset, Amount: quote, OccurredAt: at},
+	}
+	if currency, ok := quoteCurrencies[quoteAsset]; ok && unitPrice != "" {
+		p, err := parsePrice(unitPrice)
+		if err != nil {
+			return nil, err
+		}
+		records[0].Price, records[0].Currency = p, currency
+	}
+	if fee != nil && fee.Sign() != 0 {
+		records = append(records, feeRecord(e, tradeID, at, feeAsset, fee))
+	}
+	return records, nil
+}
+
+// feeRecord builds the fee charged for the operation with the given ID
+func feeRecord(e exchange.Exchange, id string, at time.Time, asset string, fee *big.Int) *exchange.Record {
+	return &exchange.Record{
+		Exchange:   e,
+		ExternalID: id + ":fee:" + asset,
+		TradeID:    id,
+		Kind:       exchange.KindFee,
+		Asset:      asset,
+		Amount:     new(big.Int).Neg(new(big.Int).Abs(fee)),
+		OccurredAt: at,
+	}
+}
+
+// transferKind classifies a balance change that is neither a trade nor a fee by its sign
+func transferKind(amount *big.Int) exchange.Kind {
+	if amount.Sign() < 0 {
+		return exchange.KindWithdrawal
+	}
+	return exchange.KindDeposit
+}
+
+// validate checks every record before the statement is returned
+func validate(records []*exchange.Record) ([]*exchange.Record, error) {
+	for _, r := range records {
+		if err := r.Validate(); err != nil {
+			return nil, fmt.Errorf("%w (external id %s)", err, r.ExternalID)
+		}
+	}
+	return records, nil
+}
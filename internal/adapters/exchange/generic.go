@@ -0,0 +1,131 @@
+package exchange
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"testtask/internal/domain/exchange"
+)
+
+// GenericParser reads statements of any exchange by the columns named in its mapping.
+// Amounts may be signed or unsigned; deposits count as incoming and withdrawals and fees
+// as outgoing either way, while trade legs keep their sign.
+type GenericParser struct {
+	Mapping exchange.Mapping
+}
+
+func (p GenericParser) Parse(r io.Reader) ([]*exchange.Record, error) {
+	m := p.Mapping
+	required := []string{strings.ToLower(m.Time), strings.ToLower(m.Kind), strings.ToLower(m.Asset), strings.ToLower(m.Amount)}
+	t, err := readTable(r, func(c map[string]int) bool {
+		return has(c, required...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	kinds := make(map[string]exchange.Kind, len(m.Kinds))
+	for value, kind := range m.Kinds {
+		kinds[strings.ToLower(value)] = kind
+	}
+	var layouts []string
+	if m.TimeLayout != "" {
+		layouts = []string{m.TimeLayout}
+	}
+
+	ids := rowIDs{}
+	var records []*exchange.Record
+	for i, row := range t.rows {
+		parsed, err := p.parseRow(t, row, kinds, layouts, ids)
+		if err != nil {
+			return nil, rowError(t.lines[i], err)
+		}
+		records = append(records, parsed...)
+	}
+	return validate(records)
+}
+
+func (p GenericParser) parseRow(t *table, row []string, kinds map[string]exchange.Kind, layouts []string, ids rowIDs) ([]*exchange.Record, error) {
+	m := p.Mapping
+	at, err := parseTime(t.field(row, m.Time), layouts...)
+	if err != nil {
+		return nil, err
+	}
+
+	value := t.field(row, m.Kind)
+	kind, ok := kinds[strings.ToLower(value)]
+	if !ok {
+		if kind, err = exchange.ParseKind(value); err != nil {
+			return nil, err
+		}
+	}
+
+	amount, err := parseAmount(t.field(row, m.Amount))
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case exchange.KindDeposit:
+		amount.Abs(amount)
+	case exchange.KindWithdrawal, exchange.KindFee:
+		amount.Neg(amount.Abs(amount))
+	}
+
+	id := ""
+	if m.ID != "" {
+		id = t.field(row, m.ID)
+	}
+	if id == "" {
+		id = ids.next(string(exchange.Generic), strings.Join(row, ","))
+	}
+	asset := strings.ToUpper(t.field(row, m.Asset))
+
+	var records []*exchange.Record
+	if amount.Sign() != 0 {
+		record := &exchange.Record{
+			Exchange:   exchange.Generic,
+			ExternalID: id,
+			Kind:       kind,
+			Asset:      asset,
+			Amount:     amount,
+			OccurredAt: at,
+		}
+		if kind == exchange.KindTrade {
+			record.TradeID = id
+		}
+		if m.Note != "" {
+			record.Note = t.field(row, m.Note)
+		}
+		if m.Price != "" {
+			if unitPrice := t.field(row, m.Price); unitPrice != "" {
+				if record.Price, err = parsePrice(unitPrice); err != nil {
+					return nil, err
+				}
+				record.Currency = strings.ToLower(t.field(row, m.Currency))
+				if record.Currency == "" {
+					return nil, fmt.Errorf("price %q has no currency", unitPrice)
+				}
+			}
+		}
+		records = append(records, record)
+	}
+
+	if m.Fee != "" {
+		if value := t.field(row, m.Fee); value != "" {
+			fee, err := parseAmount(value)
+			if err != nil {
+				return nil, err
+			}
+			feeAsset := asset
+			if m.FeeAsset != "" && t.field(row, m.FeeAsset) != "" {
+				feeAsset = strings.ToUpper(t.field(row, m.FeeAsset))
+			}
+			if fee.Sign() != 0 {
+				records = append(records, feeRecord(exchange.Generic, id, at, feeAsset, new(big.Int).Set(fee)))
+			}
+		}
+	}
+	return records, nil
+}
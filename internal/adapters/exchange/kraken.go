@@ -0,0 +1,198 @@
+package exchange
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"testtask/internal/domain/exchange"
+)
+
+// KrakenParser reads Kraken exports: the ledger history (txid, refid, type, asset,
+// amount, fee) and the trade history (txid, pair, type, price, cost, fee, vol). Trade
+// legs and fees are keyed by the trade ID in both, so importing both exports of the
+// same period does not count trades twice.
+type KrakenParser struct{}
+
+func (KrakenParser) Parse(r io.Reader) ([]*exchange.Record, error) {
+	t, err := readTable(r, func(c map[string]int) bool {
+		return has(c, "txid", "refid", "type", "asset", "amount", "fee") || has(c, "txid", "pair", "type", "cost", "fee", "vol")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*exchange.Record
+	for i, row := range t.rows {
+		var parsed []*exchange.Record
+		if has(t.columns, "refid") {
+			parsed, err = parseKrakenLedgerRow(t, row)
+		} else {
+			parsed, err = parseKrakenTradeRow(t, row)
+		}
+		if err != nil {
+			return nil, rowError(t.lines[i], err)
+		}
+		records = append(records, parsed...)
+	}
+	return validate(records)
+}
+
+// krakenAssets maps Kraken's legacy asset codes to common tickers
+var krakenAssets = map[string]string{
+	"XXBT": "BTC",
+	"XBT":  "BTC",
+	"XETH": "ETH",
+	"XETC": "ETC",
+	"XLTC": "LTC",
+	"XXRP": "XRP",
+	"XXLM": "XLM",
+	"XXMR": "XMR",
+	"XZEC": "ZEC",
+	"XREP": "REP",
+	"XMLN": "MLN",
+	"XXDG": "DOGE",
+	"XDG":  "DOGE",
+	"ZUSD": "USD",
+	"ZEUR": "EUR",
+	"ZGBP": "GBP",
+	"ZCAD": "CAD",
+	"ZJPY": "JPY",
+	"ZAUD": "AUD",
+	"ZCHF": "CHF",
+}
+
+// krakenAsset normalizes an asset code. Staked and opt-in rewards variants such as
+// ETH2.S or DOT.S count as the underlying asset.
+func krakenAsset(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if base, suffix, ok := strings.Cut(code, "."); ok && (suffix == "S" || suffix == "M" || suffix == "F" || suffix == "P" || suffix == "B") {
+		code = base
+	}
+	if code == "ETH2" {
+		return "ETH"
+	}
+	if mapped, ok := krakenAssets[code]; ok {
+		return mapped
+	}
+	return code
+}
+
+// splitKrakenPair splits pairs such as XXBTZUSD, XETHXXBT or DOTUSD
+func splitKrakenPair(pair string) (base, quote string, ok bool) {
+	pair = strings.ToUpper(strings.ReplaceAll(pair, "/", ""))
+	if len(pair) == 8 && strings.ContainsRune("XZ", rune(pair[0])) && strings.ContainsRune("XZ", rune(pair[4])) {
+		if _, known := krakenAssets[pair[:4]]; known {
+			return krakenAsset(pair[:4]), krakenAsset(pair[4:]), true
+		}
+	}
+	base, quote, ok = splitPair(pair)
+	if !ok {
+		return "", "", false
+	}
+	return krakenAsset(base), krakenAsset(quote), true
+}
+
+func parseKrakenLedgerRow(t *table, row []string) ([]*exchange.Record, error) {
+	at, err := parseTime(t.field(row, "time"))
+	if err != nil {
+		return nil, err
+	}
+	txid, refid := t.field(row, "txid"), t.field(row, "refid")
+	if txid == "" {
+		// Kraken lists the unconfirmed half of a transfer without a ledger ID
+		return nil, nil
+	}
+	asset := krakenAsset(t.field(row, "asset"))
+	amount, err := parseAmount(t.field(row, "amount"))
+	if err != nil {
+		return nil, err
+	}
+	fee := new(big.Int)
+	if value := t.field(row, "fee"); value != "" {
+		if fee, err = parseAmount(value); err != nil {
+			return nil, err
+		}
+	}
+
+	entryType := strings.ToLower(t.field(row, "type"))
+	var records []*exchange.Record
+	switch entryType {
+	case "trade", "spend", "receive":
+		if amount.Sign() != 0 {
+			records = append(records, &exchange.Record{
+				Exchange:   exchange.Kraken,
+				ExternalID: refid + ":" + asset,
+				TradeID:    refid,
+				Kind:       exchange.KindTrade,
+				Asset:      asset,
+				Amount:     amount,
+				OccurredAt: at,
+			})
+		}
+		if fee.Sign() != 0 {
+			records = append(records, feeRecord(exchange.Kraken, refid, at, asset, fee))
+		}
+		return records, nil
+	}
+
+	// Moves between spot and staking wallets pair up and cancel out
+	if entryType == "transfer" && strings.Contains(strings.ToLower(t.field(row, "subtype")), "spot") {
+		return nil, nil
+	}
+	if amount.Sign() != 0 {
+		record := &exchange.Record{
+			Exchange:   exchange.Kraken,
+			ExternalID: txid,
+			Asset:      asset,
+			Amount:     amount,
+			OccurredAt: at,
+		}
+		switch entryType {
+		case "deposit":
+			record.Kind = exchange.KindDeposit
+		case "withdrawal":
+			record.Kind = exchange.KindWithdrawal
+		default:
+			// Staking rewards, earn payouts, airdrops and adjustments
+			record.Kind = transferKind(amount)
+			record.Note = entryType
+		}
+		records = append(records, record)
+	}
+	if fee.Sign() != 0 {
+		records = append(records, feeRecord(exchange.Kraken, txid, at, asset, fee))
+	}
+	return records, nil
+}
+
+func parseKrakenTradeRow(t *table, row []string) ([]*exchange.Record, error) {
+	at, err := parseTime(t.field(row, "time"))
+	if err != nil {
+		return nil, err
+	}
+	base, quote, ok := splitKrakenPair(t.field(row, "pair"))
+	if !ok {
+		return nil, fmt.Errorf("unknown pair %q", t.field(row, "pair"))
+	}
+	volume, err := parseAmount(t.field(row, "vol"))
+	if err != nil {
+		return nil, err
+	}
+	cost, err := parseAmount(t.field(row, "cost"))
+	if err != nil {
+		return nil, err
+	}
+	var fee *big.Int
+	if value := t.field(row, "fee"); value != "" {
+		if fee, err = parseAmount(value); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := applySide(strings.ToUpper(t.field(row, "type")), volume, cost); err != nil {
+		return nil, err
+	}
+	return tradeRecords(exchange.Kraken, t.field(row, "txid"), at, base, volume, quote, cost, quote, fee, t.field(row, "price"))
+}
@@ -0,0 +1,232 @@
+package exchange
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"testtask/internal/domain/exchange"
+)
+
+// describe renders a record as "kind asset amount" with the amount in asset units
+func describe(r *exchange.Record) string {
+	amount := new(big.Rat).SetFrac(r.Amount, amountScale)
+	return fmt.Sprintf("%s %s %s", r.Kind, r.Asset, amount.FloatString(4))
+}
+
+func TestParsers(t *testing.T) {
+	tests := []struct {
+		name      string
+		exchange  exchange.Exchange
+		mapping   *exchange.Mapping
+		csv       string
+		want      []string
+		wantPrice string // price of the first record, scaled by price.CurrencyDecimal
+		wantErr   error
+	}{
+		{
+			name:     "binance trade history",
+			exchange: exchange.Binance,
+			csv: "Date(UTC),Pair,Side,Price,Executed,Amount,Fee\n" +
+				"2024-01-02 10:00:00,BTCUSDT,BUY,42000,0.01BTC,420USDT,0.00001BTC\n" +
+				"2024-01-03 11:30:00,1INCHUSDT,SELL,0.5,100.51INCH,50.25USDT,0.05USDT\n",
+			want: []string{
+				"trade BTC 0.0100", "trade USDT -420.0000", "fee BTC -0.0000",
+				"trade 1INCH -100.5000", "trade USDT 50.2500", "fee USDT -0.0500",
+			},
+			wantPrice: "4200000000000",
+		},
+		{
+			name:     "binance legacy trade history",
+			exchange: exchange.Binance,
+			csv: "Date(UTC),Market,Type,Price,Amount,Total,Fee,Fee Coin\n" +
+				"2021-05-01 08:00:00,ETHBTC,SELL,0.05,2,0.1,0.0001,BTC\n",
+			want: []string{"trade ETH -2.0000", "trade BTC 0.1000", "fee BTC -0.0001"},
+		},
+		{
+			name:     "binance transaction statement",
+			exchange: exchange.Binance,
+			csv: "User_ID,UTC_Time,Account,Operation,Coin,Change,Remark\n" +
+				"1,2024-01-01 00:00:00,Spot,Deposit,USDT,1000,\n" +
+				"1,2024-01-02 00:00:00,Spot,Transaction Buy,ETH,0.5,\n" +
+				"1,2024-01-02 00:00:00,Spot,Transaction Spend,USDT,-1100,\n" +
+				"1,2024-01-02 00:00:00,Spot,Transaction Fee,ETH,-0.0005,\n" +
+				"1,2024-01-03 00:00:00,Spot,Transfer Between Main and Funding Wallet,ETH,-0.1,\n" +
+				"1,2024-01-04 00:00:00,Earn,Simple Earn Flexible Interest,USDT,0.25,\n" +
+				"1,2024-01-05 00:00:00,Spot,Withdraw,ETH,-0.2,\n",
+			want: []string{
+				"deposit USDT 1000.0000", "trade ETH 0.5000", "trade USDT -1100.0000", "fee ETH -0.0005",
+				"deposit USDT 0.2500", "withdrawal ETH -0.2000",
+			},
+		},
+		{
+			name:     "coinbase transaction history with preamble",
+			exchange: exchange.Coinbase,
+			csv: "You can use this transaction report to inform your likely tax obligations.\n" +
+				"\n" +
+				"ID,Timestamp,Transaction Type,Asset,Quantity Transacted,Price Currency,Price at Transaction,Subtotal,Total (inclusive of fees and/or spread),Fees and/or Spread,Notes\n" +
+				"a1,2024-01-05 10:00:00 UTC,Buy,ETH,0.5,USD,\"$2,000.00\",\"$1,000.00\",\"$1,014.90\",$14.90,Bought 0.5 ETH\n" +
+				"a2,2024-02-01 10:00:00 UTC,Convert,ETH,0.1,USD,$2500,$250,$252,$2,\"Converted 0.1 ETH to 250.5 USDC\"\n" +
+				"a3,2024-03-01 10:00:00 UTC,Send,ETH,-0.2,USD,$3000,$600,$600,$0,Sent to wallet\n" +
+				"a4,2024-03-02 10:00:00 UTC,Staking Income,ETH,0.001,USD,$3000,$3,$3,$0,\n",
+			want: []string{
+				"trade ETH 0.5000", "trade USD -1000.0000", "fee USD -14.9000",
+				"trade USDC 250.5000", "trade ETH -0.1000", "fee USD -2.0000",
+				"withdrawal ETH -0.2000",
+				"deposit ETH 0.0010",
+			},
+			wantPrice: "200000000000",
+		},
+		{
+			name:     "kraken ledgers",
+			exchange: exchange.Kraken,
+			csv: "txid,refid,time,type,subtype,aclass,asset,amount,fee,balance\n" +
+				"L1,D1,2024-01-01 00:00:00,deposit,,currency,ZUSD,5000.0000,0.0000,5000.0000\n" +
+				"L2,T1,2024-01-02 00:00:00,trade,,currency,ZUSD,-4000.0000,8.0000,992.0000\n" +
+				"L3,T1,2024-01-02 00:00:00,trade,,currency,XXBT,0.1000000000,0.0000000000,0.1000000000\n" +
+				"L4,S1,2024-01-03 00:00:00,transfer,spottostaking,currency,XETH,-1.0000,0,0\n" +
+				"L5,S2,2024-01-04 00:00:00,staking,,currency,ETH2.S,0.0025,0,1.0025\n" +
+				"L6,W1,2024-01-05 00:00:00,withdrawal,,currency,XXBT,-0.0500,0.0002,0.0498\n",
+			want: []string{
+				"deposit USD 5000.0000", "trade USD -4000.0000", "fee USD -8.0000", "trade BTC 0.1000",
+				"deposit ETH 0.0025", "withdrawal BTC -0.0500", "fee BTC -0.0002",
+			},
+		},
+		{
+			name:     "kraken trades",
+			exchange: exchange.Kraken,
+			csv: "txid,ordertxid,pair,time,type,ordertype,price,cost,fee,vol,margin,misc,ledgers\n" +
+				"T1,O1,XXBTZUSD,2024-01-02 00:00:00.1234,buy,limit,40000.0,4000.0,8.0,0.1,0,,\"L2,L3\"\n" +
+				"T2,O2,DOTUSD,2024-01-06 00:00:00,sell,market,7.5,75,0.2,10,0,,\n",
+			want: []string{
+				"trade BTC 0.1000", "trade USD -4000.0000", "fee USD -8.0000",
+				"trade DOT -10.0000", "trade USD 75.0000", "fee USD -0.2000",
+			},
+			wantPrice: "4000000000000",
+		},
+		{
+			name:     "generic mapping",
+			exchange: exchange.Generic,
+			mapping: &exchange.Mapping{
+				Time: "When", Kind: "Action", Asset: "Coin", Amount: "Qty", Fee: "Fee", Price: "Unit", Currency: "Ccy",
+				TimeLayout: "02.01.2006 15:04", Kinds: map[string]exchange.Kind{"IN": exchange.KindDeposit, "OUT": exchange.KindWithdrawal},
+			},
+			csv: "When,Action,Coin,Qty,Fee,Unit,Ccy\n" +
+				"01.02.2024 09:30,IN,SOL,12.5,,95.10,EUR\n" +
+				"02.02.2024 09:30,OUT,SOL,2,0.01,,\n",
+			want:      []string{"deposit SOL 12.5000", "withdrawal SOL -2.0000", "fee SOL -0.0100"},
+			wantPrice: "9510000000",
+		},
+		{
+			name:     "generic without mapping",
+			exchange: exchange.Generic,
+			csv:      "a,b\n",
+			wantErr:  exchange.ErrInvalidStatement,
+		},
+		{
+			name:     "unknown header",
+			exchange: exchange.Kraken,
+			csv:      "Date,Amount\n2024-01-01,1\n",
+			wantErr:  exchange.ErrInvalidStatement,
+		},
+		{
+			name:     "invalid amount",
+			exchange: exchange.Binance,
+			csv:      "User_ID,UTC_Time,Account,Operation,Coin,Change,Remark\n1,2024-01-01 00:00:00,Spot,Deposit,USDT,lots,\n",
+			wantErr:  exchange.ErrInvalidStatement,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser, err := NewParser(tt.exchange, tt.mapping)
+			var records []*exchange.Record
+			if err == nil {
+				records, err = parser.Parse(strings.NewReader(tt.csv))
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			got := make([]string, len(records))
+			for i, r := range records {
+				got[i] = describe(r)
+				if r.Exchange != tt.exchange || r.ExternalID == "" || r.OccurredAt.IsZero() {
+					t.Errorf("record %d = %+v, want exchange, external id and time set", i, r)
+				}
+			}
+			if strings.Join(got, "; ") != strings.Join(tt.want, "; ") {
+				t.Errorf("Parse() =\n  %s\nwant\n  %s", strings.Join(got, "; "), strings.Join(tt.want, "; "))
+			}
+			if tt.wantPrice != "" && (records[0].Price == nil || records[0].Price.String() != tt.wantPrice) {
+				t.Errorf("records[0].Price = %v, want %s", records[0].Price, tt.wantPrice)
+			}
+		})
+	}
+}
+
+func TestParsers_StableExternalIDs(t *testing.T) {
+	// Identical fills in one file are kept apart, and a re-import yields the same IDs
+	csv := "Date(UTC),Pair,Side,Price,Executed,Amount,Fee\n" +
+		"2024-01-02 10:00:00,ETHUSDT,BUY,2000,1ETH,2000USDT,\n" +
+		"2024-01-02 10:00:00,ETHUSDT,BUY,2000,1ETH,2000USDT,\n"
+
+	first, err := BinanceParser{}.Parse(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	second, err := BinanceParser{}.Parse(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	ids := make(map[string]bool)
+	for i, r := range first {
+		ids[r.ExternalID] = true
+		if second[i].ExternalID != r.ExternalID {
+			t.Errorf("re-parsed external id %d = %s, want %s", i, second[i].ExternalID, r.ExternalID)
+		}
+	}
+	if len(ids) != 4 {
+		t.Errorf("got %d distinct external ids for 4 legs, want 4", len(ids))
+	}
+}
+
+func TestKrakenParser_TradesMatchLedger(t *testing.T) {
+	ledger := "txid,refid,time,type,subtype,aclass,asset,amount,fee,balance\n" +
+		"L2,T1,2024-01-02 00:00:00,trade,,currency,ZUSD,-4000.0000,8.0000,992.0000\n" +
+		"L3,T1,2024-01-02 00:00:00,trade,,currency,XXBT,0.1000000000,0.0000000000,0.1000000000\n"
+	trades := "txid,ordertxid,pair,time,type,ordertype,price,cost,fee,vol,margin,misc,ledgers\n" +
+		"T1,O1,XXBTZUSD,2024-01-02 00:00:00,buy,limit,40000.0,4000.0,8.0,0.1,0,,\n"
+
+	fromLedger, err := KrakenParser{}.Parse(strings.NewReader(ledger))
+	if err != nil {
+		t.Fatalf("Parse(ledger) error = %v", err)
+	}
+	fromTrades, err := KrakenParser{}.Parse(strings.NewReader(trades))
+	if err != nil {
+		t.Fatalf("Parse(trades) error = %v", err)
+	}
+
+	ids := make(map[string]bool)
+	for _, r := range fromLedger {
+		ids[r.ExternalID] = true
+	}
+	for _, r := range fromTrades {
+		if !ids[r.ExternalID] {
+			t.Errorf("trade record %s (%s) has no ledger counterpart", r.ExternalID, describe(r))
+		}
+	}
+	if at := fromTrades[0].OccurredAt; !at.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("OccurredAt = %v, want 2024-01-02 UTC", at)
+	}
+}
@@ -0,0 +1,140 @@
+package exchange
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	sqliteadapter "testtask/internal/adapters/sqlite"
+	"testtask/internal/domain/exchange"
+)
+
+// SQLiteRepository implements exchange.Repository on top of the exchange_records table
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteRepository(db *sql.DB) *SQLiteRepository {
+	return &SQLiteRepository{db: db}
+}
+
+// SaveRecords stores the records in one transaction. Rows whose external ID is already
+// stored for the portfolio and exchange are skipped by the unique index.
+func (r *SQLiteRepository) SaveRecords(ctx context.Context, records []*exchange.Record) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT OR IGNORE INTO exchange_records (id, portfolio_id, exchange, external_id, trade_id, kind, asset, amount, price, currency, note, occurred_at, imported_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare exchange record insert: %w", err)
+	}
+	defer stmt.Close()
+
+	saved := 0
+	for _, rec := range records {
+		var priceArg interface{}
+		if rec.Price != nil {
+			priceArg = rec.Price.String()
+		}
+		result, err := stmt.ExecContext(ctx,
+			rec.ID, rec.PortfolioID, string(rec.Exchange), rec.ExternalID, rec.TradeID, string(rec.Kind), rec.Asset,
+			rec.Amount.String(), priceArg, rec.Currency, rec.Note,
+			rec.OccurredAt.UTC().Format(time.RFC3339Nano), rec.ImportedAt.UTC().Format(time.RFC3339Nano),
+		)
+		if err != nil {
+			return 0, fmt.Errorf("failed to save exchange record: %w", err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("failed to save exchange record: %w", err)
+		}
+		saved += int(affected)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit exchange records: %w", err)
+	}
+	return saved, nil
+}
+
+// ListRecords returns the portfolio's matching records, oldest first
+func (r *SQLiteRepository) ListRecords(ctx context.Context, portfolioID string, filter exchange.Filter) ([]*exchange.Record, error) {
+	query := `
+		SELECT id, portfolio_id, exchange, external_id, trade_id, kind, asset, amount, COALESCE(price, ''), currency, note, occurred_at, imported_at
+		FROM exchange_records
+		WHERE portfolio_id = ?
+	`
+	args := []interface{}{portfolioID}
+	if filter.Exchange != "" {
+		query += " AND exchange = ?"
+		args = append(args, string(filter.Exchange))
+	}
+	if filter.Kind != "" {
+		query += " AND kind = ?"
+		args = append(args, string(filter.Kind))
+	}
+	if filter.Asset != "" {
+		query += " AND asset = ?"
+		args = append(args, strings.ToUpper(filter.Asset))
+	}
+	if filter.From != nil {
+		query += " AND julianday(occurred_at) >= julianday(?)"
+		args = append(args, filter.From.UTC().Format(time.RFC3339Nano))
+	}
+	if filter.To != nil {
+		query += " AND julianday(occurred_at) < julianday(?)"
+		args = append(args, filter.To.UTC().Format(time.RFC3339Nano))
+	}
+	scope, scopeArgs := sqliteadapter.PortfolioScope(ctx, "portfolio_id")
+	query += scope + " ORDER BY julianday(occurred_at) ASC, trade_id ASC, external_id ASC"
+	args = append(args, scopeArgs...)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exchange records: %w", err)
+	}
+	defer rows.Close()
+
+	var records []*exchange.Record
+	for rows.Next() {
+		var (
+			rec                           exchange.Record
+			exch, kind, amount, unitPrice string
+			occurredAt, importedAt        string
+		)
+		if err := rows.Scan(&rec.ID, &rec.PortfolioID, &exch, &rec.ExternalID, &rec.TradeID, &kind, &rec.Asset, &amount, &unitPrice, &rec.Currency, &rec.Note, &occurredAt, &importedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan exchange record: %w", err)
+		}
+		rec.Exchange, rec.Kind = exchange.Exchange(exch), exchange.Kind(kind)
+
+		var ok bool
+		if rec.Amount, ok = new(big.Int).SetString(amount, 10); !ok {
+			return nil, fmt.Errorf("invalid amount %q of exchange record %s", amount, rec.ID)
+		}
+		if unitPrice != "" {
+			if rec.Price, ok = new(big.Int).SetString(unitPrice, 10); !ok {
+				return nil, fmt.Errorf("invalid price %q of exchange record %s", unitPrice, rec.ID)
+			}
+		}
+		if rec.OccurredAt, err = time.Parse(time.RFC3339Nano, occurredAt); err != nil {
+			return nil, fmt.Errorf("failed to parse exchange record time: %w", err)
+		}
+		if rec.ImportedAt, err = time.Parse(time.RFC3339Nano, importedAt); err != nil {
+			return nil, fmt.Errorf("failed to parse exchange record import time: %w", err)
+		}
+		records = append(records, &rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list exchange records: %w", err)
+	}
+	return records, nil
+}
@@ -0,0 +1,103 @@
+package exchange
+
+import (
+	"context"
+	"database/sql"
+	"math/big"
+	"testing"
+	"time"
+
+	"testtask/internal/domain/exchange"
+	"testtask/internal/domain/user"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// setupTestDB creates an in-memory SQLite database with the exchange records schema
+func setupTestDB(t *testing.T) (*SQLiteRepository, func()) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	// Every connection to :memory: is a separate database
+	db.SetMaxOpenConns(1)
+
+	schema := `
+	CREATE TABLE IF NOT EXISTS exchange_records (
+		id TEXT PRIMARY KEY,
+		portfolio_id TEXT NOT NULL,
+		exchange TEXT NOT NULL,
+		external_id TEXT NOT NULL,
+		trade_id TEXT NOT NULL DEFAULT '',
+		kind TEXT NOT NULL,
+		asset TEXT NOT NULL,
+		amount TEXT NOT NULL,
+		price TEXT,
+		currency TEXT NOT NULL DEFAULT '',
+		note TEXT NOT NULL DEFAULT '',
+		occurred_at DATETIME NOT NULL,
+		imported_at DATETIME NOT NULL
+	);
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_exchange_records_external_id ON exchange_records(portfolio_id, exchange, external_id);
+	`
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	return NewSQLiteRepository(db), func() { db.Close() }
+}
+
+func TestSQLiteRepository_SaveRecords(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := user.AsSystem(context.Background())
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	statement := func() []*exchange.Record {
+		records := []*exchange.Record{
+			{Exchange: exchange.Kraken, ExternalID: "L1", Kind: exchange.KindDeposit, Asset: "USD", Amount: big.NewInt(5000), OccurredAt: base},
+			{Exchange: exchange.Kraken, ExternalID: "T1:BTC", TradeID: "T1", Kind: exchange.KindTrade, Asset: "BTC", Amount: big.NewInt(1), Price: big.NewInt(4000000000000), Currency: "usd", OccurredAt: base.Add(time.Hour)},
+			{Exchange: exchange.Kraken, ExternalID: "T1:USD", TradeID: "T1", Kind: exchange.KindTrade, Asset: "USD", Amount: big.NewInt(-4000), OccurredAt: base.Add(time.Hour)},
+		}
+		exchange.Assign(records, "portfolio-1", base)
+		return records
+	}
+
+	saved, err := repo.SaveRecords(ctx, statement())
+	if err != nil || saved != 3 {
+		t.Fatalf("SaveRecords() = %d, %v, want 3", saved, err)
+	}
+	// The same rows imported again, plus one for another portfolio
+	again := statement()
+	other := statement()[:1]
+	exchange.Assign(other, "portfolio-2", base)
+	saved, err = repo.SaveRecords(ctx, append(again, other...))
+	if err != nil || saved != 1 {
+		t.Fatalf("SaveRecords() re-import = %d, %v, want 1", saved, err)
+	}
+
+	records, err := repo.ListRecords(ctx, "portfolio-1", exchange.Filter{})
+	if err != nil {
+		t.Fatalf("ListRecords() error = %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("ListRecords() returned %d records, want 3", len(records))
+	}
+	if got := records[1]; got.ExternalID != "T1:BTC" || got.Price == nil || got.Price.Int64() != 4000000000000 || got.Currency != "usd" || !got.OccurredAt.Equal(base.Add(time.Hour)) {
+		t.Errorf("records[1] = %+v, want the BTC leg with its price", got)
+	}
+	if got := records[2]; got.Amount.Int64() != -4000 || got.Price != nil {
+		t.Errorf("records[2] = %+v, want the USD leg without price", got)
+	}
+
+	from := base.Add(time.Minute)
+	filtered, err := repo.ListRecords(ctx, "portfolio-1", exchange.Filter{Kind: exchange.KindTrade, Asset: "usd", From: &from})
+	if err != nil {
+		t.Fatalf("ListRecords() filtered error = %v", err)
+	}
+	if len(filtered) != 1 || filtered[0].ExternalID != "T1:USD" {
+		t.Errorf("ListRecords() filtered = %d records, want T1:USD only", len(filtered))
+	}
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"testtask/internal/domain/addressbook"
+	httpports "testtask/internal/ports/http"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// ListKnownAddresses handles GET /api/v1/address-book/known with an optional category
+func (h *HandlerAdapter) ListKnownAddresses(c echo.Context) error {
+	category := addressbook.Category(strings.ToLower(strings.TrimSpace(c.QueryParam("category"))))
+	if category != "" && !category.Valid() {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "unknown category " + string(category),
+		})
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPKnownAddresses(h.addressBookService.KnownAddresses(category)))
+}
+
+// ListAddressBook handles GET /api/v1/address-book
+func (h *HandlerAdapter) ListAddressBook(c echo.Context) error {
+	entries, err := h.addressBookService.ListEntries(c.Request().Context(), currentUser(c).ID)
+	if err != nil {
+		return h.addressBookError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPAddressBookEntries(entries))
+}
+
+// CreateAddressBookEntry handles POST /api/v1/address-book
+func (h *HandlerAdapter) CreateAddressBookEntry(c echo.Context) error {
+	var req httpports.AddressBookEntryRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "invalid request body",
+		})
+	}
+
+	entry, err := h.addressBookService.CreateEntry(c.Request().Context(), currentUser(c).ID, req.Address, req.Label, req.Note)
+	if err != nil {
+		return h.addressBookError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, httpports.ToHTTPAddressBookEntry(entry))
+}
+
+// UpdateAddressBookEntry handles PUT /api/v1/address-book/:entryID
+func (h *HandlerAdapter) UpdateAddressBookEntry(c echo.Context) error {
+	var req httpports.AddressBookEntryRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "invalid request body",
+		})
+	}
+
+	entry, err := h.addressBookService.UpdateEntry(c.Request().Context(), currentUser(c).ID, c.Param("entryID"), req.Address, req.Label, req.Note)
+	if err != nil {
+		return h.addressBookError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPAddressBookEntry(entry))
+}
+
+// DeleteAddressBookEntry handles DELETE /api/v1/address-book/:entryID
+func (h *HandlerAdapter) DeleteAddressBookEntry(c echo.Context) error {
+	if err := h.addressBookService.DeleteEntry(c.Request().Context(), currentUser(c).ID, c.Param("entryID")); err != nil {
+		return h.addressBookError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *HandlerAdapter) addressBookError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, addressbook.ErrInvalidEntry):
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: err.Error(),
+		})
+	case errors.Is(err, addressbook.ErrEntryNotFound):
+		return c.JSON(http.StatusNotFound, httpports.ErrorResponse{
+			Error:   "Not Found",
+			Message: "address book entry not found",
+		})
+	case errors.Is(err, addressbook.ErrEntryExists):
+		return c.JSON(http.StatusConflict, httpports.ErrorResponse{
+			Error:   "Conflict",
+			Message: "the address is already in the address book",
+		})
+	}
+
+	h.logger.Error("Failed to change address book", zap.Error(err))
+	return c.JSON(http.StatusInternalServerError, httpports.ErrorResponse{
+		Error:   "Internal Server Error",
+		Message: "failed to change address book",
+	})
+}
@@ -0,0 +1,34 @@
+package server
+
+import (
+	"net/http"
+
+	"testtask/internal/domain/token"
+	httpports "testtask/internal/ports/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ReloadTokens handles POST /api/v1/admin/tokens/reload.
+// A failed reload is reported in the body with status 500; the previous registry stays in use.
+func (h *HandlerAdapter) ReloadTokens(c echo.Context) error {
+	result, err := h.tokenAdminService.ReloadTokens(c.Request().Context(), token.ReloadTriggerAPI)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, httpports.ToHTTPTokenReloadResult(result))
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPTokenReloadResult(result))
+}
+
+// GetTokenReload handles GET /api/v1/admin/tokens/reload and returns the last reload result
+func (h *HandlerAdapter) GetTokenReload(c echo.Context) error {
+	result := h.tokenAdminService.LastTokenReload()
+	if result == nil {
+		return c.JSON(http.StatusNotFound, httpports.ErrorResponse{
+			Error:   "Not Found",
+			Message: "token registry has not been reloaded yet",
+		})
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPTokenReloadResult(result))
+}
@@ -0,0 +1,181 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"testtask/internal/domain/portfolio"
+	"testtask/internal/domain/price"
+	"testtask/internal/domain/transaction"
+	httpports "testtask/internal/ports/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ListAnnotations handles GET /api/v1/portfolio/:portfolioID/annotations
+func (h *HandlerAdapter) ListAnnotations(c echo.Context) error {
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleViewer); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	annotations, err := h.transactionService.ListAnnotations(c.Request().Context(), portfolioID)
+	if err != nil {
+		return h.annotationError(c, portfolioID, err)
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPAnnotations(annotations))
+}
+
+// SetAnnotation handles PUT /api/v1/portfolio/:portfolioID/annotations/:transactionID
+func (h *HandlerAdapter) SetAnnotation(c echo.Context) error {
+	var req httpports.AnnotationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "invalid request body",
+		})
+	}
+
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleEditor); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	annotation := &transaction.Annotation{
+		PortfolioID:   portfolioID,
+		TransactionID: c.Param("transactionID"),
+		Note:          req.Note,
+		Tags:          req.Tags,
+		Category:      req.Category,
+		TypeOverride:  transaction.TransactionType(req.Type),
+	}
+	if err := h.transactionService.SetAnnotation(c.Request().Context(), annotation); err != nil {
+		return h.annotationError(c, portfolioID, err)
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPAnnotation(annotation))
+}
+
+// DeleteAnnotation handles DELETE /api/v1/portfolio/:portfolioID/annotations/:transactionID
+func (h *HandlerAdapter) DeleteAnnotation(c echo.Context) error {
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleEditor); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	if err := h.transactionService.DeleteAnnotation(c.Request().Context(), portfolioID, c.Param("transactionID")); err != nil {
+		return h.annotationError(c, portfolioID, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListManualTransactions handles GET /api/v1/portfolio/:portfolioID/manual-transactions
+func (h *HandlerAdapter) ListManualTransactions(c echo.Context) error {
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleViewer); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	txs, err := h.transactionService.ListManualTransactions(c.Request().Context(), portfolioID)
+	if err != nil {
+		return h.annotationError(c, portfolioID, err)
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPManualTransactions(txs))
+}
+
+// CreateManualTransaction handles POST /api/v1/portfolio/:portfolioID/manual-transactions
+func (h *HandlerAdapter) CreateManualTransaction(c echo.Context) error {
+	var req httpports.ManualTransactionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "invalid request body",
+		})
+	}
+
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleEditor); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	tx, err := toDomainManualTransaction(req)
+	if err != nil {
+		return h.annotationError(c, portfolioID, err)
+	}
+	if err := h.transactionService.CreateManualTransaction(c.Request().Context(), portfolioID, tx); err != nil {
+		return h.annotationError(c, portfolioID, err)
+	}
+
+	return c.JSON(http.StatusCreated, httpports.ToHTTPManualTransaction(tx))
+}
+
+// DeleteManualTransaction handles DELETE /api/v1/portfolio/:portfolioID/manual-transactions/:transactionID
+func (h *HandlerAdapter) DeleteManualTransaction(c echo.Context) error {
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleEditor); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	if err := h.transactionService.DeleteManualTransaction(c.Request().Context(), portfolioID, c.Param("transactionID")); err != nil {
+		return h.annotationError(c, portfolioID, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func toDomainManualTransaction(req httpports.ManualTransactionRequest) (*transaction.Transaction, error) {
+	amount, err := httpports.ParseDecimal(req.Amount, int(req.TokenDecimals))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid amount: %v", transaction.ErrInvalidManualTransaction, err)
+	}
+	tx, err := transaction.NewManualTransaction(
+		req.TokenAddress,
+		req.TokenSymbol,
+		req.TokenDecimals,
+		amount,
+		transaction.TransactionDirection(strings.ToLower(req.Direction)),
+		transaction.TransactionType(strings.ToLower(req.Type)),
+		req.Timestamp,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if tx.Direction == transaction.TransactionDirectionIn {
+		tx.From = req.Counterparty
+	} else {
+		tx.To = req.Counterparty
+	}
+	if req.Price != "" {
+		if req.Currency == "" {
+			return nil, fmt.Errorf("%w: currency is required with price", transaction.ErrInvalidManualTransaction)
+		}
+		if tx.Price, err = httpports.ParseDecimal(req.Price, price.CurrencyDecimal); err != nil {
+			return nil, fmt.Errorf("%w: invalid price: %v", transaction.ErrInvalidManualTransaction, err)
+		}
+		tx.PriceCurrency = strings.ToUpper(strings.TrimSpace(req.Currency))
+	}
+	return tx, nil
+}
+
+func (h *HandlerAdapter) annotationError(c echo.Context, portfolioID string, err error) error {
+	switch {
+	case errors.Is(err, transaction.ErrInvalidAnnotation), errors.Is(err, transaction.ErrInvalidManualTransaction):
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: err.Error(),
+		})
+	case errors.Is(err, transaction.ErrAnnotationNotFound), errors.Is(err, transaction.ErrManualTransactionNotFound):
+		return c.JSON(http.StatusNotFound, httpports.ErrorResponse{
+			Error:   "Not Found",
+			Message: err.Error(),
+		})
+	}
+
+	return h.portfolioChangeError(c, portfolioID, err)
+}
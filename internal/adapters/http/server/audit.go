@@ -0,0 +1,75 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"testtask/internal/domain/audit"
+	"testtask/internal/domain/portfolio"
+	httpports "testtask/internal/ports/http"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// GetAuditLog handles GET /api/v1/audit?portfolio_id=&from=&to=&limit=.
+// With portfolio_id it returns the portfolio's history and requires the admin role;
+// without it, the changes made by the caller. from and to are RFC3339 times.
+func (h *HandlerAdapter) GetAuditLog(c echo.Context) error {
+	filter := audit.Filter{PortfolioID: c.QueryParam("portfolio_id")}
+	if filter.PortfolioID != "" {
+		if err := h.authorize(c, filter.PortfolioID, portfolio.RoleAdmin); err != nil {
+			return h.portfolioLookupError(c, filter.PortfolioID, err)
+		}
+	} else {
+		filter.ActorID = currentUser(c).ID
+	}
+
+	for _, param := range []struct {
+		name string
+		dst  **time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		value := c.QueryParam(param.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+				Error:   "Bad Request",
+				Message: param.name + " must be an RFC3339 time",
+			})
+		}
+		*param.dst = &parsed
+	}
+
+	if limitParam := c.QueryParam("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 {
+			return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+				Error:   "Bad Request",
+				Message: "limit must be a positive integer",
+			})
+		}
+		filter.Limit = limit
+	}
+
+	entries, err := h.auditService.List(c.Request().Context(), filter)
+	if err != nil {
+		if errors.Is(err, audit.ErrInvalidFilter) {
+			return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+				Error:   "Bad Request",
+				Message: err.Error(),
+			})
+		}
+		h.logger.Error("Failed to list audit entries", zap.String("portfolioID", filter.PortfolioID), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, httpports.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPAuditEntries(entries))
+}
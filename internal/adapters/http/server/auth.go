@@ -0,0 +1,218 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	loggeradapter "testtask/internal/adapters/logger"
+	"testtask/internal/domain"
+	"testtask/internal/domain/user"
+	httpports "testtask/internal/ports/http"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// apiKeyHeader carries an API key; access tokens use "Authorization: Bearer <token>"
+const apiKeyHeader = "X-API-Key"
+
+// authenticate resolves the caller from an API key or a bearer access token and stores
+// it in the request context, which scopes all repository queries to that user
+func authenticate(auth domain.AuthService, logger *loggeradapter.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			ctx := req.Context()
+
+			var (
+				caller *user.User
+				err    error
+			)
+			if apiKey := req.Header.Get(apiKeyHeader); apiKey != "" {
+				caller, err = auth.AuthenticateAPIKey(ctx, apiKey)
+			} else if token, ok := bearerToken(req.Header.Get(echo.HeaderAuthorization)); ok {
+				caller, err = auth.AuthenticateToken(ctx, token)
+			} else {
+				return unauthorized(c, "missing credentials: set the "+apiKeyHeader+" header or a bearer token")
+			}
+
+			if err != nil {
+				if errors.Is(err, user.ErrInvalidCredentials) || errors.Is(err, user.ErrTokensDisabled) {
+					return unauthorized(c, err.Error())
+				}
+				logger.Error("Failed to authenticate request", zap.Error(err))
+				return c.JSON(http.StatusInternalServerError, httpports.ErrorResponse{
+					Error:   "Internal Server Error",
+					Message: "failed to authenticate request",
+				})
+			}
+
+			c.SetRequest(req.WithContext(user.WithUser(ctx, caller)))
+			return next(c)
+		}
+	}
+}
+
+// requireAdmin rejects callers without the admin role; it runs after authenticate
+func requireAdmin(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if caller := currentUser(c); caller == nil || !caller.Admin {
+			return c.JSON(http.StatusForbidden, httpports.ErrorResponse{
+				Error:   "Forbidden",
+				Message: "admin role required",
+			})
+		}
+		return next(c)
+	}
+}
+
+func bearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+func unauthorized(c echo.Context, message string) error {
+	c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="api"`)
+	return c.JSON(http.StatusUnauthorized, httpports.ErrorResponse{
+		Error:   "Unauthorized",
+		Message: message,
+	})
+}
+
+// currentUser returns the caller set by the authenticate middleware
+func currentUser(c echo.Context) *user.User {
+	caller, _ := user.FromContext(c.Request().Context())
+	return caller
+}
+
+// Register handles POST /api/v1/users. It is the only unauthenticated API endpoint;
+// the returned API key is shown once.
+func (h *HandlerAdapter) Register(c echo.Context) error {
+	var req httpports.RegisterRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "invalid request body",
+		})
+	}
+
+	u, apiKey, err := h.authService.Register(c.Request().Context(), req.Email, req.Name)
+	if err != nil {
+		switch {
+		case errors.Is(err, user.ErrInvalidUser):
+			return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+				Error:   "Bad Request",
+				Message: err.Error(),
+			})
+		case errors.Is(err, user.ErrUserExists):
+			return c.JSON(http.StatusConflict, httpports.ErrorResponse{
+				Error:   "Conflict",
+				Message: "a user with this email already exists",
+			})
+		}
+		h.logger.Error("Failed to register user", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, httpports.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "failed to register user",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, httpports.RegisterResponse{
+		User:   httpports.ToHTTPUser(u),
+		APIKey: apiKey,
+	})
+}
+
+// GetCurrentUser handles GET /api/v1/users/me
+func (h *HandlerAdapter) GetCurrentUser(c echo.Context) error {
+	return c.JSON(http.StatusOK, httpports.ToHTTPUser(currentUser(c)))
+}
+
+// ListAPIKeys handles GET /api/v1/users/me/api-keys
+func (h *HandlerAdapter) ListAPIKeys(c echo.Context) error {
+	keys, err := h.authService.ListAPIKeys(c.Request().Context(), currentUser(c).ID)
+	if err != nil {
+		h.logger.Error("Failed to list api keys", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, httpports.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "failed to list api keys",
+		})
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPAPIKeys(keys))
+}
+
+// CreateAPIKey handles POST /api/v1/users/me/api-keys; the returned key is shown once
+func (h *HandlerAdapter) CreateAPIKey(c echo.Context) error {
+	var req httpports.CreateAPIKeyRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "invalid request body",
+		})
+	}
+
+	key, apiKey, err := h.authService.CreateAPIKey(c.Request().Context(), currentUser(c).ID, req.Name)
+	if err != nil {
+		h.logger.Error("Failed to create api key", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, httpports.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "failed to create api key",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, httpports.CreateAPIKeyResponse{
+		Key:    httpports.ToHTTPAPIKey(key),
+		APIKey: apiKey,
+	})
+}
+
+// RevokeAPIKey handles DELETE /api/v1/users/me/api-keys/:keyID
+func (h *HandlerAdapter) RevokeAPIKey(c echo.Context) error {
+	keyID := c.Param("keyID")
+	if err := h.authService.RevokeAPIKey(c.Request().Context(), currentUser(c).ID, keyID); err != nil {
+		if errors.Is(err, user.ErrAPIKeyNotFound) {
+			return c.JSON(http.StatusNotFound, httpports.ErrorResponse{
+				Error:   "Not Found",
+				Message: "api key not found",
+			})
+		}
+		h.logger.Error("Failed to revoke api key", zap.String("key_id", keyID), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, httpports.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "failed to revoke api key",
+		})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// IssueAccessToken handles POST /api/v1/auth/token and exchanges the caller's
+// credentials for a short-lived bearer token
+func (h *HandlerAdapter) IssueAccessToken(c echo.Context) error {
+	token, expiresAt, err := h.authService.IssueToken(c.Request().Context(), currentUser(c))
+	if err != nil {
+		if errors.Is(err, user.ErrTokensDisabled) {
+			return c.JSON(http.StatusNotImplemented, httpports.ErrorResponse{
+				Error:   "Not Implemented",
+				Message: "access tokens are disabled, authenticate with an API key",
+			})
+		}
+		h.logger.Error("Failed to issue access token", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, httpports.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "failed to issue access token",
+		})
+	}
+
+	return c.JSON(http.StatusOK, httpports.AccessTokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresAt:   expiresAt,
+	})
+}
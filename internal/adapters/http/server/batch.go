@@ -0,0 +1,105 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"testtask/internal/domain/holding"
+	"testtask/internal/domain/portfolio"
+	"testtask/internal/domain/token"
+	httpports "testtask/internal/ports/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ApplyHoldingBatch handles POST /api/v1/portfolio/:portfolioID/holdings:batch
+func (h *HandlerAdapter) ApplyHoldingBatch(c echo.Context) error {
+	var req httpports.HoldingBatchRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "invalid request body",
+		})
+	}
+
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleEditor); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	ops, err := httpports.ToDomainBatchOperations(req.Operations, h.tokenResolver(c, portfolioID))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: err.Error(),
+		})
+	}
+
+	return h.applyHoldingBatch(c, portfolioID, ops, req.DryRun)
+}
+
+// ImportHoldings handles POST /api/v1/portfolio/:portfolioID/holdings:import?dry_run=
+// The body is a CSV file with token_address and amount columns or a JSON object with
+// a holdings array. Each row sets the amount of the token's holding.
+func (h *HandlerAdapter) ImportHoldings(c echo.Context) error {
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleEditor); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	dryRun := false
+	if value := c.QueryParam("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+				Error:   "Bad Request",
+				Message: "dry_run must be a boolean",
+			})
+		}
+		dryRun = parsed
+	}
+
+	rows, err := httpports.ParseHoldingImport(c.Request().Header.Get(echo.HeaderContentType), c.Request().Body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: err.Error(),
+		})
+	}
+
+	ops, err := httpports.ToDomainImportOperations(rows, h.tokenResolver(c, portfolioID))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: err.Error(),
+		})
+	}
+
+	return h.applyHoldingBatch(c, portfolioID, ops, dryRun)
+}
+
+func (h *HandlerAdapter) applyHoldingBatch(c echo.Context, portfolioID string, ops []*holding.BatchOperation, dryRun bool) error {
+	results, err := h.portfolioService.ApplyHoldingBatch(c.Request().Context(), portfolioID, ops, dryRun)
+	switch {
+	case errors.Is(err, holding.ErrBatchFailed):
+		return c.JSON(http.StatusUnprocessableEntity, httpports.ToHTTPHoldingBatch(results, dryRun))
+	case errors.Is(err, holding.ErrInvalidBatch):
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: err.Error(),
+		})
+	case err != nil:
+		return h.portfolioChangeError(c, portfolioID, err)
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPHoldingBatch(results, dryRun))
+}
+
+// tokenResolver resolves token addresses visible to the portfolio
+func (h *HandlerAdapter) tokenResolver(c echo.Context, portfolioID string) httpports.TokenResolver {
+	ctx := c.Request().Context()
+	return func(address string) (*token.Token, bool) {
+		return h.tokensService.GetTokenForPortfolio(ctx, portfolioID, address)
+	}
+}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"errors"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"testtask/internal/domain/portfolio"
+	"testtask/internal/domain/price"
+	"testtask/internal/domain/token"
+	httpports "testtask/internal/ports/http"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// CreateCustomToken handles POST /api/v1/tokens/custom
+func (h *HandlerAdapter) CreateCustomToken(c echo.Context) error {
+	var req httpports.CreateCustomTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "invalid request body",
+		})
+	}
+
+	ctx := c.Request().Context()
+	if req.PortfolioID != "" {
+		if err := h.authorize(c, req.PortfolioID, portfolio.RoleEditor); err != nil {
+			return h.portfolioLookupError(c, req.PortfolioID, err)
+		}
+	} else if !currentUser(c).Admin {
+		// Global tokens and their prices apply to every portfolio
+		return c.JSON(http.StatusForbidden, httpports.ErrorResponse{
+			Error:   "Forbidden",
+			Message: "admin role required to create global custom tokens",
+		})
+	}
+
+	custom := token.NewCustomToken(req.PortfolioID, req.ChainID, &token.Token{
+		Name:    strings.TrimSpace(req.Name),
+		Symbol:  strings.TrimSpace(req.Symbol),
+		Address: req.Address,
+		Decimal: req.Decimals,
+	})
+	if req.Price != "" {
+		value, currency, err := parseManualPrice(req.Price, req.Currency)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+				Error:   "Bad Request",
+				Message: err.Error(),
+			})
+		}
+		custom.SetManualPrice(value, currency)
+	}
+
+	if err := h.tokensService.CreateCustomToken(ctx, custom); err != nil {
+		return h.customTokenError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, httpports.ToHTTPCustomToken(custom))
+}
+
+// ListCustomTokens handles GET /api/v1/tokens/custom?portfolio_id=
+func (h *HandlerAdapter) ListCustomTokens(c echo.Context) error {
+	portfolioID := c.QueryParam("portfolio_id")
+	if portfolioID != "" {
+		if err := h.authorize(c, portfolioID, portfolio.RoleViewer); err != nil {
+			return h.portfolioLookupError(c, portfolioID, err)
+		}
+	}
+
+	tokens, err := h.tokensService.ListCustomTokens(c.Request().Context(), portfolioID)
+	if err != nil {
+		h.logger.Error("Failed to list custom tokens", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, httpports.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPCustomTokens(tokens))
+}
+
+// SetCustomTokenPrice handles PUT /api/v1/tokens/custom/:id/price
+func (h *HandlerAdapter) SetCustomTokenPrice(c echo.Context) error {
+	var req httpports.SetCustomTokenPriceRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "invalid request body",
+		})
+	}
+
+	var (
+		value    *big.Int
+		currency string
+		err      error
+	)
+	if req.Price != "" {
+		value, currency, err = parseManualPrice(req.Price, req.Currency)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+				Error:   "Bad Request",
+				Message: err.Error(),
+			})
+		}
+	}
+
+	if ok, err := h.authorizeCustomToken(c, c.Param("id")); !ok {
+		return err
+	}
+
+	custom, err := h.tokensService.SetCustomTokenPrice(c.Request().Context(), c.Param("id"), value, currency)
+	if err != nil {
+		return h.customTokenError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPCustomToken(custom))
+}
+
+// DeleteCustomToken handles DELETE /api/v1/tokens/custom/:id
+func (h *HandlerAdapter) DeleteCustomToken(c echo.Context) error {
+	if ok, err := h.authorizeCustomToken(c, c.Param("id")); !ok {
+		return err
+	}
+
+	if err := h.tokensService.DeleteCustomToken(c.Request().Context(), c.Param("id")); err != nil {
+		return h.customTokenError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// authorizeCustomToken requires the editor role when the token belongs to a portfolio,
+// and the token's owner or an admin for global tokens.
+// When ok is false the error response has been written and err is the handler result.
+func (h *HandlerAdapter) authorizeCustomToken(c echo.Context, id string) (ok bool, err error) {
+	custom, err := h.tokensService.GetCustomToken(c.Request().Context(), id)
+	if err != nil {
+		return false, h.customTokenError(c, err)
+	}
+	if custom.PortfolioID == "" {
+		if caller := currentUser(c); caller.Admin || (custom.OwnerID != "" && custom.OwnerID == caller.ID) {
+			return true, nil
+		}
+		return false, c.JSON(http.StatusForbidden, httpports.ErrorResponse{
+			Error:   "Forbidden",
+			Message: "only the owner or an admin can change a global custom token",
+		})
+	}
+	if err := h.authorize(c, custom.PortfolioID, portfolio.RoleEditor); err != nil {
+		return false, h.portfolioLookupError(c, custom.PortfolioID, err)
+	}
+
+	return true, nil
+}
+
+func parseManualPrice(value, currency string) (*big.Int, string, error) {
+	parsed, err := httpports.ParseDecimal(value, price.CurrencyDecimal)
+	if err != nil {
+		return nil, "", err
+	}
+	if currency == "" {
+		currency = "usd"
+	}
+	return parsed, strings.ToLower(currency), nil
+}
+
+func (h *HandlerAdapter) customTokenError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, token.ErrInvalidCustomToken):
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: err.Error(),
+		})
+	case errors.Is(err, token.ErrCustomTokenNotFound):
+		return c.JSON(http.StatusNotFound, httpports.ErrorResponse{
+			Error:   "Not Found",
+			Message: err.Error(),
+		})
+	case errors.Is(err, token.ErrCustomTokenExists):
+		return c.JSON(http.StatusConflict, httpports.ErrorResponse{
+			Error:   "Conflict",
+			Message: err.Error(),
+		})
+	}
+
+	h.logger.Error("Custom token operation failed", zap.Error(err))
+	return c.JSON(http.StatusInternalServerError, httpports.ErrorResponse{
+		Error:   "Internal Server Error",
+		Message: err.Error(),
+	})
+}
+
+func (h *HandlerAdapter) portfolioLookupError(c echo.Context, portfolioID string, err error) error {
+	if errors.Is(err, portfolio.ErrPortfolioNotFound) {
+		return c.JSON(http.StatusNotFound, httpports.ErrorResponse{
+			Error:   "Not Found",
+			Message: "portfolio not found",
+		})
+	}
+	if errors.Is(err, portfolio.ErrAccessDenied) {
+		return c.JSON(http.StatusForbidden, httpports.ErrorResponse{
+			Error:   "Forbidden",
+			Message: err.Error(),
+		})
+	}
+
+	h.logger.Error("Failed to get portfolio", zap.String("portfolioID", portfolioID), zap.Error(err))
+	return c.JSON(http.StatusInternalServerError, httpports.ErrorResponse{
+		Error:   "Internal Server Error",
+		Message: err.Error(),
+	})
+}
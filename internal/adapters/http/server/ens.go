@@ -0,0 +1,35 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"testtask/internal/domain/ens"
+	httpports "testtask/internal/ports/http"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// ensError renders a failure to resolve an ENS name given in place of an address
+func (h *HandlerAdapter) ensError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, ens.ErrInvalidName), errors.Is(err, ens.ErrNotResolved):
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: err.Error(),
+		})
+	case errors.Is(err, ens.ErrUnavailable):
+		h.logger.Warn("ENS resolution unavailable", zap.Error(err))
+		return c.JSON(http.StatusServiceUnavailable, httpports.ErrorResponse{
+			Error:   "Service Unavailable",
+			Message: "ens resolution is unavailable",
+		})
+	}
+
+	h.logger.Error("Failed to resolve ENS name", zap.Error(err))
+	return c.JSON(http.StatusInternalServerError, httpports.ErrorResponse{
+		Error:   "Internal Server Error",
+		Message: "failed to resolve ens name",
+	})
+}
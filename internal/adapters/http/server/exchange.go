@@ -0,0 +1,181 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"testtask/internal/domain/exchange"
+	"testtask/internal/domain/portfolio"
+	httpports "testtask/internal/ports/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ImportStatement handles POST /api/v1/portfolio/:portfolioID/statements?exchange=
+// The body is a Binance, Coinbase or Kraken CSV export, or a JSON object carrying the
+// export and, for the generic exchange, its column mapping. Rows imported before are
+// skipped.
+func (h *HandlerAdapter) ImportStatement(c echo.Context) error {
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleEditor); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	req, err := httpports.ParseStatementImport(c.Request().Header.Get(echo.HeaderContentType), c.QueryParam("exchange"), c.Request().Body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: err.Error(),
+		})
+	}
+
+	result, err := h.exchangeService.ImportStatement(c.Request().Context(), portfolioID, req.Exchange, req.Mapping, req.Body)
+	if err != nil {
+		return h.statementError(c, portfolioID, err)
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPStatementImport(result))
+}
+
+// ListStatementRecords handles GET /api/v1/portfolio/:portfolioID/statements?exchange=&kind=&asset=&from=&to=
+func (h *HandlerAdapter) ListStatementRecords(c echo.Context) error {
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleViewer); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	filter := exchange.Filter{Asset: c.QueryParam("asset")}
+	if value := c.QueryParam("exchange"); value != "" {
+		e, err := exchange.ParseExchange(value)
+		if err != nil {
+			return h.statementError(c, portfolioID, err)
+		}
+		filter.Exchange = e
+	}
+	if value := c.QueryParam("kind"); value != "" {
+		k, err := exchange.ParseKind(value)
+		if err != nil {
+			return h.statementError(c, portfolioID, err)
+		}
+		filter.Kind = k
+	}
+	var (
+		ok  bool
+		err error
+	)
+	if filter.From, ok, err = parseTimeParam(c, "from"); !ok {
+		return err
+	}
+	if filter.To, ok, err = parseTimeParam(c, "to"); !ok {
+		return err
+	}
+
+	records, err := h.exchangeService.ListRecords(c.Request().Context(), portfolioID, filter)
+	if err != nil {
+		return h.statementError(c, portfolioID, err)
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPExchangeRecords(records))
+}
+
+// ListConnections handles GET /api/v1/portfolio/:portfolioID/connections
+func (h *HandlerAdapter) ListConnections(c echo.Context) error {
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleViewer); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	connections, err := h.exchangeService.ListConnections(c.Request().Context(), portfolioID)
+	if err != nil {
+		return h.statementError(c, portfolioID, err)
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPExchangeConnections(connections))
+}
+
+// CreateConnection handles POST /api/v1/portfolio/:portfolioID/connections.
+// Keys that may trade or withdraw are rejected.
+func (h *HandlerAdapter) CreateConnection(c echo.Context) error {
+	var req httpports.CreateConnectionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "invalid request body",
+		})
+	}
+
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleAdmin); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	e, err := exchange.ParseExchange(req.Exchange)
+	if err != nil {
+		return h.statementError(c, portfolioID, err)
+	}
+	creds := exchange.Credentials{APIKey: req.APIKey, APISecret: req.APISecret}
+	conn, err := h.exchangeService.CreateConnection(c.Request().Context(), portfolioID, e, req.Label, creds)
+	if err != nil {
+		return h.statementError(c, portfolioID, err)
+	}
+
+	return c.JSON(http.StatusCreated, httpports.ToHTTPExchangeConnection(conn))
+}
+
+// DeleteConnection handles DELETE /api/v1/portfolio/:portfolioID/connections/:connectionID.
+// Records already synced are kept.
+func (h *HandlerAdapter) DeleteConnection(c echo.Context) error {
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleAdmin); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	if err := h.exchangeService.DeleteConnection(c.Request().Context(), portfolioID, c.Param("connectionID")); err != nil {
+		return h.statementError(c, portfolioID, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// SyncConnection handles POST /api/v1/portfolio/:portfolioID/connections/:connectionID/sync
+func (h *HandlerAdapter) SyncConnection(c echo.Context) error {
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleEditor); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	result, err := h.exchangeService.SyncConnection(c.Request().Context(), portfolioID, c.Param("connectionID"))
+	if err != nil {
+		return h.statementError(c, portfolioID, err)
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPSyncResult(result))
+}
+
+func (h *HandlerAdapter) statementError(c echo.Context, portfolioID string, err error) error {
+	switch {
+	case errors.Is(err, exchange.ErrInvalidStatement), errors.Is(err, exchange.ErrUnsupportedExchange),
+		errors.Is(err, exchange.ErrInvalidFilter), errors.Is(err, exchange.ErrInvalidConnection),
+		errors.Is(err, exchange.ErrNotReadOnly):
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: err.Error(),
+		})
+	case errors.Is(err, exchange.ErrConnectionExists):
+		return c.JSON(http.StatusConflict, httpports.ErrorResponse{
+			Error:   "Conflict",
+			Message: err.Error(),
+		})
+	case errors.Is(err, exchange.ErrConnectionNotFound):
+		return c.JSON(http.StatusNotFound, httpports.ErrorResponse{
+			Error:   "Not Found",
+			Message: err.Error(),
+		})
+	case errors.Is(err, exchange.ErrConnectorUnavailable):
+		return c.JSON(http.StatusServiceUnavailable, httpports.ErrorResponse{
+			Error:   "Service Unavailable",
+			Message: err.Error(),
+		})
+	}
+	return h.portfolioChangeError(c, portfolioID, err)
+}
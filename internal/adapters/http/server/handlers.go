@@ -8,8 +8,11 @@ import (
 	"strings"
 	"testtask/internal/adapters/logger"
 	"testtask/internal/domain"
+	"testtask/internal/domain/ens"
 	"testtask/internal/domain/holding"
 	"testtask/internal/domain/portfolio"
+	"testtask/internal/domain/price"
+	"testtask/internal/domain/transaction"
 	"time"
 
 	httpports "testtask/internal/ports/http"
@@ -24,6 +27,15 @@ type HandlerAdapter struct {
 	portfolioService   domain.PortfolioService
 	priceService       domain.PriceService
 	tokensService      domain.TokensService
+	tokenAdminService  domain.TokenAdminService
+	authService        domain.AuthService
+	sharingService     domain.SharingService
+	auditService       domain.AuditService
+	exchangeService    domain.ExchangeService
+	taxService         domain.TaxService
+	incomeService      domain.IncomeService
+	addressBookService domain.AddressBookService
+	ensService         domain.ENSService
 	logger             *logger.Logger
 }
 
@@ -33,6 +45,15 @@ func NewHandlerAdapter(
 	portfolioService domain.PortfolioService,
 	priceService domain.PriceService,
 	tokensService domain.TokensService,
+	tokenAdminService domain.TokenAdminService,
+	authService domain.AuthService,
+	sharingService domain.SharingService,
+	auditService domain.AuditService,
+	exchangeService domain.ExchangeService,
+	taxService domain.TaxService,
+	incomeService domain.IncomeService,
+	addressBookService domain.AddressBookService,
+	ensService domain.ENSService,
 	logger *logger.Logger,
 ) *HandlerAdapter {
 	return &HandlerAdapter{
@@ -40,13 +61,34 @@ func NewHandlerAdapter(
 		portfolioService:   portfolioService,
 		priceService:       priceService,
 		tokensService:      tokensService,
+		tokenAdminService:  tokenAdminService,
+		authService:        authService,
+		sharingService:     sharingService,
+		auditService:       auditService,
+		exchangeService:    exchangeService,
+		taxService:         taxService,
+		incomeService:      incomeService,
+		addressBookService: addressBookService,
+		ensService:         ensService,
 		logger:             logger,
 	}
 }
 
-// CreatePortfolioRequest represents the request body for creating a portfolio
+// CreatePortfolioRequest represents the request body for creating a portfolio. Address
+// may be an ENS name, which is resolved once and also names the portfolio by default.
 type CreatePortfolioRequest struct {
-	Address string `json:"address"`
+	Address     string   `json:"address"`
+	Name        string   `json:"name,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// UpdatePortfolioRequest represents the request body for updating portfolio metadata.
+// Omitted fields are left unchanged.
+type UpdatePortfolioRequest struct {
+	Name        *string   `json:"name"`
+	Description *string   `json:"description"`
+	Tags        *[]string `json:"tags"`
 }
 
 func (h *HandlerAdapter) CreatePortfolio(c echo.Context) error {
@@ -65,9 +107,27 @@ func (h *HandlerAdapter) CreatePortfolio(c echo.Context) error {
 		})
 	}
 
+	address, err := h.ensService.ResolveAddress(c.Request().Context(), req.Address)
+	if err != nil {
+		return h.ensError(c, err)
+	}
+
 	// Create holding portfolio
-	newPortfolio := portfolio.NewPortfolio("", req.Address)
+	newPortfolio := portfolio.NewPortfolio("", address)
+	newPortfolio.Name = req.Name
+	if newPortfolio.Name == "" && ens.IsName(req.Address) {
+		newPortfolio.Name = strings.ToLower(strings.TrimSpace(req.Address))
+	}
+	newPortfolio.Description = req.Description
+	newPortfolio.Tags = req.Tags
 	if err := h.portfolioService.CreatePortfolio(c.Request().Context(), newPortfolio); err != nil {
+		if errors.Is(err, portfolio.ErrInvalidPortfolio) {
+			return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+				Error:   "Bad Request",
+				Message: err.Error(),
+			})
+		}
+
 		if errors.Is(err, portfolio.ErrPortfolioAddressExists) {
 			h.logger.Warn("Portfolio creation failed: address exists", zap.String("address", req.Address), zap.Error(err))
 			return c.JSON(http.StatusConflict, httpports.ErrorResponse{
@@ -118,40 +178,99 @@ func (h *HandlerAdapter) GetPortfolio(c echo.Context) error {
 		})
 	}
 
+	if err := h.authorize(c, portofolioID, portfolio.RoleViewer); err != nil {
+		return h.portfolioLookupError(c, portofolioID, err)
+	}
+
 	p, err := h.portfolioService.GetPortfolio(c.Request().Context(), portofolioID)
 	if err != nil {
-		h.logger.Error("Failed to get p", zap.String("portfolioID", portofolioID), zap.Error(err))
-		return c.JSON(http.StatusInternalServerError, httpports.ErrorResponse{
-			Error:   "Internal Server Error",
-			Message: err.Error(),
-		})
+		return h.portfolioLookupError(c, portofolioID, err)
 	}
 
 	return c.JSON(http.StatusOK, httpports.ToHTTPPortfolio(p))
 }
 
+const maxPortfolioPageSize = 100
+
+// GetPortfolioList handles GET /api/v1/portfolio?page=&page_size=&sort=&order=&tag=&include_archived=
 func (h *HandlerAdapter) GetPortfolioList(c echo.Context) error {
-	portofolioID := c.Param("portfolioID")
-	if portofolioID == "" {
+	opts := portfolio.ListOptions{
+		Page:     1,
+		PageSize: 20,
+		SortBy:   portfolio.SortByUpdatedAt,
+		Desc:     true,
+		Tag:      c.QueryParam("tag"),
+	}
+
+	if pageParam := c.QueryParam("page"); pageParam != "" {
+		if page, err := strconv.Atoi(pageParam); err == nil && page > 0 {
+			opts.Page = page
+		}
+	}
+	if pageSizeParam := c.QueryParam("page_size"); pageSizeParam != "" {
+		if pageSize, err := strconv.Atoi(pageSizeParam); err == nil && pageSize > 0 {
+			opts.PageSize = pageSize
+		}
+	}
+	if opts.PageSize > maxPortfolioPageSize {
+		opts.PageSize = maxPortfolioPageSize
+	}
+	if sortParam := c.QueryParam("sort"); sortParam != "" {
+		opts.SortBy = sortParam
+	}
+	switch c.QueryParam("order") {
+	case "":
+	case "asc":
+		opts.Desc = false
+	case "desc":
+		opts.Desc = true
+	default:
 		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
 			Error:   "Bad Request",
-			Message: "portfolioID is required",
+			Message: "order must be asc or desc",
 		})
 	}
+	if archivedParam := c.QueryParam("include_archived"); archivedParam != "" {
+		includeArchived, err := strconv.ParseBool(archivedParam)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+				Error:   "Bad Request",
+				Message: "include_archived must be a boolean",
+			})
+		}
+		opts.IncludeArchived = includeArchived
+	}
 
-	p, err := h.portfolioService.ListPortfolios(c.Request().Context())
+	portfolios, total, err := h.portfolioService.ListPortfolios(c.Request().Context(), opts)
 	if err != nil {
-		h.logger.Error("Failed to get p", zap.String("portfolioID", portofolioID), zap.Error(err))
+		if errors.Is(err, portfolio.ErrInvalidPortfolio) {
+			return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+				Error:   "Bad Request",
+				Message: err.Error(),
+			})
+		}
+		h.logger.Error("Failed to list portfolios", zap.Error(err))
 		return c.JSON(http.StatusInternalServerError, httpports.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: err.Error(),
 		})
 	}
 
-	return c.JSON(http.StatusOK, httpports.ToHTTPPortfolios(p))
+	totalPages := (total + opts.PageSize - 1) / opts.PageSize
+	if totalPages < 1 {
+		totalPages = 1
+	}
+
+	return c.JSON(http.StatusOK, httpports.PaginatedResponse{
+		Data:       httpports.ToHTTPPortfolios(portfolios),
+		Page:       opts.Page,
+		PageSize:   opts.PageSize,
+		Total:      total,
+		TotalPages: totalPages,
+	})
 }
 
-// AddHolding handles POST /api/v1/portfolio/:portofolioID/holdingRepo
+// AddHolding handles POST /api/v1/portfolio/:portfolioID/holdings
 func (h *HandlerAdapter) AddHolding(c echo.Context) error {
 	portofolioID := c.Param("portfolioID")
 	if portofolioID == "" {
@@ -161,6 +280,10 @@ func (h *HandlerAdapter) AddHolding(c echo.Context) error {
 		})
 	}
 
+	if err := h.authorize(c, portofolioID, portfolio.RoleEditor); err != nil {
+		return h.portfolioLookupError(c, portofolioID, err)
+	}
+
 	var req = struct {
 		TokenAddress string `json:"token_address"`
 		Amount       int    `json:"amount"`
@@ -179,7 +302,7 @@ func (h *HandlerAdapter) AddHolding(c echo.Context) error {
 		})
 	}
 
-	t, ok := h.tokensService.GetTokenByAddress(c.Request().Context(), strings.ToLower(req.TokenAddress))
+	t, ok := h.tokensService.GetTokenForPortfolio(c.Request().Context(), portofolioID, strings.ToLower(req.TokenAddress))
 	if !ok {
 		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
 			Error:   "Bad Request",
@@ -189,18 +312,15 @@ func (h *HandlerAdapter) AddHolding(c echo.Context) error {
 
 	holding := holding.NewHolding(portofolioID, "", t, big.NewInt(int64(req.Amount)))
 	if err := h.portfolioService.AddHolding(c.Request().Context(), portofolioID, holding); err != nil {
-		return c.JSON(http.StatusInternalServerError, httpports.ErrorResponse{
-			Error:   "Internal Server Error",
-			Message: err.Error(),
-		})
+		return h.portfolioChangeError(c, portofolioID, err)
 	}
 
 	return c.JSON(http.StatusCreated, holding)
 }
 
-// UpdateHolding handles PUT /api/v1/portfolio/:portofolioID/holdingRepo/:holdingID
+// UpdateHolding handles PUT /api/v1/portfolio/:portfolioID/holdings/:holdingID
 func (h *HandlerAdapter) UpdateHolding(c echo.Context) error {
-	portofolioID := c.Param("portofolioID")
+	portofolioID := c.Param("portfolioID")
 	holdingID := c.Param("holdingID")
 	if portofolioID == "" || holdingID == "" {
 		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
@@ -209,8 +329,14 @@ func (h *HandlerAdapter) UpdateHolding(c echo.Context) error {
 		})
 	}
 
+	if err := h.authorize(c, portofolioID, portfolio.RoleEditor); err != nil {
+		return h.portfolioLookupError(c, portofolioID, err)
+	}
+
+	// version is optional; when set the update only applies to that version of the holding
 	var updateReq = struct {
-		Amount int `json:"amount"`
+		Amount  int   `json:"amount"`
+		Version int64 `json:"version"`
 	}{}
 
 	if err := c.Bind(&updateReq); err != nil {
@@ -220,19 +346,16 @@ func (h *HandlerAdapter) UpdateHolding(c echo.Context) error {
 		})
 	}
 
-	if err := h.portfolioService.UpdateHolding(c.Request().Context(), portofolioID, holdingID, big.NewInt(int64(updateReq.Amount))); err != nil {
-		return c.JSON(http.StatusInternalServerError, httpports.ErrorResponse{
-			Error:   "Internal Server Error",
-			Message: err.Error(),
-		})
+	if err := h.portfolioService.UpdateHolding(c.Request().Context(), portofolioID, holdingID, big.NewInt(int64(updateReq.Amount)), updateReq.Version); err != nil {
+		return h.portfolioChangeError(c, portofolioID, err)
 	}
 
 	return c.JSON(http.StatusOK, nil)
 }
 
-// DeleteHolding handles DELETE /api/v1/portfolio/:portofolioId/holdingRepo/:holdingID
+// DeleteHolding handles DELETE /api/v1/portfolio/:portfolioID/holdings/:holdingID
 func (h *HandlerAdapter) DeleteHolding(c echo.Context) error {
-	portofolioID := c.Param("portofolioID")
+	portofolioID := c.Param("portfolioID")
 	holdingID := c.Param("holdingID")
 	if portofolioID == "" || holdingID == "" {
 		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
@@ -241,11 +364,12 @@ func (h *HandlerAdapter) DeleteHolding(c echo.Context) error {
 		})
 	}
 
+	if err := h.authorize(c, portofolioID, portfolio.RoleEditor); err != nil {
+		return h.portfolioLookupError(c, portofolioID, err)
+	}
+
 	if err := h.portfolioService.DeleteHolding(c.Request().Context(), portofolioID, holdingID); err != nil {
-		return c.JSON(http.StatusInternalServerError, httpports.ErrorResponse{
-			Error:   "Internal Server Error",
-			Message: err.Error(),
-		})
+		return h.portfolioChangeError(c, portofolioID, err)
 	}
 
 	return c.NoContent(http.StatusNoContent)
@@ -257,14 +381,34 @@ func (h *HandlerAdapter) GetTransactions(c echo.Context) error {
 		PageSize: 20,
 	}
 
+	// Callers who can view the portfolio also get its annotations and manual transactions,
+	// and may leave out the address to use the portfolio's own
+	portfolioID := c.Param("portfolioID")
+	authErr := h.authorize(c, portfolioID, portfolio.RoleViewer)
+	if authErr != nil && !errors.Is(authErr, portfolio.ErrPortfolioNotFound) && !errors.Is(authErr, portfolio.ErrAccessDenied) {
+		return h.portfolioLookupError(c, portfolioID, authErr)
+	}
+	viewer := authErr == nil
+
 	// Parse query parameters
 	addressParam := c.QueryParam("address")
-	if addressParam == "" {
+	if addressParam == "" && viewer {
+		p, err := h.portfolioService.GetPortfolio(c.Request().Context(), portfolioID)
+		if err != nil {
+			return h.portfolioLookupError(c, portfolioID, err)
+		}
+		addressParam = p.Address
+	} else if addressParam == "" {
 		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
 			Error:   "Bad Request",
-			Message: "address is required ",
+			Message: "address is required",
 		})
 	}
+	resolved, err := h.ensService.ResolveAddress(c.Request().Context(), addressParam)
+	if err != nil {
+		return h.ensError(c, err)
+	}
+	addressParam = resolved
 	filters.Address = &addressParam
 
 	if typeParam := c.QueryParam("type"); typeParam != "" {
@@ -276,6 +420,15 @@ func (h *HandlerAdapter) GetTransactions(c echo.Context) error {
 	if tokenParam := c.QueryParam("token"); tokenParam != "" {
 		filters.Token = &tokenParam
 	}
+	if tagParam := c.QueryParam("tag"); tagParam != "" {
+		if !viewer {
+			return h.portfolioLookupError(c, portfolioID, authErr)
+		}
+		filters.Tag = &tagParam
+	}
+	if counterpartyParam := c.QueryParam("counterparty"); counterpartyParam != "" {
+		filters.Counterparty = &counterpartyParam
+	}
 	if fromDateParam := c.QueryParam("from_date"); fromDateParam != "" {
 		if fromDate, err := time.Parse(time.RFC3339, fromDateParam); err == nil {
 			filters.FromDate = &fromDate
@@ -307,6 +460,10 @@ func (h *HandlerAdapter) GetTransactions(c echo.Context) error {
 		})
 	}
 
+	if viewer {
+		opts.PortfolioID = portfolioID
+	}
+
 	transactions, total, err := h.transactionService.GetTransactions(c.Request().Context(), addressParam, opts)
 	if err != nil {
 		h.logger.Error("Failed to get transactions", zap.Any("filters", filters), zap.Error(err))
@@ -316,6 +473,22 @@ func (h *HandlerAdapter) GetTransactions(c echo.Context) error {
 		})
 	}
 
+	// Income rules always apply; the portfolio's own categories only for callers who can view it
+	classifyFor := ""
+	if viewer {
+		classifyFor = portfolioID
+	}
+	txs := make([]*transaction.Transaction, len(transactions))
+	for i := range transactions {
+		txs[i] = &transactions[i]
+	}
+	if err := h.incomeService.Classify(c.Request().Context(), classifyFor, txs); err != nil {
+		h.logger.Warn("Failed to classify income", zap.String("portfolio_id", classifyFor), zap.Error(err))
+	}
+	if err := h.ensService.Name(c.Request().Context(), txs); err != nil {
+		h.logger.Warn("Failed to look up ENS names", zap.Error(err))
+	}
+
 	totalPages := (total + filters.PageSize - 1) / filters.PageSize
 	if totalPages < 1 {
 		totalPages = 1
@@ -340,6 +513,27 @@ func (h *HandlerAdapter) GetPortfolioAssets(c echo.Context) error {
 			Message: "portfolioID is required",
 		})
 	}
+	if err := h.authorize(c, portfolioID, portfolio.RoleViewer); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	return h.portfolioAssets(c, portfolioID)
+}
+
+// portfolioAssets renders the priced assets of an already authorized portfolio
+func (h *HandlerAdapter) portfolioAssets(c echo.Context, portfolioID string) error {
+	merge := false
+	if mergeParam := c.QueryParam("merge"); mergeParam != "" {
+		parsed, err := strconv.ParseBool(mergeParam)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+				Error:   "Bad Request",
+				Message: "merge must be a boolean",
+			})
+		}
+		merge = parsed
+	}
+
 	p, assets, err := h.portfolioService.GetPortfolioAssets(c.Request().Context(), portfolioID, "usd") // hardcoded
 	if err != nil {
 		if errors.Is(err, portfolio.ErrPortfolioNotFound) {
@@ -357,9 +551,60 @@ func (h *HandlerAdapter) GetPortfolioAssets(c echo.Context) error {
 		})
 	}
 
+	// Optionally show equivalent tokens such as ETH, WETH and stETH as one asset
+	if merge {
+		assets = h.portfolioService.MergeEquivalentAssets(assets)
+	}
+
 	return c.JSON(http.StatusOK, httpports.ToHTTPPortfolioAssets(p, assets))
 }
 
+// GetTopMovers handles GET /api/v1/portfolio/:portfolioID/movers
+func (h *HandlerAdapter) GetTopMovers(c echo.Context) error {
+	portfolioID := c.Param("portfolioID")
+	if portfolioID == "" {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "portfolioID is required",
+		})
+	}
+
+	if err := h.authorize(c, portfolioID, portfolio.RoleViewer); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	limit := 5
+	if limitParam := c.QueryParam("limit"); limitParam != "" {
+		parsed, err := strconv.Atoi(limitParam)
+		if err != nil || parsed <= 0 {
+			return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+				Error:   "Bad Request",
+				Message: "limit must be a positive integer",
+			})
+		}
+		limit = parsed
+	}
+
+	gainers, losers, err := h.portfolioService.GetTopMovers(c.Request().Context(), portfolioID, price.DefaultCurrency, limit)
+	if err != nil {
+		if errors.Is(err, portfolio.ErrPortfolioNotFound) {
+			h.logger.Warn("Portfolio not found", zap.String("portfolioID", portfolioID), zap.Error(err))
+			return c.JSON(http.StatusNotFound, httpports.ErrorResponse{
+				Error:   "Not Found",
+				Message: err.Error(),
+			})
+		}
+
+		h.logger.Error("Failed to get top movers", zap.String("portfolioID", portfolioID), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, httpports.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPTopMovers(portfolioID, gainers, losers))
+}
+
 func (h *HandlerAdapter) HealthCheck(c echo.Context) error {
 	status := map[string]interface{}{
 		"status":    "ok",
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"testtask/internal/domain/income"
+	"testtask/internal/domain/portfolio"
+	"testtask/internal/domain/transaction"
+	httpports "testtask/internal/ports/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ListIncomeClassifications handles GET /api/v1/portfolio/:portfolioID/income/classifications
+func (h *HandlerAdapter) ListIncomeClassifications(c echo.Context) error {
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleViewer); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	classifications, err := h.incomeService.ListClassifications(c.Request().Context(), portfolioID)
+	if err != nil {
+		return h.incomeError(c, portfolioID, err)
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPIncomeClassifications(classifications))
+}
+
+// SetIncomeClassification handles PUT /api/v1/portfolio/:portfolioID/income/classifications/:transactionID
+func (h *HandlerAdapter) SetIncomeClassification(c echo.Context) error {
+	var req httpports.IncomeClassificationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "invalid request body",
+		})
+	}
+
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleEditor); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	classification := &income.Classification{
+		PortfolioID:   portfolioID,
+		TransactionID: c.Param("transactionID"),
+		Category:      transaction.IncomeCategory(req.Category),
+		Note:          req.Note,
+	}
+	if err := h.incomeService.SetClassification(c.Request().Context(), classification); err != nil {
+		return h.incomeError(c, portfolioID, err)
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPIncomeClassification(classification))
+}
+
+// DeleteIncomeClassification handles DELETE /api/v1/portfolio/:portfolioID/income/classifications/:transactionID
+func (h *HandlerAdapter) DeleteIncomeClassification(c echo.Context) error {
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleEditor); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	if err := h.incomeService.DeleteClassification(c.Request().Context(), portfolioID, c.Param("transactionID")); err != nil {
+		return h.incomeError(c, portfolioID, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListIncomeAccruals handles GET /api/v1/portfolio/:portfolioID/income/accruals
+func (h *HandlerAdapter) ListIncomeAccruals(c echo.Context) error {
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleViewer); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	accruals, err := h.incomeService.Accruals(c.Request().Context(), portfolioID)
+	if err != nil {
+		return h.incomeError(c, portfolioID, err)
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPIncomeAccruals(accruals))
+}
+
+// ObserveIncomeAccruals handles POST /api/v1/portfolio/:portfolioID/income/accruals.
+// It checks the rebasing token balances now and returns the accruals it recorded.
+func (h *HandlerAdapter) ObserveIncomeAccruals(c echo.Context) error {
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleEditor); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	accruals, err := h.incomeService.ObserveRebases(c.Request().Context(), portfolioID)
+	if err != nil {
+		return h.incomeError(c, portfolioID, err)
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPIncomeAccruals(accruals))
+}
+
+func (h *HandlerAdapter) incomeError(c echo.Context, portfolioID string, err error) error {
+	switch {
+	case errors.Is(err, income.ErrInvalidClassification):
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: err.Error(),
+		})
+	case errors.Is(err, income.ErrClassificationNotFound):
+		return c.JSON(http.StatusNotFound, httpports.ErrorResponse{
+			Error:   "Not Found",
+			Message: err.Error(),
+		})
+	}
+
+	return h.portfolioChangeError(c, portfolioID, err)
+}
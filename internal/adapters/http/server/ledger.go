@@ -0,0 +1,129 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"testtask/internal/domain/holding"
+	"testtask/internal/domain/portfolio"
+	httpports "testtask/internal/ports/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RecordLedgerEntry handles POST /api/v1/portfolio/:portfolioID/ledger
+func (h *HandlerAdapter) RecordLedgerEntry(c echo.Context) error {
+	var req httpports.LedgerEntryRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "invalid request body",
+		})
+	}
+
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleEditor); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	draft, err := httpports.ToDomainLedgerEntry(req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: err.Error(),
+		})
+	}
+
+	ctx := c.Request().Context()
+	t, ok := h.tokensService.GetTokenForPortfolio(ctx, portfolioID, strings.ToLower(req.TokenAddress))
+	if !ok {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "not supported token address",
+		})
+	}
+
+	updated, err := h.portfolioService.RecordLedgerEntry(ctx, portfolioID, t, draft)
+	if err != nil {
+		return h.portfolioChangeError(c, portfolioID, err)
+	}
+
+	return c.JSON(http.StatusCreated, httpports.ToHTTPHolding(updated))
+}
+
+// ListLedger handles GET /api/v1/portfolio/:portfolioID/ledger?token_address=&holding_id=&from=&to=
+func (h *HandlerAdapter) ListLedger(c echo.Context) error {
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleViewer); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	filter := holding.LedgerFilter{
+		HoldingID:    c.QueryParam("holding_id"),
+		TokenAddress: c.QueryParam("token_address"),
+	}
+	var (
+		ok  bool
+		err error
+	)
+	if filter.From, ok, err = parseTimeParam(c, "from"); !ok {
+		return err
+	}
+	if filter.To, ok, err = parseTimeParam(c, "to"); !ok {
+		return err
+	}
+
+	entries, err := h.portfolioService.ListLedger(c.Request().Context(), portfolioID, filter)
+	if err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPLedgerEntries(entries))
+}
+
+// GetHoldings handles GET /api/v1/portfolio/:portfolioID/holdings?at=, the holdings as of
+// an RFC3339 time projected from the ledger. Without at, the current holdings are returned.
+func (h *HandlerAdapter) GetHoldings(c echo.Context) error {
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleViewer); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	at, ok, err := parseTimeParam(c, "at")
+	if !ok {
+		return err
+	}
+	if at == nil {
+		now := time.Now().UTC()
+		at = &now
+	}
+
+	holdings, err := h.portfolioService.GetHoldingsAt(c.Request().Context(), portfolioID, *at)
+	if err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	return c.JSON(http.StatusOK, httpports.HoldingsAt{
+		PortfolioID: portfolioID,
+		At:          *at,
+		Holdings:    httpports.ToHTTPHoldings(holdings),
+	})
+}
+
+// parseTimeParam parses an optional RFC3339 query parameter. When ok is false the 400
+// response has been written and err is the handler result.
+func parseTimeParam(c echo.Context, name string) (value *time.Time, ok bool, err error) {
+	param := c.QueryParam(name)
+	if param == "" {
+		return nil, true, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, param)
+	if err != nil {
+		return nil, false, c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: name + " must be an RFC3339 time",
+		})
+	}
+	return &parsed, true, nil
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"testtask/internal/domain/portfolio"
+	"testtask/internal/domain/price"
+	httpports "testtask/internal/ports/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ListManualEntries handles GET /api/v1/portfolio/:portfolioID/manual-entries
+func (h *HandlerAdapter) ListManualEntries(c echo.Context) error {
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleViewer); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	entries, err := h.portfolioService.ListManualEntries(c.Request().Context(), portfolioID)
+	if err != nil {
+		return h.manualEntryError(c, portfolioID, err)
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPManualEntries(entries))
+}
+
+// CreateManualEntry handles POST /api/v1/portfolio/:portfolioID/manual-entries
+func (h *HandlerAdapter) CreateManualEntry(c echo.Context) error {
+	var req httpports.ManualEntryRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "invalid request body",
+		})
+	}
+
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleEditor); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	settings, err := toDomainManualEntry(req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: err.Error(),
+		})
+	}
+
+	entry := portfolio.NewManualEntry(portfolioID, *settings)
+	if err := h.portfolioService.CreateManualEntry(c.Request().Context(), entry); err != nil {
+		return h.manualEntryError(c, portfolioID, err)
+	}
+
+	return c.JSON(http.StatusCreated, httpports.ToHTTPManualEntry(entry))
+}
+
+// UpdateManualEntry handles PUT /api/v1/portfolio/:portfolioID/manual-entries/:entryID
+func (h *HandlerAdapter) UpdateManualEntry(c echo.Context) error {
+	var req httpports.ManualEntryRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "invalid request body",
+		})
+	}
+
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleEditor); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	entry, err := toDomainManualEntry(req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: err.Error(),
+		})
+	}
+	entry.ID = c.Param("entryID")
+	entry.PortfolioID = portfolioID
+
+	if err := h.portfolioService.UpdateManualEntry(c.Request().Context(), entry); err != nil {
+		return h.manualEntryError(c, portfolioID, err)
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPManualEntry(entry))
+}
+
+// DeleteManualEntry handles DELETE /api/v1/portfolio/:portfolioID/manual-entries/:entryID
+func (h *HandlerAdapter) DeleteManualEntry(c echo.Context) error {
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleEditor); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	if err := h.portfolioService.DeleteManualEntry(c.Request().Context(), portfolioID, c.Param("entryID")); err != nil {
+		return h.manualEntryError(c, portfolioID, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func toDomainManualEntry(req httpports.ManualEntryRequest) (*portfolio.ManualEntry, error) {
+	e := &portfolio.ManualEntry{
+		Name:         req.Name,
+		Side:         portfolio.ManualSide(strings.ToLower(req.Side)),
+		Category:     portfolio.ManualCategory(strings.ToLower(req.Category)),
+		Valuation:    portfolio.Valuation(strings.ToLower(req.Valuation)),
+		Currency:     req.Currency,
+		TokenAddress: req.TokenAddress,
+		Note:         req.Note,
+	}
+
+	amounts := []struct {
+		field    string
+		value    string
+		decimals int
+		target   **big.Int
+	}{
+		{"value", req.Value, price.CurrencyDecimal, &e.Value},
+		{"quantity", req.Quantity, portfolio.ManualQuantityDecimals, &e.Quantity},
+		{"unit_price", req.UnitPrice, price.CurrencyDecimal, &e.UnitPrice},
+	}
+	for _, a := range amounts {
+		if a.value == "" {
+			continue
+		}
+		parsed, err := httpports.ParseDecimal(a.value, a.decimals)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", a.field, err)
+		}
+		*a.target = parsed
+	}
+	return e, nil
+}
+
+func (h *HandlerAdapter) manualEntryError(c echo.Context, portfolioID string, err error) error {
+	switch {
+	case errors.Is(err, portfolio.ErrInvalidManualEntry):
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: err.Error(),
+		})
+	case errors.Is(err, portfolio.ErrManualEntryNotFound):
+		return c.JSON(http.StatusNotFound, httpports.ErrorResponse{
+			Error:   "Not Found",
+			Message: err.Error(),
+		})
+	}
+
+	return h.portfolioChangeError(c, portfolioID, err)
+}
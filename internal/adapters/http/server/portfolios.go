@@ -0,0 +1,99 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"testtask/internal/domain/holding"
+	"testtask/internal/domain/portfolio"
+	httpports "testtask/internal/ports/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// UpdatePortfolio handles PUT /api/v1/portfolio/:portfolioID
+func (h *HandlerAdapter) UpdatePortfolio(c echo.Context) error {
+	var req UpdatePortfolioRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "invalid request body",
+		})
+	}
+
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleEditor); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	p, err := h.portfolioService.UpdatePortfolio(c.Request().Context(), portfolioID, portfolio.Changes{
+		Name:        req.Name,
+		Description: req.Description,
+		Tags:        req.Tags,
+	})
+	if err != nil {
+		return h.portfolioChangeError(c, portfolioID, err)
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPPortfolio(p))
+}
+
+// DeletePortfolio handles DELETE /api/v1/portfolio/:portfolioID
+func (h *HandlerAdapter) DeletePortfolio(c echo.Context) error {
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleOwner); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	if err := h.portfolioService.DeletePortfolio(c.Request().Context(), portfolioID); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ArchivePortfolio handles POST /api/v1/portfolio/:portfolioID/archive
+func (h *HandlerAdapter) ArchivePortfolio(c echo.Context) error {
+	return h.setPortfolioArchived(c, true)
+}
+
+// UnarchivePortfolio handles POST /api/v1/portfolio/:portfolioID/unarchive
+func (h *HandlerAdapter) UnarchivePortfolio(c echo.Context) error {
+	return h.setPortfolioArchived(c, false)
+}
+
+func (h *HandlerAdapter) setPortfolioArchived(c echo.Context, archived bool) error {
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleAdmin); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	p, err := h.portfolioService.ArchivePortfolio(c.Request().Context(), portfolioID, archived)
+	if err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPPortfolio(p))
+}
+
+func (h *HandlerAdapter) portfolioChangeError(c echo.Context, portfolioID string, err error) error {
+	switch {
+	case errors.Is(err, portfolio.ErrInvalidPortfolio), errors.Is(err, holding.ErrInvalidEntry):
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: err.Error(),
+		})
+	case errors.Is(err, portfolio.ErrPortfolioArchived), errors.Is(err, holding.ErrInsufficientBalance), errors.Is(err, holding.ErrVersionConflict):
+		return c.JSON(http.StatusConflict, httpports.ErrorResponse{
+			Error:   "Conflict",
+			Message: err.Error(),
+		})
+	case errors.Is(err, holding.ErrHoldingNotFound):
+		return c.JSON(http.StatusNotFound, httpports.ErrorResponse{
+			Error:   "Not Found",
+			Message: err.Error(),
+		})
+	}
+
+	return h.portfolioLookupError(c, portfolioID, err)
+}
@@ -0,0 +1,177 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"testtask/internal/domain/portfolio"
+	"testtask/internal/domain/price"
+	httpports "testtask/internal/ports/http"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// ListPriceOverrides handles GET /api/v1/portfolio/:portfolioID/price-overrides
+func (h *HandlerAdapter) ListPriceOverrides(c echo.Context) error {
+	ctx := c.Request().Context()
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleViewer); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	overrides, err := h.priceService.ListOverrides(ctx, portfolioID)
+	if err != nil {
+		return h.priceOverrideError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPPriceOverrides(overrides))
+}
+
+// CreatePriceOverride handles POST /api/v1/portfolio/:portfolioID/price-overrides
+func (h *HandlerAdapter) CreatePriceOverride(c echo.Context) error {
+	var req httpports.PriceOverrideRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "invalid request body",
+		})
+	}
+
+	ctx := c.Request().Context()
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleEditor); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	settings, err := toDomainOverride(req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: err.Error(),
+		})
+	}
+
+	override := price.NewOverride(portfolioID, *settings)
+	if err := h.priceService.CreateOverride(ctx, override); err != nil {
+		return h.priceOverrideError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, httpports.ToHTTPPriceOverride(override))
+}
+
+// UpdatePriceOverride handles PUT /api/v1/portfolio/:portfolioID/price-overrides/:overrideID.
+// The token address of an override cannot be changed.
+func (h *HandlerAdapter) UpdatePriceOverride(c echo.Context) error {
+	var req httpports.PriceOverrideRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "invalid request body",
+		})
+	}
+
+	override, err := toDomainOverride(req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: err.Error(),
+		})
+	}
+	override.ID = c.Param("overrideID")
+	override.PortfolioID = c.Param("portfolioID")
+	if req.EffectiveFrom == nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "effective_from is required",
+		})
+	}
+	if err := h.authorize(c, override.PortfolioID, portfolio.RoleEditor); err != nil {
+		return h.portfolioLookupError(c, override.PortfolioID, err)
+	}
+
+	if err := h.priceService.UpdateOverride(c.Request().Context(), override); err != nil {
+		return h.priceOverrideError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPPriceOverride(override))
+}
+
+// DeletePriceOverride handles DELETE /api/v1/portfolio/:portfolioID/price-overrides/:overrideID
+func (h *HandlerAdapter) DeletePriceOverride(c echo.Context) error {
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleEditor); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	if err := h.priceService.DeleteOverride(c.Request().Context(), portfolioID, c.Param("overrideID")); err != nil {
+		return h.priceOverrideError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetPriceOverrideAudit handles GET /api/v1/portfolio/:portfolioID/price-overrides/audit
+func (h *HandlerAdapter) GetPriceOverrideAudit(c echo.Context) error {
+	ctx := c.Request().Context()
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleViewer); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	entries, err := h.priceService.ListOverrideAudit(ctx, portfolioID)
+	if err != nil {
+		return h.priceOverrideError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPPriceOverrideAudit(entries))
+}
+
+func toDomainOverride(req httpports.PriceOverrideRequest) (*price.Override, error) {
+	o := &price.Override{
+		TokenAddress:     strings.ToLower(req.TokenAddress),
+		Kind:             price.OverrideKind(strings.ToLower(req.Kind)),
+		ReferenceAddress: strings.ToLower(req.ReferenceAddress),
+		AdjustmentBps:    req.AdjustmentBps,
+		EffectiveTo:      req.EffectiveTo,
+		Reason:           strings.TrimSpace(req.Reason),
+	}
+	if req.EffectiveFrom != nil {
+		o.EffectiveFrom = req.EffectiveFrom.UTC()
+	}
+	if o.EffectiveTo != nil {
+		to := o.EffectiveTo.UTC()
+		o.EffectiveTo = &to
+	}
+	if req.Value != "" {
+		value, currency, err := parseManualPrice(req.Value, req.Currency)
+		if err != nil {
+			return nil, err
+		}
+		o.Value = value
+		o.Currency = currency
+	}
+	return o, nil
+}
+
+func (h *HandlerAdapter) priceOverrideError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, price.ErrInvalidOverride):
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: err.Error(),
+		})
+	case errors.Is(err, price.ErrOverrideNotFound):
+		return c.JSON(http.StatusNotFound, httpports.ErrorResponse{
+			Error:   "Not Found",
+			Message: err.Error(),
+		})
+	}
+
+	h.logger.Error("Price override operation failed", zap.Error(err))
+	return c.JSON(http.StatusInternalServerError, httpports.ErrorResponse{
+		Error:   "Internal Server Error",
+		Message: err.Error(),
+	})
+}
@@ -0,0 +1,181 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"testtask/internal/domain/price"
+	"testtask/internal/domain/token"
+	httpports "testtask/internal/ports/http"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+const maxPriceQueryTokens = 100
+
+// GetPrices handles GET /api/v1/prices?tokens=&currency=&at=
+func (h *HandlerAdapter) GetPrices(c echo.Context) error {
+	tokensParam := c.QueryParam("tokens")
+	if tokensParam == "" {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "tokens is required",
+		})
+	}
+
+	addresses := splitAddresses(tokensParam)
+	if len(addresses) > maxPriceQueryTokens {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: fmt.Sprintf("at most %d tokens are allowed", maxPriceQueryTokens),
+		})
+	}
+
+	currency, at, err := parsePriceQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: err.Error(),
+		})
+	}
+
+	ctx := c.Request().Context()
+	response := httpports.PricesResponse{
+		Currency: strings.ToUpper(currency),
+		At:       at,
+		Prices:   make([]*httpports.TokenPrice, 0, len(addresses)),
+	}
+
+	tokens := make([]*token.Token, 0, len(addresses))
+	for _, address := range addresses {
+		t, ok := h.tokensService.GetTokenByAddress(ctx, address)
+		if !ok {
+			response.UnknownTokens = append(response.UnknownTokens, address)
+			continue
+		}
+		tokens = append(tokens, t)
+	}
+
+	prices, err := h.queryPrices(ctx, tokens, currency, at)
+	if err != nil {
+		h.logger.Error("Failed to get prices", zap.Strings("tokens", addresses), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, httpports.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: err.Error(),
+		})
+	}
+
+	for _, t := range tokens {
+		p, ok := prices[t]
+		if !ok {
+			response.Unpriced = append(response.Unpriced, t.Address)
+			continue
+		}
+		response.Prices = append(response.Prices, httpports.ToHTTPTokenPrice(t, p))
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// GetTokenPrice handles GET /api/v1/tokens/:address/price?currency=&at=
+func (h *HandlerAdapter) GetTokenPrice(c echo.Context) error {
+	address := strings.ToLower(c.Param("address"))
+	if address == "" {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "address is required",
+		})
+	}
+
+	currency, at, err := parsePriceQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: err.Error(),
+		})
+	}
+
+	ctx := c.Request().Context()
+	t, ok := h.tokensService.GetTokenByAddress(ctx, address)
+	if !ok {
+		return c.JSON(http.StatusNotFound, httpports.ErrorResponse{
+			Error:   "Not Found",
+			Message: "not supported token address",
+		})
+	}
+
+	prices, err := h.queryPrices(ctx, []*token.Token{t}, currency, at)
+	if err != nil {
+		h.logger.Error("Failed to get token price", zap.String("address", address), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, httpports.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: err.Error(),
+		})
+	}
+
+	p, ok := prices[t]
+	if !ok {
+		return c.JSON(http.StatusNotFound, httpports.ErrorResponse{
+			Error:   "Not Found",
+			Message: "price not available for token",
+		})
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPTokenPrice(t, p))
+}
+
+func (h *HandlerAdapter) queryPrices(ctx context.Context, tokens []*token.Token, currency string, at *time.Time) (map[*token.Token]*price.Price, error) {
+	if at != nil {
+		return h.priceService.GetHistoricalPrices(ctx, tokens, currency, *at)
+	}
+	return h.priceService.GetPrices(ctx, tokens, currency)
+}
+
+// parsePriceQuery parses the currency and optional at query parameters.
+// at accepts RFC3339 or unix seconds and must not be in the future.
+func parsePriceQuery(c echo.Context) (string, *time.Time, error) {
+	currency := strings.ToLower(c.QueryParam("currency"))
+	if currency == "" {
+		currency = "usd"
+	}
+
+	atParam := c.QueryParam("at")
+	if atParam == "" {
+		return currency, nil, nil
+	}
+
+	at, err := time.Parse(time.RFC3339, atParam)
+	if err != nil {
+		seconds, convErr := strconv.ParseInt(atParam, 10, 64)
+		if convErr != nil {
+			return "", nil, fmt.Errorf("at must be an RFC3339 timestamp or unix seconds")
+		}
+		at = time.Unix(seconds, 0)
+	}
+	if at.After(time.Now()) {
+		return "", nil, fmt.Errorf("at must not be in the future")
+	}
+
+	at = at.UTC()
+	return currency, &at, nil
+}
+
+// splitAddresses splits a comma separated list of addresses, dropping blanks and duplicates
+func splitAddresses(param string) []string {
+	seen := make(map[string]bool)
+	var addresses []string
+	for _, part := range strings.Split(param, ",") {
+		address := strings.ToLower(strings.TrimSpace(part))
+		if address == "" || seen[address] {
+			continue
+		}
+		seen[address] = true
+		addresses = append(addresses, address)
+	}
+	return addresses
+}
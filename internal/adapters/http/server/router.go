@@ -5,26 +5,117 @@ import (
 	echoSwagger "github.com/swaggo/echo-swagger"
 )
 
-// registerRoutes registers all HTTP routes using Echo
-func registerRoutes(e *echo.Echo, handler *HandlerAdapter) {
+// registerRoutes registers all HTTP routes using Echo; auth guards the API v1 group
+func registerRoutes(e *echo.Echo, handler *HandlerAdapter, auth echo.MiddlewareFunc) {
 	// Health check
 	e.GET("/health", handler.HealthCheck)
 
 	// Swagger documentation
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
 
+	// Sign-up, unauthenticated
+	e.POST("/api/v1/users", handler.Register)
+
+	// Read-only share links authenticate with the token in the path
+	e.GET("/api/v1/shared/:token", handler.GetSharedPortfolio)
+	e.GET("/api/v1/shared/:token/assets", handler.GetSharedPortfolioAssets)
+
 	// API v1 group
-	v1 := e.Group("/api/v1")
+	v1 := e.Group("/api/v1", auth)
+
+	// User and credential endpoints
+	v1.GET("/users/me", handler.GetCurrentUser)
+	v1.GET("/users/me/api-keys", handler.ListAPIKeys)
+	v1.POST("/users/me/api-keys", handler.CreateAPIKey)
+	v1.DELETE("/users/me/api-keys/:keyID", handler.RevokeAPIKey)
+	v1.POST("/auth/token", handler.IssueAccessToken)
+
+	// Address book of the caller and the registry of well-known addresses
+	addressBook := v1.Group("/address-book")
+	addressBook.GET("", handler.ListAddressBook)
+	addressBook.POST("", handler.CreateAddressBookEntry)
+	addressBook.GET("/known", handler.ListKnownAddresses)
+	addressBook.PUT("/:entryID", handler.UpdateAddressBookEntry)
+	addressBook.DELETE("/:entryID", handler.DeleteAddressBookEntry)
 
 	// Portfolio endpoints
 	portfolio := v1.Group("/portfolio")
 	portfolio.GET("", handler.GetPortfolioList)
 	portfolio.POST("", handler.CreatePortfolio)
 	portfolio.GET("/:portfolioID", handler.GetPortfolio)
+	portfolio.PUT("/:portfolioID", handler.UpdatePortfolio)
+	portfolio.DELETE("/:portfolioID", handler.DeletePortfolio)
+	portfolio.POST("/:portfolioID/archive", handler.ArchivePortfolio)
+	portfolio.POST("/:portfolioID/unarchive", handler.UnarchivePortfolio)
 	portfolio.GET("/:portfolioID/assets", handler.GetPortfolioAssets)
+	portfolio.GET("/:portfolioID/movers", handler.GetTopMovers)
+	portfolio.GET("/:portfolioID/holdings", handler.GetHoldings)
 	portfolio.POST("/:portfolioID/holdings", handler.AddHolding)
+	// The colon of the custom methods is escaped so Echo does not read it as a parameter
+	portfolio.POST("/:portfolioID/holdings\\:batch", handler.ApplyHoldingBatch)
+	portfolio.POST("/:portfolioID/holdings\\:import", handler.ImportHoldings)
 	portfolio.PUT("/:portfolioID/holdings/:holdingID", handler.UpdateHolding)
-	portfolio.DELETE("/holdings/:holdingID", handler.DeleteHolding)
+	portfolio.DELETE("/:portfolioID/holdings/:holdingID", handler.DeleteHolding)
+	portfolio.GET("/:portfolioID/ledger", handler.ListLedger)
+	portfolio.POST("/:portfolioID/ledger", handler.RecordLedgerEntry)
+	portfolio.GET("/:portfolioID/statements", handler.ListStatementRecords)
+	portfolio.POST("/:portfolioID/statements", handler.ImportStatement)
+	portfolio.GET("/:portfolioID/connections", handler.ListConnections)
+	portfolio.POST("/:portfolioID/connections", handler.CreateConnection)
+	portfolio.DELETE("/:portfolioID/connections/:connectionID", handler.DeleteConnection)
+	portfolio.POST("/:portfolioID/connections/:connectionID/sync", handler.SyncConnection)
+	portfolio.GET("/:portfolioID/tax-report", handler.GetTaxReport)
+	portfolio.GET("/:portfolioID/income/classifications", handler.ListIncomeClassifications)
+	portfolio.PUT("/:portfolioID/income/classifications/:transactionID", handler.SetIncomeClassification)
+	portfolio.DELETE("/:portfolioID/income/classifications/:transactionID", handler.DeleteIncomeClassification)
+	portfolio.GET("/:portfolioID/income/accruals", handler.ListIncomeAccruals)
+	portfolio.POST("/:portfolioID/income/accruals", handler.ObserveIncomeAccruals)
+	portfolio.GET("/:portfolioID/annotations", handler.ListAnnotations)
+	portfolio.PUT("/:portfolioID/annotations/:transactionID", handler.SetAnnotation)
+	portfolio.DELETE("/:portfolioID/annotations/:transactionID", handler.DeleteAnnotation)
+	portfolio.GET("/:portfolioID/manual-transactions", handler.ListManualTransactions)
+	portfolio.POST("/:portfolioID/manual-transactions", handler.CreateManualTransaction)
+	portfolio.DELETE("/:portfolioID/manual-transactions/:transactionID", handler.DeleteManualTransaction)
+	portfolio.GET("/:portfolioID/manual-entries", handler.ListManualEntries)
+	portfolio.POST("/:portfolioID/manual-entries", handler.CreateManualEntry)
+	portfolio.PUT("/:portfolioID/manual-entries/:entryID", handler.UpdateManualEntry)
+	portfolio.DELETE("/:portfolioID/manual-entries/:entryID", handler.DeleteManualEntry)
+	portfolio.GET("/:portfolioID/price-overrides", handler.ListPriceOverrides)
+	portfolio.POST("/:portfolioID/price-overrides", handler.CreatePriceOverride)
+	portfolio.GET("/:portfolioID/price-overrides/audit", handler.GetPriceOverrideAudit)
+	portfolio.PUT("/:portfolioID/price-overrides/:overrideID", handler.UpdatePriceOverride)
+	portfolio.DELETE("/:portfolioID/price-overrides/:overrideID", handler.DeletePriceOverride)
+	portfolio.GET("/:portfolioID/members", handler.ListMembers)
+	portfolio.POST("/:portfolioID/members", handler.AddMember)
+	portfolio.PUT("/:portfolioID/members/:userID", handler.UpdateMember)
+	portfolio.DELETE("/:portfolioID/members/:userID", handler.RemoveMember)
+	portfolio.GET("/:portfolioID/share-tokens", handler.ListShareTokens)
+	portfolio.POST("/:portfolioID/share-tokens", handler.CreateShareToken)
+	portfolio.DELETE("/:portfolioID/share-tokens/:tokenID", handler.RevokeShareToken)
+
+	// Audit log
+	v1.GET("/audit", handler.GetAuditLog)
+
+	// Price endpoints
+	v1.GET("/prices", handler.GetPrices)
+
+	// Tax endpoints
+	v1.GET("/tax/jurisdictions", handler.ListTaxJurisdictions)
+
+	// Token endpoints
+	tokens := v1.Group("/tokens")
+	tokens.GET("", handler.SearchTokens)
+	tokens.GET("/custom", handler.ListCustomTokens)
+	tokens.POST("/custom", handler.CreateCustomToken)
+	tokens.PUT("/custom/:id/price", handler.SetCustomTokenPrice)
+	tokens.DELETE("/custom/:id", handler.DeleteCustomToken)
+	tokens.GET("/:address", handler.GetToken)
+	tokens.GET("/:address/price", handler.GetTokenPrice)
+
+	// Admin endpoints, limited to the users configured in AUTH_ADMIN_USER_IDS
+	admin := v1.Group("/admin", requireAdmin)
+	admin.GET("/tokens/reload", handler.GetTokenReload)
+	admin.POST("/tokens/reload", handler.ReloadTokens)
 
 	//Transaction endpoints
 	transactions := v1.Group("/transactions")
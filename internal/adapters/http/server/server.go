@@ -11,6 +11,7 @@ import (
 	"time"
 
 	loggeradapter "testtask/internal/adapters/logger"
+	"testtask/internal/domain/audit"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -37,13 +38,18 @@ type Config struct {
 func NewServer(cfg Config, handler *HandlerAdapter, logger *loggeradapter.Logger) *Server {
 	e := echo.New()
 
-	// Middleware
+	// Middleware; the request ID is echoed in X-Request-ID and recorded in the audit log
+	e.Use(middleware.RequestIDWithConfig(middleware.RequestIDConfig{
+		RequestIDHandler: func(c echo.Context, id string) {
+			c.SetRequest(c.Request().WithContext(audit.WithRequestID(c.Request().Context(), id)))
+		},
+	}))
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
 	e.Use(middleware.CORS())
 
-	// Register routes
-	registerRoutes(e, handler)
+	// Register routes; everything under /api/v1 except sign-up requires authentication
+	registerRoutes(e, handler, authenticate(handler.authService, logger))
 
 	// Configure server
 	addr := fmt.Sprintf("%s:%s", cfg.Host, cfg.Port)
@@ -0,0 +1,235 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"testtask/internal/domain/portfolio"
+	"testtask/internal/domain/user"
+	httpports "testtask/internal/ports/http"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// authorize checks that the caller holds at least the required role on the portfolio.
+// Errors are rendered with portfolioLookupError: 404 without access, 403 below the role.
+func (h *HandlerAdapter) authorize(c echo.Context, portfolioID string, required portfolio.Role) error {
+	_, err := h.sharingService.Authorize(c.Request().Context(), portfolioID, required)
+	return err
+}
+
+// ListMembers handles GET /api/v1/portfolio/:portfolioID/members
+func (h *HandlerAdapter) ListMembers(c echo.Context) error {
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleAdmin); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	members, err := h.sharingService.ListMembers(c.Request().Context(), portfolioID)
+	if err != nil {
+		return h.sharingError(c, portfolioID, err)
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPMembers(members))
+}
+
+// AddMember handles POST /api/v1/portfolio/:portfolioID/members
+func (h *HandlerAdapter) AddMember(c echo.Context) error {
+	var req httpports.AddMemberRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "invalid request body",
+		})
+	}
+
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleAdmin); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	member, err := h.sharingService.AddMember(c.Request().Context(), portfolioID, req.Email, req.Role)
+	if err != nil {
+		return h.sharingError(c, portfolioID, err)
+	}
+
+	return c.JSON(http.StatusCreated, httpports.ToHTTPMember(member))
+}
+
+// UpdateMember handles PUT /api/v1/portfolio/:portfolioID/members/:userID
+func (h *HandlerAdapter) UpdateMember(c echo.Context) error {
+	var req httpports.UpdateMemberRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "invalid request body",
+		})
+	}
+
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleAdmin); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	member, err := h.sharingService.UpdateMember(c.Request().Context(), portfolioID, c.Param("userID"), req.Role)
+	if err != nil {
+		return h.sharingError(c, portfolioID, err)
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPMember(member))
+}
+
+// RemoveMember handles DELETE /api/v1/portfolio/:portfolioID/members/:userID.
+// Members may also remove themselves to leave a shared portfolio.
+func (h *HandlerAdapter) RemoveMember(c echo.Context) error {
+	portfolioID := c.Param("portfolioID")
+	userID := c.Param("userID")
+
+	required := portfolio.RoleAdmin
+	if userID == currentUser(c).ID {
+		required = portfolio.RoleViewer
+	}
+	if err := h.authorize(c, portfolioID, required); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	if err := h.sharingService.RemoveMember(c.Request().Context(), portfolioID, userID); err != nil {
+		return h.sharingError(c, portfolioID, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListShareTokens handles GET /api/v1/portfolio/:portfolioID/share-tokens
+func (h *HandlerAdapter) ListShareTokens(c echo.Context) error {
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleAdmin); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	tokens, err := h.sharingService.ListShareTokens(c.Request().Context(), portfolioID)
+	if err != nil {
+		return h.sharingError(c, portfolioID, err)
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPShareTokens(tokens, time.Now()))
+}
+
+// CreateShareToken handles POST /api/v1/portfolio/:portfolioID/share-tokens.
+// The token is returned once together with the read-only URL.
+func (h *HandlerAdapter) CreateShareToken(c echo.Context) error {
+	var req httpports.CreateShareTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "invalid request body",
+		})
+	}
+
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleAdmin); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	t, plain, err := h.sharingService.CreateShareToken(c.Request().Context(), portfolioID, req.Name, req.ExpiresAt)
+	if err != nil {
+		return h.sharingError(c, portfolioID, err)
+	}
+
+	return c.JSON(http.StatusCreated, httpports.CreateShareTokenResponse{
+		ShareToken: httpports.ToHTTPShareToken(t, time.Now()),
+		Token:      plain,
+		URL:        c.Scheme() + "://" + c.Request().Host + "/api/v1/shared/" + plain,
+	})
+}
+
+// RevokeShareToken handles DELETE /api/v1/portfolio/:portfolioID/share-tokens/:tokenID
+func (h *HandlerAdapter) RevokeShareToken(c echo.Context) error {
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleAdmin); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	if err := h.sharingService.RevokeShareToken(c.Request().Context(), portfolioID, c.Param("tokenID")); err != nil {
+		return h.sharingError(c, portfolioID, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetSharedPortfolio handles GET /api/v1/shared/:token, the unauthenticated read-only view
+func (h *HandlerAdapter) GetSharedPortfolio(c echo.Context) error {
+	portfolioID, ok, err := h.resolveShareToken(c)
+	if !ok {
+		return err
+	}
+
+	p, err := h.portfolioService.GetPortfolio(c.Request().Context(), portfolioID)
+	if err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPPortfolio(p))
+}
+
+// GetSharedPortfolioAssets handles GET /api/v1/shared/:token/assets with live prices
+func (h *HandlerAdapter) GetSharedPortfolioAssets(c echo.Context) error {
+	portfolioID, ok, err := h.resolveShareToken(c)
+	if !ok {
+		return err
+	}
+
+	return h.portfolioAssets(c, portfolioID)
+}
+
+// resolveShareToken returns the portfolio of the share token in the path and scopes the
+// request to the portfolio's owner, since share links have no caller. When ok is false
+// the error response has been written and err is the handler result.
+func (h *HandlerAdapter) resolveShareToken(c echo.Context) (portfolioID string, ok bool, err error) {
+	t, err := h.sharingService.ResolveShareToken(c.Request().Context(), c.Param("token"))
+	if err != nil {
+		if errors.Is(err, portfolio.ErrInvalidShareToken) {
+			return "", false, c.JSON(http.StatusNotFound, httpports.ErrorResponse{
+				Error:   "Not Found",
+				Message: "share link is invalid, expired or revoked",
+			})
+		}
+		h.logger.Error("Failed to resolve share token", zap.Error(err))
+		return "", false, c.JSON(http.StatusInternalServerError, httpports.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "failed to resolve share link",
+		})
+	}
+
+	c.SetRequest(c.Request().WithContext(user.AsOwner(c.Request().Context(), t.OwnerID)))
+	return t.PortfolioID, true, nil
+}
+
+func (h *HandlerAdapter) sharingError(c echo.Context, portfolioID string, err error) error {
+	switch {
+	case errors.Is(err, portfolio.ErrInvalidRole), errors.Is(err, portfolio.ErrInvalidShareToken):
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: err.Error(),
+		})
+	case errors.Is(err, portfolio.ErrMemberExists):
+		return c.JSON(http.StatusConflict, httpports.ErrorResponse{
+			Error:   "Conflict",
+			Message: err.Error(),
+		})
+	case errors.Is(err, user.ErrUserNotFound):
+		return c.JSON(http.StatusNotFound, httpports.ErrorResponse{
+			Error:   "Not Found",
+			Message: "no user with this email",
+		})
+	case errors.Is(err, portfolio.ErrMemberNotFound), errors.Is(err, portfolio.ErrShareTokenNotFound):
+		return c.JSON(http.StatusNotFound, httpports.ErrorResponse{
+			Error:   "Not Found",
+			Message: err.Error(),
+		})
+	}
+
+	return h.portfolioLookupError(c, portfolioID, err)
+}
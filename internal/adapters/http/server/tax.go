@@ -0,0 +1,94 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"testtask/internal/domain/portfolio"
+	"testtask/internal/domain/tax"
+	httpports "testtask/internal/ports/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ListTaxJurisdictions handles GET /api/v1/tax/jurisdictions
+func (h *HandlerAdapter) ListTaxJurisdictions(c echo.Context) error {
+	return c.JSON(http.StatusOK, httpports.ToHTTPJurisdictions(h.taxService.Jurisdictions()))
+}
+
+// GetTaxReport handles GET /api/v1/portfolio/:portfolioID/tax-report?year=&jurisdiction=&method=&currency=&format=.
+// format is json (default), csv, form8949 or income.
+func (h *HandlerAdapter) GetTaxReport(c echo.Context) error {
+	portfolioID := c.Param("portfolioID")
+	if err := h.authorize(c, portfolioID, portfolio.RoleViewer); err != nil {
+		return h.portfolioLookupError(c, portfolioID, err)
+	}
+
+	req := tax.Request{PortfolioID: portfolioID, Jurisdiction: c.QueryParam("jurisdiction"), Currency: c.QueryParam("currency")}
+	year, err := strconv.Atoi(c.QueryParam("year"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "year is required and must be a number",
+		})
+	}
+	req.Year = year
+	if req.Jurisdiction == "" {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "jurisdiction is required",
+		})
+	}
+	if value := c.QueryParam("method"); value != "" {
+		if req.Method, err = tax.ParseMethod(value); err != nil {
+			return h.taxError(c, portfolioID, err)
+		}
+	}
+	format := c.QueryParam("format")
+	switch format {
+	case "", "json", "csv", "form8949", "income":
+	default:
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "format must be json, csv, form8949 or income",
+		})
+	}
+
+	report, err := h.taxService.GenerateReport(c.Request().Context(), req)
+	if err != nil {
+		return h.taxError(c, portfolioID, err)
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case "csv":
+		err = httpports.WriteTaxReportCSV(&buf, report)
+	case "form8949":
+		err = httpports.WriteForm8949CSV(&buf, report)
+	case "income":
+		err = httpports.WriteIncomeCSV(&buf, report)
+	default:
+		return c.JSON(http.StatusOK, httpports.ToHTTPTaxReport(report))
+	}
+	if err != nil {
+		return h.taxError(c, portfolioID, err)
+	}
+
+	filename := fmt.Sprintf("%s-%s-%s-%d.csv", format, portfolioID, report.Jurisdiction, report.Year)
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+	return c.Blob(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+func (h *HandlerAdapter) taxError(c echo.Context, portfolioID string, err error) error {
+	if errors.Is(err, tax.ErrUnknownJurisdiction) || errors.Is(err, tax.ErrInvalidReport) {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: err.Error(),
+		})
+	}
+
+	return h.portfolioLookupError(c, portfolioID, err)
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"testtask/internal/domain/token"
+	httpports "testtask/internal/ports/http"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+const maxTokenSearchPageSize = 100
+
+// SearchTokens handles GET /api/v1/tokens?q=&page=&page_size=
+func (h *HandlerAdapter) SearchTokens(c echo.Context) error {
+	query := c.QueryParam("q")
+	page := 1
+	pageSize := 20
+
+	if pageParam := c.QueryParam("page"); pageParam != "" {
+		if p, err := strconv.Atoi(pageParam); err == nil && p > 0 {
+			page = p
+		}
+	}
+	if pageSizeParam := c.QueryParam("page_size"); pageSizeParam != "" {
+		if ps, err := strconv.Atoi(pageSizeParam); err == nil && ps > 0 {
+			pageSize = ps
+		}
+	}
+	if pageSize > maxTokenSearchPageSize {
+		pageSize = maxTokenSearchPageSize
+	}
+
+	tokens, total, err := h.tokensService.SearchTokens(c.Request().Context(), query, page, pageSize)
+	if err != nil {
+		h.logger.Error("Failed to search tokens", zap.String("query", query), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, httpports.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: err.Error(),
+		})
+	}
+
+	totalPages := (total + pageSize - 1) / pageSize
+	if totalPages < 1 {
+		totalPages = 1
+	}
+
+	return c.JSON(http.StatusOK, httpports.PaginatedResponse{
+		Data:       toHTTPTokenInfos(tokens),
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: totalPages,
+	})
+}
+
+// GetToken handles GET /api/v1/tokens/:address
+func (h *HandlerAdapter) GetToken(c echo.Context) error {
+	address := strings.ToLower(c.Param("address"))
+	if address == "" {
+		return c.JSON(http.StatusBadRequest, httpports.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "address is required",
+		})
+	}
+
+	t, ok := h.tokensService.GetTokenByAddress(c.Request().Context(), address)
+	if !ok {
+		return c.JSON(http.StatusNotFound, httpports.ErrorResponse{
+			Error:   "Not Found",
+			Message: "not supported token address",
+		})
+	}
+
+	return c.JSON(http.StatusOK, httpports.ToHTTPTokenInfo(t))
+}
+
+func toHTTPTokenInfos(tokens []*token.Token) []*httpports.TokenInfo {
+	result := make([]*httpports.TokenInfo, 0, len(tokens))
+	for _, t := range tokens {
+		result = append(result, httpports.ToHTTPTokenInfo(t))
+	}
+	return result
+}
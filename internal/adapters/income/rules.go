@@ -0,0 +1,58 @@
+package income
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"testtask/internal/domain/income"
+	"testtask/internal/domain/transaction"
+)
+
+// rulesFile is the JSON layout of the income rules file, see static/income_rules.json
+type rulesFile struct {
+	Distributors []struct {
+		Address  string `json:"address"`
+		Name     string `json:"name"`
+		Category string `json:"category"`
+	} `json:"distributors"`
+	RebasingTokens []struct {
+		Address  string `json:"address"`
+		Symbol   string `json:"symbol"`
+		Decimals uint8  `json:"decimals"`
+		Category string `json:"category"`
+	} `json:"rebasing_tokens"`
+}
+
+// LoadRuleSet reads the known income distributors and rebasing tokens from a JSON file
+func LoadRuleSet(path string) (*income.RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read income rules: %w", err)
+	}
+
+	var file rulesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal income rules: %w", err)
+	}
+
+	distributors := make([]*income.Distributor, 0, len(file.Distributors))
+	for _, d := range file.Distributors {
+		distributors = append(distributors, &income.Distributor{
+			Address:  d.Address,
+			Name:     d.Name,
+			Category: transaction.IncomeCategory(d.Category),
+		})
+	}
+	rebasing := make([]*income.RebasingToken, 0, len(file.RebasingTokens))
+	for _, t := range file.RebasingTokens {
+		rebasing = append(rebasing, &income.RebasingToken{
+			Address:  t.Address,
+			Symbol:   t.Symbol,
+			Decimals: t.Decimals,
+			Category: transaction.IncomeCategory(t.Category),
+		})
+	}
+
+	return income.NewRuleSet(distributors, rebasing)
+}
@@ -0,0 +1,22 @@
+package income
+
+import (
+	"testing"
+
+	"testtask/internal/domain/transaction"
+)
+
+func TestLoadRuleSet_Bundled(t *testing.T) {
+	rules, err := LoadRuleSet("../../../static/income_rules.json")
+	if err != nil {
+		t.Fatalf("LoadRuleSet() error = %v", err)
+	}
+
+	airdrop := &transaction.Transaction{From: "0x090D4613473dEE047c3f2706764f49E0821D256e", Direction: transaction.TransactionDirectionIn}
+	if category, ok := rules.Detect(airdrop); !ok || category != transaction.IncomeAirdrop {
+		t.Errorf("Detect(UNI claim) = %q, %v, want airdrop", category, ok)
+	}
+	if len(rules.RebasingTokens()) == 0 {
+		t.Error("RebasingTokens() is empty, want stETH and Aave deposit tokens")
+	}
+}
@@ -0,0 +1,141 @@
+package income
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/big"
+	"time"
+
+	sqliteadapter "testtask/internal/adapters/sqlite"
+	"testtask/internal/domain/income"
+	"testtask/internal/domain/transaction"
+)
+
+// SQLiteRepository implements income.Repository on top of the income_classifications and
+// income_accruals tables
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteRepository(db *sql.DB) *SQLiteRepository {
+	return &SQLiteRepository{db: db}
+}
+
+// ListClassifications returns the portfolio's classifications by transaction ID
+func (r *SQLiteRepository) ListClassifications(ctx context.Context, portfolioID string) ([]*income.Classification, error) {
+	scope, scopeArgs := sqliteadapter.PortfolioScope(ctx, "portfolio_id")
+	query := `
+		SELECT portfolio_id, transaction_id, category, note, updated_at
+		FROM income_classifications
+		WHERE portfolio_id = ?` + scope + `
+		ORDER BY transaction_id ASC
+	`
+	rows, err := r.db.QueryContext(ctx, query, append([]interface{}{portfolioID}, scopeArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list income classifications: %w", err)
+	}
+	defer rows.Close()
+
+	classifications := make([]*income.Classification, 0)
+	for rows.Next() {
+		var (
+			c                   income.Classification
+			category, updatedAt string
+		)
+		if err := rows.Scan(&c.PortfolioID, &c.TransactionID, &category, &c.Note, &updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan income classification: %w", err)
+		}
+		c.Category = transaction.IncomeCategory(category)
+		if c.UpdatedAt, err = time.Parse(time.RFC3339Nano, updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to parse income classification time: %w", err)
+		}
+		classifications = append(classifications, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list income classifications: %w", err)
+	}
+	return classifications, nil
+}
+
+func (r *SQLiteRepository) SetClassification(ctx context.Context, c *income.Classification) error {
+	query := `
+		INSERT INTO income_classifications (portfolio_id, transaction_id, category, note, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (portfolio_id, transaction_id) DO UPDATE SET
+			category = excluded.category, note = excluded.note, updated_at = excluded.updated_at
+	`
+	_, err := r.db.ExecContext(ctx, query, c.PortfolioID, c.TransactionID, string(c.Category), c.Note, c.UpdatedAt.UTC().Format(time.RFC3339Nano))
+	if err != nil {
+		return fmt.Errorf("failed to set income classification: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLiteRepository) DeleteClassification(ctx context.Context, portfolioID, transactionID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM income_classifications WHERE portfolio_id = ? AND transaction_id = ?`, portfolioID, transactionID)
+	if err != nil {
+		return fmt.Errorf("failed to delete income classification: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete income classification: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w: transaction_id=%s", income.ErrClassificationNotFound, transactionID)
+	}
+	return nil
+}
+
+// ListAccruals returns the portfolio's accruals, oldest first
+func (r *SQLiteRepository) ListAccruals(ctx context.Context, portfolioID string) ([]*income.Accrual, error) {
+	scope, scopeArgs := sqliteadapter.PortfolioScope(ctx, "portfolio_id")
+	query := `
+		SELECT id, portfolio_id, token_address, symbol, decimals, quantity, category, observed_at
+		FROM income_accruals
+		WHERE portfolio_id = ?` + scope + `
+		ORDER BY julianday(observed_at) ASC, id ASC
+	`
+	rows, err := r.db.QueryContext(ctx, query, append([]interface{}{portfolioID}, scopeArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list income accruals: %w", err)
+	}
+	defer rows.Close()
+
+	accruals := make([]*income.Accrual, 0)
+	for rows.Next() {
+		var (
+			a                              income.Accrual
+			quantity, category, observedAt string
+		)
+		if err := rows.Scan(&a.ID, &a.PortfolioID, &a.TokenAddress, &a.Symbol, &a.Decimals, &quantity, &category, &observedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan income accrual: %w", err)
+		}
+		a.Category = transaction.IncomeCategory(category)
+
+		var ok bool
+		if a.Quantity, ok = new(big.Int).SetString(quantity, 10); !ok {
+			return nil, fmt.Errorf("invalid quantity %q of income accrual %s", quantity, a.ID)
+		}
+		if a.ObservedAt, err = time.Parse(time.RFC3339Nano, observedAt); err != nil {
+			return nil, fmt.Errorf("failed to parse income accrual time: %w", err)
+		}
+		accruals = append(accruals, &a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list income accruals: %w", err)
+	}
+	return accruals, nil
+}
+
+func (r *SQLiteRepository) SaveAccrual(ctx context.Context, a *income.Accrual) error {
+	query := `
+		INSERT INTO income_accruals (id, portfolio_id, token_address, symbol, decimals, quantity, category, observed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.ExecContext(ctx, query, a.ID, a.PortfolioID, a.TokenAddress, a.Symbol, a.Decimals, a.Quantity.String(), string(a.Category), a.ObservedAt.UTC().Format(time.RFC3339Nano))
+	if err != nil {
+		return fmt.Errorf("failed to save income accrual: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package income
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"testtask/internal/domain/income"
+	"testtask/internal/domain/transaction"
+	"testtask/internal/domain/user"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// setupTestDB creates an in-memory SQLite database with the income schema
+func setupTestDB(t *testing.T) (*SQLiteRepository, func()) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	// Every connection to :memory: is a separate database
+	db.SetMaxOpenConns(1)
+
+	schema := `
+	CREATE TABLE IF NOT EXISTS income_classifications (
+		portfolio_id TEXT NOT NULL,
+		transaction_id TEXT NOT NULL,
+		category TEXT NOT NULL,
+		note TEXT NOT NULL DEFAULT '',
+		updated_at DATETIME NOT NULL,
+		PRIMARY KEY (portfolio_id, transaction_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS income_accruals (
+		id TEXT PRIMARY KEY,
+		portfolio_id TEXT NOT NULL,
+		token_address TEXT NOT NULL,
+		symbol TEXT NOT NULL DEFAULT '',
+		decimals INTEGER NOT NULL,
+		quantity TEXT NOT NULL,
+		category TEXT NOT NULL,
+		observed_at DATETIME NOT NULL
+	);
+	`
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	return NewSQLiteRepository(db), func() { db.Close() }
+}
+
+func TestSQLiteRepository_Classifications(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := user.AsSystem(context.Background())
+
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, c := range []*income.Classification{
+		{PortfolioID: "p1", TransactionID: "0xabc", Category: transaction.IncomeSalary, UpdatedAt: at},
+		{PortfolioID: "p1", TransactionID: "0xabc", Category: transaction.IncomeInterest, Note: "savings", UpdatedAt: at.Add(time.Hour)},
+		{PortfolioID: "p2", TransactionID: "0xdef", Category: income.NotIncome, UpdatedAt: at},
+	} {
+		if err := repo.SetClassification(ctx, c); err != nil {
+			t.Fatalf("SetClassification() error = %v", err)
+		}
+	}
+
+	got, err := repo.ListClassifications(ctx, "p1")
+	if err != nil {
+		t.Fatalf("ListClassifications() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Category != transaction.IncomeInterest || got[0].Note != "savings" || !got[0].UpdatedAt.Equal(at.Add(time.Hour)) {
+		t.Fatalf("ListClassifications() = %+v, want the replaced interest classification", got)
+	}
+
+	if err := repo.DeleteClassification(ctx, "p1", "0xabc"); err != nil {
+		t.Fatalf("DeleteClassification() error = %v", err)
+	}
+	if err := repo.DeleteClassification(ctx, "p1", "0xdef"); !errors.Is(err, income.ErrClassificationNotFound) {
+		t.Errorf("DeleteClassification(other portfolio) error = %v, want ErrClassificationNotFound", err)
+	}
+}
+
+func TestSQLiteRepository_Accruals(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := user.AsSystem(context.Background())
+
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	quantity, _ := new(big.Int).SetString("12500000000000000", 10)
+	for _, a := range []*income.Accrual{
+		{ID: "a2", PortfolioID: "p1", TokenAddress: "0xsteth", Symbol: "stETH", Decimals: 18, Quantity: quantity, Category: transaction.IncomeStakingReward, ObservedAt: at.Add(time.Hour)},
+		{ID: "a1", PortfolioID: "p1", TokenAddress: "0xsteth", Symbol: "stETH", Decimals: 18, Quantity: big.NewInt(1), Category: transaction.IncomeStakingReward, ObservedAt: at},
+		{ID: "a3", PortfolioID: "p2", TokenAddress: "0xsteth", Decimals: 18, Quantity: big.NewInt(1), Category: transaction.IncomeStakingReward, ObservedAt: at},
+	} {
+		if err := repo.SaveAccrual(ctx, a); err != nil {
+			t.Fatalf("SaveAccrual() error = %v", err)
+		}
+	}
+
+	got, err := repo.ListAccruals(ctx, "p1")
+	if err != nil {
+		t.Fatalf("ListAccruals() error = %v", err)
+	}
+	if len(got) != 2 || got[0].ID != "a1" || got[1].Quantity.Cmp(quantity) != 0 || got[1].Decimals != 18 || got[1].Symbol != "stETH" {
+		t.Errorf("ListAccruals() = %+v, want a1 then a2 of p1", got)
+	}
+}
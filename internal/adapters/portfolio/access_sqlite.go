@@ -0,0 +1,262 @@
+package portfolio
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	sqliteadapter "testtask/internal/adapters/sqlite"
+	"testtask/internal/domain/portfolio"
+)
+
+// SQLiteAccessRepository implements portfolio.AccessRepository on top of the
+// portfolio_members and share_tokens tables
+type SQLiteAccessRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteAccessRepository(db *sql.DB) *SQLiteAccessRepository {
+	return &SQLiteAccessRepository{db: db}
+}
+
+const shareTokenColumns = `id, portfolio_id, name, prefix, token_hash, created_by, expires_at, revoked_at, last_used_at, created_at`
+
+func (r *SQLiteAccessRepository) Role(ctx context.Context, portfolioID, userID string) (portfolio.Role, error) {
+	query := `
+		SELECT CASE WHEN p.owner_id = ? THEN 'owner' ELSE m.role END
+		FROM portfolios p
+		LEFT JOIN portfolio_members m ON m.portfolio_id = p.id AND m.user_id = ?
+		WHERE p.id = ?
+	`
+
+	var role sql.NullString
+	err := r.db.QueryRowContext(ctx, query, userID, userID, portfolioID).Scan(&role)
+	if err != nil && err != sql.ErrNoRows {
+		return "", fmt.Errorf("failed to get portfolio role: %w", err)
+	}
+	// Portfolios the user cannot access are reported as missing so their existence is not revealed
+	if err == sql.ErrNoRows || !role.Valid {
+		return "", fmt.Errorf("%w: portfolio_id=%s", portfolio.ErrPortfolioNotFound, portfolioID)
+	}
+
+	return portfolio.Role(role.String), nil
+}
+
+func (r *SQLiteAccessRepository) ListMembers(ctx context.Context, portfolioID string) ([]*portfolio.Member, error) {
+	query := `
+		SELECT m.portfolio_id, m.user_id, COALESCE(u.email, ''), m.role, m.created_at, m.updated_at
+		FROM portfolio_members m
+		LEFT JOIN users u ON u.id = m.user_id
+		WHERE m.portfolio_id = ?
+		ORDER BY m.created_at ASC, m.user_id ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list portfolio members: %w", err)
+	}
+	defer rows.Close()
+
+	members := make([]*portfolio.Member, 0)
+	for rows.Next() {
+		var (
+			m                    portfolio.Member
+			role                 string
+			createdAt, updatedAt string
+		)
+		if err := rows.Scan(&m.PortfolioID, &m.UserID, &m.Email, &role, &createdAt, &updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan portfolio member: %w", err)
+		}
+		m.Role = portfolio.Role(role)
+		if m.CreatedAt, err = time.Parse(time.RFC3339, createdAt); err != nil {
+			return nil, fmt.Errorf("failed to parse created_at: %w", err)
+		}
+		if m.UpdatedAt, err = time.Parse(time.RFC3339, updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to parse updated_at: %w", err)
+		}
+		members = append(members, &m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating portfolio members: %w", err)
+	}
+
+	return members, nil
+}
+
+func (r *SQLiteAccessRepository) AddMember(ctx context.Context, m *portfolio.Member) error {
+	query := `
+		INSERT INTO portfolio_members (portfolio_id, user_id, role, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, m.PortfolioID, m.UserID, string(m.Role),
+		m.CreatedAt.UTC().Format(time.RFC3339), m.UpdatedAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		if sqliteadapter.IsUniqueViolation(err) {
+			return fmt.Errorf("%w: user_id=%s", portfolio.ErrMemberExists, m.UserID)
+		}
+		return fmt.Errorf("failed to add portfolio member: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SQLiteAccessRepository) UpdateMember(ctx context.Context, m *portfolio.Member) error {
+	query := `UPDATE portfolio_members SET role = ?, updated_at = ? WHERE portfolio_id = ? AND user_id = ?`
+
+	result, err := r.db.ExecContext(ctx, query, string(m.Role), m.UpdatedAt.UTC().Format(time.RFC3339), m.PortfolioID, m.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to update portfolio member: %w", err)
+	}
+
+	return requireAffected(result, portfolio.ErrMemberNotFound, m.UserID)
+}
+
+func (r *SQLiteAccessRepository) RemoveMember(ctx context.Context, portfolioID, userID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM portfolio_members WHERE portfolio_id = ? AND user_id = ?`, portfolioID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove portfolio member: %w", err)
+	}
+
+	return requireAffected(result, portfolio.ErrMemberNotFound, userID)
+}
+
+func (r *SQLiteAccessRepository) CreateShareToken(ctx context.Context, t *portfolio.ShareToken) error {
+	query := `INSERT INTO share_tokens (` + shareTokenColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		t.ID, t.PortfolioID, t.Name, t.Prefix, t.Hash, t.CreatedBy,
+		t.ExpiresAt.UTC().Format(time.RFC3339), optionalTime(t.RevokedAt), optionalTime(t.LastUsedAt),
+		t.CreatedAt.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create share token: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SQLiteAccessRepository) ListShareTokens(ctx context.Context, portfolioID string) ([]*portfolio.ShareToken, error) {
+	query := `SELECT ` + shareTokenColumns + ` FROM share_tokens WHERE portfolio_id = ? ORDER BY created_at DESC, id ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list share tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := make([]*portfolio.ShareToken, 0)
+	for rows.Next() {
+		t, err := scanShareToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan share token: %w", err)
+		}
+		tokens = append(tokens, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating share tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+func (r *SQLiteAccessRepository) RevokeShareToken(ctx context.Context, portfolioID, tokenID string, at time.Time) error {
+	query := `UPDATE share_tokens SET revoked_at = COALESCE(revoked_at, ?) WHERE id = ? AND portfolio_id = ?`
+
+	result, err := r.db.ExecContext(ctx, query, at.UTC().Format(time.RFC3339), tokenID, portfolioID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke share token: %w", err)
+	}
+
+	return requireAffected(result, portfolio.ErrShareTokenNotFound, tokenID)
+}
+
+func (r *SQLiteAccessRepository) GetShareTokenByHash(ctx context.Context, hash string) (*portfolio.ShareToken, error) {
+	query := `
+		SELECT ` + shareTokenColumns + `, (SELECT owner_id FROM portfolios WHERE id = share_tokens.portfolio_id)
+		FROM share_tokens
+		WHERE token_hash = ?
+	`
+
+	var ownerID sql.NullString
+	t, err := scanShareToken(r.db.QueryRowContext(ctx, query, hash), &ownerID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, portfolio.ErrShareTokenNotFound
+		}
+		return nil, fmt.Errorf("failed to get share token: %w", err)
+	}
+	t.OwnerID = ownerID.String
+
+	return t, nil
+}
+
+func (r *SQLiteAccessRepository) TouchShareToken(ctx context.Context, tokenID string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE share_tokens SET last_used_at = ? WHERE id = ?`, at.UTC().Format(time.RFC3339), tokenID)
+	if err != nil {
+		return fmt.Errorf("failed to update share token usage: %w", err)
+	}
+	return nil
+}
+
+// scanShareToken scans the shareTokenColumns, followed by any extra selected columns
+func scanShareToken(row rowScanner, extra ...interface{}) (*portfolio.ShareToken, error) {
+	var (
+		t                    portfolio.ShareToken
+		expiresAt, createdAt string
+		revokedAt, lastUsed  sql.NullString
+	)
+	dest := []interface{}{&t.ID, &t.PortfolioID, &t.Name, &t.Prefix, &t.Hash, &t.CreatedBy,
+		&expiresAt, &revokedAt, &lastUsed, &createdAt}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if t.ExpiresAt, err = time.Parse(time.RFC3339, expiresAt); err != nil {
+		return nil, fmt.Errorf("failed to parse expires_at: %w", err)
+	}
+	if t.CreatedAt, err = time.Parse(time.RFC3339, createdAt); err != nil {
+		return nil, fmt.Errorf("failed to parse created_at: %w", err)
+	}
+	if t.RevokedAt, err = parseOptionalTime(revokedAt); err != nil {
+		return nil, fmt.Errorf("failed to parse revoked_at: %w", err)
+	}
+	if t.LastUsedAt, err = parseOptionalTime(lastUsed); err != nil {
+		return nil, fmt.Errorf("failed to parse last_used_at: %w", err)
+	}
+
+	return &t, nil
+}
+
+func requireAffected(result sql.Result, notFound error, id string) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: id=%s", notFound, id)
+	}
+	return nil
+}
+
+func optionalTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func parseOptionalTime(s sql.NullString) (*time.Time, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, s.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
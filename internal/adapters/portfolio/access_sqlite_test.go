@@ -0,0 +1,173 @@
+package portfolio
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"testtask/internal/domain/portfolio"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// setupAccessTestDB creates an in-memory SQLite database with the sharing schema
+func setupAccessTestDB(t *testing.T) (*SQLiteAccessRepository, func()) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+
+	schema := `
+	CREATE TABLE IF NOT EXISTS portfolios (
+		id TEXT PRIMARY KEY,
+		owner_id TEXT
+	);
+
+	CREATE TABLE IF NOT EXISTS users (
+		id TEXT PRIMARY KEY,
+		email TEXT NOT NULL UNIQUE
+	);
+
+	CREATE TABLE IF NOT EXISTS portfolio_members (
+		portfolio_id TEXT NOT NULL,
+		user_id TEXT NOT NULL,
+		role TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		PRIMARY KEY (portfolio_id, user_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS share_tokens (
+		id TEXT PRIMARY KEY,
+		portfolio_id TEXT NOT NULL,
+		name TEXT NOT NULL DEFAULT '',
+		prefix TEXT NOT NULL,
+		token_hash TEXT NOT NULL UNIQUE,
+		created_by TEXT NOT NULL,
+		expires_at DATETIME NOT NULL,
+		revoked_at DATETIME,
+		last_used_at DATETIME,
+		created_at DATETIME NOT NULL
+	);
+
+	INSERT INTO portfolios (id, owner_id) VALUES ('shared-1', 'alice');
+	INSERT INTO users (id, email) VALUES ('alice', 'alice@example.com'), ('bob', 'bob@example.com');
+	`
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	return NewSQLiteAccessRepository(db), func() { db.Close() }
+}
+
+func TestSQLiteAccessRepository_Members(t *testing.T) {
+	repo, cleanup := setupAccessTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now()
+
+	member := &portfolio.Member{PortfolioID: "shared-1", UserID: "bob", Role: portfolio.RoleViewer, CreatedAt: now, UpdatedAt: now}
+	if err := repo.AddMember(ctx, member); err != nil {
+		t.Fatalf("AddMember() error = %v", err)
+	}
+	if err := repo.AddMember(ctx, member); !errors.Is(err, portfolio.ErrMemberExists) {
+		t.Errorf("AddMember() twice error = %v, want %v", err, portfolio.ErrMemberExists)
+	}
+
+	member.Role = portfolio.RoleEditor
+	if err := repo.UpdateMember(ctx, member); err != nil {
+		t.Fatalf("UpdateMember() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		portfolioID string
+		userID      string
+		want        portfolio.Role
+		wantErr     error
+	}{
+		{name: "owner", portfolioID: "shared-1", userID: "alice", want: portfolio.RoleOwner},
+		{name: "member", portfolioID: "shared-1", userID: "bob", want: portfolio.RoleEditor},
+		{name: "stranger", portfolioID: "shared-1", userID: "carol", wantErr: portfolio.ErrPortfolioNotFound},
+		{name: "missing portfolio", portfolioID: "missing", userID: "alice", wantErr: portfolio.ErrPortfolioNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.Role(ctx, tt.portfolioID, tt.userID)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Role() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Role() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Role() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	members, err := repo.ListMembers(ctx, "shared-1")
+	if err != nil {
+		t.Fatalf("ListMembers() error = %v", err)
+	}
+	if len(members) != 1 || members[0].Email != "bob@example.com" || members[0].Role != portfolio.RoleEditor {
+		t.Errorf("ListMembers() = %+v, want bob as editor", members)
+	}
+
+	if err := repo.RemoveMember(ctx, "shared-1", "bob"); err != nil {
+		t.Fatalf("RemoveMember() error = %v", err)
+	}
+	if err := repo.RemoveMember(ctx, "shared-1", "bob"); !errors.Is(err, portfolio.ErrMemberNotFound) {
+		t.Errorf("RemoveMember() twice error = %v, want %v", err, portfolio.ErrMemberNotFound)
+	}
+}
+
+func TestSQLiteAccessRepository_ShareTokens(t *testing.T) {
+	repo, cleanup := setupAccessTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	token := portfolio.NewShareToken("shared-1", "accountant", "alice", time.Now().Add(time.Hour))
+	token.Prefix = "cps_abcd"
+	token.Hash = "hash-1"
+	if err := repo.CreateShareToken(ctx, token); err != nil {
+		t.Fatalf("CreateShareToken() error = %v", err)
+	}
+
+	got, err := repo.GetShareTokenByHash(ctx, "hash-1")
+	if err != nil {
+		t.Fatalf("GetShareTokenByHash() error = %v", err)
+	}
+	if got.ID != token.ID || got.PortfolioID != "shared-1" || got.OwnerID != "alice" || !got.Active(time.Now()) {
+		t.Errorf("GetShareTokenByHash() = %+v, want active token %s of alice's portfolio", got, token.ID)
+	}
+
+	if err := repo.RevokeShareToken(ctx, "other", token.ID, time.Now()); !errors.Is(err, portfolio.ErrShareTokenNotFound) {
+		t.Errorf("RevokeShareToken() other portfolio error = %v, want %v", err, portfolio.ErrShareTokenNotFound)
+	}
+	if err := repo.RevokeShareToken(ctx, "shared-1", token.ID, time.Now()); err != nil {
+		t.Fatalf("RevokeShareToken() error = %v", err)
+	}
+
+	tokens, err := repo.ListShareTokens(ctx, "shared-1")
+	if err != nil {
+		t.Fatalf("ListShareTokens() error = %v", err)
+	}
+	if len(tokens) != 1 || tokens[0].RevokedAt == nil || tokens[0].Active(time.Now()) {
+		t.Errorf("ListShareTokens() = %+v, want one revoked token", tokens)
+	}
+
+	if _, err := repo.GetShareTokenByHash(ctx, "missing"); !errors.Is(err, portfolio.ErrShareTokenNotFound) {
+		t.Errorf("GetShareTokenByHash() missing error = %v, want %v", err, portfolio.ErrShareTokenNotFound)
+	}
+}
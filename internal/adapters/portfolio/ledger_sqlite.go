@@ -0,0 +1,170 @@
+package portfolio
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/big"
+	"time"
+
+	sqliteadapter "testtask/internal/adapters/sqlite"
+	"testtask/internal/domain/holding"
+	"testtask/internal/domain/portfolio"
+	"testtask/internal/domain/token"
+)
+
+const ledgerColumns = `id, portfolio_id, holding_id, chain_id, token_id, token_symbol, token_address, entry_type, quantity, price, currency, note, counterparty, occurred_at, created_at`
+
+// AppendEntry stores a ledger entry and writes the holding with its new projected amount
+// in one transaction. A holding with version 0 is created on its first entry; otherwise
+// the write fails with holding.ErrVersionConflict unless h.Version is the stored version.
+func (r *SQLiteRepository) AppendEntry(ctx context.Context, portfolioID string, h *holding.Holding, e *holding.LedgerEntry) error {
+	if h.Token == nil {
+		return fmt.Errorf("token is required")
+	}
+
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		scope, scopeArgs := sqliteadapter.PortfolioScope(ctx, "id")
+		var id string
+		err := tx.QueryRowContext(ctx, `SELECT id FROM portfolios WHERE id = ?`+scope, append([]interface{}{portfolioID}, scopeArgs...)...).Scan(&id)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: portfolio_id=%s", portfolio.ErrPortfolioNotFound, portfolioID)
+		} else if err != nil {
+			return fmt.Errorf("failed to get portfolio for ledger entry: %w", err)
+		}
+
+		if err := writeHolding(ctx, tx, portfolioID, h); err != nil {
+			return err
+		}
+
+		var price interface{}
+		if e.Price != nil {
+			price = e.Price.String()
+		}
+		_, err = tx.ExecContext(ctx, `INSERT INTO holding_ledger (`+ledgerColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			e.ID, portfolioID, h.ID, h.ChainID, h.Token.ID, h.Token.Symbol, h.Token.Address,
+			string(e.Type), e.Quantity.String(), price, e.Currency, e.Note, e.Counterparty,
+			e.OccurredAt.UTC().Format(time.RFC3339), e.CreatedAt.UTC().Format(time.RFC3339),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to append ledger entry: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// writeHolding inserts a new holding or updates the amount of a stored one if its version
+// still matches, and advances h.Version
+func writeHolding(ctx context.Context, tx *sql.Tx, portfolioID string, h *holding.Holding) error {
+	updatedAt := h.UpdatedAt
+	if updatedAt.IsZero() {
+		updatedAt = time.Now()
+	}
+
+	if h.Version == 0 {
+		createdAt := h.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = time.Now()
+		}
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO holdings (id, portfolio_id, chain_id, token_id, token_symbol, token_address, amount, version, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, 1, ?, ?)
+		`, h.ID, portfolioID, h.ChainID, h.Token.ID, h.Token.Symbol, h.Token.Address, h.Amount.String(),
+			createdAt.Format(time.RFC3339), updatedAt.Format(time.RFC3339))
+		if sqliteadapter.IsUniqueViolation(err) {
+			return fmt.Errorf("%w: portfolio_id=%s already holds token %s", holding.ErrVersionConflict, portfolioID, h.Token.Address)
+		} else if err != nil {
+			return fmt.Errorf("failed to create holding: %w", err)
+		}
+		h.Version = 1
+		return nil
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE holdings SET amount = ?, version = version + 1, updated_at = ?
+		WHERE id = ? AND portfolio_id = ? AND version = ?
+	`, h.Amount.String(), updatedAt.Format(time.RFC3339), h.ID, portfolioID, h.Version)
+	if err != nil {
+		return fmt.Errorf("failed to update holding: %w", err)
+	}
+	if err := checkVersionedWrite(ctx, tx, result, portfolioID, h); err != nil {
+		return err
+	}
+	h.Version++
+	return nil
+}
+
+// ListEntries returns the ledger of a portfolio in the order the entries occurred
+func (r *SQLiteRepository) ListEntries(ctx context.Context, portfolioID string, filter holding.LedgerFilter) ([]*holding.LedgerEntry, error) {
+	query := `
+		SELECT ` + ledgerColumns + `
+		FROM holding_ledger
+		WHERE portfolio_id = ?
+	`
+	args := []interface{}{portfolioID}
+	if filter.HoldingID != "" {
+		query += " AND holding_id = ?"
+		args = append(args, filter.HoldingID)
+	}
+	if filter.TokenAddress != "" {
+		query += " AND token_address = ?"
+		args = append(args, filter.TokenAddress)
+	}
+	if filter.From != nil {
+		query += " AND julianday(occurred_at) >= julianday(?)"
+		args = append(args, filter.From.UTC().Format(time.RFC3339))
+	}
+	if filter.To != nil {
+		query += " AND julianday(occurred_at) <= julianday(?)"
+		args = append(args, filter.To.UTC().Format(time.RFC3339))
+	}
+	scope, scopeArgs := sqliteadapter.PortfolioScope(ctx, "portfolio_id")
+	query += scope + " ORDER BY julianday(occurred_at) ASC, julianday(created_at) ASC, id ASC"
+	args = append(args, scopeArgs...)
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ledger entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*holding.LedgerEntry
+	for rows.Next() {
+		var (
+			e                                      holding.LedgerEntry
+			t                                      token.Token
+			chainID                                uint8
+			entryType, quantity, occurred, created string
+			price                                  sql.NullString
+		)
+		if err := rows.Scan(&e.ID, &e.PortfolioID, &e.HoldingID, &chainID, &t.ID, &t.Symbol, &t.Address,
+			&entryType, &quantity, &price, &e.Currency, &e.Note, &e.Counterparty, &occurred, &created); err != nil {
+			return nil, fmt.Errorf("failed to scan ledger entry: %w", err)
+		}
+
+		e.Token = &t
+		e.Type = holding.EntryType(entryType)
+		var ok bool
+		if e.Quantity, ok = new(big.Int).SetString(quantity, 10); !ok {
+			return nil, fmt.Errorf("failed to parse quantity: %s", quantity)
+		}
+		if price.Valid {
+			if e.Price, ok = new(big.Int).SetString(price.String, 10); !ok {
+				return nil, fmt.Errorf("failed to parse price: %s", price.String)
+			}
+		}
+		if e.OccurredAt, err = parseTime(occurred); err != nil {
+			return nil, fmt.Errorf("failed to parse occurred_at: %w", err)
+		}
+		if e.CreatedAt, err = parseTime(created); err != nil {
+			return nil, fmt.Errorf("failed to parse created_at: %w", err)
+		}
+		entries = append(entries, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating ledger entries: %w", err)
+	}
+
+	return entries, nil
+}
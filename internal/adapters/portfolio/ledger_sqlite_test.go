@@ -0,0 +1,96 @@
+package portfolio
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"testtask/internal/domain/holding"
+	"testtask/internal/domain/portfolio"
+	"testtask/internal/domain/token"
+	"testtask/internal/domain/user"
+)
+
+func TestSQLiteRepository_Ledger(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := user.AsSystem(context.Background())
+	p := portfolio.NewPortfolio("ledger-1", "0xledger1111")
+	p.OwnerID = "alice"
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	eth := &token.Token{ID: "ethereum", Symbol: "ETH", Address: "0xeth"}
+	h := holding.NewHolding(p.ID, "ledger-holding-1", eth, big.NewInt(0))
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 12, 0, 0, 0, time.UTC) }
+
+	steps := []struct {
+		entryType holding.EntryType
+		quantity  int64
+		at        time.Time
+	}{
+		{holding.EntryDeposit, 10, day(1)},
+		{holding.EntryWithdrawal, -3, day(5)},
+		{holding.EntryAdjustment, 1, day(3)},
+	}
+	for _, step := range steps {
+		e := holding.NewLedgerEntry(h, step.entryType, big.NewInt(step.quantity), step.at)
+		h.Amount.Add(h.Amount, e.Quantity)
+		if step.entryType == holding.EntryDeposit {
+			e.Price = big.NewInt(2500_00000000)
+			e.Currency = "usd"
+			e.Note = "initial purchase"
+		}
+		if err := repo.AppendEntry(ctx, p.ID, h, e); err != nil {
+			t.Fatalf("AppendEntry() error = %v", err)
+		}
+	}
+
+	stored, err := repo.GetHolding(ctx, p.ID, h.ID)
+	if err != nil {
+		t.Fatalf("GetHolding() error = %v", err)
+	}
+	if stored.Amount.Int64() != 8 {
+		t.Errorf("GetHolding() amount = %s, want projection 8", stored.Amount)
+	}
+
+	entries, err := repo.ListEntries(ctx, p.ID, holding.LedgerFilter{})
+	if err != nil {
+		t.Fatalf("ListEntries() error = %v", err)
+	}
+	wantOrder := []holding.EntryType{holding.EntryDeposit, holding.EntryAdjustment, holding.EntryWithdrawal}
+	if len(entries) != len(wantOrder) {
+		t.Fatalf("ListEntries() returned %d entries, want %d", len(entries), len(wantOrder))
+	}
+	for i, e := range entries {
+		if e.Type != wantOrder[i] {
+			t.Errorf("ListEntries()[%d].Type = %s, want %s", i, e.Type, wantOrder[i])
+		}
+	}
+	if first := entries[0]; first.Price == nil || first.Price.Int64() != 2500_00000000 || first.Note != "initial purchase" || first.Token.Address != "0xeth" {
+		t.Errorf("ListEntries()[0] = %+v", first)
+	}
+
+	to := day(4)
+	until, err := repo.ListEntries(ctx, p.ID, holding.LedgerFilter{To: &to})
+	if err != nil {
+		t.Fatalf("ListEntries() error = %v", err)
+	}
+	if len(until) != 2 {
+		t.Errorf("ListEntries() up to %s returned %d entries, want 2", to, len(until))
+	}
+
+	// Another user can neither read nor append to the ledger
+	mallory := user.WithUser(ctx, &user.User{ID: "mallory"})
+	if entries, err := repo.ListEntries(mallory, p.ID, holding.LedgerFilter{}); err != nil || len(entries) != 0 {
+		t.Errorf("ListEntries() as other user = %d entries, %v; want none", len(entries), err)
+	}
+	e := holding.NewLedgerEntry(h, holding.EntryDeposit, big.NewInt(1), day(6))
+	if err := repo.AppendEntry(mallory, p.ID, h, e); !errors.Is(err, portfolio.ErrPortfolioNotFound) {
+		t.Errorf("AppendEntry() as other user error = %v, want ErrPortfolioNotFound", err)
+	}
+}
@@ -0,0 +1,152 @@
+package portfolio
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/big"
+	"time"
+
+	sqliteadapter "testtask/internal/adapters/sqlite"
+	"testtask/internal/domain/portfolio"
+)
+
+// SQLiteManualRepository implements portfolio.ManualRepository on top of the manual_entries table
+type SQLiteManualRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteManualRepository(db *sql.DB) *SQLiteManualRepository {
+	return &SQLiteManualRepository{db: db}
+}
+
+const manualEntryColumns = `id, portfolio_id, name, side, category, valuation, currency, value, quantity, unit_price, token_address, note, created_at, updated_at`
+
+func (r *SQLiteManualRepository) CreateManualEntry(ctx context.Context, e *portfolio.ManualEntry) error {
+	query := `INSERT INTO manual_entries (` + manualEntryColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, query,
+		e.ID, e.PortfolioID, e.Name, string(e.Side), string(e.Category), string(e.Valuation), e.Currency,
+		decimalArg(e.Value), decimalArg(e.Quantity), decimalArg(e.UnitPrice), e.TokenAddress, e.Note,
+		e.CreatedAt.UTC().Format(time.RFC3339), e.UpdatedAt.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create manual entry: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLiteManualRepository) UpdateManualEntry(ctx context.Context, e *portfolio.ManualEntry) error {
+	query := `
+		UPDATE manual_entries
+		SET name = ?, side = ?, category = ?, valuation = ?, currency = ?, value = ?, quantity = ?,
+			unit_price = ?, token_address = ?, note = ?, updated_at = ?
+		WHERE id = ? AND portfolio_id = ?
+	`
+	result, err := r.db.ExecContext(ctx, query,
+		e.Name, string(e.Side), string(e.Category), string(e.Valuation), e.Currency, decimalArg(e.Value),
+		decimalArg(e.Quantity), decimalArg(e.UnitPrice), e.TokenAddress, e.Note, e.UpdatedAt.UTC().Format(time.RFC3339),
+		e.ID, e.PortfolioID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update manual entry: %w", err)
+	}
+	return requireAffected(result, portfolio.ErrManualEntryNotFound, e.ID)
+}
+
+func (r *SQLiteManualRepository) DeleteManualEntry(ctx context.Context, portfolioID, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM manual_entries WHERE id = ? AND portfolio_id = ?`, id, portfolioID)
+	if err != nil {
+		return fmt.Errorf("failed to delete manual entry: %w", err)
+	}
+	return requireAffected(result, portfolio.ErrManualEntryNotFound, id)
+}
+
+func (r *SQLiteManualRepository) GetManualEntry(ctx context.Context, portfolioID, id string) (*portfolio.ManualEntry, error) {
+	scope, scopeArgs := sqliteadapter.PortfolioScope(ctx, "portfolio_id")
+	query := `SELECT ` + manualEntryColumns + ` FROM manual_entries WHERE id = ? AND portfolio_id = ?` + scope
+	args := append([]interface{}{id, portfolioID}, scopeArgs...)
+
+	e, err := scanManualEntry(r.db.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: id=%s", portfolio.ErrManualEntryNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get manual entry: %w", err)
+	}
+	return e, nil
+}
+
+func (r *SQLiteManualRepository) ListManualEntries(ctx context.Context, portfolioID string) ([]*portfolio.ManualEntry, error) {
+	scope, scopeArgs := sqliteadapter.PortfolioScope(ctx, "portfolio_id")
+	query := `SELECT ` + manualEntryColumns + ` FROM manual_entries WHERE portfolio_id = ?` + scope + ` ORDER BY created_at ASC, id ASC`
+	args := append([]interface{}{portfolioID}, scopeArgs...)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list manual entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]*portfolio.ManualEntry, 0)
+	for rows.Next() {
+		e, err := scanManualEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan manual entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list manual entries: %w", err)
+	}
+	return entries, nil
+}
+
+func scanManualEntry(row rowScanner) (*portfolio.ManualEntry, error) {
+	var (
+		e                          portfolio.ManualEntry
+		side, category, valuation  string
+		value, quantity, unitPrice sql.NullString
+		createdAt, updatedAt       string
+	)
+	if err := row.Scan(&e.ID, &e.PortfolioID, &e.Name, &side, &category, &valuation, &e.Currency,
+		&value, &quantity, &unitPrice, &e.TokenAddress, &e.Note, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	e.Side, e.Category, e.Valuation = portfolio.ManualSide(side), portfolio.ManualCategory(category), portfolio.Valuation(valuation)
+
+	var err error
+	if e.Value, err = parseDecimalColumn(value); err != nil {
+		return nil, err
+	}
+	if e.Quantity, err = parseDecimalColumn(quantity); err != nil {
+		return nil, err
+	}
+	if e.UnitPrice, err = parseDecimalColumn(unitPrice); err != nil {
+		return nil, err
+	}
+	if e.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, fmt.Errorf("failed to parse created_at: %w", err)
+	}
+	if e.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, fmt.Errorf("failed to parse updated_at: %w", err)
+	}
+	return &e, nil
+}
+
+func decimalArg(v *big.Int) interface{} {
+	if v == nil {
+		return nil
+	}
+	return v.String()
+}
+
+func parseDecimalColumn(s sql.NullString) (*big.Int, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	v, ok := new(big.Int).SetString(s.String, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer %q", s.String)
+	}
+	return v, nil
+}
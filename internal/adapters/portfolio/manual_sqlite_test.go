@@ -0,0 +1,107 @@
+package portfolio
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/big"
+	"testing"
+
+	"testtask/internal/domain/portfolio"
+	"testtask/internal/domain/user"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// setupManualTestDB creates an in-memory SQLite database with the manual entries schema
+func setupManualTestDB(t *testing.T) *SQLiteManualRepository {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	schema := `
+	CREATE TABLE IF NOT EXISTS manual_entries (
+		id TEXT PRIMARY KEY,
+		portfolio_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		side TEXT NOT NULL,
+		category TEXT NOT NULL,
+		valuation TEXT NOT NULL,
+		currency TEXT NOT NULL DEFAULT '',
+		value TEXT,
+		quantity TEXT,
+		unit_price TEXT,
+		token_address TEXT NOT NULL DEFAULT '',
+		note TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+	`
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+	return NewSQLiteManualRepository(db)
+}
+
+func TestSQLiteManualRepository(t *testing.T) {
+	repo := setupManualTestDB(t)
+	ctx := user.AsSystem(context.Background())
+
+	cash := portfolio.NewManualEntry("portfolio-1", portfolio.ManualEntry{
+		Name: "Bank account", Side: portfolio.ManualAsset, Category: portfolio.CategoryCash,
+		Valuation: portfolio.ValuationFixed, Currency: "EUR", Value: big.NewInt(250000000000),
+	})
+	loan := portfolio.NewManualEntry("portfolio-1", portfolio.ManualEntry{
+		Name: "Mortgage", Side: portfolio.ManualLiability, Category: portfolio.CategoryLoan,
+		Valuation: portfolio.ValuationFixed, Currency: "usd", Value: big.NewInt(100000000000),
+	})
+	for _, e := range []*portfolio.ManualEntry{cash, loan} {
+		if err := repo.CreateManualEntry(ctx, e); err != nil {
+			t.Fatalf("CreateManualEntry() error = %v", err)
+		}
+	}
+
+	got, err := repo.GetManualEntry(ctx, "portfolio-1", cash.ID)
+	if err != nil {
+		t.Fatalf("GetManualEntry() error = %v", err)
+	}
+	if got.Currency != "eur" || got.Value.Cmp(cash.Value) != 0 || got.Quantity != nil || got.Category != portfolio.CategoryCash {
+		t.Errorf("GetManualEntry() = %+v, want the stored cash entry", got)
+	}
+	if _, err := repo.GetManualEntry(ctx, "portfolio-2", cash.ID); !errors.Is(err, portfolio.ErrManualEntryNotFound) {
+		t.Errorf("GetManualEntry() from another portfolio error = %v, want ErrManualEntryNotFound", err)
+	}
+
+	quantity, _ := new(big.Int).SetString("1500000000000000000", 10)
+	loan.Valuation, loan.Value, loan.Quantity, loan.TokenAddress = portfolio.ValuationMarket, nil, quantity, "0xweth"
+	if err := repo.UpdateManualEntry(ctx, loan); err != nil {
+		t.Fatalf("UpdateManualEntry() error = %v", err)
+	}
+
+	entries, err := repo.ListManualEntries(ctx, "portfolio-1")
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("ListManualEntries() = %d, %v, want 2", len(entries), err)
+	}
+	updated := entries[0]
+	if updated.ID != loan.ID {
+		updated = entries[1]
+	}
+	if updated.Valuation != portfolio.ValuationMarket || updated.Value != nil || updated.Quantity.Cmp(quantity) != 0 || updated.TokenAddress != "0xweth" {
+		t.Errorf("updated entry = %+v, want market valuation of 1.5 0xweth", updated)
+	}
+
+	if err := repo.DeleteManualEntry(ctx, "portfolio-1", cash.ID); err != nil {
+		t.Fatalf("DeleteManualEntry() error = %v", err)
+	}
+	if err := repo.DeleteManualEntry(ctx, "portfolio-1", cash.ID); !errors.Is(err, portfolio.ErrManualEntryNotFound) {
+		t.Errorf("DeleteManualEntry() twice error = %v, want ErrManualEntryNotFound", err)
+	}
+	missing := *loan
+	missing.ID = "missing"
+	if err := repo.UpdateManualEntry(ctx, &missing); !errors.Is(err, portfolio.ErrManualEntryNotFound) {
+		t.Errorf("UpdateManualEntry() missing error = %v, want ErrManualEntryNotFound", err)
+	}
+}
@@ -3,8 +3,11 @@ package portfolio
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"math/big"
+	"strings"
+	sqliteadapter "testtask/internal/adapters/sqlite"
 	"testtask/internal/domain/holding"
 	"testtask/internal/domain/portfolio"
 	"testtask/internal/domain/token"
@@ -18,9 +21,9 @@ type SQLiteRepository struct {
 }
 
 func NewSQLiteRepository(dbPath string) (*SQLiteRepository, error) {
-	db, err := sql.Open("sqlite3", dbPath)
+	db, err := sqliteadapter.Open(dbPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
+		return nil, err
 	}
 
 	repo := &SQLiteRepository{db: db}
@@ -28,21 +31,18 @@ func NewSQLiteRepository(dbPath string) (*SQLiteRepository, error) {
 	return repo, nil
 }
 
+// portfolioColumns are the portfolio fields read by scanPortfolio
+const portfolioColumns = `id, owner_id, address, name, description, tags, archived, created_at, updated_at`
+
 func (r *SQLiteRepository) GetByID(ctx context.Context, portfolioID string) (*portfolio.Portfolio, error) {
 	query := `
-		SELECT id, address, updated_at
+		SELECT ` + portfolioColumns + `
 		FROM portfolios
 		WHERE id = ?
 	`
+	scope, scopeArgs := sqliteadapter.PortfolioScope(ctx, "id")
 
-	var p portfolio.Portfolio
-	var updatedAtStr string
-
-	err := r.db.QueryRowContext(ctx, query, portfolioID).Scan(
-		&p.ID,
-		&p.Address,
-		&updatedAtStr,
-	)
+	p, err := scanPortfolio(r.conn(ctx).QueryRowContext(ctx, query+scope, append([]interface{}{portfolioID}, scopeArgs...)...))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("%w: portfolio_id=%s", portfolio.ErrPortfolioNotFound, portfolioID)
@@ -50,19 +50,7 @@ func (r *SQLiteRepository) GetByID(ctx context.Context, portfolioID string) (*po
 		return nil, fmt.Errorf("failed to get portfolio by ID: %w", err)
 	}
 
-	// Parse updated_at
-	updatedAt, err := time.Parse(time.RFC3339, updatedAtStr)
-	if err != nil {
-		// Try parsing as datetime format if RFC3339 fails
-		updatedAt, err = time.Parse("2006-01-02 15:04:05", updatedAtStr)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse updated_at: %w", err)
-		}
-	}
-	p.UpdatedAt = updatedAt
-	p.Holdings = make([]*holding.Holding, 0)
-
-	return &p, nil
+	return p, nil
 }
 
 func (r *SQLiteRepository) GetByIDWithHoldings(ctx context.Context, portfolioID string) (*portfolio.Portfolio, error) {
@@ -84,21 +72,19 @@ func (r *SQLiteRepository) GetByIDWithHoldings(ctx context.Context, portfolioID
 	return p, nil
 }
 
+// GetByAddress returns the caller's own portfolio tracking the address. Addresses are
+// unique per owner, so portfolios shared with the caller are not considered.
 func (r *SQLiteRepository) GetByAddress(ctx context.Context, address string) (*portfolio.Portfolio, error) {
 	query := `
-		SELECT id, address, updated_at
+		SELECT ` + portfolioColumns + `
 		FROM portfolios
 		WHERE address = ?
 	`
+	scope, scopeArgs := sqliteadapter.OwnerScope(ctx, "owner_id")
+	query += scope
+	args := append([]interface{}{address}, scopeArgs...)
 
-	var p portfolio.Portfolio
-	var updatedAtStr string
-
-	err := r.db.QueryRowContext(ctx, query, address).Scan(
-		&p.ID,
-		&p.Address,
-		&updatedAtStr,
-	)
+	p, err := scanPortfolio(r.conn(ctx).QueryRowContext(ctx, query, args...))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("%w: address=%s", portfolio.ErrPortfolioNotFound, address)
@@ -106,26 +92,14 @@ func (r *SQLiteRepository) GetByAddress(ctx context.Context, address string) (*p
 		return nil, fmt.Errorf("failed to get portfolio by address: %w", err)
 	}
 
-	// Parse updated_at
-	updatedAt, err := time.Parse(time.RFC3339, updatedAtStr)
-	if err != nil {
-		// Try parsing as datetime format if RFC3339 fails
-		updatedAt, err = time.Parse("2006-01-02 15:04:05", updatedAtStr)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse updated_at: %w", err)
-		}
-	}
-	p.UpdatedAt = updatedAt
-	p.Holdings = make([]*holding.Holding, 0)
-
-	return &p, nil
+	return p, nil
 }
 
 func (r *SQLiteRepository) Create(ctx context.Context, p *portfolio.Portfolio) error {
-	// Check if address is already used by a different portfolio
-	checkQuery := `SELECT id FROM portfolios WHERE address = ? AND id != ?`
+	// Check if address is already used by a different portfolio of the same owner
+	checkQuery := `SELECT id FROM portfolios WHERE address = ? AND id != ? AND owner_id IS ?`
 	var existingID string
-	err := r.db.QueryRowContext(ctx, checkQuery, p.Address, p.ID).Scan(&existingID)
+	err := r.conn(ctx).QueryRowContext(ctx, checkQuery, p.Address, p.ID, nullString(p.OwnerID)).Scan(&existingID)
 	if err == nil {
 		// Address exists and belongs to a different portfolio
 		return fmt.Errorf("%w: address=%s", portfolio.ErrPortfolioAddressExists, p.Address)
@@ -133,36 +107,202 @@ func (r *SQLiteRepository) Create(ctx context.Context, p *portfolio.Portfolio) e
 		return fmt.Errorf("failed to check existing address: %w", err)
 	}
 
-	// Insert or update portfolio
+	tags, err := encodeTags(p.Tags)
+	if err != nil {
+		return err
+	}
+
+	// Insert or update portfolio; a portfolio of another owner is never overwritten
 	insertQuery := `
-		INSERT INTO portfolios (id, address, updated_at)
-		VALUES (?, ?, ?)
+		INSERT INTO portfolios (id, owner_id, address, name, description, tags, archived, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			address = excluded.address,
+			name = excluded.name,
+			description = excluded.description,
+			tags = excluded.tags,
+			archived = excluded.archived,
 			updated_at = excluded.updated_at
+		WHERE portfolios.owner_id IS excluded.owner_id
 	`
 
 	updatedAtStr := p.UpdatedAt.Format(time.RFC3339)
 	if p.UpdatedAt.IsZero() {
 		updatedAtStr = time.Now().Format(time.RFC3339)
 	}
+	createdAtStr := p.CreatedAt.Format(time.RFC3339)
+	if p.CreatedAt.IsZero() {
+		createdAtStr = updatedAtStr
+	}
 
-	_, err = r.db.ExecContext(ctx, insertQuery, p.ID, p.Address, updatedAtStr)
+	result, err := r.conn(ctx).ExecContext(ctx, insertQuery, p.ID, nullString(p.OwnerID), p.Address, p.Name, p.Description, tags, p.Archived, createdAtStr, updatedAtStr)
 	if err != nil {
 		return fmt.Errorf("failed to create portfolio: %w", err)
 	}
 
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: portfolio_id=%s", portfolio.ErrPortfolioExists, p.ID)
+	}
+
 	return nil
 }
 
+// Update saves the metadata and archived flag of an existing portfolio
+func (r *SQLiteRepository) Update(ctx context.Context, p *portfolio.Portfolio) error {
+	tags, err := encodeTags(p.Tags)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE portfolios
+		SET name = ?, description = ?, tags = ?, archived = ?, updated_at = ?
+		WHERE id = ?
+	`
+	scope, scopeArgs := sqliteadapter.PortfolioScope(ctx, "id")
+
+	updatedAtStr := p.UpdatedAt.Format(time.RFC3339)
+	if p.UpdatedAt.IsZero() {
+		updatedAtStr = time.Now().Format(time.RFC3339)
+	}
+
+	args := append([]interface{}{p.Name, p.Description, tags, p.Archived, updatedAtStr, p.ID}, scopeArgs...)
+	result, err := r.conn(ctx).ExecContext(ctx, query+scope, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update portfolio: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: portfolio_id=%s", portfolio.ErrPortfolioNotFound, p.ID)
+	}
+
+	return nil
+}
+
+// portfolioScopedTables hold rows owned by a portfolio through their portfolio_id column.
+// They are cleaned up with the portfolio; foreign keys are not enforced by the connection.
+var portfolioScopedTables = []string{"holdings", "holding_ledger", "custom_tokens", "price_overrides", "portfolio_members", "share_tokens", "exchange_records", "exchange_connections", "manual_entries", "income_classifications", "income_accruals", "transaction_annotations", "manual_transactions"}
+
+// Delete removes a portfolio and all portfolio-scoped rows in one transaction.
+// The price override audit trail is kept.
+func (r *SQLiteRepository) Delete(ctx context.Context, portfolioID string) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		// Resolve access first so nothing of an inaccessible portfolio is touched
+		scope, scopeArgs := sqliteadapter.PortfolioScope(ctx, "id")
+		var id string
+		err := tx.QueryRowContext(ctx, `SELECT id FROM portfolios WHERE id = ?`+scope, append([]interface{}{portfolioID}, scopeArgs...)...).Scan(&id)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: portfolio_id=%s", portfolio.ErrPortfolioNotFound, portfolioID)
+		} else if err != nil {
+			return fmt.Errorf("failed to get portfolio for deletion: %w", err)
+		}
+
+		for _, table := range portfolioScopedTables {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE portfolio_id = ?`, portfolioID); err != nil {
+				return fmt.Errorf("failed to delete %s of portfolio: %w", table, err)
+			}
+		}
+
+		result, err := tx.ExecContext(ctx, `DELETE FROM portfolios WHERE id = ?`, portfolioID)
+		if err != nil {
+			return fmt.Errorf("failed to delete portfolio: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return fmt.Errorf("%w: portfolio_id=%s", portfolio.ErrPortfolioNotFound, portfolioID)
+		}
+
+		return nil
+	})
+}
+
 func (r *SQLiteRepository) List(ctx context.Context) ([]*portfolio.Portfolio, error) {
 	query := `
-		SELECT id, address, updated_at
+		SELECT ` + portfolioColumns + `
+		FROM portfolios
+		WHERE 1 = 1%s
+		ORDER BY updated_at DESC
+	`
+	scope, scopeArgs := sqliteadapter.PortfolioScope(ctx, "id")
+
+	return r.queryPortfolios(ctx, fmt.Sprintf(query, scope), scopeArgs...)
+}
+
+// ListByOwner is not scoped to the caller, so members see the same wallets as the owner
+func (r *SQLiteRepository) ListByOwner(ctx context.Context, ownerID string) ([]*portfolio.Portfolio, error) {
+	query := `
+		SELECT ` + portfolioColumns + `
 		FROM portfolios
+		WHERE owner_id = ?
 		ORDER BY updated_at DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query)
+	return r.queryPortfolios(ctx, query, ownerID)
+}
+
+// portfolioSortColumns maps sort fields to columns; anything else is rejected
+var portfolioSortColumns = map[string]string{
+	portfolio.SortByName:      "name COLLATE NOCASE",
+	portfolio.SortByCreatedAt: "created_at",
+	portfolio.SortByUpdatedAt: "updated_at",
+}
+
+// ListPage returns one page of portfolios and the total number matching the filters
+func (r *SQLiteRepository) ListPage(ctx context.Context, opts portfolio.ListOptions) ([]*portfolio.Portfolio, int, error) {
+	sortBy := opts.SortBy
+	if sortBy == "" {
+		sortBy = portfolio.SortByUpdatedAt
+	}
+	column, ok := portfolioSortColumns[sortBy]
+	if !ok {
+		return nil, 0, fmt.Errorf("%w: unsupported sort field %q", portfolio.ErrInvalidPortfolio, sortBy)
+	}
+	direction := "ASC"
+	if opts.Desc {
+		direction = "DESC"
+	}
+
+	scope, args := sqliteadapter.PortfolioScope(ctx, "id")
+	where := "WHERE 1 = 1" + scope
+	if !opts.IncludeArchived {
+		where += " AND archived = 0"
+	}
+	if opts.Tag != "" {
+		where += " AND EXISTS (SELECT 1 FROM json_each(portfolios.tags) WHERE json_each.value = ?)"
+		args = append(args, strings.ToLower(opts.Tag))
+	}
+
+	var total int
+	if err := r.conn(ctx).QueryRowContext(ctx, `SELECT COUNT(*) FROM portfolios `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count portfolios: %w", err)
+	}
+
+	query := `SELECT ` + portfolioColumns + ` FROM portfolios ` + where +
+		` ORDER BY ` + column + ` ` + direction + `, id ` + direction + ` LIMIT ? OFFSET ?`
+	args = append(args, opts.PageSize, (opts.Page-1)*opts.PageSize)
+
+	portfolios, err := r.queryPortfolios(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return portfolios, total, nil
+}
+
+func (r *SQLiteRepository) queryPortfolios(ctx context.Context, query string, args ...interface{}) ([]*portfolio.Portfolio, error) {
+	rows, err := r.conn(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list portfolios: %w", err)
 	}
@@ -170,26 +310,11 @@ func (r *SQLiteRepository) List(ctx context.Context) ([]*portfolio.Portfolio, er
 
 	var portfolios []*portfolio.Portfolio
 	for rows.Next() {
-		var p portfolio.Portfolio
-		var updatedAtStr string
-
-		if err := rows.Scan(&p.ID, &p.Address, &updatedAtStr); err != nil {
-			return nil, fmt.Errorf("failed to scan portfolio: %w", err)
-		}
-
-		// Parse updated_at
-		updatedAt, err := time.Parse(time.RFC3339, updatedAtStr)
+		p, err := scanPortfolio(rows)
 		if err != nil {
-			// Try parsing as datetime format if RFC3339 fails
-			updatedAt, err = time.Parse("2006-01-02 15:04:05", updatedAtStr)
-			if err != nil {
-				return nil, fmt.Errorf("failed to parse updated_at: %w", err)
-			}
+			return nil, fmt.Errorf("failed to scan portfolio: %w", err)
 		}
-		p.UpdatedAt = updatedAt
-		p.Holdings = make([]*holding.Holding, 0)
-
-		portfolios = append(portfolios, &p)
+		portfolios = append(portfolios, p)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -199,18 +324,89 @@ func (r *SQLiteRepository) List(ctx context.Context) ([]*portfolio.Portfolio, er
 	return portfolios, nil
 }
 
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPortfolio(row rowScanner) (*portfolio.Portfolio, error) {
+	var (
+		p            portfolio.Portfolio
+		ownerID      sql.NullString
+		tagsStr      string
+		createdAtStr sql.NullString
+		updatedAtStr string
+	)
+
+	if err := row.Scan(&p.ID, &ownerID, &p.Address, &p.Name, &p.Description, &tagsStr, &p.Archived, &createdAtStr, &updatedAtStr); err != nil {
+		return nil, err
+	}
+	p.OwnerID = ownerID.String
+
+	if err := json.Unmarshal([]byte(tagsStr), &p.Tags); err != nil {
+		return nil, fmt.Errorf("failed to parse tags: %w", err)
+	}
+	if p.Tags == nil {
+		p.Tags = make([]string, 0)
+	}
+
+	updatedAt, err := parseTime(updatedAtStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse updated_at: %w", err)
+	}
+	p.UpdatedAt = updatedAt
+
+	p.CreatedAt = updatedAt
+	if createdAtStr.Valid {
+		if p.CreatedAt, err = parseTime(createdAtStr.String); err != nil {
+			return nil, fmt.Errorf("failed to parse created_at: %w", err)
+		}
+	}
+
+	p.Holdings = make([]*holding.Holding, 0)
+	return &p, nil
+}
+
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func encodeTags(tags []string) (string, error) {
+	if tags == nil {
+		tags = make([]string, 0)
+	}
+	data, err := json.Marshal(tags)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode tags: %w", err)
+	}
+	return string(data), nil
+}
+
+// parseTime accepts RFC3339 and the SQLite datetime() format used by the seed data
+func parseTime(s string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Parse("2006-01-02 15:04:05", s)
+	}
+	return t, nil
+}
+
 func (r *SQLiteRepository) ListWithHoldings(ctx context.Context) ([]*portfolio.Portfolio, error) {
 	query := `
 		SELECT 
 			p.id, p.address, p.updated_at,
 			h.id, h.portfolio_id, h.chain_id, h.token_id, h.token_symbol, h.token_address, 
-			h.amount, h.created_at, h.updated_at
+			h.amount, h.version, h.created_at, h.updated_at
 		FROM portfolios p
 		LEFT JOIN holdings h ON p.id = h.portfolio_id
+		WHERE 1 = 1%s
 		ORDER BY p.updated_at DESC, h.created_at ASC
 	`
+	scope, scopeArgs := sqliteadapter.PortfolioScope(ctx, "p.id")
 
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := r.conn(ctx).QueryContext(ctx, fmt.Sprintf(query, scope), scopeArgs...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list portfolios with holdings: %w", err)
 	}
@@ -222,7 +418,7 @@ func (r *SQLiteRepository) ListWithHoldings(ctx context.Context) ([]*portfolio.P
 	for rows.Next() {
 		var portfolioID, address, portfolioUpdatedAtStr string
 		var holdingID, holdingPortfolioID sql.NullString
-		var chainID sql.NullInt64
+		var chainID, version sql.NullInt64
 		var tokenID, tokenSymbol, tokenAddress sql.NullString
 		var amountStr, holdingCreatedAtStr, holdingUpdatedAtStr sql.NullString
 
@@ -237,6 +433,7 @@ func (r *SQLiteRepository) ListWithHoldings(ctx context.Context) ([]*portfolio.P
 			&tokenSymbol,
 			&tokenAddress,
 			&amountStr,
+			&version,
 			&holdingCreatedAtStr,
 			&holdingUpdatedAtStr,
 		)
@@ -272,6 +469,7 @@ func (r *SQLiteRepository) ListWithHoldings(ctx context.Context) ([]*portfolio.P
 				ID:          holdingID.String,
 				PortfolioID: holdingPortfolioID.String,
 				ChainID:     uint8(chainID.Int64),
+				Version:     version.Int64,
 			}
 
 			// Reconstruct Token
@@ -322,13 +520,14 @@ func (r *SQLiteRepository) ListWithHoldings(ctx context.Context) ([]*portfolio.P
 // loadHoldings loads all holdings for a given portfolio
 func (r *SQLiteRepository) loadHoldings(ctx context.Context, portfolioID string) ([]*holding.Holding, error) {
 	query := `
-		SELECT id, portfolio_id, chain_id, token_id, token_symbol, token_address, amount, created_at, updated_at
+		SELECT id, portfolio_id, chain_id, token_id, token_symbol, token_address, amount, version, created_at, updated_at
 		FROM holdings
-		WHERE portfolio_id = ?
+		WHERE portfolio_id = ?%s
 		ORDER BY created_at ASC
 	`
+	scope, scopeArgs := sqliteadapter.PortfolioScope(ctx, "portfolio_id")
 
-	rows, err := r.db.QueryContext(ctx, query, portfolioID)
+	rows, err := r.conn(ctx).QueryContext(ctx, fmt.Sprintf(query, scope), append([]interface{}{portfolioID}, scopeArgs...)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query holdings: %w", err)
 	}
@@ -347,6 +546,7 @@ func (r *SQLiteRepository) loadHoldings(ctx context.Context, portfolioID string)
 			&tokenSymbol,
 			&tokenAddress,
 			&amountStr,
+			&h.Version,
 			&createdAtStr,
 			&updatedAtStr,
 		); err != nil {
@@ -405,15 +605,16 @@ func (r *SQLiteRepository) Close() error {
 // GetHolding retrieves a holding by portfolio ID and holding ID
 func (r *SQLiteRepository) GetHolding(ctx context.Context, portfolioID string, holdingID string) (*holding.Holding, error) {
 	query := `
-		SELECT id, portfolio_id, chain_id, token_id, token_symbol, token_address, amount, created_at, updated_at
+		SELECT id, portfolio_id, chain_id, token_id, token_symbol, token_address, amount, version, created_at, updated_at
 		FROM holdings
 		WHERE portfolio_id = ? AND id = ?
 	`
+	scope, scopeArgs := sqliteadapter.PortfolioScope(ctx, "portfolio_id")
 
 	var h holding.Holding
 	var tokenID, tokenSymbol, tokenAddress, amountStr, createdAtStr, updatedAtStr string
 
-	err := r.db.QueryRowContext(ctx, query, portfolioID, holdingID).Scan(
+	err := r.conn(ctx).QueryRowContext(ctx, query+scope, append([]interface{}{portfolioID, holdingID}, scopeArgs...)...).Scan(
 		&h.ID,
 		&h.PortfolioID,
 		&h.ChainID,
@@ -421,6 +622,7 @@ func (r *SQLiteRepository) GetHolding(ctx context.Context, portfolioID string, h
 		&tokenSymbol,
 		&tokenAddress,
 		&amountStr,
+		&h.Version,
 		&createdAtStr,
 		&updatedAtStr,
 	)
@@ -474,10 +676,13 @@ func (r *SQLiteRepository) CreateHolding(ctx context.Context, portfolioID string
 		return fmt.Errorf("token is required")
 	}
 
+	// The row is only inserted when the portfolio is visible to the caller
 	query := `
-		INSERT INTO holdings (id, portfolio_id, chain_id, token_id, token_symbol, token_address, amount, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO holdings (id, portfolio_id, chain_id, token_id, token_symbol, token_address, amount, version, created_at, updated_at)
+		SELECT ?, ?, ?, ?, ?, ?, ?, 1, ?, ?
+		WHERE EXISTS (SELECT 1 FROM portfolios WHERE id = ?%s)
 	`
+	scope, scopeArgs := sqliteadapter.PortfolioScope(ctx, "id")
 
 	createdAtStr := h.CreatedAt.Format(time.RFC3339)
 	if h.CreatedAt.IsZero() {
@@ -489,7 +694,7 @@ func (r *SQLiteRepository) CreateHolding(ctx context.Context, portfolioID string
 		updatedAtStr = time.Now().Format(time.RFC3339)
 	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	args := append([]interface{}{
 		h.ID,
 		portfolioID,
 		h.ChainID,
@@ -499,15 +704,29 @@ func (r *SQLiteRepository) CreateHolding(ctx context.Context, portfolioID string
 		h.Amount.String(),
 		createdAtStr,
 		updatedAtStr,
-	)
-	if err != nil {
+		portfolioID,
+	}, scopeArgs...)
+	result, err := r.conn(ctx).ExecContext(ctx, fmt.Sprintf(query, scope), args...)
+	if sqliteadapter.IsUniqueViolation(err) {
+		return fmt.Errorf("%w: portfolio_id=%s already holds token %s", holding.ErrVersionConflict, portfolioID, h.Token.Address)
+	} else if err != nil {
 		return fmt.Errorf("failed to create holding: %w", err)
 	}
 
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: portfolio_id=%s", portfolio.ErrPortfolioNotFound, portfolioID)
+	}
+	h.Version = 1
+
 	return nil
 }
 
-// UpdateHolding updates an existing holding
+// UpdateHolding updates an existing holding. It fails with holding.ErrVersionConflict when
+// the holding was written since h was read.
 func (r *SQLiteRepository) UpdateHolding(ctx context.Context, portfolioID string, h *holding.Holding) error {
 	if h.Token == nil {
 		return fmt.Errorf("token is required")
@@ -515,16 +734,17 @@ func (r *SQLiteRepository) UpdateHolding(ctx context.Context, portfolioID string
 
 	query := `
 		UPDATE holdings
-		SET chain_id = ?, token_id = ?, token_symbol = ?, token_address = ?, amount = ?, updated_at = ?
-		WHERE id = ? AND portfolio_id = ?
+		SET chain_id = ?, token_id = ?, token_symbol = ?, token_address = ?, amount = ?, version = version + 1, updated_at = ?
+		WHERE id = ? AND portfolio_id = ? AND version = ?
 	`
+	scope, scopeArgs := sqliteadapter.PortfolioScope(ctx, "portfolio_id")
 
 	updatedAtStr := h.UpdatedAt.Format(time.RFC3339)
 	if h.UpdatedAt.IsZero() {
 		updatedAtStr = time.Now().Format(time.RFC3339)
 	}
 
-	result, err := r.db.ExecContext(ctx, query,
+	args := append([]interface{}{
 		h.ChainID,
 		h.Token.ID,
 		h.Token.Symbol,
@@ -533,28 +753,51 @@ func (r *SQLiteRepository) UpdateHolding(ctx context.Context, portfolioID string
 		updatedAtStr,
 		h.ID,
 		portfolioID,
-	)
+		h.Version,
+	}, scopeArgs...)
+	result, err := r.conn(ctx).ExecContext(ctx, query+scope, args...)
 	if err != nil {
 		return fmt.Errorf("failed to update holding: %w", err)
 	}
 
+	if err := checkVersionedWrite(ctx, r.conn(ctx), result, portfolioID, h); err != nil {
+		return err
+	}
+	h.Version++
+
+	return nil
+}
+
+// checkVersionedWrite tells a missing holding from a stale version when a write guarded
+// by the version column changed no rows
+func checkVersionedWrite(ctx context.Context, q querier, result sql.Result, portfolioID string, h *holding.Holding) error {
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("failed to get rows affected: %w", err)
 	}
+	if rowsAffected > 0 {
+		return nil
+	}
 
-	if rowsAffected == 0 {
+	scope, scopeArgs := sqliteadapter.PortfolioScope(ctx, "portfolio_id")
+	var current int64
+	err = q.QueryRowContext(ctx, `SELECT version FROM holdings WHERE id = ? AND portfolio_id = ?`+scope,
+		append([]interface{}{h.ID, portfolioID}, scopeArgs...)...).Scan(&current)
+	if err == sql.ErrNoRows {
 		return fmt.Errorf("%w: portfolio_id=%s, holding_id=%s", holding.ErrHoldingNotFound, portfolioID, h.ID)
+	} else if err != nil {
+		return fmt.Errorf("failed to get holding version: %w", err)
 	}
 
-	return nil
+	return fmt.Errorf("%w: holding_id=%s has version %d, expected %d", holding.ErrVersionConflict, h.ID, current, h.Version)
 }
 
 // DeleteHolding deletes a holding
 func (r *SQLiteRepository) DeleteHolding(ctx context.Context, portfolioID string, holdingID string) error {
 	query := `DELETE FROM holdings WHERE id = ? AND portfolio_id = ?`
+	scope, scopeArgs := sqliteadapter.PortfolioScope(ctx, "portfolio_id")
 
-	result, err := r.db.ExecContext(ctx, query, holdingID, portfolioID)
+	result, err := r.conn(ctx).ExecContext(ctx, query+scope, append([]interface{}{holdingID, portfolioID}, scopeArgs...)...)
 	if err != nil {
 		return fmt.Errorf("failed to delete holding: %w", err)
 	}
@@ -3,13 +3,16 @@ package portfolio
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"math/big"
 	"os"
 	"path/filepath"
 	"testing"
+	"testtask/internal/domain/holding"
 	"testtask/internal/domain/portfolio"
 	"testtask/internal/domain/token"
+	"testtask/internal/domain/user"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -27,8 +30,14 @@ func setupTestDB(t *testing.T) (*SQLiteRepository, func()) {
 	schema := `
 	CREATE TABLE IF NOT EXISTS portfolios (
 		id TEXT PRIMARY KEY,
-		address TEXT UNIQUE NOT NULL,
-		updated_at DATETIME NOT NULL
+		address TEXT NOT NULL,
+		updated_at DATETIME NOT NULL,
+		name TEXT NOT NULL DEFAULT '',
+		description TEXT NOT NULL DEFAULT '',
+		tags TEXT NOT NULL DEFAULT '[]',
+		archived INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME,
+		owner_id TEXT
 	);
 
 	CREATE TABLE IF NOT EXISTS holdings (
@@ -39,13 +48,91 @@ func setupTestDB(t *testing.T) (*SQLiteRepository, func()) {
 		token_symbol TEXT NOT NULL,
 		token_address TEXT NOT NULL,
 		amount TEXT NOT NULL,
+		version INTEGER NOT NULL DEFAULT 1,
 		created_at DATETIME NOT NULL,
 		updated_at DATETIME NOT NULL,
 		FOREIGN KEY (portfolio_id) REFERENCES portfolios(id) ON DELETE CASCADE
 	);
 
+	CREATE TABLE IF NOT EXISTS holding_ledger (
+		id TEXT PRIMARY KEY,
+		portfolio_id TEXT NOT NULL,
+		holding_id TEXT NOT NULL,
+		chain_id INTEGER NOT NULL,
+		token_id TEXT NOT NULL,
+		token_symbol TEXT NOT NULL,
+		token_address TEXT NOT NULL,
+		entry_type TEXT NOT NULL,
+		quantity TEXT NOT NULL,
+		price TEXT,
+		currency TEXT NOT NULL DEFAULT '',
+		note TEXT NOT NULL DEFAULT '',
+		counterparty TEXT NOT NULL DEFAULT '',
+		occurred_at DATETIME NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS custom_tokens (
+		id TEXT PRIMARY KEY,
+		portfolio_id TEXT
+	);
+
+	CREATE TABLE IF NOT EXISTS price_overrides (
+		id TEXT PRIMARY KEY,
+		portfolio_id TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS portfolio_members (
+		portfolio_id TEXT NOT NULL,
+		user_id TEXT NOT NULL,
+		role TEXT NOT NULL,
+		PRIMARY KEY (portfolio_id, user_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS share_tokens (
+		id TEXT PRIMARY KEY,
+		portfolio_id TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS exchange_records (
+		id TEXT PRIMARY KEY,
+		portfolio_id TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS exchange_connections (
+		id TEXT PRIMARY KEY,
+		portfolio_id TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS manual_entries (
+		id TEXT PRIMARY KEY,
+		portfolio_id TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS income_classifications (
+		transaction_id TEXT NOT NULL,
+		portfolio_id TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS income_accruals (
+		id TEXT PRIMARY KEY,
+		portfolio_id TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS transaction_annotations (
+		transaction_id TEXT NOT NULL,
+		portfolio_id TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS manual_transactions (
+		id TEXT PRIMARY KEY,
+		portfolio_id TEXT NOT NULL
+	);
+
 	CREATE INDEX IF NOT EXISTS idx_portfolios_address ON portfolios(address);
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_portfolios_owner_address ON portfolios(owner_id, address);
 	CREATE INDEX IF NOT EXISTS idx_holdings_portfolio_id ON holdings(portfolio_id);
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_holdings_portfolio_token ON holdings(portfolio_id, lower(token_address));
 	`
 
 	if _, err := db.Exec(schema); err != nil {
@@ -76,8 +163,14 @@ func setupTestDBWithFile(t *testing.T) (*SQLiteRepository, func()) {
 	schema := `
 	CREATE TABLE IF NOT EXISTS portfolios (
 		id TEXT PRIMARY KEY,
-		address TEXT UNIQUE NOT NULL,
-		updated_at DATETIME NOT NULL
+		address TEXT NOT NULL,
+		updated_at DATETIME NOT NULL,
+		name TEXT NOT NULL DEFAULT '',
+		description TEXT NOT NULL DEFAULT '',
+		tags TEXT NOT NULL DEFAULT '[]',
+		archived INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME,
+		owner_id TEXT
 	);
 
 	CREATE TABLE IF NOT EXISTS holdings (
@@ -88,13 +181,91 @@ func setupTestDBWithFile(t *testing.T) (*SQLiteRepository, func()) {
 		token_symbol TEXT NOT NULL,
 		token_address TEXT NOT NULL,
 		amount TEXT NOT NULL,
+		version INTEGER NOT NULL DEFAULT 1,
 		created_at DATETIME NOT NULL,
 		updated_at DATETIME NOT NULL,
 		FOREIGN KEY (portfolio_id) REFERENCES portfolios(id) ON DELETE CASCADE
 	);
 
+	CREATE TABLE IF NOT EXISTS holding_ledger (
+		id TEXT PRIMARY KEY,
+		portfolio_id TEXT NOT NULL,
+		holding_id TEXT NOT NULL,
+		chain_id INTEGER NOT NULL,
+		token_id TEXT NOT NULL,
+		token_symbol TEXT NOT NULL,
+		token_address TEXT NOT NULL,
+		entry_type TEXT NOT NULL,
+		quantity TEXT NOT NULL,
+		price TEXT,
+		currency TEXT NOT NULL DEFAULT '',
+		note TEXT NOT NULL DEFAULT '',
+		counterparty TEXT NOT NULL DEFAULT '',
+		occurred_at DATETIME NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS custom_tokens (
+		id TEXT PRIMARY KEY,
+		portfolio_id TEXT
+	);
+
+	CREATE TABLE IF NOT EXISTS price_overrides (
+		id TEXT PRIMARY KEY,
+		portfolio_id TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS portfolio_members (
+		portfolio_id TEXT NOT NULL,
+		user_id TEXT NOT NULL,
+		role TEXT NOT NULL,
+		PRIMARY KEY (portfolio_id, user_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS share_tokens (
+		id TEXT PRIMARY KEY,
+		portfolio_id TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS exchange_records (
+		id TEXT PRIMARY KEY,
+		portfolio_id TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS exchange_connections (
+		id TEXT PRIMARY KEY,
+		portfolio_id TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS manual_entries (
+		id TEXT PRIMARY KEY,
+		portfolio_id TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS income_classifications (
+		transaction_id TEXT NOT NULL,
+		portfolio_id TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS income_accruals (
+		id TEXT PRIMARY KEY,
+		portfolio_id TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS transaction_annotations (
+		transaction_id TEXT NOT NULL,
+		portfolio_id TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS manual_transactions (
+		id TEXT PRIMARY KEY,
+		portfolio_id TEXT NOT NULL
+	);
+
 	CREATE INDEX IF NOT EXISTS idx_portfolios_address ON portfolios(address);
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_portfolios_owner_address ON portfolios(owner_id, address);
 	CREATE INDEX IF NOT EXISTS idx_holdings_portfolio_id ON holdings(portfolio_id);
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_holdings_portfolio_token ON holdings(portfolio_id, lower(token_address));
 	`
 
 	if _, err := repo.db.Exec(schema); err != nil {
@@ -114,7 +285,7 @@ func TestSQLiteRepository_Create(t *testing.T) {
 	repo, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	ctx := context.Background()
+	ctx := user.AsSystem(context.Background())
 
 	t.Run("create new portfolio", func(t *testing.T) {
 		p := portfolio.NewPortfolio("portfolio-1", "0x1234567890abcdef")
@@ -186,7 +357,7 @@ func TestSQLiteRepository_GetByID(t *testing.T) {
 	repo, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	ctx := context.Background()
+	ctx := user.AsSystem(context.Background())
 
 	t.Run("get existing portfolio", func(t *testing.T) {
 		p := portfolio.NewPortfolio("portfolio-get-1", "0xget1234567890")
@@ -227,7 +398,7 @@ func TestSQLiteRepository_GetByAddress(t *testing.T) {
 	repo, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	ctx := context.Background()
+	ctx := user.AsSystem(context.Background())
 
 	t.Run("get existing portfolio by address", func(t *testing.T) {
 		address := "0xaddress123456789"
@@ -266,7 +437,7 @@ func TestSQLiteRepository_List(t *testing.T) {
 	repo, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	ctx := context.Background()
+	ctx := user.AsSystem(context.Background())
 
 	t.Run("list empty portfolios", func(t *testing.T) {
 		portfolios, err := repo.List(ctx)
@@ -338,7 +509,7 @@ func TestSQLiteRepository_ListWithHoldings(t *testing.T) {
 	repo, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	ctx := context.Background()
+	ctx := user.AsSystem(context.Background())
 
 	t.Run("list portfolios with holdings", func(t *testing.T) {
 		// Create portfolios
@@ -502,7 +673,7 @@ func TestSQLiteRepository_HoldingsData(t *testing.T) {
 	repo, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	ctx := context.Background()
+	ctx := user.AsSystem(context.Background())
 
 	t.Run("verify holdings data integrity", func(t *testing.T) {
 		p := portfolio.NewPortfolio("data-test-1", "0xdatatest11111")
@@ -574,7 +745,7 @@ func TestSQLiteRepository_FileBased(t *testing.T) {
 	repo, cleanup := setupTestDBWithFile(t)
 	defer cleanup()
 
-	ctx := context.Background()
+	ctx := user.AsSystem(context.Background())
 
 	t.Run("create and retrieve from file database", func(t *testing.T) {
 		p := portfolio.NewPortfolio("file-test-1", "0xfiletest11111")
@@ -599,7 +770,7 @@ func TestSQLiteRepository_ConcurrentAccess(t *testing.T) {
 	repo, cleanup := setupTestDBWithFile(t)
 	defer cleanup()
 
-	ctx := context.Background()
+	ctx := user.AsSystem(context.Background())
 
 	t.Run("concurrent creates", func(t *testing.T) {
 		// Create multiple portfolios concurrently
@@ -646,7 +817,7 @@ func TestSQLiteRepository_ErrorHandling(t *testing.T) {
 		repo, cleanup := setupTestDB(t)
 		cleanup() // Close the database
 
-		ctx := context.Background()
+		ctx := user.AsSystem(context.Background())
 		_, err := repo.GetByID(ctx, "test-id")
 		if err == nil {
 			t.Error("GetByID() expected error on closed database, got nil")
@@ -657,7 +828,7 @@ func TestSQLiteRepository_ErrorHandling(t *testing.T) {
 		repo, cleanup := setupTestDB(t)
 		defer cleanup()
 
-		ctx := context.Background()
+		ctx := user.AsSystem(context.Background())
 		// Try to query a non-existent table
 		_, err := repo.db.QueryContext(ctx, "SELECT * FROM non_existent_table")
 		if err == nil {
@@ -682,7 +853,7 @@ func TestSQLiteRepository_Integration(t *testing.T) {
 	repo, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	ctx := context.Background()
+	ctx := user.AsSystem(context.Background())
 
 	t.Run("full workflow", func(t *testing.T) {
 		// 1. Create portfolios
@@ -761,3 +932,271 @@ func TestSQLiteRepository_Integration(t *testing.T) {
 		}
 	})
 }
+
+func TestSQLiteRepository_UpdateAndDelete(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := user.AsSystem(context.Background())
+
+	p := portfolio.NewPortfolio("crud-1", "0xcrud11111111")
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	p.Name = "Long term"
+	p.Description = "Cold wallet"
+	p.Tags = []string{"cold", "eth"}
+	p.Archived = true
+	if err := repo.Update(ctx, p); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	retrieved, err := repo.GetByID(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if retrieved.Name != "Long term" || retrieved.Description != "Cold wallet" || !retrieved.Archived {
+		t.Errorf("GetByID() = %+v, want updated metadata", retrieved)
+	}
+	if len(retrieved.Tags) != 2 || retrieved.Tags[0] != "cold" || retrieved.Tags[1] != "eth" {
+		t.Errorf("GetByID() Tags = %v, want [cold eth]", retrieved.Tags)
+	}
+
+	if err := repo.Update(ctx, portfolio.NewPortfolio("missing", "0xmissing")); !errors.Is(err, portfolio.ErrPortfolioNotFound) {
+		t.Errorf("Update() missing error = %v, want ErrPortfolioNotFound", err)
+	}
+
+	insertTestHolding(t, repo, p.ID, "crud-holding-1", &token.Token{ID: "ethereum", Symbol: "ETH", Address: "0xeth"}, big.NewInt(1))
+	if _, err := repo.db.Exec(`INSERT INTO price_overrides (id, portfolio_id) VALUES ('override-1', ?)`, p.ID); err != nil {
+		t.Fatalf("Failed to insert price override: %v", err)
+	}
+
+	if err := repo.Delete(ctx, p.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := repo.GetByID(ctx, p.ID); !errors.Is(err, portfolio.ErrPortfolioNotFound) {
+		t.Errorf("GetByID() after delete error = %v, want ErrPortfolioNotFound", err)
+	}
+	for _, table := range portfolioScopedTables {
+		var count int
+		if err := repo.db.QueryRow(`SELECT COUNT(*) FROM `+table+` WHERE portfolio_id = ?`, p.ID).Scan(&count); err != nil {
+			t.Fatalf("Failed to count %s: %v", table, err)
+		}
+		if count != 0 {
+			t.Errorf("%s rows after delete = %d, want 0", table, count)
+		}
+	}
+	if err := repo.Delete(ctx, p.ID); !errors.Is(err, portfolio.ErrPortfolioNotFound) {
+		t.Errorf("Delete() twice error = %v, want ErrPortfolioNotFound", err)
+	}
+}
+
+func TestSQLiteRepository_ListPage(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := user.AsSystem(context.Background())
+	base := time.Now().Add(-time.Hour)
+
+	fixtures := []struct {
+		id, name string
+		tags     []string
+		archived bool
+	}{
+		{id: "page-1", name: "charlie", tags: []string{"defi"}},
+		{id: "page-2", name: "Alpha", tags: []string{"cold", "defi"}},
+		{id: "page-3", name: "bravo"},
+		{id: "page-4", name: "delta", tags: []string{"defi"}, archived: true},
+	}
+	for i, f := range fixtures {
+		p := portfolio.NewPortfolio(f.id, "0x"+f.id)
+		p.Name = f.name
+		p.Tags = f.tags
+		p.Archived = f.archived
+		p.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		p.UpdatedAt = p.CreatedAt
+		if err := repo.Create(ctx, p); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		opts      portfolio.ListOptions
+		wantIDs   []string
+		wantTotal int
+	}{
+		{
+			name:      "default order is oldest update first",
+			opts:      portfolio.ListOptions{Page: 1, PageSize: 10},
+			wantIDs:   []string{"page-1", "page-2", "page-3"},
+			wantTotal: 3,
+		},
+		{
+			name:      "sort by name case insensitive",
+			opts:      portfolio.ListOptions{Page: 1, PageSize: 2, SortBy: portfolio.SortByName},
+			wantIDs:   []string{"page-2", "page-3"},
+			wantTotal: 3,
+		},
+		{
+			name:      "second page descending by created_at",
+			opts:      portfolio.ListOptions{Page: 2, PageSize: 2, SortBy: portfolio.SortByCreatedAt, Desc: true},
+			wantIDs:   []string{"page-1"},
+			wantTotal: 3,
+		},
+		{
+			name:      "tag filter including archived",
+			opts:      portfolio.ListOptions{Page: 1, PageSize: 10, Tag: "DeFi", IncludeArchived: true},
+			wantIDs:   []string{"page-1", "page-2", "page-4"},
+			wantTotal: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			portfolios, total, err := repo.ListPage(ctx, tt.opts)
+			if err != nil {
+				t.Fatalf("ListPage() error = %v", err)
+			}
+			if total != tt.wantTotal {
+				t.Errorf("ListPage() total = %d, want %d", total, tt.wantTotal)
+			}
+			var ids []string
+			for _, p := range portfolios {
+				ids = append(ids, p.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.wantIDs) {
+				t.Errorf("ListPage() IDs = %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+
+	if _, _, err := repo.ListPage(ctx, portfolio.ListOptions{Page: 1, PageSize: 10, SortBy: "address"}); !errors.Is(err, portfolio.ErrInvalidPortfolio) {
+		t.Errorf("ListPage() unsupported sort error = %v, want ErrInvalidPortfolio", err)
+	}
+}
+
+func TestSQLiteRepository_OwnerScope(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	alice := user.WithUser(context.Background(), &user.User{ID: "alice"})
+	bob := user.WithUser(context.Background(), &user.User{ID: "bob"})
+
+	p := portfolio.NewPortfolio("owned-1", "0xowned1111111")
+	p.OwnerID = "alice"
+	if err := repo.Create(alice, p); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	insertTestHolding(t, repo, p.ID, "owned-holding-1", &token.Token{ID: "ethereum", Symbol: "ETH", Address: "0xeth"}, big.NewInt(1))
+
+	retrieved, err := repo.GetByIDWithHoldings(alice, p.ID)
+	if err != nil {
+		t.Fatalf("GetByIDWithHoldings() owner error = %v", err)
+	}
+	if retrieved.OwnerID != "alice" || len(retrieved.Holdings) != 1 {
+		t.Errorf("GetByIDWithHoldings() = owner %q with %d holdings, want alice with 1", retrieved.OwnerID, len(retrieved.Holdings))
+	}
+
+	if _, err := repo.GetByID(bob, p.ID); !errors.Is(err, portfolio.ErrPortfolioNotFound) {
+		t.Errorf("GetByID() other user error = %v, want ErrPortfolioNotFound", err)
+	}
+	if _, err := repo.GetByAddress(bob, p.Address); !errors.Is(err, portfolio.ErrPortfolioNotFound) {
+		t.Errorf("GetByAddress() other user error = %v, want ErrPortfolioNotFound", err)
+	}
+	if list, total, err := repo.ListPage(bob, portfolio.ListOptions{Page: 1, PageSize: 10}); err != nil || total != 0 || len(list) != 0 {
+		t.Errorf("ListPage() other user = %d/%d (err %v), want empty", len(list), total, err)
+	}
+
+	hijack := portfolio.NewPortfolio(p.ID, "0xhijack")
+	hijack.OwnerID = "bob"
+	if err := repo.Create(bob, hijack); !errors.Is(err, portfolio.ErrPortfolioExists) {
+		t.Errorf("Create() over other user's portfolio error = %v, want ErrPortfolioExists", err)
+	}
+	if err := repo.Update(bob, hijack); !errors.Is(err, portfolio.ErrPortfolioNotFound) {
+		t.Errorf("Update() other user error = %v, want ErrPortfolioNotFound", err)
+	}
+	if err := repo.CreateHolding(bob, p.ID, &holding.Holding{ID: "bob-holding", Token: &token.Token{ID: "x"}, Amount: big.NewInt(1)}); !errors.Is(err, portfolio.ErrPortfolioNotFound) {
+		t.Errorf("CreateHolding() other user error = %v, want ErrPortfolioNotFound", err)
+	}
+	if err := repo.DeleteHolding(bob, p.ID, "owned-holding-1"); !errors.Is(err, holding.ErrHoldingNotFound) {
+		t.Errorf("DeleteHolding() other user error = %v, want ErrHoldingNotFound", err)
+	}
+	if err := repo.Delete(bob, p.ID); !errors.Is(err, portfolio.ErrPortfolioNotFound) {
+		t.Errorf("Delete() other user error = %v, want ErrPortfolioNotFound", err)
+	}
+
+	// Members see shared portfolios and their holdings
+	if _, err := repo.db.Exec(`INSERT INTO portfolio_members (portfolio_id, user_id, role) VALUES (?, 'bob', 'viewer')`, p.ID); err != nil {
+		t.Fatalf("Failed to insert member: %v", err)
+	}
+	if shared, err := repo.GetByIDWithHoldings(bob, p.ID); err != nil || len(shared.Holdings) != 1 {
+		t.Errorf("GetByIDWithHoldings() member = %v (err %v), want portfolio with 1 holding", shared, err)
+	}
+	if list, total, err := repo.ListPage(bob, portfolio.ListOptions{Page: 1, PageSize: 10}); err != nil || total != 1 || len(list) != 1 {
+		t.Errorf("ListPage() member = %d/%d (err %v), want the shared portfolio", len(list), total, err)
+	}
+
+	// Listing by owner is not scoped, so members see all of the owner's wallets
+	second := portfolio.NewPortfolio("owned-2", "0xowned2222222")
+	second.OwnerID = "alice"
+	if err := repo.Create(alice, second); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if owned, err := repo.ListByOwner(bob, "alice"); err != nil || len(owned) != 2 {
+		t.Errorf("ListByOwner() member = %d portfolios (err %v), want both of alice's", len(owned), err)
+	}
+	if owned, err := repo.ListByOwner(alice, "bob"); err != nil || len(owned) != 0 {
+		t.Errorf("ListByOwner() other owner = %d portfolios (err %v), want none", len(owned), err)
+	}
+
+	// Contexts without a caller see nothing; background jobs opt in to every portfolio
+	if _, err := repo.GetByID(context.Background(), p.ID); !errors.Is(err, portfolio.ErrPortfolioNotFound) {
+		t.Errorf("GetByID() without caller error = %v, want ErrPortfolioNotFound", err)
+	}
+	if list, err := repo.List(context.Background()); err != nil || len(list) != 0 {
+		t.Errorf("List() without caller = %d portfolios (err %v), want none", len(list), err)
+	}
+	if _, err := repo.GetByAddress(context.Background(), p.Address); !errors.Is(err, portfolio.ErrPortfolioNotFound) {
+		t.Errorf("GetByAddress() without caller error = %v, want ErrPortfolioNotFound", err)
+	}
+	if _, err := repo.GetByID(user.AsSystem(context.Background()), p.ID); err != nil {
+		t.Errorf("GetByID() system error = %v", err)
+	}
+
+	// Reads on the owner's behalf see the owner's portfolios only
+	asAlice := user.AsOwner(bob, "alice")
+	if owned, err := repo.List(asAlice); err != nil || len(owned) != 2 {
+		t.Errorf("List() as owner = %d portfolios (err %v), want both of alice's", len(owned), err)
+	}
+	if _, err := repo.GetByAddress(asAlice, p.Address); err != nil {
+		t.Errorf("GetByAddress() as owner error = %v", err)
+	}
+	if owned, err := repo.List(user.AsOwner(alice, "bob")); err != nil || len(owned) != 0 {
+		t.Errorf("List() as other owner = %d portfolios (err %v), want none of alice's", len(owned), err)
+	}
+	if err := repo.Delete(alice, p.ID); err != nil {
+		t.Errorf("Delete() owner error = %v", err)
+	}
+
+	// Addresses are unique per owner: another user may track the same address
+	tracked := portfolio.NewPortfolio("alice-tracked", "0xtracked")
+	tracked.OwnerID = "alice"
+	if err := repo.Create(alice, tracked); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	same := portfolio.NewPortfolio("bob-tracked", "0xtracked")
+	same.OwnerID = "bob"
+	if err := repo.Create(bob, same); err != nil {
+		t.Errorf("Create() same address for other user error = %v", err)
+	}
+	if got, err := repo.GetByAddress(bob, "0xtracked"); err != nil || got.ID != same.ID {
+		t.Errorf("GetByAddress() other user = %v (err %v), want own portfolio", got, err)
+	}
+	duplicate := portfolio.NewPortfolio("alice-duplicate", "0xtracked")
+	duplicate.OwnerID = "alice"
+	if err := repo.Create(alice, duplicate); !errors.Is(err, portfolio.ErrPortfolioAddressExists) {
+		t.Errorf("Create() duplicate address for owner error = %v, want ErrPortfolioAddressExists", err)
+	}
+}
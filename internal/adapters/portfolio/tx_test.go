@@ -0,0 +1,163 @@
+package portfolio
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"testtask/internal/domain/holding"
+	"testtask/internal/domain/portfolio"
+	"testtask/internal/domain/token"
+	"testtask/internal/domain/user"
+)
+
+func TestSQLiteRepository_HoldingVersion(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := user.AsSystem(context.Background())
+	p := portfolio.NewPortfolio("version-1", "0xversion1111")
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	eth := &token.Token{ID: "ethereum", Symbol: "ETH", Address: "0xEth"}
+	h := holding.NewHolding(p.ID, "version-holding-1", eth, big.NewInt(5))
+	if err := repo.CreateHolding(ctx, p.ID, h); err != nil {
+		t.Fatalf("CreateHolding() error = %v", err)
+	}
+	if h.Version != 1 {
+		t.Errorf("CreateHolding() version = %d, want 1", h.Version)
+	}
+
+	duplicate := holding.NewHolding(p.ID, "version-holding-2", &token.Token{ID: "ethereum", Symbol: "ETH", Address: "0xeth"}, big.NewInt(1))
+	if err := repo.CreateHolding(ctx, p.ID, duplicate); !errors.Is(err, holding.ErrVersionConflict) {
+		t.Errorf("CreateHolding() for held token error = %v, want ErrVersionConflict", err)
+	}
+
+	stale := *h
+	h.Amount = big.NewInt(7)
+	if err := repo.UpdateHolding(ctx, p.ID, h); err != nil {
+		t.Fatalf("UpdateHolding() error = %v", err)
+	}
+	if h.Version != 2 {
+		t.Errorf("UpdateHolding() version = %d, want 2", h.Version)
+	}
+
+	tests := []struct {
+		name    string
+		holding *holding.Holding
+		wantErr error
+	}{
+		{name: "stale version", holding: &stale, wantErr: holding.ErrVersionConflict},
+		{name: "missing holding", holding: &holding.Holding{ID: "missing", Token: eth, Amount: big.NewInt(1), Version: 1}, wantErr: holding.ErrHoldingNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := repo.UpdateHolding(ctx, p.ID, tt.holding); !errors.Is(err, tt.wantErr) {
+				t.Errorf("UpdateHolding() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	stored, err := repo.GetHolding(ctx, p.ID, h.ID)
+	if err != nil {
+		t.Fatalf("GetHolding() error = %v", err)
+	}
+	if stored.Version != 2 || stored.Amount.Int64() != 7 {
+		t.Errorf("GetHolding() = version %d amount %s, want version 2 amount 7", stored.Version, stored.Amount)
+	}
+}
+
+func TestSQLiteRepository_WithinTxRollback(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := user.AsSystem(context.Background())
+	p := portfolio.NewPortfolio("tx-1", "0xtx1111")
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	failure := errors.New("abort")
+	h := holding.NewHolding(p.ID, "tx-holding-1", &token.Token{ID: "ethereum", Symbol: "ETH", Address: "0xeth"}, big.NewInt(3))
+	err := repo.WithinTx(ctx, func(ctx context.Context) error {
+		e := holding.NewLedgerEntry(h, holding.EntryDeposit, big.NewInt(3), time.Time{})
+		if err := repo.AppendEntry(ctx, p.ID, h, e); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("WithinTx() error = %v, want %v", err, failure)
+	}
+
+	if _, err := repo.GetHolding(ctx, p.ID, h.ID); !errors.Is(err, holding.ErrHoldingNotFound) {
+		t.Errorf("GetHolding() after rollback error = %v, want ErrHoldingNotFound", err)
+	}
+	entries, err := repo.ListEntries(ctx, p.ID, holding.LedgerFilter{})
+	if err != nil {
+		t.Fatalf("ListEntries() error = %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("ListEntries() after rollback = %d entries, want 0", len(entries))
+	}
+}
+
+// TestSQLiteRepository_ConcurrentDeposits runs read-modify-write deposits of one token
+// from several goroutines; none may be lost and only one holding may be created
+func TestSQLiteRepository_ConcurrentDeposits(t *testing.T) {
+	repo, cleanup := setupTestDBWithFile(t)
+	defer cleanup()
+
+	ctx := user.AsSystem(context.Background())
+	p := portfolio.NewPortfolio("concurrent-1", "0xconcurrent1111")
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	const workers = 8
+	eth := &token.Token{ID: "ethereum", Symbol: "ETH", Address: "0xeth"}
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- repo.WithinTx(ctx, func(ctx context.Context) error {
+				stored, err := repo.GetByIDWithHoldings(ctx, p.ID)
+				if err != nil {
+					return err
+				}
+				h := holding.NewHolding(p.ID, "", eth, big.NewInt(0))
+				if len(stored.Holdings) > 0 {
+					h = stored.Holdings[0]
+				}
+				e := holding.NewLedgerEntry(h, holding.EntryDeposit, big.NewInt(1), time.Time{})
+				h.Amount = new(big.Int).Add(h.Amount, e.Quantity)
+				return repo.AppendEntry(ctx, p.ID, h, e)
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("deposit error = %v", err)
+		}
+	}
+
+	holdings, err := repo.ListByPortfolioID(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("ListByPortfolioID() error = %v", err)
+	}
+	if len(holdings) != 1 {
+		t.Fatalf("ListByPortfolioID() = %d holdings, want 1", len(holdings))
+	}
+	if holdings[0].Amount.Int64() != workers || holdings[0].Version != workers {
+		t.Errorf("holding = amount %s version %d, want %d and %d", holdings[0].Amount, holdings[0].Version, workers, workers)
+	}
+}
@@ -0,0 +1,393 @@
+package price
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	sqliteadapter "testtask/internal/adapters/sqlite"
+	"testtask/internal/domain/price"
+
+	"github.com/google/uuid"
+)
+
+// SQLiteOverrideRepository implements price.OverrideRepository. Each change also
+// appends a row to price_override_audit within the same transaction.
+type SQLiteOverrideRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteOverrideRepository(db *sql.DB) *SQLiteOverrideRepository {
+	return &SQLiteOverrideRepository{db: db}
+}
+
+// auditTimeFormat is fixed width so audit entries sort chronologically as strings
+const auditTimeFormat = "2006-01-02T15:04:05.000000000Z07:00"
+
+const overrideColumns = `id, portfolio_id, token_address, kind, value, currency, reference_address,
+	adjustment_bps, effective_from, effective_to, reason, created_at, updated_at`
+
+func (r *SQLiteOverrideRepository) Create(ctx context.Context, o *price.Override) error {
+	return r.withAudit(ctx, o.PortfolioID, o.ID, price.OverrideCreated, nil, o, func(tx *sql.Tx) error {
+		query := `INSERT INTO price_overrides (` + overrideColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+		_, err := tx.ExecContext(ctx, query,
+			o.ID, o.PortfolioID, o.TokenAddress, string(o.Kind), bigIntArg(o.Value), o.Currency, o.ReferenceAddress,
+			o.AdjustmentBps, formatTime(o.EffectiveFrom), optionalTimeArg(o.EffectiveTo), o.Reason,
+			formatTime(o.CreatedAt), formatTime(o.UpdatedAt),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create price override: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *SQLiteOverrideRepository) Update(ctx context.Context, o *price.Override) error {
+	before, err := r.GetByID(ctx, o.PortfolioID, o.ID)
+	if err != nil {
+		return err
+	}
+
+	return r.withAudit(ctx, o.PortfolioID, o.ID, price.OverrideUpdated, before, o, func(tx *sql.Tx) error {
+		query := `
+			UPDATE price_overrides
+			SET kind = ?, value = ?, currency = ?, reference_address = ?, adjustment_bps = ?,
+				effective_from = ?, effective_to = ?, reason = ?, updated_at = ?
+			WHERE id = ? AND portfolio_id = ?
+		`
+		result, err := tx.ExecContext(ctx, query,
+			string(o.Kind), bigIntArg(o.Value), o.Currency, o.ReferenceAddress, o.AdjustmentBps,
+			formatTime(o.EffectiveFrom), optionalTimeArg(o.EffectiveTo), o.Reason, formatTime(o.UpdatedAt),
+			o.ID, o.PortfolioID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update price override: %w", err)
+		}
+		return requireRow(result, o.ID)
+	})
+}
+
+func (r *SQLiteOverrideRepository) Delete(ctx context.Context, portfolioID, id string) error {
+	before, err := r.GetByID(ctx, portfolioID, id)
+	if err != nil {
+		return err
+	}
+
+	return r.withAudit(ctx, portfolioID, id, price.OverrideDeleted, before, nil, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `DELETE FROM price_overrides WHERE id = ? AND portfolio_id = ?`, id, portfolioID)
+		if err != nil {
+			return fmt.Errorf("failed to delete price override: %w", err)
+		}
+		return requireRow(result, id)
+	})
+}
+
+func (r *SQLiteOverrideRepository) GetByID(ctx context.Context, portfolioID, id string) (*price.Override, error) {
+	query := `SELECT ` + overrideColumns + ` FROM price_overrides WHERE id = ? AND portfolio_id = ?`
+	scope, scopeArgs := sqliteadapter.PortfolioScope(ctx, "portfolio_id")
+
+	o, err := scanOverride(r.db.QueryRowContext(ctx, query+scope, append([]interface{}{id, portfolioID}, scopeArgs...)...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: id=%s", price.ErrOverrideNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to get price override: %w", err)
+	}
+
+	return o, nil
+}
+
+func (r *SQLiteOverrideRepository) ListByPortfolio(ctx context.Context, portfolioID string) ([]*price.Override, error) {
+	query := `
+		SELECT ` + overrideColumns + `
+		FROM price_overrides
+		WHERE portfolio_id = ?%s
+		ORDER BY token_address ASC, effective_from DESC
+	`
+	scope, scopeArgs := sqliteadapter.PortfolioScope(ctx, "portfolio_id")
+
+	return r.queryOverrides(ctx, fmt.Sprintf(query, scope), append([]interface{}{portfolioID}, scopeArgs...)...)
+}
+
+func (r *SQLiteOverrideRepository) Active(ctx context.Context, portfolioID string, at time.Time) (map[string]*price.Override, error) {
+	query := `
+		SELECT ` + overrideColumns + `
+		FROM price_overrides
+		WHERE portfolio_id = ? AND effective_from <= ? AND (effective_to IS NULL OR effective_to > ?)%s
+		ORDER BY effective_from DESC, created_at DESC
+	`
+	scope, scopeArgs := sqliteadapter.PortfolioScope(ctx, "portfolio_id")
+
+	atStr := formatTime(at)
+	overrides, err := r.queryOverrides(ctx, fmt.Sprintf(query, scope), append([]interface{}{portfolioID, atStr, atStr}, scopeArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	active := make(map[string]*price.Override, len(overrides))
+	for _, o := range overrides {
+		if _, exists := active[o.TokenAddress]; !exists {
+			active[o.TokenAddress] = o
+		}
+	}
+
+	return active, nil
+}
+
+func (r *SQLiteOverrideRepository) ListAudit(ctx context.Context, portfolioID string) ([]*price.OverrideAuditEntry, error) {
+	query := `
+		SELECT id, override_id, portfolio_id, action, before_state, after_state, created_at
+		FROM price_override_audit
+		WHERE portfolio_id = ?%s
+		ORDER BY created_at DESC, rowid DESC
+	`
+	scope, scopeArgs := sqliteadapter.PortfolioScope(ctx, "portfolio_id")
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(query, scope), append([]interface{}{portfolioID}, scopeArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list price override audit: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*price.OverrideAuditEntry
+	for rows.Next() {
+		var (
+			e         price.OverrideAuditEntry
+			before    sql.NullString
+			after     sql.NullString
+			createdAt string
+		)
+		if err := rows.Scan(&e.ID, &e.OverrideID, &e.PortfolioID, &e.Action, &before, &after, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan price override audit: %w", err)
+		}
+		if e.Before, err = decodeOverrideState(before); err != nil {
+			return nil, err
+		}
+		if e.After, err = decodeOverrideState(after); err != nil {
+			return nil, err
+		}
+		if e.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+			return nil, fmt.Errorf("failed to parse created_at: %w", err)
+		}
+		entries = append(entries, &e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating price override audit: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (r *SQLiteOverrideRepository) withAudit(ctx context.Context, portfolioID, overrideID, action string, before, after *price.Override, change func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := change(tx); err != nil {
+		return err
+	}
+
+	beforeState, err := encodeOverrideState(before)
+	if err != nil {
+		return err
+	}
+	afterState, err := encodeOverrideState(after)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO price_override_audit (id, override_id, portfolio_id, action, before_state, after_state, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, uuid.New().String(), overrideID, portfolioID, action, beforeState, afterState, time.Now().UTC().Format(auditTimeFormat))
+	if err != nil {
+		return fmt.Errorf("failed to write price override audit: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit price override: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLiteOverrideRepository) queryOverrides(ctx context.Context, query string, args ...interface{}) ([]*price.Override, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list price overrides: %w", err)
+	}
+	defer rows.Close()
+
+	var overrides []*price.Override
+	for rows.Next() {
+		o, err := scanOverride(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan price override: %w", err)
+		}
+		overrides = append(overrides, o)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating price overrides: %w", err)
+	}
+
+	return overrides, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanOverride(row rowScanner) (*price.Override, error) {
+	var (
+		o             price.Override
+		kind          string
+		value         sql.NullString
+		effectiveFrom string
+		effectiveTo   sql.NullString
+		createdAt     string
+		updatedAt     string
+	)
+
+	err := row.Scan(&o.ID, &o.PortfolioID, &o.TokenAddress, &kind, &value, &o.Currency, &o.ReferenceAddress,
+		&o.AdjustmentBps, &effectiveFrom, &effectiveTo, &o.Reason, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	o.Kind = price.OverrideKind(kind)
+	if value.Valid {
+		v, ok := new(big.Int).SetString(value.String, 10)
+		if !ok {
+			return nil, fmt.Errorf("failed to parse override value: %s", value.String)
+		}
+		o.Value = v
+	}
+	if o.EffectiveFrom, err = time.Parse(time.RFC3339, effectiveFrom); err != nil {
+		return nil, fmt.Errorf("failed to parse effective_from: %w", err)
+	}
+	if effectiveTo.Valid {
+		to, err := time.Parse(time.RFC3339, effectiveTo.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse effective_to: %w", err)
+		}
+		o.EffectiveTo = &to
+	}
+	if o.CreatedAt, err = time.Parse(time.RFC3339, createdAt); err != nil {
+		return nil, fmt.Errorf("failed to parse created_at: %w", err)
+	}
+	if o.UpdatedAt, err = time.Parse(time.RFC3339, updatedAt); err != nil {
+		return nil, fmt.Errorf("failed to parse updated_at: %w", err)
+	}
+
+	return &o, nil
+}
+
+// overrideState is the JSON snapshot of an override stored in the audit trail
+type overrideState struct {
+	ID               string     `json:"id"`
+	PortfolioID      string     `json:"portfolio_id"`
+	TokenAddress     string     `json:"token_address"`
+	Kind             string     `json:"kind"`
+	Value            string     `json:"value,omitempty"`
+	Currency         string     `json:"currency,omitempty"`
+	ReferenceAddress string     `json:"reference_address,omitempty"`
+	AdjustmentBps    int64      `json:"adjustment_bps,omitempty"`
+	EffectiveFrom    time.Time  `json:"effective_from"`
+	EffectiveTo      *time.Time `json:"effective_to,omitempty"`
+	Reason           string     `json:"reason,omitempty"`
+}
+
+func encodeOverrideState(o *price.Override) (interface{}, error) {
+	if o == nil {
+		return nil, nil
+	}
+	state := overrideState{
+		ID:               o.ID,
+		PortfolioID:      o.PortfolioID,
+		TokenAddress:     o.TokenAddress,
+		Kind:             string(o.Kind),
+		Currency:         o.Currency,
+		ReferenceAddress: o.ReferenceAddress,
+		AdjustmentBps:    o.AdjustmentBps,
+		EffectiveFrom:    o.EffectiveFrom,
+		EffectiveTo:      o.EffectiveTo,
+		Reason:           o.Reason,
+	}
+	if o.Value != nil {
+		state.Value = o.Value.String()
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode override state: %w", err)
+	}
+	return string(data), nil
+}
+
+func decodeOverrideState(data sql.NullString) (*price.Override, error) {
+	if !data.Valid {
+		return nil, nil
+	}
+
+	var state overrideState
+	if err := json.Unmarshal([]byte(data.String), &state); err != nil {
+		return nil, fmt.Errorf("failed to decode override state: %w", err)
+	}
+
+	o := &price.Override{
+		ID:               state.ID,
+		PortfolioID:      state.PortfolioID,
+		TokenAddress:     state.TokenAddress,
+		Kind:             price.OverrideKind(state.Kind),
+		Currency:         state.Currency,
+		ReferenceAddress: state.ReferenceAddress,
+		AdjustmentBps:    state.AdjustmentBps,
+		EffectiveFrom:    state.EffectiveFrom,
+		EffectiveTo:      state.EffectiveTo,
+		Reason:           state.Reason,
+	}
+	if state.Value != "" {
+		v, ok := new(big.Int).SetString(state.Value, 10)
+		if !ok {
+			return nil, fmt.Errorf("failed to parse override value: %s", state.Value)
+		}
+		o.Value = v
+	}
+	return o, nil
+}
+
+func requireRow(result sql.Result, id string) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: id=%s", price.ErrOverrideNotFound, id)
+	}
+	return nil
+}
+
+// formatTime stores times in UTC so RFC3339 strings compare chronologically
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func optionalTimeArg(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return formatTime(*t)
+}
+
+func bigIntArg(v *big.Int) interface{} {
+	if v == nil {
+		return nil
+	}
+	return v.String()
+}
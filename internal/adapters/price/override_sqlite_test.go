@@ -0,0 +1,172 @@
+package price
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/big"
+	"testing"
+	"testtask/internal/domain/price"
+	"testtask/internal/domain/user"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// setupOverrideTestDB creates an in-memory SQLite database with the price overrides schema
+func setupOverrideTestDB(t *testing.T) (*SQLiteOverrideRepository, func()) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	// Every connection to :memory: is a separate database
+	db.SetMaxOpenConns(1)
+
+	schema := `
+	CREATE TABLE IF NOT EXISTS price_overrides (
+		id TEXT PRIMARY KEY,
+		portfolio_id TEXT NOT NULL,
+		token_address TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		value TEXT,
+		currency TEXT NOT NULL DEFAULT '',
+		reference_address TEXT NOT NULL DEFAULT '',
+		adjustment_bps INTEGER NOT NULL DEFAULT 0,
+		effective_from DATETIME NOT NULL,
+		effective_to DATETIME,
+		reason TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS price_override_audit (
+		id TEXT PRIMARY KEY,
+		override_id TEXT NOT NULL,
+		portfolio_id TEXT NOT NULL,
+		action TEXT NOT NULL,
+		before_state TEXT,
+		after_state TEXT,
+		created_at DATETIME NOT NULL
+	);
+	`
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	return NewSQLiteOverrideRepository(db), func() { db.Close() }
+}
+
+func TestSQLiteOverrideRepository_Active(t *testing.T) {
+	repo, cleanup := setupOverrideTestDB(t)
+	defer cleanup()
+
+	ctx := user.AsSystem(context.Background())
+	now := time.Now().UTC().Truncate(time.Second)
+	expired := now.Add(-time.Hour)
+
+	overrides := []*price.Override{
+		price.NewOverride("portfolio-1", price.Override{
+			TokenAddress: "0xLOCKED", Kind: price.OverrideFixed, Value: big.NewInt(100), Currency: "usd",
+			EffectiveFrom: now.Add(-48 * time.Hour),
+		}),
+		price.NewOverride("portfolio-1", price.Override{
+			TokenAddress: "0xlocked", Kind: price.OverrideFixed, Value: big.NewInt(200), Currency: "usd",
+			EffectiveFrom: now.Add(-24 * time.Hour),
+		}),
+		price.NewOverride("portfolio-1", price.Override{
+			TokenAddress: "0xlocked", Kind: price.OverrideFixed, Value: big.NewInt(300), Currency: "usd",
+			EffectiveFrom: now.Add(24 * time.Hour),
+		}),
+		price.NewOverride("portfolio-1", price.Override{
+			TokenAddress: "0xotc", Kind: price.OverrideRelative, ReferenceAddress: "0xeth", AdjustmentBps: -2000,
+			EffectiveFrom: now.Add(-48 * time.Hour), EffectiveTo: &expired,
+		}),
+		price.NewOverride("portfolio-2", price.Override{
+			TokenAddress: "0xother", Kind: price.OverrideFixed, Value: big.NewInt(1), Currency: "usd",
+		}),
+	}
+	for _, o := range overrides {
+		if err := repo.Create(ctx, o); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	active, err := repo.Active(ctx, "portfolio-1", now)
+	if err != nil {
+		t.Fatalf("Active() error = %v", err)
+	}
+	if len(active) != 1 {
+		t.Fatalf("Active() returned %d overrides, want 1", len(active))
+	}
+	if got := active["0xlocked"]; got == nil || got.Value.Cmp(big.NewInt(200)) != 0 {
+		t.Errorf("active override for 0xlocked = %+v, want value 200", got)
+	}
+
+	list, err := repo.ListByPortfolio(ctx, "portfolio-1")
+	if err != nil {
+		t.Fatalf("ListByPortfolio() error = %v", err)
+	}
+	if len(list) != 4 {
+		t.Errorf("ListByPortfolio() returned %d overrides, want 4", len(list))
+	}
+	if got := list[len(list)-1]; got.EffectiveTo == nil || !got.EffectiveTo.Equal(expired) {
+		t.Errorf("EffectiveTo = %v, want %v", got.EffectiveTo, expired)
+	}
+}
+
+func TestSQLiteOverrideRepository_Audit(t *testing.T) {
+	repo, cleanup := setupOverrideTestDB(t)
+	defer cleanup()
+
+	ctx := user.AsSystem(context.Background())
+	o := price.NewOverride("portfolio-1", price.Override{
+		TokenAddress: "0xlocked", Kind: price.OverrideFixed, Value: big.NewInt(100), Currency: "usd", Reason: "OTC deal",
+	})
+	if err := repo.Create(ctx, o); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	updated := *o
+	updated.Value = big.NewInt(150)
+	if err := repo.Update(ctx, &updated); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if err := repo.Delete(ctx, "portfolio-1", o.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if _, err := repo.GetByID(ctx, "portfolio-1", o.ID); !errors.Is(err, price.ErrOverrideNotFound) {
+		t.Errorf("GetByID() after delete error = %v, want ErrOverrideNotFound", err)
+	}
+	if err := repo.Delete(ctx, "portfolio-1", o.ID); !errors.Is(err, price.ErrOverrideNotFound) {
+		t.Errorf("Delete() twice error = %v, want ErrOverrideNotFound", err)
+	}
+
+	entries, err := repo.ListAudit(ctx, "portfolio-1")
+	if err != nil {
+		t.Fatalf("ListAudit() error = %v", err)
+	}
+
+	wantActions := []string{price.OverrideDeleted, price.OverrideUpdated, price.OverrideCreated}
+	if len(entries) != len(wantActions) {
+		t.Fatalf("ListAudit() returned %d entries, want %d", len(entries), len(wantActions))
+	}
+	for i, want := range wantActions {
+		if entries[i].Action != want {
+			t.Errorf("entries[%d].Action = %q, want %q", i, entries[i].Action, want)
+		}
+	}
+
+	update := entries[1]
+	if update.Before == nil || update.Before.Value.Cmp(big.NewInt(100)) != 0 {
+		t.Errorf("update Before = %+v, want value 100", update.Before)
+	}
+	if update.After == nil || update.After.Value.Cmp(big.NewInt(150)) != 0 || update.After.Reason != "OTC deal" {
+		t.Errorf("update After = %+v, want value 150", update.After)
+	}
+	if entries[0].After != nil || entries[2].Before != nil {
+		t.Error("deleted entries should have no after state and created entries no before state")
+	}
+}
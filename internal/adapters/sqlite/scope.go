@@ -0,0 +1,68 @@
+package sqlite
+
+import (
+	"context"
+
+	"testtask/internal/domain/user"
+)
+
+const (
+	// accessiblePortfolios selects the IDs of portfolios a user owns or is a member of
+	accessiblePortfolios = ` IN (SELECT id FROM portfolios WHERE owner_id = ? UNION SELECT portfolio_id FROM portfolio_members WHERE user_id = ?)`
+	// ownedPortfolios selects the IDs of portfolios a user owns
+	ownedPortfolios = ` IN (SELECT id FROM portfolios WHERE owner_id = ?)`
+	// noPortfolios selects nothing; it is the scope of contexts without a caller
+	noPortfolios = ` IN (SELECT id FROM portfolios WHERE 0)`
+)
+
+// PortfolioScope restricts rows whose column references a portfolio to portfolios the
+// authenticated caller in ctx owns or is a member of, or to the portfolios of the owner
+// set by user.AsOwner. Only user.AsSystem contexts are not scoped; any other context
+// without a caller matches no rows.
+func PortfolioScope(ctx context.Context, column string) (string, []interface{}) {
+	set, args, scoped := portfolioSet(ctx)
+	if !scoped {
+		return "", nil
+	}
+	return " AND " + column + set, args
+}
+
+// SharedPortfolioScope is PortfolioScope for rows that are either global (NULL column)
+// or belong to one portfolio. Contexts without a caller match the global rows only.
+func SharedPortfolioScope(ctx context.Context, column string) (string, []interface{}) {
+	set, args, scoped := portfolioSet(ctx)
+	if !scoped {
+		return "", nil
+	}
+	return " AND (" + column + " IS NULL OR " + column + set + ")", args
+}
+
+// OwnerScope restricts rows whose column holds a user ID to the caller in ctx, or to
+// the owner set by user.AsOwner. It fails closed like PortfolioScope.
+func OwnerScope(ctx context.Context, column string) (string, []interface{}) {
+	if ownerID, ok := user.OwnerFromContext(ctx); ok {
+		return " AND " + column + " = ?", []interface{}{ownerID}
+	}
+	if caller, ok := user.FromContext(ctx); ok {
+		return " AND " + column + " = ?", []interface{}{caller.ID}
+	}
+	if user.IsSystem(ctx) {
+		return "", nil
+	}
+	return " AND 0", nil
+}
+
+// portfolioSet returns the clause selecting the portfolios ctx may read; scoped is
+// false for system contexts, which read every portfolio
+func portfolioSet(ctx context.Context) (set string, args []interface{}, scoped bool) {
+	if ownerID, ok := user.OwnerFromContext(ctx); ok {
+		return ownedPortfolios, []interface{}{ownerID}, true
+	}
+	if caller, ok := user.FromContext(ctx); ok {
+		return accessiblePortfolios, []interface{}{caller.ID, caller.ID}, true
+	}
+	if user.IsSystem(ctx) {
+		return "", nil, false
+	}
+	return noPortfolios, nil, true
+}
@@ -0,0 +1,61 @@
+package tax
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"testtask/internal/domain/tax"
+)
+
+// rulesFile is the JSON layout of the jurisdiction rules file, see static/tax_rules.json
+type rulesFile struct {
+	Jurisdictions []struct {
+		Code           string   `json:"code"`
+		Name           string   `json:"name"`
+		Currency       string   `json:"currency"`
+		Method         string   `json:"method"`
+		AllowedMethods []string `json:"allowed_methods"`
+		LongTermYears  int      `json:"long_term_years"`
+		LongTermExempt bool     `json:"long_term_exempt"`
+		TaxYearStart   string   `json:"tax_year_start"` // MM-DD
+	} `json:"jurisdictions"`
+}
+
+// LoadRuleSet reads the capital gains rules of each jurisdiction from a JSON file
+func LoadRuleSet(path string) (*tax.RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tax rules: %w", err)
+	}
+
+	var file rulesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tax rules: %w", err)
+	}
+
+	rules := make([]*tax.Rules, 0, len(file.Jurisdictions))
+	for _, j := range file.Jurisdictions {
+		start, err := time.Parse("01-02", j.TaxYearStart)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: tax year start %q is not MM-DD", tax.ErrInvalidRules, j.Code, j.TaxYearStart)
+		}
+		r := &tax.Rules{
+			Code:           j.Code,
+			Name:           j.Name,
+			Currency:       j.Currency,
+			Method:         tax.Method(j.Method),
+			LongTermYears:  j.LongTermYears,
+			LongTermExempt: j.LongTermExempt,
+			YearStartMonth: start.Month(),
+			YearStartDay:   start.Day(),
+		}
+		for _, m := range j.AllowedMethods {
+			r.AllowedMethods = append(r.AllowedMethods, tax.Method(m))
+		}
+		rules = append(rules, r)
+	}
+
+	return tax.NewRuleSet(rules)
+}
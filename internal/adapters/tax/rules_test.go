@@ -0,0 +1,28 @@
+package tax
+
+import (
+	"testing"
+	"time"
+
+	"testtask/internal/domain/tax"
+)
+
+func TestLoadRuleSet_Bundled(t *testing.T) {
+	rules, err := LoadRuleSet("../../../static/tax_rules.json")
+	if err != nil {
+		t.Fatalf("LoadRuleSet() error = %v", err)
+	}
+
+	us, err := rules.Lookup("US")
+	if err != nil || us.Method != tax.MethodFIFO || us.LongTermYears != 1 || us.LongTermExempt || !us.Allows(tax.MethodHIFO) {
+		t.Errorf("Lookup(US) = %+v, %v, want FIFO with a one year holding period", us, err)
+	}
+	de, err := rules.Lookup("DE")
+	if err != nil || !de.LongTermExempt || de.Currency != "eur" || de.Allows(tax.MethodLIFO) {
+		t.Errorf("Lookup(DE) = %+v, %v, want FIFO only with the one year exemption", de, err)
+	}
+	uk, err := rules.Lookup("UK")
+	if err != nil || uk.Method != tax.MethodPool || uk.YearStartMonth != time.April || uk.YearStartDay != 6 {
+		t.Errorf("Lookup(UK) = %+v, %v, want pooling with the tax year starting 6 April", uk, err)
+	}
+}
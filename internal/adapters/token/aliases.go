@@ -0,0 +1,69 @@
+package token
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"testtask/internal/domain/token"
+)
+
+// aliasFile is the JSON layout of the alias registry file, see static/aliases.json
+type aliasFile struct {
+	Chains []struct {
+		ChainID string `json:"chain_id"`
+		Native  *struct {
+			ID         string `json:"id"`
+			Name       string `json:"name"`
+			Symbol     string `json:"symbol"`
+			Decimals   uint8  `json:"decimals"`
+			PriceProxy string `json:"price_proxy"`
+		} `json:"native"`
+		Groups []struct {
+			ID       string   `json:"id"`
+			Name     string   `json:"name"`
+			Symbol   string   `json:"symbol"`
+			Decimals uint8    `json:"decimals"`
+			Members  []string `json:"members"`
+		} `json:"groups"`
+	} `json:"chains"`
+}
+
+// LoadAliasRegistry reads native assets and token equivalence groups from a JSON file
+func LoadAliasRegistry(path string) (*token.AliasRegistry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alias registry: %w", err)
+	}
+
+	var file aliasFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal alias registry: %w", err)
+	}
+
+	chains := make([]*token.ChainAliases, 0, len(file.Chains))
+	for _, c := range file.Chains {
+		chain := &token.ChainAliases{ChainID: c.ChainID}
+		if c.Native != nil {
+			chain.Native = &token.NativeAsset{
+				ID:         c.Native.ID,
+				Name:       c.Native.Name,
+				Symbol:     c.Native.Symbol,
+				Decimal:    c.Native.Decimals,
+				PriceProxy: c.Native.PriceProxy,
+			}
+		}
+		for _, g := range c.Groups {
+			chain.Groups = append(chain.Groups, &token.AliasGroup{
+				ID:      g.ID,
+				Name:    g.Name,
+				Symbol:  g.Symbol,
+				Decimal: g.Decimals,
+				Members: g.Members,
+			})
+		}
+		chains = append(chains, chain)
+	}
+
+	return token.NewAliasRegistry(chains)
+}
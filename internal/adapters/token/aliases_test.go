@@ -0,0 +1,27 @@
+package token
+
+import (
+	"testing"
+
+	"testtask/internal/domain/token"
+)
+
+func TestLoadAliasRegistry_Bundled(t *testing.T) {
+	registry, err := LoadAliasRegistry("../../../static/aliases.json")
+	if err != nil {
+		t.Fatalf("LoadAliasRegistry() error = %v", err)
+	}
+
+	native, ok := registry.Native("1")
+	if !ok || native.Symbol != "ETH" || !token.IsAddress(native.PriceProxy) {
+		t.Errorf("Native(1) = %+v, %v", native, ok)
+	}
+
+	weth, ok := registry.Group("1", native.PriceProxy)
+	if !ok {
+		t.Fatal("WETH should belong to an alias group")
+	}
+	if eth, _ := registry.Group("1", token.ZeroAddress); eth != weth {
+		t.Error("native ETH and WETH should be in the same group")
+	}
+}
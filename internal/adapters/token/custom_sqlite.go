@@ -0,0 +1,257 @@
+package token
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	sqliteadapter "testtask/internal/adapters/sqlite"
+	"testtask/internal/domain/token"
+)
+
+// SQLiteCustomRepository implements token.CustomRepository on top of the custom_tokens table
+type SQLiteCustomRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteCustomRepository(db *sql.DB) *SQLiteCustomRepository {
+	return &SQLiteCustomRepository{db: db}
+}
+
+const customTokenColumns = `id, portfolio_id, owner_id, chain_id, address, name, symbol, decimals,
+	manual_price, price_currency, price_updated_at, created_at, updated_at`
+
+func (r *SQLiteCustomRepository) Create(ctx context.Context, t *token.CustomToken) error {
+	query := `INSERT INTO custom_tokens (` + customTokenColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	price, priceUpdatedAt := manualPriceArgs(t)
+	_, err := r.db.ExecContext(ctx, query,
+		t.ID,
+		nullString(t.PortfolioID),
+		nullString(t.OwnerID),
+		t.ChainID,
+		strings.ToLower(t.Token.Address),
+		t.Token.Name,
+		t.Token.Symbol,
+		t.Token.Decimal,
+		price,
+		t.PriceCurrency,
+		priceUpdatedAt,
+		t.CreatedAt.Format(time.RFC3339),
+		t.UpdatedAt.Format(time.RFC3339),
+	)
+	if err != nil {
+		if sqliteadapter.IsUniqueViolation(err) {
+			return fmt.Errorf("%w: address=%s", token.ErrCustomTokenExists, t.Token.Address)
+		}
+		return fmt.Errorf("failed to create custom token: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SQLiteCustomRepository) Update(ctx context.Context, t *token.CustomToken) error {
+	query := `
+		UPDATE custom_tokens
+		SET name = ?, symbol = ?, decimals = ?, manual_price = ?, price_currency = ?, price_updated_at = ?, updated_at = ?
+		WHERE id = ?
+	`
+	scope, scopeArgs := sqliteadapter.SharedPortfolioScope(ctx, "portfolio_id")
+
+	price, priceUpdatedAt := manualPriceArgs(t)
+	args := append([]interface{}{
+		t.Token.Name,
+		t.Token.Symbol,
+		t.Token.Decimal,
+		price,
+		t.PriceCurrency,
+		priceUpdatedAt,
+		t.UpdatedAt.Format(time.RFC3339),
+		t.ID,
+	}, scopeArgs...)
+	result, err := r.db.ExecContext(ctx, query+scope, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update custom token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: id=%s", token.ErrCustomTokenNotFound, t.ID)
+	}
+
+	return nil
+}
+
+func (r *SQLiteCustomRepository) Delete(ctx context.Context, id string) error {
+	scope, scopeArgs := sqliteadapter.SharedPortfolioScope(ctx, "portfolio_id")
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM custom_tokens WHERE id = ?`+scope, append([]interface{}{id}, scopeArgs...)...)
+	if err != nil {
+		return fmt.Errorf("failed to delete custom token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: id=%s", token.ErrCustomTokenNotFound, id)
+	}
+
+	return nil
+}
+
+func (r *SQLiteCustomRepository) GetByID(ctx context.Context, id string) (*token.CustomToken, error) {
+	query := `SELECT ` + customTokenColumns + ` FROM custom_tokens WHERE id = ?`
+	scope, scopeArgs := sqliteadapter.SharedPortfolioScope(ctx, "portfolio_id")
+
+	t, err := scanCustomToken(r.db.QueryRowContext(ctx, query+scope, append([]interface{}{id}, scopeArgs...)...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: id=%s", token.ErrCustomTokenNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to get custom token: %w", err)
+	}
+
+	return t, nil
+}
+
+func (r *SQLiteCustomRepository) List(ctx context.Context, portfolioID string) ([]*token.CustomToken, error) {
+	query := `
+		SELECT ` + customTokenColumns + `
+		FROM custom_tokens
+		WHERE (portfolio_id IS NULL OR portfolio_id = ?)%s
+		ORDER BY symbol ASC, address ASC
+	`
+	scope, scopeArgs := sqliteadapter.SharedPortfolioScope(ctx, "portfolio_id")
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(query, scope), append([]interface{}{portfolioID}, scopeArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom tokens: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCustomTokens(rows)
+}
+
+func (r *SQLiteCustomRepository) GetByAddresses(ctx context.Context, portfolioID string, addresses []string) (map[string]*token.CustomToken, error) {
+	result := make(map[string]*token.CustomToken, len(addresses))
+	if len(addresses) == 0 {
+		return result, nil
+	}
+
+	args := make([]interface{}, len(addresses))
+	for i, address := range addresses {
+		args[i] = strings.ToLower(address)
+	}
+
+	// Portfolio tokens sort before global ones and mainnet before other chains,
+	// so the first row per address wins
+	query := `
+		SELECT ` + customTokenColumns + `
+		FROM custom_tokens
+		WHERE lower(address) IN (?` + strings.Repeat(",?", len(args)-1) + `)
+			AND (portfolio_id IS NULL OR portfolio_id = ? OR ? = ?)%s
+		ORDER BY portfolio_id IS NULL, CAST(chain_id AS INTEGER), created_at
+	`
+	scope, scopeArgs := sqliteadapter.SharedPortfolioScope(ctx, "portfolio_id")
+	args = append(args, portfolioID, portfolioID, token.AnyPortfolio)
+	args = append(args, scopeArgs...)
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(query, scope), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get custom tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens, err := scanCustomTokens(rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tokens {
+		if _, exists := result[t.Token.Address]; !exists {
+			result[t.Token.Address] = t
+		}
+	}
+
+	return result, nil
+}
+
+func manualPriceArgs(t *token.CustomToken) (interface{}, interface{}) {
+	if t.ManualPrice == nil {
+		return nil, nil
+	}
+	return t.ManualPrice.String(), t.PriceUpdatedAt.Format(time.RFC3339)
+}
+
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func scanCustomToken(row rowScanner) (*token.CustomToken, error) {
+	var (
+		c              token.CustomToken
+		t              token.Token
+		portfolioID    sql.NullString
+		ownerID        sql.NullString
+		manualPrice    sql.NullString
+		priceUpdatedAt sql.NullString
+		createdAt      string
+		updatedAt      string
+	)
+
+	err := row.Scan(&c.ID, &portfolioID, &ownerID, &c.ChainID, &t.Address, &t.Name, &t.Symbol, &t.Decimal,
+		&manualPrice, &c.PriceCurrency, &priceUpdatedAt, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	c.PortfolioID = portfolioID.String
+	c.OwnerID = ownerID.String
+	c.Token = &t
+	if manualPrice.Valid {
+		value, ok := new(big.Int).SetString(manualPrice.String, 10)
+		if !ok {
+			return nil, fmt.Errorf("failed to parse manual price: %s", manualPrice.String)
+		}
+		c.ManualPrice = value
+	}
+	if priceUpdatedAt.Valid {
+		if c.PriceUpdatedAt, err = time.Parse(time.RFC3339, priceUpdatedAt.String); err != nil {
+			return nil, fmt.Errorf("failed to parse price_updated_at: %w", err)
+		}
+	}
+	if c.CreatedAt, err = time.Parse(time.RFC3339, createdAt); err != nil {
+		return nil, fmt.Errorf("failed to parse created_at: %w", err)
+	}
+	if c.UpdatedAt, err = time.Parse(time.RFC3339, updatedAt); err != nil {
+		return nil, fmt.Errorf("failed to parse updated_at: %w", err)
+	}
+
+	return &c, nil
+}
+
+func scanCustomTokens(rows *sql.Rows) ([]*token.CustomToken, error) {
+	var tokens []*token.CustomToken
+	for rows.Next() {
+		t, err := scanCustomToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan custom token: %w", err)
+		}
+		tokens = append(tokens, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating custom tokens: %w", err)
+	}
+
+	return tokens, nil
+}
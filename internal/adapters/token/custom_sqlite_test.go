@@ -0,0 +1,198 @@
+package token
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/big"
+	"testing"
+	"testtask/internal/domain/price"
+	"testtask/internal/domain/token"
+	"testtask/internal/domain/user"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// setupCustomTestDB creates an in-memory SQLite database with the custom tokens schema
+func setupCustomTestDB(t *testing.T) (*SQLiteCustomRepository, func()) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+
+	schema := `
+	CREATE TABLE IF NOT EXISTS custom_tokens (
+		id TEXT PRIMARY KEY,
+		portfolio_id TEXT,
+		owner_id TEXT,
+		chain_id TEXT NOT NULL,
+		address TEXT NOT NULL,
+		name TEXT NOT NULL,
+		symbol TEXT NOT NULL,
+		decimals INTEGER NOT NULL,
+		manual_price TEXT,
+		price_currency TEXT NOT NULL DEFAULT '',
+		price_updated_at DATETIME,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_custom_tokens_chain_address_portfolio ON custom_tokens(chain_id, lower(address), portfolio_id);
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_custom_tokens_chain_address_global ON custom_tokens(chain_id, lower(address)) WHERE portfolio_id IS NULL;
+	`
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	return NewSQLiteCustomRepository(db), func() { db.Close() }
+}
+
+func TestSQLiteCustomRepository(t *testing.T) {
+	repo, cleanup := setupCustomTestDB(t)
+	defer cleanup()
+
+	ctx := user.AsSystem(context.Background())
+
+	global := token.NewCustomToken("", "1", &token.Token{Name: "Internal", Symbol: "INT", Address: "0xAAAA", Decimal: 18})
+	global.SetManualPrice(big.NewInt(250000000), "USD")
+	global.OwnerID = "admin-1"
+	scoped := token.NewCustomToken("portfolio-1", "11155111", &token.Token{Name: "Test", Symbol: "TST", Address: "0xbbbb", Decimal: 6})
+
+	for _, c := range []*token.CustomToken{global, scoped} {
+		if err := repo.Create(ctx, c); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	t.Run("duplicate address", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			token   *token.CustomToken
+			wantErr bool
+		}{
+			{"global on the same chain", token.NewCustomToken("", "1", &token.Token{Symbol: "DUP", Address: "0xAAAA"}), true},
+			{"same portfolio and chain", token.NewCustomToken("portfolio-1", "11155111", &token.Token{Symbol: "DUP", Address: "0xbbbb"}), true},
+			{"global on another chain", token.NewCustomToken("", "137", &token.Token{Symbol: "POL", Address: "0xaaaa"}), false},
+			{"another portfolio", token.NewCustomToken("portfolio-2", "11155111", &token.Token{Symbol: "OWN", Address: "0xbbbb"}), false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				err := repo.Create(ctx, tt.token)
+				if tt.wantErr && !errors.Is(err, token.ErrCustomTokenExists) {
+					t.Errorf("Create() error = %v, want ErrCustomTokenExists", err)
+				}
+				if !tt.wantErr {
+					if err != nil {
+						t.Fatalf("Create() error = %v", err)
+					}
+					if err := repo.Delete(ctx, tt.token.ID); err != nil {
+						t.Fatalf("Delete() error = %v", err)
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("portfolio token shadows global", func(t *testing.T) {
+		own := token.NewCustomToken("portfolio-1", "1", &token.Token{Name: "Own", Symbol: "OWN", Address: "0xaaaa", Decimal: 18})
+		if err := repo.Create(ctx, own); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		defer repo.Delete(ctx, own.ID)
+
+		got, err := repo.GetByAddresses(ctx, "portfolio-1", []string{"0xAAAA", "0xbbbb"})
+		if err != nil {
+			t.Fatalf("GetByAddresses() error = %v", err)
+		}
+		if len(got) != 2 || got["0xaaaa"].ID != own.ID || got["0xbbbb"].ID != scoped.ID {
+			t.Errorf("GetByAddresses(portfolio-1) = %v, want the portfolio tokens", got)
+		}
+
+		got, err = repo.GetByAddresses(ctx, "", []string{"0xaaaa", "0xbbbb"})
+		if err != nil {
+			t.Fatalf("GetByAddresses() error = %v", err)
+		}
+		if len(got) != 1 || got["0xaaaa"].ID != global.ID {
+			t.Errorf("GetByAddresses(\"\") = %v, want only the global token", got)
+		}
+	})
+
+	t.Run("list by scope", func(t *testing.T) {
+		all, err := repo.List(ctx, "portfolio-1")
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if len(all) != 2 {
+			t.Errorf("List(portfolio-1) = %d tokens, want 2", len(all))
+		}
+
+		globalOnly, err := repo.List(ctx, "")
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if len(globalOnly) != 1 || globalOnly[0].Token.Symbol != "INT" {
+			t.Errorf("List(\"\") = %v, want only the global token", globalOnly)
+		}
+	})
+
+	t.Run("round trips manual price", func(t *testing.T) {
+		got, err := repo.GetByID(ctx, global.ID)
+		if err != nil {
+			t.Fatalf("GetByID() error = %v", err)
+		}
+		if got.ManualPrice == nil || got.ManualPrice.Cmp(big.NewInt(250000000)) != 0 || got.PriceCurrency != "usd" {
+			t.Errorf("GetByID() price = %v %s, want 250000000 usd", got.ManualPrice, got.PriceCurrency)
+		}
+		if got.Token.Address != "0xaaaa" {
+			t.Errorf("GetByID() address = %s, want lowercase", got.Token.Address)
+		}
+		if got.OwnerID != "admin-1" {
+			t.Errorf("GetByID() owner = %q, want admin-1", got.OwnerID)
+		}
+	})
+
+	t.Run("manual price provider", func(t *testing.T) {
+		provider := NewManualPriceProvider(repo)
+		withPrice := &token.Token{Address: "0xAaAa"}
+		withoutPrice := &token.Token{Address: "0xbbbb"}
+
+		prices, err := provider.GetPrices(ctx, []*token.Token{withPrice, withoutPrice}, "usd")
+		if err != nil {
+			t.Fatalf("GetPrices() error = %v", err)
+		}
+		if len(prices) != 1 || prices[withPrice].Source != price.SourceManual {
+			t.Errorf("GetPrices() = %v, want one manual price", prices)
+		}
+
+		prices, err = provider.GetPrices(ctx, []*token.Token{withPrice}, "eur")
+		if err != nil {
+			t.Fatalf("GetPrices() error = %v", err)
+		}
+		if len(prices) != 0 {
+			t.Errorf("GetPrices(eur) = %v, want no price in another currency", prices)
+		}
+	})
+
+	t.Run("clear price and delete", func(t *testing.T) {
+		global.SetManualPrice(nil, "")
+		if err := repo.Update(ctx, global); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		got, err := repo.GetByID(ctx, global.ID)
+		if err != nil {
+			t.Fatalf("GetByID() error = %v", err)
+		}
+		if got.ManualPrice != nil {
+			t.Errorf("ManualPrice = %v, want nil after clearing", got.ManualPrice)
+		}
+
+		if err := repo.Delete(ctx, global.ID); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if _, err := repo.GetByID(ctx, global.ID); !errors.Is(err, token.ErrCustomTokenNotFound) {
+			t.Errorf("GetByID() after delete error = %v, want ErrCustomTokenNotFound", err)
+		}
+	})
+}
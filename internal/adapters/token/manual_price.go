@@ -0,0 +1,55 @@
+package token
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"testtask/internal/domain/price"
+	"testtask/internal/domain/token"
+)
+
+// ManualPriceProvider implements price.PriceProvider with the manual price feeds of custom tokens.
+// Tokens without a manual price in the requested currency are left out of the result.
+type ManualPriceProvider struct {
+	repo token.CustomRepository
+}
+
+func NewManualPriceProvider(repo token.CustomRepository) *ManualPriceProvider {
+	return &ManualPriceProvider{repo: repo}
+}
+
+func (p *ManualPriceProvider) GetPrices(ctx context.Context, tokens []*token.Token, currency string) (map[*token.Token]*price.Price, error) {
+	result := make(map[*token.Token]*price.Price)
+	if len(tokens) == 0 {
+		return result, nil
+	}
+
+	addresses := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		addresses = append(addresses, t.Address)
+	}
+
+	// Prices carry no portfolio, so the token of any portfolio visible to the caller is used
+	custom, err := p.repo.GetByAddresses(ctx, token.AnyPortfolio, addresses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load manual prices: %w", err)
+	}
+
+	for _, t := range tokens {
+		c, ok := custom[strings.ToLower(t.Address)]
+		if !ok || c.ManualPrice == nil || !strings.EqualFold(c.PriceCurrency, currency) {
+			continue
+		}
+		result[t] = &price.Price{
+			Token:       t,
+			Value:       new(big.Int).Set(c.ManualPrice),
+			Currency:    strings.ToUpper(currency),
+			LastUpdated: c.PriceUpdatedAt,
+			Source:      price.SourceManual,
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,311 @@
+package token
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testtask/internal/domain/token"
+	"time"
+)
+
+// SQLiteRepository implements token.Registry on top of the tokens table.
+// Addresses are stored lowercase.
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteRepository(db *sql.DB) *SQLiteRepository {
+	return &SQLiteRepository{db: db}
+}
+
+const tokenColumns = `address, coingecko_id, name, symbol, decimals`
+
+func (r *SQLiteRepository) GetList(ctx context.Context) ([]*token.Token, error) {
+	query := `SELECT ` + tokenColumns + ` FROM tokens ORDER BY symbol ASC, address ASC`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tokens: %w", err)
+	}
+	defer rows.Close()
+
+	return scanTokens(rows)
+}
+
+func (r *SQLiteRepository) GetByAddress(ctx context.Context, address string) (*token.Token, error) {
+	query := `SELECT ` + tokenColumns + ` FROM tokens WHERE address = ?`
+
+	t, err := scanToken(r.db.QueryRowContext(ctx, query, strings.ToLower(address)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: address=%s", token.ErrTokenNotFound, address)
+		}
+		return nil, fmt.Errorf("failed to get token by address: %w", err)
+	}
+
+	return t, nil
+}
+
+// GetByAddresses returns the registered tokens keyed by the requested address.
+// Lookup errors are treated as misses to match token.Repository semantics.
+func (r *SQLiteRepository) GetByAddresses(ctx context.Context, addresses []string) map[string]*token.Token {
+	result := make(map[string]*token.Token, len(addresses))
+	if len(addresses) == 0 {
+		return result
+	}
+
+	requested := make(map[string][]string, len(addresses))
+	args := make([]interface{}, 0, len(addresses))
+	for _, address := range addresses {
+		key := strings.ToLower(address)
+		if _, ok := requested[key]; !ok {
+			args = append(args, key)
+		}
+		requested[key] = append(requested[key], address)
+	}
+
+	query := `SELECT ` + tokenColumns + ` FROM tokens WHERE address IN (?` + strings.Repeat(",?", len(args)-1) + `)`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return result
+	}
+	defer rows.Close()
+
+	tokens, err := scanTokens(rows)
+	if err != nil {
+		return result
+	}
+
+	for _, t := range tokens {
+		for _, address := range requested[t.Address] {
+			result[address] = t
+		}
+	}
+
+	return result
+}
+
+func (r *SQLiteRepository) Register(ctx context.Context, tokens []*token.Token, source string) (int, error) {
+	if len(tokens) == 0 {
+		return 0, nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO tokens (address, coingecko_id, name, symbol, decimals, source, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(address) DO NOTHING
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare token insert: %w", err)
+	}
+	defer stmt.Close()
+
+	now := time.Now().Format(time.RFC3339)
+	inserted := 0
+	for _, t := range tokens {
+		if t == nil || t.Address == "" {
+			continue
+		}
+		res, err := stmt.ExecContext(ctx, strings.ToLower(t.Address), t.ID, t.Name, t.Symbol, t.Decimal, source, now, now)
+		if err != nil {
+			return 0, fmt.Errorf("failed to register token %s: %w", t.Address, err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		inserted += int(n)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit tokens: %w", err)
+	}
+
+	return inserted, nil
+}
+
+func (r *SQLiteRepository) ListUnresolved(ctx context.Context, refreshedBefore time.Time, limit int) ([]*token.Token, error) {
+	query := `
+		SELECT ` + tokenColumns + `
+		FROM tokens
+		WHERE (coingecko_id = '' OR name = '')
+			AND (refreshed_at IS NULL OR refreshed_at < ?)
+		ORDER BY created_at ASC
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, refreshedBefore.Format(time.RFC3339), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unresolved tokens: %w", err)
+	}
+	defer rows.Close()
+
+	return scanTokens(rows)
+}
+
+func (r *SQLiteRepository) UpdateMetadata(ctx context.Context, address string, metadata *token.Token) error {
+	now := time.Now().Format(time.RFC3339)
+
+	var (
+		res sql.Result
+		err error
+	)
+	if metadata == nil {
+		res, err = r.db.ExecContext(ctx, `UPDATE tokens SET refreshed_at = ? WHERE address = ?`, now, strings.ToLower(address))
+	} else {
+		// Only fill in what the provider knows; never blank out existing values
+		res, err = r.db.ExecContext(ctx, `
+			UPDATE tokens
+			SET coingecko_id = CASE WHEN ? != '' THEN ? ELSE coingecko_id END,
+				name = CASE WHEN ? != '' THEN ? ELSE name END,
+				updated_at = ?,
+				refreshed_at = ?
+			WHERE address = ?
+		`, metadata.ID, metadata.ID, metadata.Name, metadata.Name, now, now, strings.ToLower(address))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update token metadata: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: address=%s", token.ErrTokenNotFound, address)
+	}
+
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanToken(row rowScanner) (*token.Token, error) {
+	var t token.Token
+	if err := row.Scan(&t.Address, &t.ID, &t.Name, &t.Symbol, &t.Decimal); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func scanTokens(rows *sql.Rows) ([]*token.Token, error) {
+	var tokens []*token.Token
+	for rows.Next() {
+		t, err := scanToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan token: %w", err)
+		}
+		tokens = append(tokens, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+func (r *SQLiteRepository) Sync(ctx context.Context, tokens []*token.Token, source string) (token.SyncStats, error) {
+	var stats token.SyncStats
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return stats, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT `+tokenColumns+`, source FROM tokens`)
+	if err != nil {
+		return stats, fmt.Errorf("failed to load tokens: %w", err)
+	}
+	existing := make(map[string]*token.Token)
+	existingSource := make(map[string]string)
+	for rows.Next() {
+		var t token.Token
+		var src string
+		if err := rows.Scan(&t.Address, &t.ID, &t.Name, &t.Symbol, &t.Decimal, &src); err != nil {
+			rows.Close()
+			return stats, fmt.Errorf("failed to scan token: %w", err)
+		}
+		existing[t.Address] = &t
+		existingSource[t.Address] = src
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return stats, fmt.Errorf("error iterating tokens: %w", err)
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	synced := make(map[string]bool, len(tokens))
+	for _, t := range tokens {
+		if t == nil || t.Address == "" {
+			continue
+		}
+		address := strings.ToLower(t.Address)
+		if synced[address] {
+			continue
+		}
+		synced[address] = true
+
+		old, ok := existing[address]
+		if !ok {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO tokens (address, coingecko_id, name, symbol, decimals, source, created_at, updated_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			`, address, t.ID, t.Name, t.Symbol, t.Decimal, source, now, now); err != nil {
+				return stats, fmt.Errorf("failed to insert token %s: %w", address, err)
+			}
+			stats.Added++
+			continue
+		}
+
+		if existingSource[address] == source && !metadataChanged(old, t) {
+			stats.Unchanged++
+			continue
+		}
+
+		// Blank IDs and names in the list must not erase values backfilled by the refresh job
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE tokens
+			SET coingecko_id = CASE WHEN ? != '' THEN ? ELSE coingecko_id END,
+				name = CASE WHEN ? != '' THEN ? ELSE name END,
+				symbol = ?,
+				decimals = ?,
+				source = ?,
+				updated_at = ?
+			WHERE address = ?
+		`, t.ID, t.ID, t.Name, t.Name, t.Symbol, t.Decimal, source, now, address); err != nil {
+			return stats, fmt.Errorf("failed to update token %s: %w", address, err)
+		}
+		stats.Updated++
+	}
+
+	for address, src := range existingSource {
+		if src == source && !synced[address] {
+			stats.Stale++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return stats, fmt.Errorf("failed to commit tokens: %w", err)
+	}
+
+	return stats, nil
+}
+
+func metadataChanged(old, t *token.Token) bool {
+	return (t.ID != "" && t.ID != old.ID) ||
+		(t.Name != "" && t.Name != old.Name) ||
+		t.Symbol != old.Symbol ||
+		t.Decimal != old.Decimal
+}
@@ -0,0 +1,214 @@
+package token
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"testtask/internal/domain/token"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// setupTestDB creates an in-memory SQLite database with the tokens schema
+func setupTestDB(t *testing.T) (*SQLiteRepository, func()) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+
+	schema := `
+	CREATE TABLE IF NOT EXISTS tokens (
+		address TEXT PRIMARY KEY,
+		coingecko_id TEXT NOT NULL DEFAULT '',
+		name TEXT NOT NULL DEFAULT '',
+		symbol TEXT NOT NULL,
+		decimals INTEGER NOT NULL,
+		source TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		refreshed_at DATETIME
+	);
+	`
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	return NewSQLiteRepository(db), func() { db.Close() }
+}
+
+func TestSQLiteRepository_Register(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	usdc := &token.Token{ID: "usd-coin", Name: "USD Coin", Symbol: "USDC", Address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Decimal: 6}
+	added, err := repo.Register(ctx, []*token.Token{usdc}, token.SourceStatic)
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if added != 1 {
+		t.Errorf("Register() added = %d, want 1", added)
+	}
+
+	// Registering again must not overwrite the stored token
+	renamed := *usdc
+	renamed.Name = "Renamed"
+	added, err = repo.Register(ctx, []*token.Token{&renamed}, token.SourceDiscovered)
+	if err != nil {
+		t.Fatalf("Register() second call error = %v", err)
+	}
+	if added != 0 {
+		t.Errorf("Register() second call added = %d, want 0", added)
+	}
+
+	got, err := repo.GetByAddress(ctx, "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48")
+	if err != nil {
+		t.Fatalf("GetByAddress() error = %v", err)
+	}
+	if got.Name != "USD Coin" || got.Decimal != 6 || got.ID != "usd-coin" {
+		t.Errorf("GetByAddress() = %+v, want original USDC metadata", got)
+	}
+	if got.Address != "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48" {
+		t.Errorf("GetByAddress() Address = %s, want lowercase address", got.Address)
+	}
+}
+
+func TestSQLiteRepository_GetByAddress_NotFound(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	_, err := repo.GetByAddress(context.Background(), "0x0000000000000000000000000000000000000001")
+	if !errors.Is(err, token.ErrTokenNotFound) {
+		t.Errorf("GetByAddress() error = %v, want ErrTokenNotFound", err)
+	}
+}
+
+func TestSQLiteRepository_GetByAddresses(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	_, err := repo.Register(ctx, []*token.Token{
+		{Symbol: "AAA", Address: "0xaaa", Decimal: 18},
+		{Symbol: "BBB", Address: "0xbbb", Decimal: 8},
+	}, token.SourceDiscovered)
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	got := repo.GetByAddresses(ctx, []string{"0xAAA", "0xbbb", "0xccc"})
+	if len(got) != 2 {
+		t.Fatalf("GetByAddresses() returned %d tokens, want 2", len(got))
+	}
+	if got["0xAAA"] == nil || got["0xAAA"].Symbol != "AAA" {
+		t.Errorf("GetByAddresses() should key results by the requested address")
+	}
+	if _, ok := got["0xccc"]; ok {
+		t.Errorf("GetByAddresses() returned unknown token")
+	}
+}
+
+func TestSQLiteRepository_UnresolvedLifecycle(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	_, err := repo.Register(ctx, []*token.Token{
+		{ID: "known", Name: "Known", Symbol: "KNW", Address: "0x01", Decimal: 18},
+		{Symbol: "NEW", Address: "0x02", Decimal: 18},
+		{Symbol: "GONE", Address: "0x03", Decimal: 18},
+	}, token.SourceDiscovered)
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	future := time.Now().Add(time.Hour)
+	unresolved, err := repo.ListUnresolved(ctx, future, 10)
+	if err != nil {
+		t.Fatalf("ListUnresolved() error = %v", err)
+	}
+	if len(unresolved) != 2 {
+		t.Fatalf("ListUnresolved() returned %d tokens, want 2", len(unresolved))
+	}
+
+	if err := repo.UpdateMetadata(ctx, "0x02", &token.Token{ID: "new-token", Name: "New Token"}); err != nil {
+		t.Fatalf("UpdateMetadata() error = %v", err)
+	}
+	if err := repo.UpdateMetadata(ctx, "0x03", nil); err != nil {
+		t.Fatalf("UpdateMetadata(nil) error = %v", err)
+	}
+
+	got, err := repo.GetByAddress(ctx, "0x02")
+	if err != nil {
+		t.Fatalf("GetByAddress() error = %v", err)
+	}
+	if got.ID != "new-token" || got.Name != "New Token" || got.Symbol != "NEW" {
+		t.Errorf("GetByAddress() = %+v, want backfilled metadata with original symbol", got)
+	}
+
+	// 0x03 was marked refreshed, so it is only retried after the cutoff
+	unresolved, err = repo.ListUnresolved(ctx, time.Now().Add(-time.Hour), 10)
+	if err != nil {
+		t.Fatalf("ListUnresolved() error = %v", err)
+	}
+	if len(unresolved) != 0 {
+		t.Errorf("ListUnresolved() returned %d tokens, want 0", len(unresolved))
+	}
+
+	if err := repo.UpdateMetadata(ctx, "0x04", nil); !errors.Is(err, token.ErrTokenNotFound) {
+		t.Errorf("UpdateMetadata() unknown token error = %v, want ErrTokenNotFound", err)
+	}
+}
+
+func TestSQLiteRepository_Sync(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	if _, err := repo.Register(ctx, []*token.Token{
+		{ID: "keep", Name: "Keep", Symbol: "KEEP", Address: "0x01", Decimal: 18},
+		{ID: "old", Name: "Old", Symbol: "OLD", Address: "0x02", Decimal: 18},
+	}, token.SourceStatic); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if _, err := repo.Register(ctx, []*token.Token{
+		{Symbol: "DISC", Address: "0x03", Decimal: 6},
+	}, token.SourceDiscovered); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := repo.UpdateMetadata(ctx, "0x03", &token.Token{ID: "backfilled"}); err != nil {
+		t.Fatalf("UpdateMetadata() error = %v", err)
+	}
+
+	stats, err := repo.Sync(ctx, []*token.Token{
+		{ID: "keep", Name: "Keep", Symbol: "KEEP", Address: "0x01", Decimal: 18},
+		{Name: "Discovered", Symbol: "DISC", Address: "0x03", Decimal: 6},
+		{ID: "new", Name: "New", Symbol: "NEW", Address: "0x04", Decimal: 8},
+	}, token.SourceStatic)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	want := token.SyncStats{Added: 1, Updated: 1, Unchanged: 1, Stale: 1}
+	if stats != want {
+		t.Errorf("Sync() stats = %+v, want %+v", stats, want)
+	}
+
+	got, err := repo.GetByAddress(ctx, "0x03")
+	if err != nil {
+		t.Fatalf("GetByAddress() error = %v", err)
+	}
+	if got.ID != "backfilled" || got.Name != "Discovered" {
+		t.Errorf("GetByAddress() = %+v, want backfilled ID kept and name updated", got)
+	}
+
+	// Stale tokens stay registered
+	if _, err := repo.GetByAddress(ctx, "0x02"); err != nil {
+		t.Errorf("GetByAddress() stale token error = %v", err)
+	}
+}
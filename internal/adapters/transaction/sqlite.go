@@ -0,0 +1,194 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	sqliteadapter "testtask/internal/adapters/sqlite"
+	"testtask/internal/domain/transaction"
+)
+
+// SQLiteRepository implements transaction.AnnotationRepository and transaction.ManualRepository
+// on top of the transaction_annotations and manual_transactions tables
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteRepository(db *sql.DB) *SQLiteRepository {
+	return &SQLiteRepository{db: db}
+}
+
+// ListAnnotations returns the portfolio's annotations by transaction ID
+func (r *SQLiteRepository) ListAnnotations(ctx context.Context, portfolioID string) ([]*transaction.Annotation, error) {
+	scope, scopeArgs := sqliteadapter.PortfolioScope(ctx, "portfolio_id")
+	query := `
+		SELECT portfolio_id, transaction_id, note, tags, category, type_override, updated_at
+		FROM transaction_annotations
+		WHERE portfolio_id = ?` + scope + `
+		ORDER BY transaction_id ASC
+	`
+	rows, err := r.db.QueryContext(ctx, query, append([]interface{}{portfolioID}, scopeArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transaction annotations: %w", err)
+	}
+	defer rows.Close()
+
+	annotations := make([]*transaction.Annotation, 0)
+	for rows.Next() {
+		var (
+			a                             transaction.Annotation
+			tags, typeOverride, updatedAt string
+		)
+		if err := rows.Scan(&a.PortfolioID, &a.TransactionID, &a.Note, &tags, &a.Category, &typeOverride, &updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan transaction annotation: %w", err)
+		}
+		if err := json.Unmarshal([]byte(tags), &a.Tags); err != nil {
+			return nil, fmt.Errorf("invalid tags of transaction annotation %s: %w", a.TransactionID, err)
+		}
+		a.TypeOverride = transaction.TransactionType(typeOverride)
+		if a.UpdatedAt, err = time.Parse(time.RFC3339Nano, updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to parse transaction annotation time: %w", err)
+		}
+		annotations = append(annotations, &a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list transaction annotations: %w", err)
+	}
+	return annotations, nil
+}
+
+func (r *SQLiteRepository) SetAnnotation(ctx context.Context, a *transaction.Annotation) error {
+	tags := a.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	encoded, err := json.Marshal(tags)
+	if err != nil {
+		return fmt.Errorf("failed to encode annotation tags: %w", err)
+	}
+
+	query := `
+		INSERT INTO transaction_annotations (portfolio_id, transaction_id, note, tags, category, type_override, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (portfolio_id, transaction_id) DO UPDATE SET
+			note = excluded.note, tags = excluded.tags, category = excluded.category,
+			type_override = excluded.type_override, updated_at = excluded.updated_at
+	`
+	_, err = r.db.ExecContext(ctx, query, a.PortfolioID, a.TransactionID, a.Note, string(encoded), a.Category, string(a.TypeOverride), a.UpdatedAt.UTC().Format(time.RFC3339Nano))
+	if err != nil {
+		return fmt.Errorf("failed to set transaction annotation: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLiteRepository) DeleteAnnotation(ctx context.Context, portfolioID, transactionID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM transaction_annotations WHERE portfolio_id = ? AND transaction_id = ?`, portfolioID, transactionID)
+	if err != nil {
+		return fmt.Errorf("failed to delete transaction annotation: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete transaction annotation: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w: transaction_id=%s", transaction.ErrAnnotationNotFound, transactionID)
+	}
+	return nil
+}
+
+// ListManualTransactions returns the portfolio's manual transactions, oldest first
+func (r *SQLiteRepository) ListManualTransactions(ctx context.Context, portfolioID string) ([]*transaction.Transaction, error) {
+	scope, scopeArgs := sqliteadapter.PortfolioScope(ctx, "portfolio_id")
+	query := `
+		SELECT id, token_address, token_symbol, token_decimal, amount, type, direction,
+			from_address, to_address, price, price_currency, timestamp
+		FROM manual_transactions
+		WHERE portfolio_id = ?` + scope + `
+		ORDER BY julianday(timestamp) ASC, id ASC
+	`
+	rows, err := r.db.QueryContext(ctx, query, append([]interface{}{portfolioID}, scopeArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list manual transactions: %w", err)
+	}
+	defer rows.Close()
+
+	txs := make([]*transaction.Transaction, 0)
+	for rows.Next() {
+		var (
+			tx                                   transaction.Transaction
+			decimals                             uint8
+			amount, txType, direction, price, at string
+		)
+		if err := rows.Scan(&tx.ID, &tx.TokenAddress, &tx.TokenSymbol, &decimals, &amount, &txType, &direction,
+			&tx.From, &tx.To, &price, &tx.PriceCurrency, &at); err != nil {
+			return nil, fmt.Errorf("failed to scan manual transaction: %w", err)
+		}
+		tx.Hash = tx.ID
+		tx.TokenDecimal = &decimals
+		tx.Type = transaction.TransactionType(txType)
+		tx.Direction = transaction.TransactionDirection(direction)
+		tx.Status = transaction.TransactionStatusSuccess
+		tx.Source = transaction.SourceManual
+
+		var ok bool
+		if tx.Amount, ok = new(big.Int).SetString(amount, 10); !ok {
+			return nil, fmt.Errorf("invalid amount %q of manual transaction %s", amount, tx.ID)
+		}
+		if price != "" {
+			if tx.Price, ok = new(big.Int).SetString(price, 10); !ok {
+				return nil, fmt.Errorf("invalid price %q of manual transaction %s", price, tx.ID)
+			}
+		}
+		if tx.Timestamp, err = time.Parse(time.RFC3339Nano, at); err != nil {
+			return nil, fmt.Errorf("failed to parse manual transaction time: %w", err)
+		}
+		txs = append(txs, &tx)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list manual transactions: %w", err)
+	}
+	return txs, nil
+}
+
+func (r *SQLiteRepository) CreateManualTransaction(ctx context.Context, portfolioID string, tx *transaction.Transaction) error {
+	var decimals uint8
+	if tx.TokenDecimal != nil {
+		decimals = *tx.TokenDecimal
+	}
+	var price string
+	if tx.Price != nil {
+		price = tx.Price.String()
+	}
+
+	query := `
+		INSERT INTO manual_transactions (id, portfolio_id, token_address, token_symbol, token_decimal, amount, type, direction,
+			from_address, to_address, price, price_currency, timestamp, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.ExecContext(ctx, query, tx.ID, portfolioID, tx.TokenAddress, tx.TokenSymbol, decimals, tx.Amount.String(),
+		string(tx.Type), string(tx.Direction), tx.From, tx.To, price, tx.PriceCurrency,
+		tx.Timestamp.UTC().Format(time.RFC3339Nano), time.Now().UTC().Format(time.RFC3339Nano))
+	if err != nil {
+		return fmt.Errorf("failed to create manual transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLiteRepository) DeleteManualTransaction(ctx context.Context, portfolioID, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM manual_transactions WHERE portfolio_id = ? AND id = ?`, portfolioID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete manual transaction: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete manual transaction: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w: id=%s", transaction.ErrManualTransactionNotFound, id)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"testtask/internal/domain/transaction"
+	"testtask/internal/domain/user"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// setupTestDB creates an in-memory SQLite database with the annotation schema
+func setupTestDB(t *testing.T) (*SQLiteRepository, func()) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	// Every connection to :memory: is a separate database
+	db.SetMaxOpenConns(1)
+
+	schema := `
+	CREATE TABLE IF NOT EXISTS transaction_annotations (
+		portfolio_id TEXT NOT NULL,
+		transaction_id TEXT NOT NULL,
+		note TEXT NOT NULL DEFAULT '',
+		tags TEXT NOT NULL DEFAULT '[]',
+		category TEXT NOT NULL DEFAULT '',
+		type_override TEXT NOT NULL DEFAULT '',
+		updated_at DATETIME NOT NULL,
+		PRIMARY KEY (portfolio_id, transaction_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS manual_transactions (
+		id TEXT PRIMARY KEY,
+		portfolio_id TEXT NOT NULL,
+		token_address TEXT NOT NULL,
+		token_symbol TEXT NOT NULL DEFAULT '',
+		token_decimal INTEGER NOT NULL,
+		amount TEXT NOT NULL,
+		type TEXT NOT NULL,
+		direction TEXT NOT NULL,
+		from_address TEXT NOT NULL DEFAULT '',
+		to_address TEXT NOT NULL DEFAULT '',
+		price TEXT NOT NULL DEFAULT '',
+		price_currency TEXT NOT NULL DEFAULT '',
+		timestamp DATETIME NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+	`
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	return NewSQLiteRepository(db), func() { db.Close() }
+}
+
+func TestSQLiteRepository_Annotations(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := user.AsSystem(context.Background())
+
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, a := range []*transaction.Annotation{
+		{PortfolioID: "p1", TransactionID: "0xabc", Note: "rent", UpdatedAt: at},
+		{PortfolioID: "p1", TransactionID: "0xabc", Tags: []string{"business", "q2"}, Category: "rent", TypeOverride: transaction.TransactionTypeSwap, UpdatedAt: at.Add(time.Hour)},
+		{PortfolioID: "p2", TransactionID: "0xdef", Note: "gift", UpdatedAt: at},
+	} {
+		if err := repo.SetAnnotation(ctx, a); err != nil {
+			t.Fatalf("SetAnnotation() error = %v", err)
+		}
+	}
+
+	got, err := repo.ListAnnotations(ctx, "p1")
+	if err != nil {
+		t.Fatalf("ListAnnotations() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Note != "" || len(got[0].Tags) != 2 || got[0].Tags[1] != "q2" ||
+		got[0].TypeOverride != transaction.TransactionTypeSwap || !got[0].UpdatedAt.Equal(at.Add(time.Hour)) {
+		t.Fatalf("ListAnnotations() = %+v, want the replaced annotation", got)
+	}
+	if got, _ := repo.ListAnnotations(ctx, "p2"); len(got) != 1 || got[0].Tags == nil {
+		t.Errorf("ListAnnotations(p2) = %+v, want one annotation with empty tags", got)
+	}
+
+	if err := repo.DeleteAnnotation(ctx, "p1", "0xabc"); err != nil {
+		t.Fatalf("DeleteAnnotation() error = %v", err)
+	}
+	if err := repo.DeleteAnnotation(ctx, "p1", "0xdef"); !errors.Is(err, transaction.ErrAnnotationNotFound) {
+		t.Errorf("DeleteAnnotation(other portfolio) error = %v, want ErrAnnotationNotFound", err)
+	}
+}
+
+func TestSQLiteRepository_ManualTransactions(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := user.AsSystem(context.Background())
+
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	amount, _ := new(big.Int).SetString("1500000000000000000", 10)
+	later, err := transaction.NewManualTransaction("0x0000000000000000000000000000000000000000", "ETH", 18, amount, transaction.TransactionDirectionIn, "", at.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("NewManualTransaction() error = %v", err)
+	}
+	later.From, later.Price, later.PriceCurrency = "0xseller", big.NewInt(300000), "USD"
+	earlier, _ := transaction.NewManualTransaction("0xtoken", "TKN", 6, big.NewInt(5), transaction.TransactionDirectionOut, "", at)
+	other, _ := transaction.NewManualTransaction("0xtoken", "TKN", 6, big.NewInt(5), transaction.TransactionDirectionOut, "", at)
+
+	for _, tc := range []struct {
+		portfolioID string
+		tx          *transaction.Transaction
+	}{{"p1", later}, {"p1", earlier}, {"p2", other}} {
+		if err := repo.CreateManualTransaction(ctx, tc.portfolioID, tc.tx); err != nil {
+			t.Fatalf("CreateManualTransaction() error = %v", err)
+		}
+	}
+
+	got, err := repo.ListManualTransactions(ctx, "p1")
+	if err != nil {
+		t.Fatalf("ListManualTransactions() error = %v", err)
+	}
+	if len(got) != 2 || got[0].ID != earlier.ID || got[0].Price != nil {
+		t.Fatalf("ListManualTransactions() = %+v, want earlier then later of p1", got)
+	}
+	tx := got[1]
+	if tx.Amount.Cmp(amount) != 0 || *tx.TokenDecimal != 18 || tx.Type != transaction.TransactionTypeReceive ||
+		tx.From != "0xseller" || tx.Price.Int64() != 300000 || tx.Source != transaction.SourceManual || !tx.Timestamp.Equal(at.Add(time.Hour)) {
+		t.Errorf("ListManualTransactions()[1] = %+v, want the stored purchase", tx)
+	}
+
+	if err := repo.DeleteManualTransaction(ctx, "p1", earlier.ID); err != nil {
+		t.Fatalf("DeleteManualTransaction() error = %v", err)
+	}
+	if err := repo.DeleteManualTransaction(ctx, "p1", other.ID); !errors.Is(err, transaction.ErrManualTransactionNotFound) {
+		t.Errorf("DeleteManualTransaction(other portfolio) error = %v, want ErrManualTransactionNotFound", err)
+	}
+}
@@ -0,0 +1,198 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	sqliteadapter "testtask/internal/adapters/sqlite"
+	"testtask/internal/domain/user"
+)
+
+// SQLiteRepository implements user.Repository on top of the users and api_keys tables
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteRepository(db *sql.DB) *SQLiteRepository {
+	return &SQLiteRepository{db: db}
+}
+
+const userColumns = `id, email, name, created_at`
+
+const apiKeyColumns = `id, user_id, name, prefix, key_hash, created_at, last_used_at`
+
+func (r *SQLiteRepository) Create(ctx context.Context, u *user.User) error {
+	query := `INSERT INTO users (` + userColumns + `) VALUES (?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, query, u.ID, u.Email, u.Name, u.CreatedAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		if sqliteadapter.IsUniqueViolation(err) {
+			return fmt.Errorf("%w: email=%s", user.ErrUserExists, u.Email)
+		}
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SQLiteRepository) GetByID(ctx context.Context, id string) (*user.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = ?`
+
+	u, err := scanUser(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: user_id=%s", user.ErrUserNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return u, nil
+}
+
+func (r *SQLiteRepository) GetByEmail(ctx context.Context, email string) (*user.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = ?`
+
+	u, err := scanUser(r.db.QueryRowContext(ctx, query, email))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: email=%s", user.ErrUserNotFound, email)
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return u, nil
+}
+
+func (r *SQLiteRepository) CreateAPIKey(ctx context.Context, key *user.APIKey) error {
+	query := `INSERT INTO api_keys (` + apiKeyColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		key.ID, key.UserID, key.Name, key.Prefix, key.Hash,
+		key.CreatedAt.UTC().Format(time.RFC3339), nil,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SQLiteRepository) GetByAPIKeyHash(ctx context.Context, hash string) (*user.APIKey, *user.User, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = ?`
+
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, hash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, user.ErrAPIKeyNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	u, err := r.GetByID(ctx, key.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return key, u, nil
+}
+
+func (r *SQLiteRepository) ListAPIKeys(ctx context.Context, userID string) ([]*user.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE user_id = ? ORDER BY created_at ASC, id ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make([]*user.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating api keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+func (r *SQLiteRepository) DeleteAPIKey(ctx context.Context, userID, keyID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM api_keys WHERE id = ? AND user_id = ?`, keyID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete api key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: key_id=%s", user.ErrAPIKeyNotFound, keyID)
+	}
+
+	return nil
+}
+
+func (r *SQLiteRepository) TouchAPIKey(ctx context.Context, keyID string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = ? WHERE id = ?`, at.UTC().Format(time.RFC3339), keyID)
+	if err != nil {
+		return fmt.Errorf("failed to update api key usage: %w", err)
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanUser(row rowScanner) (*user.User, error) {
+	var (
+		u            user.User
+		createdAtStr string
+	)
+	if err := row.Scan(&u.ID, &u.Email, &u.Name, &createdAtStr); err != nil {
+		return nil, err
+	}
+
+	createdAt, err := time.Parse(time.RFC3339, createdAtStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse created_at: %w", err)
+	}
+	u.CreatedAt = createdAt
+
+	return &u, nil
+}
+
+func scanAPIKey(row rowScanner) (*user.APIKey, error) {
+	var (
+		key          user.APIKey
+		createdAtStr string
+		lastUsedStr  sql.NullString
+	)
+	if err := row.Scan(&key.ID, &key.UserID, &key.Name, &key.Prefix, &key.Hash, &createdAtStr, &lastUsedStr); err != nil {
+		return nil, err
+	}
+
+	createdAt, err := time.Parse(time.RFC3339, createdAtStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse created_at: %w", err)
+	}
+	key.CreatedAt = createdAt
+
+	if lastUsedStr.Valid {
+		lastUsed, err := time.Parse(time.RFC3339, lastUsedStr.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse last_used_at: %w", err)
+		}
+		key.LastUsedAt = &lastUsed
+	}
+
+	return &key, nil
+}
@@ -0,0 +1,128 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"testtask/internal/domain/user"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// setupTestDB creates an in-memory SQLite database with the users schema
+func setupTestDB(t *testing.T) (*SQLiteRepository, func()) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+
+	schema := `
+	CREATE TABLE IF NOT EXISTS users (
+		id TEXT PRIMARY KEY,
+		email TEXT NOT NULL UNIQUE,
+		name TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS api_keys (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		name TEXT NOT NULL DEFAULT '',
+		prefix TEXT NOT NULL,
+		key_hash TEXT NOT NULL UNIQUE,
+		created_at DATETIME NOT NULL,
+		last_used_at DATETIME
+	);
+	`
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	return NewSQLiteRepository(db), func() { db.Close() }
+}
+
+func TestSQLiteRepository_Users(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	u, err := user.NewUser(" Alice@Example.com ", "Alice")
+	if err != nil {
+		t.Fatalf("NewUser() error = %v", err)
+	}
+	if err := repo.Create(ctx, u); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	duplicate, _ := user.NewUser("alice@example.com", "Other")
+	if err := repo.Create(ctx, duplicate); !errors.Is(err, user.ErrUserExists) {
+		t.Errorf("Create() duplicate error = %v, want %v", err, user.ErrUserExists)
+	}
+
+	got, err := repo.GetByEmail(ctx, "alice@example.com")
+	if err != nil {
+		t.Fatalf("GetByEmail() error = %v", err)
+	}
+	if got.ID != u.ID || got.Name != "Alice" {
+		t.Errorf("GetByEmail() = %+v, want %+v", got, u)
+	}
+
+	if _, err := repo.GetByID(ctx, "missing"); !errors.Is(err, user.ErrUserNotFound) {
+		t.Errorf("GetByID() error = %v, want %v", err, user.ErrUserNotFound)
+	}
+}
+
+func TestSQLiteRepository_APIKeys(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	u, _ := user.NewUser("bob@example.com", "")
+	if err := repo.Create(ctx, u); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	key := &user.APIKey{ID: "key-1", UserID: u.ID, Name: "ci", Prefix: "cpt_abcd", Hash: "hash-1", CreatedAt: time.Now()}
+	if err := repo.CreateAPIKey(ctx, key); err != nil {
+		t.Fatalf("CreateAPIKey() error = %v", err)
+	}
+
+	gotKey, gotUser, err := repo.GetByAPIKeyHash(ctx, "hash-1")
+	if err != nil {
+		t.Fatalf("GetByAPIKeyHash() error = %v", err)
+	}
+	if gotKey.ID != "key-1" || gotUser.ID != u.ID {
+		t.Errorf("GetByAPIKeyHash() = %s/%s, want key-1/%s", gotKey.ID, gotUser.ID, u.ID)
+	}
+	if gotKey.LastUsedAt != nil {
+		t.Errorf("LastUsedAt = %v, want nil", gotKey.LastUsedAt)
+	}
+
+	if err := repo.TouchAPIKey(ctx, "key-1", time.Now()); err != nil {
+		t.Fatalf("TouchAPIKey() error = %v", err)
+	}
+	keys, err := repo.ListAPIKeys(ctx, u.ID)
+	if err != nil {
+		t.Fatalf("ListAPIKeys() error = %v", err)
+	}
+	if len(keys) != 1 || keys[0].LastUsedAt == nil {
+		t.Fatalf("ListAPIKeys() = %+v, want one used key", keys)
+	}
+
+	if err := repo.DeleteAPIKey(ctx, "other-user", "key-1"); !errors.Is(err, user.ErrAPIKeyNotFound) {
+		t.Errorf("DeleteAPIKey() by other user error = %v, want %v", err, user.ErrAPIKeyNotFound)
+	}
+	if err := repo.DeleteAPIKey(ctx, u.ID, "key-1"); err != nil {
+		t.Fatalf("DeleteAPIKey() error = %v", err)
+	}
+	if _, _, err := repo.GetByAPIKeyHash(ctx, "hash-1"); !errors.Is(err, user.ErrAPIKeyNotFound) {
+		t.Errorf("GetByAPIKeyHash() after delete error = %v, want %v", err, user.ErrAPIKeyNotFound)
+	}
+}
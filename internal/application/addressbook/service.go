@@ -0,0 +1,137 @@
+package addressbook
+
+import (
+	"context"
+	"time"
+
+	loggeradapter "testtask/internal/adapters/logger"
+	"testtask/internal/domain/addressbook"
+	"testtask/internal/domain/audit"
+	"testtask/internal/domain/transaction"
+	"testtask/internal/domain/user"
+
+	"go.uber.org/zap"
+)
+
+// Service manages the address book of each user and labels transaction counterparties
+// from it and from the registry of well-known addresses
+type Service struct {
+	registry *addressbook.Registry
+	repo     addressbook.Repository
+	recorder audit.Recorder
+	logger   *loggeradapter.Logger
+	now      func() time.Time
+}
+
+// NewService creates the address book service. A nil registry labels from the users'
+// entries only.
+func NewService(registry *addressbook.Registry, repo addressbook.Repository, recorder audit.Recorder, logger *loggeradapter.Logger) *Service {
+	if logger == nil {
+		logger = loggeradapter.NewNopLogger()
+	}
+	if recorder == nil {
+		recorder = audit.NopRecorder{}
+	}
+	return &Service{
+		registry: registry,
+		repo:     repo,
+		recorder: recorder,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// KnownAddresses returns the well-known addresses of the category, all when it is empty
+func (s *Service) KnownAddresses(category addressbook.Category) []*addressbook.KnownAddress {
+	return s.registry.List(category)
+}
+
+func (s *Service) ListEntries(ctx context.Context, userID string) ([]*addressbook.Entry, error) {
+	return s.repo.List(ctx, userID)
+}
+
+// CreateEntry labels an address for the user; each address is labeled once
+func (s *Service) CreateEntry(ctx context.Context, userID, address, label, note string) (*addressbook.Entry, error) {
+	e, err := addressbook.NewEntry(userID, address, label, note)
+	if err != nil {
+		return nil, err
+	}
+	e.CreatedAt = s.now().UTC()
+	e.UpdatedAt = e.CreatedAt
+	if err := s.repo.Create(ctx, e); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Created address book entry", zap.String("user_id", userID), zap.String("entry_id", e.ID))
+	s.recorder.Record(ctx, audit.NewEntry(audit.ActionAddressCreate, audit.ResourceAddressBook, e.ID, ""), nil, snapshotEntry(e))
+	return e, nil
+}
+
+// UpdateEntry replaces the address, label and note of an entry of the user
+func (s *Service) UpdateEntry(ctx context.Context, userID, id, address, label, note string) (*addressbook.Entry, error) {
+	before, err := s.repo.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	e := &addressbook.Entry{
+		ID:        before.ID,
+		UserID:    before.UserID,
+		Address:   address,
+		Label:     label,
+		Note:      note,
+		CreatedAt: before.CreatedAt,
+		UpdatedAt: s.now().UTC(),
+	}
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, e); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Updated address book entry", zap.String("user_id", userID), zap.String("entry_id", e.ID))
+	s.recorder.Record(ctx, audit.NewEntry(audit.ActionAddressUpdate, audit.ResourceAddressBook, e.ID, ""), snapshotEntry(before), snapshotEntry(e))
+	return e, nil
+}
+
+func (s *Service) DeleteEntry(ctx context.Context, userID, id string) error {
+	before, err := s.repo.Get(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, userID, id); err != nil {
+		return err
+	}
+
+	s.logger.Info("Deleted address book entry", zap.String("user_id", userID), zap.String("entry_id", id))
+	s.recorder.Record(ctx, audit.NewEntry(audit.ActionAddressDelete, audit.ResourceAddressBook, id, ""), snapshotEntry(before), nil)
+	return nil
+}
+
+// Label sets FromLabel and ToLabel on the transactions from the address book of the
+// caller in ctx and the registry; without a caller only the registry applies
+func (s *Service) Label(ctx context.Context, txs []*transaction.Transaction) error {
+	var entries []*addressbook.Entry
+	if caller, ok := user.FromContext(ctx); ok {
+		var err error
+		if entries, err = s.repo.List(ctx, caller.ID); err != nil {
+			return err
+		}
+	}
+	addressbook.Apply(txs, s.registry, entries)
+	return nil
+}
+
+// entrySnapshot is the audit log representation of an address book entry
+type entrySnapshot struct {
+	Address string `json:"address"`
+	Label   string `json:"label"`
+	Note    string `json:"note,omitempty"`
+}
+
+func snapshotEntry(e *addressbook.Entry) *entrySnapshot {
+	if e == nil {
+		return nil
+	}
+	return &entrySnapshot{Address: e.Address, Label: e.Label, Note: e.Note}
+}
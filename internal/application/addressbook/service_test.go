@@ -0,0 +1,135 @@
+package addressbook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"testtask/internal/domain/addressbook"
+	"testtask/internal/domain/transaction"
+	"testtask/internal/domain/user"
+)
+
+const (
+	routerAddress = "0x7a250d5630b4cf539739df2c5dacb4c659f2488d"
+	friendAddress = "0x1111111111111111111111111111111111111111"
+)
+
+// mockRepository keeps entries in memory
+type mockRepository struct {
+	entries map[string]*addressbook.Entry
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{entries: make(map[string]*addressbook.Entry)}
+}
+
+func (m *mockRepository) List(_ context.Context, userID string) ([]*addressbook.Entry, error) {
+	var entries []*addressbook.Entry
+	for _, e := range m.entries {
+		if e.UserID == userID {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+func (m *mockRepository) Get(_ context.Context, userID, id string) (*addressbook.Entry, error) {
+	e, ok := m.entries[id]
+	if !ok || e.UserID != userID {
+		return nil, fmt.Errorf("%w: id=%s", addressbook.ErrEntryNotFound, id)
+	}
+	copied := *e
+	return &copied, nil
+}
+
+func (m *mockRepository) Create(_ context.Context, e *addressbook.Entry) error {
+	for _, existing := range m.entries {
+		if existing.UserID == e.UserID && existing.Address == e.Address {
+			return addressbook.ErrEntryExists
+		}
+	}
+	m.entries[e.ID] = e
+	return nil
+}
+
+func (m *mockRepository) Update(_ context.Context, e *addressbook.Entry) error {
+	m.entries[e.ID] = e
+	return nil
+}
+
+func (m *mockRepository) Delete(_ context.Context, _, id string) error {
+	delete(m.entries, id)
+	return nil
+}
+
+func newTestService(t *testing.T) (*Service, *mockRepository) {
+	t.Helper()
+	registry, err := addressbook.NewRegistry([]*addressbook.KnownAddress{
+		{Address: routerAddress, Label: "Uniswap V2: Router 2", Category: addressbook.CategoryDEX},
+	})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+	repo := newMockRepository()
+	return NewService(registry, repo, nil, nil), repo
+}
+
+func TestService_Label(t *testing.T) {
+	svc, _ := newTestService(t)
+	alice := &user.User{ID: "user-1"}
+	ctx := user.WithUser(context.Background(), alice)
+
+	if _, err := svc.CreateEntry(ctx, alice.ID, friendAddress, "Friend", ""); err != nil {
+		t.Fatalf("CreateEntry() error = %v", err)
+	}
+	if _, err := svc.CreateEntry(ctx, "user-2", routerAddress, "Not Alice's label", ""); err != nil {
+		t.Fatalf("CreateEntry() error = %v", err)
+	}
+
+	tests := []struct {
+		name             string
+		ctx              context.Context
+		wantFrom, wantTo string
+	}{
+		{"caller", ctx, "Friend", "Uniswap V2: Router 2"},
+		{"no caller", context.Background(), "", "Uniswap V2: Router 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &transaction.Transaction{From: friendAddress, To: routerAddress}
+			if err := svc.Label(tt.ctx, []*transaction.Transaction{tx}); err != nil {
+				t.Fatalf("Label() error = %v", err)
+			}
+			if tx.FromLabel != tt.wantFrom || tx.ToLabel != tt.wantTo {
+				t.Errorf("labels = %q, %q, want %q, %q", tx.FromLabel, tx.ToLabel, tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+}
+
+func TestService_UpdateEntry(t *testing.T) {
+	svc, _ := newTestService(t)
+	ctx := context.Background()
+
+	e, err := svc.CreateEntry(ctx, "user-1", friendAddress, "Friend", "")
+	if err != nil {
+		t.Fatalf("CreateEntry() error = %v", err)
+	}
+
+	if _, err := svc.UpdateEntry(ctx, "user-1", e.ID, friendAddress, " ", ""); !errors.Is(err, addressbook.ErrInvalidEntry) {
+		t.Errorf("UpdateEntry(empty label) error = %v, want ErrInvalidEntry", err)
+	}
+	if _, err := svc.UpdateEntry(ctx, "user-2", e.ID, friendAddress, "Mine", ""); !errors.Is(err, addressbook.ErrEntryNotFound) {
+		t.Errorf("UpdateEntry(other user) error = %v, want ErrEntryNotFound", err)
+	}
+
+	updated, err := svc.UpdateEntry(ctx, "user-1", e.ID, friendAddress, "Best friend", "met at a conference")
+	if err != nil {
+		t.Fatalf("UpdateEntry() error = %v", err)
+	}
+	if updated.Label != "Best friend" || !updated.CreatedAt.Equal(e.CreatedAt) {
+		t.Errorf("UpdateEntry() = %+v, want the new label and the original creation time", updated)
+	}
+}
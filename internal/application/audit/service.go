@@ -0,0 +1,85 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	loggeradapter "testtask/internal/adapters/logger"
+	"testtask/internal/domain/audit"
+	"testtask/internal/domain/user"
+
+	"go.uber.org/zap"
+)
+
+// Service records state changes in the audit log and queries it. It implements
+// audit.Recorder for the other application services.
+type Service struct {
+	repo   audit.Repository
+	logger *loggeradapter.Logger
+	now    func() time.Time
+}
+
+func NewService(repo audit.Repository, logger *loggeradapter.Logger) *Service {
+	if logger == nil {
+		logger = loggeradapter.NewNopLogger()
+	}
+	return &Service{
+		repo:   repo,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Record stamps the entry with the caller, request ID and time and appends it with
+// JSON snapshots of before and after. Recording is best effort: the change has already
+// been committed, so failures are logged rather than returned.
+func (s *Service) Record(ctx context.Context, e *audit.Entry, before, after any) {
+	e.ActorID = audit.SystemActor
+	if caller, ok := user.FromContext(ctx); ok {
+		e.ActorID = caller.ID
+	}
+	e.RequestID = audit.RequestIDFromContext(ctx)
+	e.CreatedAt = s.now().UTC()
+
+	var err error
+	if e.Before, err = snapshot(before); err != nil {
+		s.logger.Error("Failed to encode audit snapshot", zap.String("action", e.Action), zap.String("resource_id", e.ResourceID), zap.Error(err))
+	}
+	if e.After, err = snapshot(after); err != nil {
+		s.logger.Error("Failed to encode audit snapshot", zap.String("action", e.Action), zap.String("resource_id", e.ResourceID), zap.Error(err))
+	}
+
+	// The request may be cancelled once the change is committed; the entry must still be written
+	if err := s.repo.Append(context.WithoutCancel(ctx), e); err != nil {
+		s.logger.Error("Failed to record audit entry",
+			zap.String("action", e.Action),
+			zap.String("resource_type", e.ResourceType),
+			zap.String("resource_id", e.ResourceID),
+			zap.String("portfolio_id", e.PortfolioID),
+			zap.String("actor_id", e.ActorID),
+			zap.String("request_id", e.RequestID),
+			zap.Error(err),
+		)
+	}
+}
+
+// List returns entries matching the filter, newest first
+func (s *Service) List(ctx context.Context, filter audit.Filter) ([]*audit.Entry, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	return s.repo.List(ctx, filter)
+}
+
+// snapshot encodes v, treating nil and typed nil pointers as an absent value
+func snapshot(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
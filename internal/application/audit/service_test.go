@@ -0,0 +1,114 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"testtask/internal/domain/audit"
+	"testtask/internal/domain/user"
+)
+
+type mockRepository struct {
+	entries []*audit.Entry
+	filter  audit.Filter
+}
+
+func (m *mockRepository) Append(_ context.Context, e *audit.Entry) error {
+	m.entries = append(m.entries, e)
+	return nil
+}
+
+func (m *mockRepository) List(_ context.Context, filter audit.Filter) ([]*audit.Entry, error) {
+	m.filter = filter
+	return m.entries, nil
+}
+
+func TestService_Record(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	caller := &user.User{ID: "alice"}
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		before        any
+		after         any
+		wantActor     string
+		wantRequestID string
+		wantBefore    string
+		wantAfter     string
+	}{
+		{
+			name:          "authenticated request",
+			ctx:           audit.WithRequestID(user.WithUser(context.Background(), caller), "req-1"),
+			before:        map[string]string{"amount": "1"},
+			after:         map[string]string{"amount": "2"},
+			wantActor:     "alice",
+			wantRequestID: "req-1",
+			wantBefore:    `{"amount":"1"}`,
+			wantAfter:     `{"amount":"2"}`,
+		},
+		{
+			name:      "background job creation",
+			ctx:       context.Background(),
+			before:    (*map[string]string)(nil),
+			after:     map[string]string{"amount": "2"},
+			wantActor: audit.SystemActor,
+			wantAfter: `{"amount":"2"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepository{}
+			service := NewService(repo, nil)
+			service.now = func() time.Time { return now }
+
+			service.Record(tt.ctx, audit.NewEntry(audit.ActionHoldingUpdate, audit.ResourceHolding, "h1", "p1"), tt.before, tt.after)
+
+			if len(repo.entries) != 1 {
+				t.Fatalf("recorded %d entries, want 1", len(repo.entries))
+			}
+			e := repo.entries[0]
+			if e.ActorID != tt.wantActor || e.RequestID != tt.wantRequestID || !e.CreatedAt.Equal(now) {
+				t.Errorf("Record() entry = %+v", e)
+			}
+			if string(e.Before) != tt.wantBefore || string(e.After) != tt.wantAfter {
+				t.Errorf("Record() before/after = %s/%s, want %s/%s", e.Before, e.After, tt.wantBefore, tt.wantAfter)
+			}
+		})
+	}
+}
+
+func TestService_List(t *testing.T) {
+	from := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	to := from.Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		filter    audit.Filter
+		wantLimit int
+		wantErr   error
+	}{
+		{name: "default limit", filter: audit.Filter{PortfolioID: "p1"}, wantLimit: audit.DefaultLimit},
+		{name: "limit capped", filter: audit.Filter{Limit: 5000}, wantLimit: audit.MaxLimit},
+		{name: "inverted range", filter: audit.Filter{From: &from, To: &to}, wantErr: audit.ErrInvalidFilter},
+		{name: "negative limit", filter: audit.Filter{Limit: -1}, wantErr: audit.ErrInvalidFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepository{}
+			service := NewService(repo, nil)
+
+			_, err := service.List(context.Background(), tt.filter)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("List() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && repo.filter.Limit != tt.wantLimit {
+				t.Errorf("List() limit = %d, want %d", repo.filter.Limit, tt.wantLimit)
+			}
+		})
+	}
+}
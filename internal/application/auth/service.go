@@ -0,0 +1,208 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	loggeradapter "testtask/internal/adapters/logger"
+	"testtask/internal/domain/audit"
+	"testtask/internal/domain/user"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// apiKeyPrefix marks keys issued by this service so they are recognisable in configs and logs
+	apiKeyPrefix = "cpt_"
+	// apiKeyDisplayLength is the number of leading characters kept for listings
+	apiKeyDisplayLength = 12
+	defaultAPIKeyName   = "default"
+)
+
+// Service registers users, manages their API keys and issues short-lived HS256 access tokens
+type Service struct {
+	users     user.Repository
+	jwtSecret []byte
+	tokenTTL  time.Duration
+	admins    map[string]bool // IDs of the users granted the admin role
+	recorder  audit.Recorder
+	logger    *loggeradapter.Logger
+	now       func() time.Time
+}
+
+// NewService creates the auth service. An empty jwtSecret disables access tokens;
+// API keys keep working. Users whose ID is in adminIDs authenticate as admins; IDs are
+// assigned by the service, so the role cannot be claimed by registering an address.
+func NewService(users user.Repository, jwtSecret string, tokenTTL time.Duration, adminIDs []string, recorder audit.Recorder, logger *loggeradapter.Logger) *Service {
+	if logger == nil {
+		logger = loggeradapter.NewNopLogger()
+	}
+	if recorder == nil {
+		recorder = audit.NopRecorder{}
+	}
+	if tokenTTL <= 0 {
+		tokenTTL = time.Hour
+	}
+	admins := make(map[string]bool, len(adminIDs))
+	for _, id := range adminIDs {
+		if id = strings.TrimSpace(id); id != "" {
+			admins[id] = true
+		}
+	}
+	return &Service{
+		users:     users,
+		jwtSecret: []byte(jwtSecret),
+		tokenTTL:  tokenTTL,
+		admins:    admins,
+		recorder:  recorder,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// Register creates a user together with a first API key. The plain key is only returned here.
+func (s *Service) Register(ctx context.Context, email, name string) (*user.User, string, error) {
+	u, err := user.NewUser(email, name)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := s.users.Create(ctx, u); err != nil {
+		return nil, "", err
+	}
+	u.Admin = s.admins[u.ID]
+
+	// The new user is the actor of its first key in the audit log
+	_, plain, err := s.CreateAPIKey(user.WithUser(ctx, u), u.ID, defaultAPIKeyName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	s.logger.Info("User registered", zap.String("user_id", u.ID))
+	return u, plain, nil
+}
+
+// CreateAPIKey issues a new API key for the user. The plain key is only returned here.
+func (s *Service) CreateAPIKey(ctx context.Context, userID, name string) (*user.APIKey, string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	plain := apiKeyPrefix + hex.EncodeToString(secret)
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = defaultAPIKeyName
+	}
+
+	key := &user.APIKey{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Name:      name,
+		Prefix:    plain[:apiKeyDisplayLength],
+		Hash:      hashAPIKey(plain),
+		CreatedAt: s.now().UTC(),
+	}
+	if err := s.users.CreateAPIKey(ctx, key); err != nil {
+		return nil, "", err
+	}
+
+	s.recorder.Record(ctx, audit.NewEntry(audit.ActionAPIKeyCreate, audit.ResourceAPIKey, key.ID, ""), nil, apiKeySnapshot{Name: key.Name, Prefix: key.Prefix})
+	return key, plain, nil
+}
+
+func (s *Service) ListAPIKeys(ctx context.Context, userID string) ([]*user.APIKey, error) {
+	return s.users.ListAPIKeys(ctx, userID)
+}
+
+func (s *Service) RevokeAPIKey(ctx context.Context, userID, keyID string) error {
+	if err := s.users.DeleteAPIKey(ctx, userID, keyID); err != nil {
+		return err
+	}
+
+	s.recorder.Record(ctx, audit.NewEntry(audit.ActionAPIKeyRevoke, audit.ResourceAPIKey, keyID, ""), nil, nil)
+	return nil
+}
+
+// apiKeySnapshot is the audit log representation of an API key; the secret is never recorded
+type apiKeySnapshot struct {
+	Name   string `json:"name"`
+	Prefix string `json:"prefix"`
+}
+
+// AuthenticateAPIKey returns the owner of a plain API key
+func (s *Service) AuthenticateAPIKey(ctx context.Context, apiKey string) (*user.User, error) {
+	if !strings.HasPrefix(apiKey, apiKeyPrefix) {
+		return nil, fmt.Errorf("%w: unknown api key", user.ErrInvalidCredentials)
+	}
+
+	key, u, err := s.users.GetByAPIKeyHash(ctx, hashAPIKey(apiKey))
+	if err != nil {
+		if errors.Is(err, user.ErrAPIKeyNotFound) || errors.Is(err, user.ErrUserNotFound) {
+			return nil, fmt.Errorf("%w: unknown api key", user.ErrInvalidCredentials)
+		}
+		return nil, err
+	}
+
+	// Usage tracking is best effort and must not fail the request
+	if err := s.users.TouchAPIKey(ctx, key.ID, s.now()); err != nil {
+		s.logger.Warn("Failed to record api key usage", zap.String("key_id", key.ID), zap.Error(err))
+	}
+
+	u.Admin = s.admins[u.ID]
+	return u, nil
+}
+
+// IssueToken returns a signed access token for the user and its expiry
+func (s *Service) IssueToken(_ context.Context, u *user.User) (string, time.Time, error) {
+	if len(s.jwtSecret) == 0 {
+		return "", time.Time{}, user.ErrTokensDisabled
+	}
+
+	now := s.now()
+	expiresAt := now.Add(s.tokenTTL)
+	token, err := signToken(s.jwtSecret, claims{
+		Subject:   u.ID,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiresAt, nil
+}
+
+// AuthenticateToken verifies an access token and returns its user. Tokens of deleted users are rejected.
+func (s *Service) AuthenticateToken(ctx context.Context, token string) (*user.User, error) {
+	if len(s.jwtSecret) == 0 {
+		return nil, user.ErrTokensDisabled
+	}
+
+	c, err := parseToken(s.jwtSecret, token, s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := s.users.GetByID(ctx, c.Subject)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return nil, fmt.Errorf("%w: unknown token subject", user.ErrInvalidCredentials)
+		}
+		return nil, err
+	}
+
+	u.Admin = s.admins[u.ID]
+	return u, nil
+}
+
+func hashAPIKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,258 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"testtask/internal/domain/user"
+)
+
+// mockUserRepository implements user.Repository in memory
+type mockUserRepository struct {
+	users map[string]*user.User
+	keys  map[string]*user.APIKey
+}
+
+func newMockUserRepository() *mockUserRepository {
+	return &mockUserRepository{
+		users: make(map[string]*user.User),
+		keys:  make(map[string]*user.APIKey),
+	}
+}
+
+func (m *mockUserRepository) Create(_ context.Context, u *user.User) error {
+	for _, existing := range m.users {
+		if existing.Email == u.Email {
+			return user.ErrUserExists
+		}
+	}
+	m.users[u.ID] = u
+	return nil
+}
+
+func (m *mockUserRepository) GetByID(_ context.Context, id string) (*user.User, error) {
+	if u, ok := m.users[id]; ok {
+		return u, nil
+	}
+	return nil, user.ErrUserNotFound
+}
+
+func (m *mockUserRepository) GetByEmail(_ context.Context, email string) (*user.User, error) {
+	for _, u := range m.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return nil, user.ErrUserNotFound
+}
+
+func (m *mockUserRepository) CreateAPIKey(_ context.Context, key *user.APIKey) error {
+	m.keys[key.Hash] = key
+	return nil
+}
+
+func (m *mockUserRepository) GetByAPIKeyHash(ctx context.Context, hash string) (*user.APIKey, *user.User, error) {
+	key, ok := m.keys[hash]
+	if !ok {
+		return nil, nil, user.ErrAPIKeyNotFound
+	}
+	u, err := m.GetByID(ctx, key.UserID)
+	return key, u, err
+}
+
+func (m *mockUserRepository) ListAPIKeys(_ context.Context, userID string) ([]*user.APIKey, error) {
+	var keys []*user.APIKey
+	for _, key := range m.keys {
+		if key.UserID == userID {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (m *mockUserRepository) DeleteAPIKey(_ context.Context, userID, keyID string) error {
+	for hash, key := range m.keys {
+		if key.ID == keyID && key.UserID == userID {
+			delete(m.keys, hash)
+			return nil
+		}
+	}
+	return user.ErrAPIKeyNotFound
+}
+
+func (m *mockUserRepository) TouchAPIKey(_ context.Context, keyID string, at time.Time) error {
+	for _, key := range m.keys {
+		if key.ID == keyID {
+			key.LastUsedAt = &at
+		}
+	}
+	return nil
+}
+
+func TestService_APIKeys(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(newMockUserRepository(), "secret", time.Hour, nil, nil, nil)
+
+	u, plain, err := service.Register(ctx, "carol@example.com", "Carol")
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if !strings.HasPrefix(plain, apiKeyPrefix) {
+		t.Errorf("api key = %q, want prefix %q", plain, apiKeyPrefix)
+	}
+
+	if _, _, err := service.Register(ctx, "CAROL@example.com", ""); !errors.Is(err, user.ErrUserExists) {
+		t.Errorf("Register() duplicate error = %v, want %v", err, user.ErrUserExists)
+	}
+	if _, _, err := service.Register(ctx, "not-an-email", ""); !errors.Is(err, user.ErrInvalidUser) {
+		t.Errorf("Register() invalid email error = %v, want %v", err, user.ErrInvalidUser)
+	}
+
+	got, err := service.AuthenticateAPIKey(ctx, plain)
+	if err != nil {
+		t.Fatalf("AuthenticateAPIKey() error = %v", err)
+	}
+	if got.ID != u.ID {
+		t.Errorf("AuthenticateAPIKey() user = %s, want %s", got.ID, u.ID)
+	}
+
+	keys, _ := service.ListAPIKeys(ctx, u.ID)
+	if len(keys) != 1 || keys[0].LastUsedAt == nil {
+		t.Fatalf("ListAPIKeys() = %+v, want one used key", keys)
+	}
+	if err := service.RevokeAPIKey(ctx, u.ID, keys[0].ID); err != nil {
+		t.Fatalf("RevokeAPIKey() error = %v", err)
+	}
+	if _, err := service.AuthenticateAPIKey(ctx, plain); !errors.Is(err, user.ErrInvalidCredentials) {
+		t.Errorf("AuthenticateAPIKey() revoked error = %v, want %v", err, user.ErrInvalidCredentials)
+	}
+}
+
+func TestService_AccessTokens(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockUserRepository()
+	service := NewService(repo, "secret", time.Hour, nil, nil, nil)
+
+	u, _, err := service.Register(ctx, "dave@example.com", "")
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	token, expiresAt, err := service.IssueToken(ctx, u)
+	if err != nil {
+		t.Fatalf("IssueToken() error = %v", err)
+	}
+	if !expiresAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("expiresAt = %v, want %v", expiresAt, now.Add(time.Hour))
+	}
+
+	other := NewService(repo, "other-secret", time.Hour, nil, nil, nil)
+	other.now = service.now
+	parts := strings.Split(token, ".")
+
+	tests := []struct {
+		name    string
+		service *Service
+		token   string
+		at      time.Time
+		wantErr error
+	}{
+		{name: "valid", service: service, token: token, at: now.Add(time.Minute)},
+		{name: "expired", service: service, token: token, at: now.Add(time.Hour), wantErr: user.ErrInvalidCredentials},
+		{name: "wrong secret", service: other, token: token, at: now, wantErr: user.ErrInvalidCredentials},
+		{name: "tampered payload", service: service, token: parts[0] + "." + parts[1] + "x." + parts[2], at: now, wantErr: user.ErrInvalidCredentials},
+		{name: "alg none", service: service, token: "eyJhbGciOiJub25lIn0." + parts[1] + ".", at: now, wantErr: user.ErrInvalidCredentials},
+		{name: "garbage", service: service, token: "not-a-token", at: now, wantErr: user.ErrInvalidCredentials},
+		{name: "disabled", service: NewService(repo, "", time.Hour, nil, nil, nil), token: token, at: now, wantErr: user.ErrTokensDisabled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at := tt.at
+			tt.service.now = func() time.Time { return at }
+
+			got, err := tt.service.AuthenticateToken(ctx, tt.token)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("AuthenticateToken() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AuthenticateToken() error = %v", err)
+			}
+			if got.ID != u.ID {
+				t.Errorf("AuthenticateToken() user = %s, want %s", got.ID, u.ID)
+			}
+		})
+	}
+}
+
+func TestService_AdminRole(t *testing.T) {
+	ctx := context.Background()
+	users := newMockUserRepository()
+	setup := NewService(users, "secret", time.Hour, nil, nil, nil)
+
+	admin, _, err := setup.Register(ctx, "root@example.com", "")
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	service := NewService(users, "secret", time.Hour, []string{" " + admin.ID + " ", "admin@example.com"}, nil, nil)
+
+	tests := []struct {
+		email string
+		admin bool
+	}{
+		{email: "admin@example.com", admin: false}, // an email in the list grants nothing
+		{email: "erin@example.com", admin: false},
+	}
+
+	check := func(t *testing.T, u *user.User, plain string, want bool) {
+		t.Helper()
+		got, err := service.AuthenticateAPIKey(ctx, plain)
+		if err != nil {
+			t.Fatalf("AuthenticateAPIKey() error = %v", err)
+		}
+		if got.Admin != want {
+			t.Errorf("AuthenticateAPIKey() admin = %v, want %v", got.Admin, want)
+		}
+
+		token, _, err := service.IssueToken(ctx, u)
+		if err != nil {
+			t.Fatalf("IssueToken() error = %v", err)
+		}
+		got, err = service.AuthenticateToken(ctx, token)
+		if err != nil {
+			t.Fatalf("AuthenticateToken() error = %v", err)
+		}
+		if got.Admin != want {
+			t.Errorf("AuthenticateToken() admin = %v, want %v", got.Admin, want)
+		}
+	}
+
+	t.Run("configured user ID", func(t *testing.T) {
+		_, plain, err := service.CreateAPIKey(user.WithUser(ctx, admin), admin.ID, "ops")
+		if err != nil {
+			t.Fatalf("CreateAPIKey() error = %v", err)
+		}
+		check(t, admin, plain, true)
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			u, plain, err := service.Register(ctx, tt.email, "")
+			if err != nil {
+				t.Fatalf("Register() error = %v", err)
+			}
+			if u.Admin != tt.admin {
+				t.Errorf("Register() admin = %v, want %v", u.Admin, tt.admin)
+			}
+			check(t, u, plain, tt.admin)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package ens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	loggeradapter "testtask/internal/adapters/logger"
+	"testtask/internal/domain"
+	"testtask/internal/domain/ens"
+	"testtask/internal/domain/transaction"
+
+	"go.uber.org/zap"
+)
+
+const defaultCacheTTL = time.Hour
+
+// Service resolves ENS names to addresses and addresses to their verified primary names,
+// caching results and misses for the TTL. Failed lookups are not cached.
+type Service struct {
+	resolver ens.Resolver
+	cache    domain.Cache[string, ens.Result]
+	cacheTTL time.Duration
+	logger   *loggeradapter.Logger
+	now      func() time.Time
+}
+
+// NewService creates the ENS service. A nil resolver disables resolution: names are
+// rejected with ens.ErrUnavailable and addresses have no names.
+func NewService(resolver ens.Resolver, cache domain.Cache[string, ens.Result], cacheTTL time.Duration, logger *loggeradapter.Logger) *Service {
+	if logger == nil {
+		logger = loggeradapter.NewNopLogger()
+	}
+	if cacheTTL <= 0 {
+		cacheTTL = defaultCacheTTL
+	}
+	return &Service{
+		resolver: resolver,
+		cache:    cache,
+		cacheTTL: cacheTTL,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// ResolveAddress returns input as is unless it is an ENS name, which is resolved to its address
+func (s *Service) ResolveAddress(ctx context.Context, input string) (string, error) {
+	if !ens.IsName(input) {
+		return input, nil
+	}
+	return s.Resolve(ctx, input)
+}
+
+// Resolve returns the address of a name
+func (s *Service) Resolve(ctx context.Context, name string) (string, error) {
+	name, err := ens.Normalize(name)
+	if err != nil {
+		return "", err
+	}
+	if s.resolver == nil {
+		return "", fmt.Errorf("%w: no ethereum rpc endpoint is configured", ens.ErrUnavailable)
+	}
+
+	address, err := s.cached(ctx, "name:"+name, func() (string, error) {
+		address, err := s.resolver.Resolve(ctx, name)
+		if errors.Is(err, ens.ErrNotResolved) {
+			return "", nil
+		}
+		return address, err
+	})
+	if err != nil {
+		return "", err
+	}
+	if address == "" {
+		return "", fmt.Errorf("%w: %s", ens.ErrNotResolved, name)
+	}
+	return address, nil
+}
+
+// LookupAddress returns the verified primary name of an address, empty when it has none
+func (s *Service) LookupAddress(ctx context.Context, address string) (string, error) {
+	if s.resolver == nil {
+		return "", nil
+	}
+	address = strings.ToLower(strings.TrimSpace(address))
+	return s.cached(ctx, "addr:"+address, func() (string, error) {
+		return s.resolver.LookupAddress(ctx, address)
+	})
+}
+
+// Name sets FromENS and ToENS on the transactions. Resolution stops at the first failure,
+// leaving the remaining names empty.
+func (s *Service) Name(ctx context.Context, txs []*transaction.Transaction) error {
+	if s.resolver == nil {
+		return nil
+	}
+	names := make(map[string]string)
+	lookup := func(address string) (string, error) {
+		if address == "" {
+			return "", nil
+		}
+		address = strings.ToLower(address)
+		if name, ok := names[address]; ok {
+			return name, nil
+		}
+		name, err := s.LookupAddress(ctx, address)
+		if err != nil {
+			return "", err
+		}
+		names[address] = name
+		return name, nil
+	}
+
+	for _, tx := range txs {
+		if tx == nil {
+			continue
+		}
+		var err error
+		if tx.FromENS, err = lookup(tx.From); err != nil {
+			return err
+		}
+		if tx.ToENS, err = lookup(tx.To); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cached returns the value cached under key while it is fresh, otherwise the result of
+// resolve, which is cached unless it fails
+func (s *Service) cached(ctx context.Context, key string, resolve func() (string, error)) (string, error) {
+	now := s.now()
+	if result, ok := s.cache.Get(ctx, key); ok && now.Sub(result.ResolvedAt) < s.cacheTTL {
+		return result.Value, nil
+	}
+
+	value, err := resolve()
+	if err != nil {
+		s.logger.Warn("ENS resolution failed", zap.String("key", key), zap.Error(err))
+		return "", err
+	}
+	s.cache.Set(ctx, key, ens.Result{Value: value, ResolvedAt: now})
+	return value, nil
+}
@@ -0,0 +1,151 @@
+package ens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"testtask/internal/adapters/cache"
+	"testtask/internal/domain/ens"
+	"testtask/internal/domain/transaction"
+)
+
+const (
+	vitalikAddress = "0xd8da6bf26964af9d7eed9e03e53415d37aa96045"
+	friendAddress  = "0x1111111111111111111111111111111111111111"
+)
+
+// mockResolver knows vitalik.eth and counts lookups; down makes every lookup fail
+type mockResolver struct {
+	calls int
+	down  bool
+}
+
+func (m *mockResolver) Resolve(_ context.Context, name string) (string, error) {
+	m.calls++
+	if m.down {
+		return "", fmt.Errorf("%w: rpc down", ens.ErrUnavailable)
+	}
+	if name == "vitalik.eth" {
+		return vitalikAddress, nil
+	}
+	return "", fmt.Errorf("%w: %s", ens.ErrNotResolved, name)
+}
+
+func (m *mockResolver) LookupAddress(_ context.Context, address string) (string, error) {
+	m.calls++
+	if m.down {
+		return "", fmt.Errorf("%w: rpc down", ens.ErrUnavailable)
+	}
+	if address == vitalikAddress {
+		return "vitalik.eth", nil
+	}
+	return "", nil
+}
+
+func newTestService(resolver ens.Resolver) (*Service, *time.Time) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	svc := NewService(resolver, cache.NewCache[string, ens.Result](10), time.Hour, nil)
+	svc.now = func() time.Time { return now }
+	return svc, &now
+}
+
+func TestService_ResolveAddress(t *testing.T) {
+	svc, _ := newTestService(&mockResolver{})
+	ctx := context.Background()
+
+	tests := []struct {
+		input   string
+		want    string
+		wantErr error
+	}{
+		{"Vitalik.ETH", vitalikAddress, nil},
+		{friendAddress, friendAddress, nil},
+		{"nobody.eth", "", ens.ErrNotResolved},
+		{"vitalik..eth", "", ens.ErrInvalidName},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := svc.ResolveAddress(ctx, tt.input)
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("ResolveAddress() = %q, %v, want %q, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestService_Resolve_Cache(t *testing.T) {
+	resolver := &mockResolver{}
+	svc, now := newTestService(resolver)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := svc.Resolve(ctx, "vitalik.eth"); err != nil {
+			t.Fatalf("Resolve() error = %v", err)
+		}
+		if _, err := svc.Resolve(ctx, "nobody.eth"); !errors.Is(err, ens.ErrNotResolved) {
+			t.Fatalf("Resolve(nobody.eth) error = %v, want ErrNotResolved", err)
+		}
+	}
+	if resolver.calls != 2 {
+		t.Errorf("resolver calls = %d, want 2: hits and misses are cached", resolver.calls)
+	}
+
+	*now = now.Add(time.Hour)
+	if _, err := svc.Resolve(ctx, "vitalik.eth"); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if resolver.calls != 3 {
+		t.Errorf("resolver calls = %d, want 3: the entry expired", resolver.calls)
+	}
+
+	// Failures are not cached
+	resolver.down = true
+	*now = now.Add(time.Hour)
+	for i := 0; i < 2; i++ {
+		if _, err := svc.Resolve(ctx, "vitalik.eth"); !errors.Is(err, ens.ErrUnavailable) {
+			t.Fatalf("Resolve() error = %v, want ErrUnavailable", err)
+		}
+	}
+	if resolver.calls != 5 {
+		t.Errorf("resolver calls = %d, want 5", resolver.calls)
+	}
+}
+
+func TestService_Name(t *testing.T) {
+	resolver := &mockResolver{}
+	svc, _ := newTestService(resolver)
+
+	txs := []*transaction.Transaction{
+		{From: vitalikAddress, To: friendAddress},
+		{From: friendAddress, To: "0xD8DA6BF26964AF9D7EED9E03E53415D37AA96045"},
+		nil,
+	}
+	if err := svc.Name(context.Background(), txs); err != nil {
+		t.Fatalf("Name() error = %v", err)
+	}
+	if txs[0].FromENS != "vitalik.eth" || txs[0].ToENS != "" || txs[1].ToENS != "vitalik.eth" {
+		t.Errorf("names = %q, %q, %q", txs[0].FromENS, txs[0].ToENS, txs[1].ToENS)
+	}
+	if resolver.calls != 2 {
+		t.Errorf("resolver calls = %d, want one per distinct address", resolver.calls)
+	}
+}
+
+func TestService_Disabled(t *testing.T) {
+	svc, _ := newTestService(nil)
+	ctx := context.Background()
+
+	if _, err := svc.ResolveAddress(ctx, "vitalik.eth"); !errors.Is(err, ens.ErrUnavailable) {
+		t.Errorf("ResolveAddress(name) error = %v, want ErrUnavailable", err)
+	}
+	if got, err := svc.ResolveAddress(ctx, friendAddress); err != nil || got != friendAddress {
+		t.Errorf("ResolveAddress(address) = %q, %v, want the address", got, err)
+	}
+	tx := &transaction.Transaction{From: vitalikAddress}
+	if err := svc.Name(ctx, []*transaction.Transaction{tx}); err != nil || tx.FromENS != "" {
+		t.Errorf("Name() = %q, %v, want no names", tx.FromENS, err)
+	}
+}
@@ -0,0 +1,298 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"testtask/internal/domain/audit"
+	"testtask/internal/domain/exchange"
+	"testtask/internal/domain/portfolio"
+
+	"go.uber.org/zap"
+)
+
+// connectionSnapshot is the audit log representation of a connection; credentials are
+// never recorded
+type connectionSnapshot struct {
+	Exchange string `json:"exchange"`
+	Label    string `json:"label"`
+	KeyHint  string `json:"api_key_hint"`
+}
+
+func snapshotConnection(c *exchange.Connection) *connectionSnapshot {
+	return &connectionSnapshot{Exchange: string(c.Exchange), Label: c.Label, KeyHint: c.KeyHint}
+}
+
+// CreateConnection links the portfolio to an exchange account. The key is checked to be
+// read-only before its credentials are stored encrypted.
+func (s *Service) CreateConnection(ctx context.Context, portfolioID string, e exchange.Exchange, label string, creds exchange.Credentials) (*exchange.Connection, error) {
+	if err := creds.Validate(); err != nil {
+		return nil, err
+	}
+	connector, err := s.connector(e)
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.portfolios.GetByID(ctx, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	if p.Archived {
+		s.logger.Warn("Attempted to connect exchange to archived portfolio", zap.String("portfolio_id", portfolioID))
+		return nil, portfolio.ErrPortfolioArchived
+	}
+
+	if err := connector.VerifyReadOnly(ctx, creds); err != nil {
+		s.logger.Warn("Exchange API key rejected", zap.String("portfolio_id", portfolioID), zap.String("exchange", string(e)), zap.Error(err))
+		return nil, err
+	}
+
+	plaintext, err := json.Marshal(creds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode credentials: %w", err)
+	}
+	sealed, err := s.cipher.Encrypt(plaintext)
+	if err != nil {
+		s.logger.Error("Failed to encrypt exchange credentials", zap.Error(err))
+		return nil, err
+	}
+
+	c := exchange.NewConnection(portfolioID, e, label, creds.Hint(), sealed)
+	if err := s.connections.CreateConnection(ctx, c); err != nil {
+		if !errors.Is(err, exchange.ErrConnectionExists) {
+			s.logger.Error("Failed to create exchange connection", zap.String("portfolio_id", portfolioID), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	s.logger.Info("Exchange connection created", zap.String("portfolio_id", portfolioID), zap.String("connection_id", c.ID), zap.String("exchange", string(e)))
+	s.recorder.Record(ctx, audit.NewEntry(audit.ActionConnectionCreate, audit.ResourceConnection, c.ID, portfolioID), nil, snapshotConnection(c))
+	return c, nil
+}
+
+// ListConnections returns the portfolio's exchange connections
+func (s *Service) ListConnections(ctx context.Context, portfolioID string) ([]*exchange.Connection, error) {
+	if _, err := s.portfolios.GetByID(ctx, portfolioID); err != nil {
+		return nil, err
+	}
+	return s.connections.ListConnections(ctx, portfolioID)
+}
+
+// DeleteConnection removes a connection and its credentials. Records synced through it
+// are kept.
+func (s *Service) DeleteConnection(ctx context.Context, portfolioID, connectionID string) error {
+	c, err := s.connections.GetConnection(ctx, portfolioID, connectionID)
+	if err != nil {
+		return err
+	}
+	if err := s.connections.DeleteConnection(ctx, portfolioID, connectionID); err != nil {
+		s.logger.Error("Failed to delete exchange connection", zap.String("connection_id", connectionID), zap.Error(err))
+		return err
+	}
+
+	s.logger.Info("Exchange connection deleted", zap.String("portfolio_id", portfolioID), zap.String("connection_id", connectionID))
+	s.recorder.Record(ctx, audit.NewEntry(audit.ActionConnectionDelete, audit.ResourceConnection, connectionID, portfolioID), snapshotConnection(c), nil)
+	return nil
+}
+
+// SyncConnection pulls the account's trades and balances into the portfolio
+func (s *Service) SyncConnection(ctx context.Context, portfolioID, connectionID string) (*exchange.SyncResult, error) {
+	c, err := s.connections.GetConnection(ctx, portfolioID, connectionID)
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.portfolios.GetByID(ctx, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	if p.Archived {
+		return nil, portfolio.ErrPortfolioArchived
+	}
+	return s.sync(ctx, c)
+}
+
+// SyncAll syncs every connection of active portfolios. It is run by the scheduler; a
+// failing connection does not stop the others.
+func (s *Service) SyncAll(ctx context.Context) error {
+	connections, err := s.connections.ListAllConnections(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, c := range connections {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		p, err := s.portfolios.GetByID(ctx, c.PortfolioID)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if p.Archived {
+			continue
+		}
+		if _, err := s.sync(ctx, c); err != nil {
+			errs = append(errs, fmt.Errorf("connection %s: %w", c.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sync stores new trades, then adds a deposit or withdrawal per asset whose recorded
+// balance differs from the live one, covering transfers and history the trade endpoints
+// do not report. The outcome is kept on the connection.
+func (s *Service) sync(ctx context.Context, c *exchange.Connection) (*exchange.SyncResult, error) {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	result, cursor, err := s.pull(ctx, c)
+	now := s.now().UTC()
+	c.UpdatedAt = now
+	if err != nil {
+		c.LastError = err.Error()
+		s.logger.Warn("Exchange sync failed", zap.String("portfolio_id", c.PortfolioID), zap.String("connection_id", c.ID), zap.Error(err))
+	} else {
+		c.Cursor, c.LastSyncedAt, c.LastError = cursor, &now, ""
+		s.logger.Info("Exchange sync completed",
+			zap.String("portfolio_id", c.PortfolioID),
+			zap.String("connection_id", c.ID),
+			zap.Int("trades", result.Trades),
+			zap.Int("adjustments", result.Adjustments))
+	}
+	if updateErr := s.connections.UpdateSyncState(ctx, c); updateErr != nil {
+		s.logger.Error("Failed to store exchange sync state", zap.String("connection_id", c.ID), zap.Error(updateErr))
+		if err == nil {
+			err = updateErr
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *Service) pull(ctx context.Context, c *exchange.Connection) (*exchange.SyncResult, exchange.Cursor, error) {
+	connector, err := s.connector(c.Exchange)
+	if err != nil {
+		return nil, nil, err
+	}
+	plaintext, err := s.cipher.Decrypt(c.Credentials)
+	if err != nil {
+		return nil, nil, err
+	}
+	var creds exchange.Credentials
+	if err := json.Unmarshal(plaintext, &creds); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode credentials: %w", err)
+	}
+
+	balances, err := connector.Balances(ctx, creds)
+	if err != nil {
+		return nil, nil, err
+	}
+	recorded, err := s.recordedBalances(ctx, c)
+	if err != nil {
+		return nil, nil, err
+	}
+	live := make(map[string]*big.Int, len(balances))
+	for _, b := range balances {
+		live[b.Asset] = b.Total()
+	}
+
+	assets := make([]string, 0, len(live)+len(recorded))
+	for asset := range live {
+		assets = append(assets, asset)
+	}
+	for asset := range recorded {
+		if _, ok := live[asset]; !ok {
+			assets = append(assets, asset)
+		}
+	}
+	sort.Strings(assets)
+
+	trades, cursor, err := connector.Trades(ctx, creds, assets, c.Cursor)
+	if err != nil {
+		return nil, nil, err
+	}
+	exchange.Assign(trades, c.PortfolioID, s.now())
+	saved, err := s.repo.SaveRecords(ctx, trades)
+	if err != nil {
+		return nil, nil, err
+	}
+	if saved > 0 {
+		if recorded, err = s.recordedBalances(ctx, c); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	now := s.now().UTC()
+	var adjustments []*exchange.Record
+	for _, asset := range assets {
+		want := live[asset]
+		if want == nil {
+			want = new(big.Int)
+		}
+		have := recorded[asset]
+		if have == nil {
+			have = new(big.Int)
+		}
+		diff := new(big.Int).Sub(want, have)
+		if diff.Sign() == 0 {
+			continue
+		}
+		adjustments = append(adjustments, &exchange.Record{
+			Exchange:   c.Exchange,
+			ExternalID: fmt.Sprintf("sync:%s:%s:%d", c.ID, asset, now.UnixNano()),
+			Kind:       transferKindOf(diff),
+			Asset:      asset,
+			Amount:     diff,
+			Note:       "balance sync",
+			OccurredAt: now,
+		})
+	}
+	exchange.Assign(adjustments, c.PortfolioID, now)
+	adjusted, err := s.repo.SaveRecords(ctx, adjustments)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &exchange.SyncResult{Balances: len(balances), Trades: saved, Adjustments: adjusted}, cursor, nil
+}
+
+// recordedBalances sums the portfolio's records of the connection's exchange per asset
+func (s *Service) recordedBalances(ctx context.Context, c *exchange.Connection) (map[string]*big.Int, error) {
+	records, err := s.repo.ListRecords(ctx, c.PortfolioID, exchange.Filter{Exchange: c.Exchange})
+	if err != nil {
+		return nil, err
+	}
+	balances := make(map[string]*big.Int)
+	for _, rec := range records {
+		if balances[rec.Asset] == nil {
+			balances[rec.Asset] = new(big.Int)
+		}
+		balances[rec.Asset].Add(balances[rec.Asset], rec.Amount)
+	}
+	return balances, nil
+}
+
+func (s *Service) connector(e exchange.Exchange) (exchange.Connector, error) {
+	connector, ok := s.connectors[e]
+	if !ok {
+		return nil, fmt.Errorf("%w: no API connector for %s", exchange.ErrUnsupportedExchange, e)
+	}
+	if s.cipher == nil {
+		return nil, fmt.Errorf("%w: credentials key is not configured", exchange.ErrConnectorUnavailable)
+	}
+	return connector, nil
+}
+
+func transferKindOf(amount *big.Int) exchange.Kind {
+	if amount.Sign() < 0 {
+		return exchange.KindWithdrawal
+	}
+	return exchange.KindDeposit
+}
@@ -0,0 +1,166 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"testtask/internal/domain/exchange"
+	"testtask/internal/domain/portfolio"
+)
+
+// mockPortfolios serves fixed portfolios by ID
+type mockPortfolios struct {
+	portfolio.Repository
+	portfolios map[string]*portfolio.Portfolio
+}
+
+func (m *mockPortfolios) GetByID(_ context.Context, id string) (*portfolio.Portfolio, error) {
+	if p, ok := m.portfolios[id]; ok {
+		return p, nil
+	}
+	return nil, portfolio.ErrPortfolioNotFound
+}
+
+// mockConnections implements exchange.ConnectionRepository in memory
+type mockConnections struct {
+	connections map[string]*exchange.Connection
+}
+
+func (m *mockConnections) CreateConnection(_ context.Context, c *exchange.Connection) error {
+	m.connections[c.ID] = c
+	return nil
+}
+
+func (m *mockConnections) GetConnection(_ context.Context, portfolioID, id string) (*exchange.Connection, error) {
+	if c, ok := m.connections[id]; ok && c.PortfolioID == portfolioID {
+		return c, nil
+	}
+	return nil, exchange.ErrConnectionNotFound
+}
+
+func (m *mockConnections) ListConnections(context.Context, string) ([]*exchange.Connection, error) {
+	return m.ListAllConnections(context.Background())
+}
+
+func (m *mockConnections) ListAllConnections(context.Context) ([]*exchange.Connection, error) {
+	var all []*exchange.Connection
+	for _, c := range m.connections {
+		all = append(all, c)
+	}
+	return all, nil
+}
+
+func (m *mockConnections) UpdateSyncState(context.Context, *exchange.Connection) error { return nil }
+
+func (m *mockConnections) DeleteConnection(_ context.Context, _, id string) error {
+	delete(m.connections, id)
+	return nil
+}
+
+// mockConnector returns fixed balances and hands out its trades once
+type mockConnector struct {
+	readOnly bool
+	balances []*exchange.Balance
+	trades   []*exchange.Record
+	assets   []string
+}
+
+func (m *mockConnector) VerifyReadOnly(context.Context, exchange.Credentials) error {
+	if !m.readOnly {
+		return exchange.ErrNotReadOnly
+	}
+	return nil
+}
+
+func (m *mockConnector) Balances(context.Context, exchange.Credentials) ([]*exchange.Balance, error) {
+	return m.balances, nil
+}
+
+func (m *mockConnector) Trades(_ context.Context, _ exchange.Credentials, assets []string, cursor exchange.Cursor) ([]*exchange.Record, exchange.Cursor, error) {
+	m.assets = assets
+	trades := m.trades
+	m.trades = nil
+	return trades, exchange.Cursor{"BTCUSDT": cursor["BTCUSDT"] + int64(len(trades))}, nil
+}
+
+// prefixCipher marks sealed values without hiding them
+type prefixCipher struct{}
+
+func (prefixCipher) Encrypt(plaintext []byte) (string, error) {
+	return "sealed:" + string(plaintext), nil
+}
+
+func (prefixCipher) Decrypt(ciphertext string) ([]byte, error) {
+	plain, ok := strings.CutPrefix(ciphertext, "sealed:")
+	if !ok {
+		return nil, errors.New("not sealed")
+	}
+	return []byte(plain), nil
+}
+
+func TestService_SyncConnection(t *testing.T) {
+	records := &mockRecordRepository{records: []*exchange.Record{
+		{ID: "csv-1", PortfolioID: "portfolio-1", Exchange: exchange.Binance, Kind: exchange.KindDeposit, Asset: "USDT", Amount: units(1000, 0)},
+	}}
+	connector := &mockConnector{
+		readOnly: true,
+		balances: []*exchange.Balance{
+			{Asset: "BTC", Free: units(999, 5), Locked: new(big.Int)},
+			{Asset: "USDT", Free: units(500, 0), Locked: new(big.Int)},
+		},
+		trades: []*exchange.Record{
+			{Exchange: exchange.Binance, ExternalID: "api:BTCUSDT:1:BTC", Kind: exchange.KindTrade, Asset: "BTC", Amount: units(1, 2)},
+			{Exchange: exchange.Binance, ExternalID: "api:BTCUSDT:1:USDT", Kind: exchange.KindTrade, Asset: "USDT", Amount: units(-420, 0)},
+			{Exchange: exchange.Binance, ExternalID: "api:BTCUSDT:1:fee:BTC", Kind: exchange.KindFee, Asset: "BTC", Amount: units(-1, 5)},
+		},
+	}
+	service := NewService(records, &mockConnections{connections: map[string]*exchange.Connection{}},
+		&mockPortfolios{portfolios: map[string]*portfolio.Portfolio{"portfolio-1": {ID: "portfolio-1"}}},
+		nil, map[exchange.Exchange]exchange.Connector{exchange.Binance: connector}, prefixCipher{}, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	if _, err := service.CreateConnection(ctx, "portfolio-1", exchange.Binance, "main", exchange.Credentials{APIKey: "key", APISecret: ""}); !errors.Is(err, exchange.ErrInvalidConnection) {
+		t.Fatalf("CreateConnection() without secret error = %v, want ErrInvalidConnection", err)
+	}
+	c, err := service.CreateConnection(ctx, "portfolio-1", exchange.Binance, "main", exchange.Credentials{APIKey: "key-1234", APISecret: "secret"})
+	if err != nil {
+		t.Fatalf("CreateConnection() error = %v", err)
+	}
+	if c.KeyHint != "1234" || !strings.HasPrefix(c.Credentials, "sealed:") {
+		t.Errorf("connection = %+v, want sealed credentials and a key hint", c)
+	}
+
+	result, err := service.SyncConnection(ctx, "portfolio-1", c.ID)
+	if err != nil {
+		t.Fatalf("SyncConnection() error = %v", err)
+	}
+	if result.Trades != 3 || result.Adjustments != 1 {
+		t.Fatalf("SyncConnection() = %+v, want 3 trade records and 1 adjustment", result)
+	}
+	adjustment := records.records[len(records.records)-1]
+	if adjustment.Kind != exchange.KindWithdrawal || adjustment.Asset != "USDT" || adjustment.Amount.Cmp(units(-80, 0)) != 0 {
+		t.Errorf("adjustment = %s %s %s, want a USDT withdrawal of 80", adjustment.Kind, adjustment.Asset, adjustment.Amount)
+	}
+	if strings.Join(connector.assets, ",") != "BTC,USDT" {
+		t.Errorf("Trades() assets = %v, want BTC and USDT", connector.assets)
+	}
+	if c.LastSyncedAt == nil || c.LastError != "" || c.Cursor["BTCUSDT"] != 3 {
+		t.Errorf("connection state = %v %q %v, want synced with the advanced cursor", c.LastSyncedAt, c.LastError, c.Cursor)
+	}
+
+	// Balances now match the records, so a scheduled sync adds nothing
+	if err := service.SyncAll(ctx); err != nil {
+		t.Fatalf("SyncAll() error = %v", err)
+	}
+	if len(records.records) != 5 {
+		t.Errorf("records after second sync = %d, want 5", len(records.records))
+	}
+
+	connector.readOnly = false
+	if _, err := service.CreateConnection(ctx, "portfolio-1", exchange.Binance, "", exchange.Credentials{APIKey: "key", APISecret: "secret"}); !errors.Is(err, exchange.ErrNotReadOnly) {
+		t.Errorf("CreateConnection() with trading key error = %v, want ErrNotReadOnly", err)
+	}
+}
@@ -0,0 +1,254 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	loggeradapter "testtask/internal/adapters/logger"
+	"testtask/internal/domain/audit"
+	"testtask/internal/domain/exchange"
+	"testtask/internal/domain/portfolio"
+	"testtask/internal/domain/token"
+	"testtask/internal/domain/transaction"
+
+	"go.uber.org/zap"
+)
+
+// chainID is the chain exchange assets are valued on, matching portfolio addresses
+const chainID = "1"
+
+// ParserFactory returns the statement parser of an exchange
+type ParserFactory func(e exchange.Exchange, mapping *exchange.Mapping) (exchange.Parser, error)
+
+// TokenResolver looks up the tokens exchange tickers are valued as
+type TokenResolver interface {
+	GetTokenByAddress(ctx context.Context, address string) (*token.Token, bool)
+	GetTokenBySymbol(ctx context.Context, symbol string) (*token.Token, bool)
+}
+
+// Service imports exchange statements, syncs connected exchange accounts and exposes the
+// resulting records as transactions, so that valuation treats exchange balances like
+// on-chain transfers
+type Service struct {
+	repo        exchange.Repository
+	connections exchange.ConnectionRepository
+	portfolios  portfolio.Repository
+	parsers     ParserFactory
+	connectors  map[exchange.Exchange]exchange.Connector
+	cipher      exchange.CredentialCipher
+	tokens      TokenResolver
+	aliases     *token.AliasRegistry
+	recorder    audit.Recorder
+	logger      *loggeradapter.Logger
+	now         func() time.Time
+
+	// syncMu keeps scheduled and requested syncs of a connection from interleaving
+	syncMu sync.Mutex
+}
+
+// NewService creates the exchange service. Connections are unavailable when cipher is nil.
+func NewService(repo exchange.Repository, connections exchange.ConnectionRepository, portfolios portfolio.Repository, parsers ParserFactory, connectors map[exchange.Exchange]exchange.Connector, cipher exchange.CredentialCipher, tokens TokenResolver, aliases *token.AliasRegistry, recorder audit.Recorder, logger *loggeradapter.Logger) *Service {
+	if logger == nil {
+		logger = loggeradapter.NewNopLogger()
+	}
+	if recorder == nil {
+		recorder = audit.NopRecorder{}
+	}
+	return &Service{
+		repo:        repo,
+		connections: connections,
+		portfolios:  portfolios,
+		parsers:     parsers,
+		connectors:  connectors,
+		cipher:      cipher,
+		tokens:      tokens,
+		aliases:     aliases,
+		recorder:    recorder,
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+// statementSnapshot is the audit log representation of an import
+type statementSnapshot struct {
+	Exchange   string `json:"exchange"`
+	Parsed     int    `json:"parsed"`
+	Imported   int    `json:"imported"`
+	Duplicates int    `json:"duplicates"`
+}
+
+// ImportStatement parses an exchange export and stores its records for the portfolio.
+// Rows imported before are skipped, so overlapping statements can be imported again.
+func (s *Service) ImportStatement(ctx context.Context, portfolioID string, e exchange.Exchange, mapping *exchange.Mapping, body io.Reader) (*exchange.ImportResult, error) {
+	p, err := s.portfolios.GetByID(ctx, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	if p.Archived {
+		s.logger.Warn("Attempted to import statement into archived portfolio", zap.String("portfolio_id", portfolioID))
+		return nil, portfolio.ErrPortfolioArchived
+	}
+
+	parser, err := s.parsers(e, mapping)
+	if err != nil {
+		return nil, err
+	}
+	records, err := parser.Parse(body)
+	if err != nil {
+		s.logger.Warn("Failed to parse exchange statement", zap.String("portfolio_id", portfolioID), zap.String("exchange", string(e)), zap.Error(err))
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: no records", exchange.ErrInvalidStatement)
+	}
+
+	exchange.Assign(records, portfolioID, s.now())
+	imported, err := s.repo.SaveRecords(ctx, records)
+	if err != nil {
+		s.logger.Error("Failed to save exchange records", zap.String("portfolio_id", portfolioID), zap.Error(err))
+		return nil, err
+	}
+
+	result := &exchange.ImportResult{
+		Exchange:   e,
+		Parsed:     len(records),
+		Imported:   imported,
+		Duplicates: len(records) - imported,
+	}
+	s.logger.Info("Imported exchange statement",
+		zap.String("portfolio_id", portfolioID),
+		zap.String("exchange", string(e)),
+		zap.Int("parsed", result.Parsed),
+		zap.Int("imported", result.Imported))
+	if imported > 0 {
+		s.recorder.Record(ctx, audit.NewEntry(audit.ActionStatementImport, audit.ResourceStatement, string(e), portfolioID), nil, &statementSnapshot{
+			Exchange:   string(e),
+			Parsed:     result.Parsed,
+			Imported:   result.Imported,
+			Duplicates: result.Duplicates,
+		})
+	}
+	return result, nil
+}
+
+// ListRecords returns the portfolio's imported records, oldest first
+func (s *Service) ListRecords(ctx context.Context, portfolioID string, filter exchange.Filter) ([]*exchange.Record, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := s.portfolios.GetByID(ctx, portfolioID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListRecords(ctx, portfolioID, filter)
+}
+
+// StatementTxsByPortfolio implements transaction.StatementProvider. Records whose asset
+// does not resolve to a token, such as fiat balances, are left out.
+func (s *Service) StatementTxsByPortfolio(ctx context.Context, portfolioID string) ([]*transaction.Transaction, error) {
+	records, err := s.repo.ListRecords(ctx, portfolioID, exchange.Filter{})
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make(map[string]*token.Token)
+	var txs []*transaction.Transaction
+	for _, rec := range records {
+		tok, seen := resolved[rec.Asset]
+		if !seen {
+			tok = s.resolveAsset(ctx, rec.Asset)
+			resolved[rec.Asset] = tok
+			if tok == nil {
+				s.logger.Debug("Exchange asset has no token, leaving it out of valuation", zap.String("portfolio_id", portfolioID), zap.String("asset", rec.Asset))
+			}
+		}
+		if tok == nil {
+			continue
+		}
+		if tx := toTransaction(rec, tok); tx != nil {
+			txs = append(txs, tx)
+		}
+	}
+	return txs, nil
+}
+
+// resolveAsset finds the token an exchange ticker is valued as: the chain's native asset,
+// then the first member of an equivalence group with that symbol (e.g. WBTC for BTC),
+// then a registry token with that exact symbol
+func (s *Service) resolveAsset(ctx context.Context, asset string) *token.Token {
+	if native, ok := s.aliases.Native(chainID); ok && strings.EqualFold(native.Symbol, asset) {
+		tok, _ := s.aliases.NativeToken(chainID)
+		return tok
+	}
+	if g, ok := s.aliases.GroupBySymbol(chainID, asset); ok {
+		for _, member := range g.Members {
+			if member == token.ZeroAddress {
+				continue
+			}
+			if tok, ok := s.tokens.GetTokenByAddress(ctx, member); ok {
+				return tok
+			}
+		}
+	}
+	if tok, ok := s.tokens.GetTokenBySymbol(ctx, asset); ok {
+		return tok
+	}
+	return nil
+}
+
+// toTransaction converts a record to the token's base units. Digits below the token's
+// precision are dropped; a record that rounds to zero is left out.
+func toTransaction(rec *exchange.Record, tok *token.Token) *transaction.Transaction {
+	amount := new(big.Int).Abs(rec.Amount)
+	shift := int64(tok.Decimal) - exchange.AmountDecimals
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(abs(shift)), nil)
+	if shift < 0 {
+		amount.Quo(amount, scale)
+	} else {
+		amount.Mul(amount, scale)
+	}
+	if amount.Sign() == 0 {
+		return nil
+	}
+
+	decimals := tok.Decimal
+	tx := &transaction.Transaction{
+		ID:            rec.ID,
+		Hash:          rec.ExternalID,
+		TokenAddress:  tok.Address,
+		TokenSymbol:   tok.Symbol,
+		TokenName:     tok.Name,
+		TokenDecimal:  &decimals,
+		Amount:        amount,
+		Status:        transaction.TransactionStatusSuccess,
+		Method:        string(rec.Kind),
+		Direction:     transaction.TransactionDirectionIn,
+		Timestamp:     rec.OccurredAt,
+		Source:        string(rec.Exchange),
+		Price:         rec.Price,
+		PriceCurrency: rec.Currency,
+	}
+	if rec.Amount.Sign() < 0 {
+		tx.Direction = transaction.TransactionDirectionOut
+	}
+	switch rec.Kind {
+	case exchange.KindTrade:
+		tx.Type = transaction.TransactionTypeSwap
+	case exchange.KindDeposit:
+		tx.Type = transaction.TransactionTypeReceive
+	default:
+		tx.Type = transaction.TransactionTypeSend
+	}
+	return tx
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,117 @@
+package exchange
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"testtask/internal/domain/exchange"
+	"testtask/internal/domain/token"
+	"testtask/internal/domain/transaction"
+)
+
+const wbtcAddress = "0x2260fac5e5542a773aa44fbcfedf7c193bc2c599"
+
+// mockRecordRepository implements exchange.Repository in memory
+type mockRecordRepository struct {
+	records []*exchange.Record
+}
+
+func (m *mockRecordRepository) SaveRecords(_ context.Context, records []*exchange.Record) (int, error) {
+	m.records = append(m.records, records...)
+	return len(records), nil
+}
+
+func (m *mockRecordRepository) ListRecords(context.Context, string, exchange.Filter) ([]*exchange.Record, error) {
+	return m.records, nil
+}
+
+// mockTokenResolver resolves a fixed token list by address and symbol
+type mockTokenResolver struct {
+	tokens []*token.Token
+}
+
+func (m *mockTokenResolver) GetTokenByAddress(_ context.Context, address string) (*token.Token, bool) {
+	for _, t := range m.tokens {
+		if strings.EqualFold(t.Address, address) {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+func (m *mockTokenResolver) GetTokenBySymbol(_ context.Context, symbol string) (*token.Token, bool) {
+	for _, t := range m.tokens {
+		if strings.EqualFold(t.Symbol, symbol) {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// units scales whole asset units by AmountDecimals
+func units(n int64, exp int64) *big.Int {
+	v := new(big.Int).Exp(big.NewInt(10), big.NewInt(exchange.AmountDecimals-exp), nil)
+	return v.Mul(v, big.NewInt(n))
+}
+
+func TestService_StatementTxsByPortfolio(t *testing.T) {
+	aliases, err := token.NewAliasRegistry([]*token.ChainAliases{{
+		ChainID: "1",
+		Native:  &token.NativeAsset{ID: "ethereum", Name: "Ethereum", Symbol: "ETH", Decimal: 18, PriceProxy: "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"},
+		Groups: []*token.AliasGroup{{ID: "btc", Symbol: "BTC", Members: []string{
+			wbtcAddress, "0xcbb7c0000ab88b473b1f5afd9ef808440eed33bf",
+		}}},
+	}})
+	if err != nil {
+		t.Fatalf("NewAliasRegistry() error = %v", err)
+	}
+	tokens := &mockTokenResolver{tokens: []*token.Token{
+		{Symbol: "WBTC", Address: wbtcAddress, Decimal: 8},
+		{Symbol: "USDC", Address: "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", Decimal: 6},
+	}}
+
+	at := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	repo := &mockRecordRepository{records: []*exchange.Record{
+		{ID: "1", Exchange: exchange.Kraken, Kind: exchange.KindTrade, Asset: "BTC", Amount: units(15, 1), Price: big.NewInt(4000000000000), Currency: "usd", OccurredAt: at},
+		{ID: "2", Exchange: exchange.Kraken, Kind: exchange.KindTrade, Asset: "USD", Amount: units(-6000, 0), OccurredAt: at},
+		{ID: "3", Exchange: exchange.Kraken, Kind: exchange.KindWithdrawal, Asset: "ETH", Amount: units(-2, 0), OccurredAt: at},
+		{ID: "4", Exchange: exchange.Kraken, Kind: exchange.KindFee, Asset: "USDC", Amount: big.NewInt(-1), OccurredAt: at}, // below USDC precision
+		{ID: "5", Exchange: exchange.Kraken, Kind: exchange.KindDeposit, Asset: "USDC", Amount: units(25, 1), OccurredAt: at},
+	}}
+	service := NewService(repo, nil, nil, nil, nil, nil, tokens, aliases, nil, nil)
+
+	txs, err := service.StatementTxsByPortfolio(context.Background(), "portfolio-1")
+	if err != nil {
+		t.Fatalf("StatementTxsByPortfolio() error = %v", err)
+	}
+
+	want := []struct {
+		id        string
+		address   string
+		amount    string
+		direction transaction.TransactionDirection
+		txType    transaction.TransactionType
+	}{
+		{id: "1", address: wbtcAddress, amount: "150000000", direction: transaction.TransactionDirectionIn, txType: transaction.TransactionTypeSwap},
+		{id: "3", address: token.ZeroAddress, amount: "2000000000000000000", direction: transaction.TransactionDirectionOut, txType: transaction.TransactionTypeSend},
+		{id: "5", address: "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", amount: "2500000", direction: transaction.TransactionDirectionIn, txType: transaction.TransactionTypeReceive},
+	}
+	if len(txs) != len(want) {
+		t.Fatalf("StatementTxsByPortfolio() returned %d transactions, want %d", len(txs), len(want))
+	}
+	for i, w := range want {
+		tx := txs[i]
+		if tx.ID != w.id || tx.TokenAddress != w.address || tx.Amount.String() != w.amount || tx.Direction != w.direction || tx.Type != w.txType {
+			t.Errorf("txs[%d] = {%s %s %s %s %s}, want %+v", i, tx.ID, tx.TokenAddress, tx.Amount, tx.Direction, tx.Type, w)
+		}
+		if tx.Source != string(exchange.Kraken) {
+			t.Errorf("txs[%d].Source = %q, want kraken", i, tx.Source)
+		}
+	}
+	if txs[0].Price == nil || txs[0].PriceCurrency != "usd" {
+		t.Errorf("txs[0] price = %v %q, want the trade price in usd", txs[0].Price, txs[0].PriceCurrency)
+	}
+}
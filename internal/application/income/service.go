@@ -0,0 +1,255 @@
+package income
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	loggeradapter "testtask/internal/adapters/logger"
+	"testtask/internal/domain/audit"
+	"testtask/internal/domain/income"
+	domainPortfolio "testtask/internal/domain/portfolio"
+	"testtask/internal/domain/transaction"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Service classifies incoming transfers as income by rules and by hand, and records the
+// growth of rebasing token balances as income
+type Service struct {
+	rules      *income.RuleSet
+	repo       income.Repository
+	portfolios domainPortfolio.Repository
+	provider   transaction.Provider
+	recorder   audit.Recorder
+	logger     *loggeradapter.Logger
+	now        func() time.Time
+}
+
+func NewService(rules *income.RuleSet, repo income.Repository, portfolios domainPortfolio.Repository, provider transaction.Provider, recorder audit.Recorder, logger *loggeradapter.Logger) *Service {
+	if logger == nil {
+		logger = loggeradapter.NewNopLogger()
+	}
+	if recorder == nil {
+		recorder = audit.NopRecorder{}
+	}
+	return &Service{
+		rules:      rules,
+		repo:       repo,
+		portfolios: portfolios,
+		provider:   provider,
+		recorder:   recorder,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// Classify sets Income on the transactions; an empty portfolioID applies the rules only
+func (s *Service) Classify(ctx context.Context, portfolioID string, txs []*transaction.Transaction) error {
+	var classifications []*income.Classification
+	if portfolioID != "" {
+		var err error
+		if classifications, err = s.repo.ListClassifications(ctx, portfolioID); err != nil {
+			return err
+		}
+	}
+	income.Apply(txs, s.rules, classifications)
+	return nil
+}
+
+// ListClassifications returns the categories set by hand on the portfolio's transactions
+func (s *Service) ListClassifications(ctx context.Context, portfolioID string) ([]*income.Classification, error) {
+	if _, err := s.portfolios.GetByID(ctx, portfolioID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListClassifications(ctx, portfolioID)
+}
+
+// SetClassification sets the income category of a transaction of the portfolio
+func (s *Service) SetClassification(ctx context.Context, c *income.Classification) error {
+	if _, err := s.mutablePortfolio(ctx, c.PortfolioID); err != nil {
+		return err
+	}
+	c.TransactionID = strings.TrimSpace(c.TransactionID)
+	if c.TransactionID == "" {
+		return fmt.Errorf("%w: transaction_id is required", income.ErrInvalidClassification)
+	}
+	category, err := income.ParseCategory(string(c.Category))
+	if err != nil {
+		return err
+	}
+	c.Category = category
+	c.Note = strings.TrimSpace(c.Note)
+	c.UpdatedAt = s.now().UTC()
+
+	before := s.classification(ctx, c.PortfolioID, c.TransactionID)
+	if err := s.repo.SetClassification(ctx, c); err != nil {
+		s.logger.Error("Failed to set income classification", zap.String("portfolio_id", c.PortfolioID), zap.String("transaction_id", c.TransactionID), zap.Error(err))
+		return err
+	}
+
+	s.logger.Info("Set income classification", zap.String("portfolio_id", c.PortfolioID), zap.String("transaction_id", c.TransactionID), zap.String("category", string(c.Category)))
+	s.recorder.Record(ctx, audit.NewEntry(audit.ActionIncomeClassify, audit.ResourceIncome, c.TransactionID, c.PortfolioID), snapshotClassification(before), snapshotClassification(c))
+	return nil
+}
+
+// DeleteClassification removes the category set by hand, so the rules apply again
+func (s *Service) DeleteClassification(ctx context.Context, portfolioID, transactionID string) error {
+	if _, err := s.mutablePortfolio(ctx, portfolioID); err != nil {
+		return err
+	}
+	before := s.classification(ctx, portfolioID, transactionID)
+	if err := s.repo.DeleteClassification(ctx, portfolioID, transactionID); err != nil {
+		return err
+	}
+
+	s.logger.Info("Deleted income classification", zap.String("portfolio_id", portfolioID), zap.String("transaction_id", transactionID))
+	s.recorder.Record(ctx, audit.NewEntry(audit.ActionIncomeUnclassify, audit.ResourceIncome, transactionID, portfolioID), snapshotClassification(before), nil)
+	return nil
+}
+
+// Accruals returns the rebasing token growth recorded as income of the portfolio
+func (s *Service) Accruals(ctx context.Context, portfolioID string) ([]*income.Accrual, error) {
+	return s.repo.ListAccruals(ctx, portfolioID)
+}
+
+// ObserveRebases records, for each rebasing token the portfolio address holds, the growth
+// of its balance beyond transfers and earlier accruals as a new accrual
+func (s *Service) ObserveRebases(ctx context.Context, portfolioID string) ([]*income.Accrual, error) {
+	p, err := s.portfolios.GetByID(ctx, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	return s.observe(ctx, p)
+}
+
+// ObserveAll observes the rebases of every active portfolio with an address
+func (s *Service) ObserveAll(ctx context.Context) error {
+	if len(s.rules.RebasingTokens()) == 0 {
+		return nil
+	}
+	portfolios, err := s.portfolios.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, p := range portfolios {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if p.Archived || p.Address == "" {
+			continue
+		}
+		if _, err := s.observe(ctx, p); err != nil {
+			errs = append(errs, fmt.Errorf("portfolio %s: %w", p.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Service) observe(ctx context.Context, p *domainPortfolio.Portfolio) ([]*income.Accrual, error) {
+	rebasing := s.rules.RebasingTokens()
+	if p.Address == "" || len(rebasing) == 0 {
+		return nil, nil
+	}
+
+	txs, err := s.provider.TokenTxsByAddress(ctx, p.Address, transaction.FilterOptions{Address: p.Address})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load token transfers: %w", err)
+	}
+	for _, tx := range txs {
+		tx.SetDirectionForAddress(p.Address)
+	}
+	history := transaction.Transactions(txs)
+	transferred, err := history.CalculateTokensAmounts()
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.repo.ListAccruals(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	accrued := make(map[string]*big.Int)
+	for _, a := range existing {
+		if accrued[a.TokenAddress] == nil {
+			accrued[a.TokenAddress] = new(big.Int)
+		}
+		accrued[a.TokenAddress].Add(accrued[a.TokenAddress], a.Quantity)
+	}
+
+	var recorded []*income.Accrual
+	for _, t := range rebasing {
+		// Tokens never received have no balance to grow
+		if transferred[t.Address] == nil && accrued[t.Address] == nil {
+			continue
+		}
+		balance, err := s.provider.TokenBalance(ctx, p.Address, t.Address)
+		if err != nil {
+			return recorded, fmt.Errorf("failed to get %s balance: %w", t.Symbol, err)
+		}
+		delta := income.RebaseDelta(balance, transferred[t.Address], accrued[t.Address])
+		if delta == nil {
+			continue
+		}
+
+		a := &income.Accrual{
+			ID:           uuid.New().String(),
+			PortfolioID:  p.ID,
+			TokenAddress: t.Address,
+			Symbol:       t.Symbol,
+			Decimals:     t.Decimals,
+			Quantity:     delta,
+			Category:     t.Category,
+			ObservedAt:   s.now().UTC(),
+		}
+		if err := s.repo.SaveAccrual(ctx, a); err != nil {
+			return recorded, err
+		}
+		recorded = append(recorded, a)
+		s.logger.Info("Recorded rebase income", zap.String("portfolio_id", p.ID), zap.String("token", t.Symbol), zap.String("quantity", delta.String()))
+	}
+	return recorded, nil
+}
+
+func (s *Service) mutablePortfolio(ctx context.Context, portfolioID string) (*domainPortfolio.Portfolio, error) {
+	p, err := s.portfolios.GetByID(ctx, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	if p.Archived {
+		return nil, domainPortfolio.ErrPortfolioArchived
+	}
+	return p, nil
+}
+
+// classification returns the current classification of a transaction, nil if none
+func (s *Service) classification(ctx context.Context, portfolioID, transactionID string) *income.Classification {
+	classifications, err := s.repo.ListClassifications(ctx, portfolioID)
+	if err != nil {
+		return nil
+	}
+	for _, c := range classifications {
+		if c.TransactionID == transactionID {
+			return c
+		}
+	}
+	return nil
+}
+
+type classificationSnapshot struct {
+	TransactionID string `json:"transaction_id"`
+	Category      string `json:"category"`
+	Note          string `json:"note,omitempty"`
+}
+
+func snapshotClassification(c *income.Classification) *classificationSnapshot {
+	if c == nil {
+		return nil
+	}
+	return &classificationSnapshot{TransactionID: c.TransactionID, Category: string(c.Category), Note: c.Note}
+}
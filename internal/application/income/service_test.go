@@ -0,0 +1,167 @@
+package income
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"testtask/internal/domain/income"
+	domainPortfolio "testtask/internal/domain/portfolio"
+	"testtask/internal/domain/transaction"
+)
+
+const (
+	walletAddress = "0x1111111111111111111111111111111111111111"
+	stethAddress  = "0xae7ab96520de3a18e5e111b5eaab095312d7fe84"
+)
+
+// mockRepository implements income.Repository in memory
+type mockRepository struct {
+	classifications map[string]*income.Classification
+	accruals        []*income.Accrual
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{classifications: make(map[string]*income.Classification)}
+}
+
+func (m *mockRepository) ListClassifications(context.Context, string) ([]*income.Classification, error) {
+	var result []*income.Classification
+	for _, c := range m.classifications {
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+func (m *mockRepository) SetClassification(_ context.Context, c *income.Classification) error {
+	m.classifications[c.TransactionID] = c
+	return nil
+}
+
+func (m *mockRepository) DeleteClassification(_ context.Context, _ string, transactionID string) error {
+	if _, ok := m.classifications[transactionID]; !ok {
+		return income.ErrClassificationNotFound
+	}
+	delete(m.classifications, transactionID)
+	return nil
+}
+
+func (m *mockRepository) ListAccruals(context.Context, string) ([]*income.Accrual, error) {
+	return m.accruals, nil
+}
+
+func (m *mockRepository) SaveAccrual(_ context.Context, a *income.Accrual) error {
+	m.accruals = append(m.accruals, a)
+	return nil
+}
+
+// mockPortfolioRepository serves one portfolio; other methods are not used by the service
+type mockPortfolioRepository struct {
+	domainPortfolio.Repository
+	portfolio *domainPortfolio.Portfolio
+}
+
+func (m *mockPortfolioRepository) GetByID(_ context.Context, id string) (*domainPortfolio.Portfolio, error) {
+	if id != m.portfolio.ID {
+		return nil, domainPortfolio.ErrPortfolioNotFound
+	}
+	return m.portfolio, nil
+}
+
+// mockProvider serves token transfers and a settable token balance
+type mockProvider struct {
+	transaction.Provider
+	tokenTxs []*transaction.Transaction
+	balance  *big.Int
+}
+
+func (m *mockProvider) TokenTxsByAddress(context.Context, string, transaction.FilterOptions) ([]*transaction.Transaction, error) {
+	return m.tokenTxs, nil
+}
+
+func (m *mockProvider) TokenBalance(context.Context, string, string) (*big.Int, error) {
+	return m.balance, nil
+}
+
+func newTestService(t *testing.T, archived bool, provider transaction.Provider) (*Service, *mockRepository) {
+	t.Helper()
+	rules, err := income.NewRuleSet(nil, []*income.RebasingToken{
+		{Address: stethAddress, Symbol: "stETH", Decimals: 18, Category: transaction.IncomeStakingReward},
+	})
+	if err != nil {
+		t.Fatalf("NewRuleSet() error = %v", err)
+	}
+	repo := newMockRepository()
+	portfolios := &mockPortfolioRepository{portfolio: &domainPortfolio.Portfolio{ID: "portfolio-1", Address: walletAddress, Archived: archived}}
+	return NewService(rules, repo, portfolios, provider, nil, nil), repo
+}
+
+func TestService_ObserveRebases(t *testing.T) {
+	provider := &mockProvider{
+		tokenTxs: []*transaction.Transaction{
+			{ID: "in", From: "0xexchange", To: walletAddress, TokenAddress: stethAddress, Amount: big.NewInt(1000)},
+			{ID: "out", From: walletAddress, To: "0xfriend", TokenAddress: stethAddress, Amount: big.NewInt(200)},
+		},
+		balance: big.NewInt(850),
+	}
+	svc, repo := newTestService(t, false, provider)
+	ctx := context.Background()
+
+	recorded, err := svc.ObserveRebases(ctx, "portfolio-1")
+	if err != nil {
+		t.Fatalf("ObserveRebases() error = %v", err)
+	}
+	if len(recorded) != 1 || recorded[0].Quantity.Int64() != 50 || recorded[0].Category != transaction.IncomeStakingReward {
+		t.Fatalf("ObserveRebases() = %+v, want 50 of stETH beyond the net 800 transferred", recorded)
+	}
+
+	// Growth already recorded is not recorded again
+	provider.balance = big.NewInt(870)
+	if recorded, err = svc.ObserveRebases(ctx, "portfolio-1"); err != nil || len(recorded) != 1 || recorded[0].Quantity.Int64() != 20 {
+		t.Errorf("second ObserveRebases() = %+v, %v, want only the further 20", recorded, err)
+	}
+	if len(repo.accruals) != 2 {
+		t.Errorf("stored accruals = %d, want 2", len(repo.accruals))
+	}
+}
+
+func TestService_SetClassification(t *testing.T) {
+	svc, _ := newTestService(t, false, nil)
+	ctx := context.Background()
+
+	c := &income.Classification{PortfolioID: "portfolio-1", TransactionID: " 0xabc ", Category: "Salary"}
+	if err := svc.SetClassification(ctx, c); err != nil {
+		t.Fatalf("SetClassification() error = %v", err)
+	}
+	if c.TransactionID != "0xabc" || c.Category != transaction.IncomeSalary || c.UpdatedAt.IsZero() {
+		t.Errorf("classification = %+v, want a normalized salary classification", c)
+	}
+
+	txs := []*transaction.Transaction{{ID: "0xabc", Direction: transaction.TransactionDirectionIn}}
+	if err := svc.Classify(ctx, "portfolio-1", txs); err != nil || txs[0].Income != transaction.IncomeSalary {
+		t.Errorf("Classify() income = %q, %v, want salary", txs[0].Income, err)
+	}
+	if err := svc.Classify(ctx, "", txs); err != nil || txs[0].Income != "" {
+		t.Errorf("Classify(rules only) income = %q, %v, want none", txs[0].Income, err)
+	}
+
+	tests := []struct {
+		name     string
+		archived bool
+		c        *income.Classification
+		wantErr  error
+	}{
+		{"unknown category", false, &income.Classification{PortfolioID: "portfolio-1", TransactionID: "0xabc", Category: "gift"}, income.ErrInvalidClassification},
+		{"missing transaction", false, &income.Classification{PortfolioID: "portfolio-1", Category: transaction.IncomeAirdrop}, income.ErrInvalidClassification},
+		{"archived portfolio", true, &income.Classification{PortfolioID: "portfolio-1", TransactionID: "0xabc", Category: transaction.IncomeAirdrop}, domainPortfolio.ErrPortfolioArchived},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _ := newTestService(t, tt.archived, nil)
+			if err := svc.SetClassification(ctx, tt.c); !errors.Is(err, tt.wantErr) {
+				t.Errorf("SetClassification() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package portfolio
+
+import (
+	domainHolding "testtask/internal/domain/holding"
+	domainPortfolio "testtask/internal/domain/portfolio"
+)
+
+// portfolioSnapshot is the audit log representation of a portfolio
+type portfolioSnapshot struct {
+	ID          string            `json:"id"`
+	OwnerID     string            `json:"owner_id,omitempty"`
+	Address     string            `json:"address"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Tags        []string          `json:"tags"`
+	Archived    bool              `json:"archived"`
+	Holdings    []holdingSnapshot `json:"holdings,omitempty"`
+}
+
+// holdingSnapshot is the audit log representation of a holding
+type holdingSnapshot struct {
+	ID           string `json:"id"`
+	TokenAddress string `json:"token_address"`
+	TokenSymbol  string `json:"token_symbol"`
+	Amount       string `json:"amount"`
+}
+
+// snapshotPortfolio captures the portfolio metadata; holdings are included when requested,
+// for deletions
+func snapshotPortfolio(p *domainPortfolio.Portfolio, withHoldings bool) *portfolioSnapshot {
+	s := &portfolioSnapshot{
+		ID:          p.ID,
+		OwnerID:     p.OwnerID,
+		Address:     p.Address,
+		Name:        p.Name,
+		Description: p.Description,
+		Tags:        append([]string(nil), p.Tags...),
+		Archived:    p.Archived,
+	}
+	if withHoldings {
+		for _, h := range p.Holdings {
+			s.Holdings = append(s.Holdings, *snapshotHolding(h))
+		}
+	}
+	return s
+}
+
+func snapshotHolding(h *domainHolding.Holding) *holdingSnapshot {
+	s := &holdingSnapshot{ID: h.ID}
+	if h.Token != nil {
+		s.TokenAddress = h.Token.Address
+		s.TokenSymbol = h.Token.Symbol
+	}
+	if h.Amount != nil {
+		s.Amount = h.Amount.String()
+	}
+	return s
+}
+
+// manualEntrySnapshot is the audit log representation of a manual asset or liability
+type manualEntrySnapshot struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Side         string `json:"side"`
+	Category     string `json:"category"`
+	Valuation    string `json:"valuation"`
+	Currency     string `json:"currency,omitempty"`
+	Value        string `json:"value,omitempty"`
+	Quantity     string `json:"quantity,omitempty"`
+	UnitPrice    string `json:"unit_price,omitempty"`
+	TokenAddress string `json:"token_address,omitempty"`
+}
+
+func snapshotManualEntry(e *domainPortfolio.ManualEntry) *manualEntrySnapshot {
+	s := &manualEntrySnapshot{
+		ID:           e.ID,
+		Name:         e.Name,
+		Side:         string(e.Side),
+		Category:     string(e.Category),
+		Valuation:    string(e.Valuation),
+		Currency:     e.Currency,
+		TokenAddress: e.TokenAddress,
+	}
+	if e.Value != nil {
+		s.Value = e.Value.String()
+	}
+	if e.Quantity != nil {
+		s.Quantity = e.Quantity.String()
+	}
+	if e.UnitPrice != nil {
+		s.UnitPrice = e.UnitPrice.String()
+	}
+	return s
+}
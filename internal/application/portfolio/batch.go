@@ -0,0 +1,176 @@
+package portfolio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"testtask/internal/domain/audit"
+	domainHolding "testtask/internal/domain/holding"
+	domainPortfolio "testtask/internal/domain/portfolio"
+
+	"go.uber.org/zap"
+)
+
+// errDryRun rolls back the unit of work of a dry run
+var errDryRun = errors.New("dry run")
+
+// holdingChange is an applied batch operation, recorded in the audit log after commit
+type holdingChange struct {
+	action    string
+	holdingID string
+	before    *holdingSnapshot
+	after     *holdingSnapshot
+}
+
+// ApplyHoldingBatch applies the operations in order in one unit of work. Every operation
+// is attempted so the results report all failures; if any fails the whole batch is rolled
+// back and ErrBatchFailed is returned along with the results. A dry run reports the same
+// results and always rolls back.
+func (s *Service) ApplyHoldingBatch(ctx context.Context, portfolioID string, ops []*domainHolding.BatchOperation, dryRun bool) ([]*domainHolding.BatchResult, error) {
+	switch {
+	case len(ops) == 0:
+		return nil, fmt.Errorf("%w: no operations", domainHolding.ErrInvalidBatch)
+	case len(ops) > domainHolding.MaxBatchSize:
+		return nil, fmt.Errorf("%w: %d operations exceed the limit of %d", domainHolding.ErrInvalidBatch, len(ops), domainHolding.MaxBatchSize)
+	}
+
+	s.logger.Info("Applying holding batch", zap.String("portfolio_id", portfolioID), zap.Int("operations", len(ops)), zap.Bool("dry_run", dryRun))
+
+	var (
+		results []*domainHolding.BatchResult
+		changes []holdingChange
+		failed  int
+	)
+	err := s.uow.WithinTx(ctx, func(ctx context.Context) error {
+		p, err := s.getMutablePortfolio(ctx, portfolioID, "applying holding batch")
+		if err != nil {
+			return err
+		}
+
+		results = make([]*domainHolding.BatchResult, len(ops))
+		for i, op := range ops {
+			result := &domainHolding.BatchResult{Index: i, Op: op.Op, Status: domainHolding.BatchApplied}
+			change, err := s.applyBatchOperation(ctx, p, op)
+			if err != nil {
+				result.Status = domainHolding.BatchFailed
+				result.Err = err
+				failed++
+			} else {
+				// Later operations may change the same holding
+				if change.holding != nil {
+					held := *change.holding
+					held.Amount = new(big.Int).Set(change.holding.Amount)
+					result.Holding = &held
+				}
+				if change.action != "" {
+					changes = append(changes, change.holdingChange)
+				}
+			}
+			results[i] = result
+		}
+
+		switch {
+		case failed > 0:
+			return fmt.Errorf("%w: %d of %d operations failed", domainHolding.ErrBatchFailed, failed, len(ops))
+		case dryRun:
+			return errDryRun
+		}
+		return nil
+	})
+	if errors.Is(err, domainHolding.ErrBatchFailed) {
+		for _, result := range results {
+			if result.Status == domainHolding.BatchApplied {
+				result.Status = domainHolding.BatchRolledBack
+			}
+		}
+		s.logger.Warn("Rolled back holding batch", zap.String("portfolio_id", portfolioID), zap.Int("failed", failed), zap.Bool("dry_run", dryRun))
+		return results, err
+	}
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+	if dryRun {
+		return results, nil
+	}
+
+	s.logger.Info("Successfully applied holding batch", zap.String("portfolio_id", portfolioID), zap.Int("operations", len(ops)), zap.Int("changes", len(changes)))
+	for _, change := range changes {
+		s.recordHolding(ctx, change.action, portfolioID, change.holdingID, change.before, change.after)
+	}
+	return results, nil
+}
+
+// batchChange is the holding after an operation and the change to record; action is
+// empty when the operation did not change anything
+type batchChange struct {
+	holdingChange
+	holding *domainHolding.Holding
+}
+
+func (s *Service) applyBatchOperation(ctx context.Context, p *domainPortfolio.Portfolio, op *domainHolding.BatchOperation) (batchChange, error) {
+	if err := op.Validate(); err != nil {
+		return batchChange{}, err
+	}
+
+	switch op.Op {
+	case domainHolding.BatchAdd:
+		return s.batchDeposit(ctx, p, op)
+
+	case domainHolding.BatchSet:
+		h := s.FindHoldingByToken(p, op.Token.Address)
+		if h == nil {
+			if op.Amount.Sign() == 0 {
+				return batchChange{}, nil
+			}
+			return s.batchDeposit(ctx, p, op)
+		}
+		if op.Version != 0 && op.Version != h.Version {
+			return batchChange{}, fmt.Errorf("%w: holding_id=%s has version %d, expected %d", domainHolding.ErrVersionConflict, h.ID, h.Version, op.Version)
+		}
+		return s.batchSetAmount(ctx, p, h, op)
+
+	case domainHolding.BatchUpdate:
+		h, err := s.findVersionedHolding(p, op.HoldingID, op.Version)
+		if err != nil {
+			return batchChange{}, err
+		}
+		return s.batchSetAmount(ctx, p, h, op)
+
+	default:
+		h, err := s.findVersionedHolding(p, op.HoldingID, op.Version)
+		if err != nil {
+			return batchChange{}, err
+		}
+		before, err := s.removeHolding(ctx, p, h)
+		if err != nil {
+			return batchChange{}, err
+		}
+		return batchChange{holdingChange: holdingChange{action: audit.ActionHoldingDelete, holdingID: h.ID, before: before}}, nil
+	}
+}
+
+func (s *Service) batchDeposit(ctx context.Context, p *domainPortfolio.Portfolio, op *domainHolding.BatchOperation) (batchChange, error) {
+	h, before, err := s.depositHolding(ctx, p, op.Token, op.Amount)
+	if err != nil {
+		return batchChange{}, err
+	}
+	action := audit.ActionHoldingUpdate
+	if before == nil {
+		action = audit.ActionHoldingCreate
+	}
+	return batchChange{holdingChange: holdingChange{action: action, holdingID: h.ID, before: before, after: snapshotHolding(h)}, holding: h}, nil
+}
+
+func (s *Service) batchSetAmount(ctx context.Context, p *domainPortfolio.Portfolio, h *domainHolding.Holding, op *domainHolding.BatchOperation) (batchChange, error) {
+	before, err := s.setHoldingAmount(ctx, p, h, op.Amount)
+	if err != nil {
+		return batchChange{}, err
+	}
+	change := batchChange{holding: h}
+	if before != nil {
+		change.holdingChange = holdingChange{action: audit.ActionHoldingUpdate, holdingID: h.ID, before: before, after: snapshotHolding(h)}
+	}
+	return change, nil
+}
@@ -0,0 +1,182 @@
+package portfolio
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	portfoliorepo "testtask/internal/adapters/portfolio"
+	sqliteadapter "testtask/internal/adapters/sqlite"
+	domainHolding "testtask/internal/domain/holding"
+	domainPortfolio "testtask/internal/domain/portfolio"
+	"testtask/internal/domain/token"
+	"testtask/internal/domain/user"
+)
+
+const batchTestSchema = `
+CREATE TABLE portfolios (
+	id TEXT PRIMARY KEY,
+	owner_id TEXT,
+	address TEXT NOT NULL,
+	name TEXT NOT NULL DEFAULT '',
+	description TEXT NOT NULL DEFAULT '',
+	tags TEXT NOT NULL DEFAULT '[]',
+	archived INTEGER NOT NULL DEFAULT 0,
+	created_at DATETIME,
+	updated_at DATETIME NOT NULL
+);
+
+CREATE TABLE holdings (
+	id TEXT PRIMARY KEY,
+	portfolio_id TEXT NOT NULL,
+	chain_id INTEGER NOT NULL,
+	token_id TEXT NOT NULL,
+	token_symbol TEXT NOT NULL,
+	token_address TEXT NOT NULL,
+	amount TEXT NOT NULL,
+	version INTEGER NOT NULL DEFAULT 1,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL
+);
+CREATE UNIQUE INDEX idx_portfolios_owner_address ON portfolios(owner_id, address);
+CREATE UNIQUE INDEX idx_holdings_portfolio_token ON holdings(portfolio_id, lower(token_address));
+
+CREATE TABLE holding_ledger (
+	id TEXT PRIMARY KEY,
+	portfolio_id TEXT NOT NULL,
+	holding_id TEXT NOT NULL,
+	chain_id INTEGER NOT NULL,
+	token_id TEXT NOT NULL,
+	token_symbol TEXT NOT NULL,
+	token_address TEXT NOT NULL,
+	entry_type TEXT NOT NULL,
+	quantity TEXT NOT NULL,
+	price TEXT,
+	currency TEXT NOT NULL DEFAULT '',
+	note TEXT NOT NULL DEFAULT '',
+	counterparty TEXT NOT NULL DEFAULT '',
+	occurred_at DATETIME NOT NULL,
+	created_at DATETIME NOT NULL
+);
+`
+
+func setupBatchTest(t *testing.T) (*Service, *portfoliorepo.SQLiteRepository, *domainHolding.Holding) {
+	t.Helper()
+	dbPath := filepath.Join(t.TempDir(), "batch.db")
+	db, err := sqliteadapter.Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	_, err = db.Exec(batchTestSchema)
+	db.Close()
+	if err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+
+	repo, err := portfoliorepo.NewSQLiteRepository(dbPath)
+	if err != nil {
+		t.Fatalf("NewSQLiteRepository() error = %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+
+	ctx := user.AsSystem(context.Background())
+	if err := repo.Create(ctx, domainPortfolio.NewPortfolio("batch-1", "0xbatch1111")); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	service := NewService(repo, repo, repo, repo, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	eth := domainHolding.NewHolding("batch-1", "", &token.Token{ID: "ethereum", Symbol: "ETH", Address: "0xeth", Decimal: 18}, big.NewInt(5))
+	if err := service.AddHolding(ctx, "batch-1", eth); err != nil {
+		t.Fatalf("AddHolding() error = %v", err)
+	}
+	return service, repo, eth
+}
+
+func TestService_ApplyHoldingBatch(t *testing.T) {
+	usdc := &token.Token{ID: "usd-coin", Symbol: "USDC", Address: "0xusdc", Decimal: 6}
+
+	tests := []struct {
+		name         string
+		ops          func(eth *domainHolding.Holding) []*domainHolding.BatchOperation
+		dryRun       bool
+		wantErr      error
+		wantStatuses []domainHolding.BatchStatus
+		wantAmounts  map[string]int64 // token address -> stored amount after the call
+	}{
+		{
+			name: "applies all operations",
+			ops: func(eth *domainHolding.Holding) []*domainHolding.BatchOperation {
+				return []*domainHolding.BatchOperation{
+					{Op: domainHolding.BatchAdd, TokenAddress: usdc.Address, Token: usdc, Amount: big.NewInt(10)},
+					{Op: domainHolding.BatchUpdate, HoldingID: eth.ID, Amount: big.NewInt(7), Version: eth.Version},
+					{Op: domainHolding.BatchSet, TokenAddress: usdc.Address, Token: usdc, Amount: big.NewInt(4)},
+				}
+			},
+			wantStatuses: []domainHolding.BatchStatus{domainHolding.BatchApplied, domainHolding.BatchApplied, domainHolding.BatchApplied},
+			wantAmounts:  map[string]int64{"0xeth": 7, "0xusdc": 4},
+		},
+		{
+			name: "dry run does not persist",
+			ops: func(eth *domainHolding.Holding) []*domainHolding.BatchOperation {
+				return []*domainHolding.BatchOperation{
+					{Op: domainHolding.BatchDelete, HoldingID: eth.ID},
+					{Op: domainHolding.BatchAdd, TokenAddress: usdc.Address, Token: usdc, Amount: big.NewInt(10)},
+				}
+			},
+			dryRun:       true,
+			wantStatuses: []domainHolding.BatchStatus{domainHolding.BatchApplied, domainHolding.BatchApplied},
+			wantAmounts:  map[string]int64{"0xeth": 5},
+		},
+		{
+			name: "one failure rolls back the batch",
+			ops: func(eth *domainHolding.Holding) []*domainHolding.BatchOperation {
+				return []*domainHolding.BatchOperation{
+					{Op: domainHolding.BatchAdd, TokenAddress: usdc.Address, Token: usdc, Amount: big.NewInt(10)},
+					{Op: domainHolding.BatchUpdate, HoldingID: eth.ID, Amount: big.NewInt(9), Version: eth.Version + 1},
+					{Op: domainHolding.BatchAdd, TokenAddress: "0xunknown"},
+				}
+			},
+			wantErr:      domainHolding.ErrBatchFailed,
+			wantStatuses: []domainHolding.BatchStatus{domainHolding.BatchRolledBack, domainHolding.BatchFailed, domainHolding.BatchFailed},
+			wantAmounts:  map[string]int64{"0xeth": 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo, eth := setupBatchTest(t)
+			ctx := user.AsSystem(context.Background())
+
+			results, err := service.ApplyHoldingBatch(ctx, "batch-1", tt.ops(eth), tt.dryRun)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ApplyHoldingBatch() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(results) != len(tt.wantStatuses) {
+				t.Fatalf("ApplyHoldingBatch() = %d results, want %d", len(results), len(tt.wantStatuses))
+			}
+			for i, want := range tt.wantStatuses {
+				if results[i].Status != want {
+					t.Errorf("results[%d].Status = %s (%v), want %s", i, results[i].Status, results[i].Err, want)
+				}
+			}
+
+			holdings, err := repo.ListByPortfolioID(ctx, "batch-1")
+			if err != nil {
+				t.Fatalf("ListByPortfolioID() error = %v", err)
+			}
+			got := make(map[string]int64)
+			for _, h := range holdings {
+				got[h.Token.Address] = h.Amount.Int64()
+			}
+			if len(got) != len(tt.wantAmounts) {
+				t.Errorf("stored holdings = %v, want %v", got, tt.wantAmounts)
+			}
+			for address, want := range tt.wantAmounts {
+				if got[address] != want {
+					t.Errorf("stored amount of %s = %d, want %d", address, got[address], want)
+				}
+			}
+		})
+	}
+}
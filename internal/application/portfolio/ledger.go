@@ -0,0 +1,120 @@
+package portfolio
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"testtask/internal/domain/audit"
+	domainHolding "testtask/internal/domain/holding"
+	domainPortfolio "testtask/internal/domain/portfolio"
+	"testtask/internal/domain/token"
+
+	"go.uber.org/zap"
+)
+
+// RecordLedgerEntry appends a deposit, withdrawal, adjustment or transfer of the token to
+// the portfolio ledger and returns the holding with its new amount. draft carries the
+// type, signed quantity and optional details; the holding is created on its first entry.
+func (s *Service) RecordLedgerEntry(ctx context.Context, portfolioID string, t *token.Token, draft *domainHolding.LedgerEntry) (*domainHolding.Holding, error) {
+	if t == nil || draft == nil || draft.Quantity == nil {
+		s.logger.Warn("Attempted to record invalid ledger entry", zap.String("portfolio_id", portfolioID))
+		return nil, domainHolding.ErrInvalidEntry
+	}
+
+	var (
+		h      *domainHolding.Holding
+		before *holdingSnapshot
+	)
+	err := s.uow.WithinTx(ctx, func(ctx context.Context) error {
+		p, err := s.getMutablePortfolio(ctx, portfolioID, "recording ledger entry")
+		if err != nil {
+			return err
+		}
+
+		h = s.FindHoldingByToken(p, t.Address)
+		if h != nil {
+			before = snapshotHolding(h)
+		} else {
+			h = domainHolding.NewHolding(portfolioID, "", t, new(big.Int))
+		}
+
+		e := domainHolding.NewLedgerEntry(h, draft.Type, draft.Quantity, draft.OccurredAt)
+		e.Price = draft.Price
+		e.Currency = strings.ToLower(draft.Currency)
+		e.Note = strings.TrimSpace(draft.Note)
+		e.Counterparty = strings.TrimSpace(draft.Counterparty)
+		if err := e.Validate(); err != nil {
+			return err
+		}
+
+		return s.appendEntry(ctx, portfolioID, h, e)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	action := audit.ActionHoldingUpdate
+	if before == nil {
+		action = audit.ActionHoldingCreate
+	}
+	s.recordHolding(ctx, action, portfolioID, h.ID, before, snapshotHolding(h))
+	return h, nil
+}
+
+// ListLedger returns the ledger entries of a portfolio in the order they occurred
+func (s *Service) ListLedger(ctx context.Context, portfolioID string, filter domainHolding.LedgerFilter) ([]*domainHolding.LedgerEntry, error) {
+	if _, err := s.portfolioRepo.GetByID(ctx, portfolioID); err != nil {
+		return nil, err
+	}
+	filter.TokenAddress = strings.ToLower(filter.TokenAddress)
+	return s.ledgerRepo.ListEntries(ctx, portfolioID, filter)
+}
+
+// GetHoldingsAt projects the ledger onto the holdings of the portfolio as of at
+func (s *Service) GetHoldingsAt(ctx context.Context, portfolioID string, at time.Time) ([]*domainHolding.Holding, error) {
+	if _, err := s.portfolioRepo.GetByID(ctx, portfolioID); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.ledgerRepo.ListEntries(ctx, portfolioID, domainHolding.LedgerFilter{To: &at})
+	if err != nil {
+		s.logger.Error("Failed to list ledger entries", zap.String("portfolio_id", portfolioID), zap.Error(err))
+		return nil, err
+	}
+	return domainHolding.Positions(portfolioID, entries, &at), nil
+}
+
+// appendEntry checks that the holding's balance never goes negative with the new entry,
+// sets the holding amount to the ledger projection and stores both
+func (s *Service) appendEntry(ctx context.Context, portfolioID string, h *domainHolding.Holding, e *domainHolding.LedgerEntry) error {
+	entries, err := s.ledgerRepo.ListEntries(ctx, portfolioID, domainHolding.LedgerFilter{HoldingID: h.ID})
+	if err != nil {
+		s.logger.Error("Failed to list ledger entries", zap.String("portfolio_id", portfolioID), zap.String("holding_id", h.ID), zap.Error(err))
+		return err
+	}
+
+	entries = append(entries, e)
+	if err := domainHolding.CheckBalance(entries); err != nil {
+		s.logger.Warn("Rejected ledger entry", zap.String("portfolio_id", portfolioID), zap.String("holding_id", h.ID), zap.Error(err))
+		return err
+	}
+
+	s.UpdateAmount(h, domainHolding.Balance(entries, nil))
+	if err := s.ledgerRepo.AppendEntry(ctx, portfolioID, h, e); err != nil {
+		if !errors.Is(err, domainPortfolio.ErrPortfolioNotFound) {
+			s.logger.Error("Failed to append ledger entry", zap.String("portfolio_id", portfolioID), zap.String("holding_id", h.ID), zap.Error(err))
+		}
+		return err
+	}
+
+	s.logger.Info("Appended ledger entry",
+		zap.String("portfolio_id", portfolioID),
+		zap.String("holding_id", h.ID),
+		zap.String("type", string(e.Type)),
+		zap.String("quantity", e.Quantity.String()),
+		zap.String("amount", h.Amount.String()))
+	return nil
+}
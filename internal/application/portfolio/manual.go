@@ -0,0 +1,270 @@
+package portfolio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"testtask/internal/domain/audit"
+	domainPortfolio "testtask/internal/domain/portfolio"
+	"testtask/internal/domain/price"
+	"testtask/internal/domain/token"
+
+	"go.uber.org/zap"
+)
+
+// ListManualEntries returns the portfolio's manual assets and liabilities
+func (s *Service) ListManualEntries(ctx context.Context, portfolioID string) ([]*domainPortfolio.ManualEntry, error) {
+	if _, err := s.portfolioRepo.GetByID(ctx, portfolioID); err != nil {
+		return nil, err
+	}
+	return s.manualRepo.ListManualEntries(ctx, portfolioID)
+}
+
+// CreateManualEntry adds an off-chain asset or liability to the portfolio
+func (s *Service) CreateManualEntry(ctx context.Context, e *domainPortfolio.ManualEntry) error {
+	if _, err := s.getMutablePortfolio(ctx, e.PortfolioID, "adding manual entry"); err != nil {
+		return err
+	}
+	if err := s.validateManualEntry(ctx, e); err != nil {
+		s.logger.Warn("Invalid manual entry", zap.String("portfolio_id", e.PortfolioID), zap.Error(err))
+		return err
+	}
+
+	if err := s.manualRepo.CreateManualEntry(ctx, e); err != nil {
+		s.logger.Error("Failed to create manual entry", zap.String("portfolio_id", e.PortfolioID), zap.Error(err))
+		return err
+	}
+
+	s.logger.Info("Created manual entry", zap.String("portfolio_id", e.PortfolioID), zap.String("entry_id", e.ID), zap.String("side", string(e.Side)))
+	s.recordManualEntry(ctx, audit.ActionManualEntryCreate, e.PortfolioID, e.ID, nil, snapshotManualEntry(e))
+	return nil
+}
+
+// UpdateManualEntry replaces the settings of an existing entry
+func (s *Service) UpdateManualEntry(ctx context.Context, e *domainPortfolio.ManualEntry) error {
+	if _, err := s.getMutablePortfolio(ctx, e.PortfolioID, "updating manual entry"); err != nil {
+		return err
+	}
+	existing, err := s.manualRepo.GetManualEntry(ctx, e.PortfolioID, e.ID)
+	if err != nil {
+		return err
+	}
+
+	e.Normalize()
+	e.CreatedAt = existing.CreatedAt
+	e.UpdatedAt = time.Now().UTC()
+	if err := s.validateManualEntry(ctx, e); err != nil {
+		s.logger.Warn("Invalid manual entry", zap.String("portfolio_id", e.PortfolioID), zap.String("entry_id", e.ID), zap.Error(err))
+		return err
+	}
+
+	if err := s.manualRepo.UpdateManualEntry(ctx, e); err != nil {
+		if !errors.Is(err, domainPortfolio.ErrManualEntryNotFound) {
+			s.logger.Error("Failed to update manual entry", zap.String("entry_id", e.ID), zap.Error(err))
+		}
+		return err
+	}
+
+	s.logger.Info("Updated manual entry", zap.String("portfolio_id", e.PortfolioID), zap.String("entry_id", e.ID))
+	s.recordManualEntry(ctx, audit.ActionManualEntryUpdate, e.PortfolioID, e.ID, snapshotManualEntry(existing), snapshotManualEntry(e))
+	return nil
+}
+
+// DeleteManualEntry removes an entry from the portfolio
+func (s *Service) DeleteManualEntry(ctx context.Context, portfolioID, entryID string) error {
+	if _, err := s.getMutablePortfolio(ctx, portfolioID, "deleting manual entry"); err != nil {
+		return err
+	}
+	existing, err := s.manualRepo.GetManualEntry(ctx, portfolioID, entryID)
+	if err != nil {
+		return err
+	}
+	if err := s.manualRepo.DeleteManualEntry(ctx, portfolioID, entryID); err != nil {
+		return err
+	}
+
+	s.logger.Info("Deleted manual entry", zap.String("portfolio_id", portfolioID), zap.String("entry_id", entryID))
+	s.recordManualEntry(ctx, audit.ActionManualEntryDelete, portfolioID, entryID, snapshotManualEntry(existing), nil)
+	return nil
+}
+
+func (s *Service) recordManualEntry(ctx context.Context, action, portfolioID, entryID string, before, after *manualEntrySnapshot) {
+	s.recorder.Record(ctx, audit.NewEntry(action, audit.ResourceManualEntry, entryID, portfolioID), before, after)
+}
+
+// validateManualEntry checks the entry and, for market valuation, that its token is known
+func (s *Service) validateManualEntry(ctx context.Context, e *domainPortfolio.ManualEntry) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+	if e.Valuation == domainPortfolio.ValuationMarket {
+		if _, ok := s.manualTokens(ctx, e.PortfolioID, []string{e.TokenAddress})[e.TokenAddress]; !ok {
+			return fmt.Errorf("%w: unknown token %s", domainPortfolio.ErrInvalidManualEntry, e.TokenAddress)
+		}
+	}
+	return nil
+}
+
+// manualTokens resolves token addresses from the registry, then the portfolio's custom tokens
+func (s *Service) manualTokens(ctx context.Context, portfolioID string, addresses []string) map[string]*token.Token {
+	tokens := make(map[string]*token.Token)
+	if len(addresses) == 0 {
+		return tokens
+	}
+	if s.tokenRepo != nil {
+		for addr, tok := range s.tokenRepo.GetByAddresses(ctx, addresses) {
+			tokens[strings.ToLower(addr)] = tok
+		}
+	}
+	if s.tokenCatalog != nil {
+		var unknown []string
+		for _, addr := range addresses {
+			if _, ok := tokens[addr]; !ok {
+				unknown = append(unknown, addr)
+			}
+		}
+		if len(unknown) > 0 {
+			for addr, tok := range s.tokenCatalog.CustomTokens(ctx, portfolioID, unknown) {
+				tokens[strings.ToLower(addr)] = tok
+			}
+		}
+	}
+	return tokens
+}
+
+// manualAssets values the portfolio's manual entries in currency. Liabilities get
+// negative amounts and values. Entries that cannot be priced or converted are returned
+// without a value, like unpriced tokens.
+func (s *Service) manualAssets(ctx context.Context, portfolioID, currency string) ([]*domainPortfolio.Asset, error) {
+	if s.manualRepo == nil {
+		return nil, nil
+	}
+	entries, err := s.manualRepo.ListManualEntries(ctx, portfolioID)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+
+	var addresses, currencies []string
+	for _, e := range entries {
+		if e.Valuation == domainPortfolio.ValuationMarket {
+			addresses = append(addresses, e.TokenAddress)
+		} else {
+			currencies = append(currencies, e.Currency)
+		}
+	}
+
+	tokens := s.manualTokens(ctx, portfolioID, addresses)
+	prices := make(map[string]*price.Price) // lowercase token address -> price
+	if len(tokens) > 0 {
+		list := make([]*token.Token, 0, len(tokens))
+		for _, tok := range tokens {
+			list = append(list, tok)
+		}
+		priced, err := s.fetchPrices(ctx, portfolioID, list, currency)
+		if err != nil {
+			s.logger.Warn("Failed to price manual entries", zap.String("portfolio_id", portfolioID), zap.Error(err))
+		}
+		for tok, p := range priced {
+			prices[strings.ToLower(tok.Address)] = p
+		}
+	}
+	rates := s.currencyRates(ctx, currencies, currency)
+
+	assets := make([]*domainPortfolio.Asset, 0, len(entries))
+	for _, e := range entries {
+		asset := &domainPortfolio.Asset{Source: domainPortfolio.SourceManual, Manual: e}
+		sign := big.NewInt(e.Sign())
+
+		if e.Valuation == domainPortfolio.ValuationMarket {
+			tok := tokens[e.TokenAddress]
+			if tok == nil {
+				s.logger.Warn("Manual entry token not found", zap.String("entry_id", e.ID), zap.String("address", e.TokenAddress))
+				assets = append(assets, asset)
+				continue
+			}
+			asset.Token = tok
+			asset.Amount = rescaleQuantity(e.Quantity, tok.Decimal)
+			asset.Amount.Mul(asset.Amount, sign)
+			asset.Price = prices[e.TokenAddress]
+			if asset.Price != nil {
+				asset.Value = domainPortfolio.CalculateValue(tok.Decimal, asset.Amount, asset.Price)
+				asset.ValueChange24h = domainPortfolio.CalculateValueChange(asset.Value, asset.Price.Change24h())
+			}
+		} else if value := domainPortfolio.ConvertValue(e.QuoteValue(), rates[e.Currency]); value != nil {
+			asset.Value = value.Mul(value, sign)
+		} else {
+			s.logger.Warn("No exchange rate for manual entry currency", zap.String("entry_id", e.ID), zap.String("from", e.Currency), zap.String("to", currency))
+		}
+		assets = append(assets, asset)
+	}
+	return assets, nil
+}
+
+// currencyRates returns the price of one unit of each currency in target, scaled by
+// price.CurrencyDecimal. Rates are derived from the native asset's price in both
+// currencies; a currency without a rate is left out.
+func (s *Service) currencyRates(ctx context.Context, currencies []string, target string) map[string]*big.Int {
+	one := new(big.Int).Exp(big.NewInt(10), big.NewInt(price.CurrencyDecimal), nil)
+	rates := map[string]*big.Int{target: one}
+
+	var foreign []string
+	seen := map[string]bool{target: true}
+	for _, c := range currencies {
+		if !seen[c] {
+			seen[c] = true
+			foreign = append(foreign, c)
+		}
+	}
+	if len(foreign) == 0 || s.priceProvider == nil {
+		return rates
+	}
+
+	nativeToken, ok := s.aliases.NativeToken(portfolioChainID)
+	if !ok {
+		return rates
+	}
+	native, _ := s.aliases.Native(portfolioChainID)
+	reference := *nativeToken
+	reference.Address = native.PriceProxy
+
+	quote := func(currency string) *big.Int {
+		prices, err := s.priceProvider.GetPrices(ctx, []*token.Token{&reference}, currency)
+		if err != nil {
+			s.logger.Warn("Failed to fetch reference price for exchange rate", zap.String("currency", currency), zap.Error(err))
+			return nil
+		}
+		for _, p := range prices {
+			if p != nil && p.Value != nil && p.Value.Sign() > 0 {
+				return p.Value
+			}
+		}
+		return nil
+	}
+
+	targetPrice := quote(target)
+	if targetPrice == nil {
+		return rates
+	}
+	for _, c := range foreign {
+		if sourcePrice := quote(c); sourcePrice != nil {
+			rate := new(big.Int).Mul(targetPrice, one)
+			rates[c] = rate.Quo(rate, sourcePrice)
+		}
+	}
+	return rates
+}
+
+// rescaleQuantity converts a manual quantity to a token's base units, truncating extra digits
+func rescaleQuantity(quantity *big.Int, decimals uint8) *big.Int {
+	shift := int64(decimals) - domainPortfolio.ManualQuantityDecimals
+	if shift >= 0 {
+		scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(shift), nil)
+		return scale.Mul(scale, quantity)
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(-shift), nil)
+	return new(big.Int).Quo(quantity, scale)
+}
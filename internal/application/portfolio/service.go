@@ -3,15 +3,19 @@ package portfolio
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
 	"strings"
 
 	loggeradapter "testtask/internal/adapters/logger"
+	"testtask/internal/domain"
+	"testtask/internal/domain/audit"
 	domainHolding "testtask/internal/domain/holding"
 	domainPortfolio "testtask/internal/domain/portfolio"
 	"testtask/internal/domain/price"
 	"testtask/internal/domain/token"
 	domainTransaction "testtask/internal/domain/transaction"
+	"testtask/internal/domain/user"
 	"time"
 
 	"go.uber.org/zap"
@@ -19,44 +23,70 @@ import (
 
 var (
 	ErrInvalidHolding   = errors.New("invalid domainHolding")
-	ErrInvalidPortfolio = errors.New("invalid portfolio")
-	ErrPortfolioExists  = errors.New("portfolio already exists")
+	ErrInvalidPortfolio = domainPortfolio.ErrInvalidPortfolio
+	ErrPortfolioExists  = domainPortfolio.ErrPortfolioExists
 )
 
-// WETHAddress is the Wrapped ETH address on Ethereum mainnet.
-// Used for price lookups when dealing with native ETH.
-const WETHAddress = "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"
+// portfolioChainID is the chain portfolio addresses are tracked on (Ethereum mainnet)
+const portfolioChainID = "1"
 
 type Service struct {
 	portfolioRepo   domainPortfolio.Repository
 	holdingRepo     domainHolding.Repository
+	ledgerRepo      domainHolding.LedgerRepository
+	uow             domainHolding.UnitOfWork
 	transactionRepo domainTransaction.Provider
+	statements      domainTransaction.StatementProvider
+	manualRepo      domainPortfolio.ManualRepository
 	tokenRepo       token.Repository
+	tokenCatalog    domain.TokenCatalogService
 	priceProvider   price.PriceProvider
+	aliases         *token.AliasRegistry
+	recorder        audit.Recorder
 	logger          *loggeradapter.Logger
 }
 
-func (s *Service) ListPortfolios(ctx context.Context) ([]*domainPortfolio.Portfolio, error) {
-	s.logger.Info("Listing all portfolios")
-	portfolios, err := s.portfolioRepo.List(ctx)
+// defaultPortfolioPageSize applies when the caller does not paginate
+const defaultPortfolioPageSize = 20
+
+func (s *Service) ListPortfolios(ctx context.Context, opts domainPortfolio.ListOptions) ([]*domainPortfolio.Portfolio, int, error) {
+	if opts.Page < 1 {
+		opts.Page = 1
+	}
+	if opts.PageSize < 1 {
+		opts.PageSize = defaultPortfolioPageSize
+	}
+
+	s.logger.Info("Listing portfolios", zap.Int("page", opts.Page), zap.Int("page_size", opts.PageSize), zap.String("sort_by", opts.SortBy))
+	portfolios, total, err := s.portfolioRepo.ListPage(ctx, opts)
 	if err != nil {
 		s.logger.Error("Failed to list portfolios", zap.Error(err))
-		return nil, err
+		return nil, 0, err
 	}
-	s.logger.Info("Successfully listed portfolios", zap.Int("count", len(portfolios)))
-	return portfolios, nil
+	s.logger.Info("Successfully listed portfolios", zap.Int("count", len(portfolios)), zap.Int("total", total))
+	return portfolios, total, nil
 }
 
-func NewService(repo domainPortfolio.Repository, holdingRepo domainHolding.Repository, transactionRepo domainTransaction.Provider, tokenRepo token.Repository, priceProvider price.PriceProvider, logger *loggeradapter.Logger) *Service {
+func NewService(repo domainPortfolio.Repository, holdingRepo domainHolding.Repository, ledgerRepo domainHolding.LedgerRepository, uow domainHolding.UnitOfWork, transactionRepo domainTransaction.Provider, statements domainTransaction.StatementProvider, manualRepo domainPortfolio.ManualRepository, tokenRepo token.Repository, tokenCatalog domain.TokenCatalogService, priceProvider price.PriceProvider, aliases *token.AliasRegistry, recorder audit.Recorder, logger *loggeradapter.Logger) *Service {
 	if logger == nil {
 		logger = loggeradapter.NewNopLogger()
 	}
+	if recorder == nil {
+		recorder = audit.NopRecorder{}
+	}
 	return &Service{
 		portfolioRepo:   repo,
 		priceProvider:   priceProvider,
 		holdingRepo:     holdingRepo,
+		ledgerRepo:      ledgerRepo,
+		uow:             uow,
 		transactionRepo: transactionRepo,
+		statements:      statements,
+		manualRepo:      manualRepo,
 		tokenRepo:       tokenRepo,
+		tokenCatalog:    tokenCatalog,
+		aliases:         aliases,
+		recorder:        recorder,
 		logger:          logger,
 	}
 }
@@ -72,6 +102,14 @@ func (s *Service) CreatePortfolio(ctx context.Context, p *domainPortfolio.Portfo
 		return ErrInvalidPortfolio
 	}
 
+	p.Name = strings.TrimSpace(p.Name)
+	p.Description = strings.TrimSpace(p.Description)
+	p.Tags = domainPortfolio.NormalizeTags(p.Tags)
+	if err := p.Validate(); err != nil {
+		s.logger.Warn("Attempted to create portfolio with invalid metadata", zap.String("address", p.Address), zap.Error(err))
+		return err
+	}
+
 	s.logger.Info("Creating portfolio", zap.String("address", p.Address), zap.String("id", p.ID))
 
 	existingByAddr, err := s.portfolioRepo.GetByAddress(ctx, p.Address)
@@ -86,11 +124,13 @@ func (s *Service) CreatePortfolio(ctx context.Context, p *domainPortfolio.Portfo
 
 	if p.ID == "" {
 		newPortfolio := domainPortfolio.NewPortfolio("", p.Address)
+		copyMetadata(ctx, newPortfolio, p)
 		if err := s.portfolioRepo.Create(ctx, newPortfolio); err != nil {
 			s.logger.Error("Failed to create portfolio", zap.String("address", p.Address), zap.Error(err))
 			return err
 		}
 		s.logger.Info("Successfully created portfolio", zap.String("address", p.Address), zap.String("id", newPortfolio.ID))
+		s.recordPortfolio(ctx, audit.ActionPortfolioCreate, newPortfolio.ID, nil, snapshotPortfolio(newPortfolio, false))
 		return nil
 	}
 
@@ -106,11 +146,13 @@ func (s *Service) CreatePortfolio(ctx context.Context, p *domainPortfolio.Portfo
 	}
 
 	newPortfolio := domainPortfolio.NewPortfolio(p.ID, p.Address)
+	copyMetadata(ctx, newPortfolio, p)
 	if err := s.portfolioRepo.Create(ctx, newPortfolio); err != nil {
 		s.logger.Error("Failed to create portfolio", zap.String("id", p.ID), zap.String("address", p.Address), zap.Error(err))
 		return err
 	}
 	s.logger.Info("Successfully created portfolio", zap.String("id", p.ID), zap.String("address", p.Address))
+	s.recordPortfolio(ctx, audit.ActionPortfolioCreate, newPortfolio.ID, nil, snapshotPortfolio(newPortfolio, false))
 	return nil
 }
 
@@ -125,6 +167,90 @@ func (s *Service) GetPortfolio(ctx context.Context, portfolioID string) (*domain
 	return p, nil
 }
 
+// UpdatePortfolio applies metadata changes to a portfolio
+func (s *Service) UpdatePortfolio(ctx context.Context, portfolioID string, changes domainPortfolio.Changes) (*domainPortfolio.Portfolio, error) {
+	s.logger.Info("Updating portfolio", zap.String("portfolio_id", portfolioID))
+
+	p, err := s.portfolioRepo.GetByID(ctx, portfolioID)
+	if err != nil {
+		s.logger.Warn("Failed to get portfolio for update", zap.String("portfolio_id", portfolioID), zap.Error(err))
+		return nil, err
+	}
+
+	before := snapshotPortfolio(p, false)
+	if err := p.Apply(changes); err != nil {
+		return nil, err
+	}
+
+	if err := s.portfolioRepo.Update(ctx, p); err != nil {
+		s.logger.Error("Failed to update portfolio", zap.String("portfolio_id", portfolioID), zap.Error(err))
+		return nil, err
+	}
+	s.logger.Info("Successfully updated portfolio", zap.String("portfolio_id", portfolioID))
+	s.recordPortfolio(ctx, audit.ActionPortfolioUpdate, portfolioID, before, snapshotPortfolio(p, false))
+	return p, nil
+}
+
+// ArchivePortfolio archives or restores a portfolio. Archived portfolios are hidden
+// from listings by default and their holdings cannot be changed.
+func (s *Service) ArchivePortfolio(ctx context.Context, portfolioID string, archived bool) (*domainPortfolio.Portfolio, error) {
+	s.logger.Info("Setting portfolio archived flag", zap.String("portfolio_id", portfolioID), zap.Bool("archived", archived))
+
+	p, err := s.portfolioRepo.GetByID(ctx, portfolioID)
+	if err != nil {
+		s.logger.Warn("Failed to get portfolio for archiving", zap.String("portfolio_id", portfolioID), zap.Error(err))
+		return nil, err
+	}
+	if p.Archived == archived {
+		return p, nil
+	}
+
+	before := snapshotPortfolio(p, false)
+	p.Archived = archived
+	p.UpdatedAt = time.Now()
+	if err := s.portfolioRepo.Update(ctx, p); err != nil {
+		s.logger.Error("Failed to archive portfolio", zap.String("portfolio_id", portfolioID), zap.Error(err))
+		return nil, err
+	}
+
+	action := audit.ActionPortfolioArchive
+	if !archived {
+		action = audit.ActionPortfolioUnarchive
+	}
+	s.recordPortfolio(ctx, action, portfolioID, before, snapshotPortfolio(p, false))
+	return p, nil
+}
+
+// DeletePortfolio removes a portfolio with its holdings, custom tokens and price overrides
+func (s *Service) DeletePortfolio(ctx context.Context, portfolioID string) error {
+	s.logger.Info("Deleting portfolio", zap.String("portfolio_id", portfolioID))
+
+	// The audit entry keeps the holdings, which are removed with the portfolio
+	p, err := s.portfolioRepo.GetByIDWithHoldings(ctx, portfolioID)
+	if err != nil {
+		s.logger.Warn("Failed to get portfolio for deletion", zap.String("portfolio_id", portfolioID), zap.Error(err))
+		return err
+	}
+
+	if err := s.portfolioRepo.Delete(ctx, portfolioID); err != nil {
+		s.logger.Warn("Failed to delete portfolio", zap.String("portfolio_id", portfolioID), zap.Error(err))
+		return err
+	}
+	s.logger.Info("Successfully deleted portfolio", zap.String("portfolio_id", portfolioID))
+	s.recordPortfolio(ctx, audit.ActionPortfolioDelete, portfolioID, snapshotPortfolio(p, true), nil)
+	return nil
+}
+
+// copyMetadata copies the user-editable fields and assigns the portfolio to the caller
+func copyMetadata(ctx context.Context, dst, src *domainPortfolio.Portfolio) {
+	if caller, ok := user.FromContext(ctx); ok {
+		dst.OwnerID = caller.ID
+	}
+	dst.Name = src.Name
+	dst.Description = src.Description
+	dst.Tags = src.Tags
+}
+
 func (s *Service) GetHoldings(ctx context.Context, portfolioID string) ([]*domainHolding.Holding, error) {
 	s.logger.Info("Getting holdings", zap.String("portfolio_id", portfolioID))
 	p, err := s.portfolioRepo.GetByIDWithHoldings(ctx, portfolioID)
@@ -140,6 +266,9 @@ func (s *Service) GetHoldings(ctx context.Context, portfolioID string) ([]*domai
 	return holdings, nil
 }
 
+// AddHolding deposits the amount into the portfolio's holding of the token, creating the
+// holding on first use. The read and the write run in one unit of work so concurrent adds
+// of the same token neither create duplicate holdings nor lose a deposit.
 func (s *Service) AddHolding(ctx context.Context, portfolioID string, holding *domainHolding.Holding) error {
 	if holding.Token == nil {
 		s.logger.Warn("Attempted to add holding with nil token", zap.String("portfolio_id", portfolioID))
@@ -153,42 +282,37 @@ func (s *Service) AddHolding(ctx context.Context, portfolioID string, holding *d
 
 	s.logger.Info("Adding holding", zap.String("portfolio_id", portfolioID), zap.String("token_id", holding.Token.ID), zap.String("amount", holding.Amount.String()))
 
-	p, err := s.portfolioRepo.GetByIDWithHoldings(ctx, portfolioID)
-	if err != nil {
-		if errors.Is(err, domainPortfolio.ErrPortfolioNotFound) {
-			s.logger.Warn("Portfolio not found when adding holding", zap.String("portfolio_id", portfolioID))
-			return domainPortfolio.ErrPortfolioNotFound
+	var (
+		stored *domainHolding.Holding
+		before *holdingSnapshot
+	)
+	err := s.uow.WithinTx(ctx, func(ctx context.Context) error {
+		p, err := s.getMutablePortfolio(ctx, portfolioID, "adding holding")
+		if err != nil {
+			return err
 		}
-		s.logger.Error("Failed to get portfolio when adding holding", zap.String("portfolio_id", portfolioID), zap.Error(err))
+		stored, before, err = s.depositHolding(ctx, p, holding.Token, holding.Amount)
+		return err
+	})
+	if err != nil {
 		return err
 	}
 
-	existing := s.FindHoldingByToken(p, holding.Token.ID)
-	if existing != nil {
-		s.logger.Info("Holding exists, updating amount", zap.String("portfolio_id", portfolioID), zap.String("token_id", holding.Token.ID), zap.String("holding_id", existing.ID))
-		newAmount := new(big.Int).Add(existing.Amount, holding.Amount)
-		s.UpdateAmount(existing, newAmount)
-
-		if err := s.holdingRepo.UpdateHolding(ctx, portfolioID, existing); err != nil {
-			s.logger.Error("Failed to update holding", zap.String("portfolio_id", portfolioID), zap.String("holding_id", existing.ID), zap.Error(err))
-			return err
-		}
-		s.logger.Info("Successfully updated holding", zap.String("portfolio_id", portfolioID), zap.String("holding_id", existing.ID), zap.String("new_amount", newAmount.String()))
+	holding.ID = stored.ID
+	holding.Version = stored.Version
+	if before != nil {
+		s.logger.Info("Successfully updated holding", zap.String("portfolio_id", portfolioID), zap.String("holding_id", stored.ID), zap.String("new_amount", stored.Amount.String()))
+		s.recordHolding(ctx, audit.ActionHoldingUpdate, portfolioID, stored.ID, before, snapshotHolding(stored))
 		return nil
 	}
-
-	// Create new domainHolding with UUID and portfolioID
-	newHolding := domainHolding.NewHolding(portfolioID, "", holding.Token, holding.Amount)
-
-	if err := s.holdingRepo.CreateHolding(ctx, portfolioID, newHolding); err != nil {
-		s.logger.Error("Failed to create holding", zap.String("portfolio_id", portfolioID), zap.String("token_id", holding.Token.ID), zap.Error(err))
-		return err
-	}
-	s.logger.Info("Successfully created holding", zap.String("portfolio_id", portfolioID), zap.String("holding_id", newHolding.ID), zap.String("token_id", holding.Token.ID))
+	s.logger.Info("Successfully created holding", zap.String("portfolio_id", portfolioID), zap.String("holding_id", stored.ID), zap.String("token_id", holding.Token.ID))
+	s.recordHolding(ctx, audit.ActionHoldingCreate, portfolioID, stored.ID, nil, snapshotHolding(stored))
 	return nil
 }
 
-func (s *Service) UpdateHolding(ctx context.Context, portfolioID string, holdingID string, amount *big.Int) error {
+// UpdateHolding sets the amount of a holding. A non-zero version must match the stored
+// version of the holding, otherwise the update fails with ErrVersionConflict.
+func (s *Service) UpdateHolding(ctx context.Context, portfolioID string, holdingID string, amount *big.Int, version int64) error {
 	if amount == nil || amount.Sign() < 0 {
 		s.logger.Warn("Attempted to update holding with invalid amount", zap.String("portfolio_id", portfolioID), zap.String("holding_id", holdingID))
 		return ErrInvalidHolding
@@ -196,57 +320,161 @@ func (s *Service) UpdateHolding(ctx context.Context, portfolioID string, holding
 
 	s.logger.Info("Updating holding", zap.String("portfolio_id", portfolioID), zap.String("holding_id", holdingID), zap.String("amount", amount.String()))
 
-	p, err := s.portfolioRepo.GetByIDWithHoldings(ctx, portfolioID)
-	if err != nil {
-		if errors.Is(err, domainPortfolio.ErrPortfolioNotFound) {
-			s.logger.Warn("Portfolio not found when updating holding", zap.String("portfolio_id", portfolioID))
-			return domainPortfolio.ErrPortfolioNotFound
+	var (
+		existing *domainHolding.Holding
+		before   *holdingSnapshot
+	)
+	err := s.uow.WithinTx(ctx, func(ctx context.Context) error {
+		p, err := s.getMutablePortfolio(ctx, portfolioID, "updating holding")
+		if err != nil {
+			return err
 		}
-		s.logger.Error("Failed to get portfolio when updating holding", zap.String("portfolio_id", portfolioID), zap.Error(err))
+		if existing, err = s.findVersionedHolding(p, holdingID, version); err != nil {
+			return err
+		}
+		before, err = s.setHoldingAmount(ctx, p, existing, amount)
+		return err
+	})
+	if err != nil || before == nil {
 		return err
 	}
 
-	existing := s.FindHolding(p, holdingID)
-	if existing == nil {
-		s.logger.Warn("Holding not found", zap.String("portfolio_id", portfolioID), zap.String("holding_id", holdingID))
-		return domainHolding.ErrHoldingNotFound
-	}
+	s.logger.Info("Successfully updated holding", zap.String("portfolio_id", portfolioID), zap.String("holding_id", holdingID), zap.String("amount", amount.String()))
+	s.recordHolding(ctx, audit.ActionHoldingUpdate, portfolioID, holdingID, before, snapshotHolding(existing))
+	return nil
+}
 
-	s.UpdateAmount(existing, amount)
+// DeleteHolding closes the position with a ledger entry and removes the holding in one
+// unit of work
+func (s *Service) DeleteHolding(ctx context.Context, portfolioID string, holdingID string) error {
+	s.logger.Info("Deleting holding", zap.String("portfolio_id", portfolioID), zap.String("holding_id", holdingID))
 
-	if err := s.holdingRepo.UpdateHolding(ctx, portfolioID, existing); err != nil {
-		s.logger.Error("Failed to update holding", zap.String("portfolio_id", portfolioID), zap.String("holding_id", holdingID), zap.Error(err))
+	var before *holdingSnapshot
+	err := s.uow.WithinTx(ctx, func(ctx context.Context) error {
+		p, err := s.getMutablePortfolio(ctx, portfolioID, "deleting holding")
+		if err != nil {
+			return err
+		}
+		holding, err := s.findVersionedHolding(p, holdingID, 0)
+		if err != nil {
+			return err
+		}
+		before, err = s.removeHolding(ctx, p, holding)
+		return err
+	})
+	if err != nil {
 		return err
 	}
-	s.logger.Info("Successfully updated holding", zap.String("portfolio_id", portfolioID), zap.String("holding_id", holdingID), zap.String("amount", amount.String()))
+
+	s.logger.Info("Successfully deleted holding", zap.String("portfolio_id", portfolioID), zap.String("holding_id", holdingID))
+	s.recordHolding(ctx, audit.ActionHoldingDelete, portfolioID, holdingID, before, nil)
 	return nil
 }
 
-func (s *Service) DeleteHolding(ctx context.Context, portfolioID string, holdingID string) error {
-	s.logger.Info("Deleting holding", zap.String("portfolio_id", portfolioID), zap.String("holding_id", holdingID))
+// depositHolding deposits amount into the portfolio's holding of the token, creating the
+// holding on first use. before is nil for a new holding.
+func (s *Service) depositHolding(ctx context.Context, p *domainPortfolio.Portfolio, t *token.Token, amount *big.Int) (*domainHolding.Holding, *holdingSnapshot, error) {
+	var before *holdingSnapshot
+	h := s.FindHoldingByToken(p, t.Address)
+	if h != nil {
+		s.logger.Info("Holding exists, updating amount", zap.String("portfolio_id", p.ID), zap.String("token_id", t.ID), zap.String("holding_id", h.ID))
+		before = snapshotHolding(h)
+	} else {
+		// Create new domainHolding with UUID and portfolioID; its amount is projected from the opening deposit
+		h = domainHolding.NewHolding(p.ID, "", t, new(big.Int))
+	}
 
+	deposit := domainHolding.NewLedgerEntry(h, domainHolding.EntryDeposit, amount, time.Time{})
+	if err := s.appendEntry(ctx, p.ID, h, deposit); err != nil {
+		return nil, nil, err
+	}
+	if before == nil {
+		p.Holdings = append(p.Holdings, h)
+	}
+	return h, before, nil
+}
+
+// setHoldingAmount records the difference to amount as an adjustment. before is nil when
+// the amount is unchanged and nothing was written.
+func (s *Service) setHoldingAmount(ctx context.Context, p *domainPortfolio.Portfolio, h *domainHolding.Holding, amount *big.Int) (*holdingSnapshot, error) {
+	delta := new(big.Int).Sub(amount, h.Amount)
+	if delta.Sign() == 0 {
+		return nil, nil
+	}
+
+	before := snapshotHolding(h)
+	adjustment := domainHolding.NewLedgerEntry(h, domainHolding.EntryAdjustment, delta, time.Time{})
+	if err := s.appendEntry(ctx, p.ID, h, adjustment); err != nil {
+		return nil, err
+	}
+	return before, nil
+}
+
+// removeHolding closes the position and deletes the holding. The ledger keeps the
+// history; a closing adjustment brings the position to zero.
+func (s *Service) removeHolding(ctx context.Context, p *domainPortfolio.Portfolio, h *domainHolding.Holding) (*holdingSnapshot, error) {
+	before := snapshotHolding(h)
+	if h.Amount.Sign() != 0 {
+		closing := domainHolding.NewLedgerEntry(h, domainHolding.EntryAdjustment, new(big.Int).Neg(h.Amount), time.Time{})
+		closing.Note = "holding deleted"
+		if err := s.appendEntry(ctx, p.ID, h, closing); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.holdingRepo.DeleteHolding(ctx, p.ID, h.ID); err != nil {
+		s.logger.Error("Failed to delete holding", zap.String("portfolio_id", p.ID), zap.String("holding_id", h.ID), zap.Error(err))
+		return nil, err
+	}
+
+	for i, held := range p.Holdings {
+		if held == h {
+			p.Holdings = append(p.Holdings[:i], p.Holdings[i+1:]...)
+			break
+		}
+	}
+	return before, nil
+}
+
+// findVersionedHolding returns the holding with the ID. A non-zero version must match
+// the holding's current version.
+func (s *Service) findVersionedHolding(p *domainPortfolio.Portfolio, holdingID string, version int64) (*domainHolding.Holding, error) {
+	h := s.FindHolding(p, holdingID)
+	if h == nil {
+		s.logger.Warn("Holding not found", zap.String("portfolio_id", p.ID), zap.String("holding_id", holdingID))
+		return nil, domainHolding.ErrHoldingNotFound
+	}
+	if version != 0 && version != h.Version {
+		s.logger.Warn("Rejected stale holding change", zap.String("portfolio_id", p.ID), zap.String("holding_id", holdingID), zap.Int64("version", version), zap.Int64("current_version", h.Version))
+		return nil, fmt.Errorf("%w: holding_id=%s has version %d, expected %d", domainHolding.ErrVersionConflict, holdingID, h.Version, version)
+	}
+	return h, nil
+}
+
+// getMutablePortfolio loads the portfolio with its holdings for a change of its holdings
+func (s *Service) getMutablePortfolio(ctx context.Context, portfolioID, operation string) (*domainPortfolio.Portfolio, error) {
 	p, err := s.portfolioRepo.GetByIDWithHoldings(ctx, portfolioID)
 	if err != nil {
 		if errors.Is(err, domainPortfolio.ErrPortfolioNotFound) {
-			s.logger.Warn("Portfolio not found when deleting holding", zap.String("portfolio_id", portfolioID))
-			return domainPortfolio.ErrPortfolioNotFound
+			s.logger.Warn("Portfolio not found when "+operation, zap.String("portfolio_id", portfolioID))
+			return nil, domainPortfolio.ErrPortfolioNotFound
 		}
-		s.logger.Error("Failed to get portfolio when deleting holding", zap.String("portfolio_id", portfolioID), zap.Error(err))
-		return err
+		s.logger.Error("Failed to get portfolio when "+operation, zap.String("portfolio_id", portfolioID), zap.Error(err))
+		return nil, err
 	}
-
-	holding := s.FindHolding(p, holdingID)
-	if holding == nil {
-		s.logger.Warn("Holding not found", zap.String("portfolio_id", portfolioID), zap.String("holding_id", holdingID))
-		return domainHolding.ErrHoldingNotFound
+	if p.Archived {
+		s.logger.Warn("Attempted to change holdings of archived portfolio", zap.String("portfolio_id", portfolioID))
+		return nil, domainPortfolio.ErrPortfolioArchived
 	}
+	return p, nil
+}
 
-	if err := s.holdingRepo.DeleteHolding(ctx, portfolioID, holdingID); err != nil {
-		s.logger.Error("Failed to delete holding", zap.String("portfolio_id", portfolioID), zap.String("holding_id", holdingID), zap.Error(err))
-		return err
-	}
-	s.logger.Info("Successfully deleted holding", zap.String("portfolio_id", portfolioID), zap.String("holding_id", holdingID))
-	return nil
+func (s *Service) recordPortfolio(ctx context.Context, action, portfolioID string, before, after *portfolioSnapshot) {
+	s.recorder.Record(ctx, audit.NewEntry(action, audit.ResourcePortfolio, portfolioID, portfolioID), before, after)
+}
+
+func (s *Service) recordHolding(ctx context.Context, action, portfolioID, holdingID string, before, after *holdingSnapshot) {
+	s.recorder.Record(ctx, audit.NewEntry(action, audit.ResourceHolding, holdingID, portfolioID), before, after)
 }
 
 func (s *Service) UpdateAmount(holding *domainHolding.Holding, amount *big.Int) {
@@ -263,9 +491,10 @@ func (s *Service) FindHolding(p *domainPortfolio.Portfolio, holdingID string) *d
 	return nil
 }
 
-func (s *Service) FindHoldingByToken(p *domainPortfolio.Portfolio, tokenID string) *domainHolding.Holding {
+// FindHoldingByToken matches by contract address; discovered tokens may not have an ID yet.
+func (s *Service) FindHoldingByToken(p *domainPortfolio.Portfolio, tokenAddress string) *domainHolding.Holding {
 	for _, h := range p.Holdings {
-		if h.Token != nil && h.Token.ID == tokenID {
+		if h.Token != nil && strings.EqualFold(h.Token.Address, tokenAddress) {
 			return h
 		}
 	}
@@ -315,6 +544,13 @@ func (s *Service) GetPortfolioAssets(ctx context.Context, portfolioID string, cu
 		s.logger.Debug("Fetched token transactions", zap.Int("count", len(tokenTxs)))
 	}
 
+	// Register tokens we have not seen before so their balances are not dropped below
+	if s.tokenCatalog != nil && len(tokenTxs) > 0 {
+		if _, err := s.tokenCatalog.DiscoverFromTransactions(ctx, tokenTxs); err != nil {
+			s.logger.Warn("Failed to discover tokens from transfers", zap.String("address", portfolio.Address), zap.Error(err))
+		}
+	}
+
 	internalTxs, err := s.transactionRepo.InternalTxsByAddress(ctx, portfolio.Address, opts)
 	if err != nil {
 		s.logger.Warn("Failed to fetch internal transactions, continuing with holdings only", zap.String("address", portfolio.Address), zap.Error(err))
@@ -360,10 +596,6 @@ func (s *Service) GetPortfolioAssets(ctx context.Context, portfolioID string, cu
 			continue
 		}
 		tokenAddr := strings.ToLower(holding.Token.Address)
-		// Treat zero address as native ETH (empty string key)
-		if tokenAddr == token.ZeroAddress {
-			tokenAddr = token.ZeroAddress
-		}
 
 		if existing, exists := aggregatedBalances[tokenAddr]; exists {
 			aggregatedBalances[tokenAddr] = new(big.Int).Add(existing, holding.Amount)
@@ -388,8 +620,33 @@ func (s *Service) GetPortfolioAssets(ctx context.Context, portfolioID string, cu
 	if ethBalance != nil && ethBalance.Sign() > 0 {
 		aggregatedBalances[token.ZeroAddress] = new(big.Int).Set(ethBalance)
 	}
+
+	// Balances held on exchanges add to the wallet's, the native balance included
+	if s.statements != nil {
+		statementTxs, err := s.statements.StatementTxsByPortfolio(ctx, portfolio.ID)
+		if err != nil {
+			s.logger.Warn("Failed to load exchange statements, continuing without them", zap.String("portfolio_id", portfolioID), zap.Error(err))
+		} else {
+			exchangeTxs := domainTransaction.Transactions(statementTxs)
+			statementBalances, err := exchangeTxs.CalculateTokensAmounts()
+			if err != nil {
+				s.logger.Error("Failed to calculate exchange balances", zap.Error(err))
+				return nil, nil, err
+			}
+			for tokenAddr, balance := range statementBalances {
+				if existing, exists := aggregatedBalances[tokenAddr]; exists {
+					aggregatedBalances[tokenAddr] = new(big.Int).Add(existing, balance)
+				} else {
+					aggregatedBalances[tokenAddr] = new(big.Int).Set(balance)
+				}
+			}
+			s.logger.Debug("Added exchange balances", zap.Int("transaction_count", len(statementTxs)), zap.Int("token_count", len(statementBalances)))
+		}
+	}
 	s.logger.Debug("Aggregated all balances", zap.Int("total_tokens", len(aggregatedBalances)))
 
+	// Negative balances come from truncated history or spam transfers, not debt; only
+	// manual liabilities reduce the net worth
 	filteredBalances := make(map[string]*big.Int)
 	for tokenAddr, balance := range aggregatedBalances {
 		if balance != nil && balance.Sign() > 0 {
@@ -400,18 +657,30 @@ func (s *Service) GetPortfolioAssets(ctx context.Context, portfolioID string, cu
 
 	tokenAddresses := make([]string, 0, len(filteredBalances))
 	for tokenAddr := range filteredBalances {
-		if tokenAddr != "" {
+		if tokenAddr != "" && tokenAddr != token.ZeroAddress {
 			tokenAddresses = append(tokenAddresses, tokenAddr)
 		}
 	}
 
-	// Fetch token metadata
+	// Fetch token metadata; custom tokens fill in what the registry does not know
 	tokensMap := s.tokenRepo.GetByAddresses(ctx, tokenAddresses)
+	if s.tokenCatalog != nil {
+		var unknown []string
+		for _, addr := range tokenAddresses {
+			if _, ok := tokensMap[addr]; !ok {
+				unknown = append(unknown, addr)
+			}
+		}
+		for addr, tok := range s.tokenCatalog.CustomTokens(ctx, portfolioID, unknown) {
+			tokensMap[addr] = tok
+		}
+	}
 	s.logger.Debug("Fetched token metadata", zap.Int("found_count", len(tokensMap)))
 
 	// Step 7: Build token list for price fetching
 	var tokensForPricing []*token.Token
 	tokenAddressToToken := make(map[string]*token.Token)
+	priceAddress := make(map[string]string) // token address -> address its price is looked up by
 
 	// Add ERC-20 tokens
 	for tokenAddr, tok := range tokensMap {
@@ -421,22 +690,22 @@ func (s *Service) GetPortfolioAssets(ctx context.Context, portfolioID string, cu
 		}
 	}
 
-	// Handle native ETH - use WETH for price lookup
-	if _, hasETH := filteredBalances[token.ZeroAddress]; hasETH {
-		// Create a token struct for ETH using WETH address for price lookup
-		ethToken := &token.Token{
-			ID:      "ethereum",
-			Name:    "Ethereum",
-			Symbol:  "ETH",
-			Address: WETHAddress, // Use WETH address for price lookup
-			Decimal: 18,
+	// The native asset has no contract; it is priced through its proxy token, e.g. WETH
+	if _, hasNative := filteredBalances[token.ZeroAddress]; hasNative {
+		if nativeToken, ok := s.aliases.NativeToken(portfolioChainID); ok {
+			native, _ := s.aliases.Native(portfolioChainID)
+			proxy := *nativeToken
+			proxy.Address = native.PriceProxy
+			tokensForPricing = append(tokensForPricing, &proxy)
+			tokenAddressToToken[token.ZeroAddress] = nativeToken
+			priceAddress[token.ZeroAddress] = native.PriceProxy
+		} else {
+			s.logger.Warn("Native asset not configured for chain, skipping native balance", zap.String("chain_id", portfolioChainID))
 		}
-		tokensForPricing = append(tokensForPricing, ethToken)
-		tokenAddressToToken[token.ZeroAddress] = ethToken
 	}
 
-	// Fetch prices
-	pricesMap, err := s.priceProvider.GetPrices(ctx, tokensForPricing, currency)
+	// Fetch prices, applying the portfolio's price overrides when supported
+	pricesMap, err := s.fetchPrices(ctx, portfolioID, tokensForPricing, currency)
 	if err != nil {
 		s.logger.Error("Failed to fetch prices", zap.Error(err))
 		return nil, nil, err
@@ -454,15 +723,16 @@ func (s *Service) GetPortfolioAssets(ctx context.Context, portfolioID string, cu
 			continue
 		}
 
-		if tok.Name == "ETH" {
-			tok.Address = token.ZeroAddress
+		lookupAddress := tok.Address
+		if proxy, ok := priceAddress[tokenAddr]; ok {
+			lookupAddress = proxy
 		}
 
 		// Find price for this token
 		var assetPrice *price.Price
 		for priceToken, p := range pricesMap {
 			// Match by address (case-insensitive)
-			if strings.EqualFold(priceToken.Address, tok.Address) {
+			if strings.EqualFold(priceToken.Address, lookupAddress) {
 				assetPrice = p
 				break
 			}
@@ -486,11 +756,12 @@ func (s *Service) GetPortfolioAssets(ctx context.Context, portfolioID string, cu
 		value := domainPortfolio.CalculateValue(tok.Decimal, balance, assetPrice)
 
 		asset := &domainPortfolio.Asset{
-			Token:  tok,
-			Amount: balance,
-			Price:  assetPrice,
-			Value:  value,
-			Source: "aggregated",
+			Token:          tok,
+			Amount:         balance,
+			Price:          assetPrice,
+			Value:          value,
+			ValueChange24h: domainPortfolio.CalculateValueChange(value, assetPrice.Change24h()),
+			Source:         "aggregated",
 		}
 		assets = append(assets, asset)
 
@@ -500,9 +771,49 @@ func (s *Service) GetPortfolioAssets(ctx context.Context, portfolioID string, cu
 			zap.String("value", value.String()))
 	}
 
+	// Off-chain assets and liabilities entered by hand
+	manual, err := s.manualAssets(ctx, portfolioID, currency)
+	if err != nil {
+		s.logger.Warn("Failed to load manual entries, continuing without them", zap.String("portfolio_id", portfolioID), zap.Error(err))
+	}
+	assets = append(assets, manual...)
+
 	s.logger.Info("Successfully created portfolio assets",
 		zap.String("portfolio_id", portfolioID),
 		zap.Int("asset_count", len(assets)))
 
 	return portfolio, assets, nil
 }
+
+// GetTopMovers returns the portfolio assets with the largest 24h gains and losses.
+func (s *Service) GetTopMovers(ctx context.Context, portfolioID string, currency string, limit int) ([]*domainPortfolio.Asset, []*domainPortfolio.Asset, error) {
+	s.logger.Info("Getting top movers", zap.String("portfolio_id", portfolioID), zap.Int("limit", limit))
+
+	_, assets, err := s.GetPortfolioAssets(ctx, portfolioID, currency)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	gainers, losers := domainPortfolio.TopMovers(assets, limit)
+	s.logger.Info("Successfully calculated top movers",
+		zap.String("portfolio_id", portfolioID),
+		zap.Int("gainers", len(gainers)),
+		zap.Int("losers", len(losers)))
+
+	return gainers, losers, nil
+}
+
+// MergeEquivalentAssets combines assets of equivalent tokens, such as ETH, WETH
+// and stETH, as defined by the alias registry.
+func (s *Service) MergeEquivalentAssets(assets []*domainPortfolio.Asset) []*domainPortfolio.Asset {
+	return domainPortfolio.MergeEquivalent(assets, func(address string) (*token.AliasGroup, bool) {
+		return s.aliases.Group(portfolioChainID, address)
+	})
+}
+
+func (s *Service) fetchPrices(ctx context.Context, portfolioID string, tokens []*token.Token, currency string) (map[*token.Token]*price.Price, error) {
+	if provider, ok := s.priceProvider.(price.PortfolioPriceProvider); ok {
+		return provider.GetPortfolioPrices(ctx, portfolioID, tokens, currency)
+	}
+	return s.priceProvider.GetPrices(ctx, tokens, currency)
+}
@@ -0,0 +1,221 @@
+package price
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"testtask/internal/domain/audit"
+	domainPrice "testtask/internal/domain/price"
+	domainToken "testtask/internal/domain/token"
+
+	"go.uber.org/zap"
+)
+
+// GetPortfolioPrices retrieves prices like GetPrices, but consults the portfolio's
+// active price overrides first. Fixed overrides in another currency are ignored;
+// relative overrides are priced off the market price of their reference token.
+func (s *Service) GetPortfolioPrices(
+	ctx context.Context,
+	portfolioID string,
+	tokens []*domainToken.Token,
+	currency string,
+) (map[*domainToken.Token]*domainPrice.Price, error) {
+	if s.overrides == nil || len(tokens) == 0 {
+		return s.GetPrices(ctx, tokens, currency)
+	}
+
+	active, err := s.overrides.Active(ctx, portfolioID, time.Now())
+	if err != nil {
+		s.logger.Warn("Failed to load price overrides, using market prices", zap.String("portfolioID", portfolioID), zap.Error(err))
+		return s.GetPrices(ctx, tokens, currency)
+	}
+	if len(active) == 0 {
+		return s.GetPrices(ctx, tokens, currency)
+	}
+
+	results := make(map[*domainToken.Token]*domainPrice.Price)
+	relative := make(map[*domainToken.Token]*domainPrice.Override)
+	references := make(map[string]*domainToken.Token)
+	remaining := make([]*domainToken.Token, 0, len(tokens))
+
+	for _, t := range tokens {
+		o, ok := active[strings.ToLower(t.Address)]
+		switch {
+		case !ok:
+			remaining = append(remaining, t)
+		case o.Kind == domainPrice.OverrideFixed && strings.EqualFold(o.Currency, currency):
+			results[t] = overridePrice(t, o, o.Apply(nil), currency)
+		case o.Kind == domainPrice.OverrideRelative:
+			relative[t] = o
+			if _, exists := references[o.ReferenceAddress]; !exists {
+				references[o.ReferenceAddress] = &domainToken.Token{Address: o.ReferenceAddress}
+			}
+		default:
+			s.logger.Debug("Price override currency mismatch, using market price",
+				zap.String("overrideID", o.ID), zap.String("currency", currency))
+			remaining = append(remaining, t)
+		}
+	}
+
+	// A reference token the portfolio holds is priced through the held token itself;
+	// providers key prices by address and would keep only one of two pointers
+	held := make(map[string]bool)
+	for _, t := range remaining {
+		address := strings.ToLower(t.Address)
+		if _, ok := references[address]; ok {
+			references[address] = t
+			held[address] = true
+		}
+	}
+
+	lookup := remaining
+	for address, ref := range references {
+		if !held[address] {
+			lookup = append(lookup, ref)
+		}
+	}
+
+	market, err := s.GetPrices(ctx, lookup, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range remaining {
+		if p, ok := market[t]; ok {
+			results[t] = p
+		}
+	}
+
+	for t, o := range relative {
+		ref, ok := market[references[o.ReferenceAddress]]
+		if !ok || ref.Value == nil {
+			s.logger.Warn("Reference price not found for price override",
+				zap.String("overrideID", o.ID), zap.String("reference", o.ReferenceAddress))
+			continue
+		}
+		results[t] = overridePrice(t, o, o.Apply(ref.Value), currency)
+	}
+
+	s.logger.Info("Applied price overrides", zap.String("portfolioID", portfolioID), zap.Int("override_count", len(tokens)-len(remaining)))
+	return results, nil
+}
+
+// CreateOverride validates and stores a new price override
+func (s *Service) CreateOverride(ctx context.Context, o *domainPrice.Override) error {
+	if s.overrides == nil {
+		return fmt.Errorf("price overrides are not configured")
+	}
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	if err := s.overrides.Create(ctx, o); err != nil {
+		return err
+	}
+
+	s.recordOverride(ctx, audit.ActionOverrideCreate, o.PortfolioID, o.ID, nil, snapshotOverride(o))
+	return nil
+}
+
+// UpdateOverride replaces the settings of an existing override, keeping its identity
+func (s *Service) UpdateOverride(ctx context.Context, o *domainPrice.Override) error {
+	if s.overrides == nil {
+		return fmt.Errorf("price overrides are not configured")
+	}
+
+	existing, err := s.overrides.GetByID(ctx, o.PortfolioID, o.ID)
+	if err != nil {
+		return err
+	}
+
+	o.TokenAddress = existing.TokenAddress
+	o.CreatedAt = existing.CreatedAt
+	o.UpdatedAt = time.Now().UTC()
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	if err := s.overrides.Update(ctx, o); err != nil {
+		return err
+	}
+
+	s.recordOverride(ctx, audit.ActionOverrideUpdate, o.PortfolioID, o.ID, snapshotOverride(existing), snapshotOverride(o))
+	return nil
+}
+
+func (s *Service) DeleteOverride(ctx context.Context, portfolioID, id string) error {
+	if s.overrides == nil {
+		return fmt.Errorf("%w: id=%s", domainPrice.ErrOverrideNotFound, id)
+	}
+
+	existing, err := s.overrides.GetByID(ctx, portfolioID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.overrides.Delete(ctx, portfolioID, id); err != nil {
+		return err
+	}
+
+	s.recordOverride(ctx, audit.ActionOverrideDelete, portfolioID, id, snapshotOverride(existing), nil)
+	return nil
+}
+
+func (s *Service) ListOverrides(ctx context.Context, portfolioID string) ([]*domainPrice.Override, error) {
+	if s.overrides == nil {
+		return nil, nil
+	}
+	return s.overrides.ListByPortfolio(ctx, portfolioID)
+}
+
+func (s *Service) ListOverrideAudit(ctx context.Context, portfolioID string) ([]*domainPrice.OverrideAuditEntry, error) {
+	if s.overrides == nil {
+		return nil, nil
+	}
+	return s.overrides.ListAudit(ctx, portfolioID)
+}
+
+// overrideSnapshot is the audit log representation of a price override
+type overrideSnapshot struct {
+	TokenAddress     string     `json:"token_address"`
+	Kind             string     `json:"kind"`
+	Value            string     `json:"value,omitempty"`
+	Currency         string     `json:"currency,omitempty"`
+	ReferenceAddress string     `json:"reference_address,omitempty"`
+	AdjustmentBps    int64      `json:"adjustment_bps,omitempty"`
+	EffectiveFrom    time.Time  `json:"effective_from"`
+	EffectiveTo      *time.Time `json:"effective_to,omitempty"`
+	Reason           string     `json:"reason,omitempty"`
+}
+
+func snapshotOverride(o *domainPrice.Override) *overrideSnapshot {
+	s := &overrideSnapshot{
+		TokenAddress:     o.TokenAddress,
+		Kind:             string(o.Kind),
+		Currency:         o.Currency,
+		ReferenceAddress: o.ReferenceAddress,
+		AdjustmentBps:    o.AdjustmentBps,
+		EffectiveFrom:    o.EffectiveFrom,
+		EffectiveTo:      o.EffectiveTo,
+		Reason:           o.Reason,
+	}
+	if o.Value != nil {
+		s.Value = o.Value.String()
+	}
+	return s
+}
+
+func (s *Service) recordOverride(ctx context.Context, action, portfolioID, id string, before, after *overrideSnapshot) {
+	s.recorder.Record(ctx, audit.NewEntry(action, audit.ResourcePriceOverride, id, portfolioID), before, after)
+}
+
+func overridePrice(t *domainToken.Token, o *domainPrice.Override, value *big.Int, currency string) *domainPrice.Price {
+	return &domainPrice.Price{
+		Token:       t,
+		Value:       value,
+		Currency:    strings.ToUpper(currency),
+		LastUpdated: o.UpdatedAt,
+		Source:      domainPrice.SourceOverride,
+		OverrideID:  o.ID,
+	}
+}
@@ -2,10 +2,12 @@ package price
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	loggeradapter "testtask/internal/adapters/logger"
 	"testtask/internal/application/ratelimiter"
 	"testtask/internal/domain"
+	"testtask/internal/domain/audit"
 	domainPrice "testtask/internal/domain/price"
 	domainToken "testtask/internal/domain/token"
 	"time"
@@ -20,7 +22,10 @@ type Service struct {
 	cache            domain.Cache[string, domainPrice.Price]
 	primaryProvider  domainPrice.Provider
 	fallbackProvider domainPrice.Provider
+	manualProvider   domainPrice.Provider
+	overrides        domainPrice.OverrideRepository
 	rateLimiter      *ratelimiter.RateLimiter
+	recorder         audit.Recorder
 	logger           *loggeradapter.Logger
 }
 
@@ -64,23 +69,33 @@ func NewService(
 	cache domain.Cache[string, domainPrice.Price],
 	primaryProvider domainPrice.Provider,
 	fallbackProvider domainPrice.Provider,
+	manualProvider domainPrice.Provider,
+	overrides domainPrice.OverrideRepository,
 	rateLimiter *ratelimiter.RateLimiter,
+	recorder audit.Recorder,
 	logger *loggeradapter.Logger,
 ) *Service {
 	if logger == nil {
 		logger = loggeradapter.NewNopLogger()
 	}
+	if recorder == nil {
+		recorder = audit.NopRecorder{}
+	}
 	return &Service{
 		cacheTTL:         cacheTTL,
 		cache:            cache,
 		primaryProvider:  primaryProvider,
 		fallbackProvider: fallbackProvider,
+		manualProvider:   manualProvider,
+		overrides:        overrides,
 		rateLimiter:      rateLimiter,
+		recorder:         recorder,
 		logger:           logger,
 	}
 }
 
 // GetPrices retrieves prices for multiple tokens with cache-aside pattern
+// 0. Manual prices of custom tokens are used as is, bypassing the cache
 // 1. First tries to get from cache
 // 2. If cache misses, goes to primary provider (CoinGecko API)
 // 3. If primary fails, goes to fallback provider (mock)
@@ -97,7 +112,10 @@ func (s *Service) GetPrices(
 
 	s.logger.Info("Getting prices", zap.Int("token_count", len(tokens)), zap.String("currency", currency))
 
-	results := make(map[*domainToken.Token]*domainPrice.Price)
+	results, tokens := s.manualPrices(ctx, tokens, currency)
+	if len(tokens) == 0 {
+		return results, nil
+	}
 	var missedTokens []*domainToken.Token
 
 	cacheKeys := make([]string, 0, len(tokens))
@@ -121,6 +139,7 @@ func (s *Service) GetPrices(
 
 		if now.Sub(cachedPrice.LastUpdated) < s.cacheTTL {
 			priceCopy := cachedPrice
+			priceCopy.Cached = true
 			results[t] = &priceCopy
 			cacheHits++
 		} else {
@@ -145,7 +164,10 @@ func (s *Service) GetPrices(
 		usedFallback := false
 
 		// If rate limiter is provided, check rate limit before calling primary provider
-		rateLimitErr := s.rateLimiter.Allow(ctx)
+		var rateLimitErr error
+		if s.rateLimiter != nil {
+			rateLimitErr = s.rateLimiter.Allow(ctx)
+		}
 		if rateLimitErr == nil {
 			// Rate limit allows, try primary provider
 			s.logger.Debug("Rate limit allows, calling primary provider", zap.Int("token_count", len(missedTokens)))
@@ -189,10 +211,128 @@ func (s *Service) GetPrices(
 	return results, nil
 }
 
+// GetHistoricalPrices retrieves prices for multiple tokens at a point in time.
+// Timestamps are bucketed by hour; historical prices never change, so cached
+// entries do not expire. Only market prices from the primary provider are
+// returned; tokens it cannot price are left out of the result.
+func (s *Service) GetHistoricalPrices(
+	ctx context.Context,
+	tokens []*domainToken.Token,
+	currency string,
+	at time.Time,
+) (map[*domainToken.Token]*domainPrice.Price, error) {
+	if len(tokens) == 0 {
+		return make(map[*domainToken.Token]*domainPrice.Price), nil
+	}
+
+	// Custom tokens have no market history; their manual price is the best estimate
+	results, tokens := s.manualPrices(ctx, tokens, currency)
+	if len(tokens) == 0 {
+		return results, nil
+	}
+
+	bucket := at.UTC().Truncate(time.Hour)
+	s.logger.Info("Getting historical prices", zap.Int("token_count", len(tokens)), zap.String("currency", currency), zap.Time("at", bucket))
+
+	cacheKeys := make([]string, 0, len(tokens))
+	keyToToken := make(map[string]*domainToken.Token)
+	for _, t := range tokens {
+		key := s.historicalCacheKey(t.Address, currency, bucket)
+		cacheKeys = append(cacheKeys, key)
+		keyToToken[key] = t
+	}
+
+	for key, cachedPrice := range s.cache.GetBatch(ctx, cacheKeys) {
+		if t := keyToToken[key]; t != nil {
+			priceCopy := cachedPrice
+			priceCopy.Cached = true
+			results[t] = &priceCopy
+		}
+	}
+
+	var missedTokens []*domainToken.Token
+	for _, t := range tokens {
+		if _, found := results[t]; !found {
+			missedTokens = append(missedTokens, t)
+		}
+	}
+	if len(missedTokens) == 0 {
+		return results, nil
+	}
+
+	// Only the primary provider quotes real market history; a fallback price
+	// would be mistaken for the market price at that time and cached forever.
+	primary, ok := s.primaryProvider.(domainPrice.HistoricalProvider)
+	if !ok {
+		return nil, errors.New("no historical price provider available")
+	}
+	if s.rateLimiter != nil {
+		if err := s.rateLimiter.Allow(ctx); err != nil {
+			return nil, fmt.Errorf("rate limit exceeded: %w", err)
+		}
+	}
+	fetched, err := primary.GetHistoricalPrices(ctx, missedTokens, currency, bucket)
+	if err != nil {
+		s.logger.Warn("Primary provider failed to get historical prices", zap.Error(err))
+		return nil, fmt.Errorf("failed to get historical prices: %w", err)
+	}
+
+	cacheItems := make(map[string]domainPrice.Price, len(fetched))
+	for t, p := range fetched {
+		if !p.IsMarket() {
+			continue
+		}
+		results[t] = p
+		cacheItems[s.historicalCacheKey(t.Address, currency, bucket)] = *p
+	}
+	s.cache.SetBatch(ctx, cacheItems)
+
+	s.logger.Info("Successfully retrieved historical prices", zap.Int("total_prices", len(results)), zap.String("currency", currency))
+	return results, nil
+}
+
+// manualPrices resolves tokens priced by the manual provider and returns the remaining tokens.
+// Manual provider failures are logged and the tokens fall through to the market providers.
+func (s *Service) manualPrices(
+	ctx context.Context,
+	tokens []*domainToken.Token,
+	currency string,
+) (map[*domainToken.Token]*domainPrice.Price, []*domainToken.Token) {
+	results := make(map[*domainToken.Token]*domainPrice.Price)
+	if s.manualProvider == nil {
+		return results, tokens
+	}
+
+	manual, err := s.manualProvider.GetPrices(ctx, tokens, currency)
+	if err != nil {
+		s.logger.Warn("Manual price provider failed", zap.Error(err))
+		return results, tokens
+	}
+	if len(manual) == 0 {
+		return results, tokens
+	}
+
+	remaining := make([]*domainToken.Token, 0, len(tokens)-len(manual))
+	for _, t := range tokens {
+		if p, ok := manual[t]; ok {
+			results[t] = p
+			continue
+		}
+		remaining = append(remaining, t)
+	}
+
+	s.logger.Debug("Resolved manual prices", zap.Int("price_count", len(results)))
+	return results, remaining
+}
+
 func (s *Service) cacheKey(tokenID, currency string) string {
 	return fmt.Sprintf("%s:%s", tokenID, currency)
 }
 
+func (s *Service) historicalCacheKey(tokenID, currency string, at time.Time) string {
+	return fmt.Sprintf("%s:%s@%d", tokenID, currency, at.Unix())
+}
+
 func (s *Service) cacheFetchedPrices(
 	ctx context.Context,
 	prices map[*domainToken.Token]*domainPrice.Price,
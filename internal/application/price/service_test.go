@@ -446,7 +446,7 @@ func TestService_GetPrices(t *testing.T) {
 
 			cache.resetCallCounters()
 
-			service := NewService(cache, primary, fallback, nil, nil)
+			service := NewService(cache, primary, fallback, nil, nil, nil, nil, nil)
 
 			results, err := service.GetPrices(context.Background(), tt.tokens, tt.currency)
 
@@ -743,3 +743,286 @@ func TestRateLimitedService_GetPrices(t *testing.T) {
 		})
 	}
 }
+
+// mockHistoricalProvider implements domainprice.HistoricalProvider
+type mockHistoricalProvider struct {
+	*mockProvider
+	historicalCalls int
+	lastAt          time.Time
+}
+
+func (m *mockHistoricalProvider) GetHistoricalPrices(
+	ctx context.Context,
+	tokens []*token.Token,
+	currency string,
+	at time.Time,
+) (map[*token.Token]*domainprice.Price, error) {
+	m.historicalCalls++
+	m.lastAt = at
+	return m.GetPrices(ctx, tokens, currency)
+}
+
+func TestService_GetHistoricalPrices(t *testing.T) {
+	at := time.Date(2024, 3, 1, 14, 35, 0, 0, time.UTC)
+	btcToken := &token.Token{ID: "bitcoin", Symbol: "BTC", Address: "0xbtc"}
+
+	t.Run("fetches from primary and caches without expiry", func(t *testing.T) {
+		cache := newMockCache()
+		primary := &mockHistoricalProvider{mockProvider: newMockProvider()}
+		historical := domainprice.NewPrice(btcToken, big.NewInt(6200000000000), "USD")
+		historical.LastUpdated = at
+		historical.Source = domainprice.SourceCoinGecko
+		primary.setPrice("0xbtc", &historical)
+
+		service := NewService(cache, primary, newMockProvider(), nil, nil, nil, nil, nil)
+
+		results, err := service.GetHistoricalPrices(context.Background(), []*token.Token{btcToken}, "USD", at)
+		if err != nil {
+			t.Fatalf("GetHistoricalPrices() error = %v", err)
+		}
+		if len(results) != 1 || results[btcToken].Value.Cmp(big.NewInt(6200000000000)) != 0 {
+			t.Fatalf("GetHistoricalPrices() = %v, want 6200000000000", results)
+		}
+		if !primary.lastAt.Equal(at.Truncate(time.Hour)) {
+			t.Errorf("Provider called with %v, want hour bucket %v", primary.lastAt, at.Truncate(time.Hour))
+		}
+
+		// The same hour is served from cache even though LastUpdated is old
+		results, err = service.GetHistoricalPrices(context.Background(), []*token.Token{btcToken}, "USD", at.Add(10*time.Minute))
+		if err != nil {
+			t.Fatalf("GetHistoricalPrices() error = %v", err)
+		}
+		if primary.historicalCalls != 1 {
+			t.Errorf("Primary provider should be called once, got %d", primary.historicalCalls)
+		}
+		if !results[btcToken].Cached {
+			t.Error("Second lookup should be marked as cached")
+		}
+	})
+
+	t.Run("failing primary leaves the token unpriced", func(t *testing.T) {
+		cache := newMockCache()
+		primary := &mockHistoricalProvider{mockProvider: newMockProvider()}
+		primary.setError(errors.New("upstream unavailable"))
+		fallback := &mockHistoricalProvider{mockProvider: newMockProvider()}
+		fallbackPrice := domainprice.NewPrice(btcToken, big.NewInt(1000000000), "USD")
+		fallbackPrice.Source = domainprice.SourceMock
+		fallback.setPrice("0xbtc", &fallbackPrice)
+
+		service := NewService(cache, primary, fallback, nil, nil, nil, nil, nil)
+
+		results, err := service.GetHistoricalPrices(context.Background(), []*token.Token{btcToken}, "USD", at)
+		if err == nil {
+			t.Fatal("GetHistoricalPrices() expected error, got nil")
+		}
+		if p, ok := results[btcToken]; ok {
+			t.Errorf("GetHistoricalPrices() priced the token at %s, want no price", p.Value)
+		}
+		if fallback.historicalCalls != 0 {
+			t.Errorf("Fallback should not be asked for history, got %d calls", fallback.historicalCalls)
+		}
+		if len(cache.items) != 0 {
+			t.Errorf("Nothing should be cached, got %v", cache.items)
+		}
+	})
+
+	t.Run("non-market prices are neither returned nor cached", func(t *testing.T) {
+		cache := newMockCache()
+		primary := &mockHistoricalProvider{mockProvider: newMockProvider()}
+		mockPrice := domainprice.NewPrice(btcToken, big.NewInt(1000000000), "USD")
+		mockPrice.Source = domainprice.SourceMock
+		primary.setPrice("0xbtc", &mockPrice)
+
+		service := NewService(cache, primary, newMockProvider(), nil, nil, nil, nil, nil)
+
+		results, err := service.GetHistoricalPrices(context.Background(), []*token.Token{btcToken}, "USD", at)
+		if err != nil {
+			t.Fatalf("GetHistoricalPrices() error = %v", err)
+		}
+		if len(results) != 0 {
+			t.Errorf("GetHistoricalPrices() = %v, want no price", results)
+		}
+		if len(cache.items) != 0 {
+			t.Errorf("Nothing should be cached, got %v", cache.items)
+		}
+	})
+
+	t.Run("fails when no provider supports history", func(t *testing.T) {
+		service := NewService(newMockCache(), newMockProvider(), newMockProvider(), nil, nil, nil, nil, nil)
+
+		if _, err := service.GetHistoricalPrices(context.Background(), []*token.Token{btcToken}, "USD", at); err == nil {
+			t.Error("GetHistoricalPrices() expected error, got nil")
+		}
+	})
+}
+
+func TestService_GetPrices_ManualProvider(t *testing.T) {
+	customToken := &token.Token{Symbol: "INT", Address: "0xinternal"}
+	ethToken := &token.Token{ID: "ethereum", Symbol: "ETH", Address: "0xeth"}
+
+	manual := newMockProvider()
+	manualPrice := domainprice.NewPrice(customToken, big.NewInt(150000000), "USD")
+	manualPrice.Source = domainprice.SourceManual
+	manual.setPrice("0xinternal", &manualPrice)
+
+	primary := newMockProvider()
+	primaryPrice := domainprice.NewPrice(ethToken, big.NewInt(300000000000), "USD")
+	primary.setPrice("0xeth", &primaryPrice)
+	// A market price for the custom token must be ignored
+	primary.setPrice("0xinternal", &primaryPrice)
+
+	cache := newMockCache()
+	service := NewService(cache, primary, newMockProvider(), manual, nil, nil, nil, nil)
+
+	results, err := service.GetPrices(context.Background(), []*token.Token{customToken, ethToken}, "USD")
+	if err != nil {
+		t.Fatalf("GetPrices() error = %v", err)
+	}
+	if got := results[customToken]; got == nil || got.Value.Cmp(big.NewInt(150000000)) != 0 || got.Source != domainprice.SourceManual {
+		t.Errorf("custom token price = %+v, want manual 150000000", got)
+	}
+	if got := results[ethToken]; got == nil || got.Value.Cmp(big.NewInt(300000000000)) != 0 {
+		t.Errorf("ETH price = %+v, want primary 300000000000", got)
+	}
+	if _, cached := cache.items["0xinternal:USD"]; cached {
+		t.Error("manual prices should not be cached")
+	}
+
+	t.Run("manual provider failure falls through to market providers", func(t *testing.T) {
+		manual.setError(errors.New("database locked"))
+		results, err := service.GetPrices(context.Background(), []*token.Token{ethToken}, "USD")
+		if err != nil {
+			t.Fatalf("GetPrices() error = %v", err)
+		}
+		if results[ethToken] == nil {
+			t.Error("ETH should still be priced")
+		}
+	})
+}
+
+// mockOverrideRepository implements domainprice.OverrideRepository with a fixed set of active overrides
+type mockOverrideRepository struct {
+	domainprice.OverrideRepository
+	active map[string]*domainprice.Override
+}
+
+func (m *mockOverrideRepository) Active(ctx context.Context, portfolioID string, at time.Time) (map[string]*domainprice.Override, error) {
+	if portfolioID != "portfolio-1" {
+		return nil, nil
+	}
+	return m.active, nil
+}
+
+func TestService_GetPortfolioPrices_Overrides(t *testing.T) {
+	locked := &token.Token{Symbol: "LCK", Address: "0xlocked"}
+	otc := &token.Token{Symbol: "OTC", Address: "0xotc"}
+	ethToken := &token.Token{Symbol: "ETH", Address: "0xeth"}
+
+	overrides := &mockOverrideRepository{active: map[string]*domainprice.Override{
+		"0xlocked": {ID: "fixed-1", Kind: domainprice.OverrideFixed, Value: big.NewInt(50000000), Currency: "usd"},
+		// 20% discount to ETH
+		"0xotc": {ID: "relative-1", Kind: domainprice.OverrideRelative, ReferenceAddress: "0xeth", AdjustmentBps: -2000},
+	}}
+
+	primary := newMockProvider()
+	ethPrice := domainprice.NewPrice(ethToken, big.NewInt(300000000000), "USD")
+	primary.setPrice("0xeth", &ethPrice)
+	// A market price for the locked token must be ignored
+	primary.setPrice("0xlocked", &ethPrice)
+
+	service := NewService(newMockCache(), primary, newMockProvider(), nil, overrides, nil, nil, nil)
+
+	tests := []struct {
+		name        string
+		portfolioID string
+		currency    string
+		want        map[*token.Token]int64
+		overridden  map[*token.Token]bool
+	}{
+		{
+			name:        "fixed and relative overrides",
+			portfolioID: "portfolio-1",
+			currency:    "USD",
+			want:        map[*token.Token]int64{locked: 50000000, otc: 240000000000, ethToken: 300000000000},
+			overridden:  map[*token.Token]bool{locked: true, otc: true},
+		},
+		{
+			name:        "other portfolio uses market prices",
+			portfolioID: "portfolio-2",
+			currency:    "USD",
+			want:        map[*token.Token]int64{locked: 300000000000, ethToken: 300000000000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := service.GetPortfolioPrices(context.Background(), tt.portfolioID, []*token.Token{locked, otc, ethToken}, tt.currency)
+			if err != nil {
+				t.Fatalf("GetPortfolioPrices() error = %v", err)
+			}
+			if len(results) != len(tt.want) {
+				t.Errorf("got %d prices, want %d", len(results), len(tt.want))
+			}
+			for tok, want := range tt.want {
+				got := results[tok]
+				if got == nil || got.Value.Cmp(big.NewInt(want)) != 0 {
+					t.Errorf("%s price = %+v, want %d", tok.Symbol, got, want)
+					continue
+				}
+				if got.Overridden() != tt.overridden[tok] {
+					t.Errorf("%s Overridden() = %v, want %v", tok.Symbol, got.Overridden(), tt.overridden[tok])
+				}
+				if got.Overridden() && got.Source != domainprice.SourceOverride {
+					t.Errorf("%s Source = %q, want %q", tok.Symbol, got.Source, domainprice.SourceOverride)
+				}
+			}
+		})
+	}
+}
+
+// addressKeyedProvider prices tokens by address and keeps the last pointer per address,
+// like the CoinGecko provider does
+type addressKeyedProvider struct {
+	prices map[string]int64
+}
+
+func (m *addressKeyedProvider) GetPrices(ctx context.Context, tokens []*token.Token, currency string) (map[*token.Token]*domainprice.Price, error) {
+	byAddress := make(map[string]*token.Token)
+	for _, t := range tokens {
+		byAddress[t.Address] = t
+	}
+	result := make(map[*token.Token]*domainprice.Price)
+	for address, t := range byAddress {
+		if value, ok := m.prices[address]; ok {
+			p := domainprice.NewPrice(t, big.NewInt(value), "USD")
+			result[t] = &p
+		}
+	}
+	return result, nil
+}
+
+func TestService_GetPortfolioPrices_HeldReferenceToken(t *testing.T) {
+	weth := &token.Token{Symbol: "WETH", Address: "0xweth"}
+	steth := &token.Token{Symbol: "stETH", Address: "0xsteth"}
+
+	overrides := &mockOverrideRepository{active: map[string]*domainprice.Override{
+		// 1% discount to WETH
+		"0xsteth": {ID: "relative-1", Kind: domainprice.OverrideRelative, ReferenceAddress: "0xweth", AdjustmentBps: -100},
+	}}
+	primary := &addressKeyedProvider{prices: map[string]int64{"0xweth": 300000000000}}
+	service := NewService(newMockCache(), primary, newMockProvider(), nil, overrides, nil, nil, nil)
+
+	// The second call is served from the cache, which is keyed by address as well
+	for i := 0; i < 2; i++ {
+		results, err := service.GetPortfolioPrices(context.Background(), "portfolio-1", []*token.Token{weth, steth}, "USD")
+		if err != nil {
+			t.Fatalf("GetPortfolioPrices() error = %v", err)
+		}
+		if got := results[weth]; got == nil || got.Value.Cmp(big.NewInt(300000000000)) != 0 {
+			t.Errorf("call %d: WETH price = %+v, want market 300000000000", i+1, got)
+		}
+		if got := results[steth]; got == nil || got.Value.Cmp(big.NewInt(297000000000)) != 0 || !got.Overridden() {
+			t.Errorf("call %d: stETH price = %+v, want override 297000000000", i+1, got)
+		}
+	}
+}
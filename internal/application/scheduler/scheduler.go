@@ -0,0 +1,119 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	loggeradapter "testtask/internal/adapters/logger"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Job is a unit of background work run periodically by the Scheduler
+type Job func(ctx context.Context) error
+
+type scheduledJob struct {
+	name     string
+	interval time.Duration
+	run      Job
+}
+
+// Scheduler runs registered jobs on fixed intervals until stopped.
+// A job never overlaps with itself; a slow run delays its next tick.
+type Scheduler struct {
+	mu      sync.Mutex
+	jobs    []scheduledJob
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	started bool
+	logger  *loggeradapter.Logger
+}
+
+func NewScheduler(logger *loggeradapter.Logger) *Scheduler {
+	if logger == nil {
+		logger = loggeradapter.NewNopLogger()
+	}
+	return &Scheduler{logger: logger}
+}
+
+// Add registers a job; jobs added after Start are ignored.
+func (s *Scheduler) Add(name string, interval time.Duration, job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		s.logger.Warn("Scheduler already started, job ignored", zap.String("job", name))
+		return
+	}
+	if interval <= 0 {
+		s.logger.Warn("Job interval must be positive, job ignored", zap.String("job", name), zap.Duration("interval", interval))
+		return
+	}
+
+	s.jobs = append(s.jobs, scheduledJob{name: name, interval: interval, run: job})
+}
+
+// Start runs every job once immediately and then on its interval.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, job)
+	}
+
+	s.logger.Info("Scheduler started", zap.Int("jobs", len(s.jobs)))
+}
+
+// Stop cancels running jobs and waits for them to return.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	s.wg.Wait()
+	s.logger.Info("Scheduler stopped")
+}
+
+func (s *Scheduler) loop(ctx context.Context, job scheduledJob) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(job.interval)
+	defer ticker.Stop()
+
+	for {
+		s.runOnce(ctx, job)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context, job scheduledJob) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("Scheduled job panicked", zap.String("job", job.name), zap.Any("panic", r))
+		}
+	}()
+
+	started := time.Now()
+	if err := job.run(ctx); err != nil {
+		s.logger.Error("Scheduled job failed", zap.String("job", job.name), zap.Error(err))
+		return
+	}
+	s.logger.Debug("Scheduled job completed", zap.String("job", job.name), zap.Duration("duration", time.Since(started)))
+}
@@ -0,0 +1,31 @@
+package sharing
+
+import (
+	"context"
+	"time"
+
+	"testtask/internal/domain/audit"
+)
+
+// memberSnapshot is the audit log representation of a membership
+type memberSnapshot struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email,omitempty"`
+	Role   string `json:"role"`
+}
+
+// shareTokenSnapshot is the audit log representation of a share token; the secret is never recorded
+type shareTokenSnapshot struct {
+	Name      string     `json:"name,omitempty"`
+	Prefix    string     `json:"prefix,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+func (s *Service) recordMember(ctx context.Context, action, portfolioID, userID string, before, after *memberSnapshot) {
+	s.recorder.Record(ctx, audit.NewEntry(action, audit.ResourceMember, userID, portfolioID), before, after)
+}
+
+func (s *Service) recordShareToken(ctx context.Context, action, portfolioID, tokenID string, before, after *shareTokenSnapshot) {
+	s.recorder.Record(ctx, audit.NewEntry(action, audit.ResourceShareToken, tokenID, portfolioID), before, after)
+}
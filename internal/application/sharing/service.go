@@ -0,0 +1,241 @@
+package sharing
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	loggeradapter "testtask/internal/adapters/logger"
+	"testtask/internal/domain/audit"
+	"testtask/internal/domain/portfolio"
+	"testtask/internal/domain/user"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// shareTokenPrefix marks read-only share tokens, as opposed to API keys
+	shareTokenPrefix = "cps_"
+	// shareTokenDisplayLength is the number of leading characters kept for listings
+	shareTokenDisplayLength = 12
+)
+
+// Service resolves the caller's role on portfolios, manages members and issues
+// read-only share tokens
+type Service struct {
+	access   portfolio.AccessRepository
+	users    user.Repository
+	recorder audit.Recorder
+	logger   *loggeradapter.Logger
+	now      func() time.Time
+}
+
+func NewService(access portfolio.AccessRepository, users user.Repository, recorder audit.Recorder, logger *loggeradapter.Logger) *Service {
+	if logger == nil {
+		logger = loggeradapter.NewNopLogger()
+	}
+	if recorder == nil {
+		recorder = audit.NopRecorder{}
+	}
+	return &Service{
+		access:   access,
+		users:    users,
+		recorder: recorder,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// Authorize returns the caller's role on the portfolio. It fails with ErrPortfolioNotFound
+// when the caller has no access and with ErrAccessDenied when the role is below required.
+func (s *Service) Authorize(ctx context.Context, portfolioID string, required portfolio.Role) (portfolio.Role, error) {
+	caller, ok := user.FromContext(ctx)
+	if !ok {
+		return "", fmt.Errorf("%w: no authenticated caller", portfolio.ErrAccessDenied)
+	}
+
+	role, err := s.access.Role(ctx, portfolioID, caller.ID)
+	if err != nil {
+		return "", err
+	}
+	if !role.Allows(required) {
+		return role, fmt.Errorf("%w: %s role required, caller is %s", portfolio.ErrAccessDenied, required, role)
+	}
+
+	return role, nil
+}
+
+func (s *Service) ListMembers(ctx context.Context, portfolioID string) ([]*portfolio.Member, error) {
+	return s.access.ListMembers(ctx, portfolioID)
+}
+
+// AddMember grants the user with the given email a role on the portfolio
+func (s *Service) AddMember(ctx context.Context, portfolioID, email, role string) (*portfolio.Member, error) {
+	r, err := portfolio.ParseRole(role)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := s.users.GetByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		return nil, err
+	}
+
+	// The owner already has full access and cannot be demoted through membership
+	if current, err := s.access.Role(ctx, portfolioID, u.ID); err == nil && current == portfolio.RoleOwner {
+		return nil, fmt.Errorf("%w: user owns the portfolio", portfolio.ErrMemberExists)
+	}
+
+	now := s.now().UTC()
+	m := &portfolio.Member{
+		PortfolioID: portfolioID,
+		UserID:      u.ID,
+		Email:       u.Email,
+		Role:        r,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.access.AddMember(ctx, m); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Added portfolio member", zap.String("portfolio_id", portfolioID), zap.String("user_id", u.ID), zap.String("role", role))
+	s.recordMember(ctx, audit.ActionMemberAdd, portfolioID, u.ID, nil, &memberSnapshot{UserID: u.ID, Email: u.Email, Role: string(r)})
+	return m, nil
+}
+
+// UpdateMember changes the role of an existing member
+func (s *Service) UpdateMember(ctx context.Context, portfolioID, userID, role string) (*portfolio.Member, error) {
+	r, err := portfolio.ParseRole(role)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := s.currentRole(ctx, portfolioID, userID)
+	m := &portfolio.Member{
+		PortfolioID: portfolioID,
+		UserID:      userID,
+		Role:        r,
+		UpdatedAt:   s.now().UTC(),
+	}
+	if err := s.access.UpdateMember(ctx, m); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Updated portfolio member", zap.String("portfolio_id", portfolioID), zap.String("user_id", userID), zap.String("role", role))
+	s.recordMember(ctx, audit.ActionMemberUpdate, portfolioID, userID,
+		&memberSnapshot{UserID: userID, Role: previous},
+		&memberSnapshot{UserID: userID, Role: string(r)})
+	return m, nil
+}
+
+func (s *Service) RemoveMember(ctx context.Context, portfolioID, userID string) error {
+	previous := s.currentRole(ctx, portfolioID, userID)
+	if err := s.access.RemoveMember(ctx, portfolioID, userID); err != nil {
+		return err
+	}
+
+	s.logger.Info("Removed portfolio member", zap.String("portfolio_id", portfolioID), zap.String("user_id", userID))
+	s.recordMember(ctx, audit.ActionMemberRemove, portfolioID, userID, &memberSnapshot{UserID: userID, Role: previous}, nil)
+	return nil
+}
+
+// currentRole returns the user's role for the audit log, or an empty string without access
+func (s *Service) currentRole(ctx context.Context, portfolioID, userID string) string {
+	role, err := s.access.Role(ctx, portfolioID, userID)
+	if err != nil {
+		return ""
+	}
+	return string(role)
+}
+
+// CreateShareToken issues a read-only link to the portfolio. A nil expiresAt uses the
+// default lifetime. The plain token is only returned here.
+func (s *Service) CreateShareToken(ctx context.Context, portfolioID, name string, expiresAt *time.Time) (*portfolio.ShareToken, string, error) {
+	now := s.now()
+	expires := now.Add(portfolio.DefaultShareTokenTTL)
+	if expiresAt != nil {
+		expires = *expiresAt
+	}
+	switch {
+	case !expires.After(now):
+		return nil, "", fmt.Errorf("%w: expires_at must be in the future", portfolio.ErrInvalidShareToken)
+	case expires.Sub(now) > portfolio.MaxShareTokenTTL:
+		return nil, "", fmt.Errorf("%w: expires_at must be within %s", portfolio.ErrInvalidShareToken, portfolio.MaxShareTokenTTL)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", fmt.Errorf("failed to generate share token: %w", err)
+	}
+	plain := shareTokenPrefix + hex.EncodeToString(secret)
+
+	var createdBy string
+	if caller, ok := user.FromContext(ctx); ok {
+		createdBy = caller.ID
+	}
+
+	t := portfolio.NewShareToken(portfolioID, strings.TrimSpace(name), createdBy, expires)
+	t.Prefix = plain[:shareTokenDisplayLength]
+	t.Hash = hashShareToken(plain)
+	if err := s.access.CreateShareToken(ctx, t); err != nil {
+		return nil, "", err
+	}
+
+	s.logger.Info("Created share token", zap.String("portfolio_id", portfolioID), zap.String("token_id", t.ID), zap.Time("expires_at", t.ExpiresAt))
+	s.recordShareToken(ctx, audit.ActionShareTokenCreate, portfolioID, t.ID, nil, &shareTokenSnapshot{Name: t.Name, Prefix: t.Prefix, ExpiresAt: &t.ExpiresAt})
+	return t, plain, nil
+}
+
+func (s *Service) ListShareTokens(ctx context.Context, portfolioID string) ([]*portfolio.ShareToken, error) {
+	return s.access.ListShareTokens(ctx, portfolioID)
+}
+
+func (s *Service) RevokeShareToken(ctx context.Context, portfolioID, tokenID string) error {
+	now := s.now()
+	if err := s.access.RevokeShareToken(ctx, portfolioID, tokenID, now); err != nil {
+		return err
+	}
+
+	s.logger.Info("Revoked share token", zap.String("portfolio_id", portfolioID), zap.String("token_id", tokenID))
+	s.recordShareToken(ctx, audit.ActionShareTokenRevoke, portfolioID, tokenID, nil, &shareTokenSnapshot{RevokedAt: &now})
+	return nil
+}
+
+// ResolveShareToken returns the active share token for a plain token. Unknown, expired
+// and revoked tokens are all reported as ErrInvalidShareToken.
+func (s *Service) ResolveShareToken(ctx context.Context, plain string) (*portfolio.ShareToken, error) {
+	if !strings.HasPrefix(plain, shareTokenPrefix) {
+		return nil, portfolio.ErrInvalidShareToken
+	}
+
+	t, err := s.access.GetShareTokenByHash(ctx, hashShareToken(plain))
+	if err != nil {
+		if errors.Is(err, portfolio.ErrShareTokenNotFound) {
+			return nil, portfolio.ErrInvalidShareToken
+		}
+		return nil, err
+	}
+
+	now := s.now()
+	if !t.Active(now) {
+		return nil, portfolio.ErrInvalidShareToken
+	}
+
+	// Usage tracking is best effort and must not fail the request
+	if err := s.access.TouchShareToken(ctx, t.ID, now); err != nil {
+		s.logger.Warn("Failed to record share token usage", zap.String("token_id", t.ID), zap.Error(err))
+	}
+
+	return t, nil
+}
+
+func hashShareToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,199 @@
+package sharing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"testtask/internal/domain/portfolio"
+	"testtask/internal/domain/user"
+)
+
+// mockAccessRepository implements portfolio.AccessRepository in memory
+type mockAccessRepository struct {
+	roles  map[string]portfolio.Role // user ID -> role on the test portfolio
+	tokens map[string]*portfolio.ShareToken
+}
+
+func newMockAccessRepository() *mockAccessRepository {
+	return &mockAccessRepository{
+		roles:  map[string]portfolio.Role{"owner": portfolio.RoleOwner},
+		tokens: make(map[string]*portfolio.ShareToken),
+	}
+}
+
+func (m *mockAccessRepository) Role(_ context.Context, _, userID string) (portfolio.Role, error) {
+	if role, ok := m.roles[userID]; ok {
+		return role, nil
+	}
+	return "", portfolio.ErrPortfolioNotFound
+}
+
+func (m *mockAccessRepository) ListMembers(context.Context, string) ([]*portfolio.Member, error) {
+	return nil, nil
+}
+
+func (m *mockAccessRepository) AddMember(_ context.Context, member *portfolio.Member) error {
+	if _, ok := m.roles[member.UserID]; ok {
+		return portfolio.ErrMemberExists
+	}
+	m.roles[member.UserID] = member.Role
+	return nil
+}
+
+func (m *mockAccessRepository) UpdateMember(_ context.Context, member *portfolio.Member) error {
+	m.roles[member.UserID] = member.Role
+	return nil
+}
+
+func (m *mockAccessRepository) RemoveMember(_ context.Context, _, userID string) error {
+	delete(m.roles, userID)
+	return nil
+}
+
+func (m *mockAccessRepository) CreateShareToken(_ context.Context, t *portfolio.ShareToken) error {
+	m.tokens[t.Hash] = t
+	return nil
+}
+
+func (m *mockAccessRepository) ListShareTokens(context.Context, string) ([]*portfolio.ShareToken, error) {
+	return nil, nil
+}
+
+func (m *mockAccessRepository) RevokeShareToken(_ context.Context, _, tokenID string, at time.Time) error {
+	for _, t := range m.tokens {
+		if t.ID == tokenID {
+			t.RevokedAt = &at
+			return nil
+		}
+	}
+	return portfolio.ErrShareTokenNotFound
+}
+
+func (m *mockAccessRepository) GetShareTokenByHash(_ context.Context, hash string) (*portfolio.ShareToken, error) {
+	if t, ok := m.tokens[hash]; ok {
+		return t, nil
+	}
+	return nil, portfolio.ErrShareTokenNotFound
+}
+
+func (m *mockAccessRepository) TouchShareToken(context.Context, string, time.Time) error {
+	return nil
+}
+
+// mockUserRepository resolves members by email
+type mockUserRepository struct {
+	user.Repository
+	users map[string]*user.User
+}
+
+func (m *mockUserRepository) GetByEmail(_ context.Context, email string) (*user.User, error) {
+	if u, ok := m.users[email]; ok {
+		return u, nil
+	}
+	return nil, user.ErrUserNotFound
+}
+
+func asUser(id string) context.Context {
+	return user.WithUser(context.Background(), &user.User{ID: id})
+}
+
+func TestService_Authorize(t *testing.T) {
+	access := newMockAccessRepository()
+	users := &mockUserRepository{users: map[string]*user.User{
+		"viewer@example.com": {ID: "viewer", Email: "viewer@example.com"},
+		"editor@example.com": {ID: "editor", Email: "editor@example.com"},
+		"owner@example.com":  {ID: "owner", Email: "owner@example.com"},
+	}}
+	service := NewService(access, users, nil, nil)
+
+	if _, err := service.AddMember(asUser("owner"), "p1", "viewer@example.com", "viewer"); err != nil {
+		t.Fatalf("AddMember() error = %v", err)
+	}
+	if _, err := service.AddMember(asUser("owner"), "p1", "editor@example.com", "editor"); err != nil {
+		t.Fatalf("AddMember() error = %v", err)
+	}
+	if _, err := service.AddMember(asUser("owner"), "p1", "owner@example.com", "admin"); !errors.Is(err, portfolio.ErrMemberExists) {
+		t.Errorf("AddMember() owner error = %v, want %v", err, portfolio.ErrMemberExists)
+	}
+	if _, err := service.AddMember(asUser("owner"), "p1", "viewer@example.com", "owner"); !errors.Is(err, portfolio.ErrInvalidRole) {
+		t.Errorf("AddMember() owner role error = %v, want %v", err, portfolio.ErrInvalidRole)
+	}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		required portfolio.Role
+		wantErr  error
+	}{
+		{name: "viewer reads", ctx: asUser("viewer"), required: portfolio.RoleViewer},
+		{name: "viewer cannot edit", ctx: asUser("viewer"), required: portfolio.RoleEditor, wantErr: portfolio.ErrAccessDenied},
+		{name: "editor edits", ctx: asUser("editor"), required: portfolio.RoleEditor},
+		{name: "editor cannot administer", ctx: asUser("editor"), required: portfolio.RoleAdmin, wantErr: portfolio.ErrAccessDenied},
+		{name: "owner deletes", ctx: asUser("owner"), required: portfolio.RoleOwner},
+		{name: "stranger", ctx: asUser("stranger"), required: portfolio.RoleViewer, wantErr: portfolio.ErrPortfolioNotFound},
+		{name: "anonymous", ctx: context.Background(), required: portfolio.RoleViewer, wantErr: portfolio.ErrAccessDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Authorize(tt.ctx, "p1", tt.required)
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("Authorize() error = %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Authorize() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestService_ShareTokens(t *testing.T) {
+	access := newMockAccessRepository()
+	service := NewService(access, &mockUserRepository{}, nil, nil)
+	ctx := asUser("owner")
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	past := now.Add(-time.Minute)
+	if _, _, err := service.CreateShareToken(ctx, "p1", "", &past); !errors.Is(err, portfolio.ErrInvalidShareToken) {
+		t.Errorf("CreateShareToken() past expiry error = %v, want %v", err, portfolio.ErrInvalidShareToken)
+	}
+	tooLate := now.Add(portfolio.MaxShareTokenTTL + time.Hour)
+	if _, _, err := service.CreateShareToken(ctx, "p1", "", &tooLate); !errors.Is(err, portfolio.ErrInvalidShareToken) {
+		t.Errorf("CreateShareToken() long expiry error = %v, want %v", err, portfolio.ErrInvalidShareToken)
+	}
+
+	token, plain, err := service.CreateShareToken(ctx, "p1", "accountant", nil)
+	if err != nil {
+		t.Fatalf("CreateShareToken() error = %v", err)
+	}
+	if !token.ExpiresAt.Equal(now.Add(portfolio.DefaultShareTokenTTL)) {
+		t.Errorf("ExpiresAt = %v, want default lifetime", token.ExpiresAt)
+	}
+	if token.CreatedBy != "owner" {
+		t.Errorf("CreatedBy = %q, want owner", token.CreatedBy)
+	}
+
+	if got, err := service.ResolveShareToken(context.Background(), plain); err != nil || got.PortfolioID != "p1" {
+		t.Fatalf("ResolveShareToken() = %v, %v, want token of p1", got, err)
+	}
+	if _, err := service.ResolveShareToken(context.Background(), plain+"x"); !errors.Is(err, portfolio.ErrInvalidShareToken) {
+		t.Errorf("ResolveShareToken() unknown error = %v, want %v", err, portfolio.ErrInvalidShareToken)
+	}
+
+	service.now = func() time.Time { return token.ExpiresAt }
+	if _, err := service.ResolveShareToken(context.Background(), plain); !errors.Is(err, portfolio.ErrInvalidShareToken) {
+		t.Errorf("ResolveShareToken() expired error = %v, want %v", err, portfolio.ErrInvalidShareToken)
+	}
+
+	service.now = func() time.Time { return now }
+	if err := service.RevokeShareToken(ctx, "p1", token.ID); err != nil {
+		t.Fatalf("RevokeShareToken() error = %v", err)
+	}
+	if _, err := service.ResolveShareToken(context.Background(), plain); !errors.Is(err, portfolio.ErrInvalidShareToken) {
+		t.Errorf("ResolveShareToken() revoked error = %v, want %v", err, portfolio.ErrInvalidShareToken)
+	}
+}
@@ -0,0 +1,551 @@
+package tax
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	loggeradapter "testtask/internal/adapters/logger"
+	"testtask/internal/domain"
+	domainHolding "testtask/internal/domain/holding"
+	"testtask/internal/domain/income"
+	domainPortfolio "testtask/internal/domain/portfolio"
+	"testtask/internal/domain/price"
+	"testtask/internal/domain/tax"
+	"testtask/internal/domain/token"
+	"testtask/internal/domain/transaction"
+	"testtask/internal/domain/user"
+
+	"go.uber.org/zap"
+)
+
+// chainID is the chain portfolio addresses live on
+const chainID = "1"
+
+// Source of on-chain events; statement events carry their exchange, manual transactions
+// "manual", ledger events "ledger" and rebasing token accruals "rebase"
+const (
+	SourceOnChain = "onchain"
+	SourceLedger  = "ledger"
+	SourceRebase  = "rebase"
+)
+
+// firstTaxYear is the earliest year a report can be generated for
+const firstTaxYear = 2009
+
+// Service generates capital gains and income reports from a portfolio's on-chain transfers,
+// exchange statements, ledger entries and income accruals, valued at historical prices
+type Service struct {
+	rules        *tax.RuleSet
+	portfolios   domainPortfolio.Repository
+	transactions domain.TransactionService
+	statements   transaction.StatementProvider
+	ledger       domainHolding.LedgerRepository
+	income       income.Source
+	prices       price.HistoricalProvider
+	aliases      *token.AliasRegistry
+	logger       *loggeradapter.Logger
+}
+
+func NewService(rules *tax.RuleSet, portfolios domainPortfolio.Repository, transactions domain.TransactionService, statements transaction.StatementProvider, ledger domainHolding.LedgerRepository, incomeSource income.Source, prices price.HistoricalProvider, aliases *token.AliasRegistry, logger *loggeradapter.Logger) *Service {
+	if logger == nil {
+		logger = loggeradapter.NewNopLogger()
+	}
+	return &Service{
+		rules:        rules,
+		portfolios:   portfolios,
+		transactions: transactions,
+		statements:   statements,
+		ledger:       ledger,
+		income:       incomeSource,
+		prices:       prices,
+		aliases:      aliases,
+		logger:       logger,
+	}
+}
+
+// Jurisdictions returns the rules of the supported jurisdictions
+func (s *Service) Jurisdictions() []*tax.Rules {
+	return s.rules.All()
+}
+
+// GenerateReport builds the capital gains report of a portfolio for a tax year
+func (s *Service) GenerateReport(ctx context.Context, req tax.Request) (*tax.Report, error) {
+	rules, err := s.rules.Lookup(req.Jurisdiction)
+	if err != nil {
+		return nil, err
+	}
+	if req.Method == "" {
+		req.Method = rules.Method
+	}
+	if !rules.Allows(req.Method) {
+		return nil, fmt.Errorf("%w: %s does not allow method %s", tax.ErrInvalidReport, rules.Code, req.Method)
+	}
+	req.Currency = strings.ToLower(strings.TrimSpace(req.Currency))
+	if req.Currency == "" {
+		req.Currency = rules.Currency
+	}
+	if req.Year < firstTaxYear || req.Year > time.Now().UTC().Year() {
+		return nil, fmt.Errorf("%w: year must be between %d and %d", tax.ErrInvalidReport, firstTaxYear, time.Now().UTC().Year())
+	}
+
+	p, err := s.portfolios.GetByID(ctx, req.PortfolioID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Transfers between the owner's wallets carry lots over from the owner's other portfolios
+	all := []*domainPortfolio.Portfolio{p}
+	if p.OwnerID != "" {
+		if all, err = s.portfolios.ListByOwner(ctx, p.OwnerID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Acquisitions shortly after the tax year can still match its disposals under the
+	// 30-day rule; anything later cannot change the year's gains and is not priced
+	_, to := rules.TaxYear(req.Year)
+	scope := &reportScope{
+		rules:    rules,
+		method:   req.Method,
+		currency: req.Currency,
+		cutoff:   to.AddDate(0, 0, 31),
+		wallets:  domainPortfolio.Wallets(p, all),
+		visited:  make(map[string]bool),
+	}
+	events, warnings, err := s.events(ctx, p, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	report := tax.NewReport(req, rules, events)
+	report.Warnings = append(warnings, report.Warnings...)
+
+	s.logger.Info("Generated tax report",
+		zap.String("portfolio_id", p.ID),
+		zap.String("jurisdiction", rules.Code),
+		zap.Int("year", req.Year),
+		zap.String("method", string(req.Method)),
+		zap.Int("event_count", len(events)),
+		zap.Int("gain_count", len(report.Gains)),
+		zap.Int("warning_count", len(report.Warnings)))
+	return report, nil
+}
+
+// reportScope is what the events of a report, and of the owner's portfolios it carries
+// lots over from, are collected for
+type reportScope struct {
+	rules    *tax.Rules
+	method   tax.Method
+	currency string
+	cutoff   time.Time
+	wallets  map[string]*domainPortfolio.Portfolio // the owner's wallets by lowercase address
+	visited  map[string]bool                       // portfolios whose events were collected
+}
+
+// movement is an event before valuation, with its token, the unit price its source
+// recorded, if any, and the counterparty of transfers
+type movement struct {
+	event         *tax.Event
+	token         *token.Token
+	price         *big.Int
+	priceCurrency string
+	counterparty  string
+}
+
+// events collects the portfolio's acquisitions, disposals and internal transfers before the
+// cutoff and values them in currency
+func (s *Service) events(ctx context.Context, p *domainPortfolio.Portfolio, scope *reportScope) ([]*tax.Event, []string, error) {
+	var (
+		movements []*movement
+		warnings  []string
+	)
+	scope.visited[p.ID] = true
+
+	// The portfolio's manual transactions and type overrides come along with the on-chain ones
+	var txs []*transaction.Transaction
+	if s.transactions != nil {
+		onChain, truncated, err := s.transactions.History(ctx, p.Address, p.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load transactions: %w", err)
+		}
+		if truncated {
+			warnings = append(warnings, fmt.Sprintf("transaction history of %s exceeds what the provider serves, later transfers left out", p.Address))
+		}
+		for _, tx := range onChain {
+			if tx.Source == "" {
+				tx.Source = SourceOnChain
+			}
+			txs = append(txs, tx)
+		}
+	}
+
+	if s.statements != nil {
+		statementTxs, err := s.statements.StatementTxsByPortfolio(ctx, p.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load exchange statements: %w", err)
+		}
+		txs = append(txs, statementTxs...)
+	}
+
+	// Income is classified on the transfers; rebasing growth has no transfer of its own
+	if s.income != nil {
+		if err := s.income.Classify(ctx, p.ID, txs); err != nil {
+			return nil, nil, fmt.Errorf("failed to classify income: %w", err)
+		}
+		accruals, err := s.income.Accruals(ctx, p.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load income accruals: %w", err)
+		}
+		for _, a := range accruals {
+			movements = append(movements, fromAccrual(a))
+		}
+	}
+
+	for _, tx := range txs {
+		if m := s.fromTransaction(tx, &warnings); m != nil {
+			movements = append(movements, m)
+		}
+	}
+
+	if s.ledger != nil {
+		entries, err := s.ledger.ListEntries(ctx, p.ID, domainHolding.LedgerFilter{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load ledger: %w", err)
+		}
+		for _, e := range entries {
+			if m := fromLedgerEntry(e); m != nil {
+				movements = append(movements, m)
+			}
+		}
+	}
+
+	kept := movements[:0]
+	for _, m := range movements {
+		if m.event.At.Before(scope.cutoff) {
+			kept = append(kept, m)
+		}
+	}
+	s.value(ctx, kept, scope.currency, &warnings)
+	kept = s.carryIn(ctx, p, kept, scope, &warnings)
+
+	events := make([]*tax.Event, 0, len(kept))
+	for _, m := range kept {
+		events = append(events, m.event)
+	}
+	return events, warnings, nil
+}
+
+// fromTransaction turns a successful transfer into an acquisition or disposal
+func (s *Service) fromTransaction(tx *transaction.Transaction, warnings *[]string) *movement {
+	if tx == nil || tx.Amount == nil || tx.Amount.Sign() <= 0 || tx.Status == transaction.TransactionStatusFailed {
+		return nil
+	}
+	internal := tx.Type == transaction.TransactionTypeInternalTransfer
+	var kind tax.Kind
+	switch {
+	case tx.Direction == transaction.TransactionDirectionIn && internal:
+		kind = tax.KindTransferIn
+	case tx.Direction == transaction.TransactionDirectionOut && internal:
+		kind = tax.KindTransferOut
+	case tx.Direction == transaction.TransactionDirectionIn:
+		kind = tax.KindAcquisition
+	case tx.Direction == transaction.TransactionDirectionOut:
+		kind = tax.KindDisposal
+	default:
+		// Transfers to itself change nothing
+		return nil
+	}
+
+	var tok *token.Token
+	if address := strings.ToLower(tx.TokenAddress); address == "" || address == token.ZeroAddress {
+		nativeToken, ok := s.aliases.NativeToken(chainID)
+		if !ok {
+			*warnings = append(*warnings, fmt.Sprintf("native asset not configured, transaction %s left out", tx.Hash))
+			return nil
+		}
+		tok = nativeToken
+	} else {
+		if tx.TokenDecimal == nil {
+			*warnings = append(*warnings, fmt.Sprintf("%s has unknown decimals, transaction %s left out", tx.TokenSymbol, tx.Hash))
+			return nil
+		}
+		tok = &token.Token{Address: address, Symbol: tx.TokenSymbol, Name: tx.TokenName, Decimal: *tx.TokenDecimal}
+	}
+
+	return &movement{
+		event: &tax.Event{
+			Asset:     tok.Address,
+			Symbol:    tok.Symbol,
+			Decimals:  tok.Decimal,
+			Kind:      kind,
+			Quantity:  new(big.Int).Set(tx.Amount),
+			At:        tx.Timestamp.UTC(),
+			Source:    tx.Source,
+			Reference: tx.Hash,
+			Income:    tx.Income,
+		},
+		token:         tok,
+		price:         tx.Price,
+		priceCurrency: tx.PriceCurrency,
+		counterparty:  tx.Counterparty(),
+	}
+}
+
+// carryIn gives transfers in from the owner's other portfolios the lots the transfers out
+// of those portfolios carried, so the lots keep their cost basis and acquisition date.
+// Transfers whose lots cannot be found stay transfers in and are acquired at their value.
+func (s *Service) carryIn(ctx context.Context, p *domainPortfolio.Portfolio, movements []*movement, scope *reportScope, warnings *[]string) []*movement {
+	var sources []*domainPortfolio.Portfolio
+	bySource := make(map[string][]*movement)
+	for _, m := range movements {
+		if m.event.Kind != tax.KindTransferIn {
+			continue
+		}
+		source, ok := scope.wallets[m.counterparty]
+		if !ok || source.ID == p.ID || scope.visited[source.ID] {
+			continue
+		}
+		if _, seen := bySource[source.ID]; !seen {
+			sources = append(sources, source)
+		}
+		bySource[source.ID] = append(bySource[source.ID], m)
+	}
+	if len(sources) == 0 {
+		return movements
+	}
+
+	// The caller may only be a member of p; the owner's other wallets are read on the
+	// owner's behalf so that every member gets the owner's gains
+	ownerCtx := user.AsOwner(ctx, p.OwnerID)
+	replaced := make(map[*movement][]*movement)
+	for _, source := range sources {
+		events, _, err := s.events(ownerCtx, source, scope)
+		if err != nil {
+			s.logger.Warn("Failed to collect events of own portfolio", zap.String("portfolio_id", source.ID), zap.Error(err))
+			*warnings = append(*warnings, fmt.Sprintf("history of own wallet %s unavailable, transfers from it valued at receipt", source.Address))
+			continue
+		}
+		carried := tax.Calculate(events, scope.rules, scope.method).Carried
+		for _, m := range bySource[source.ID] {
+			replaced[m] = takeCarried(m, carried[m.event.Reference])
+		}
+	}
+
+	result := make([]*movement, 0, len(movements))
+	for _, m := range movements {
+		if r, ok := replaced[m]; ok {
+			result = append(result, r...)
+		} else {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// takeCarried turns a transfer in into acquisitions of the lots carried under its reference,
+// consuming them; a rest the lots do not cover stays a transfer in
+func takeCarried(m *movement, lots []*tax.CarriedLot) []*movement {
+	e := m.event
+	rest := new(big.Int).Set(e.Quantity)
+	var result []*movement
+	for _, lot := range lots {
+		if lot.Asset != e.Asset || lot.Quantity.Sign() <= 0 || rest.Sign() == 0 {
+			continue
+		}
+		quantity := new(big.Int).Set(lot.Quantity)
+		if rest.Cmp(quantity) < 0 {
+			quantity.Set(rest)
+		}
+		cost := new(big.Int).Set(lot.Cost)
+		if quantity.Cmp(lot.Quantity) < 0 {
+			cost.Mul(cost, quantity).Quo(cost, lot.Quantity)
+		}
+		lot.Quantity = new(big.Int).Sub(lot.Quantity, quantity)
+		lot.Cost = new(big.Int).Sub(lot.Cost, cost)
+		rest.Sub(rest, quantity)
+
+		// Pooled lots have no date of their own; dating them at the transfer keeps them out
+		// of same-day and 30-day matching
+		acquiredAt := lot.AcquiredAt
+		if acquiredAt == nil {
+			at := e.At
+			acquiredAt = &at
+		}
+		result = append(result, &movement{
+			event: &tax.Event{
+				Asset:      e.Asset,
+				Symbol:     e.Symbol,
+				Decimals:   e.Decimals,
+				Kind:       tax.KindAcquisition,
+				Quantity:   quantity,
+				Value:      cost,
+				At:         e.At,
+				Source:     e.Source,
+				Reference:  e.Reference,
+				AcquiredAt: acquiredAt,
+			},
+			token:        m.token,
+			counterparty: m.counterparty,
+		})
+	}
+
+	switch {
+	case len(result) == 0:
+		return []*movement{m}
+	case rest.Sign() > 0:
+		restEvent := *e
+		restEvent.Quantity = rest
+		if e.Value != nil {
+			restEvent.Value = new(big.Int).Mul(e.Value, rest)
+			restEvent.Value.Quo(restEvent.Value, e.Quantity)
+		}
+		result = append(result, &movement{event: &restEvent, token: m.token, counterparty: m.counterparty})
+	}
+	return result
+}
+
+// fromLedgerEntry turns a change of a manual position into an acquisition or disposal
+func fromLedgerEntry(e *domainHolding.LedgerEntry) *movement {
+	if e.Token == nil || e.Quantity == nil || e.Quantity.Sign() == 0 {
+		return nil
+	}
+	kind := tax.KindAcquisition
+	if e.Quantity.Sign() < 0 {
+		kind = tax.KindDisposal
+	}
+	return &movement{
+		event: &tax.Event{
+			Asset:     strings.ToLower(e.Token.Address),
+			Symbol:    e.Token.Symbol,
+			Decimals:  e.Token.Decimal,
+			Kind:      kind,
+			Quantity:  new(big.Int).Abs(e.Quantity),
+			At:        e.OccurredAt.UTC(),
+			Source:    SourceLedger,
+			Reference: e.ID,
+		},
+		token:         e.Token,
+		price:         e.Price,
+		priceCurrency: e.Currency,
+	}
+}
+
+// fromAccrual turns the growth of a rebasing token balance into an income acquisition
+func fromAccrual(a *income.Accrual) *movement {
+	tok := &token.Token{Address: a.TokenAddress, Symbol: a.Symbol, Decimal: a.Decimals}
+	return &movement{
+		event: &tax.Event{
+			Asset:     tok.Address,
+			Symbol:    tok.Symbol,
+			Decimals:  tok.Decimal,
+			Kind:      tax.KindAcquisition,
+			Quantity:  new(big.Int).Set(a.Quantity),
+			At:        a.ObservedAt.UTC(),
+			Source:    SourceRebase,
+			Reference: a.ID,
+			Income:    a.Category,
+		},
+		token: tok,
+	}
+}
+
+// value sets the value of each movement from the price its source recorded or, without
+// one in currency, from the market price at the start of the UTC day it occurred. Prices
+// are looked up once per day so a year of history stays within the provider's rate
+// limit. Movements without a market price are left unvalued and the reason is warned about.
+func (s *Service) value(ctx context.Context, movements []*movement, currency string, warnings *[]string) {
+	byDay := make(map[time.Time][]*movement)
+	var days []time.Time
+	for _, m := range movements {
+		if m.price != nil && strings.EqualFold(m.priceCurrency, currency) {
+			m.event.Value = valueAt(m.event.Quantity, m.price, m.event.Decimals)
+			continue
+		}
+		day := m.event.At.UTC().Truncate(24 * time.Hour)
+		if _, ok := byDay[day]; !ok {
+			days = append(days, day)
+		}
+		byDay[day] = append(byDay[day], m)
+	}
+	if s.prices == nil || len(days) == 0 {
+		return
+	}
+
+	var (
+		failedDays  int
+		failedMoves int
+		firstErr    error
+		unpriced    = make(map[string]int) // symbol -> movements without a market price
+		symbols     []string
+	)
+	for _, day := range days {
+		tokens := make(map[string]*token.Token) // price lookup address -> token
+		for _, m := range byDay[day] {
+			t := s.pricedAs(m.token)
+			tokens[strings.ToLower(t.Address)] = t
+		}
+		list := make([]*token.Token, 0, len(tokens))
+		for _, t := range tokens {
+			list = append(list, t)
+		}
+
+		fetched, err := s.prices.GetHistoricalPrices(ctx, list, currency, day)
+		if err != nil {
+			s.logger.Warn("Failed to fetch historical prices for tax report", zap.Time("at", day), zap.String("currency", currency), zap.Error(err))
+			if firstErr == nil {
+				firstErr = err
+			}
+			failedDays++
+			failedMoves += len(byDay[day])
+			continue
+		}
+		prices := make(map[string]*big.Int, len(fetched))
+		for t, p := range fetched {
+			// Mock, manual or override prices are no basis for gains
+			if p != nil && p.Value != nil && p.IsMarket() {
+				prices[strings.ToLower(t.Address)] = p.Value
+			}
+		}
+		for _, m := range byDay[day] {
+			unit, ok := prices[strings.ToLower(s.pricedAs(m.token).Address)]
+			if !ok {
+				if _, seen := unpriced[m.event.Symbol]; !seen {
+					symbols = append(symbols, m.event.Symbol)
+				}
+				unpriced[m.event.Symbol]++
+				continue
+			}
+			m.event.Value = valueAt(m.event.Quantity, unit, m.event.Decimals)
+		}
+	}
+
+	for _, symbol := range symbols {
+		*warnings = append(*warnings, fmt.Sprintf("no market price in %s for %s, %d movements left unvalued", strings.ToUpper(currency), symbol, unpriced[symbol]))
+	}
+	if failedDays > 0 {
+		*warnings = append(*warnings, fmt.Sprintf("historical prices unavailable on %d of %d days, %d movements left unvalued: %v", failedDays, len(days), failedMoves, firstErr))
+	}
+}
+
+// pricedAs returns the token a price is looked up by: the native asset has no contract
+// and is priced through its proxy token, e.g. WETH
+func (s *Service) pricedAs(t *token.Token) *token.Token {
+	if !strings.EqualFold(t.Address, token.ZeroAddress) {
+		return t
+	}
+	native, ok := s.aliases.Native(chainID)
+	if !ok {
+		return t
+	}
+	proxy := *t
+	proxy.Address = native.PriceProxy
+	return &proxy
+}
+
+// valueAt returns quantity in base units times the unit price, keeping the price's scale
+func valueAt(quantity, unitPrice *big.Int, decimals uint8) *big.Int {
+	return domainPortfolio.CalculateValue(decimals, quantity, &price.Price{Value: unitPrice})
+}
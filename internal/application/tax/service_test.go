@@ -0,0 +1,367 @@
+package tax
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"testtask/internal/domain"
+	"testtask/internal/domain/income"
+	domainPortfolio "testtask/internal/domain/portfolio"
+	"testtask/internal/domain/price"
+	"testtask/internal/domain/tax"
+	"testtask/internal/domain/token"
+	"testtask/internal/domain/transaction"
+	"testtask/internal/domain/user"
+)
+
+const wethAddress = "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"
+
+// mockPortfolioRepository serves one portfolio and lists an owner's among it and others; other methods are
+// not used by the service
+type mockPortfolioRepository struct {
+	domainPortfolio.Repository
+	portfolio *domainPortfolio.Portfolio
+	others    []*domainPortfolio.Portfolio
+}
+
+func (m *mockPortfolioRepository) GetByID(_ context.Context, id string) (*domainPortfolio.Portfolio, error) {
+	if id != m.portfolio.ID {
+		return nil, domainPortfolio.ErrPortfolioNotFound
+	}
+	return m.portfolio, nil
+}
+
+func (m *mockPortfolioRepository) ListByOwner(_ context.Context, ownerID string) ([]*domainPortfolio.Portfolio, error) {
+	var result []*domainPortfolio.Portfolio
+	for _, p := range append([]*domainPortfolio.Portfolio{m.portfolio}, m.others...) {
+		if p.OwnerID == ownerID {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+// mockTransactions serves the transactions of each address and records the caller
+// each history was read for
+type mockTransactions struct {
+	domain.TransactionService
+	byAddress map[string][]transaction.Transaction
+	truncated map[string]bool
+	readAs    map[string]string // address -> owner the history was read on behalf of, or the caller
+}
+
+func (m *mockTransactions) History(ctx context.Context, address, _ string) (transaction.Transactions, bool, error) {
+	if m.readAs != nil {
+		if ownerID, ok := user.OwnerFromContext(ctx); ok {
+			m.readAs[address] = "owner:" + ownerID
+		} else if caller, ok := user.FromContext(ctx); ok {
+			m.readAs[address] = caller.ID
+		}
+	}
+	var txs transaction.Transactions
+	for _, tx := range m.byAddress[address] {
+		copied := tx
+		txs = append(txs, &copied)
+	}
+	return txs, m.truncated[address], nil
+}
+
+type mockStatements struct {
+	txs []*transaction.Transaction
+}
+
+func (m *mockStatements) StatementTxsByPortfolio(context.Context, string) ([]*transaction.Transaction, error) {
+	return m.txs, nil
+}
+
+// mockHistoricalPrices prices every token at a fixed unit price from source, CoinGecko by
+// default, and records the lookups; err makes every lookup fail
+type mockHistoricalPrices struct {
+	unit    *big.Int
+	source  string
+	err     error
+	lookups []string
+	at      []time.Time
+}
+
+func (m *mockHistoricalPrices) GetHistoricalPrices(_ context.Context, tokens []*token.Token, currency string, at time.Time) (map[*token.Token]*price.Price, error) {
+	m.at = append(m.at, at)
+	if m.err != nil {
+		return nil, m.err
+	}
+	source := m.source
+	if source == "" {
+		source = price.SourceCoinGecko
+	}
+	result := make(map[*token.Token]*price.Price, len(tokens))
+	for _, t := range tokens {
+		m.lookups = append(m.lookups, t.Address)
+		result[t] = &price.Price{Value: m.unit, Currency: currency, Source: source}
+	}
+	return result, nil
+}
+
+// money returns whole currency units scaled by price.CurrencyDecimal
+func money(units int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(units), big.NewInt(100000000))
+}
+
+// ether returns whole ether in wei
+func ether(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil))
+}
+
+// mockIncome classifies transactions by ID and serves fixed accruals
+type mockIncome struct {
+	categories map[string]transaction.IncomeCategory
+	accruals   []*income.Accrual
+}
+
+func (m *mockIncome) Classify(_ context.Context, _ string, txs []*transaction.Transaction) error {
+	for _, tx := range txs {
+		tx.Income = m.categories[tx.ID]
+	}
+	return nil
+}
+
+func (m *mockIncome) Accruals(context.Context, string) ([]*income.Accrual, error) {
+	return m.accruals, nil
+}
+
+func newTestService(t *testing.T, txs []*transaction.Transaction, incomeSource income.Source, prices price.HistoricalProvider) *Service {
+	t.Helper()
+	rules, err := tax.NewRuleSet([]*tax.Rules{
+		{Code: "US", Currency: "usd", Method: tax.MethodFIFO, AllowedMethods: []tax.Method{tax.MethodLIFO}, LongTermYears: 1, YearStartMonth: time.January, YearStartDay: 1},
+	})
+	if err != nil {
+		t.Fatalf("NewRuleSet() error = %v", err)
+	}
+	aliases, err := token.NewAliasRegistry([]*token.ChainAliases{{
+		ChainID: "1",
+		Native:  &token.NativeAsset{ID: "ethereum", Name: "Ethereum", Symbol: "ETH", Decimal: 18, PriceProxy: wethAddress},
+	}})
+	if err != nil {
+		t.Fatalf("NewAliasRegistry() error = %v", err)
+	}
+	portfolios := &mockPortfolioRepository{portfolio: &domainPortfolio.Portfolio{ID: "portfolio-1"}}
+	return NewService(rules, portfolios, nil, &mockStatements{txs: txs}, nil, incomeSource, prices, aliases, nil)
+}
+
+func TestService_GenerateReport(t *testing.T) {
+	prices := &mockHistoricalPrices{unit: money(3000)}
+	svc := newTestService(t, []*transaction.Transaction{
+		{Hash: "buy", Source: "kraken", TokenAddress: token.ZeroAddress, Amount: ether(2), Direction: transaction.TransactionDirectionIn, Timestamp: time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC), Price: money(1000), PriceCurrency: "usd"},
+		{Hash: "sell", Source: "kraken", TokenAddress: token.ZeroAddress, Amount: ether(1), Direction: transaction.TransactionDirectionOut, Timestamp: time.Date(2023, 6, 1, 12, 30, 0, 0, time.UTC)},
+		{Hash: "later", Source: "kraken", TokenAddress: token.ZeroAddress, Amount: ether(1), Direction: transaction.TransactionDirectionOut, Timestamp: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+	}, nil, prices)
+
+	report, err := svc.GenerateReport(context.Background(), tax.Request{PortfolioID: "portfolio-1", Jurisdiction: "us", Year: 2023})
+	if err != nil {
+		t.Fatalf("GenerateReport() error = %v", err)
+	}
+
+	if report.Method != tax.MethodFIFO || report.Currency != "usd" {
+		t.Errorf("report method %s currency %s, want the jurisdiction defaults", report.Method, report.Currency)
+	}
+	if len(report.Gains) != 1 {
+		t.Fatalf("gains = %d, want 1", len(report.Gains))
+	}
+	g := report.Gains[0]
+	if g.Proceeds.Cmp(money(3000)) != 0 || g.CostBasis.Cmp(money(1000)) != 0 || g.Term != tax.TermLong || g.Symbol != "ETH" {
+		t.Errorf("gain = %s proceeds %v cost %v %s, want ETH proceeds 3000 cost 1000 long-term", g.Symbol, g.Proceeds, g.CostBasis, g.Term)
+	}
+	// The recorded price values the acquisition, the native disposal is priced through its
+	// proxy and the disposal after the tax year is not priced at all
+	if len(prices.lookups) != 1 || prices.lookups[0] != wethAddress {
+		t.Errorf("historical lookups = %v, want only the proxy of the 2023 disposal", prices.lookups)
+	}
+}
+
+func TestService_GenerateReport_Income(t *testing.T) {
+	const stethAddress = "0xae7ab96520de3a18e5e111b5eaab095312d7fe84"
+	incomeSource := &mockIncome{
+		categories: map[string]transaction.IncomeCategory{"reward": transaction.IncomeStakingReward},
+		accruals: []*income.Accrual{
+			{ID: "accrual", TokenAddress: stethAddress, Symbol: "stETH", Decimals: 18, Quantity: ether(1), Category: transaction.IncomeStakingReward, ObservedAt: time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+	svc := newTestService(t, []*transaction.Transaction{
+		{ID: "reward", Hash: "reward", Source: "kraken", TokenAddress: token.ZeroAddress, Amount: ether(1), Direction: transaction.TransactionDirectionIn, Timestamp: time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC), Price: money(1500), PriceCurrency: "usd"},
+		{ID: "sell", Hash: "sell", Source: "kraken", TokenAddress: token.ZeroAddress, Amount: ether(1), Direction: transaction.TransactionDirectionOut, Timestamp: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC), Price: money(1800), PriceCurrency: "usd"},
+	}, incomeSource, &mockHistoricalPrices{unit: money(2000)})
+
+	report, err := svc.GenerateReport(context.Background(), tax.Request{PortfolioID: "portfolio-1", Jurisdiction: "US", Year: 2023})
+	if err != nil {
+		t.Fatalf("GenerateReport() error = %v", err)
+	}
+
+	if len(report.Income) != 2 || report.Summary.Income.Cmp(money(3500)) != 0 || report.Income[1].Source != SourceRebase {
+		t.Errorf("income = %d events totalling %v, want the reward at 1500 and the rebase at 2000", len(report.Income), report.Summary.Income)
+	}
+	// The reward is the only lot of the disposal, at its value on receipt
+	if len(report.Gains) != 1 || report.Gains[0].CostBasis.Cmp(money(1500)) != 0 || report.Gains[0].Gain.Cmp(money(300)) != 0 {
+		t.Errorf("gains = %+v, want the reward lot at a cost of 1500", report.Gains)
+	}
+}
+
+func TestService_GenerateReport_Invalid(t *testing.T) {
+	svc := newTestService(t, nil, nil, nil)
+
+	tests := []struct {
+		name    string
+		req     tax.Request
+		wantErr error
+	}{
+		{"unknown jurisdiction", tax.Request{PortfolioID: "portfolio-1", Jurisdiction: "FR", Year: 2023}, tax.ErrUnknownJurisdiction},
+		{"method not allowed", tax.Request{PortfolioID: "portfolio-1", Jurisdiction: "US", Year: 2023, Method: tax.MethodHIFO}, tax.ErrInvalidReport},
+		{"year in the future", tax.Request{PortfolioID: "portfolio-1", Jurisdiction: "US", Year: time.Now().Year() + 1}, tax.ErrInvalidReport},
+		{"unknown portfolio", tax.Request{PortfolioID: "portfolio-2", Jurisdiction: "US", Year: 2023}, domainPortfolio.ErrPortfolioNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.GenerateReport(context.Background(), tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GenerateReport() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestService_GenerateReport_InternalTransfers(t *testing.T) {
+	const (
+		hotWallet  = "0x1111111111111111111111111111111111111111"
+		coldWallet = "0x2222222222222222222222222222222222222222"
+	)
+	internal := transaction.TransactionTypeInternalTransfer
+	moved := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+	transactions := &mockTransactions{readAs: make(map[string]string), byAddress: map[string][]transaction.Transaction{
+		coldWallet: {
+			{Hash: "buy", From: "0xexchange", To: coldWallet, TokenAddress: token.ZeroAddress, Amount: ether(2), Direction: transaction.TransactionDirectionIn, Timestamp: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), Price: money(1000), PriceCurrency: "usd"},
+			{Hash: "move", From: coldWallet, To: hotWallet, TokenAddress: token.ZeroAddress, Amount: ether(2), Type: internal, Direction: transaction.TransactionDirectionOut, Timestamp: moved},
+		},
+		hotWallet: {
+			{Hash: "move", From: coldWallet, To: hotWallet, TokenAddress: token.ZeroAddress, Amount: ether(2), Type: internal, Direction: transaction.TransactionDirectionIn, Timestamp: moved},
+			{Hash: "sell", From: hotWallet, To: "0xexchange", TokenAddress: token.ZeroAddress, Amount: ether(1), Direction: transaction.TransactionDirectionOut, Timestamp: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	}}
+
+	svc := newTestService(t, nil, nil, &mockHistoricalPrices{unit: money(3000)})
+	svc.transactions = transactions
+	svc.portfolios = &mockPortfolioRepository{
+		portfolio: &domainPortfolio.Portfolio{ID: "hot", OwnerID: "user-1", Address: hotWallet},
+		others:    []*domainPortfolio.Portfolio{{ID: "cold", OwnerID: "user-1", Address: coldWallet}},
+	}
+
+	// An accountant with access to the hot wallet only gets the owner's gains
+	accountant := user.WithUser(context.Background(), &user.User{ID: "accountant"})
+	report, err := svc.GenerateReport(accountant, tax.Request{PortfolioID: "hot", Jurisdiction: "us", Year: 2023})
+	if err != nil {
+		t.Fatalf("GenerateReport() error = %v", err)
+	}
+	if readAs := transactions.readAs[coldWallet]; readAs != "owner:user-1" {
+		t.Errorf("cold wallet history read as %q, want it read on the owner's behalf", readAs)
+	}
+
+	// The transfer realizes nothing; the sale takes the lot bought in the cold wallet
+	if len(report.Gains) != 1 || len(report.Warnings) != 0 {
+		t.Fatalf("report gains = %+v warnings = %v, want only the sale", report.Gains, report.Warnings)
+	}
+	g := report.Gains[0]
+	if g.Reference != "sell" || g.CostBasis.Cmp(money(1000)) != 0 || g.Term != tax.TermLong || g.AcquiredAt.Year() != 2022 {
+		t.Errorf("gain = %s cost %v %s acquired %v, want the long-term 2022 lot at 1000", g.Reference, g.CostBasis, g.Term, g.AcquiredAt)
+	}
+	if len(report.Income) != 0 {
+		t.Errorf("income = %+v, want none", report.Income)
+	}
+}
+
+func TestService_GenerateReport_TruncatedHistory(t *testing.T) {
+	const wallet = "0x1111111111111111111111111111111111111111"
+	svc := newTestService(t, nil, nil, &mockHistoricalPrices{unit: money(3000)})
+	svc.transactions = &mockTransactions{truncated: map[string]bool{wallet: true}}
+	svc.portfolios = &mockPortfolioRepository{portfolio: &domainPortfolio.Portfolio{ID: "portfolio-1", Address: wallet}}
+
+	report, err := svc.GenerateReport(context.Background(), tax.Request{PortfolioID: "portfolio-1", Jurisdiction: "us", Year: 2023})
+	if err != nil {
+		t.Fatalf("GenerateReport() error = %v", err)
+	}
+	if len(report.Warnings) != 1 || !strings.Contains(report.Warnings[0], wallet) {
+		t.Errorf("warnings = %v, want one about the truncated history of %s", report.Warnings, wallet)
+	}
+}
+
+func TestService_GenerateReport_HistoricalPrices(t *testing.T) {
+	// Both disposals on 2023-06-01 are priced by one lookup at the start of that day
+	txs := []*transaction.Transaction{
+		{Hash: "buy", Source: "kraken", TokenAddress: token.ZeroAddress, Amount: ether(2), Direction: transaction.TransactionDirectionIn, Timestamp: time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC), Price: money(1000), PriceCurrency: "usd"},
+		{Hash: "morning", Source: "kraken", TokenAddress: token.ZeroAddress, Amount: ether(1), Direction: transaction.TransactionDirectionOut, Timestamp: time.Date(2023, 6, 1, 9, 15, 0, 0, time.UTC)},
+		{Hash: "evening", Source: "kraken", TokenAddress: token.ZeroAddress, Amount: ether(1), Direction: transaction.TransactionDirectionOut, Timestamp: time.Date(2023, 6, 1, 21, 40, 0, 0, time.UTC)},
+	}
+
+	tests := []struct {
+		name         string
+		prices       *mockHistoricalPrices
+		wantProceeds *big.Int
+		wantWarning  string
+	}{
+		{
+			name:         "market price",
+			prices:       &mockHistoricalPrices{unit: money(3000)},
+			wantProceeds: money(6000),
+		},
+		{
+			name:        "non-market price",
+			prices:      &mockHistoricalPrices{unit: money(10), source: price.SourceMock},
+			wantWarning: "no market price in USD for ETH, 2 movements left unvalued",
+		},
+		{
+			name:        "rate limited",
+			prices:      &mockHistoricalPrices{err: errors.New("rate limit exceeded")},
+			wantWarning: "historical prices unavailable on 1 of 1 days, 2 movements left unvalued: rate limit exceeded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newTestService(t, txs, nil, tt.prices)
+
+			report, err := svc.GenerateReport(context.Background(), tax.Request{PortfolioID: "portfolio-1", Jurisdiction: "us", Year: 2023})
+			if err != nil {
+				t.Fatalf("GenerateReport() error = %v", err)
+			}
+			if len(tt.prices.at) != 1 || !tt.prices.at[0].Equal(time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)) {
+				t.Errorf("historical lookups at %v, want one at the start of 2023-06-01", tt.prices.at)
+			}
+
+			proceeds := new(big.Int)
+			for _, g := range report.Gains {
+				proceeds.Add(proceeds, g.Proceeds)
+			}
+			want := tt.wantProceeds
+			if want == nil {
+				want = new(big.Int)
+			}
+			if proceeds.Cmp(want) != 0 {
+				t.Errorf("proceeds = %v, want %v", proceeds, want)
+			}
+
+			if tt.wantWarning == "" {
+				if len(report.Warnings) != 0 {
+					t.Errorf("warnings = %v, want none", report.Warnings)
+				}
+				return
+			}
+			found := false
+			for _, w := range report.Warnings {
+				found = found || w == tt.wantWarning
+			}
+			if !found {
+				t.Errorf("warnings = %v, want %q", report.Warnings, tt.wantWarning)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package token
+
+import (
+	"context"
+
+	"testtask/internal/domain/audit"
+	"testtask/internal/domain/token"
+)
+
+// customTokenSnapshot is the audit log representation of a custom token
+type customTokenSnapshot struct {
+	ChainID       string `json:"chain_id"`
+	Address       string `json:"address"`
+	Symbol        string `json:"symbol"`
+	Name          string `json:"name"`
+	Decimals      int    `json:"decimals"`
+	ManualPrice   string `json:"manual_price,omitempty"`
+	PriceCurrency string `json:"price_currency,omitempty"`
+}
+
+func snapshotCustomToken(c *token.CustomToken) *customTokenSnapshot {
+	s := &customTokenSnapshot{
+		ChainID:       c.ChainID,
+		PriceCurrency: c.PriceCurrency,
+	}
+	if c.Token != nil {
+		s.Address = c.Token.Address
+		s.Symbol = c.Token.Symbol
+		s.Name = c.Token.Name
+		s.Decimals = int(c.Token.Decimal)
+	}
+	if c.ManualPrice != nil {
+		s.ManualPrice = c.ManualPrice.String()
+	}
+	return s
+}
+
+func (s *Service) recordCustomToken(ctx context.Context, action string, c *token.CustomToken, before, after *customTokenSnapshot) {
+	s.recorder.Record(ctx, audit.NewEntry(action, audit.ResourceCustomToken, c.ID, c.PortfolioID), before, after)
+}
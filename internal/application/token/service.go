@@ -0,0 +1,483 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	loggeradapter "testtask/internal/adapters/logger"
+	"testtask/internal/domain/audit"
+	"testtask/internal/domain/token"
+	"testtask/internal/domain/transaction"
+	"testtask/internal/domain/user"
+
+	"go.uber.org/zap"
+)
+
+// Service owns the token registry: lookups, search, discovery of tokens seen in
+// transfer history and background metadata backfill.
+type Service struct {
+	registry         token.Registry
+	customRepo       token.CustomRepository
+	metadataProvider token.MetadataProvider
+	staticSource     token.ListSource
+	logos            map[string]string // lowercase address -> logo URI
+	index            atomic.Pointer[token.Index]
+	indexMu          sync.Mutex // serializes rebuilds so a stale one cannot replace a newer index
+
+	reloadMu      sync.Mutex
+	loadedModTime time.Time
+	lastReload    atomic.Pointer[token.ReloadResult]
+	refreshBatch  int
+	refreshRetry  time.Duration
+	recorder      audit.Recorder
+	logger        *loggeradapter.Logger
+}
+
+// NewService creates a token service. metadataProvider may be nil, in which case
+// RefreshMetadata is a no-op, and staticSource may be nil when no bundled token list
+// is configured. The search index is empty until RebuildIndex or ReloadTokens is called.
+func NewService(registry token.Registry, customRepo token.CustomRepository, metadataProvider token.MetadataProvider, staticSource token.ListSource, logos map[string]string, refreshBatch int, refreshRetry time.Duration, recorder audit.Recorder, logger *loggeradapter.Logger) *Service {
+	if logger == nil {
+		logger = loggeradapter.NewNopLogger()
+	}
+	if recorder == nil {
+		recorder = audit.NopRecorder{}
+	}
+	if refreshBatch <= 0 {
+		refreshBatch = 20
+	}
+	s := &Service{
+		registry:         registry,
+		customRepo:       customRepo,
+		metadataProvider: metadataProvider,
+		staticSource:     staticSource,
+		logos:            logos,
+		refreshBatch:     refreshBatch,
+		refreshRetry:     refreshRetry,
+		recorder:         recorder,
+		logger:           logger,
+	}
+	s.index.Store(token.NewIndex(nil))
+	return s
+}
+
+// GetTokenByAddress looks a token up in the registry, then among global custom tokens
+func (s *Service) GetTokenByAddress(ctx context.Context, address string) (*token.Token, bool) {
+	return s.GetTokenForPortfolio(ctx, "", address)
+}
+
+// GetTokenForPortfolio looks a token up in the registry, then among the custom tokens
+// visible to the portfolio
+func (s *Service) GetTokenForPortfolio(ctx context.Context, portfolioID string, address string) (*token.Token, bool) {
+	tok, err := s.registry.GetByAddress(ctx, address)
+	if err == nil {
+		s.applyLogo(tok)
+		return tok, true
+	}
+	if !errors.Is(err, token.ErrTokenNotFound) {
+		s.logger.Error("Failed to get token", zap.String("address", address), zap.Error(err))
+		return nil, false
+	}
+
+	custom := s.CustomTokens(ctx, portfolioID, []string{address})
+	tok, ok := custom[strings.ToLower(address)]
+	return tok, ok
+}
+
+// GetTokenBySymbol looks a token up in the search index by its exact symbol. Symbols are
+// not unique, so a symbol shared by several tokens is not resolved.
+func (s *Service) GetTokenBySymbol(_ context.Context, symbol string) (*token.Token, bool) {
+	matches := s.index.Load().BySymbol(symbol)
+	if len(matches) != 1 {
+		if len(matches) > 1 {
+			s.logger.Debug("Ambiguous token symbol", zap.String("symbol", symbol), zap.Int("matches", len(matches)))
+		}
+		return nil, false
+	}
+	return matches[0], true
+}
+
+// CustomTokens returns the custom tokens visible to the portfolio, keyed by lowercase address
+func (s *Service) CustomTokens(ctx context.Context, portfolioID string, addresses []string) map[string]*token.Token {
+	result := make(map[string]*token.Token)
+	if s.customRepo == nil || len(addresses) == 0 {
+		return result
+	}
+
+	custom, err := s.customRepo.GetByAddresses(ctx, portfolioID, addresses)
+	if err != nil {
+		s.logger.Error("Failed to get custom tokens", zap.Error(err))
+		return result
+	}
+	for address, c := range custom {
+		if c.VisibleTo(portfolioID) {
+			result[address] = c.Token
+		}
+	}
+
+	return result
+}
+
+// CreateCustomToken registers a user-defined token. Addresses already known to the
+// registry cannot be redefined.
+func (s *Service) CreateCustomToken(ctx context.Context, c *token.CustomToken) error {
+	if c == nil || c.Token == nil {
+		return token.ErrInvalidCustomToken
+	}
+	if !token.IsAddress(c.Token.Address) {
+		return fmt.Errorf("%w: address must be a 0x-prefixed 20-byte hex string", token.ErrInvalidCustomToken)
+	}
+	if strings.TrimSpace(c.Token.Symbol) == "" {
+		return fmt.Errorf("%w: symbol is required", token.ErrInvalidCustomToken)
+	}
+	if chainID, err := strconv.ParseUint(c.ChainID, 10, 64); err != nil || chainID == 0 {
+		return fmt.Errorf("%w: chain_id must be a positive integer", token.ErrInvalidCustomToken)
+	}
+	if c.ManualPrice != nil && c.ManualPrice.Sign() < 0 {
+		return fmt.Errorf("%w: price must not be negative", token.ErrInvalidCustomToken)
+	}
+
+	if _, err := s.registry.GetByAddress(ctx, c.Token.Address); err == nil {
+		return fmt.Errorf("%w: address=%s is in the token registry", token.ErrCustomTokenExists, c.Token.Address)
+	}
+
+	if caller, ok := user.FromContext(ctx); ok {
+		c.OwnerID = caller.ID
+	}
+
+	if err := s.customRepo.Create(ctx, c); err != nil {
+		s.logger.Warn("Failed to create custom token", zap.String("address", c.Token.Address), zap.Error(err))
+		return err
+	}
+
+	s.logger.Info("Created custom token",
+		zap.String("id", c.ID),
+		zap.String("address", c.Token.Address),
+		zap.String("portfolio_id", c.PortfolioID))
+	s.recordCustomToken(ctx, audit.ActionCustomTokenCreate, c, nil, snapshotCustomToken(c))
+	if c.PortfolioID == "" {
+		s.rebuildIndexAfterChange(ctx)
+	}
+	return nil
+}
+
+func (s *Service) GetCustomToken(ctx context.Context, id string) (*token.CustomToken, error) {
+	return s.customRepo.GetByID(ctx, id)
+}
+
+func (s *Service) ListCustomTokens(ctx context.Context, portfolioID string) ([]*token.CustomToken, error) {
+	return s.customRepo.List(ctx, portfolioID)
+}
+
+// SetCustomTokenPrice sets the manual price feed of a custom token; a nil value removes it
+func (s *Service) SetCustomTokenPrice(ctx context.Context, id string, value *big.Int, currency string) (*token.CustomToken, error) {
+	if value != nil && value.Sign() < 0 {
+		return nil, fmt.Errorf("%w: price must not be negative", token.ErrInvalidCustomToken)
+	}
+
+	c, err := s.customRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	before := snapshotCustomToken(c)
+	c.SetManualPrice(value, currency)
+	if err := s.customRepo.Update(ctx, c); err != nil {
+		s.logger.Error("Failed to update custom token price", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("Updated custom token price", zap.String("id", id), zap.Bool("has_price", value != nil))
+	s.recordCustomToken(ctx, audit.ActionCustomTokenPrice, c, before, snapshotCustomToken(c))
+	return c, nil
+}
+
+func (s *Service) DeleteCustomToken(ctx context.Context, id string) error {
+	c, err := s.customRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.customRepo.Delete(ctx, id); err != nil {
+		s.logger.Error("Failed to delete custom token", zap.String("id", id), zap.Error(err))
+		return err
+	}
+
+	s.logger.Info("Deleted custom token", zap.String("id", id), zap.String("address", c.Token.Address))
+	s.recordCustomToken(ctx, audit.ActionCustomTokenDelete, c, snapshotCustomToken(c), nil)
+	if c.PortfolioID == "" {
+		s.rebuildIndexAfterChange(ctx)
+	}
+	return nil
+}
+
+// SearchTokens returns a page of tokens matching the query, best matches first.
+// An empty query lists all tokens.
+func (s *Service) SearchTokens(_ context.Context, query string, page, pageSize int) ([]*token.Token, int, error) {
+	idx := s.index.Load()
+
+	var matches []*token.Token
+	if strings.TrimSpace(query) == "" {
+		matches = idx.Tokens()
+	} else {
+		results := idx.Search(query)
+		matches = make([]*token.Token, len(results))
+		for i, r := range results {
+			matches[i] = r.Token
+		}
+	}
+
+	total := len(matches)
+	start := (page - 1) * pageSize
+	if start >= total {
+		return []*token.Token{}, total, nil
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+
+	return matches[start:end], total, nil
+}
+
+// RebuildIndex reloads the registry into a new search index and swaps it in.
+// Searches in flight keep using the previous index.
+func (s *Service) RebuildIndex(ctx context.Context) error {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
+	tokens, err := s.registry.GetList(ctx)
+	if err != nil {
+		return err
+	}
+	for _, t := range tokens {
+		s.applyLogo(t)
+	}
+
+	// Global custom tokens are searchable too; portfolio-scoped ones stay private
+	if s.customRepo != nil {
+		custom, err := s.customRepo.List(ctx, "")
+		if err != nil {
+			return err
+		}
+		for _, c := range custom {
+			tokens = append(tokens, c.Token)
+		}
+	}
+
+	s.index.Store(token.NewIndex(tokens))
+	s.logger.Info("Token search index rebuilt", zap.Int("tokens", len(tokens)))
+	return nil
+}
+
+func (s *Service) applyLogo(t *token.Token) {
+	if t.LogoURI == "" {
+		t.LogoURI = s.logos[strings.ToLower(t.Address)]
+	}
+}
+
+func (s *Service) rebuildIndexAfterChange(ctx context.Context) {
+	if err := s.RebuildIndex(ctx); err != nil {
+		s.logger.Warn("Failed to rebuild token search index", zap.Error(err))
+	}
+}
+
+// DiscoverFromTransactions registers tokens seen in token transfers that are not
+// in the registry yet, using the symbol and decimals reported by the provider.
+// Transfers without decimals are skipped since amounts cannot be scaled without them.
+func (s *Service) DiscoverFromTransactions(ctx context.Context, txs []*transaction.Transaction) (int, error) {
+	candidates := make(map[string]*token.Token)
+	for _, tx := range txs {
+		if tx == nil || tx.TokenAddress == "" || tx.TokenDecimal == nil {
+			continue
+		}
+		address := strings.ToLower(tx.TokenAddress)
+		if _, seen := candidates[address]; seen {
+			continue
+		}
+		candidates[address] = &token.Token{
+			Name:    tx.TokenName,
+			Symbol:  tx.TokenSymbol,
+			Address: address,
+			Decimal: *tx.TokenDecimal,
+		}
+	}
+	if len(candidates) == 0 {
+		return 0, nil
+	}
+
+	addresses := make([]string, 0, len(candidates))
+	for address := range candidates {
+		addresses = append(addresses, address)
+	}
+	known := s.registry.GetByAddresses(ctx, addresses)
+
+	unknown := make([]*token.Token, 0, len(candidates))
+	for address, t := range candidates {
+		if _, ok := known[address]; !ok {
+			unknown = append(unknown, t)
+		}
+	}
+	if len(unknown) == 0 {
+		return 0, nil
+	}
+
+	added, err := s.registry.Register(ctx, unknown, token.SourceDiscovered)
+	if err != nil {
+		s.logger.Error("Failed to register discovered tokens", zap.Int("count", len(unknown)), zap.Error(err))
+		return 0, err
+	}
+
+	s.logger.Info("Registered discovered tokens", zap.Int("added", added))
+	if added > 0 {
+		s.rebuildIndexAfterChange(ctx)
+	}
+	return added, nil
+}
+
+// RefreshMetadata backfills names and CoinGecko IDs for one batch of unresolved tokens.
+// Tokens the provider does not know are marked refreshed so they are retried later.
+func (s *Service) RefreshMetadata(ctx context.Context) error {
+	if s.metadataProvider == nil {
+		return nil
+	}
+
+	tokens, err := s.registry.ListUnresolved(ctx, time.Now().Add(-s.refreshRetry), s.refreshBatch)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	resolved := 0
+	for _, t := range tokens {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		metadata, err := s.metadataProvider.GetMetadata(ctx, t.Address)
+		if err != nil {
+			if !errors.Is(err, token.ErrTokenNotFound) {
+				// Transient failure (rate limit, network); retry on the next run
+				s.logger.Warn("Failed to fetch token metadata", zap.String("address", t.Address), zap.Error(err))
+				continue
+			}
+			metadata = nil
+		}
+
+		if err := s.registry.UpdateMetadata(ctx, t.Address, metadata); err != nil {
+			s.logger.Error("Failed to update token metadata", zap.String("address", t.Address), zap.Error(err))
+			continue
+		}
+		if metadata != nil {
+			resolved++
+		}
+	}
+
+	s.logger.Info("Refreshed token metadata", zap.Int("checked", len(tokens)), zap.Int("resolved", resolved))
+	if resolved > 0 {
+		s.rebuildIndexAfterChange(ctx)
+	}
+	return nil
+}
+
+// ReloadTokens syncs the bundled token list into the registry and swaps in a new
+// search index. Reloads are serialized; a failed reload leaves the previous index in place.
+func (s *Service) ReloadTokens(ctx context.Context, trigger string) (*token.ReloadResult, error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	result := &token.ReloadResult{Trigger: trigger, StartedAt: time.Now()}
+	err := s.reload(ctx, result)
+	result.Duration = time.Since(result.StartedAt)
+	result.Err = err
+	s.lastReload.Store(result)
+
+	if err != nil {
+		s.logger.Error("Token registry reload failed",
+			zap.String("trigger", trigger),
+			zap.String("source", result.Source),
+			zap.Error(err))
+		return result, err
+	}
+
+	s.logger.Info("Token registry reloaded",
+		zap.String("trigger", trigger),
+		zap.String("source", result.Source),
+		zap.Int("loaded", result.Loaded),
+		zap.Int("added", result.Added),
+		zap.Int("updated", result.Updated),
+		zap.Int("unchanged", result.Unchanged),
+		zap.Int("stale", result.Stale),
+		zap.Int("indexed", result.Indexed),
+		zap.Duration("duration", result.Duration))
+	return result, nil
+}
+
+func (s *Service) reload(ctx context.Context, result *token.ReloadResult) error {
+	if s.staticSource == nil {
+		return fmt.Errorf("no token list configured")
+	}
+	result.Source = s.staticSource.Location()
+
+	// Read the modification time first so a write during the load triggers another reload
+	modTime, err := s.staticSource.ModTime()
+	if err != nil {
+		return err
+	}
+
+	tokens, err := s.staticSource.Load(ctx)
+	if err != nil {
+		return err
+	}
+	result.Loaded = len(tokens)
+
+	stats, err := s.registry.Sync(ctx, tokens, token.SourceStatic)
+	if err != nil {
+		return err
+	}
+	result.SyncStats = stats
+	s.loadedModTime = modTime
+
+	if err := s.RebuildIndex(ctx); err != nil {
+		return err
+	}
+	result.Indexed = s.index.Load().Len()
+
+	return nil
+}
+
+// LastTokenReload returns the result of the most recent reload, or nil if none ran yet
+func (s *Service) LastTokenReload() *token.ReloadResult {
+	return s.lastReload.Load()
+}
+
+// WatchTokenList reloads the bundled token list when its file changed since the last reload
+func (s *Service) WatchTokenList(ctx context.Context) error {
+	if s.staticSource == nil {
+		return nil
+	}
+
+	modTime, err := s.staticSource.ModTime()
+	if err != nil {
+		return err
+	}
+
+	s.reloadMu.Lock()
+	changed := modTime.After(s.loadedModTime)
+	s.reloadMu.Unlock()
+	if !changed {
+		return nil
+	}
+
+	_, err = s.ReloadTokens(ctx, token.ReloadTriggerFileChange)
+	return err
+}
@@ -0,0 +1,98 @@
+package token
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"testtask/internal/domain/token"
+)
+
+// mockRegistry is an empty token registry
+type mockRegistry struct {
+	token.Registry
+}
+
+func (m *mockRegistry) GetList(context.Context) ([]*token.Token, error) {
+	return nil, nil
+}
+
+// mockCustomRepository serves global custom tokens. The first List call takes
+// its snapshot, reports it on listed and then waits for release, so a test can
+// change the tokens while that rebuild is in flight.
+type mockCustomRepository struct {
+	token.CustomRepository
+
+	mu      sync.Mutex
+	tokens  []*token.CustomToken
+	calls   int
+	listed  chan struct{}
+	release chan struct{}
+}
+
+func (m *mockCustomRepository) List(context.Context, string) ([]*token.CustomToken, error) {
+	m.mu.Lock()
+	snapshot := append([]*token.CustomToken(nil), m.tokens...)
+	m.calls++
+	first := m.calls == 1
+	m.mu.Unlock()
+
+	if first {
+		close(m.listed)
+		<-m.release
+	}
+	return snapshot, nil
+}
+
+func (m *mockCustomRepository) add(c *token.CustomToken) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tokens = append(m.tokens, c)
+}
+
+func TestService_RebuildIndex_Concurrent(t *testing.T) {
+	first := token.NewCustomToken("", "1", &token.Token{Symbol: "ONE", Address: "0x1111111111111111111111111111111111111111"})
+	second := token.NewCustomToken("", "1", &token.Token{Symbol: "TWO", Address: "0x2222222222222222222222222222222222222222"})
+
+	repo := &mockCustomRepository{
+		tokens:  []*token.CustomToken{first},
+		listed:  make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	svc := NewService(&mockRegistry{}, repo, nil, nil, nil, 0, 0, nil, nil)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		svc.rebuildIndexAfterChange(ctx)
+	}()
+	<-repo.listed
+
+	// A newer rebuild starts while the first one still holds its stale snapshot
+	repo.add(second)
+	newer := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(newer)
+		svc.rebuildIndexAfterChange(ctx)
+	}()
+
+	select {
+	case <-newer:
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(repo.release)
+	wg.Wait()
+
+	tokens, total, err := svc.SearchTokens(ctx, "", 1, 10)
+	if err != nil {
+		t.Fatalf("SearchTokens() error = %v", err)
+	}
+	if total != 2 {
+		t.Fatalf("SearchTokens() total = %d, want 2 (stale rebuild replaced the newer index): %v", total, tokens)
+	}
+}
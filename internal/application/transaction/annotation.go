@@ -0,0 +1,209 @@
+package transaction
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"testtask/internal/domain/audit"
+	domainPortfolio "testtask/internal/domain/portfolio"
+	"testtask/internal/domain/transaction"
+
+	"go.uber.org/zap"
+)
+
+// ListAnnotations returns the annotations of the portfolio's transactions
+func (s *Service) ListAnnotations(ctx context.Context, portfolioID string) ([]*transaction.Annotation, error) {
+	if _, err := s.portfolios.GetByID(ctx, portfolioID); err != nil {
+		return nil, err
+	}
+	return s.listAnnotations(ctx, portfolioID)
+}
+
+// SetAnnotation creates or replaces the annotation of a transaction of the portfolio
+func (s *Service) SetAnnotation(ctx context.Context, a *transaction.Annotation) error {
+	if _, err := s.mutablePortfolio(ctx, a.PortfolioID); err != nil {
+		return err
+	}
+	a.Normalize()
+	if err := a.Validate(); err != nil {
+		return err
+	}
+	a.UpdatedAt = s.now().UTC()
+
+	before := s.annotation(ctx, a.PortfolioID, a.TransactionID)
+	if err := s.annotations.SetAnnotation(ctx, a); err != nil {
+		s.logger.Error("Failed to set transaction annotation", zap.String("portfolio_id", a.PortfolioID), zap.String("transaction_id", a.TransactionID), zap.Error(err))
+		return err
+	}
+
+	s.logger.Info("Set transaction annotation", zap.String("portfolio_id", a.PortfolioID), zap.String("transaction_id", a.TransactionID))
+	s.recorder.Record(ctx, audit.NewEntry(audit.ActionAnnotationSet, audit.ResourceAnnotation, a.TransactionID, a.PortfolioID), snapshotAnnotation(before), snapshotAnnotation(a))
+	return nil
+}
+
+// DeleteAnnotation removes the annotation, so the transaction shows as the provider reports it
+func (s *Service) DeleteAnnotation(ctx context.Context, portfolioID, transactionID string) error {
+	if _, err := s.mutablePortfolio(ctx, portfolioID); err != nil {
+		return err
+	}
+	before := s.annotation(ctx, portfolioID, transactionID)
+	if err := s.annotations.DeleteAnnotation(ctx, portfolioID, transactionID); err != nil {
+		return err
+	}
+
+	s.logger.Info("Deleted transaction annotation", zap.String("portfolio_id", portfolioID), zap.String("transaction_id", transactionID))
+	s.recorder.Record(ctx, audit.NewEntry(audit.ActionAnnotationDelete, audit.ResourceAnnotation, transactionID, portfolioID), snapshotAnnotation(before), nil)
+	return nil
+}
+
+// ListManualTransactions returns the transactions entered by hand for the portfolio
+func (s *Service) ListManualTransactions(ctx context.Context, portfolioID string) ([]*transaction.Transaction, error) {
+	if _, err := s.portfolios.GetByID(ctx, portfolioID); err != nil {
+		return nil, err
+	}
+	return s.manualTransactions(ctx, portfolioID)
+}
+
+// CreateManualTransaction stores a transaction built with transaction.NewManualTransaction.
+// The portfolio address fills the side of the transfer the counterparty does not.
+func (s *Service) CreateManualTransaction(ctx context.Context, portfolioID string, tx *transaction.Transaction) error {
+	p, err := s.mutablePortfolio(ctx, portfolioID)
+	if err != nil {
+		return err
+	}
+	owner := strings.ToLower(p.Address)
+	if tx.Direction == transaction.TransactionDirectionIn {
+		tx.From, tx.To = strings.ToLower(strings.TrimSpace(tx.From)), owner
+	} else {
+		tx.From, tx.To = owner, strings.ToLower(strings.TrimSpace(tx.To))
+	}
+
+	if err := s.manual.CreateManualTransaction(ctx, portfolioID, tx); err != nil {
+		s.logger.Error("Failed to create manual transaction", zap.String("portfolio_id", portfolioID), zap.Error(err))
+		return err
+	}
+
+	s.logger.Info("Created manual transaction", zap.String("portfolio_id", portfolioID), zap.String("id", tx.ID), zap.String("token", tx.TokenAddress))
+	s.recorder.Record(ctx, audit.NewEntry(audit.ActionManualTxCreate, audit.ResourceManualTx, tx.ID, portfolioID), nil, snapshotManual(tx))
+	return nil
+}
+
+// DeleteManualTransaction removes a transaction entered by hand; its annotation is kept
+// and applies again if the transaction is re-entered under the same ID
+func (s *Service) DeleteManualTransaction(ctx context.Context, portfolioID, id string) error {
+	if _, err := s.mutablePortfolio(ctx, portfolioID); err != nil {
+		return err
+	}
+	var before *transaction.Transaction
+	if existing, err := s.manual.ListManualTransactions(ctx, portfolioID); err == nil {
+		for _, tx := range existing {
+			if tx.ID == id {
+				before = tx
+			}
+		}
+	}
+	if err := s.manual.DeleteManualTransaction(ctx, portfolioID, id); err != nil {
+		return err
+	}
+
+	s.logger.Info("Deleted manual transaction", zap.String("portfolio_id", portfolioID), zap.String("id", id))
+	s.recorder.Record(ctx, audit.NewEntry(audit.ActionManualTxDelete, audit.ResourceManualTx, id, portfolioID), snapshotManual(before), nil)
+	return nil
+}
+
+func (s *Service) listAnnotations(ctx context.Context, portfolioID string) ([]*transaction.Annotation, error) {
+	if s.annotations == nil {
+		return nil, nil
+	}
+	return s.annotations.ListAnnotations(ctx, portfolioID)
+}
+
+func (s *Service) manualTransactions(ctx context.Context, portfolioID string) ([]*transaction.Transaction, error) {
+	if s.manual == nil {
+		return nil, nil
+	}
+	return s.manual.ListManualTransactions(ctx, portfolioID)
+}
+
+// ownWallets returns the addresses of the portfolio owner's wallets
+func (s *Service) ownWallets(ctx context.Context, portfolioID string) (map[string]bool, error) {
+	p, err := s.portfolios.GetByID(ctx, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	all := []*domainPortfolio.Portfolio{p}
+	if p.OwnerID != "" {
+		if all, err = s.portfolios.ListByOwner(ctx, p.OwnerID); err != nil {
+			return nil, err
+		}
+	}
+	wallets := make(map[string]bool)
+	for address := range domainPortfolio.Wallets(p, all) {
+		wallets[address] = true
+	}
+	return wallets, nil
+}
+
+func (s *Service) mutablePortfolio(ctx context.Context, portfolioID string) (*domainPortfolio.Portfolio, error) {
+	p, err := s.portfolios.GetByID(ctx, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	if p.Archived {
+		return nil, domainPortfolio.ErrPortfolioArchived
+	}
+	return p, nil
+}
+
+// annotation returns the current annotation of a transaction, nil if none
+func (s *Service) annotation(ctx context.Context, portfolioID, transactionID string) *transaction.Annotation {
+	annotations, err := s.annotations.ListAnnotations(ctx, portfolioID)
+	if err != nil {
+		return nil
+	}
+	for _, a := range annotations {
+		if a.TransactionID == transactionID {
+			return a
+		}
+	}
+	return nil
+}
+
+type annotationSnapshot struct {
+	TransactionID string   `json:"transaction_id"`
+	Note          string   `json:"note,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+	Category      string   `json:"category,omitempty"`
+	TypeOverride  string   `json:"type_override,omitempty"`
+}
+
+func snapshotAnnotation(a *transaction.Annotation) *annotationSnapshot {
+	if a == nil {
+		return nil
+	}
+	return &annotationSnapshot{TransactionID: a.TransactionID, Note: a.Note, Tags: a.Tags, Category: a.Category, TypeOverride: string(a.TypeOverride)}
+}
+
+type manualSnapshot struct {
+	ID           string `json:"id"`
+	TokenAddress string `json:"token_address"`
+	Amount       string `json:"amount"`
+	Type         string `json:"type"`
+	Direction    string `json:"direction"`
+	Timestamp    string `json:"timestamp"`
+}
+
+func snapshotManual(tx *transaction.Transaction) *manualSnapshot {
+	if tx == nil {
+		return nil
+	}
+	return &manualSnapshot{
+		ID:           tx.ID,
+		TokenAddress: tx.TokenAddress,
+		Amount:       tx.Amount.String(),
+		Type:         string(tx.Type),
+		Direction:    string(tx.Direction),
+		Timestamp:    tx.Timestamp.Format(time.RFC3339),
+	}
+}
@@ -5,25 +5,47 @@ import (
 	"math/big"
 	"sort"
 	"strings"
+	"time"
 
 	loggeradapter "testtask/internal/adapters/logger"
+	"testtask/internal/domain/audit"
+	domainPortfolio "testtask/internal/domain/portfolio"
 	"testtask/internal/domain/transaction"
+
+	"go.uber.org/zap"
 )
 
 // Service implements transaction aggregation and classification logic.
-// It hides provider details (Etherscan, pagination, etc.) from callers.
+// It hides provider details (Etherscan, pagination, etc.) from callers, and merges in
+// the annotations and manual transactions of a portfolio and the counterparty labels.
 type Service struct {
-	provider transaction.Provider
-	logger   *loggeradapter.Logger
+	provider    transaction.Provider
+	annotations transaction.AnnotationRepository
+	manual      transaction.ManualRepository
+	portfolios  domainPortfolio.Repository
+	labels      transaction.Labeler
+	recorder    audit.Recorder
+	logger      *loggeradapter.Logger
+	now         func() time.Time
 }
 
-func NewService(provider transaction.Provider, logger *loggeradapter.Logger) *Service {
+// NewService creates the transaction service. Without labels, counterparties stay unlabeled.
+func NewService(provider transaction.Provider, annotations transaction.AnnotationRepository, manual transaction.ManualRepository, portfolios domainPortfolio.Repository, labels transaction.Labeler, recorder audit.Recorder, logger *loggeradapter.Logger) *Service {
 	if logger == nil {
 		logger = loggeradapter.NewNopLogger()
 	}
+	if recorder == nil {
+		recorder = audit.NopRecorder{}
+	}
 	return &Service{
-		provider: provider,
-		logger:   logger,
+		provider:    provider,
+		annotations: annotations,
+		manual:      manual,
+		portfolios:  portfolios,
+		labels:      labels,
+		recorder:    recorder,
+		logger:      logger,
+		now:         time.Now,
 	}
 }
 
@@ -57,28 +79,34 @@ func (s *Service) TransactionsByAddress(
 ) (transaction.Transactions, error) {
 	addr := strings.ToLower(strings.TrimSpace(address))
 
-	nativeTxs, err := s.provider.NativeTxsByAddress(ctx, addr, opts)
-	if err != nil {
-		return nil, err
-	}
-	internalTxs, err := s.provider.InternalTxsByAddress(ctx, addr, opts)
-	if err != nil {
-		return nil, err
+	var all transaction.Transactions
+	if addr != "" {
+		nativeTxs, err := s.provider.NativeTxsByAddress(ctx, addr, opts)
+		if err != nil {
+			return nil, err
+		}
+		internalTxs, err := s.provider.InternalTxsByAddress(ctx, addr, opts)
+		if err != nil {
+			return nil, err
+		}
+		tokenTxs, err := s.provider.TokenTxsByAddress(ctx, addr, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, nativeTxs...)
+		all = append(all, internalTxs...)
+		all = append(all, tokenTxs...)
 	}
-	tokenTxs, err := s.provider.TokenTxsByAddress(ctx, addr, opts)
+
+	// Manual transactions, internal transfers and annotations all come before filtering
+	all, err := s.merge(ctx, addr, all, opts.PortfolioID)
 	if err != nil {
 		return nil, err
 	}
 
-	var all transaction.Transactions
-	all = append(all, nativeTxs...)
-	all = append(all, internalTxs...)
-	all = append(all, tokenTxs...)
-
-	// Enrich and filter.
 	var filtered transaction.Transactions
 	for _, tx := range all {
-		s.enrichTransaction(tx, addr)
 		if matchesFilter(tx, opts) {
 			filtered = append(filtered, tx)
 		}
@@ -111,6 +139,101 @@ func (s *Service) TransactionsByAddress(
 	return filtered[start:end], nil
 }
 
+// History returns the complete history of an address, merged like TransactionsByAddress
+// but unfiltered and unsorted. The provider is read page by page; truncated reports that
+// it stopped serving pages before the history ended.
+func (s *Service) History(ctx context.Context, address, portfolioID string) (transaction.Transactions, bool, error) {
+	addr := strings.ToLower(strings.TrimSpace(address))
+
+	var (
+		all       transaction.Transactions
+		truncated bool
+	)
+	if addr != "" {
+		for _, fetch := range []pageFetcher{s.provider.NativeTxsByAddress, s.provider.InternalTxsByAddress, s.provider.TokenTxsByAddress} {
+			txs, full, err := allPages(ctx, addr, fetch)
+			if err != nil {
+				return nil, false, err
+			}
+			all = append(all, txs...)
+			truncated = truncated || full
+		}
+	}
+
+	all, err := s.merge(ctx, addr, all, portfolioID)
+	if err != nil {
+		return nil, false, err
+	}
+	if truncated {
+		s.logger.Warn("Transaction history truncated", zap.String("address", addr), zap.Int("max_results", historyPageSize*historyMaxPages))
+	}
+
+	return all, truncated, nil
+}
+
+const (
+	// historyPageSize is the page size History reads the provider with
+	historyPageSize = 1000
+	// historyMaxPages bounds History; Etherscan serves at most 10000 results per query
+	historyMaxPages = 10
+)
+
+type pageFetcher func(ctx context.Context, address string, opts transaction.FilterOptions) ([]*transaction.Transaction, error)
+
+// allPages reads pages until one is not full. full reports that the last allowed page was.
+func allPages(ctx context.Context, address string, fetch pageFetcher) ([]*transaction.Transaction, bool, error) {
+	var all []*transaction.Transaction
+	for page := 1; page <= historyMaxPages; page++ {
+		txs, err := fetch(ctx, address, transaction.FilterOptions{Address: address, Page: page, PageSize: historyPageSize})
+		if err != nil {
+			return nil, false, err
+		}
+		all = append(all, txs...)
+		if len(txs) < historyPageSize {
+			return all, false, nil
+		}
+	}
+	return all, true, nil
+}
+
+// merge enriches provider transactions and adds the portfolio's manual transactions,
+// internal transfer detection, annotations and counterparty labels. Manual transactions
+// carry their own direction and type; transfers between the owner's wallets become
+// internal unless an annotation overrides the type.
+func (s *Service) merge(ctx context.Context, addr string, all transaction.Transactions, portfolioID string) (transaction.Transactions, error) {
+	for _, tx := range all {
+		s.enrichTransaction(tx, addr)
+	}
+
+	var wallets map[string]bool
+	if portfolioID != "" {
+		manual, err := s.manualTransactions(ctx, portfolioID)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, manual...)
+
+		if wallets, err = s.ownWallets(ctx, portfolioID); err != nil {
+			return nil, err
+		}
+	}
+	transaction.MarkInternalTransfers(all, wallets)
+	if portfolioID != "" {
+		annotations, err := s.listAnnotations(ctx, portfolioID)
+		if err != nil {
+			return nil, err
+		}
+		transaction.Annotate(all, annotations)
+	}
+	if s.labels != nil {
+		if err := s.labels.Label(ctx, all); err != nil {
+			return nil, err
+		}
+	}
+
+	return all, nil
+}
+
 // enrichTransaction sets Direction and Type based on address and method data.
 func (s *Service) enrichTransaction(tx *transaction.Transaction, address string) {
 	if tx == nil {
@@ -173,6 +296,14 @@ func matchesFilter(tx *transaction.Transaction, opts transaction.FilterOptions)
 		return false
 	}
 
+	if opts.Tag != nil && strings.TrimSpace(*opts.Tag) != "" && !tx.HasTag(*opts.Tag) {
+		return false
+	}
+
+	if opts.Counterparty != nil && !tx.MatchesCounterparty(*opts.Counterparty) {
+		return false
+	}
+
 	return true
 }
 
@@ -0,0 +1,320 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	domainPortfolio "testtask/internal/domain/portfolio"
+	"testtask/internal/domain/transaction"
+)
+
+const walletAddress = "0x1111111111111111111111111111111111111111"
+
+// mockProvider serves fresh copies of native transfers, as a provider fetching them would
+type mockProvider struct {
+	transaction.Provider
+	nativeTxs []*transaction.Transaction
+}
+
+func (m *mockProvider) NativeTxsByAddress(context.Context, string, transaction.FilterOptions) ([]*transaction.Transaction, error) {
+	result := make([]*transaction.Transaction, 0, len(m.nativeTxs))
+	for _, tx := range m.nativeTxs {
+		copied := *tx
+		result = append(result, &copied)
+	}
+	return result, nil
+}
+
+func (m *mockProvider) InternalTxsByAddress(context.Context, string, transaction.FilterOptions) ([]*transaction.Transaction, error) {
+	return nil, nil
+}
+
+func (m *mockProvider) TokenTxsByAddress(context.Context, string, transaction.FilterOptions) ([]*transaction.Transaction, error) {
+	return nil, nil
+}
+
+// mockRepository implements the annotation and manual repositories in memory
+type mockRepository struct {
+	annotations map[string]*transaction.Annotation
+	manual      []*transaction.Transaction
+}
+
+func (m *mockRepository) ListAnnotations(context.Context, string) ([]*transaction.Annotation, error) {
+	var result []*transaction.Annotation
+	for _, a := range m.annotations {
+		result = append(result, a)
+	}
+	return result, nil
+}
+
+func (m *mockRepository) SetAnnotation(_ context.Context, a *transaction.Annotation) error {
+	m.annotations[a.TransactionID] = a
+	return nil
+}
+
+func (m *mockRepository) DeleteAnnotation(_ context.Context, _ string, transactionID string) error {
+	if _, ok := m.annotations[transactionID]; !ok {
+		return transaction.ErrAnnotationNotFound
+	}
+	delete(m.annotations, transactionID)
+	return nil
+}
+
+func (m *mockRepository) ListManualTransactions(context.Context, string) ([]*transaction.Transaction, error) {
+	return m.manual, nil
+}
+
+func (m *mockRepository) CreateManualTransaction(_ context.Context, _ string, tx *transaction.Transaction) error {
+	m.manual = append(m.manual, tx)
+	return nil
+}
+
+func (m *mockRepository) DeleteManualTransaction(context.Context, string, string) error {
+	return transaction.ErrManualTransactionNotFound
+}
+
+// mockPortfolioRepository serves one portfolio and lists an owner's among it and others; other methods are
+// not used by the service
+type mockPortfolioRepository struct {
+	domainPortfolio.Repository
+	portfolio *domainPortfolio.Portfolio
+	others    []*domainPortfolio.Portfolio
+}
+
+func (m *mockPortfolioRepository) ListByOwner(_ context.Context, ownerID string) ([]*domainPortfolio.Portfolio, error) {
+	var result []*domainPortfolio.Portfolio
+	for _, p := range append([]*domainPortfolio.Portfolio{m.portfolio}, m.others...) {
+		if p.OwnerID == ownerID {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockPortfolioRepository) GetByID(_ context.Context, id string) (*domainPortfolio.Portfolio, error) {
+	if id != m.portfolio.ID {
+		return nil, domainPortfolio.ErrPortfolioNotFound
+	}
+	return m.portfolio, nil
+}
+
+func newTestService(archived bool, provider transaction.Provider) (*Service, *mockRepository) {
+	repo := &mockRepository{annotations: make(map[string]*transaction.Annotation)}
+	portfolios := &mockPortfolioRepository{portfolio: &domainPortfolio.Portfolio{ID: "portfolio-1", Address: walletAddress, Archived: archived}}
+	return NewService(provider, repo, repo, portfolios, nil, nil, nil), repo
+}
+
+func TestService_TransactionsByAddress_Annotations(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	provider := &mockProvider{nativeTxs: []*transaction.Transaction{
+		{ID: "0xaaa", From: "0xfriend", To: walletAddress, Amount: big.NewInt(1), Timestamp: at},
+		{ID: "0xbbb", From: walletAddress, To: "0xshop", Amount: big.NewInt(2), Timestamp: at.Add(time.Hour)},
+	}}
+	svc, _ := newTestService(false, provider)
+	ctx := context.Background()
+
+	if err := svc.SetAnnotation(ctx, &transaction.Annotation{PortfolioID: "portfolio-1", TransactionID: "0xaaa", Tags: []string{" Business ", "business"}, TypeOverride: "Swap"}); err != nil {
+		t.Fatalf("SetAnnotation() error = %v", err)
+	}
+	manual, err := transaction.NewManualTransaction("0xtoken", "TKN", 6, big.NewInt(5), transaction.TransactionDirectionIn, "", at.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("NewManualTransaction() error = %v", err)
+	}
+	if err := svc.CreateManualTransaction(ctx, "portfolio-1", manual); err != nil {
+		t.Fatalf("CreateManualTransaction() error = %v", err)
+	}
+	if manual.To != walletAddress {
+		t.Errorf("manual To = %q, want the portfolio address", manual.To)
+	}
+
+	all, err := svc.TransactionsByAddress(ctx, walletAddress, transaction.FilterOptions{PortfolioID: "portfolio-1"})
+	if err != nil {
+		t.Fatalf("TransactionsByAddress() error = %v", err)
+	}
+	if len(all) != 3 || all[0].ID != manual.ID || all[2].Type != transaction.TransactionTypeSwap || len(all[2].Tags) != 1 {
+		t.Fatalf("TransactionsByAddress() = %+v, want the manual transaction first and the annotated swap last", all)
+	}
+
+	tag := "BUSINESS"
+	tagged, err := svc.TransactionsByAddress(ctx, walletAddress, transaction.FilterOptions{PortfolioID: "portfolio-1", Tag: &tag})
+	if err != nil || len(tagged) != 1 || tagged[0].ID != "0xaaa" {
+		t.Errorf("TransactionsByAddress(tag) = %+v, %v, want only 0xaaa", tagged, err)
+	}
+
+	// Without a portfolio the provider data shows as reported
+	plain, err := svc.TransactionsByAddress(ctx, walletAddress, transaction.FilterOptions{})
+	if err != nil || len(plain) != 2 || plain[1].Type != transaction.TransactionTypeReceive || plain[1].Tags != nil {
+		t.Errorf("TransactionsByAddress(no portfolio) = %+v, %v, want the unannotated transfers", plain, err)
+	}
+}
+
+func TestService_SetAnnotation_Invalid(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		archived bool
+		a        *transaction.Annotation
+		wantErr  error
+	}{
+		{"missing transaction", false, &transaction.Annotation{PortfolioID: "portfolio-1", Note: "x"}, transaction.ErrInvalidAnnotation},
+		{"empty annotation", false, &transaction.Annotation{PortfolioID: "portfolio-1", TransactionID: "0xaaa", Tags: []string{" "}}, transaction.ErrInvalidAnnotation},
+		{"unknown type", false, &transaction.Annotation{PortfolioID: "portfolio-1", TransactionID: "0xaaa", TypeOverride: "mint"}, transaction.ErrInvalidAnnotation},
+		{"archived portfolio", true, &transaction.Annotation{PortfolioID: "portfolio-1", TransactionID: "0xaaa", Note: "x"}, domainPortfolio.ErrPortfolioArchived},
+		{"unknown portfolio", false, &transaction.Annotation{PortfolioID: "portfolio-2", TransactionID: "0xaaa", Note: "x"}, domainPortfolio.ErrPortfolioNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _ := newTestService(tt.archived, nil)
+			if err := svc.SetAnnotation(ctx, tt.a); !errors.Is(err, tt.wantErr) {
+				t.Errorf("SetAnnotation() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestService_TransactionsByAddress_InternalTransfers(t *testing.T) {
+	const coldWallet = "0x2222222222222222222222222222222222222222"
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	provider := &mockProvider{nativeTxs: []*transaction.Transaction{
+		{ID: "from-cold", From: coldWallet, To: walletAddress, Amount: big.NewInt(1), Timestamp: at},
+		{ID: "to-self", From: walletAddress, To: walletAddress, Amount: big.NewInt(1), Timestamp: at},
+		{ID: "overridden", From: walletAddress, To: coldWallet, Amount: big.NewInt(1), Timestamp: at},
+		{ID: "to-friend", From: walletAddress, To: "0xfriend", Amount: big.NewInt(1), Timestamp: at},
+	}}
+	repo := &mockRepository{annotations: map[string]*transaction.Annotation{
+		"overridden": {TransactionID: "overridden", TypeOverride: transaction.TransactionTypeSend},
+	}}
+	portfolios := &mockPortfolioRepository{
+		portfolio: &domainPortfolio.Portfolio{ID: "portfolio-1", OwnerID: "user-1", Address: walletAddress},
+		others: []*domainPortfolio.Portfolio{
+			{ID: "portfolio-2", OwnerID: "user-1", Address: coldWallet},
+			{ID: "portfolio-3", OwnerID: "user-2", Address: "0xfriend"},
+		},
+	}
+	svc := NewService(provider, repo, repo, portfolios, nil, nil, nil)
+
+	txs, err := svc.TransactionsByAddress(context.Background(), walletAddress, transaction.FilterOptions{PortfolioID: "portfolio-1"})
+	if err != nil {
+		t.Fatalf("TransactionsByAddress() error = %v", err)
+	}
+	want := map[string]transaction.TransactionType{
+		"from-cold":  transaction.TransactionTypeInternalTransfer,
+		"to-self":    transaction.TransactionTypeInternalTransfer,
+		"overridden": transaction.TransactionTypeSend,
+		"to-friend":  transaction.TransactionTypeSend,
+	}
+	for _, tx := range txs {
+		if tx.Type != want[tx.ID] {
+			t.Errorf("%s: Type = %q, want %q", tx.ID, tx.Type, want[tx.ID])
+		}
+	}
+}
+
+// mockLabeler labels addresses from a fixed map
+type mockLabeler map[string]string
+
+func (m mockLabeler) Label(_ context.Context, txs []*transaction.Transaction) error {
+	for _, tx := range txs {
+		tx.FromLabel, tx.ToLabel = m[tx.From], m[tx.To]
+	}
+	return nil
+}
+
+func TestService_TransactionsByAddress_Counterparty(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	provider := &mockProvider{nativeTxs: []*transaction.Transaction{
+		{ID: "swap", From: walletAddress, To: "0xrouter", Amount: big.NewInt(1), Timestamp: at},
+		{ID: "deposit", From: walletAddress, To: "0xbinance", Amount: big.NewInt(1), Timestamp: at.Add(time.Hour)},
+		{ID: "withdrawal", From: "0xbinance", To: walletAddress, Amount: big.NewInt(1), Timestamp: at.Add(2 * time.Hour)},
+	}}
+	labels := mockLabeler{"0xrouter": "Uniswap V2: Router 2", "0xbinance": "Binance 14", walletAddress: "Hot wallet"}
+	repo := &mockRepository{annotations: make(map[string]*transaction.Annotation)}
+	svc := NewService(provider, repo, repo, &mockPortfolioRepository{}, labels, nil, nil)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"binance", []string{"withdrawal", "deposit"}},
+		{"UNISWAP", []string{"swap"}},
+		{"hot wallet", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			query := tt.query
+			txs, err := svc.TransactionsByAddress(context.Background(), walletAddress, transaction.FilterOptions{Counterparty: &query})
+			if err != nil {
+				t.Fatalf("TransactionsByAddress() error = %v", err)
+			}
+			if len(txs) != len(tt.want) {
+				t.Fatalf("TransactionsByAddress() = %d transactions, want %v", len(txs), tt.want)
+			}
+			for i, tx := range txs {
+				if tx.ID != tt.want[i] {
+					t.Errorf("transaction %d = %s, want %s", i, tx.ID, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// pagedProvider serves count native transfers in pages, like Etherscan
+type pagedProvider struct {
+	transaction.Provider
+	count int
+	pages []int
+}
+
+func (m *pagedProvider) NativeTxsByAddress(_ context.Context, _ string, opts transaction.FilterOptions) ([]*transaction.Transaction, error) {
+	m.pages = append(m.pages, opts.Page)
+	var result []*transaction.Transaction
+	for i := (opts.Page - 1) * opts.PageSize; i < opts.Page*opts.PageSize && i < m.count; i++ {
+		result = append(result, &transaction.Transaction{ID: fmt.Sprintf("tx-%d", i), From: walletAddress, To: "0xfriend", Amount: big.NewInt(1)})
+	}
+	return result, nil
+}
+
+func (m *pagedProvider) InternalTxsByAddress(context.Context, string, transaction.FilterOptions) ([]*transaction.Transaction, error) {
+	return nil, nil
+}
+
+func (m *pagedProvider) TokenTxsByAddress(context.Context, string, transaction.FilterOptions) ([]*transaction.Transaction, error) {
+	return nil, nil
+}
+
+func TestService_History(t *testing.T) {
+	tests := []struct {
+		name          string
+		count         int
+		wantCount     int
+		wantPages     int
+		wantTruncated bool
+	}{
+		{name: "single page", count: 10, wantCount: 10, wantPages: 1},
+		{name: "several pages", count: 2500, wantCount: 2500, wantPages: 3},
+		{name: "ends on a page boundary", count: 2000, wantCount: 2000, wantPages: 3},
+		{name: "beyond the provider window", count: 12000, wantCount: 10000, wantPages: 10, wantTruncated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &pagedProvider{count: tt.count}
+			svc, _ := newTestService(false, provider)
+
+			txs, truncated, err := svc.History(context.Background(), walletAddress, "portfolio-1")
+			if err != nil {
+				t.Fatalf("History() error = %v", err)
+			}
+			if len(txs) != tt.wantCount || truncated != tt.wantTruncated {
+				t.Errorf("History() = %d transactions, truncated %v, want %d, %v", len(txs), truncated, tt.wantCount, tt.wantTruncated)
+			}
+			if len(provider.pages) != tt.wantPages {
+				t.Errorf("provider pages read = %v, want %d", provider.pages, tt.wantPages)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package addressbook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"testtask/internal/domain/token"
+	"testtask/internal/domain/transaction"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidRegistry = errors.New("invalid address registry")
+	ErrInvalidEntry    = errors.New("invalid address book entry")
+	ErrEntryNotFound   = errors.New("address book entry not found")
+	ErrEntryExists     = errors.New("address book entry already exists")
+)
+
+// Limits on user-entered text
+const (
+	MaxLabelLength = 100
+	MaxNoteLength  = 1000
+)
+
+// Category groups well-known addresses in the registry
+type Category string
+
+const (
+	CategoryDEX      Category = "dex"
+	CategoryBridge   Category = "bridge"
+	CategoryExchange Category = "exchange"
+	CategoryStaking  Category = "staking"
+	CategoryOther    Category = "other"
+)
+
+// Valid reports whether c is a known category
+func (c Category) Valid() bool {
+	switch c {
+	case CategoryDEX, CategoryBridge, CategoryExchange, CategoryStaking, CategoryOther:
+		return true
+	}
+	return false
+}
+
+// KnownAddress is a well-known contract or wallet, such as a DEX router, a bridge or an
+// exchange hot wallet
+type KnownAddress struct {
+	Address  string
+	Label    string
+	Category Category
+}
+
+// Registry holds the bundled well-known addresses
+type Registry struct {
+	known map[string]*KnownAddress // lowercase address -> known address
+}
+
+// NewRegistry validates the addresses and indexes them by lowercase address
+func NewRegistry(known []*KnownAddress) (*Registry, error) {
+	r := &Registry{known: make(map[string]*KnownAddress, len(known))}
+	for _, k := range known {
+		k.Address = strings.ToLower(strings.TrimSpace(k.Address))
+		k.Label = strings.TrimSpace(k.Label)
+		if !token.IsAddress(k.Address) || k.Label == "" || !k.Category.Valid() {
+			return nil, fmt.Errorf("%w: %q needs an address, a label and a category", ErrInvalidRegistry, k.Label)
+		}
+		if _, ok := r.known[k.Address]; ok {
+			return nil, fmt.Errorf("%w: duplicate address %s", ErrInvalidRegistry, k.Address)
+		}
+		r.known[k.Address] = k
+	}
+	return r, nil
+}
+
+// Lookup returns the well-known address, case-insensitively
+func (r *Registry) Lookup(address string) (*KnownAddress, bool) {
+	if r == nil {
+		return nil, false
+	}
+	k, ok := r.known[strings.ToLower(strings.TrimSpace(address))]
+	return k, ok
+}
+
+// List returns the well-known addresses of the category, all when it is empty, sorted by label
+func (r *Registry) List(category Category) []*KnownAddress {
+	if r == nil {
+		return nil
+	}
+	known := make([]*KnownAddress, 0, len(r.known))
+	for _, k := range r.known {
+		if category == "" || k.Category == category {
+			known = append(known, k)
+		}
+	}
+	sort.Slice(known, func(i, j int) bool {
+		if known[i].Label != known[j].Label {
+			return known[i].Label < known[j].Label
+		}
+		return known[i].Address < known[j].Address
+	})
+	return known
+}
+
+// Entry is a label a user gave to an address. It takes precedence over the registry.
+type Entry struct {
+	ID        string
+	UserID    string
+	Address   string
+	Label     string
+	Note      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewEntry creates a validated entry of the user with a fresh ID
+func NewEntry(userID, address, label, note string) (*Entry, error) {
+	e := &Entry{
+		ID:      uuid.New().String(),
+		UserID:  userID,
+		Address: address,
+		Label:   label,
+		Note:    note,
+	}
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Validate normalizes the address to lowercase and trims the label and note
+func (e *Entry) Validate() error {
+	e.Address = strings.ToLower(strings.TrimSpace(e.Address))
+	e.Label = strings.TrimSpace(e.Label)
+	e.Note = strings.TrimSpace(e.Note)
+	switch {
+	case !token.IsAddress(e.Address):
+		return fmt.Errorf("%w: address must be a 0x-prefixed 20-byte hex address", ErrInvalidEntry)
+	case e.Label == "":
+		return fmt.Errorf("%w: label is required", ErrInvalidEntry)
+	case len(e.Label) > MaxLabelLength:
+		return fmt.Errorf("%w: label is longer than %d characters", ErrInvalidEntry, MaxLabelLength)
+	case len(e.Note) > MaxNoteLength:
+		return fmt.Errorf("%w: note is longer than %d characters", ErrInvalidEntry, MaxNoteLength)
+	}
+	return nil
+}
+
+// Repository stores the address book entries of each user
+type Repository interface {
+	List(ctx context.Context, userID string) ([]*Entry, error)
+	Get(ctx context.Context, userID, id string) (*Entry, error)
+	Create(ctx context.Context, e *Entry) error
+	Update(ctx context.Context, e *Entry) error
+	Delete(ctx context.Context, userID, id string) error
+}
+
+// Apply sets FromLabel and ToLabel on the transactions: the user's entries first, then
+// the registry. Addresses without either keep an empty label.
+func Apply(txs []*transaction.Transaction, registry *Registry, entries []*Entry) {
+	own := make(map[string]string, len(entries))
+	for _, e := range entries {
+		own[strings.ToLower(e.Address)] = e.Label
+	}
+	label := func(address string) string {
+		if address == "" {
+			return ""
+		}
+		if l, ok := own[strings.ToLower(address)]; ok {
+			return l
+		}
+		if k, ok := registry.Lookup(address); ok {
+			return k.Label
+		}
+		return ""
+	}
+	for _, tx := range txs {
+		if tx == nil {
+			continue
+		}
+		tx.FromLabel = label(tx.From)
+		tx.ToLabel = label(tx.To)
+	}
+}
@@ -0,0 +1,117 @@
+package addressbook
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"testtask/internal/domain/transaction"
+)
+
+const (
+	routerAddress = "0x7a250d5630b4cf539739df2c5dacb4c659f2488d"
+	friendAddress = "0x1111111111111111111111111111111111111111"
+	ownerAddress  = "0x2222222222222222222222222222222222222222"
+)
+
+func TestNewRegistry_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		known []*KnownAddress
+	}{
+		{"invalid address", []*KnownAddress{{Address: "0x1234", Label: "Router", Category: CategoryDEX}}},
+		{"missing label", []*KnownAddress{{Address: routerAddress, Category: CategoryDEX}}},
+		{"unknown category", []*KnownAddress{{Address: routerAddress, Label: "Router", Category: "casino"}}},
+		{"duplicate", []*KnownAddress{
+			{Address: routerAddress, Label: "Router", Category: CategoryDEX},
+			{Address: "0x7A250D5630B4CF539739DF2C5DACB4C659F2488D", Label: "Router again", Category: CategoryDEX},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewRegistry(tt.known); !errors.Is(err, ErrInvalidRegistry) {
+				t.Errorf("NewRegistry() error = %v, want ErrInvalidRegistry", err)
+			}
+		})
+	}
+}
+
+func TestEntry_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   Entry
+		wantErr bool
+	}{
+		{"valid", Entry{Address: " 0x7A250D5630B4CF539739DF2C5DACB4C659F2488D ", Label: " Router "}, false},
+		{"invalid address", Entry{Address: "vitalik", Label: "Vitalik"}, true},
+		{"missing label", Entry{Address: routerAddress, Label: "  "}, true},
+		{"long label", Entry{Address: routerAddress, Label: strings.Repeat("a", MaxLabelLength+1)}, true},
+		{"long note", Entry{Address: routerAddress, Label: "Router", Note: strings.Repeat("a", MaxNoteLength+1)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.entry.Validate()
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidEntry) {
+					t.Errorf("Validate() error = %v, want ErrInvalidEntry", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if tt.entry.Address != routerAddress || tt.entry.Label != "Router" {
+				t.Errorf("Validate() left address %q and label %q, want them normalized", tt.entry.Address, tt.entry.Label)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	registry, err := NewRegistry([]*KnownAddress{{Address: routerAddress, Label: "Uniswap V2: Router 2", Category: CategoryDEX}})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+	entries := []*Entry{
+		{Address: friendAddress, Label: "Alice"},
+		{Address: ownerAddress, Label: "Cold wallet"},
+	}
+
+	txs := []*transaction.Transaction{
+		{ID: "swap", From: ownerAddress, To: "0x7A250D5630B4CF539739DF2C5DACB4C659F2488D", Direction: transaction.TransactionDirectionOut},
+		{ID: "gift", From: friendAddress, To: ownerAddress, Direction: transaction.TransactionDirectionIn},
+		{ID: "unknown", From: "0x3333333333333333333333333333333333333333", To: ownerAddress, Direction: transaction.TransactionDirectionIn},
+		nil,
+	}
+	Apply(txs, registry, entries)
+
+	want := []struct{ from, to, counterparty string }{
+		{"Cold wallet", "Uniswap V2: Router 2", "Uniswap V2: Router 2"},
+		{"Alice", "Cold wallet", "Alice"},
+		{"", "Cold wallet", ""},
+	}
+	for i, w := range want {
+		tx := txs[i]
+		if tx.FromLabel != w.from || tx.ToLabel != w.to || tx.CounterpartyLabel() != w.counterparty {
+			t.Errorf("%s: labels = %q, %q, %q, want %q, %q, %q", tx.ID, tx.FromLabel, tx.ToLabel, tx.CounterpartyLabel(), w.from, w.to, w.counterparty)
+		}
+	}
+}
+
+func TestApply_EntryOverridesRegistry(t *testing.T) {
+	registry, err := NewRegistry([]*KnownAddress{{Address: routerAddress, Label: "Uniswap V2: Router 2", Category: CategoryDEX}})
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+	tx := &transaction.Transaction{To: routerAddress, Direction: transaction.TransactionDirectionOut}
+
+	Apply([]*transaction.Transaction{tx}, registry, []*Entry{{Address: routerAddress, Label: "My DEX"}})
+	if tx.ToLabel != "My DEX" {
+		t.Errorf("ToLabel = %q, want the address book label", tx.ToLabel)
+	}
+
+	Apply([]*transaction.Transaction{tx}, nil, nil)
+	if tx.ToLabel != "" {
+		t.Errorf("ToLabel = %q without registry and entries, want empty", tx.ToLabel)
+	}
+}
@@ -0,0 +1,164 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidFilter = errors.New("invalid audit filter")
+
+// SystemActor is recorded for changes made without an authenticated caller,
+// such as scheduled jobs
+const SystemActor = "system"
+
+// Actions recorded in the audit log
+const (
+	ActionPortfolioCreate    = "portfolio.create"
+	ActionPortfolioUpdate    = "portfolio.update"
+	ActionPortfolioArchive   = "portfolio.archive"
+	ActionPortfolioUnarchive = "portfolio.unarchive"
+	ActionPortfolioDelete    = "portfolio.delete"
+	ActionHoldingCreate      = "holding.create"
+	ActionHoldingUpdate      = "holding.update"
+	ActionHoldingDelete      = "holding.delete"
+	ActionOverrideCreate     = "price_override.create"
+	ActionOverrideUpdate     = "price_override.update"
+	ActionOverrideDelete     = "price_override.delete"
+	ActionCustomTokenCreate  = "custom_token.create"
+	ActionCustomTokenPrice   = "custom_token.set_price"
+	ActionCustomTokenDelete  = "custom_token.delete"
+	ActionMemberAdd          = "member.add"
+	ActionMemberUpdate       = "member.update"
+	ActionMemberRemove       = "member.remove"
+	ActionShareTokenCreate   = "share_token.create"
+	ActionShareTokenRevoke   = "share_token.revoke"
+	ActionAPIKeyCreate       = "api_key.create"
+	ActionAPIKeyRevoke       = "api_key.revoke"
+	ActionStatementImport    = "exchange_statement.import"
+	ActionConnectionCreate   = "exchange_connection.create"
+	ActionConnectionDelete   = "exchange_connection.delete"
+	ActionManualEntryCreate  = "manual_entry.create"
+	ActionManualEntryUpdate  = "manual_entry.update"
+	ActionManualEntryDelete  = "manual_entry.delete"
+	ActionIncomeClassify     = "income_classification.set"
+	ActionIncomeUnclassify   = "income_classification.delete"
+	ActionAnnotationSet      = "transaction_annotation.set"
+	ActionAnnotationDelete   = "transaction_annotation.delete"
+	ActionManualTxCreate     = "manual_transaction.create"
+	ActionManualTxDelete     = "manual_transaction.delete"
+	ActionAddressCreate      = "address_book.create"
+	ActionAddressUpdate      = "address_book.update"
+	ActionAddressDelete      = "address_book.delete"
+)
+
+// Resource types recorded in the audit log
+const (
+	ResourcePortfolio     = "portfolio"
+	ResourceHolding       = "holding"
+	ResourcePriceOverride = "price_override"
+	ResourceCustomToken   = "custom_token"
+	ResourceMember        = "member"
+	ResourceShareToken    = "share_token"
+	ResourceAPIKey        = "api_key"
+	ResourceStatement     = "exchange_statement"
+	ResourceConnection    = "exchange_connection"
+	ResourceManualEntry   = "manual_entry"
+	ResourceIncome        = "income_classification"
+	ResourceAnnotation    = "transaction_annotation"
+	ResourceManualTx      = "manual_transaction"
+	ResourceAddressBook   = "address_book"
+)
+
+// DefaultLimit and MaxLimit bound the number of entries returned by a query
+const (
+	DefaultLimit = 100
+	MaxLimit     = 1000
+)
+
+// Entry is an immutable record of a state-changing operation. Before is empty for
+// creations and After is empty for deletions.
+type Entry struct {
+	ID           string
+	ActorID      string
+	Action       string
+	ResourceType string
+	ResourceID   string
+	PortfolioID  string // empty for changes outside a portfolio
+	Before       json.RawMessage
+	After        json.RawMessage
+	RequestID    string
+	CreatedAt    time.Time
+}
+
+// NewEntry creates an entry with a fresh ID; the recorder fills in actor, request ID and time
+func NewEntry(action, resourceType, resourceID, portfolioID string) *Entry {
+	return &Entry{
+		ID:           uuid.New().String(),
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		PortfolioID:  portfolioID,
+	}
+}
+
+// Filter selects audit entries. From is inclusive and To is exclusive.
+type Filter struct {
+	PortfolioID string
+	ActorID     string
+	From        *time.Time
+	To          *time.Time
+	Limit       int
+}
+
+// Validate checks the time range and normalizes the limit
+func (f *Filter) Validate() error {
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		return fmt.Errorf("%w: from must be before to", ErrInvalidFilter)
+	}
+	if f.Limit < 0 {
+		return fmt.Errorf("%w: limit must be positive", ErrInvalidFilter)
+	}
+	if f.Limit == 0 {
+		f.Limit = DefaultLimit
+	}
+	if f.Limit > MaxLimit {
+		f.Limit = MaxLimit
+	}
+	return nil
+}
+
+// Repository persists audit entries. The log is append-only: entries are never
+// updated and survive the deletion of the resources they describe.
+type Repository interface {
+	Append(ctx context.Context, e *Entry) error
+	List(ctx context.Context, filter Filter) ([]*Entry, error)
+}
+
+// Recorder records state changes; before and after are snapshots serialized as JSON
+// and may be nil
+type Recorder interface {
+	Record(ctx context.Context, e *Entry, before, after any)
+}
+
+// NopRecorder discards entries, for services constructed without an audit log
+type NopRecorder struct{}
+
+func (NopRecorder) Record(context.Context, *Entry, any, any) {}
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying the ID of the HTTP request being served
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID, or an empty string outside a request
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
@@ -6,8 +6,15 @@ type Chain struct {
 	ID      string
 	ChianID string
 	Name    string
+	// Platform is the CoinGecko asset platform ID used for contract lookups
+	Platform string
 }
 
 type Repository interface {
 	GetList(ctx context.Context) ([]*Chain, error)
 }
+
+// Supported lists the chains the tracker collects token metadata for
+var Supported = []*Chain{
+	{ID: "ethereum", ChianID: "1", Name: "Ethereum", Platform: "ethereum"},
+}
@@ -2,12 +2,20 @@ package domain
 
 import (
 	"context"
+	"io"
 	"math/big"
+	"testtask/internal/domain/addressbook"
+	"testtask/internal/domain/audit"
+	"testtask/internal/domain/exchange"
 	domainHolding "testtask/internal/domain/holding"
+	"testtask/internal/domain/income"
 	domainPortfolio "testtask/internal/domain/portfolio"
 	"testtask/internal/domain/price"
+	"testtask/internal/domain/tax"
 	"testtask/internal/domain/token"
 	"testtask/internal/domain/transaction"
+	"testtask/internal/domain/user"
+	"time"
 )
 
 type RateLimiterService interface {
@@ -24,22 +32,143 @@ type Cache[K comparable, V any] interface {
 // TransactionService defines the interface for transaction operations.
 type TransactionService interface {
 	GetTransactions(ctx context.Context, address string, opts transaction.FilterOptions) ([]transaction.Transaction, int, error)
+	// History returns the complete history of an address; truncated reports that the
+	// provider stopped serving pages before it ended
+	History(ctx context.Context, address, portfolioID string) (txs transaction.Transactions, truncated bool, err error)
+
+	ListAnnotations(ctx context.Context, portfolioID string) ([]*transaction.Annotation, error)
+	SetAnnotation(ctx context.Context, a *transaction.Annotation) error
+	DeleteAnnotation(ctx context.Context, portfolioID, transactionID string) error
+	ListManualTransactions(ctx context.Context, portfolioID string) ([]*transaction.Transaction, error)
+	CreateManualTransaction(ctx context.Context, portfolioID string, tx *transaction.Transaction) error
+	DeleteManualTransaction(ctx context.Context, portfolioID, id string) error
 }
 
 type PriceService interface {
 	GetPrices(ctx context.Context, tokens []*token.Token, currency string) (map[*token.Token]*price.Price, error)
+	GetHistoricalPrices(ctx context.Context, tokens []*token.Token, currency string, at time.Time) (map[*token.Token]*price.Price, error)
+
+	CreateOverride(ctx context.Context, o *price.Override) error
+	UpdateOverride(ctx context.Context, o *price.Override) error
+	DeleteOverride(ctx context.Context, portfolioID, id string) error
+	ListOverrides(ctx context.Context, portfolioID string) ([]*price.Override, error)
+	ListOverrideAudit(ctx context.Context, portfolioID string) ([]*price.OverrideAuditEntry, error)
 }
 
 type PortfolioService interface {
-	ListPortfolios(ctx context.Context) ([]*domainPortfolio.Portfolio, error)
+	ListPortfolios(ctx context.Context, opts domainPortfolio.ListOptions) ([]*domainPortfolio.Portfolio, int, error)
 	CreatePortfolio(ctx context.Context, portfolio *domainPortfolio.Portfolio) error
 	GetPortfolio(ctx context.Context, portfolioID string) (*domainPortfolio.Portfolio, error)
-	AddHolding(ctx context.Context, userID string, holding *domainHolding.Holding) error
-	UpdateHolding(ctx context.Context, userID string, holdingID string, amount *big.Int) error
-	DeleteHolding(ctx context.Context, userID string, holdingID string) error
+	UpdatePortfolio(ctx context.Context, portfolioID string, changes domainPortfolio.Changes) (*domainPortfolio.Portfolio, error)
+	ArchivePortfolio(ctx context.Context, portfolioID string, archived bool) (*domainPortfolio.Portfolio, error)
+	DeletePortfolio(ctx context.Context, portfolioID string) error
+	AddHolding(ctx context.Context, portfolioID string, holding *domainHolding.Holding) error
+	UpdateHolding(ctx context.Context, portfolioID string, holdingID string, amount *big.Int, version int64) error
+	DeleteHolding(ctx context.Context, portfolioID string, holdingID string) error
+	ApplyHoldingBatch(ctx context.Context, portfolioID string, ops []*domainHolding.BatchOperation, dryRun bool) ([]*domainHolding.BatchResult, error)
+	RecordLedgerEntry(ctx context.Context, portfolioID string, t *token.Token, draft *domainHolding.LedgerEntry) (*domainHolding.Holding, error)
+	ListLedger(ctx context.Context, portfolioID string, filter domainHolding.LedgerFilter) ([]*domainHolding.LedgerEntry, error)
+	GetHoldingsAt(ctx context.Context, portfolioID string, at time.Time) ([]*domainHolding.Holding, error)
 	GetPortfolioAssets(ctx context.Context, portfolioID string, currency string) (*domainPortfolio.Portfolio, []*domainPortfolio.Asset, error)
+	GetTopMovers(ctx context.Context, portfolioID string, currency string, limit int) (gainers []*domainPortfolio.Asset, losers []*domainPortfolio.Asset, err error)
+	MergeEquivalentAssets(assets []*domainPortfolio.Asset) []*domainPortfolio.Asset
+	ListManualEntries(ctx context.Context, portfolioID string) ([]*domainPortfolio.ManualEntry, error)
+	CreateManualEntry(ctx context.Context, entry *domainPortfolio.ManualEntry) error
+	UpdateManualEntry(ctx context.Context, entry *domainPortfolio.ManualEntry) error
+	DeleteManualEntry(ctx context.Context, portfolioID, entryID string) error
 }
 
 type TokensService interface {
 	GetTokenByAddress(_ context.Context, address string) (*token.Token, bool)
+	GetTokenForPortfolio(ctx context.Context, portfolioID string, address string) (*token.Token, bool)
+	SearchTokens(ctx context.Context, query string, page, pageSize int) ([]*token.Token, int, error)
+
+	CreateCustomToken(ctx context.Context, t *token.CustomToken) error
+	GetCustomToken(ctx context.Context, id string) (*token.CustomToken, error)
+	ListCustomTokens(ctx context.Context, portfolioID string) ([]*token.CustomToken, error)
+	SetCustomTokenPrice(ctx context.Context, id string, value *big.Int, currency string) (*token.CustomToken, error)
+	DeleteCustomToken(ctx context.Context, id string) error
+}
+
+// TokenAdminService reloads the bundled token list at runtime.
+type TokenAdminService interface {
+	ReloadTokens(ctx context.Context, trigger string) (*token.ReloadResult, error)
+	LastTokenReload() *token.ReloadResult
+}
+
+// TokenCatalogService registers tokens first seen in transfer history and
+// resolves the custom tokens visible to a portfolio.
+type TokenCatalogService interface {
+	DiscoverFromTransactions(ctx context.Context, txs []*transaction.Transaction) (int, error)
+	CustomTokens(ctx context.Context, portfolioID string, addresses []string) map[string]*token.Token
+}
+
+// AuthService authenticates API callers and manages their credentials
+type AuthService interface {
+	Register(ctx context.Context, email, name string) (*user.User, string, error)
+	CreateAPIKey(ctx context.Context, userID, name string) (*user.APIKey, string, error)
+	ListAPIKeys(ctx context.Context, userID string) ([]*user.APIKey, error)
+	RevokeAPIKey(ctx context.Context, userID, keyID string) error
+	AuthenticateAPIKey(ctx context.Context, apiKey string) (*user.User, error)
+	IssueToken(ctx context.Context, u *user.User) (string, time.Time, error)
+	AuthenticateToken(ctx context.Context, token string) (*user.User, error)
+}
+
+// SharingService resolves the caller's role on portfolios and manages members and share tokens
+type SharingService interface {
+	Authorize(ctx context.Context, portfolioID string, required domainPortfolio.Role) (domainPortfolio.Role, error)
+	ListMembers(ctx context.Context, portfolioID string) ([]*domainPortfolio.Member, error)
+	AddMember(ctx context.Context, portfolioID, email, role string) (*domainPortfolio.Member, error)
+	UpdateMember(ctx context.Context, portfolioID, userID, role string) (*domainPortfolio.Member, error)
+	RemoveMember(ctx context.Context, portfolioID, userID string) error
+	CreateShareToken(ctx context.Context, portfolioID, name string, expiresAt *time.Time) (*domainPortfolio.ShareToken, string, error)
+	ListShareTokens(ctx context.Context, portfolioID string) ([]*domainPortfolio.ShareToken, error)
+	RevokeShareToken(ctx context.Context, portfolioID, tokenID string) error
+	ResolveShareToken(ctx context.Context, token string) (*domainPortfolio.ShareToken, error)
+}
+
+// ExchangeService imports exchange statements into portfolios and syncs connected exchange accounts
+type ExchangeService interface {
+	ImportStatement(ctx context.Context, portfolioID string, e exchange.Exchange, mapping *exchange.Mapping, body io.Reader) (*exchange.ImportResult, error)
+	ListRecords(ctx context.Context, portfolioID string, filter exchange.Filter) ([]*exchange.Record, error)
+	CreateConnection(ctx context.Context, portfolioID string, e exchange.Exchange, label string, creds exchange.Credentials) (*exchange.Connection, error)
+	ListConnections(ctx context.Context, portfolioID string) ([]*exchange.Connection, error)
+	DeleteConnection(ctx context.Context, portfolioID, connectionID string) error
+	SyncConnection(ctx context.Context, portfolioID, connectionID string) (*exchange.SyncResult, error)
+}
+
+// AuditService queries the append-only audit log
+type AuditService interface {
+	List(ctx context.Context, filter audit.Filter) ([]*audit.Entry, error)
+}
+
+// TaxService generates capital gains reports under the rules of a jurisdiction
+type TaxService interface {
+	Jurisdictions() []*tax.Rules
+	GenerateReport(ctx context.Context, req tax.Request) (*tax.Report, error)
+}
+
+// IncomeService classifies incoming transfers as income and records rebasing token growth
+type IncomeService interface {
+	Classify(ctx context.Context, portfolioID string, txs []*transaction.Transaction) error
+	ListClassifications(ctx context.Context, portfolioID string) ([]*income.Classification, error)
+	SetClassification(ctx context.Context, c *income.Classification) error
+	DeleteClassification(ctx context.Context, portfolioID, transactionID string) error
+	Accruals(ctx context.Context, portfolioID string) ([]*income.Accrual, error)
+	ObserveRebases(ctx context.Context, portfolioID string) ([]*income.Accrual, error)
+}
+
+// AddressBookService manages the caller's address labels and the registry of well-known addresses
+type AddressBookService interface {
+	KnownAddresses(category addressbook.Category) []*addressbook.KnownAddress
+	ListEntries(ctx context.Context, userID string) ([]*addressbook.Entry, error)
+	CreateEntry(ctx context.Context, userID, address, label, note string) (*addressbook.Entry, error)
+	UpdateEntry(ctx context.Context, userID, id, address, label, note string) (*addressbook.Entry, error)
+	DeleteEntry(ctx context.Context, userID, id string) error
+}
+
+// ENSService resolves ENS names given in place of addresses and names the counterparties of transactions
+type ENSService interface {
+	ResolveAddress(ctx context.Context, input string) (string, error)
+	Name(ctx context.Context, txs []*transaction.Transaction) error
 }
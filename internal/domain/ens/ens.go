@@ -0,0 +1,98 @@
+package ens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"testtask/internal/domain/token"
+
+	"golang.org/x/crypto/sha3"
+)
+
+var (
+	ErrInvalidName = errors.New("invalid ens name")
+	ErrNotResolved = errors.New("ens name does not resolve to an address")
+	ErrUnavailable = errors.New("ens resolution is unavailable")
+)
+
+// RegistryAddress is the ENS registry on Ethereum mainnet
+const RegistryAddress = "0x00000000000c2e074ec69a0dfb2997ba6c7d2e1e"
+
+// MaxNameLength bounds the names accepted for resolution
+const MaxNameLength = 255
+
+// IsName reports whether s looks like an ENS name rather than an address: dot-separated
+// labels such as vitalik.eth
+func IsName(s string) bool {
+	s = strings.TrimSpace(s)
+	return !token.IsAddress(s) && strings.Contains(s, ".")
+}
+
+// Normalize lowercases and validates a name. It does not implement ENSIP-15: rather than
+// mapping unicode names to their normalized form, which a plain lowercase gets wrong, it
+// only accepts labels of ASCII letters, digits and hyphens and rejects everything else,
+// including emoji and non-Latin names. Such a name hashes the same under ENSIP-15.
+// The check runs before lowercasing, since unicode case mapping can yield ASCII.
+func Normalize(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > MaxNameLength {
+		return "", fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	for _, label := range strings.Split(name, ".") {
+		if label == "" {
+			return "", fmt.Errorf("%w: %q has an empty label", ErrInvalidName, name)
+		}
+		for _, r := range label {
+			if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '-' {
+				return "", fmt.Errorf("%w: %q may only contain a-z, 0-9 and hyphens", ErrInvalidName, name)
+			}
+		}
+	}
+	return strings.ToLower(name), nil
+}
+
+// Keccak256 returns the legacy Keccak-256 hash Ethereum uses
+func Keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+// Namehash returns the EIP-137 node of a normalized name; the empty name is the root
+func Namehash(name string) [32]byte {
+	var node [32]byte
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		copy(node[:], Keccak256(node[:], Keccak256([]byte(labels[i]))))
+	}
+	return node
+}
+
+// ReverseName returns the name under addr.reverse that holds the primary name of address
+func ReverseName(address string) string {
+	return strings.TrimPrefix(strings.ToLower(address), "0x") + ".addr.reverse"
+}
+
+// Resolver resolves names against the ENS registry
+type Resolver interface {
+	// Resolve returns the lowercase address a normalized name points to, ErrNotResolved
+	// when it has none
+	Resolve(ctx context.Context, name string) (string, error)
+	// LookupAddress returns the primary name of an address, empty when it has none or the
+	// name does not resolve back to the address
+	LookupAddress(ctx context.Context, address string) (string, error)
+}
+
+// Result is a cached resolution; an empty Value records that nothing was found
+type Result struct {
+	Value      string
+	ResolvedAt time.Time
+}
@@ -0,0 +1,87 @@
+package ens
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestNamehash(t *testing.T) {
+	// Test vectors from EIP-137
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"", "0000000000000000000000000000000000000000000000000000000000000000"},
+		{"eth", "93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae"},
+		{"foo.eth", "de9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := Namehash(tt.name)
+			if got := hex.EncodeToString(node[:]); got != tt.want {
+				t.Errorf("Namehash(%q) = %s, want %s", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{" Vitalik.ETH ", "vitalik.eth", false},
+		{"pay.vitalik.eth", "pay.vitalik.eth", false},
+		{"vitalik..eth", "", true},
+		{".eth", "", true},
+		{"vita lik.eth", "", true},
+		{"", "", true},
+		{"VITALIK.eth", "vitalik.eth", false},
+		{"my-name2.eth", "my-name2.eth", false},
+		{"vitalık.eth", "", true},    // dotless i
+		{"VİTALIK.eth", "", true},    // strings.ToLower turns this into a plain i
+		{"ÄPFEL.eth", "", true},      // ENSIP-15 maps this to äpfel, which is not supported
+		{"\U0001F680.eth", "", true}, // emoji
+		{"名前.eth", "", true},
+		{"under_score.eth", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.name)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidName) {
+					t.Errorf("Normalize() error = %v, want ErrInvalidName", err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Normalize() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsName(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{"vitalik.eth", true},
+		{"0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045", false},
+		{"0x1234", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsName(tt.s); got != tt.want {
+			t.Errorf("IsName(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestReverseName(t *testing.T) {
+	if got := ReverseName("0xD8DA6BF26964AF9D7EED9E03E53415D37AA96045"); got != "d8da6bf26964af9d7eed9e03e53415d37aa96045.addr.reverse" {
+		t.Errorf("ReverseName() = %q", got)
+	}
+}
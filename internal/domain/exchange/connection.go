@@ -0,0 +1,138 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrConnectionNotFound = errors.New("exchange connection not found")
+	ErrConnectionExists   = errors.New("exchange connection already exists")
+	ErrInvalidConnection  = errors.New("invalid exchange connection")
+	// ErrNotReadOnly rejects API keys that may trade or withdraw
+	ErrNotReadOnly = errors.New("exchange API key is not read-only")
+	// ErrConnectorUnavailable is returned when no connector or credential key is configured
+	ErrConnectorUnavailable = errors.New("exchange connector unavailable")
+)
+
+// Credentials authenticate read-only API requests
+type Credentials struct {
+	APIKey    string `json:"api_key"`
+	APISecret string `json:"api_secret"`
+}
+
+// Validate checks that both parts are present
+func (c Credentials) Validate() error {
+	if strings.TrimSpace(c.APIKey) == "" || strings.TrimSpace(c.APISecret) == "" {
+		return fmt.Errorf("%w: api key and secret are required", ErrInvalidConnection)
+	}
+	return nil
+}
+
+// Hint returns the last characters of the API key, for listings
+func (c Credentials) Hint() string {
+	const visible = 4
+	if len(c.APIKey) <= visible {
+		return c.APIKey
+	}
+	return c.APIKey[len(c.APIKey)-visible:]
+}
+
+// Cursor records how far trade history has been synced, e.g. the last trade ID per symbol
+type Cursor map[string]int64
+
+// Connection links a portfolio to an exchange account through read-only API credentials.
+// A portfolio has at most one connection per exchange, whose balances its records of
+// that exchange are reconciled with.
+type Connection struct {
+	ID          string
+	PortfolioID string
+	Exchange    Exchange
+	Label       string
+	KeyHint     string
+	Credentials string // encrypted Credentials, see CredentialCipher
+	Cursor      Cursor
+
+	LastSyncedAt *time.Time
+	LastError    string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// NewConnection creates a connection with a fresh ID; encrypted holds the sealed credentials
+func NewConnection(portfolioID string, e Exchange, label, keyHint, encrypted string) *Connection {
+	now := time.Now().UTC()
+	return &Connection{
+		ID:          uuid.New().String(),
+		PortfolioID: portfolioID,
+		Exchange:    e,
+		Label:       strings.TrimSpace(label),
+		KeyHint:     keyHint,
+		Credentials: encrypted,
+		Cursor:      Cursor{},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// Balance is the holding of one asset on an exchange account, scaled by AmountDecimals
+type Balance struct {
+	Asset  string
+	Free   *big.Int
+	Locked *big.Int
+}
+
+// Total returns the free and locked amounts together
+func (b *Balance) Total() *big.Int {
+	total := new(big.Int)
+	if b.Free != nil {
+		total.Add(total, b.Free)
+	}
+	if b.Locked != nil {
+		total.Add(total, b.Locked)
+	}
+	return total
+}
+
+// Connector reads an exchange account through its API. Implementations only issue
+// read-only requests.
+type Connector interface {
+	// VerifyReadOnly fails with ErrNotReadOnly when the key may trade or withdraw
+	VerifyReadOnly(ctx context.Context, creds Credentials) error
+	// Balances returns the account's non-zero balances
+	Balances(ctx context.Context, creds Credentials) ([]*Balance, error)
+	// Trades returns the trades after the cursor for markets of the given assets, as
+	// records, together with the advanced cursor
+	Trades(ctx context.Context, creds Credentials, assets []string, cursor Cursor) ([]*Record, Cursor, error)
+}
+
+// CredentialCipher seals credentials at rest
+type CredentialCipher interface {
+	Encrypt(plaintext []byte) (string, error)
+	Decrypt(ciphertext string) ([]byte, error)
+}
+
+// SyncResult summarizes a connection sync
+type SyncResult struct {
+	Balances    int
+	Trades      int // new trade records
+	Adjustments int // records added to match the live balances
+}
+
+// ConnectionRepository stores exchange connections
+type ConnectionRepository interface {
+	CreateConnection(ctx context.Context, c *Connection) error
+	GetConnection(ctx context.Context, portfolioID, id string) (*Connection, error)
+	ListConnections(ctx context.Context, portfolioID string) ([]*Connection, error)
+	// ListAllConnections returns the connections of every portfolio, for background sync
+	ListAllConnections(ctx context.Context) ([]*Connection, error)
+	// UpdateSyncState stores the cursor, last sync time and last error
+	UpdateSyncState(ctx context.Context, c *Connection) error
+	DeleteConnection(ctx context.Context, portfolioID, id string) error
+}
@@ -0,0 +1,204 @@
+package exchange
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidStatement    = errors.New("invalid exchange statement")
+	ErrUnsupportedExchange = errors.New("unsupported exchange")
+	ErrInvalidFilter       = errors.New("invalid statement filter")
+)
+
+// AmountDecimals is the fixed scale of record amounts; exchanges report quantities as
+// decimals of varying precision, so they are normalized before the asset is known
+const AmountDecimals = 18
+
+// Exchange identifies the source a statement was exported from
+type Exchange string
+
+const (
+	Binance  Exchange = "binance"
+	Coinbase Exchange = "coinbase"
+	Kraken   Exchange = "kraken"
+	// Generic statements are read with a caller-supplied column Mapping
+	Generic Exchange = "generic"
+)
+
+// ParseExchange validates an exchange name
+func ParseExchange(s string) (Exchange, error) {
+	switch e := Exchange(strings.ToLower(strings.TrimSpace(s))); e {
+	case Binance, Coinbase, Kraken, Generic:
+		return e, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnsupportedExchange, s)
+}
+
+// Kind classifies a normalized statement row
+type Kind string
+
+const (
+	// KindTrade is one leg of a trade; the bought asset is positive, the sold one negative
+	KindTrade Kind = "trade"
+	// KindDeposit moves funds onto the exchange
+	KindDeposit Kind = "deposit"
+	// KindWithdrawal moves funds off the exchange
+	KindWithdrawal Kind = "withdrawal"
+	// KindFee is charged by the exchange, for trades and withdrawals alike
+	KindFee Kind = "fee"
+)
+
+// ParseKind validates a record kind
+func ParseKind(s string) (Kind, error) {
+	switch k := Kind(strings.ToLower(strings.TrimSpace(s))); k {
+	case KindTrade, KindDeposit, KindWithdrawal, KindFee:
+		return k, nil
+	}
+	return "", fmt.Errorf("%w: unknown kind %q", ErrInvalidStatement, s)
+}
+
+// Record is one normalized balance change on an exchange account. A trade is stored as
+// one record per leg sharing a TradeID, plus a fee record when the exchange charged one.
+type Record struct {
+	ID          string
+	PortfolioID string
+	Exchange    Exchange
+	ExternalID  string // unique per exchange and portfolio; re-imports of the same row are skipped
+	TradeID     string // groups the legs and fee of a trade, empty otherwise
+	Kind        Kind
+	Asset       string   // upper-case ticker as reported by the exchange, e.g. BTC
+	Amount      *big.Int // signed change scaled by AmountDecimals
+
+	// Optional unit price of the asset at OccurredAt, scaled by price.CurrencyDecimal
+	Price    *big.Int
+	Currency string
+
+	Note       string
+	OccurredAt time.Time
+	ImportedAt time.Time
+}
+
+// Validate checks that the record describes a balance change
+func (r *Record) Validate() error {
+	switch {
+	case r.ExternalID == "":
+		return fmt.Errorf("%w: external id is required", ErrInvalidStatement)
+	case r.Asset == "":
+		return fmt.Errorf("%w: asset is required", ErrInvalidStatement)
+	case r.Amount == nil || r.Amount.Sign() == 0:
+		return fmt.Errorf("%w: %s amount must not be zero", ErrInvalidStatement, r.Asset)
+	case r.OccurredAt.IsZero():
+		return fmt.Errorf("%w: time is required", ErrInvalidStatement)
+	case r.Price != nil && (r.Price.Sign() < 0 || r.Currency == ""):
+		return fmt.Errorf("%w: price must be positive and have a currency", ErrInvalidStatement)
+	}
+	if _, err := ParseKind(string(r.Kind)); err != nil {
+		return err
+	}
+	if (r.Kind == KindDeposit && r.Amount.Sign() < 0) || ((r.Kind == KindWithdrawal || r.Kind == KindFee) && r.Amount.Sign() > 0) {
+		return fmt.Errorf("%w: %s amount has the wrong sign", ErrInvalidStatement, r.Kind)
+	}
+	return nil
+}
+
+// Assign links parsed records to the portfolio and gives them IDs
+func Assign(records []*Record, portfolioID string, now time.Time) {
+	for _, r := range records {
+		r.ID = uuid.New().String()
+		r.PortfolioID = portfolioID
+		r.ImportedAt = now.UTC()
+		r.OccurredAt = r.OccurredAt.UTC()
+	}
+}
+
+// RowID derives a stable external ID from the raw fields of a statement row, for
+// formats that do not carry a transaction ID of their own
+func RowID(fields ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\x1f")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Mapping describes a generic CSV statement by naming its columns. Time, Kind, Asset
+// and Amount are required; the rest are optional.
+type Mapping struct {
+	Time     string `json:"time"`
+	Kind     string `json:"kind"`
+	Asset    string `json:"asset"`
+	Amount   string `json:"amount"`
+	Fee      string `json:"fee,omitempty"`
+	FeeAsset string `json:"fee_asset,omitempty"` // defaults to the row's asset
+	Price    string `json:"price,omitempty"`
+	Currency string `json:"currency,omitempty"`
+	ID       string `json:"id,omitempty"`
+	Note     string `json:"note,omitempty"`
+
+	// TimeLayout is a Go time layout; RFC 3339 and "2006-01-02 15:04:05" are tried when empty
+	TimeLayout string `json:"time_layout,omitempty"`
+	// Kinds maps values of the kind column to kinds, e.g. {"BUY": "trade"}. Values that
+	// are not mapped must name a kind.
+	Kinds map[string]Kind `json:"kinds,omitempty"`
+}
+
+// Validate checks that the required columns are named
+func (m *Mapping) Validate() error {
+	if m == nil {
+		return fmt.Errorf("%w: generic statements need a column mapping", ErrInvalidStatement)
+	}
+	if m.Time == "" || m.Kind == "" || m.Asset == "" || m.Amount == "" {
+		return fmt.Errorf("%w: mapping must name the time, kind, asset and amount columns", ErrInvalidStatement)
+	}
+	for value, kind := range m.Kinds {
+		if _, err := ParseKind(string(kind)); err != nil {
+			return fmt.Errorf("%w: mapping of %q", err, value)
+		}
+	}
+	return nil
+}
+
+// Parser reads a statement export into records that are not yet linked to a portfolio
+type Parser interface {
+	Parse(r io.Reader) ([]*Record, error)
+}
+
+// ImportResult summarizes a statement import
+type ImportResult struct {
+	Exchange   Exchange
+	Parsed     int
+	Imported   int
+	Duplicates int // rows already imported earlier
+}
+
+// Filter selects records. From is inclusive and To is exclusive.
+type Filter struct {
+	Exchange Exchange
+	Kind     Kind
+	Asset    string
+	From     *time.Time
+	To       *time.Time
+}
+
+// Validate checks the time range
+func (f *Filter) Validate() error {
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		return fmt.Errorf("%w: from must be before to", ErrInvalidFilter)
+	}
+	return nil
+}
+
+// Repository stores imported records
+type Repository interface {
+	// SaveRecords stores the records, skipping those whose external ID was already
+	// imported for the portfolio and exchange, and returns the number stored
+	SaveRecords(ctx context.Context, records []*Record) (int, error)
+	ListRecords(ctx context.Context, portfolioID string, filter Filter) ([]*Record, error)
+}
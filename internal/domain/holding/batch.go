@@ -0,0 +1,95 @@
+package holding
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"testtask/internal/domain/token"
+)
+
+var (
+	ErrInvalidBatch = errors.New("invalid batch")
+	// ErrBatchFailed means at least one operation failed and the batch was rolled back
+	ErrBatchFailed = errors.New("batch failed")
+)
+
+// MaxBatchSize limits the operations of one batch
+const MaxBatchSize = 500
+
+// BatchOp is the kind of a batch operation
+type BatchOp string
+
+const (
+	// BatchAdd deposits the amount into the token's holding, creating it on first use
+	BatchAdd BatchOp = "add"
+	// BatchSet sets the amount of the token's holding, creating it on first use
+	BatchSet BatchOp = "set"
+	// BatchUpdate sets the amount of the holding with the given ID
+	BatchUpdate BatchOp = "update"
+	// BatchDelete closes and removes the holding with the given ID
+	BatchDelete BatchOp = "delete"
+)
+
+// BatchOperation is one change of a batch. Add and set address a holding by token,
+// update and delete by holding ID. A non-zero Version must match the stored holding.
+type BatchOperation struct {
+	Op           BatchOp
+	HoldingID    string
+	TokenAddress string
+	Token        *token.Token // resolved from TokenAddress; nil for unsupported tokens
+	Amount       *big.Int
+	Version      int64
+}
+
+// Validate checks that the operation carries the fields its kind requires
+func (o *BatchOperation) Validate() error {
+	switch o.Op {
+	case BatchAdd, BatchSet:
+		if o.TokenAddress == "" {
+			return fmt.Errorf("%w: token_address is required", ErrInvalidBatch)
+		}
+		if o.Token == nil {
+			return fmt.Errorf("%w: token %s is not supported", ErrInvalidBatch, o.TokenAddress)
+		}
+	case BatchUpdate, BatchDelete:
+		if o.HoldingID == "" {
+			return fmt.Errorf("%w: holding_id is required", ErrInvalidBatch)
+		}
+	default:
+		return fmt.Errorf("%w: unknown op %q", ErrInvalidBatch, o.Op)
+	}
+
+	switch {
+	case o.Op == BatchDelete:
+	case o.Amount == nil:
+		return fmt.Errorf("%w: amount is required", ErrInvalidBatch)
+	case o.Op == BatchAdd && o.Amount.Sign() <= 0:
+		return fmt.Errorf("%w: amount must be positive", ErrInvalidBatch)
+	case o.Amount.Sign() < 0:
+		return fmt.Errorf("%w: amount must not be negative", ErrInvalidBatch)
+	}
+	return nil
+}
+
+// BatchStatus is the outcome of one operation
+type BatchStatus string
+
+const (
+	// BatchApplied operations were committed, or would be in a dry run
+	BatchApplied BatchStatus = "applied"
+	// BatchFailed operations caused the batch to be rolled back
+	BatchFailed BatchStatus = "failed"
+	// BatchRolledBack operations succeeded but were undone because another one failed
+	BatchRolledBack BatchStatus = "rolled_back"
+)
+
+// BatchResult reports the outcome of the operation at Index. Holding is the holding
+// after the operation; it is nil for deletes and failed operations.
+type BatchResult struct {
+	Index   int
+	Op      BatchOp
+	Status  BatchStatus
+	Holding *Holding
+	Err     error
+}
@@ -0,0 +1,41 @@
+package holding
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"testtask/internal/domain/token"
+)
+
+func TestBatchOperation_Validate(t *testing.T) {
+	eth := &token.Token{ID: "ethereum", Symbol: "ETH", Address: "0xeth"}
+
+	tests := []struct {
+		name    string
+		op      BatchOperation
+		wantErr bool
+	}{
+		{name: "add", op: BatchOperation{Op: BatchAdd, TokenAddress: "0xeth", Token: eth, Amount: big.NewInt(1)}},
+		{name: "add zero", op: BatchOperation{Op: BatchAdd, TokenAddress: "0xeth", Token: eth, Amount: big.NewInt(0)}, wantErr: true},
+		{name: "add unsupported token", op: BatchOperation{Op: BatchAdd, TokenAddress: "0xunknown", Amount: big.NewInt(1)}, wantErr: true},
+		{name: "set zero", op: BatchOperation{Op: BatchSet, TokenAddress: "0xeth", Token: eth, Amount: big.NewInt(0)}},
+		{name: "set negative", op: BatchOperation{Op: BatchSet, TokenAddress: "0xeth", Token: eth, Amount: big.NewInt(-1)}, wantErr: true},
+		{name: "update without holding", op: BatchOperation{Op: BatchUpdate, Amount: big.NewInt(1)}, wantErr: true},
+		{name: "update without amount", op: BatchOperation{Op: BatchUpdate, HoldingID: "h1"}, wantErr: true},
+		{name: "delete", op: BatchOperation{Op: BatchDelete, HoldingID: "h1"}},
+		{name: "unknown op", op: BatchOperation{Op: "move", HoldingID: "h1"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.op.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidBatch) {
+				t.Errorf("Validate() error = %v, want ErrInvalidBatch", err)
+			}
+		})
+	}
+}
@@ -10,7 +10,11 @@ import (
 	"github.com/google/uuid"
 )
 
-var ErrHoldingNotFound = errors.New("holdingRepo.Holding not found")
+var (
+	ErrHoldingNotFound = errors.New("holdingRepo.Holding not found")
+	// ErrVersionConflict means the holding was changed since it was read
+	ErrVersionConflict = errors.New("holding was modified concurrently")
+)
 
 type Repository interface {
 	GetHolding(ctx context.Context, portfolioID string, holdingID string) (*Holding, error)
@@ -20,6 +24,12 @@ type Repository interface {
 	ListByPortfolioID(ctx context.Context, portfolioID string) ([]*Holding, error)
 }
 
+// UnitOfWork runs fn in one transaction. Repository calls made with the context passed to
+// fn take part in the transaction; an error returned by fn rolls it back.
+type UnitOfWork interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 type BulkFetcher interface {
 }
 
@@ -30,6 +40,10 @@ type Holding struct {
 	Token       *token.Token
 	Amount      *big.Int
 
+	// Version is incremented on every write; a write based on an older version fails
+	// with ErrVersionConflict. Zero means the holding has not been stored yet.
+	Version int64
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
@@ -0,0 +1,199 @@
+package holding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"testtask/internal/domain/token"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidEntry        = errors.New("invalid ledger entry")
+	ErrInsufficientBalance = errors.New("insufficient balance")
+)
+
+// EntryType classifies a change of a manual position
+type EntryType string
+
+const (
+	// EntryDeposit adds funds to the position
+	EntryDeposit EntryType = "deposit"
+	// EntryWithdrawal removes funds from the position
+	EntryWithdrawal EntryType = "withdrawal"
+	// EntryAdjustment corrects the position in either direction, e.g. after a recount
+	EntryAdjustment EntryType = "adjustment"
+	// EntryTransfer moves funds to or from a counterparty such as another portfolio
+	EntryTransfer EntryType = "transfer"
+)
+
+// ParseEntryType validates an entry type
+func ParseEntryType(s string) (EntryType, error) {
+	switch t := EntryType(s); t {
+	case EntryDeposit, EntryWithdrawal, EntryAdjustment, EntryTransfer:
+		return t, nil
+	}
+	return "", fmt.Errorf("%w: unknown entry type %q", ErrInvalidEntry, s)
+}
+
+// LedgerEntry is an immutable change of a holding. Quantity is the signed change in
+// token base units; a holding's amount is the sum of the quantities of its entries.
+type LedgerEntry struct {
+	ID          string
+	PortfolioID string
+	HoldingID   string
+	Token       *token.Token
+	Type        EntryType
+	Quantity    *big.Int
+
+	// Optional unit price at OccurredAt, scaled by price.CurrencyDecimal
+	Price    *big.Int
+	Currency string
+
+	Note         string
+	Counterparty string // transfers only, e.g. the other portfolio or an exchange account
+	OccurredAt   time.Time
+	CreatedAt    time.Time
+}
+
+// NewLedgerEntry creates an entry for the holding. A zero occurredAt means now.
+func NewLedgerEntry(h *Holding, entryType EntryType, quantity *big.Int, occurredAt time.Time) *LedgerEntry {
+	now := time.Now().UTC()
+	if occurredAt.IsZero() {
+		occurredAt = now
+	}
+	return &LedgerEntry{
+		ID:          uuid.New().String(),
+		PortfolioID: h.PortfolioID,
+		HoldingID:   h.ID,
+		Token:       h.Token,
+		Type:        entryType,
+		Quantity:    new(big.Int).Set(quantity),
+		OccurredAt:  occurredAt.UTC(),
+		CreatedAt:   now,
+	}
+}
+
+// Validate checks that the sign of the quantity matches the entry type. Deposits are
+// positive, withdrawals negative, adjustments and transfers may go either way.
+func (e *LedgerEntry) Validate() error {
+	if e.Quantity == nil || e.Quantity.Sign() == 0 {
+		return fmt.Errorf("%w: quantity must not be zero", ErrInvalidEntry)
+	}
+	switch e.Type {
+	case EntryDeposit:
+		if e.Quantity.Sign() < 0 {
+			return fmt.Errorf("%w: deposit quantity must be positive", ErrInvalidEntry)
+		}
+	case EntryWithdrawal:
+		if e.Quantity.Sign() > 0 {
+			return fmt.Errorf("%w: withdrawal quantity must be negative", ErrInvalidEntry)
+		}
+	case EntryAdjustment, EntryTransfer:
+	default:
+		return fmt.Errorf("%w: unknown entry type %q", ErrInvalidEntry, e.Type)
+	}
+	if e.Counterparty != "" && e.Type != EntryTransfer {
+		return fmt.Errorf("%w: counterparty is only allowed on transfers", ErrInvalidEntry)
+	}
+	if e.Price != nil {
+		if e.Price.Sign() < 0 {
+			return fmt.Errorf("%w: price must not be negative", ErrInvalidEntry)
+		}
+		if e.Currency == "" {
+			return fmt.Errorf("%w: price requires a currency", ErrInvalidEntry)
+		}
+	}
+	if e.OccurredAt.After(time.Now().Add(time.Minute)) {
+		return fmt.Errorf("%w: timestamp must not be in the future", ErrInvalidEntry)
+	}
+	return nil
+}
+
+// Balance returns the sum of the quantities of entries that occurred at or before at;
+// a nil at includes all entries
+func Balance(entries []*LedgerEntry, at *time.Time) *big.Int {
+	total := new(big.Int)
+	for _, e := range entries {
+		if at != nil && e.OccurredAt.After(*at) {
+			continue
+		}
+		total.Add(total, e.Quantity)
+	}
+	return total
+}
+
+// CheckBalance fails when the running balance of the entries, in time order, ever
+// drops below zero. Backdated withdrawals are checked against the balance at that time.
+func CheckBalance(entries []*LedgerEntry) error {
+	sorted := append([]*LedgerEntry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].OccurredAt.Before(sorted[j].OccurredAt)
+	})
+
+	running := new(big.Int)
+	for _, e := range sorted {
+		running.Add(running, e.Quantity)
+		if running.Sign() < 0 {
+			return fmt.Errorf("%w: balance would be %s at %s", ErrInsufficientBalance, running, e.OccurredAt.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+// Positions projects the entries onto holdings as of at, one per token. Tokens with a
+// zero balance are omitted. Holding IDs are taken from the latest entry of the token.
+func Positions(portfolioID string, entries []*LedgerEntry, at *time.Time) []*Holding {
+	var (
+		order     []string
+		byAddress = make(map[string]*Holding)
+	)
+	for _, e := range entries {
+		if at != nil && e.OccurredAt.After(*at) {
+			continue
+		}
+		h, ok := byAddress[e.Token.Address]
+		if !ok {
+			h = &Holding{PortfolioID: portfolioID, Token: e.Token, Amount: new(big.Int), CreatedAt: e.OccurredAt}
+			byAddress[e.Token.Address] = h
+			order = append(order, e.Token.Address)
+		}
+		h.Amount.Add(h.Amount, e.Quantity)
+		if !e.OccurredAt.Before(h.UpdatedAt) {
+			h.ID = e.HoldingID
+			h.UpdatedAt = e.OccurredAt
+		}
+		if e.OccurredAt.Before(h.CreatedAt) {
+			h.CreatedAt = e.OccurredAt
+		}
+	}
+
+	positions := make([]*Holding, 0, len(order))
+	for _, address := range order {
+		if h := byAddress[address]; h.Amount.Sign() != 0 {
+			positions = append(positions, h)
+		}
+	}
+	return positions
+}
+
+// LedgerFilter selects ledger entries of a portfolio. From is inclusive and To is inclusive,
+// so To can be used as a point in time.
+type LedgerFilter struct {
+	HoldingID    string
+	TokenAddress string
+	From         *time.Time
+	To           *time.Time
+}
+
+// LedgerRepository stores ledger entries. AppendEntry writes the entry and the holding
+// projection with its new amount in one transaction, creating the holding if needed.
+type LedgerRepository interface {
+	AppendEntry(ctx context.Context, portfolioID string, h *Holding, e *LedgerEntry) error
+	ListEntries(ctx context.Context, portfolioID string, filter LedgerFilter) ([]*LedgerEntry, error)
+}
@@ -0,0 +1,142 @@
+package holding
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"testtask/internal/domain/token"
+)
+
+func entry(h *Holding, entryType EntryType, quantity int64, at time.Time) *LedgerEntry {
+	return NewLedgerEntry(h, entryType, big.NewInt(quantity), at)
+}
+
+func TestLedgerEntry_Validate(t *testing.T) {
+	h := &Holding{ID: "h1", PortfolioID: "p1", Token: &token.Token{Address: "0xa"}}
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		entry   func() *LedgerEntry
+		wantErr error
+	}{
+		{name: "deposit", entry: func() *LedgerEntry { return entry(h, EntryDeposit, 5, now) }},
+		{name: "negative deposit", entry: func() *LedgerEntry { return entry(h, EntryDeposit, -5, now) }, wantErr: ErrInvalidEntry},
+		{name: "positive withdrawal", entry: func() *LedgerEntry { return entry(h, EntryWithdrawal, 5, now) }, wantErr: ErrInvalidEntry},
+		{name: "negative adjustment", entry: func() *LedgerEntry { return entry(h, EntryAdjustment, -5, now) }},
+		{name: "zero quantity", entry: func() *LedgerEntry { return entry(h, EntryTransfer, 0, now) }, wantErr: ErrInvalidEntry},
+		{
+			name: "transfer with counterparty",
+			entry: func() *LedgerEntry {
+				e := entry(h, EntryTransfer, -5, now)
+				e.Counterparty = "p2"
+				return e
+			},
+		},
+		{
+			name: "counterparty on deposit",
+			entry: func() *LedgerEntry {
+				e := entry(h, EntryDeposit, 5, now)
+				e.Counterparty = "p2"
+				return e
+			},
+			wantErr: ErrInvalidEntry,
+		},
+		{
+			name: "price without currency",
+			entry: func() *LedgerEntry {
+				e := entry(h, EntryDeposit, 5, now)
+				e.Price = big.NewInt(100)
+				return e
+			},
+			wantErr: ErrInvalidEntry,
+		},
+		{name: "future timestamp", entry: func() *LedgerEntry { return entry(h, EntryDeposit, 5, now.Add(time.Hour)) }, wantErr: ErrInvalidEntry},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.entry().Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckBalance(t *testing.T) {
+	h := &Holding{ID: "h1", PortfolioID: "p1", Token: &token.Token{Address: "0xa"}}
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name    string
+		entries []*LedgerEntry
+		wantErr error
+	}{
+		{
+			name:    "withdrawal after deposit",
+			entries: []*LedgerEntry{entry(h, EntryDeposit, 10, day(1)), entry(h, EntryWithdrawal, -10, day(2))},
+		},
+		{
+			name:    "backdated withdrawal before deposit",
+			entries: []*LedgerEntry{entry(h, EntryDeposit, 10, day(2)), entry(h, EntryWithdrawal, -5, day(1))},
+			wantErr: ErrInsufficientBalance,
+		},
+		{
+			name:    "overdraw",
+			entries: []*LedgerEntry{entry(h, EntryDeposit, 10, day(1)), entry(h, EntryAdjustment, -11, day(3))},
+			wantErr: ErrInsufficientBalance,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckBalance(tt.entries); !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckBalance() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPositions(t *testing.T) {
+	eth := &Holding{ID: "h1", PortfolioID: "p1", Token: &token.Token{Address: "0xeth"}}
+	usdc := &Holding{ID: "h2", PortfolioID: "p1", Token: &token.Token{Address: "0xusdc"}}
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	entries := []*LedgerEntry{
+		entry(eth, EntryDeposit, 10, day(1)),
+		entry(usdc, EntryDeposit, 500, day(1)),
+		entry(eth, EntryWithdrawal, -4, day(5)),
+		entry(usdc, EntryAdjustment, -500, day(10)),
+	}
+
+	tests := []struct {
+		name string
+		at   *time.Time
+		want map[string]int64
+	}{
+		{name: "before any entry", at: ptr(day(0)), want: map[string]int64{}},
+		{name: "march 1st", at: ptr(day(1)), want: map[string]int64{"0xeth": 10, "0xusdc": 500}},
+		{name: "after withdrawal", at: ptr(day(6)), want: map[string]int64{"0xeth": 6, "0xusdc": 500}},
+		{name: "current omits closed positions", want: map[string]int64{"0xeth": 6}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Positions("p1", entries, tt.at)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Positions() returned %d holdings, want %d", len(got), len(tt.want))
+			}
+			for _, h := range got {
+				if want, ok := tt.want[h.Token.Address]; !ok || h.Amount.Int64() != want {
+					t.Errorf("Positions() %s = %s, want %d", h.Token.Address, h.Amount, want)
+				}
+			}
+		})
+	}
+}
+
+func ptr(t time.Time) *time.Time {
+	return &t
+}
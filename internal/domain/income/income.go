@@ -0,0 +1,195 @@
+package income
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"testtask/internal/domain/transaction"
+)
+
+var (
+	ErrInvalidRules           = errors.New("invalid income rules")
+	ErrInvalidClassification  = errors.New("invalid income classification")
+	ErrClassificationNotFound = errors.New("income classification not found")
+)
+
+// NotIncome is set manually on a transfer a rule wrongly detects as income
+const NotIncome transaction.IncomeCategory = "none"
+
+// ParseCategory validates a manually set category; NotIncome is accepted
+func ParseCategory(s string) (transaction.IncomeCategory, error) {
+	c := transaction.IncomeCategory(strings.ToLower(strings.TrimSpace(s)))
+	if c != NotIncome && !c.Valid() {
+		return "", fmt.Errorf("%w: unknown category %q", ErrInvalidClassification, s)
+	}
+	return c, nil
+}
+
+// Distributor is a contract or address whose payouts are income, such as a staking
+// rewards distributor, an airdrop claim contract or a mining pool
+type Distributor struct {
+	Address  string
+	Name     string
+	Category transaction.IncomeCategory
+}
+
+// RebasingToken is a token whose balance grows without transfers, such as liquid staking
+// or lending deposit tokens; the growth is income
+type RebasingToken struct {
+	Address  string
+	Symbol   string
+	Decimals uint8
+	Category transaction.IncomeCategory
+}
+
+// RuleSet detects income from known distributors and rebasing tokens
+type RuleSet struct {
+	distributors map[string]*Distributor   // lowercase address -> distributor
+	rebasing     map[string]*RebasingToken // lowercase address -> token
+}
+
+// NewRuleSet validates the rules and indexes them by lowercase address
+func NewRuleSet(distributors []*Distributor, rebasing []*RebasingToken) (*RuleSet, error) {
+	set := &RuleSet{
+		distributors: make(map[string]*Distributor, len(distributors)),
+		rebasing:     make(map[string]*RebasingToken, len(rebasing)),
+	}
+	for _, d := range distributors {
+		d.Address = strings.ToLower(strings.TrimSpace(d.Address))
+		if d.Address == "" || !d.Category.Valid() {
+			return nil, fmt.Errorf("%w: distributor %q needs an address and a category", ErrInvalidRules, d.Name)
+		}
+		if _, ok := set.distributors[d.Address]; ok {
+			return nil, fmt.Errorf("%w: duplicate distributor %s", ErrInvalidRules, d.Address)
+		}
+		set.distributors[d.Address] = d
+	}
+	for _, t := range rebasing {
+		t.Address = strings.ToLower(strings.TrimSpace(t.Address))
+		if t.Address == "" || !t.Category.Valid() {
+			return nil, fmt.Errorf("%w: rebasing token %q needs an address and a category", ErrInvalidRules, t.Symbol)
+		}
+		if _, ok := set.rebasing[t.Address]; ok {
+			return nil, fmt.Errorf("%w: duplicate rebasing token %s", ErrInvalidRules, t.Address)
+		}
+		set.rebasing[t.Address] = t
+	}
+	return set, nil
+}
+
+// Detect returns the income category of an incoming transfer sent by a known distributor
+func (s *RuleSet) Detect(tx *transaction.Transaction) (transaction.IncomeCategory, bool) {
+	if s == nil || tx == nil || tx.Direction != transaction.TransactionDirectionIn {
+		return "", false
+	}
+	d, ok := s.distributors[strings.ToLower(tx.From)]
+	if !ok {
+		return "", false
+	}
+	return d.Category, true
+}
+
+// RebasingTokens returns the rebasing tokens sorted by address
+func (s *RuleSet) RebasingTokens() []*RebasingToken {
+	if s == nil {
+		return nil
+	}
+	tokens := make([]*RebasingToken, 0, len(s.rebasing))
+	for _, t := range s.rebasing {
+		tokens = append(tokens, t)
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].Address < tokens[j].Address })
+	return tokens
+}
+
+// Classification is an income category set by hand on a transaction of a portfolio. It
+// takes precedence over detection; NotIncome marks a detected transfer as not income.
+type Classification struct {
+	PortfolioID   string
+	TransactionID string
+	Category      transaction.IncomeCategory
+	Note          string
+	UpdatedAt     time.Time
+}
+
+// Apply sets the income category of each transaction: rules first, then the portfolio's
+// classifications by transaction ID. Only incoming transfers are income, and never those
+// from another wallet of the owner.
+func Apply(txs []*transaction.Transaction, rules *RuleSet, classifications []*Classification) {
+	manual := make(map[string]transaction.IncomeCategory, len(classifications))
+	for _, c := range classifications {
+		manual[c.TransactionID] = c.Category
+	}
+	for _, tx := range txs {
+		if tx == nil {
+			continue
+		}
+		tx.Income = ""
+		if tx.Direction != transaction.TransactionDirectionIn || tx.Type == transaction.TransactionTypeInternalTransfer {
+			continue
+		}
+		if category, ok := rules.Detect(tx); ok {
+			tx.Income = category
+		}
+		if category, ok := manual[tx.ID]; ok {
+			tx.Income = category
+			if category == NotIncome {
+				tx.Income = ""
+			}
+		}
+	}
+}
+
+// Accrual is income observed as growth of a rebasing token balance beyond what transfers
+// and earlier accruals explain. It is dated when observed.
+type Accrual struct {
+	ID           string
+	PortfolioID  string
+	TokenAddress string
+	Symbol       string
+	Decimals     uint8
+	Quantity     *big.Int // in base units
+	Category     transaction.IncomeCategory
+	ObservedAt   time.Time
+}
+
+// RebaseDelta returns the part of balance not explained by the net transferred amount and
+// the quantity already accrued, or nil when there is none
+func RebaseDelta(balance, transferred, accrued *big.Int) *big.Int {
+	if balance == nil {
+		return nil
+	}
+	delta := new(big.Int).Set(balance)
+	if transferred != nil {
+		delta.Sub(delta, transferred)
+	}
+	if accrued != nil {
+		delta.Sub(delta, accrued)
+	}
+	if delta.Sign() <= 0 {
+		return nil
+	}
+	return delta
+}
+
+// Repository stores classifications and accruals per portfolio
+type Repository interface {
+	ListClassifications(ctx context.Context, portfolioID string) ([]*Classification, error)
+	// SetClassification creates or replaces the classification of a transaction
+	SetClassification(ctx context.Context, c *Classification) error
+	DeleteClassification(ctx context.Context, portfolioID, transactionID string) error
+	ListAccruals(ctx context.Context, portfolioID string) ([]*Accrual, error)
+	SaveAccrual(ctx context.Context, a *Accrual) error
+}
+
+// Source supplies the income classification of a portfolio's transactions
+type Source interface {
+	// Classify sets Income on the transactions; an empty portfolioID applies the rules only
+	Classify(ctx context.Context, portfolioID string, txs []*transaction.Transaction) error
+	Accruals(ctx context.Context, portfolioID string) ([]*Accrual, error)
+}
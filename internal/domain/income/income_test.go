@@ -0,0 +1,91 @@
+package income
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"testtask/internal/domain/transaction"
+)
+
+const (
+	distributorAddress = "0x090d4613473dee047c3f2706764f49e0821d256e"
+	stethAddress       = "0xae7ab96520de3a18e5e111b5eaab095312d7fe84"
+)
+
+func TestApply(t *testing.T) {
+	rules, err := NewRuleSet(
+		[]*Distributor{{Address: "0x090D4613473dEE047c3f2706764f49E0821D256e", Name: "Uniswap airdrop", Category: transaction.IncomeAirdrop}},
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("NewRuleSet() error = %v", err)
+	}
+
+	in := transaction.TransactionDirectionIn
+	txs := []*transaction.Transaction{
+		{ID: "detected", From: distributorAddress, Direction: in},
+		{ID: "overridden", From: distributorAddress, Direction: in},
+		{ID: "manual", From: "0xemployer", Direction: in},
+		{ID: "plain", From: "0xfriend", Direction: in},
+		{ID: "outgoing", From: distributorAddress, Direction: transaction.TransactionDirectionOut},
+		{ID: "internal", From: "0xcold", Direction: in, Type: transaction.TransactionTypeInternalTransfer},
+	}
+	Apply(txs, rules, []*Classification{
+		{TransactionID: "overridden", Category: NotIncome},
+		{TransactionID: "manual", Category: transaction.IncomeSalary},
+		{TransactionID: "outgoing", Category: transaction.IncomeSalary},
+		{TransactionID: "internal", Category: transaction.IncomeSalary},
+	})
+
+	want := []transaction.IncomeCategory{transaction.IncomeAirdrop, "", transaction.IncomeSalary, "", "", ""}
+	for i, tx := range txs {
+		if tx.Income != want[i] {
+			t.Errorf("%s: Income = %q, want %q", tx.ID, tx.Income, want[i])
+		}
+	}
+}
+
+func TestNewRuleSet_Invalid(t *testing.T) {
+	tests := []struct {
+		name         string
+		distributors []*Distributor
+		rebasing     []*RebasingToken
+	}{
+		{"unknown category", []*Distributor{{Address: distributorAddress, Category: "gift"}}, nil},
+		{"not income", []*Distributor{{Address: distributorAddress, Category: NotIncome}}, nil},
+		{"missing address", nil, []*RebasingToken{{Symbol: "stETH", Category: transaction.IncomeStakingReward}}},
+		{"duplicate", nil, []*RebasingToken{
+			{Address: stethAddress, Category: transaction.IncomeStakingReward},
+			{Address: "0xAE7AB96520DE3A18E5E111B5EAAB095312D7FE84", Category: transaction.IncomeInterest},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewRuleSet(tt.distributors, tt.rebasing); !errors.Is(err, ErrInvalidRules) {
+				t.Errorf("NewRuleSet() error = %v, want ErrInvalidRules", err)
+			}
+		})
+	}
+}
+
+func TestRebaseDelta(t *testing.T) {
+	tests := []struct {
+		name                          string
+		balance, transferred, accrued int64
+		want                          int64
+	}{
+		{"growth", 1050, 1000, 0, 50},
+		{"growth since last accrual", 1080, 1000, 50, 30},
+		{"no growth", 1050, 1000, 50, 0},
+		{"balance below transfers", 900, 1000, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RebaseDelta(big.NewInt(tt.balance), big.NewInt(tt.transferred), big.NewInt(tt.accrued))
+			if (tt.want == 0) != (got == nil) || (got != nil && got.Int64() != tt.want) {
+				t.Errorf("RebaseDelta() = %v, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package portfolio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAccessDenied       = errors.New("access denied")
+	ErrInvalidRole        = errors.New("invalid role")
+	ErrMemberNotFound     = errors.New("portfolio member not found")
+	ErrMemberExists       = errors.New("portfolio member already exists")
+	ErrShareTokenNotFound = errors.New("share token not found")
+	ErrInvalidShareToken  = errors.New("invalid share token")
+)
+
+// Role is the access level of a user on a portfolio. Each role includes the
+// permissions of the roles below it.
+type Role string
+
+const (
+	// RoleViewer reads the portfolio, its assets and price overrides
+	RoleViewer Role = "viewer"
+	// RoleEditor also changes metadata, holdings, price overrides and custom tokens
+	RoleEditor Role = "editor"
+	// RoleAdmin also archives the portfolio and manages members and share tokens
+	RoleAdmin Role = "admin"
+	// RoleOwner is held by the portfolio owner only and is the only role allowed to delete it
+	RoleOwner Role = "owner"
+)
+
+var roleRanks = map[Role]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+	RoleOwner:  4,
+}
+
+// ParseRole parses a role that can be granted to a member; the owner role cannot be granted
+func ParseRole(s string) (Role, error) {
+	role := Role(s)
+	if role == RoleOwner || roleRanks[role] == 0 {
+		return "", fmt.Errorf("%w: %q, must be one of viewer, editor, admin", ErrInvalidRole, s)
+	}
+	return role, nil
+}
+
+// Allows reports whether the role includes the permissions of required
+func (r Role) Allows(required Role) bool {
+	rank, ok := roleRanks[r]
+	return ok && rank >= roleRanks[required]
+}
+
+// Member grants a user a role on a portfolio owned by someone else
+type Member struct {
+	PortfolioID string
+	UserID      string
+	Email       string
+	Role        Role
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Share token lifetimes
+const (
+	DefaultShareTokenTTL = 30 * 24 * time.Hour
+	MaxShareTokenTTL     = 365 * 24 * time.Hour
+)
+
+// ShareToken is a revocable, expiring read-only link to a portfolio. Only the
+// SHA-256 hash of the token is stored; Prefix identifies it in listings.
+type ShareToken struct {
+	ID          string
+	PortfolioID string
+	OwnerID     string // owner of the shared portfolio, set when the token is resolved
+	Name        string
+	Prefix      string
+	Hash        string
+	CreatedBy   string
+	ExpiresAt   time.Time
+	RevokedAt   *time.Time
+	LastUsedAt  *time.Time
+	CreatedAt   time.Time
+}
+
+func NewShareToken(portfolioID, name, createdBy string, expiresAt time.Time) *ShareToken {
+	return &ShareToken{
+		ID:          uuid.New().String(),
+		PortfolioID: portfolioID,
+		Name:        name,
+		CreatedBy:   createdBy,
+		ExpiresAt:   expiresAt.UTC(),
+		CreatedAt:   time.Now().UTC(),
+	}
+}
+
+// Active reports whether the token grants access at the given time
+func (t *ShareToken) Active(at time.Time) bool {
+	return t.RevokedAt == nil && at.Before(t.ExpiresAt)
+}
+
+type AccessRepository interface {
+	// Role returns the role of the user on the portfolio; ErrPortfolioNotFound when the
+	// portfolio does not exist or the user has no access to it
+	Role(ctx context.Context, portfolioID, userID string) (Role, error)
+
+	ListMembers(ctx context.Context, portfolioID string) ([]*Member, error)
+	AddMember(ctx context.Context, m *Member) error
+	UpdateMember(ctx context.Context, m *Member) error
+	RemoveMember(ctx context.Context, portfolioID, userID string) error
+
+	CreateShareToken(ctx context.Context, t *ShareToken) error
+	ListShareTokens(ctx context.Context, portfolioID string) ([]*ShareToken, error)
+	RevokeShareToken(ctx context.Context, portfolioID, tokenID string, at time.Time) error
+	GetShareTokenByHash(ctx context.Context, hash string) (*ShareToken, error)
+	TouchShareToken(ctx context.Context, tokenID string, at time.Time) error
+}
@@ -2,17 +2,34 @@ package portfolio
 
 import (
 	"math/big"
+	"sort"
 	"testtask/internal/domain/price"
 	"testtask/internal/domain/token"
 )
 
 // Asset represents an asset from holdings or transactions with its value
 type Asset struct {
-	Token  *token.Token
-	Amount *big.Int
-	Price  *price.Price
-	Value  *big.Int
-	Source string // "asset" or "transaction"
+	Token          *token.Token
+	Amount         *big.Int
+	Price          *price.Price
+	Value          *big.Int
+	ValueChange24h *big.Int     // absolute value change over 24h, nil if unknown
+	Source         string       // "asset" or "transaction"
+	Components     []*Asset     // assets combined into a merged asset
+	Manual         *ManualEntry // entry a manual asset or liability is valued from
+}
+
+// SourceMerged marks an asset that combines equivalent tokens, e.g. ETH and WETH
+const SourceMerged = "merged"
+
+// AssetsSummary aggregates asset values of a portfolio. Negative values, such as
+// liabilities, reduce the total, which is the portfolio's net worth.
+type AssetsSummary struct {
+	TotalValue   *big.Int // smallest currency units
+	GrossAssets  *big.Int // sum of positive values
+	Liabilities  *big.Int // sum of negative values, as a positive amount
+	Change24h    *big.Int // absolute 24h PnL in smallest currency units
+	Change24hPct *big.Int // percent, scaled by price.ChangeDecimal; nil if unknown
 }
 
 // CalculateValue calculates the USD value of a asset based on token price, decimals, and amount.
@@ -42,3 +59,187 @@ func CalculateValue(decimals uint8, amount *big.Int, priceValue *price.Price) *b
 
 	return result
 }
+
+// CalculateValueChange derives the absolute change of a value over a period
+// from its current value and the period's percentage change.
+// Formula: value * pct / (100 + pct), with pct scaled by price.ChangeDecimal.
+// Returns nil if any input is nil or the change is -100% or lower.
+func CalculateValueChange(value *big.Int, changePct *big.Int) *big.Int {
+	if value == nil || changePct == nil {
+		return nil
+	}
+
+	hundred := new(big.Int).Mul(big.NewInt(100), pow10(price.ChangeDecimal))
+	denominator := new(big.Int).Add(hundred, changePct)
+	if denominator.Sign() <= 0 {
+		return nil
+	}
+
+	result := new(big.Int).Mul(value, changePct)
+	return result.Quo(result, denominator)
+}
+
+// SummarizeAssets sums values and 24h changes of the given assets.
+// Assets without a known 24h change contribute to the total value only.
+func SummarizeAssets(assets []*Asset) *AssetsSummary {
+	summary := &AssetsSummary{
+		TotalValue:  big.NewInt(0),
+		GrossAssets: big.NewInt(0),
+		Liabilities: big.NewInt(0),
+		Change24h:   big.NewInt(0),
+	}
+
+	for _, a := range assets {
+		if a == nil || a.Value == nil {
+			continue
+		}
+		summary.TotalValue.Add(summary.TotalValue, a.Value)
+		if a.Value.Sign() < 0 {
+			summary.Liabilities.Sub(summary.Liabilities, a.Value)
+		} else {
+			summary.GrossAssets.Add(summary.GrossAssets, a.Value)
+		}
+		if a.ValueChange24h != nil {
+			summary.Change24h.Add(summary.Change24h, a.ValueChange24h)
+		}
+	}
+
+	// Previous value is total minus change; pct = change / previous * 100
+	previous := new(big.Int).Sub(summary.TotalValue, summary.Change24h)
+	if previous.Sign() > 0 {
+		pct := new(big.Int).Mul(summary.Change24h, big.NewInt(100))
+		pct.Mul(pct, pow10(price.ChangeDecimal))
+		summary.Change24hPct = pct.Quo(pct, previous)
+	}
+
+	return summary
+}
+
+// TopMovers returns up to limit assets with the largest 24h gains and losses,
+// ordered by magnitude. Assets without a known 24h change are ignored.
+func TopMovers(assets []*Asset, limit int) (gainers []*Asset, losers []*Asset) {
+	gainers = make([]*Asset, 0)
+	losers = make([]*Asset, 0)
+
+	for _, a := range assets {
+		if a == nil {
+			continue
+		}
+		change := a.Price.Change24h()
+		if change == nil {
+			continue
+		}
+		switch change.Sign() {
+		case 1:
+			gainers = append(gainers, a)
+		case -1:
+			losers = append(losers, a)
+		}
+	}
+
+	sort.SliceStable(gainers, func(i, j int) bool {
+		return gainers[i].Price.Change24h().Cmp(gainers[j].Price.Change24h()) > 0
+	})
+	sort.SliceStable(losers, func(i, j int) bool {
+		return losers[i].Price.Change24h().Cmp(losers[j].Price.Change24h()) < 0
+	})
+
+	if limit > 0 && len(gainers) > limit {
+		gainers = gainers[:limit]
+	}
+	if limit > 0 && len(losers) > limit {
+		losers = losers[:limit]
+	}
+
+	return gainers, losers
+}
+
+// MergeEquivalent combines assets of the same alias group into one asset per group.
+// Amounts are converted to the group's decimals and values are summed; the merged
+// asset has no single price and keeps the original assets as components.
+// Assets outside any group, or alone in their group, and manual entries are returned
+// unchanged.
+func MergeEquivalent(assets []*Asset, group func(address string) (*token.AliasGroup, bool)) []*Asset {
+	byGroup := make(map[string][]*Asset)
+	groups := make(map[string]*token.AliasGroup)
+	for _, a := range assets {
+		if a == nil || a.Token == nil || a.Manual != nil {
+			continue
+		}
+		if g, ok := group(a.Token.Address); ok {
+			byGroup[g.ID] = append(byGroup[g.ID], a)
+			groups[g.ID] = g
+		}
+	}
+
+	result := make([]*Asset, 0, len(assets))
+	emitted := make(map[string]bool)
+	for _, a := range assets {
+		if a == nil || a.Token == nil || a.Manual != nil {
+			result = append(result, a)
+			continue
+		}
+		g, ok := group(a.Token.Address)
+		if !ok || len(byGroup[g.ID]) < 2 {
+			result = append(result, a)
+			continue
+		}
+		if emitted[g.ID] {
+			continue
+		}
+		emitted[g.ID] = true
+		result = append(result, mergeAssets(groups[g.ID], byGroup[g.ID]))
+	}
+
+	return result
+}
+
+func mergeAssets(g *token.AliasGroup, components []*Asset) *Asset {
+	merged := &Asset{
+		Token: &token.Token{
+			ID:      g.ID,
+			Name:    g.Name,
+			Symbol:  g.Symbol,
+			Address: g.Members[0],
+			Decimal: g.Decimal,
+		},
+		Amount:     big.NewInt(0),
+		Source:     SourceMerged,
+		Components: components,
+	}
+
+	for _, a := range components {
+		if a.Amount != nil {
+			merged.Amount.Add(merged.Amount, rescale(a.Amount, a.Token.Decimal, g.Decimal))
+		}
+		if a.Value != nil {
+			if merged.Value == nil {
+				merged.Value = big.NewInt(0)
+			}
+			merged.Value.Add(merged.Value, a.Value)
+		}
+		if a.ValueChange24h != nil {
+			if merged.ValueChange24h == nil {
+				merged.ValueChange24h = big.NewInt(0)
+			}
+			merged.ValueChange24h.Add(merged.ValueChange24h, a.ValueChange24h)
+		}
+	}
+
+	return merged
+}
+
+// rescale converts an amount between token decimal precisions, truncating extra digits
+func rescale(amount *big.Int, from, to uint8) *big.Int {
+	switch {
+	case from < to:
+		return new(big.Int).Mul(amount, pow10(int(to-from)))
+	case from > to:
+		return new(big.Int).Quo(amount, pow10(int(from-to)))
+	}
+	return new(big.Int).Set(amount)
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
@@ -309,3 +309,216 @@ func TestCalculateValue(t *testing.T) {
 		})
 	}
 }
+
+func TestCalculateValueChange(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     *big.Int
+		changePct *big.Int
+		expected  *big.Int
+	}{
+		{
+			name:      "positive change - 25%",
+			value:     big.NewInt(12500000000), // $125.00
+			changePct: big.NewInt(2500000000),  // 25%
+			expected:  big.NewInt(2500000000),  // $25.00 (previous value $100)
+		},
+		{
+			name:      "negative change - 20%",
+			value:     big.NewInt(8000000000),  // $80.00
+			changePct: big.NewInt(-2000000000), // -20%
+			expected:  big.NewInt(-2000000000), // -$20.00 (previous value $100)
+		},
+		{
+			name:      "zero change",
+			value:     big.NewInt(8000000000),
+			changePct: big.NewInt(0),
+			expected:  big.NewInt(0),
+		},
+		{
+			name:      "nil change",
+			value:     big.NewInt(8000000000),
+			changePct: nil,
+			expected:  nil,
+		},
+		{
+			name:      "nil value",
+			value:     nil,
+			changePct: big.NewInt(100000000),
+			expected:  nil,
+		},
+		{
+			name:      "change of -100% has no previous value",
+			value:     big.NewInt(0),
+			changePct: big.NewInt(-10000000000),
+			expected:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := CalculateValueChange(tt.value, tt.changePct)
+
+			if tt.expected == nil {
+				if result != nil {
+					t.Errorf("CalculateValueChange() = %v, want nil", result)
+				}
+				return
+			}
+
+			if result == nil || result.Cmp(tt.expected) != 0 {
+				t.Errorf("CalculateValueChange() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSummarizeAssets(t *testing.T) {
+	assets := []*Asset{
+		{Value: big.NewInt(12500000000), ValueChange24h: big.NewInt(2500000000)}, // $125, +$25
+		{Value: big.NewInt(8000000000), ValueChange24h: big.NewInt(-2000000000)}, // $80, -$20
+		{Value: big.NewInt(1500000000)},                                          // $15, change unknown
+		{Value: nil},                                                             // unpriced
+		nil,
+	}
+
+	summary := SummarizeAssets(assets)
+
+	if summary.TotalValue.Cmp(big.NewInt(22000000000)) != 0 {
+		t.Errorf("TotalValue = %v, want 22000000000", summary.TotalValue)
+	}
+	if summary.Change24h.Cmp(big.NewInt(500000000)) != 0 {
+		t.Errorf("Change24h = %v, want 500000000", summary.Change24h)
+	}
+	// $5 on a previous value of $215
+	if summary.Change24hPct == nil || summary.Change24hPct.Cmp(big.NewInt(232558139)) != 0 {
+		t.Errorf("Change24hPct = %v, want 232558139", summary.Change24hPct)
+	}
+
+	empty := SummarizeAssets(nil)
+	if empty.TotalValue.Sign() != 0 || empty.Change24hPct != nil {
+		t.Errorf("SummarizeAssets(nil) = %+v, want zero total and nil pct", empty)
+	}
+}
+
+func TestSummarizeAssets_Liabilities(t *testing.T) {
+	assets := []*Asset{
+		{Value: big.NewInt(50000000000)},                    // $500 on-chain
+		{Value: big.NewInt(20000000000), Source: "manual"},  // $200 cash
+		{Value: big.NewInt(-30000000000), Source: "manual"}, // $300 loan
+	}
+
+	summary := SummarizeAssets(assets)
+
+	if summary.GrossAssets.Cmp(big.NewInt(70000000000)) != 0 {
+		t.Errorf("GrossAssets = %v, want 70000000000", summary.GrossAssets)
+	}
+	if summary.Liabilities.Cmp(big.NewInt(30000000000)) != 0 {
+		t.Errorf("Liabilities = %v, want 30000000000", summary.Liabilities)
+	}
+	if summary.TotalValue.Cmp(big.NewInt(40000000000)) != 0 {
+		t.Errorf("TotalValue = %v, want net worth 40000000000", summary.TotalValue)
+	}
+}
+
+func TestTopMovers(t *testing.T) {
+	withChange := func(symbol string, pct int64) *Asset {
+		return &Asset{
+			Token: &token.Token{Symbol: symbol},
+			Price: &price.Price{
+				Value:  big.NewInt(100000000),
+				Market: &price.MarketData{Change24h: big.NewInt(pct)},
+			},
+		}
+	}
+
+	assets := []*Asset{
+		withChange("A", 500000000),
+		withChange("B", -300000000),
+		withChange("C", 1200000000),
+		withChange("D", -900000000),
+		withChange("E", 0),
+		{Token: &token.Token{Symbol: "F"}, Price: &price.Price{Value: big.NewInt(1)}},
+		{Token: &token.Token{Symbol: "G"}},
+	}
+
+	gainers, losers := TopMovers(assets, 1)
+	if len(gainers) != 1 || gainers[0].Token.Symbol != "C" {
+		t.Errorf("TopMovers() gainers = %v, want [C]", symbols(gainers))
+	}
+	if len(losers) != 1 || losers[0].Token.Symbol != "D" {
+		t.Errorf("TopMovers() losers = %v, want [D]", symbols(losers))
+	}
+
+	gainers, losers = TopMovers(assets, 0)
+	if got := symbols(gainers); len(got) != 2 || got[0] != "C" || got[1] != "A" {
+		t.Errorf("TopMovers() gainers = %v, want [C A]", got)
+	}
+	if got := symbols(losers); len(got) != 2 || got[0] != "D" || got[1] != "B" {
+		t.Errorf("TopMovers() losers = %v, want [D B]", got)
+	}
+}
+
+func symbols(assets []*Asset) []string {
+	result := make([]string, 0, len(assets))
+	for _, a := range assets {
+		result = append(result, a.Token.Symbol)
+	}
+	return result
+}
+
+func TestMergeEquivalent(t *testing.T) {
+	eth := &token.AliasGroup{ID: "eth", Name: "Ether", Symbol: "ETH", Decimal: 18, Members: []string{"0xeth", "0xweth", "0xsteth"}}
+	btc := &token.AliasGroup{ID: "btc", Name: "Bitcoin", Symbol: "BTC", Decimal: 8, Members: []string{"0xwbtc", "0xcbbtc"}}
+	groups := map[string]*token.AliasGroup{"0xeth": eth, "0xweth": eth, "0xsteth": eth, "0xwbtc": btc, "0xcbbtc": btc}
+	group := func(address string) (*token.AliasGroup, bool) {
+		g, ok := groups[address]
+		return g, ok
+	}
+
+	native := &Asset{Token: &token.Token{Symbol: "ETH", Address: "0xeth", Decimal: 18}, Amount: big.NewInt(1e18), Value: big.NewInt(300000000000), ValueChange24h: big.NewInt(1000)}
+	weth := &Asset{Token: &token.Token{Symbol: "WETH", Address: "0xweth", Decimal: 18}, Amount: big.NewInt(2e18), Value: big.NewInt(600000000000)}
+	usdc := &Asset{Token: &token.Token{Symbol: "USDC", Address: "0xusdc", Decimal: 6}, Amount: big.NewInt(1e6), Value: big.NewInt(100000000)}
+	// Only one BTC token is held, so it is not merged
+	wbtc := &Asset{Token: &token.Token{Symbol: "WBTC", Address: "0xwbtc", Decimal: 8}, Amount: big.NewInt(1e8)}
+
+	merged := MergeEquivalent([]*Asset{native, usdc, weth, wbtc}, group)
+	if len(merged) != 3 {
+		t.Fatalf("MergeEquivalent() returned %d assets, want 3", len(merged))
+	}
+	if merged[1] != usdc || merged[2] != wbtc {
+		t.Errorf("ungrouped assets should be returned unchanged and in order")
+	}
+
+	got := merged[0]
+	if got.Source != SourceMerged || got.Token.Symbol != "ETH" || len(got.Components) != 2 {
+		t.Fatalf("merged asset = %+v, want ETH with 2 components", got)
+	}
+	if got.Amount.Cmp(big.NewInt(3e18)) != 0 {
+		t.Errorf("Amount = %s, want 3e18", got.Amount)
+	}
+	if got.Value.Cmp(big.NewInt(900000000000)) != 0 {
+		t.Errorf("Value = %s, want 900000000000", got.Value)
+	}
+	if got.ValueChange24h.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("ValueChange24h = %s, want 1000", got.ValueChange24h)
+	}
+}
+
+func TestRescale(t *testing.T) {
+	tests := []struct {
+		amount   int64
+		from, to uint8
+		want     int64
+	}{
+		{amount: 1500000, from: 6, to: 8, want: 150000000},
+		{amount: 150000000, from: 8, to: 6, want: 1500000},
+		{amount: 42, from: 18, to: 18, want: 42},
+	}
+
+	for _, tt := range tests {
+		if got := rescale(big.NewInt(tt.amount), tt.from, tt.to); got.Cmp(big.NewInt(tt.want)) != 0 {
+			t.Errorf("rescale(%d, %d, %d) = %s, want %d", tt.amount, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
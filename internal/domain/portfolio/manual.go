@@ -0,0 +1,180 @@
+package portfolio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"testtask/internal/domain/price"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrManualEntryNotFound = errors.New("manual entry not found")
+	ErrInvalidManualEntry  = errors.New("invalid manual entry")
+)
+
+// SourceManual marks assets valued from a manual entry
+const SourceManual = "manual"
+
+// ManualQuantityDecimals is the precision of manual entry quantities
+const ManualQuantityDecimals = 18
+
+// ManualSide tells whether an entry adds to or is owed from the portfolio's net worth
+type ManualSide string
+
+const (
+	ManualAsset     ManualSide = "asset"
+	ManualLiability ManualSide = "liability"
+)
+
+// ManualCategory groups off-chain entries for display
+type ManualCategory string
+
+const (
+	CategoryCash      ManualCategory = "cash"
+	CategoryCustodian ManualCategory = "custodian"
+	CategoryLoan      ManualCategory = "loan"
+	CategoryOther     ManualCategory = "other"
+)
+
+// Valuation is how a manual entry is valued
+type Valuation string
+
+const (
+	// ValuationFixed values the entry at Value in Currency, e.g. a cash balance or a loan
+	ValuationFixed Valuation = "fixed"
+	// ValuationUnitPrice values Quantity at UnitPrice in Currency, e.g. shares at a custodian
+	ValuationUnitPrice Valuation = "unit_price"
+	// ValuationMarket values Quantity of TokenAddress at its market price, e.g. BTC held at a custodian
+	ValuationMarket Valuation = "market"
+)
+
+// ManualEntry is an off-chain asset or liability the user maintains by hand. Amounts are
+// positive; the side decides whether the entry counts towards assets or liabilities.
+type ManualEntry struct {
+	ID          string
+	PortfolioID string
+	Name        string
+	Side        ManualSide
+	Category    ManualCategory
+	Valuation   Valuation
+	Currency    string // quote currency of Value and UnitPrice
+
+	Value        *big.Int // fixed valuation, scaled by price.CurrencyDecimal
+	Quantity     *big.Int // unit price and market valuation, scaled by ManualQuantityDecimals
+	UnitPrice    *big.Int // unit price valuation, scaled by price.CurrencyDecimal
+	TokenAddress string   // market valuation
+
+	Note      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewManualEntry creates an entry for the portfolio with a fresh ID from the given settings
+func NewManualEntry(portfolioID string, e ManualEntry) *ManualEntry {
+	now := time.Now().UTC()
+	e.ID = uuid.New().String()
+	e.PortfolioID = portfolioID
+	e.CreatedAt = now
+	e.UpdatedAt = now
+	e.Normalize()
+	return &e
+}
+
+// Normalize trims text fields and lowercases codes and addresses
+func (e *ManualEntry) Normalize() {
+	e.Name = strings.TrimSpace(e.Name)
+	e.Note = strings.TrimSpace(e.Note)
+	e.Currency = strings.ToLower(strings.TrimSpace(e.Currency))
+	e.TokenAddress = strings.ToLower(strings.TrimSpace(e.TokenAddress))
+	if e.Category == "" {
+		e.Category = CategoryOther
+	}
+}
+
+// Validate checks the entry is complete for its valuation method
+func (e *ManualEntry) Validate() error {
+	if e.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidManualEntry)
+	}
+	if e.Side != ManualAsset && e.Side != ManualLiability {
+		return fmt.Errorf("%w: side must be asset or liability", ErrInvalidManualEntry)
+	}
+	switch e.Category {
+	case CategoryCash, CategoryCustodian, CategoryLoan, CategoryOther:
+	default:
+		return fmt.Errorf("%w: category must be cash, custodian, loan or other", ErrInvalidManualEntry)
+	}
+
+	switch e.Valuation {
+	case ValuationFixed:
+		if e.Value == nil || e.Value.Sign() < 0 {
+			return fmt.Errorf("%w: fixed valuation requires a non-negative value", ErrInvalidManualEntry)
+		}
+		if e.Currency == "" {
+			return fmt.Errorf("%w: fixed valuation requires a currency", ErrInvalidManualEntry)
+		}
+	case ValuationUnitPrice:
+		if e.Quantity == nil || e.Quantity.Sign() < 0 || e.UnitPrice == nil || e.UnitPrice.Sign() < 0 {
+			return fmt.Errorf("%w: unit price valuation requires a non-negative quantity and unit price", ErrInvalidManualEntry)
+		}
+		if e.Currency == "" {
+			return fmt.Errorf("%w: unit price valuation requires a currency", ErrInvalidManualEntry)
+		}
+	case ValuationMarket:
+		if e.Quantity == nil || e.Quantity.Sign() < 0 {
+			return fmt.Errorf("%w: market valuation requires a non-negative quantity", ErrInvalidManualEntry)
+		}
+		if e.TokenAddress == "" {
+			return fmt.Errorf("%w: market valuation requires a token address", ErrInvalidManualEntry)
+		}
+	default:
+		return fmt.Errorf("%w: valuation must be fixed, unit_price or market", ErrInvalidManualEntry)
+	}
+	return nil
+}
+
+// Sign is 1 for assets and -1 for liabilities
+func (e *ManualEntry) Sign() int64 {
+	if e.Side == ManualLiability {
+		return -1
+	}
+	return 1
+}
+
+// QuoteValue returns the unsigned value in the entry's currency, scaled by
+// price.CurrencyDecimal. It is nil for market valuation, which needs a market price.
+func (e *ManualEntry) QuoteValue() *big.Int {
+	switch e.Valuation {
+	case ValuationFixed:
+		return new(big.Int).Set(e.Value)
+	case ValuationUnitPrice:
+		value := new(big.Int).Mul(e.Quantity, e.UnitPrice)
+		return value.Quo(value, pow10(ManualQuantityDecimals))
+	}
+	return nil
+}
+
+// ConvertValue converts a value between currencies at rate, the price of one unit of the
+// source currency in the target currency scaled by price.CurrencyDecimal
+func ConvertValue(value, rate *big.Int) *big.Int {
+	if value == nil || rate == nil {
+		return nil
+	}
+	converted := new(big.Int).Mul(value, rate)
+	return converted.Quo(converted, pow10(price.CurrencyDecimal))
+}
+
+// ManualRepository stores manual entries
+type ManualRepository interface {
+	CreateManualEntry(ctx context.Context, e *ManualEntry) error
+	UpdateManualEntry(ctx context.Context, e *ManualEntry) error
+	DeleteManualEntry(ctx context.Context, portfolioID, id string) error
+	GetManualEntry(ctx context.Context, portfolioID, id string) (*ManualEntry, error)
+	ListManualEntries(ctx context.Context, portfolioID string) ([]*ManualEntry, error)
+}
@@ -0,0 +1,96 @@
+package portfolio
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestManualEntry_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   ManualEntry
+		wantErr bool
+	}{
+		{
+			name:  "fixed cash",
+			entry: ManualEntry{Name: "Bank", Side: ManualAsset, Valuation: ValuationFixed, Currency: "eur", Value: big.NewInt(100)},
+		},
+		{
+			name:  "unit price custodian position",
+			entry: ManualEntry{Name: "Broker", Side: ManualAsset, Valuation: ValuationUnitPrice, Currency: "usd", Quantity: big.NewInt(1), UnitPrice: big.NewInt(1)},
+		},
+		{
+			name:  "market loan",
+			entry: ManualEntry{Name: "Loan", Side: ManualLiability, Category: CategoryLoan, Valuation: ValuationMarket, Quantity: big.NewInt(1), TokenAddress: "0xabc"},
+		},
+		{
+			name:    "missing name",
+			entry:   ManualEntry{Side: ManualAsset, Valuation: ValuationFixed, Currency: "usd", Value: big.NewInt(1)},
+			wantErr: true,
+		},
+		{
+			name:    "unknown side",
+			entry:   ManualEntry{Name: "x", Side: "owed", Valuation: ValuationFixed, Currency: "usd", Value: big.NewInt(1)},
+			wantErr: true,
+		},
+		{
+			name:    "negative value",
+			entry:   ManualEntry{Name: "x", Side: ManualLiability, Valuation: ValuationFixed, Currency: "usd", Value: big.NewInt(-1)},
+			wantErr: true,
+		},
+		{
+			name:    "fixed without currency",
+			entry:   ManualEntry{Name: "x", Side: ManualAsset, Valuation: ValuationFixed, Value: big.NewInt(1)},
+			wantErr: true,
+		},
+		{
+			name:    "market without token",
+			entry:   ManualEntry{Name: "x", Side: ManualAsset, Valuation: ValuationMarket, Quantity: big.NewInt(1)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := tt.entry
+			e.Normalize()
+			err := e.Validate()
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidManualEntry) {
+				t.Errorf("Validate() error = %v, want ErrInvalidManualEntry", err)
+			}
+		})
+	}
+}
+
+func TestManualEntry_QuoteValue(t *testing.T) {
+	// 2.5 units at $40
+	quantity, _ := new(big.Int).SetString("2500000000000000000", 10)
+	unit := &ManualEntry{Valuation: ValuationUnitPrice, Quantity: quantity, UnitPrice: big.NewInt(4000000000)}
+	if got := unit.QuoteValue(); got.Cmp(big.NewInt(10000000000)) != 0 {
+		t.Errorf("unit price QuoteValue() = %v, want 10000000000", got)
+	}
+
+	fixed := &ManualEntry{Valuation: ValuationFixed, Value: big.NewInt(500)}
+	if got := fixed.QuoteValue(); got.Cmp(big.NewInt(500)) != 0 {
+		t.Errorf("fixed QuoteValue() = %v, want 500", got)
+	}
+
+	market := &ManualEntry{Valuation: ValuationMarket, Quantity: quantity}
+	if got := market.QuoteValue(); got != nil {
+		t.Errorf("market QuoteValue() = %v, want nil", got)
+	}
+}
+
+func TestConvertValue(t *testing.T) {
+	// €100 at 1.10 USD per EUR
+	if got := ConvertValue(big.NewInt(10000000000), big.NewInt(110000000)); got.Cmp(big.NewInt(11000000000)) != 0 {
+		t.Errorf("ConvertValue() = %v, want 11000000000", got)
+	}
+	if got := ConvertValue(big.NewInt(1), nil); got != nil {
+		t.Errorf("ConvertValue() without rate = %v, want nil", got)
+	}
+}
@@ -3,6 +3,9 @@ package portfolio
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	domainHolding "testtask/internal/domain/holding"
 	"time"
 
@@ -12,28 +15,140 @@ import (
 var (
 	ErrPortfolioNotFound      = errors.New("portfolio not found")
 	ErrPortfolioAddressExists = errors.New("portfolio address already exists")
+	ErrPortfolioExists        = errors.New("portfolio already exists")
+	ErrInvalidPortfolio       = errors.New("invalid portfolio")
+	ErrPortfolioArchived      = errors.New("portfolio is archived")
+)
+
+// Limits of portfolio metadata
+const (
+	MaxNameLength        = 100
+	MaxDescriptionLength = 1000
+	MaxTags              = 20
+	MaxTagLength         = 32
 )
 
 // holdingRepo.Holding represents a token holdingRepo.Holding in the portfolio
 
 // Portfolio represents the user's crypto portfolio
 type Portfolio struct {
-	ID        string
-	Address   string
-	Holdings  []*domainHolding.Holding
-	UpdatedAt time.Time
+	ID          string
+	OwnerID     string
+	Address     string
+	Name        string
+	Description string
+	Tags        []string
+	Archived    bool
+	Holdings    []*domainHolding.Holding
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
 }
 
 func NewPortfolio(id, address string) *Portfolio {
 	if id == "" {
 		id = uuid.New().String()
 	}
+	now := time.Now()
 	return &Portfolio{
 		ID:        id,
 		Address:   address,
+		Tags:      make([]string, 0),
 		Holdings:  make([]*domainHolding.Holding, 0),
-		UpdatedAt: time.Now(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Changes is a partial update of portfolio metadata; nil fields are left unchanged
+type Changes struct {
+	Name        *string
+	Description *string
+	Tags        *[]string
+}
+
+// Apply updates the metadata with the given changes and validates the result
+func (p *Portfolio) Apply(c Changes) error {
+	if c.Name != nil {
+		p.Name = strings.TrimSpace(*c.Name)
+	}
+	if c.Description != nil {
+		p.Description = strings.TrimSpace(*c.Description)
+	}
+	if c.Tags != nil {
+		p.Tags = NormalizeTags(*c.Tags)
+	}
+	p.UpdatedAt = time.Now()
+	return p.Validate()
+}
+
+// Validate checks metadata limits
+func (p *Portfolio) Validate() error {
+	switch {
+	case len(p.Name) > MaxNameLength:
+		return fmt.Errorf("%w: name must be at most %d characters", ErrInvalidPortfolio, MaxNameLength)
+	case len(p.Description) > MaxDescriptionLength:
+		return fmt.Errorf("%w: description must be at most %d characters", ErrInvalidPortfolio, MaxDescriptionLength)
+	case len(p.Tags) > MaxTags:
+		return fmt.Errorf("%w: at most %d tags are allowed", ErrInvalidPortfolio, MaxTags)
 	}
+	for _, tag := range p.Tags {
+		if len(tag) > MaxTagLength {
+			return fmt.Errorf("%w: tag %q must be at most %d characters", ErrInvalidPortfolio, tag, MaxTagLength)
+		}
+	}
+	return nil
+}
+
+// NormalizeTags trims, lowercases, deduplicates and sorts tags, dropping empty ones
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Wallets maps the lowercase addresses of the owner's portfolios, p included, to the
+// portfolio of each. A portfolio without an owner only has its own address.
+func Wallets(p *Portfolio, all []*Portfolio) map[string]*Portfolio {
+	wallets := make(map[string]*Portfolio)
+	for _, other := range all {
+		if other.ID != p.ID && (p.OwnerID == "" || other.OwnerID != p.OwnerID) {
+			continue
+		}
+		if address := strings.ToLower(other.Address); address != "" {
+			wallets[address] = other
+		}
+	}
+	if address := strings.ToLower(p.Address); address != "" {
+		wallets[address] = p
+	}
+	return wallets
+}
+
+// Sort fields of portfolio listings
+const (
+	SortByName      = "name"
+	SortByCreatedAt = "created_at"
+	SortByUpdatedAt = "updated_at"
+)
+
+// ListOptions filters, sorts and paginates portfolio listings.
+// Archived portfolios are left out unless IncludeArchived is set.
+type ListOptions struct {
+	Page            int
+	PageSize        int
+	SortBy          string
+	Desc            bool
+	IncludeArchived bool
+	Tag             string
 }
 
 type Repository interface {
@@ -41,5 +156,12 @@ type Repository interface {
 	GetByID(ctx context.Context, portfolioID string) (*Portfolio, error)
 	GetByIDWithHoldings(ctx context.Context, portfolioID string) (*Portfolio, error)
 	Create(ctx context.Context, portfolio *Portfolio) error
+	Update(ctx context.Context, portfolio *Portfolio) error
+	// Delete removes the portfolio together with its holdings and other portfolio-scoped data
+	Delete(ctx context.Context, portfolioID string) error
 	List(ctx context.Context) ([]*Portfolio, error)
+	// ListByOwner returns every portfolio of the owner regardless of the caller in ctx.
+	// Callers check access to one of the owner's portfolios first.
+	ListByOwner(ctx context.Context, ownerID string) ([]*Portfolio, error)
+	ListPage(ctx context.Context, opts ListOptions) ([]*Portfolio, int, error)
 }
@@ -0,0 +1,113 @@
+package portfolio
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	got := NormalizeTags([]string{" DeFi ", "cold", "", "defi", "Cold"})
+	want := []string{"cold", "defi"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NormalizeTags() = %v, want %v", got, want)
+	}
+}
+
+func TestPortfolio_Apply(t *testing.T) {
+	name := "  Long term  "
+	longName := strings.Repeat("x", MaxNameLength+1)
+	tags := []string{"ETH", "cold"}
+	tooManyTags := make([]string, MaxTags+1)
+	for i := range tooManyTags {
+		tooManyTags[i] = strings.Repeat("t", i+1)
+	}
+
+	tests := []struct {
+		name     string
+		changes  Changes
+		wantName string
+		wantTags []string
+		wantErr  bool
+	}{
+		{
+			name:     "trims name and normalizes tags",
+			changes:  Changes{Name: &name, Tags: &tags},
+			wantName: "Long term",
+			wantTags: []string{"cold", "eth"},
+		},
+		{
+			name:     "nil fields are unchanged",
+			changes:  Changes{},
+			wantName: "Original",
+			wantTags: []string{"keep"},
+		},
+		{
+			name:    "name too long",
+			changes: Changes{Name: &longName},
+			wantErr: true,
+		},
+		{
+			name:    "too many tags",
+			changes: Changes{Tags: &tooManyTags},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPortfolio("p-1", "0xabc")
+			p.Name = "Original"
+			p.Tags = []string{"keep"}
+
+			err := p.Apply(tt.changes)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPortfolio) {
+					t.Errorf("Apply() error = %v, want ErrInvalidPortfolio", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if p.Name != tt.wantName || !reflect.DeepEqual(p.Tags, tt.wantTags) {
+				t.Errorf("Apply() = name %q tags %v, want %q %v", p.Name, p.Tags, tt.wantName, tt.wantTags)
+			}
+		})
+	}
+}
+
+func TestWallets(t *testing.T) {
+	mine := &Portfolio{ID: "p-1", OwnerID: "u-1", Address: "0xAAA"}
+	all := []*Portfolio{
+		mine,
+		{ID: "p-2", OwnerID: "u-1", Address: "0xBBB"},
+		{ID: "p-3", OwnerID: "u-1"},
+		{ID: "p-4", OwnerID: "u-2", Address: "0xccc"},
+		{ID: "p-5", Address: "0xddd"},
+	}
+
+	tests := []struct {
+		name string
+		p    *Portfolio
+		want []string
+	}{
+		{"same owner", mine, []string{"0xaaa", "0xbbb"}},
+		{"no owner", all[4], []string{"0xddd"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Wallets(tt.p, all)
+			var addresses []string
+			for address := range got {
+				addresses = append(addresses, address)
+			}
+			sort.Strings(addresses)
+			if !reflect.DeepEqual(addresses, tt.want) {
+				t.Errorf("Wallets() = %v, want %v", addresses, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package price
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testtask/internal/domain/token"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrOverrideNotFound = errors.New("price override not found")
+	ErrInvalidOverride  = errors.New("invalid price override")
+)
+
+// SourceOverride marks prices taken from a portfolio price override
+const SourceOverride = "override"
+
+type OverrideKind string
+
+const (
+	// OverrideFixed sets the price to a fixed value in one currency
+	OverrideFixed OverrideKind = "fixed"
+	// OverrideRelative prices a token off another token's market price, e.g. a 20% discount
+	OverrideRelative OverrideKind = "relative"
+)
+
+// bpsDenominator is 100% in basis points
+const bpsDenominator = 10000
+
+// Override replaces the market price of a token inside one portfolio while it is effective.
+type Override struct {
+	ID           string
+	PortfolioID  string
+	TokenAddress string
+	Kind         OverrideKind
+
+	// Fixed overrides
+	Value    *big.Int // scaled by CurrencyDecimal
+	Currency string
+
+	// Relative overrides: reference price * (10000 + AdjustmentBps) / 10000
+	ReferenceAddress string
+	AdjustmentBps    int64
+
+	EffectiveFrom time.Time
+	EffectiveTo   *time.Time // open-ended when nil
+	Reason        string
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Override audit actions
+const (
+	OverrideCreated = "created"
+	OverrideUpdated = "updated"
+	OverrideDeleted = "deleted"
+)
+
+// OverrideAuditEntry records one change of an override with its state before and after
+type OverrideAuditEntry struct {
+	ID          string
+	OverrideID  string
+	PortfolioID string
+	Action      string
+	Before      *Override
+	After       *Override
+	CreatedAt   time.Time
+}
+
+// OverrideRepository stores overrides; every change is recorded in the audit trail
+// in the same transaction.
+type OverrideRepository interface {
+	Create(ctx context.Context, o *Override) error
+	Update(ctx context.Context, o *Override) error
+	Delete(ctx context.Context, portfolioID, id string) error
+	GetByID(ctx context.Context, portfolioID, id string) (*Override, error)
+	ListByPortfolio(ctx context.Context, portfolioID string) ([]*Override, error)
+	// Active returns the effective override per lowercase token address at the given time.
+	// When windows overlap the override with the latest EffectiveFrom wins.
+	Active(ctx context.Context, portfolioID string, at time.Time) (map[string]*Override, error)
+	ListAudit(ctx context.Context, portfolioID string) ([]*OverrideAuditEntry, error)
+}
+
+// PortfolioPriceProvider is implemented by price services that apply portfolio overrides
+type PortfolioPriceProvider interface {
+	GetPortfolioPrices(ctx context.Context, portfolioID string, tokens []*token.Token, currency string) (map[*token.Token]*Price, error)
+}
+
+func NewOverride(portfolioID string, o Override) *Override {
+	now := time.Now().UTC()
+	o.ID = uuid.New().String()
+	o.PortfolioID = portfolioID
+	o.TokenAddress = strings.ToLower(o.TokenAddress)
+	o.ReferenceAddress = strings.ToLower(o.ReferenceAddress)
+	o.Currency = strings.ToLower(o.Currency)
+	if o.EffectiveFrom.IsZero() {
+		o.EffectiveFrom = now
+	}
+	o.CreatedAt = now
+	o.UpdatedAt = now
+	return &o
+}
+
+// Validate checks the override is complete for its kind
+func (o *Override) Validate() error {
+	switch o.Kind {
+	case OverrideFixed:
+		if o.Value == nil || o.Value.Sign() < 0 {
+			return fmt.Errorf("%w: fixed override requires a non-negative value", ErrInvalidOverride)
+		}
+		if o.Currency == "" {
+			return fmt.Errorf("%w: fixed override requires a currency", ErrInvalidOverride)
+		}
+	case OverrideRelative:
+		if o.ReferenceAddress == "" || strings.EqualFold(o.ReferenceAddress, o.TokenAddress) {
+			return fmt.Errorf("%w: relative override requires another reference token", ErrInvalidOverride)
+		}
+		if o.AdjustmentBps <= -bpsDenominator {
+			return fmt.Errorf("%w: adjustment must be above -10000 bps", ErrInvalidOverride)
+		}
+	default:
+		return fmt.Errorf("%w: kind must be fixed or relative", ErrInvalidOverride)
+	}
+
+	if o.EffectiveTo != nil && !o.EffectiveTo.After(o.EffectiveFrom) {
+		return fmt.Errorf("%w: effective_to must be after effective_from", ErrInvalidOverride)
+	}
+	return nil
+}
+
+// EffectiveAt reports whether the override applies at the given time
+func (o *Override) EffectiveAt(at time.Time) bool {
+	if at.Before(o.EffectiveFrom) {
+		return false
+	}
+	return o.EffectiveTo == nil || at.Before(*o.EffectiveTo)
+}
+
+// Apply computes the overridden price value. For relative overrides reference is the
+// market price of the reference token; it is ignored for fixed overrides.
+func (o *Override) Apply(reference *big.Int) *big.Int {
+	if o.Kind == OverrideFixed {
+		return new(big.Int).Set(o.Value)
+	}
+	if reference == nil {
+		return nil
+	}
+	value := new(big.Int).Mul(reference, big.NewInt(bpsDenominator+o.AdjustmentBps))
+	return value.Quo(value, big.NewInt(bpsDenominator))
+}
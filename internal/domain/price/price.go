@@ -9,6 +9,12 @@ import (
 
 const CurrencyDecimal = 8
 
+// DefaultCurrency is the quote currency used when a request does not name one.
+const DefaultCurrency = "usd"
+
+// ChangeDecimal is the fixed-point scale of percentage changes (2.5% == 250000000).
+const ChangeDecimal = 8
+
 type Cache[K string, P Price] interface {
 	GetBatch(ctx context.Context, tokenIDs []string) (map[K]*P, bool)
 	SetBatch(ctx context.Context, prices map[K]*P) bool
@@ -25,11 +31,54 @@ type PriceProvider interface {
 // Provider is an alias for PriceProvider for backward compatibility.
 type Provider = PriceProvider
 
+// HistoricalProvider is implemented by providers that can price tokens at a past point in time.
+type HistoricalProvider interface {
+	GetHistoricalPrices(
+		ctx context.Context,
+		tokens []*token.Token,
+		currency string,
+		at time.Time,
+	) (map[*token.Token]*Price, error)
+}
+
+// Price sources reported as provenance of a price
+const (
+	SourceCoinGecko = "coingecko"
+	SourceMock      = "mock"
+	SourceManual    = "manual"
+)
+
 type Price struct {
 	Token       *token.Token
 	Value       *big.Int
 	Currency    string
 	LastUpdated time.Time
+	Market      *MarketData
+
+	Source string // provider that produced the price
+	Cached bool   // served from the tracker's cache
+
+	OverrideID string // set when a portfolio price override replaced the market price
+}
+
+// IsMarket reports whether the price was quoted by a market data provider,
+// as opposed to a mock, manual or override price.
+func (p Price) IsMarket() bool {
+	return p.Source == SourceCoinGecko
+}
+
+// Overridden reports whether the price comes from a portfolio price override
+func (p *Price) Overridden() bool {
+	return p != nil && p.OverrideID != ""
+}
+
+// MarketData holds market statistics reported alongside a price.
+// Any field may be nil when the provider does not report it.
+type MarketData struct {
+	Change24h *big.Int // percent, scaled by ChangeDecimal
+	Change7d  *big.Int // percent, scaled by ChangeDecimal
+	Volume24h *big.Int // currency units, scaled by CurrencyDecimal
+	MarketCap *big.Int // currency units, scaled by CurrencyDecimal
 }
 
 func NewPrice(token *token.Token, amount *big.Int, currency string) Price {
@@ -40,3 +89,11 @@ func NewPrice(token *token.Token, amount *big.Int, currency string) Price {
 		LastUpdated: time.Now(),
 	}
 }
+
+// Change24h returns the 24h percentage change or nil if it is unknown.
+func (p *Price) Change24h() *big.Int {
+	if p == nil || p.Market == nil {
+		return nil
+	}
+	return p.Market.Change24h
+}
@@ -0,0 +1,458 @@
+package tax
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"testtask/internal/domain/transaction"
+)
+
+// Kind tells whether an event adds to or removes from a position
+type Kind string
+
+const (
+	KindAcquisition Kind = "acquisition"
+	KindDisposal    Kind = "disposal"
+	// Transfers between the owner's wallets move lots with their cost basis and realize
+	// nothing; a transfer in takes the lots of the transfer out with the same reference
+	KindTransferOut Kind = "transfer_out"
+	KindTransferIn  Kind = "transfer_in"
+)
+
+// Event is an acquisition, disposal or internal transfer of an asset, valued in the report
+// currency
+type Event struct {
+	Asset     string // lowercase token address; lots are matched per asset
+	Symbol    string
+	Decimals  uint8
+	Kind      Kind
+	Quantity  *big.Int  // positive, in token base units
+	Value     *big.Int  // cost or proceeds scaled by price.CurrencyDecimal, nil if no price was found
+	At        time.Time // when the event occurred
+	Source    string    // where the event comes from, e.g. "onchain", an exchange or "ledger"
+	Reference string    // transaction hash or record ID
+	// Income is set on acquisitions received as income; their value is income at receipt
+	// and the cost basis of the lot they open
+	Income transaction.IncomeCategory
+	// AcquiredAt is the original acquisition date of a lot carried in by an internal
+	// transfer, nil otherwise
+	AcquiredAt *time.Time
+}
+
+// acquired returns when the lot an acquisition opens was first acquired
+func (e *Event) acquired() time.Time {
+	if e.AcquiredAt != nil {
+		return *e.AcquiredAt
+	}
+	return e.At
+}
+
+// rank orders events at the same instant: acquisitions are available to transfers out,
+// transfers out to the transfers in taking their lots, and all of them to disposals
+func (e *Event) rank() int {
+	switch e.Kind {
+	case KindAcquisition:
+		return 0
+	case KindTransferOut:
+		return 1
+	case KindTransferIn:
+		return 2
+	}
+	return 3
+}
+
+// CarriedLot is the part of a position an internal transfer out took along, with its cost
+type CarriedLot struct {
+	Asset      string
+	Quantity   *big.Int
+	Cost       *big.Int
+	AcquiredAt *time.Time // nil when the cost basis is pooled or unknown
+}
+
+// Match names how the cost basis of a gain was found
+type Match string
+
+const (
+	// MatchLot is a lot picked by FIFO, LIFO or HIFO
+	MatchLot Match = "lot"
+	// MatchSameDay is an acquisition on the day of the disposal
+	MatchSameDay Match = "same_day"
+	// MatchThirtyDay is an acquisition in the 30 days after the disposal
+	MatchThirtyDay Match = "thirty_day"
+	// MatchPool is the section 104 pool at average cost
+	MatchPool Match = "section_104"
+	// MatchUnmatched is a disposal beyond the known acquisitions, with a zero cost basis
+	MatchUnmatched Match = "unmatched"
+)
+
+// Gain is the capital gain or loss of a disposal, or of the part of it matched against
+// one lot. Amounts are in the report currency scaled by price.CurrencyDecimal.
+type Gain struct {
+	Asset      string
+	Symbol     string
+	Decimals   uint8
+	Quantity   *big.Int
+	AcquiredAt *time.Time // nil when the cost basis is pooled or unknown
+	DisposedAt time.Time
+	Proceeds   *big.Int
+	CostBasis  *big.Int
+	Gain       *big.Int
+	Term       Term
+	Exempt     bool
+	Match      Match
+	Reference  string // reference of the disposal
+}
+
+// Calculation is the outcome of matching a history of events
+type Calculation struct {
+	Gains    []*Gain
+	Warnings []string
+	// Carried holds, by reference, the lots of transfers out that no transfer in of the
+	// history took; a transfer in to another portfolio of the owner takes them from here
+	Carried map[string][]*CarriedLot
+}
+
+// Calculate matches every disposal against earlier acquisitions of the same asset with the
+// method and returns the gains in disposal order. Events without a value count as zero and
+// disposals beyond the known acquisitions get a zero cost basis; both are reported as warnings.
+func Calculate(events []*Event, rules *Rules, method Method) *Calculation {
+	sorted := append([]*Event(nil), events...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].At.Equal(sorted[j].At) {
+			return sorted[i].At.Before(sorted[j].At)
+		}
+		return sorted[i].rank() < sorted[j].rank()
+	})
+
+	calc := &Calculation{Carried: make(map[string][]*CarriedLot)}
+	var assets []string
+	byAsset := make(map[string][]*Event)
+	for _, e := range sorted {
+		if e.Quantity == nil || e.Quantity.Sign() <= 0 {
+			continue
+		}
+		if e.Value == nil && (e.Kind == KindAcquisition || e.Kind == KindDisposal) {
+			calc.warn("no %s price for %s on %s, valued at zero", e.Kind, e.Symbol, e.At.Format(time.DateOnly))
+		}
+		if _, seen := byAsset[e.Asset]; !seen {
+			assets = append(assets, e.Asset)
+		}
+		byAsset[e.Asset] = append(byAsset[e.Asset], e)
+	}
+
+	for _, asset := range assets {
+		if method == MethodPool {
+			calc.pool(byAsset[asset], rules)
+		} else {
+			calc.lots(byAsset[asset], rules, method)
+		}
+	}
+
+	sort.SliceStable(calc.Gains, func(i, j int) bool {
+		return calc.Gains[i].DisposedAt.Before(calc.Gains[j].DisposedAt)
+	})
+	return calc
+}
+
+// position is the unmatched rest of an event
+type position struct {
+	event    *Event
+	quantity *big.Int
+	amount   *big.Int // remaining cost or proceeds
+}
+
+func newPosition(e *Event) *position {
+	amount := new(big.Int)
+	if e.Value != nil {
+		amount.Set(e.Value)
+	}
+	return &position{event: e, quantity: new(big.Int).Set(e.Quantity), amount: amount}
+}
+
+// take removes quantity from the position and returns its share of the amount. Taking the
+// whole rest returns the whole amount, so shares always add up to the original value.
+func (p *position) take(quantity *big.Int) *big.Int {
+	if quantity.Cmp(p.quantity) >= 0 {
+		share := p.amount
+		p.quantity, p.amount = new(big.Int), new(big.Int)
+		return share
+	}
+	share := new(big.Int).Mul(p.amount, quantity)
+	share.Quo(share, p.quantity)
+	p.amount.Sub(p.amount, share)
+	p.quantity.Sub(p.quantity, quantity)
+	return share
+}
+
+func (p *position) open() bool {
+	return p.quantity.Sign() > 0
+}
+
+// lots matches disposals against individual lots in method order
+func (c *Calculation) lots(events []*Event, rules *Rules, method Method) {
+	var lots []*position
+	for _, e := range events {
+		switch e.Kind {
+		case KindAcquisition:
+			lots = append(lots, newPosition(e))
+			continue
+		case KindTransferIn:
+			lots = append(lots, c.receive(e)...)
+			continue
+		}
+
+		// Lots carried in keep their place by original acquisition date
+		order := append([]*position(nil), lots...)
+		sort.SliceStable(order, func(i, j int) bool {
+			return order[i].event.acquired().Before(order[j].event.acquired())
+		})
+		switch method {
+		case MethodLIFO:
+			for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+				order[i], order[j] = order[j], order[i]
+			}
+		case MethodHIFO:
+			sort.SliceStable(order, func(i, j int) bool {
+				// Compare unit costs without dividing: a.amount/a.quantity > b.amount/b.quantity
+				left := new(big.Int).Mul(order[i].amount, order[j].quantity)
+				right := new(big.Int).Mul(order[j].amount, order[i].quantity)
+				return left.Cmp(right) > 0
+			})
+		}
+
+		if e.Kind == KindTransferOut {
+			rest := new(big.Int).Set(e.Quantity)
+			for _, lot := range order {
+				if rest.Sign() == 0 {
+					break
+				}
+				quantity := minInt(rest, lot.quantity)
+				acquiredAt := lot.event.acquired()
+				c.carry(e, quantity, lot.take(quantity), &acquiredAt)
+				rest.Sub(rest, quantity)
+			}
+			c.carryUnknown(e, rest)
+		} else {
+			disposal := newPosition(e)
+			for _, lot := range order {
+				if !disposal.open() {
+					break
+				}
+				quantity := minInt(disposal.quantity, lot.quantity)
+				acquiredAt := lot.event.acquired()
+				c.match(disposal, quantity, lot.take(quantity), &acquiredAt, MatchLot, rules)
+			}
+			c.unmatched(disposal, rules)
+		}
+
+		remaining := lots[:0]
+		for _, lot := range lots {
+			if lot.open() {
+				remaining = append(remaining, lot)
+			}
+		}
+		lots = remaining
+	}
+}
+
+// pool applies the UK matching rules: acquisitions on the same day, then acquisitions in
+// the 30 days after the disposal, then the section 104 pool of everything else at average cost
+func (c *Calculation) pool(events []*Event, rules *Rules) {
+	var acquisitions, disposals []*position
+	positions := make(map[*Event]*position, len(events))
+	for _, e := range events {
+		p := newPosition(e)
+		positions[e] = p
+		switch {
+		case e.Kind == KindDisposal:
+			disposals = append(disposals, p)
+		case e.Kind == KindAcquisition && e.AcquiredAt == nil:
+			// Lots carried in were acquired earlier and go straight to the pool
+			acquisitions = append(acquisitions, p)
+		}
+	}
+
+	for _, d := range disposals {
+		for _, a := range acquisitions {
+			if d.open() && a.open() && day(a.event.At).Equal(day(d.event.At)) {
+				quantity := minInt(d.quantity, a.quantity)
+				acquiredAt := a.event.At
+				c.match(d, quantity, a.take(quantity), &acquiredAt, MatchSameDay, rules)
+			}
+		}
+	}
+
+	for _, d := range disposals {
+		first, last := day(d.event.At).AddDate(0, 0, 1), day(d.event.At).AddDate(0, 0, 30)
+		for _, a := range acquisitions {
+			at := day(a.event.At)
+			if d.open() && a.open() && !at.Before(first) && !at.After(last) {
+				quantity := minInt(d.quantity, a.quantity)
+				acquiredAt := a.event.At
+				c.match(d, quantity, a.take(quantity), &acquiredAt, MatchThirtyDay, rules)
+			}
+		}
+	}
+
+	pool := &position{quantity: new(big.Int), amount: new(big.Int)}
+	for _, e := range events {
+		p := positions[e]
+		if !p.open() {
+			continue
+		}
+		switch e.Kind {
+		case KindAcquisition:
+			pool.quantity.Add(pool.quantity, p.quantity)
+			pool.amount.Add(pool.amount, p.amount)
+			continue
+		case KindTransferIn:
+			for _, lot := range c.receive(e) {
+				pool.quantity.Add(pool.quantity, lot.quantity)
+				pool.amount.Add(pool.amount, lot.amount)
+			}
+			continue
+		case KindTransferOut:
+			quantity := minInt(p.quantity, pool.quantity)
+			if quantity.Sign() > 0 {
+				c.carry(e, quantity, pool.take(quantity), nil)
+			}
+			c.carryUnknown(e, new(big.Int).Sub(p.quantity, quantity))
+			continue
+		}
+		if pool.open() {
+			quantity := minInt(p.quantity, pool.quantity)
+			c.match(p, quantity, pool.take(quantity), nil, MatchPool, rules)
+		}
+		c.unmatched(p, rules)
+	}
+}
+
+// match records the gain of quantity of a disposal with the given cost basis
+func (c *Calculation) match(disposal *position, quantity, cost *big.Int, acquiredAt *time.Time, m Match, rules *Rules) {
+	e := disposal.event
+	proceeds := disposal.take(quantity)
+	g := &Gain{
+		Asset:      e.Asset,
+		Symbol:     e.Symbol,
+		Decimals:   e.Decimals,
+		Quantity:   new(big.Int).Set(quantity),
+		AcquiredAt: acquiredAt,
+		DisposedAt: e.At,
+		Proceeds:   proceeds,
+		CostBasis:  cost,
+		Gain:       new(big.Int).Sub(proceeds, cost),
+		Match:      m,
+		Reference:  e.Reference,
+	}
+	switch {
+	case acquiredAt != nil && m == MatchLot:
+		g.Term, g.Exempt = rules.Classify(*acquiredAt, e.At)
+	case rules.LongTermYears > 0:
+		// Without an acquisition date a holding period cannot be shown
+		g.Term = TermShort
+	}
+	c.Gains = append(c.Gains, g)
+}
+
+// unmatched records the rest of a disposal that no acquisition covers
+func (c *Calculation) unmatched(disposal *position, rules *Rules) {
+	if !disposal.open() {
+		return
+	}
+	e := disposal.event
+	c.warn("%s disposal on %s exceeds known acquisitions, cost basis of the rest taken as zero", e.Symbol, e.At.Format(time.DateOnly))
+	c.match(disposal, new(big.Int).Set(disposal.quantity), new(big.Int), nil, MatchUnmatched, rules)
+}
+
+// carry records quantity of a transfer out taken along with its cost
+func (c *Calculation) carry(e *Event, quantity, cost *big.Int, acquiredAt *time.Time) {
+	c.Carried[e.Reference] = append(c.Carried[e.Reference], &CarriedLot{
+		Asset:      e.Asset,
+		Quantity:   new(big.Int).Set(quantity),
+		Cost:       cost,
+		AcquiredAt: acquiredAt,
+	})
+}
+
+// carryUnknown records the rest of a transfer out that no acquisition covers, at zero cost
+func (c *Calculation) carryUnknown(e *Event, rest *big.Int) {
+	if rest.Sign() <= 0 {
+		return
+	}
+	c.warn("%s internal transfer on %s exceeds known acquisitions, cost basis of the rest taken as zero", e.Symbol, e.At.Format(time.DateOnly))
+	c.carry(e, rest, new(big.Int), nil)
+}
+
+// receive turns a transfer in into the lots the matching transfer out carried, with their
+// original cost and acquisition date. Without one, the rest is acquired at its value.
+func (c *Calculation) receive(e *Event) []*position {
+	var (
+		lots    []*position
+		kept    []*CarriedLot
+		rest    = new(big.Int).Set(e.Quantity)
+		carried = c.Carried[e.Reference]
+	)
+	for _, lot := range carried {
+		if lot.Asset != e.Asset || rest.Sign() == 0 {
+			kept = append(kept, lot)
+			continue
+		}
+		quantity := minInt(rest, lot.Quantity)
+		from := &position{quantity: lot.Quantity, amount: lot.Cost}
+		cost := from.take(quantity)
+		lots = append(lots, &position{
+			event: &Event{
+				Asset:      e.Asset,
+				Symbol:     e.Symbol,
+				Decimals:   e.Decimals,
+				Kind:       KindAcquisition,
+				Quantity:   quantity,
+				Value:      cost,
+				At:         e.At,
+				Source:     e.Source,
+				Reference:  e.Reference,
+				AcquiredAt: lot.AcquiredAt,
+			},
+			quantity: new(big.Int).Set(quantity),
+			amount:   new(big.Int).Set(cost),
+		})
+		rest.Sub(rest, quantity)
+		if from.open() {
+			kept = append(kept, &CarriedLot{Asset: lot.Asset, Quantity: from.quantity, Cost: from.amount, AcquiredAt: lot.AcquiredAt})
+		}
+	}
+	if len(kept) == 0 {
+		delete(c.Carried, e.Reference)
+	} else {
+		c.Carried[e.Reference] = kept
+	}
+
+	if rest.Sign() > 0 {
+		c.warn("cost basis of %s internal transfer on %s unknown, valued at receipt", e.Symbol, e.At.Format(time.DateOnly))
+		if e.Value == nil {
+			c.warn("no %s price for %s on %s, valued at zero", KindAcquisition, e.Symbol, e.At.Format(time.DateOnly))
+		}
+		received := newPosition(e)
+		lots = append(lots, &position{event: e, quantity: new(big.Int).Set(rest), amount: received.take(rest)})
+	}
+	return lots
+}
+
+func (c *Calculation) warn(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	for _, w := range c.Warnings {
+		if w == message {
+			return
+		}
+	}
+	c.Warnings = append(c.Warnings, message)
+}
+
+func minInt(a, b *big.Int) *big.Int {
+	if a.Cmp(b) <= 0 {
+		return new(big.Int).Set(a)
+	}
+	return new(big.Int).Set(b)
+}
@@ -0,0 +1,237 @@
+package tax
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"testtask/internal/domain/transaction"
+)
+
+var (
+	usRules = &Rules{Code: "US", Currency: "usd", Method: MethodFIFO, AllowedMethods: []Method{MethodLIFO, MethodHIFO}, LongTermYears: 1, YearStartMonth: time.January, YearStartDay: 1}
+	deRules = &Rules{Code: "DE", Currency: "eur", Method: MethodFIFO, LongTermYears: 1, LongTermExempt: true, YearStartMonth: time.January, YearStartDay: 1}
+	ukRules = &Rules{Code: "UK", Currency: "gbp", Method: MethodPool, YearStartMonth: time.April, YearStartDay: 6}
+)
+
+// money returns whole currency units scaled by price.CurrencyDecimal
+func money(units int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(units), big.NewInt(100000000))
+}
+
+func buy(quantity, cost int64, at string) *Event {
+	return event(KindAcquisition, quantity, cost, at)
+}
+
+func sell(quantity, proceeds int64, at string) *Event {
+	return event(KindDisposal, quantity, proceeds, at)
+}
+
+func event(kind Kind, quantity, value int64, at string) *Event {
+	ts, err := time.Parse(time.DateTime, at)
+	if err != nil {
+		ts, _ = time.Parse(time.DateOnly, at)
+	}
+	return &Event{Asset: "0xeth", Symbol: "ETH", Kind: kind, Quantity: big.NewInt(quantity), Value: money(value), At: ts, Reference: string(kind) + at}
+}
+
+func TestCalculate_Methods(t *testing.T) {
+	events := []*Event{
+		buy(1, 100, "2023-01-01"),
+		buy(1, 300, "2023-01-15"),
+		buy(1, 200, "2023-02-01"),
+		sell(1, 250, "2023-03-01"),
+	}
+
+	tests := []struct {
+		method   Method
+		wantCost int64
+	}{
+		{MethodFIFO, 100},
+		{MethodLIFO, 200},
+		{MethodHIFO, 300},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.method), func(t *testing.T) {
+			calc := Calculate(events, usRules, tt.method)
+			if len(calc.Gains) != 1 {
+				t.Fatalf("Calculate() gains = %d, want 1", len(calc.Gains))
+			}
+			g := calc.Gains[0]
+			if g.CostBasis.Cmp(money(tt.wantCost)) != 0 || g.Gain.Cmp(money(250-tt.wantCost)) != 0 {
+				t.Errorf("gain = cost %v gain %v, want cost %d", g.CostBasis, g.Gain, tt.wantCost)
+			}
+		})
+	}
+}
+
+func TestCalculate_SplitsAcrossLotsAndClassifiesTerms(t *testing.T) {
+	calc := Calculate([]*Event{
+		buy(2, 200, "2022-01-10"),
+		buy(2, 400, "2023-01-10"),
+		sell(3, 900, "2023-01-10"), // anniversary of the first lot: still short-term
+		sell(1, 500, "2024-01-11"),
+	}, usRules, MethodFIFO)
+
+	want := []struct {
+		quantity, proceeds, cost int64
+		term                     Term
+	}{
+		{2, 600, 200, TermShort},
+		{1, 300, 200, TermShort},
+		{1, 500, 200, TermLong},
+	}
+	if len(calc.Gains) != len(want) {
+		t.Fatalf("Calculate() gains = %d, want %d", len(calc.Gains), len(want))
+	}
+	for i, w := range want {
+		g := calc.Gains[i]
+		if g.Quantity.Int64() != w.quantity || g.Proceeds.Cmp(money(w.proceeds)) != 0 || g.CostBasis.Cmp(money(w.cost)) != 0 || g.Term != w.term {
+			t.Errorf("gain %d = %s for %v cost %v %s, want %d for %d cost %d %s", i, g.Quantity, g.Proceeds, g.CostBasis, g.Term, w.quantity, w.proceeds, w.cost, w.term)
+		}
+	}
+	if len(calc.Warnings) != 0 {
+		t.Errorf("Warnings = %v, want none", calc.Warnings)
+	}
+}
+
+func TestCalculate_GermanExemption(t *testing.T) {
+	calc := Calculate([]*Event{
+		buy(1, 100, "2022-01-01"),
+		buy(1, 400, "2023-01-01"),
+		sell(1, 500, "2023-06-01"),
+		sell(1, 450, "2023-06-02"),
+	}, deRules, MethodFIFO)
+
+	summary := Summarize(calc.Gains)
+	if !calc.Gains[0].Exempt || calc.Gains[1].Exempt {
+		t.Errorf("exempt = %v, %v, want only the lot held over a year exempt", calc.Gains[0].Exempt, calc.Gains[1].Exempt)
+	}
+	if summary.ExemptGain.Cmp(money(400)) != 0 || summary.TaxableGain.Cmp(money(50)) != 0 || summary.LongTermGain.Cmp(money(400)) != 0 {
+		t.Errorf("summary = exempt %v taxable %v long %v, want 400, 50 and 400", summary.ExemptGain, summary.TaxableGain, summary.LongTermGain)
+	}
+}
+
+func TestCalculate_UKPooling(t *testing.T) {
+	calc := Calculate([]*Event{
+		buy(10, 1000, "2023-01-01"),
+		buy(10, 3000, "2023-02-01"),
+		sell(5, 1500, "2023-05-10 10:00:00"),
+		buy(2, 500, "2023-05-10 15:00:00"),
+		buy(1, 400, "2023-05-20"),
+		buy(1, 100, "2023-07-01"), // more than 30 days later, goes to the pool
+		sell(19, 1900, "2023-08-01"),
+	}, ukRules, MethodPool)
+
+	want := []struct {
+		match                    Match
+		quantity, proceeds, cost int64
+	}{
+		{MatchSameDay, 2, 600, 500},
+		{MatchThirtyDay, 1, 300, 400},
+		{MatchPool, 2, 600, 400},
+		{MatchPool, 19, 1900, 3700},
+	}
+	if len(calc.Gains) != len(want) {
+		t.Fatalf("Calculate() gains = %d, want %d", len(calc.Gains), len(want))
+	}
+	for i, w := range want {
+		g := calc.Gains[i]
+		if g.Match != w.match || g.Quantity.Int64() != w.quantity || g.Proceeds.Cmp(money(w.proceeds)) != 0 || g.CostBasis.Cmp(money(w.cost)) != 0 {
+			t.Errorf("gain %d = %s %s for %v cost %v, want %s %d for %d cost %d", i, g.Match, g.Quantity, g.Proceeds, g.CostBasis, w.match, w.quantity, w.proceeds, w.cost)
+		}
+		if g.Term != "" || (g.Match == MatchPool) != (g.AcquiredAt == nil) {
+			t.Errorf("gain %d term %q acquired %v, want no term and a date unless pooled", i, g.Term, g.AcquiredAt)
+		}
+	}
+}
+
+func TestCalculate_InternalTransfers(t *testing.T) {
+	// The transfer carries the 2022 lot to the other wallet and back; only the sale realizes
+	out := event(KindTransferOut, 2, 500, "2023-03-01")
+	in := event(KindTransferIn, 2, 500, "2023-03-01")
+	in.Reference = out.Reference
+	calc := Calculate([]*Event{
+		buy(2, 200, "2022-01-01"),
+		buy(1, 400, "2023-02-01"),
+		out,
+		in,
+		sell(1, 300, "2023-04-01"),
+	}, usRules, MethodFIFO)
+
+	if len(calc.Gains) != 1 || len(calc.Carried) != 0 || len(calc.Warnings) != 0 {
+		t.Fatalf("Calculate() = %+v, want one gain, no carried lots and no warnings", calc)
+	}
+	g := calc.Gains[0]
+	if g.CostBasis.Cmp(money(100)) != 0 || g.AcquiredAt.Year() != 2022 || g.Term != TermLong {
+		t.Errorf("gain = cost %v acquired %v term %q, want the long-term 2022 lot at 100", g.CostBasis, g.AcquiredAt, g.Term)
+	}
+
+	// A transfer out to another portfolio leaves its lots for that portfolio to take
+	calc = Calculate([]*Event{buy(2, 200, "2022-01-01"), event(KindTransferOut, 3, 0, "2023-03-01")}, usRules, MethodFIFO)
+	carried := calc.Carried["transfer_out2023-03-01"]
+	if len(calc.Gains) != 0 || len(carried) != 2 || carried[0].Cost.Cmp(money(200)) != 0 || carried[1].Cost.Sign() != 0 || carried[1].AcquiredAt != nil {
+		t.Errorf("Carried = %+v, want the lot and a zero-cost rest", carried)
+	}
+
+	// A transfer in without the lots it carried is acquired at its value
+	calc = Calculate([]*Event{event(KindTransferIn, 1, 250, "2023-01-01"), sell(1, 300, "2023-02-01")}, ukRules, MethodPool)
+	if len(calc.Gains) != 1 || calc.Gains[0].CostBasis.Cmp(money(250)) != 0 || len(calc.Warnings) != 1 || !strings.Contains(calc.Warnings[0], "internal transfer") {
+		t.Errorf("Calculate() = %+v, want the pooled cost at receipt and a warning", calc)
+	}
+}
+
+func TestCalculate_Warnings(t *testing.T) {
+	unpriced := buy(1, 0, "2023-01-01")
+	unpriced.Value = nil
+	calc := Calculate([]*Event{unpriced, sell(3, 300, "2023-02-01")}, usRules, MethodFIFO)
+
+	if len(calc.Gains) != 2 || calc.Gains[1].Match != MatchUnmatched || calc.Gains[1].CostBasis.Sign() != 0 || calc.Gains[1].Term != TermShort {
+		t.Fatalf("gains = %+v, want a lot and an unmatched rest", calc.Gains)
+	}
+	if calc.Gains[0].Proceeds.Cmp(money(100)) != 0 || calc.Gains[1].Proceeds.Cmp(money(200)) != 0 {
+		t.Errorf("proceeds = %v, %v, want 100 and 200", calc.Gains[0].Proceeds, calc.Gains[1].Proceeds)
+	}
+	if len(calc.Warnings) != 2 || !strings.Contains(calc.Warnings[0], "no acquisition price") || !strings.Contains(calc.Warnings[1], "exceeds known acquisitions") {
+		t.Errorf("Warnings = %v, want a missing price and an unmatched disposal", calc.Warnings)
+	}
+}
+
+func TestNewReport_TaxYear(t *testing.T) {
+	events := []*Event{
+		buy(3, 300, "2022-01-01"),
+		sell(1, 150, "2023-04-05"), // last day of the UK tax year 2022/23
+		sell(1, 200, "2023-04-06"),
+	}
+
+	report := NewReport(Request{PortfolioID: "portfolio-1", Year: 2023, Method: MethodPool, Currency: "gbp"}, ukRules, events)
+
+	if !report.From.Equal(time.Date(2023, time.April, 6, 0, 0, 0, 0, time.UTC)) || !report.To.Equal(time.Date(2024, time.April, 6, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("tax year = %s to %s, want 2023-04-06 to 2024-04-06", report.From, report.To)
+	}
+	if len(report.Gains) != 1 || report.Summary.Disposals != 1 || report.Summary.NetGain.Cmp(money(100)) != 0 {
+		t.Errorf("report = %d gains, summary %+v, want the one disposal in the tax year with a gain of 100", len(report.Gains), report.Summary)
+	}
+}
+
+func TestNewReport_Income(t *testing.T) {
+	reward := buy(1, 120, "2023-03-01")
+	reward.Income = transaction.IncomeStakingReward
+	unpriced := buy(1, 0, "2023-04-01")
+	unpriced.Income, unpriced.Value = transaction.IncomeAirdrop, nil
+	earlier := buy(1, 80, "2022-03-01")
+	earlier.Income = transaction.IncomeStakingReward
+
+	report := NewReport(Request{PortfolioID: "portfolio-1", Year: 2023, Method: MethodFIFO, Currency: "usd"}, usRules, []*Event{
+		earlier, buy(1, 100, "2023-01-01"), reward, unpriced, sell(2, 300, "2023-05-01"),
+	})
+
+	if len(report.Income) != 2 || report.Summary.Income.Cmp(money(120)) != 0 || report.Summary.IncomeByCategory[transaction.IncomeStakingReward].Cmp(money(120)) != 0 {
+		t.Errorf("income = %d events totalling %v, want the 2023 reward of 120 and the unpriced airdrop", len(report.Income), report.Summary.Income)
+	}
+	// The 2022 reward opened the first lot at its value on receipt
+	if len(report.Gains) != 2 || report.Gains[0].CostBasis.Cmp(money(80)) != 0 || report.Gains[0].Term != TermLong {
+		t.Errorf("gains = %+v, want the reward lot matched first at a cost of 80", report.Gains)
+	}
+}
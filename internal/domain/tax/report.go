@@ -0,0 +1,140 @@
+package tax
+
+import (
+	"math/big"
+	"sort"
+	"time"
+
+	"testtask/internal/domain/transaction"
+)
+
+// Report is the capital gains report of a portfolio for one tax year
+type Report struct {
+	PortfolioID  string
+	Jurisdiction string
+	Method       Method
+	Currency     string
+	Year         int       // calendar year the tax year starts in
+	From         time.Time // inclusive
+	To           time.Time // exclusive
+	Gains        []*Gain
+	Income       []*Event // income received within the tax year
+	Summary      *Summary
+	Warnings     []string
+	GeneratedAt  time.Time
+}
+
+// Summary totals the gains of a report. Gains are net of losses; ShortTermGain and
+// LongTermGain stay zero when the jurisdiction does not classify terms.
+type Summary struct {
+	Disposals     int
+	Proceeds      *big.Int
+	CostBasis     *big.Int
+	NetGain       *big.Int
+	ShortTermGain *big.Int
+	LongTermGain  *big.Int
+	ExemptGain    *big.Int
+	TaxableGain   *big.Int // net gain without exempt gains
+
+	// Income totals the value of income received, per category and overall; unpriced
+	// income counts as zero
+	Income           *big.Int
+	IncomeByCategory map[transaction.IncomeCategory]*big.Int
+}
+
+// Request selects the report to generate. Method and Currency default to the rules'.
+type Request struct {
+	PortfolioID  string
+	Jurisdiction string
+	Year         int
+	Method       Method
+	Currency     string
+}
+
+// NewReport matches the whole event history and keeps the gains of disposals within the
+// tax year; earlier events are needed to know the cost basis
+func NewReport(req Request, rules *Rules, events []*Event) *Report {
+	from, to := rules.TaxYear(req.Year)
+	calc := Calculate(events, rules, req.Method)
+
+	gains := make([]*Gain, 0)
+	for _, g := range calc.Gains {
+		if !g.DisposedAt.Before(from) && g.DisposedAt.Before(to) {
+			gains = append(gains, g)
+		}
+	}
+	received := make([]*Event, 0)
+	for _, e := range events {
+		if e.Income != "" && e.Kind == KindAcquisition && !e.At.Before(from) && e.At.Before(to) {
+			received = append(received, e)
+		}
+	}
+	sort.SliceStable(received, func(i, j int) bool { return received[i].At.Before(received[j].At) })
+
+	summary := Summarize(gains)
+	for _, e := range received {
+		if summary.IncomeByCategory[e.Income] == nil {
+			summary.IncomeByCategory[e.Income] = new(big.Int)
+		}
+		if e.Value != nil {
+			summary.IncomeByCategory[e.Income].Add(summary.IncomeByCategory[e.Income], e.Value)
+			summary.Income.Add(summary.Income, e.Value)
+		}
+	}
+
+	return &Report{
+		PortfolioID:  req.PortfolioID,
+		Jurisdiction: rules.Code,
+		Method:       req.Method,
+		Currency:     req.Currency,
+		Year:         req.Year,
+		From:         from,
+		To:           to,
+		Gains:        gains,
+		Income:       received,
+		Summary:      summary,
+		Warnings:     calc.Warnings,
+		GeneratedAt:  time.Now().UTC(),
+	}
+}
+
+// Summarize totals gains; income is totalled by NewReport
+func Summarize(gains []*Gain) *Summary {
+	s := &Summary{
+		Proceeds:      new(big.Int),
+		CostBasis:     new(big.Int),
+		NetGain:       new(big.Int),
+		ShortTermGain: new(big.Int),
+		LongTermGain:  new(big.Int),
+		ExemptGain:    new(big.Int),
+		TaxableGain:   new(big.Int),
+
+		Income:           new(big.Int),
+		IncomeByCategory: make(map[transaction.IncomeCategory]*big.Int),
+	}
+
+	// A disposal matched against several lots has one gain per lot
+	type disposal struct {
+		asset, reference string
+		at               int64
+	}
+	disposals := make(map[disposal]bool)
+	for _, g := range gains {
+		disposals[disposal{g.Asset, g.Reference, g.DisposedAt.UnixNano()}] = true
+		s.Proceeds.Add(s.Proceeds, g.Proceeds)
+		s.CostBasis.Add(s.CostBasis, g.CostBasis)
+		s.NetGain.Add(s.NetGain, g.Gain)
+		switch g.Term {
+		case TermShort:
+			s.ShortTermGain.Add(s.ShortTermGain, g.Gain)
+		case TermLong:
+			s.LongTermGain.Add(s.LongTermGain, g.Gain)
+		}
+		if g.Exempt {
+			s.ExemptGain.Add(s.ExemptGain, g.Gain)
+		}
+	}
+	s.Disposals = len(disposals)
+	s.TaxableGain.Sub(s.NetGain, s.ExemptGain)
+	return s
+}
@@ -0,0 +1,184 @@
+package tax
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUnknownJurisdiction = errors.New("unknown jurisdiction")
+	ErrInvalidRules        = errors.New("invalid tax rules")
+	ErrInvalidReport       = errors.New("invalid tax report request")
+)
+
+// Method is how disposals are matched against earlier acquisitions
+type Method string
+
+const (
+	// MethodFIFO matches the oldest lots first
+	MethodFIFO Method = "fifo"
+	// MethodLIFO matches the newest lots first
+	MethodLIFO Method = "lifo"
+	// MethodHIFO matches the lots with the highest unit cost first
+	MethodHIFO Method = "hifo"
+	// MethodPool applies UK share pooling: same-day acquisitions, then acquisitions in the
+	// following 30 days, then the section 104 pool at average cost
+	MethodPool Method = "pool"
+)
+
+// ParseMethod validates a matching method
+func ParseMethod(s string) (Method, error) {
+	switch m := Method(strings.ToLower(s)); m {
+	case MethodFIFO, MethodLIFO, MethodHIFO, MethodPool:
+		return m, nil
+	}
+	return "", fmt.Errorf("%w: unknown method %q", ErrInvalidReport, s)
+}
+
+// Term classifies a gain by how long the asset was held
+type Term string
+
+const (
+	TermShort Term = "short"
+	TermLong  Term = "long"
+)
+
+// Rules are the capital gains rules of a jurisdiction
+type Rules struct {
+	Code     string // e.g. "US"
+	Name     string
+	Currency string // currency gains are reported in
+	Method   Method // default matching method
+	// AllowedMethods are the methods a report may choose instead of Method
+	AllowedMethods []Method
+	// LongTermYears is the holding period after which a gain is long-term: a disposal
+	// later than this many years after the acquisition. Zero means every gain is classified
+	// alike and no term is reported.
+	LongTermYears int
+	// LongTermExempt makes long-term gains and losses tax free, as the German rule for
+	// private sales held for more than one year
+	LongTermExempt bool
+	// YearStartMonth and YearStartDay are the first day of the tax year, e.g. 6 April in the UK
+	YearStartMonth time.Month
+	YearStartDay   int
+}
+
+// Validate checks the rules are complete and consistent
+func (r *Rules) Validate() error {
+	if r.Code == "" {
+		return fmt.Errorf("%w: code is required", ErrInvalidRules)
+	}
+	if r.Currency == "" {
+		return fmt.Errorf("%w: %s: currency is required", ErrInvalidRules, r.Code)
+	}
+	if _, err := ParseMethod(string(r.Method)); err != nil {
+		return fmt.Errorf("%w: %s: unknown method %q", ErrInvalidRules, r.Code, r.Method)
+	}
+	for _, m := range r.AllowedMethods {
+		if _, err := ParseMethod(string(m)); err != nil {
+			return fmt.Errorf("%w: %s: unknown method %q", ErrInvalidRules, r.Code, m)
+		}
+		if m == MethodPool && r.LongTermYears > 0 {
+			return fmt.Errorf("%w: %s: pooling cannot classify long-term gains", ErrInvalidRules, r.Code)
+		}
+	}
+	if r.LongTermYears < 0 {
+		return fmt.Errorf("%w: %s: long-term holding period must not be negative", ErrInvalidRules, r.Code)
+	}
+	if r.Method == MethodPool && r.LongTermYears > 0 {
+		return fmt.Errorf("%w: %s: pooling cannot classify long-term gains", ErrInvalidRules, r.Code)
+	}
+	if r.LongTermExempt && r.LongTermYears == 0 {
+		return fmt.Errorf("%w: %s: long-term exemption requires a holding period", ErrInvalidRules, r.Code)
+	}
+	start := time.Date(2001, r.YearStartMonth, r.YearStartDay, 0, 0, 0, 0, time.UTC)
+	if r.YearStartMonth < time.January || r.YearStartMonth > time.December || start.Month() != r.YearStartMonth || start.Day() != r.YearStartDay {
+		return fmt.Errorf("%w: %s: invalid tax year start", ErrInvalidRules, r.Code)
+	}
+	return nil
+}
+
+// Allows reports whether a report may use the method
+func (r *Rules) Allows(m Method) bool {
+	if m == r.Method {
+		return true
+	}
+	for _, allowed := range r.AllowedMethods {
+		if allowed == m {
+			return true
+		}
+	}
+	return false
+}
+
+// TaxYear returns the tax year starting in the given calendar year as [from, to)
+func (r *Rules) TaxYear(year int) (from, to time.Time) {
+	from = time.Date(year, r.YearStartMonth, r.YearStartDay, 0, 0, 0, 0, time.UTC)
+	return from, from.AddDate(1, 0, 0)
+}
+
+// Classify returns the term of a gain and whether it is exempt. Without a holding period
+// the term is empty.
+func (r *Rules) Classify(acquiredAt, disposedAt time.Time) (Term, bool) {
+	if r.LongTermYears == 0 {
+		return "", false
+	}
+	// Holding periods count whole days: an asset sold on the anniversary is still short-term
+	threshold := day(acquiredAt).AddDate(r.LongTermYears, 0, 0)
+	if day(disposedAt).After(threshold) {
+		return TermLong, r.LongTermExempt
+	}
+	return TermShort, false
+}
+
+// RuleSet holds the rules of the supported jurisdictions
+type RuleSet struct {
+	rules map[string]*Rules
+}
+
+// NewRuleSet validates the rules and indexes them by upper-case code
+func NewRuleSet(rules []*Rules) (*RuleSet, error) {
+	set := &RuleSet{rules: make(map[string]*Rules, len(rules))}
+	for _, r := range rules {
+		r.Code = strings.ToUpper(strings.TrimSpace(r.Code))
+		r.Currency = strings.ToLower(strings.TrimSpace(r.Currency))
+		if err := r.Validate(); err != nil {
+			return nil, err
+		}
+		if _, exists := set.rules[r.Code]; exists {
+			return nil, fmt.Errorf("%w: duplicate jurisdiction %s", ErrInvalidRules, r.Code)
+		}
+		set.rules[r.Code] = r
+	}
+	return set, nil
+}
+
+// Lookup returns the rules of a jurisdiction, matching the code case-insensitively
+func (s *RuleSet) Lookup(code string) (*Rules, error) {
+	if s != nil {
+		if r, ok := s.rules[strings.ToUpper(strings.TrimSpace(code))]; ok {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownJurisdiction, code)
+}
+
+// All returns the rules of every jurisdiction ordered by code
+func (s *RuleSet) All() []*Rules {
+	if s == nil {
+		return nil
+	}
+	all := make([]*Rules, 0, len(s.rules))
+	for _, r := range s.rules {
+		all = append(all, r)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Code < all[j].Code })
+	return all
+}
+
+func day(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
@@ -0,0 +1,69 @@
+package tax
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRules_Classify(t *testing.T) {
+	acquired := time.Date(2023, time.March, 1, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		rules      *Rules
+		disposed   time.Time
+		wantTerm   Term
+		wantExempt bool
+	}{
+		{"US same year", usRules, time.Date(2023, time.December, 31, 0, 0, 0, 0, time.UTC), TermShort, false},
+		{"US on anniversary", usRules, time.Date(2024, time.March, 1, 23, 0, 0, 0, time.UTC), TermShort, false},
+		{"US day after anniversary", usRules, time.Date(2024, time.March, 2, 0, 0, 0, 0, time.UTC), TermLong, false},
+		{"DE over a year", deRules, time.Date(2024, time.March, 2, 0, 0, 0, 0, time.UTC), TermLong, true},
+		{"UK without terms", ukRules, time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC), "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			term, exempt := tt.rules.Classify(acquired, tt.disposed)
+			if term != tt.wantTerm || exempt != tt.wantExempt {
+				t.Errorf("Classify() = %q, %v, want %q, %v", term, exempt, tt.wantTerm, tt.wantExempt)
+			}
+		})
+	}
+}
+
+func TestNewRuleSet(t *testing.T) {
+	set, err := NewRuleSet([]*Rules{
+		{Code: "us", Currency: "USD", Method: MethodFIFO, LongTermYears: 1, YearStartMonth: time.January, YearStartDay: 1},
+		{Code: "UK", Currency: "gbp", Method: MethodPool, YearStartMonth: time.April, YearStartDay: 6},
+	})
+	if err != nil {
+		t.Fatalf("NewRuleSet() error = %v", err)
+	}
+	us, err := set.Lookup("Us")
+	if err != nil || us.Code != "US" || us.Currency != "usd" {
+		t.Errorf("Lookup(Us) = %+v, %v, want normalized US rules", us, err)
+	}
+	if _, err := set.Lookup("FR"); !errors.Is(err, ErrUnknownJurisdiction) {
+		t.Errorf("Lookup(FR) error = %v, want ErrUnknownJurisdiction", err)
+	}
+	if all := set.All(); len(all) != 2 || all[0].Code != "UK" {
+		t.Errorf("All() = %v, want UK and US by code", all)
+	}
+
+	invalid := [][]*Rules{
+		{{Code: "XX", Currency: "usd", Method: MethodPool, LongTermYears: 1, YearStartMonth: time.January, YearStartDay: 1}},
+		{{Code: "XX", Currency: "usd", Method: MethodFIFO, LongTermExempt: true, YearStartMonth: time.January, YearStartDay: 1}},
+		{{Code: "XX", Currency: "usd", Method: MethodFIFO, YearStartMonth: time.February, YearStartDay: 30}},
+		{{Code: "XX", Currency: "usd", Method: "average", YearStartMonth: time.January, YearStartDay: 1}},
+		{
+			{Code: "XX", Currency: "usd", Method: MethodFIFO, YearStartMonth: time.January, YearStartDay: 1},
+			{Code: "xx", Currency: "usd", Method: MethodFIFO, YearStartMonth: time.January, YearStartDay: 1},
+		},
+	}
+	for i, rules := range invalid {
+		if _, err := NewRuleSet(rules); !errors.Is(err, ErrInvalidRules) {
+			t.Errorf("NewRuleSet(invalid %d) error = %v, want ErrInvalidRules", i, err)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidAliases = errors.New("invalid alias registry")
+
+// NativeAsset describes the native coin of a chain. It has no contract, so it is
+// priced through PriceProxy, usually the wrapped token's contract.
+type NativeAsset struct {
+	ID         string
+	Name       string
+	Symbol     string
+	Decimal    uint8
+	PriceProxy string
+}
+
+// AliasGroup is a set of tokens that represent the same underlying asset,
+// such as ETH, WETH and stETH. Members are lowercase contract addresses;
+// ZeroAddress stands for the native asset.
+type AliasGroup struct {
+	ID      string
+	Name    string
+	Symbol  string
+	Decimal uint8
+	Members []string
+}
+
+// ChainAliases holds the native asset and the equivalence groups of one chain
+type ChainAliases struct {
+	ChainID string
+	Native  *NativeAsset
+	Groups  []*AliasGroup
+}
+
+// AliasRegistry resolves native assets and equivalent tokens per chain.
+// It is read-only after construction and safe for concurrent use.
+type AliasRegistry struct {
+	chains map[string]*ChainAliases
+	groups map[string]map[string]*AliasGroup // chain ID -> member address -> group
+}
+
+// NewAliasRegistry validates the chain definitions and indexes the group members.
+// A token may belong to at most one group per chain.
+func NewAliasRegistry(chains []*ChainAliases) (*AliasRegistry, error) {
+	r := &AliasRegistry{
+		chains: make(map[string]*ChainAliases, len(chains)),
+		groups: make(map[string]map[string]*AliasGroup, len(chains)),
+	}
+
+	for _, c := range chains {
+		if c.ChainID == "" {
+			return nil, fmt.Errorf("%w: chain ID is required", ErrInvalidAliases)
+		}
+		if _, exists := r.chains[c.ChainID]; exists {
+			return nil, fmt.Errorf("%w: chain %s defined twice", ErrInvalidAliases, c.ChainID)
+		}
+		if c.Native != nil {
+			if !IsAddress(c.Native.PriceProxy) {
+				return nil, fmt.Errorf("%w: chain %s native price proxy %q is not an address", ErrInvalidAliases, c.ChainID, c.Native.PriceProxy)
+			}
+			c.Native.PriceProxy = strings.ToLower(c.Native.PriceProxy)
+		}
+
+		members := make(map[string]*AliasGroup)
+		for _, g := range c.Groups {
+			if g.ID == "" || len(g.Members) < 2 {
+				return nil, fmt.Errorf("%w: chain %s group %q needs an ID and at least two members", ErrInvalidAliases, c.ChainID, g.ID)
+			}
+			for i, addr := range g.Members {
+				if !IsAddress(addr) {
+					return nil, fmt.Errorf("%w: chain %s group %s member %q is not an address", ErrInvalidAliases, c.ChainID, g.ID, addr)
+				}
+				addr = strings.ToLower(addr)
+				if other, exists := members[addr]; exists {
+					return nil, fmt.Errorf("%w: chain %s token %s is in groups %s and %s", ErrInvalidAliases, c.ChainID, addr, other.ID, g.ID)
+				}
+				g.Members[i] = addr
+				members[addr] = g
+			}
+		}
+
+		r.chains[c.ChainID] = c
+		r.groups[c.ChainID] = members
+	}
+
+	return r, nil
+}
+
+// Native returns the native asset of a chain
+func (r *AliasRegistry) Native(chainID string) (*NativeAsset, bool) {
+	if r == nil {
+		return nil, false
+	}
+	c, ok := r.chains[chainID]
+	if !ok || c.Native == nil {
+		return nil, false
+	}
+	return c.Native, true
+}
+
+// NativeToken returns the native asset of a chain as a token at ZeroAddress
+func (r *AliasRegistry) NativeToken(chainID string) (*Token, bool) {
+	native, ok := r.Native(chainID)
+	if !ok {
+		return nil, false
+	}
+	return &Token{
+		ID:      native.ID,
+		Name:    native.Name,
+		Symbol:  native.Symbol,
+		Address: ZeroAddress,
+		Decimal: native.Decimal,
+	}, true
+}
+
+// Group returns the equivalence group a token belongs to on a chain
+func (r *AliasRegistry) Group(chainID, address string) (*AliasGroup, bool) {
+	if r == nil {
+		return nil, false
+	}
+	g, ok := r.groups[chainID][strings.ToLower(address)]
+	return g, ok
+}
+
+// GroupBySymbol returns the equivalence group of a chain with the given symbol, ignoring case
+func (r *AliasRegistry) GroupBySymbol(chainID, symbol string) (*AliasGroup, bool) {
+	if r == nil || r.chains[chainID] == nil {
+		return nil, false
+	}
+	for _, g := range r.chains[chainID].Groups {
+		if g.Symbol != "" && strings.EqualFold(g.Symbol, symbol) {
+			return g, true
+		}
+	}
+	return nil, false
+}
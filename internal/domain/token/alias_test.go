@@ -0,0 +1,101 @@
+package token
+
+import (
+	"errors"
+	"testing"
+)
+
+const (
+	wethAddress  = "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"
+	stethAddress = "0xae7ab96520de3a18e5e111b5eaab095312d7fe84"
+)
+
+func TestNewAliasRegistry(t *testing.T) {
+	tests := []struct {
+		name    string
+		chains  []*ChainAliases
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			chains: []*ChainAliases{{
+				ChainID: "1",
+				Native:  &NativeAsset{ID: "ethereum", Symbol: "ETH", Decimal: 18, PriceProxy: "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"},
+				Groups:  []*AliasGroup{{ID: "eth", Members: []string{ZeroAddress, wethAddress, stethAddress}}},
+			}},
+		},
+		{
+			name:    "missing chain ID",
+			chains:  []*ChainAliases{{}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid price proxy",
+			chains:  []*ChainAliases{{ChainID: "1", Native: &NativeAsset{PriceProxy: "weth"}}},
+			wantErr: true,
+		},
+		{
+			name:    "single member group",
+			chains:  []*ChainAliases{{ChainID: "1", Groups: []*AliasGroup{{ID: "eth", Members: []string{wethAddress}}}}},
+			wantErr: true,
+		},
+		{
+			name: "token in two groups",
+			chains: []*ChainAliases{{ChainID: "1", Groups: []*AliasGroup{
+				{ID: "eth", Members: []string{ZeroAddress, wethAddress}},
+				{ID: "steth", Members: []string{stethAddress, wethAddress}},
+			}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAliasRegistry(tt.chains)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewAliasRegistry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidAliases) {
+				t.Errorf("NewAliasRegistry() error = %v, want ErrInvalidAliases", err)
+			}
+		})
+	}
+}
+
+func TestAliasRegistry_Lookup(t *testing.T) {
+	registry, err := NewAliasRegistry([]*ChainAliases{{
+		ChainID: "1",
+		Native:  &NativeAsset{ID: "ethereum", Name: "Ethereum", Symbol: "ETH", Decimal: 18, PriceProxy: wethAddress},
+		Groups:  []*AliasGroup{{ID: "eth", Symbol: "ETH", Members: []string{ZeroAddress, wethAddress, stethAddress}}},
+	}})
+	if err != nil {
+		t.Fatalf("NewAliasRegistry() error = %v", err)
+	}
+
+	native, ok := registry.NativeToken("1")
+	if !ok || native.Address != ZeroAddress || native.Symbol != "ETH" {
+		t.Errorf("NativeToken(1) = %+v, %v", native, ok)
+	}
+	if _, ok := registry.NativeToken("10"); ok {
+		t.Error("NativeToken(10) should not be configured")
+	}
+
+	if g, ok := registry.Group("1", "0xAE7AB96520DE3A18E5E111B5EAAB095312D7FE84"); !ok || g.ID != "eth" {
+		t.Errorf("Group(stETH) = %+v, %v, want eth", g, ok)
+	}
+	if _, ok := registry.Group("42161", wethAddress); ok {
+		t.Error("groups must be scoped to their chain")
+	}
+
+	if g, ok := registry.GroupBySymbol("1", "eth"); !ok || g.ID != "eth" {
+		t.Errorf("GroupBySymbol(eth) = %+v, %v, want eth", g, ok)
+	}
+	if _, ok := registry.GroupBySymbol("1", "BTC"); ok {
+		t.Error("GroupBySymbol(BTC) should not match")
+	}
+
+	var empty *AliasRegistry
+	if _, ok := empty.Group("1", wethAddress); ok {
+		t.Error("nil registry should have no groups")
+	}
+}
@@ -0,0 +1,88 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrCustomTokenNotFound = errors.New("custom token not found")
+	ErrCustomTokenExists   = errors.New("custom token address already registered")
+	ErrInvalidCustomToken  = errors.New("invalid custom token")
+)
+
+// SourceCustom marks tokens defined by users rather than a token list or transfer history
+const SourceCustom = "custom"
+
+// CustomToken is a user-defined token, visible globally or only inside one portfolio.
+// A contract address can be registered once per chain globally and once per chain in
+// each portfolio; a portfolio's own token shadows a global one with the same address.
+type CustomToken struct {
+	ID          string
+	PortfolioID string // empty for global tokens
+	OwnerID     string // user who created the token; global tokens are managed by their owner or an admin
+	ChainID     string
+	Token       *Token
+
+	// Manual price feed; ManualPrice is nil when the token has no price
+	ManualPrice    *big.Int // scaled by price.CurrencyDecimal
+	PriceCurrency  string
+	PriceUpdatedAt time.Time
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type CustomRepository interface {
+	Create(ctx context.Context, t *CustomToken) error
+	Update(ctx context.Context, t *CustomToken) error
+	Delete(ctx context.Context, id string) error
+	GetByID(ctx context.Context, id string) (*CustomToken, error)
+	// List returns global tokens and, when portfolioID is set, tokens scoped to that portfolio.
+	List(ctx context.Context, portfolioID string) ([]*CustomToken, error)
+	// GetByAddresses returns global tokens and the tokens of portfolioID by lowercase
+	// address, a portfolio's token shadowing a global one. AnyPortfolio matches the
+	// tokens of every portfolio visible to the caller.
+	GetByAddresses(ctx context.Context, portfolioID string, addresses []string) (map[string]*CustomToken, error)
+}
+
+// AnyPortfolio makes CustomRepository.GetByAddresses match tokens of any visible portfolio
+const AnyPortfolio = "*"
+
+func NewCustomToken(portfolioID, chainID string, t *Token) *CustomToken {
+	now := time.Now()
+	t.Address = strings.ToLower(t.Address)
+	return &CustomToken{
+		ID:          uuid.New().String(),
+		PortfolioID: portfolioID,
+		ChainID:     chainID,
+		Token:       t,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// VisibleTo reports whether the token can be used in the given portfolio
+func (c *CustomToken) VisibleTo(portfolioID string) bool {
+	return c.PortfolioID == "" || c.PortfolioID == portfolioID
+}
+
+// SetManualPrice sets or, with a nil value, clears the manual price feed
+func (c *CustomToken) SetManualPrice(value *big.Int, currency string) {
+	now := time.Now()
+	c.UpdatedAt = now
+	if value == nil {
+		c.ManualPrice = nil
+		c.PriceCurrency = ""
+		c.PriceUpdatedAt = time.Time{}
+		return
+	}
+	c.ManualPrice = new(big.Int).Set(value)
+	c.PriceCurrency = strings.ToLower(currency)
+	c.PriceUpdatedAt = now
+}
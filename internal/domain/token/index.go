@@ -0,0 +1,250 @@
+package token
+
+import (
+	"sort"
+	"strings"
+)
+
+// Match scores; higher ranks first
+const (
+	scoreExact        = 100
+	scoreSymbolPrefix = 80
+	scoreAddrPrefix   = 70
+	scoreNamePrefix   = 60
+	scoreWordPrefix   = 50
+	scoreFuzzyMax     = 40
+
+	// minFuzzySimilarity is the trigram Jaccard similarity below which fuzzy matches are dropped
+	minFuzzySimilarity = 0.3
+)
+
+type keyKind uint8
+
+const (
+	keySymbol keyKind = iota
+	keyName
+	keyWord
+	keyAddress
+)
+
+type indexKey struct {
+	value string
+	kind  keyKind
+	token int
+}
+
+type fuzzyKey struct {
+	token    int
+	trigrams int
+}
+
+// Index is an immutable in-memory search index over a token list.
+// Prefix lookups binary-search a sorted key list; fuzzy lookups use trigram posting lists.
+type Index struct {
+	tokens   []*Token
+	keys     []indexKey
+	fuzzy    []fuzzyKey
+	postings map[string][]int // trigram -> fuzzy key positions
+}
+
+// SearchResult is a ranked index match
+type SearchResult struct {
+	Token *Token
+	Score int
+}
+
+func NewIndex(tokens []*Token) *Index {
+	idx := &Index{
+		tokens:   make([]*Token, 0, len(tokens)),
+		postings: make(map[string][]int),
+	}
+
+	for _, t := range tokens {
+		if t != nil {
+			idx.tokens = append(idx.tokens, t)
+		}
+	}
+	sort.SliceStable(idx.tokens, func(i, j int) bool {
+		return lessTokens(idx.tokens[i], idx.tokens[j])
+	})
+
+	for i, t := range idx.tokens {
+		symbol := strings.ToLower(t.Symbol)
+		name := strings.ToLower(t.Name)
+
+		idx.addKey(symbol, keySymbol, i)
+		idx.addKey(name, keyName, i)
+		idx.addKey(strings.ToLower(t.Address), keyAddress, i)
+		if words := strings.Fields(name); len(words) > 1 {
+			for _, w := range words[1:] {
+				idx.addKey(w, keyWord, i)
+			}
+		}
+
+		idx.addFuzzy(symbol, i)
+		if name != symbol {
+			idx.addFuzzy(name, i)
+		}
+	}
+
+	sort.Slice(idx.keys, func(i, j int) bool {
+		return idx.keys[i].value < idx.keys[j].value
+	})
+
+	return idx
+}
+
+func (idx *Index) addKey(value string, kind keyKind, token int) {
+	if value == "" {
+		return
+	}
+	idx.keys = append(idx.keys, indexKey{value: value, kind: kind, token: token})
+}
+
+func (idx *Index) addFuzzy(value string, token int) {
+	grams := trigrams(value)
+	if len(grams) == 0 {
+		return
+	}
+	pos := len(idx.fuzzy)
+	idx.fuzzy = append(idx.fuzzy, fuzzyKey{token: token, trigrams: len(grams)})
+	for g := range grams {
+		idx.postings[g] = append(idx.postings[g], pos)
+	}
+}
+
+// Len returns the number of indexed tokens
+func (idx *Index) Len() int {
+	return len(idx.tokens)
+}
+
+// Tokens returns all indexed tokens in the order used to break ranking ties
+func (idx *Index) Tokens() []*Token {
+	return idx.tokens
+}
+
+// Search returns tokens matching the query by symbol, name or address,
+// ranked by match quality. Exact and prefix matches always outrank fuzzy ones.
+func (idx *Index) Search(query string) []SearchResult {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil
+	}
+
+	scores := make(map[int]int)
+	record := func(token, score int) {
+		if score > scores[token] {
+			scores[token] = score
+		}
+	}
+
+	start := sort.Search(len(idx.keys), func(i int) bool {
+		return idx.keys[i].value >= query
+	})
+	for i := start; i < len(idx.keys) && strings.HasPrefix(idx.keys[i].value, query); i++ {
+		key := idx.keys[i]
+		if key.value == query && key.kind != keyWord {
+			record(key.token, scoreExact)
+			continue
+		}
+		switch key.kind {
+		case keySymbol:
+			record(key.token, scoreSymbolPrefix)
+		case keyAddress:
+			record(key.token, scoreAddrPrefix)
+		case keyName:
+			record(key.token, scoreNamePrefix)
+		case keyWord:
+			record(key.token, scoreWordPrefix)
+		}
+	}
+
+	// Addresses are not fuzzy matched: a typo in hex is a different token
+	if !strings.HasPrefix(query, "0x") {
+		for token, similarity := range idx.fuzzyMatch(query) {
+			record(token, int(similarity*scoreFuzzyMax))
+		}
+	}
+
+	results := make([]SearchResult, 0, len(scores))
+	for token, score := range scores {
+		results = append(results, SearchResult{Token: idx.tokens[token], Score: score})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return lessTokens(results[i].Token, results[j].Token)
+	})
+
+	return results
+}
+
+// BySymbol returns the tokens whose symbol equals the given one, ignoring case
+func (idx *Index) BySymbol(symbol string) []*Token {
+	symbol = strings.ToLower(strings.TrimSpace(symbol))
+	if symbol == "" {
+		return nil
+	}
+
+	var matches []*Token
+	start := sort.Search(len(idx.keys), func(i int) bool {
+		return idx.keys[i].value >= symbol
+	})
+	for i := start; i < len(idx.keys) && idx.keys[i].value == symbol; i++ {
+		if idx.keys[i].kind == keySymbol {
+			matches = append(matches, idx.tokens[idx.keys[i].token])
+		}
+	}
+	return matches
+}
+
+// fuzzyMatch returns the best trigram similarity per token above minFuzzySimilarity
+func (idx *Index) fuzzyMatch(query string) map[int]float64 {
+	grams := trigrams(query)
+	if len(grams) == 0 {
+		return nil
+	}
+
+	shared := make(map[int]int)
+	for g := range grams {
+		for _, pos := range idx.postings[g] {
+			shared[pos]++
+		}
+	}
+
+	best := make(map[int]float64)
+	for pos, n := range shared {
+		key := idx.fuzzy[pos]
+		similarity := float64(n) / float64(len(grams)+key.trigrams-n)
+		if similarity >= minFuzzySimilarity && similarity > best[key.token] {
+			best[key.token] = similarity
+		}
+	}
+
+	return best
+}
+
+// trigrams returns the set of padded 3-character substrings of s
+func trigrams(s string) map[string]struct{} {
+	if s == "" {
+		return nil
+	}
+	runes := []rune(" " + s + " ")
+	grams := make(map[string]struct{}, len(runes))
+	for i := 0; i+3 <= len(runes); i++ {
+		grams[string(runes[i:i+3])] = struct{}{}
+	}
+	return grams
+}
+
+// lessTokens orders shorter symbols first so that "ETH" ranks above "ETHX" on equal scores
+func lessTokens(a, b *Token) bool {
+	if len(a.Symbol) != len(b.Symbol) {
+		return len(a.Symbol) < len(b.Symbol)
+	}
+	if a.Symbol != b.Symbol {
+		return a.Symbol < b.Symbol
+	}
+	return a.Address < b.Address
+}
@@ -0,0 +1,90 @@
+package token
+
+import "testing"
+
+func testTokens() []*Token {
+	return []*Token{
+		{Symbol: "ETHX", Name: "Stader ETHx", Address: "0xa35b1b31ce002fbf2058d22f30f95d405200a15b"},
+		{Symbol: "ETH", Name: "Ethereum", Address: "0x0000000000000000000000000000000000000000"},
+		{Symbol: "WETH", Name: "Wrapped Ether", Address: "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"},
+		{Symbol: "USDC", Name: "USD Coin", Address: "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"},
+		{Symbol: "WBTC", Name: "Wrapped Bitcoin", Address: "0x2260fac5e5542a773aa44fbc8cfe7ed6a2a8e0e3"},
+	}
+}
+
+func TestIndex_Search(t *testing.T) {
+	idx := NewIndex(testTokens())
+
+	tests := []struct {
+		name      string
+		query     string
+		wantFirst string
+		wantAll   []string // expected symbols in rank order, nil to only check the first
+	}{
+		{name: "exact symbol ranks first", query: "eth", wantFirst: "ETH", wantAll: []string{"ETH", "ETHX", "WETH"}},
+		{name: "case insensitive", query: "UsDc", wantFirst: "USDC", wantAll: []string{"USDC"}},
+		{name: "name prefix ties by symbol", query: "wrapped", wantFirst: "WBTC", wantAll: []string{"WBTC", "WETH"}},
+		{name: "name word prefix", query: "bitc", wantFirst: "WBTC", wantAll: []string{"WBTC"}},
+		{name: "address prefix", query: "0xc02a", wantFirst: "WETH", wantAll: []string{"WETH"}},
+		{name: "full address", query: "0x2260FAC5E5542a773Aa44fBCfeDf7C193bc2C599", wantAll: []string{}},
+		{name: "fuzzy name with typo", query: "etherum", wantFirst: "ETH"},
+		{name: "no match", query: "zzzz", wantAll: []string{}},
+		{name: "empty query", query: "  ", wantAll: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := idx.Search(tt.query)
+
+			if tt.wantFirst != "" {
+				if len(results) == 0 {
+					t.Fatalf("Search(%q) returned no results, want %s first", tt.query, tt.wantFirst)
+				}
+				if results[0].Token.Symbol != tt.wantFirst {
+					t.Errorf("Search(%q) first = %s, want %s", tt.query, results[0].Token.Symbol, tt.wantFirst)
+				}
+			}
+
+			if tt.wantAll != nil {
+				got := make([]string, len(results))
+				for i, r := range results {
+					got[i] = r.Token.Symbol
+				}
+				if len(got) != len(tt.wantAll) {
+					t.Fatalf("Search(%q) = %v, want %v", tt.query, got, tt.wantAll)
+				}
+				for i := range got {
+					if got[i] != tt.wantAll[i] {
+						t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.wantAll)
+						break
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestIndex_Tokens(t *testing.T) {
+	idx := NewIndex(append(testTokens(), nil))
+
+	if idx.Len() != 5 {
+		t.Fatalf("Len() = %d, want 5", idx.Len())
+	}
+	if first := idx.Tokens()[0].Symbol; first != "ETH" {
+		t.Errorf("Tokens()[0] = %s, want ETH", first)
+	}
+}
+
+func TestIndex_BySymbol(t *testing.T) {
+	idx := NewIndex(testTokens())
+
+	if got := idx.BySymbol("weth"); len(got) != 1 || got[0].Symbol != "WETH" {
+		t.Errorf("BySymbol(weth) = %v, want [WETH]", got)
+	}
+	// Names and prefixes do not match
+	for _, symbol := range []string{"ethereum", "ET", ""} {
+		if got := idx.BySymbol(symbol); len(got) != 0 {
+			t.Errorf("BySymbol(%q) = %v, want none", symbol, got)
+		}
+	}
+}
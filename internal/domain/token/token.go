@@ -2,9 +2,28 @@ package token
 
 import (
 	"context"
+	"errors"
+	"regexp"
+	"time"
 )
 
-const ZeroAddress = "0x0000000000000000000000000000000000000"
+// ZeroAddress stands for the native asset of a chain in balances and holdings
+const ZeroAddress = "0x0000000000000000000000000000000000000000"
+
+var ErrTokenNotFound = errors.New("token not found")
+
+var addressPattern = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+
+// IsAddress reports whether s is a 20-byte hex contract address
+func IsAddress(s string) bool {
+	return addressPattern.MatchString(s)
+}
+
+// Token sources recorded by the registry
+const (
+	SourceStatic     = "static"     // loaded from the bundled token list
+	SourceDiscovered = "discovered" // first seen in transfer history
+)
 
 type Token struct {
 	ID      string
@@ -12,6 +31,7 @@ type Token struct {
 	Symbol  string
 	Address string
 	Decimal uint8
+	LogoURI string
 }
 
 type Repository interface {
@@ -19,3 +39,56 @@ type Repository interface {
 	GetByAddress(ctx context.Context, address string) (*Token, error)
 	GetByAddresses(ctx context.Context, addresses []string) map[string]*Token
 }
+
+// Registry is a persistent token repository that can learn new tokens.
+type Registry interface {
+	Repository
+	// Register inserts tokens that are not registered yet and returns how many were added.
+	Register(ctx context.Context, tokens []*Token, source string) (int, error)
+	// ListUnresolved returns tokens missing a name or CoinGecko ID that were not refreshed since the given time.
+	ListUnresolved(ctx context.Context, refreshedBefore time.Time, limit int) ([]*Token, error)
+	// UpdateMetadata stores refreshed metadata; a nil metadata only marks the token as refreshed.
+	UpdateMetadata(ctx context.Context, address string, metadata *Token) error
+	// Sync upserts a complete token list from the given source in one transaction.
+	Sync(ctx context.Context, tokens []*Token, source string) (SyncStats, error)
+}
+
+// SyncStats counts the effect of a registry sync
+type SyncStats struct {
+	Added     int
+	Updated   int
+	Unchanged int
+	Stale     int // registered from the same source but missing from the synced list; kept as is
+}
+
+// ListSource is a bundled token list that can change while the application runs
+type ListSource interface {
+	Load(ctx context.Context) ([]*Token, error)
+	ModTime() (time.Time, error)
+	Location() string
+}
+
+// Reload triggers
+const (
+	ReloadTriggerStartup    = "startup"
+	ReloadTriggerSignal     = "signal"
+	ReloadTriggerFileChange = "file_change"
+	ReloadTriggerAPI        = "api"
+)
+
+// ReloadResult describes one reload of the bundled token list into the registry
+type ReloadResult struct {
+	Trigger   string
+	Source    string
+	StartedAt time.Time
+	Duration  time.Duration
+	Loaded    int
+	SyncStats
+	Indexed int
+	Err     error
+}
+
+// MetadataProvider resolves token metadata by contract address.
+type MetadataProvider interface {
+	GetMetadata(ctx context.Context, address string) (*Token, error)
+}
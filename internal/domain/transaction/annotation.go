@@ -0,0 +1,191 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidAnnotation         = errors.New("invalid transaction annotation")
+	ErrAnnotationNotFound        = errors.New("transaction annotation not found")
+	ErrInvalidManualTransaction  = errors.New("invalid manual transaction")
+	ErrManualTransactionNotFound = errors.New("manual transaction not found")
+)
+
+// SourceManual marks transactions entered by hand
+const SourceManual = "manual"
+
+// Limits of user supplied annotation fields
+const (
+	MaxNoteLength     = 2000
+	MaxTags           = 20
+	MaxTagLength      = 50
+	MaxCategoryLength = 100
+)
+
+// ValidType reports whether t is a known transaction type
+func ValidType(t TransactionType) bool {
+	switch t {
+	case TransactionTypeSend, TransactionTypeReceive, TransactionTypeSwap, TransactionTypeStake, TransactionTypeInternalTransfer:
+		return true
+	}
+	return false
+}
+
+// Annotation holds what a user attached to a transaction of a portfolio. It is stored apart
+// from provider data, so syncing again keeps it.
+type Annotation struct {
+	PortfolioID   string
+	TransactionID string
+	Note          string
+	Tags          []string        // lowercase, unique, sorted
+	Category      string          // free-form, e.g. "rent" or "gift"
+	TypeOverride  TransactionType // replaces the detected type, empty keeps it
+	UpdatedAt     time.Time
+}
+
+// Normalize trims the fields and lowercases, dedupes and sorts the tags
+func (a *Annotation) Normalize() {
+	a.TransactionID = strings.TrimSpace(a.TransactionID)
+	a.Note = strings.TrimSpace(a.Note)
+	a.Category = strings.TrimSpace(a.Category)
+	a.TypeOverride = TransactionType(strings.ToLower(strings.TrimSpace(string(a.TypeOverride))))
+	a.Tags = NormalizeTags(a.Tags)
+}
+
+// Validate checks the annotation after Normalize
+func (a *Annotation) Validate() error {
+	if a.TransactionID == "" {
+		return fmt.Errorf("%w: transaction_id is required", ErrInvalidAnnotation)
+	}
+	if len(a.Note) > MaxNoteLength {
+		return fmt.Errorf("%w: note exceeds %d characters", ErrInvalidAnnotation, MaxNoteLength)
+	}
+	if len(a.Category) > MaxCategoryLength {
+		return fmt.Errorf("%w: category exceeds %d characters", ErrInvalidAnnotation, MaxCategoryLength)
+	}
+	if len(a.Tags) > MaxTags {
+		return fmt.Errorf("%w: at most %d tags are allowed", ErrInvalidAnnotation, MaxTags)
+	}
+	for _, tag := range a.Tags {
+		if len(tag) > MaxTagLength {
+			return fmt.Errorf("%w: tag %q exceeds %d characters", ErrInvalidAnnotation, tag, MaxTagLength)
+		}
+	}
+	if a.TypeOverride != "" && !ValidType(a.TypeOverride) {
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidAnnotation, a.TypeOverride)
+	}
+	if a.Note == "" && a.Category == "" && a.TypeOverride == "" && len(a.Tags) == 0 {
+		return fmt.Errorf("%w: nothing to annotate", ErrInvalidAnnotation)
+	}
+	return nil
+}
+
+// NormalizeTags trims and lowercases tags, dropping empty and duplicate ones, sorted
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Annotate applies the annotations to the transactions by ID
+func Annotate(txs []*Transaction, annotations []*Annotation) {
+	byID := make(map[string]*Annotation, len(annotations))
+	for _, a := range annotations {
+		byID[a.TransactionID] = a
+	}
+	for _, tx := range txs {
+		if tx == nil {
+			continue
+		}
+		a, ok := byID[tx.ID]
+		if !ok {
+			continue
+		}
+		tx.Note, tx.Tags, tx.Category = a.Note, a.Tags, a.Category
+		if a.TypeOverride != "" {
+			tx.Type = a.TypeOverride
+		}
+	}
+}
+
+// HasTag reports whether the transaction carries the tag, ignoring case
+func (tx *Transaction) HasTag(tag string) bool {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	for _, t := range tx.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// NewManualTransaction creates a transaction for activity the provider cannot see, such as
+// an over-the-counter trade. The native asset uses the zero address.
+func NewManualTransaction(tokenAddress, symbol string, decimals uint8, amount *big.Int, direction TransactionDirection, txType TransactionType, at time.Time) (*Transaction, error) {
+	tx := &Transaction{
+		ID:           "manual-" + uuid.New().String(),
+		TokenAddress: strings.ToLower(strings.TrimSpace(tokenAddress)),
+		TokenSymbol:  strings.TrimSpace(symbol),
+		TokenDecimal: &decimals,
+		Amount:       amount,
+		Type:         txType,
+		Status:       TransactionStatusSuccess,
+		Direction:    direction,
+		Timestamp:    at.UTC(),
+		Source:       SourceManual,
+	}
+	tx.Hash = tx.ID
+
+	switch {
+	case tx.TokenAddress == "":
+		return nil, fmt.Errorf("%w: token_address is required", ErrInvalidManualTransaction)
+	case amount == nil || amount.Sign() <= 0:
+		return nil, fmt.Errorf("%w: amount must be positive", ErrInvalidManualTransaction)
+	case direction != TransactionDirectionIn && direction != TransactionDirectionOut:
+		return nil, fmt.Errorf("%w: direction must be in or out", ErrInvalidManualTransaction)
+	case at.IsZero() || at.After(time.Now().Add(time.Minute)):
+		return nil, fmt.Errorf("%w: timestamp is required and cannot be in the future", ErrInvalidManualTransaction)
+	}
+	if tx.Type == "" {
+		tx.Type = TransactionTypeReceive
+		if direction == TransactionDirectionOut {
+			tx.Type = TransactionTypeSend
+		}
+	}
+	if !ValidType(tx.Type) {
+		return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidManualTransaction, tx.Type)
+	}
+	return tx, nil
+}
+
+// AnnotationRepository stores annotations per portfolio and transaction ID
+type AnnotationRepository interface {
+	ListAnnotations(ctx context.Context, portfolioID string) ([]*Annotation, error)
+	// SetAnnotation creates or replaces the annotation of a transaction
+	SetAnnotation(ctx context.Context, a *Annotation) error
+	DeleteAnnotation(ctx context.Context, portfolioID, transactionID string) error
+}
+
+// ManualRepository stores the transactions entered by hand per portfolio
+type ManualRepository interface {
+	ListManualTransactions(ctx context.Context, portfolioID string) ([]*Transaction, error)
+	CreateManualTransaction(ctx context.Context, portfolioID string, tx *Transaction) error
+	DeleteManualTransaction(ctx context.Context, portfolioID, id string) error
+}
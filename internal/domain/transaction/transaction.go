@@ -14,6 +14,8 @@ const (
 	TransactionTypeReceive TransactionType = "receive"
 	TransactionTypeSwap    TransactionType = "swap"
 	TransactionTypeStake   TransactionType = "stake"
+	// TransactionTypeInternalTransfer moves funds between wallets of the same owner
+	TransactionTypeInternalTransfer TransactionType = "internal_transfer"
 )
 
 type TransactionStatus string
@@ -24,6 +26,31 @@ const (
 	TransactionStatusFailed  TransactionStatus = "failed"
 )
 
+// IncomeCategory classifies an incoming transfer that is income rather than a purchase or
+// a transfer of the owner's own funds; empty when the transfer is not income
+type IncomeCategory string
+
+const (
+	IncomeStakingReward IncomeCategory = "staking_reward"
+	IncomeAirdrop       IncomeCategory = "airdrop"
+	IncomeInterest      IncomeCategory = "interest"
+	IncomeMining        IncomeCategory = "mining"
+	IncomeSalary        IncomeCategory = "salary"
+)
+
+// IncomeCategories lists the supported income categories
+var IncomeCategories = []IncomeCategory{IncomeStakingReward, IncomeAirdrop, IncomeInterest, IncomeMining, IncomeSalary}
+
+// Valid reports whether c is a supported income category
+func (c IncomeCategory) Valid() bool {
+	for _, known := range IncomeCategories {
+		if c == known {
+			return true
+		}
+	}
+	return false
+}
+
 type TransactionDirection string
 
 const (
@@ -38,6 +65,8 @@ type Transaction struct {
 	To           string
 	TokenAddress string
 	TokenSymbol  string
+	TokenName    string
+	TokenDecimal *uint8   // reported by the provider for token transfers, nil if unknown
 	Amount       *big.Int // Changed from string to *big.Int
 	Type         TransactionType
 	Status       TransactionStatus
@@ -48,6 +77,30 @@ type Transaction struct {
 	Direction    TransactionDirection
 	Timestamp    time.Time
 	BlockNumber  int64
+
+	// Source is the exchange a statement row was imported from, SourceManual for
+	// transactions entered by hand, empty for on-chain transfers
+	Source string
+	// Optional unit price at Timestamp, scaled by price.CurrencyDecimal; set for imported
+	// trades whose counter asset is a currency
+	Price         *big.Int
+	PriceCurrency string
+
+	// Income is set on incoming transfers classified as income
+	Income IncomeCategory
+
+	// Set from the portfolio's annotation of the transaction
+	Note     string
+	Tags     []string
+	Category string
+
+	// Names of From and To from the caller's address book or the registry of well-known
+	// addresses, empty when unknown
+	FromLabel string
+	ToLabel   string
+	// Verified primary ENS names of From and To, empty when they have none
+	FromENS string
+	ToENS   string
 }
 
 // SetDirectionForAddress sets the Direction field based on from/to address comparison.
@@ -76,6 +129,13 @@ type FilterOptions struct {
 	FromDate  *time.Time
 	ToDate    *time.Time
 	Direction *TransactionDirection
+	Tag       *string // requires PortfolioID, annotations carry the tags
+	// Counterparty matches a part of the counterparty's label, case-insensitively
+	Counterparty *string
+
+	// PortfolioID adds the portfolio's manual transactions and annotations, empty for
+	// provider data only
+	PortfolioID string
 
 	Page     int
 	PageSize int
@@ -94,6 +154,19 @@ type Provider interface {
 	TokenTxsByAddress(ctx context.Context, address string, opts FilterOptions) ([]*Transaction, error)
 	InternalTxsByAddress(ctx context.Context, address string, opts FilterOptions) ([]*Transaction, error)
 	GetNativeBalance(ctx context.Context, address string) (*big.Int, error)
+	// TokenBalance returns the current balance of an ERC-20 token held by address
+	TokenBalance(ctx context.Context, address, contract string) (*big.Int, error)
+}
+
+// Labeler sets FromLabel and ToLabel on transactions for the caller in ctx
+type Labeler interface {
+	Label(ctx context.Context, txs []*Transaction) error
+}
+
+// StatementProvider supplies the transactions imported from a portfolio's exchange
+// statements. Their Direction is already set; From and To are empty.
+type StatementProvider interface {
+	StatementTxsByPortfolio(ctx context.Context, portfolioID string) ([]*Transaction, error)
 }
 
 type AggregatedData struct {
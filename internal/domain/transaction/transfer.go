@@ -0,0 +1,53 @@
+package transaction
+
+import "strings"
+
+// Counterparty returns the other side of the transfer: the sender of incoming and the
+// recipient of outgoing transactions, empty when the direction is unknown
+func (tx *Transaction) Counterparty() string {
+	switch tx.Direction {
+	case TransactionDirectionIn:
+		return strings.ToLower(tx.From)
+	case TransactionDirectionOut:
+		return strings.ToLower(tx.To)
+	}
+	return ""
+}
+
+// CounterpartyLabel returns the label of the counterparty, empty when it has none
+func (tx *Transaction) CounterpartyLabel() string {
+	switch tx.Direction {
+	case TransactionDirectionIn:
+		return tx.FromLabel
+	case TransactionDirectionOut:
+		return tx.ToLabel
+	}
+	return ""
+}
+
+// MatchesCounterparty reports whether the counterparty's label contains query,
+// case-insensitively; an empty query matches every transaction
+func (tx *Transaction) MatchesCounterparty(query string) bool {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return true
+	}
+	return strings.Contains(strings.ToLower(tx.CounterpartyLabel()), query)
+}
+
+// MarkInternalTransfers sets TransactionTypeInternalTransfer on transfers whose counterparty
+// is one of the owner's wallets, and on transfers from a wallet to itself. Wallets are
+// lowercase addresses. Internal transfers are never income.
+func MarkInternalTransfers(txs []*Transaction, wallets map[string]bool) {
+	for _, tx := range txs {
+		if tx == nil {
+			continue
+		}
+		from, to := strings.ToLower(tx.From), strings.ToLower(tx.To)
+		self := from != "" && from == to
+		if counterparty := tx.Counterparty(); self || (counterparty != "" && wallets[counterparty]) {
+			tx.Type = TransactionTypeInternalTransfer
+			tx.Income = ""
+		}
+	}
+}
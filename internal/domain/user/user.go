@@ -0,0 +1,114 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUserNotFound       = errors.New("user not found")
+	ErrUserExists         = errors.New("user already exists")
+	ErrInvalidUser        = errors.New("invalid user")
+	ErrAPIKeyNotFound     = errors.New("api key not found")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrTokensDisabled     = errors.New("token authentication is disabled")
+)
+
+type User struct {
+	ID        string
+	Email     string
+	Name      string
+	CreatedAt time.Time
+	// Admin grants the operator endpoints such as the token registry reload. It is
+	// set from configuration on authentication and is not stored.
+	Admin bool
+}
+
+// APIKey is a long-lived credential of a user. Only the SHA-256 hash of the key is
+// stored; Prefix identifies the key in listings.
+type APIKey struct {
+	ID         string
+	UserID     string
+	Name       string
+	Prefix     string
+	Hash       string
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+}
+
+type Repository interface {
+	Create(ctx context.Context, u *User) error
+	GetByID(ctx context.Context, id string) (*User, error)
+	GetByEmail(ctx context.Context, email string) (*User, error)
+
+	CreateAPIKey(ctx context.Context, key *APIKey) error
+	// GetByAPIKeyHash returns the key with the given hash and its owner
+	GetByAPIKeyHash(ctx context.Context, hash string) (*APIKey, *User, error)
+	ListAPIKeys(ctx context.Context, userID string) ([]*APIKey, error)
+	DeleteAPIKey(ctx context.Context, userID, keyID string) error
+	TouchAPIKey(ctx context.Context, keyID string, at time.Time) error
+}
+
+func NewUser(email, name string) (*User, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if _, err := mail.ParseAddress(email); err != nil {
+		return nil, fmt.Errorf("%w: invalid email", ErrInvalidUser)
+	}
+	return &User{
+		ID:        uuid.New().String(),
+		Email:     email,
+		Name:      strings.TrimSpace(name),
+		CreatedAt: time.Now().UTC(),
+	}, nil
+}
+
+type (
+	contextKey struct{}
+	ownerKey   struct{}
+	systemKey  struct{}
+)
+
+// WithUser returns a context carrying the authenticated caller. Repositories scope
+// their queries to the portfolios this user owns or is a member of; contexts without
+// a caller, an owner or the system scope read no portfolio data at all.
+func WithUser(ctx context.Context, u *User) context.Context {
+	return context.WithValue(ctx, contextKey{}, u)
+}
+
+// AsOwner returns a context that reads on behalf of the user ownerID: repositories scope
+// its queries to the portfolios that user owns, whoever the caller is. It is for reads
+// done after the caller's access to one of the owner's portfolios has been checked.
+// The caller, if any, stays the actor of audited changes.
+func AsOwner(ctx context.Context, ownerID string) context.Context {
+	return context.WithValue(ctx, ownerKey{}, ownerID)
+}
+
+// AsSystem returns a context for background jobs that work across all users, such as
+// scheduled syncs. Repositories do not scope its queries, so request handlers must not use it.
+func AsSystem(ctx context.Context) context.Context {
+	return context.WithValue(ctx, systemKey{}, true)
+}
+
+// OwnerFromContext returns the owner set by AsOwner, if any
+func OwnerFromContext(ctx context.Context) (string, bool) {
+	ownerID, ok := ctx.Value(ownerKey{}).(string)
+	return ownerID, ok && ownerID != ""
+}
+
+// IsSystem reports whether ctx was made by AsSystem
+func IsSystem(ctx context.Context) bool {
+	system, _ := ctx.Value(systemKey{}).(bool)
+	return system
+}
+
+// FromContext returns the authenticated caller, if any
+func FromContext(ctx context.Context) (*User, bool) {
+	u, ok := ctx.Value(contextKey{}).(*User)
+	return u, ok && u != nil
+}
@@ -0,0 +1,68 @@
+package http
+
+import (
+	"time"
+
+	"testtask/internal/domain/addressbook"
+)
+
+// AddressBookEntryRequest represents the request body for labeling an address
+type AddressBookEntryRequest struct {
+	Address string `json:"address"`
+	Label   string `json:"label"`
+	Note    string `json:"note,omitempty"`
+}
+
+// AddressBookEntry represents a label the caller gave to an address
+type AddressBookEntry struct {
+	ID        string    `json:"id"`
+	Address   string    `json:"address"`
+	Label     string    `json:"label"`
+	Note      string    `json:"note,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// KnownAddress represents a well-known contract or wallet from the bundled registry
+type KnownAddress struct {
+	Address  string `json:"address"`
+	Label    string `json:"label"`
+	Category string `json:"category"`
+}
+
+// ToHTTPAddressBookEntry converts a domain address book entry to HTTP AddressBookEntry
+func ToHTTPAddressBookEntry(e *addressbook.Entry) *AddressBookEntry {
+	if e == nil {
+		return nil
+	}
+	return &AddressBookEntry{
+		ID:        e.ID,
+		Address:   e.Address,
+		Label:     e.Label,
+		Note:      e.Note,
+		CreatedAt: e.CreatedAt,
+		UpdatedAt: e.UpdatedAt,
+	}
+}
+
+// ToHTTPAddressBookEntries converts domain address book entries to HTTP AddressBookEntries
+func ToHTTPAddressBookEntries(entries []*addressbook.Entry) []*AddressBookEntry {
+	result := make([]*AddressBookEntry, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, ToHTTPAddressBookEntry(e))
+	}
+	return result
+}
+
+// ToHTTPKnownAddresses converts domain known addresses to HTTP KnownAddresses
+func ToHTTPKnownAddresses(known []*addressbook.KnownAddress) []*KnownAddress {
+	result := make([]*KnownAddress, 0, len(known))
+	for _, k := range known {
+		result = append(result, &KnownAddress{
+			Address:  k.Address,
+			Label:    k.Label,
+			Category: string(k.Category),
+		})
+	}
+	return result
+}
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"time"
+
+	"testtask/internal/domain/token"
+)
+
+// TokenReloadResult represents the outcome of a token registry reload
+type TokenReloadResult struct {
+	Trigger    string    `json:"trigger"`
+	Source     string    `json:"source,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMs int64     `json:"duration_ms"`
+	Success    bool      `json:"success"`
+	Error      string    `json:"error,omitempty"`
+	Loaded     int       `json:"loaded"`
+	Added      int       `json:"added"`
+	Updated    int       `json:"updated"`
+	Unchanged  int       `json:"unchanged"`
+	Stale      int       `json:"stale"`
+	Indexed    int       `json:"indexed"`
+}
+
+// ToHTTPTokenReloadResult converts a domain reload result to HTTP TokenReloadResult
+func ToHTTPTokenReloadResult(r *token.ReloadResult) *TokenReloadResult {
+	if r == nil {
+		return nil
+	}
+	result := &TokenReloadResult{
+		Trigger:    r.Trigger,
+		Source:     r.Source,
+		StartedAt:  r.StartedAt,
+		DurationMs: r.Duration.Milliseconds(),
+		Success:    r.Err == nil,
+		Loaded:     r.Loaded,
+		Added:      r.Added,
+		Updated:    r.Updated,
+		Unchanged:  r.Unchanged,
+		Stale:      r.Stale,
+		Indexed:    r.Indexed,
+	}
+	if r.Err != nil {
+		result.Error = r.Err.Error()
+	}
+	return result
+}
@@ -0,0 +1,123 @@
+package http
+
+import (
+	"time"
+
+	"testtask/internal/domain/price"
+	"testtask/internal/domain/transaction"
+)
+
+// AnnotationRequest represents the request body for annotating a transaction. Type, when
+// set, replaces the detected transaction type.
+type AnnotationRequest struct {
+	Note     string   `json:"note,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Category string   `json:"category,omitempty"`
+	Type     string   `json:"type,omitempty"`
+}
+
+// Annotation represents what a user attached to a transaction
+type Annotation struct {
+	PortfolioID   string    `json:"portfolio_id"`
+	TransactionID string    `json:"transaction_id"`
+	Note          string    `json:"note,omitempty"`
+	Tags          []string  `json:"tags"`
+	Category      string    `json:"category,omitempty"`
+	Type          string    `json:"type,omitempty"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// ManualTransactionRequest represents the request body for adding a transaction the provider
+// cannot see. Amount and price are decimal strings; price is per unit in currency.
+// Counterparty is the sender of incoming and the recipient of outgoing transactions.
+type ManualTransactionRequest struct {
+	TokenAddress  string    `json:"token_address"`
+	TokenSymbol   string    `json:"token_symbol,omitempty"`
+	TokenDecimals uint8     `json:"token_decimals"`
+	Amount        string    `json:"amount"`
+	Direction     string    `json:"direction"`
+	Type          string    `json:"type,omitempty"`
+	Counterparty  string    `json:"counterparty,omitempty"`
+	Price         string    `json:"price,omitempty"`
+	Currency      string    `json:"currency,omitempty"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// ManualTransaction represents a transaction entered by hand
+type ManualTransaction struct {
+	ID            string    `json:"id"`
+	From          string    `json:"from"`
+	To            string    `json:"to"`
+	TokenAddress  string    `json:"token_address"`
+	TokenSymbol   string    `json:"token_symbol"`
+	TokenDecimals uint8     `json:"token_decimals"`
+	Amount        string    `json:"amount"`
+	Type          string    `json:"type"`
+	Direction     string    `json:"direction"`
+	Price         string    `json:"price,omitempty"`
+	Currency      string    `json:"currency,omitempty"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// ToHTTPAnnotation converts a domain annotation to HTTP Annotation
+func ToHTTPAnnotation(a *transaction.Annotation) *Annotation {
+	if a == nil {
+		return nil
+	}
+	tags := a.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return &Annotation{
+		PortfolioID:   a.PortfolioID,
+		TransactionID: a.TransactionID,
+		Note:          a.Note,
+		Tags:          tags,
+		Category:      a.Category,
+		Type:          string(a.TypeOverride),
+		UpdatedAt:     a.UpdatedAt,
+	}
+}
+
+// ToHTTPAnnotations converts domain annotations to HTTP Annotations
+func ToHTTPAnnotations(annotations []*transaction.Annotation) []*Annotation {
+	result := make([]*Annotation, 0, len(annotations))
+	for _, a := range annotations {
+		result = append(result, ToHTTPAnnotation(a))
+	}
+	return result
+}
+
+// ToHTTPManualTransaction converts a domain manual transaction to HTTP ManualTransaction
+func ToHTTPManualTransaction(tx *transaction.Transaction) *ManualTransaction {
+	if tx == nil {
+		return nil
+	}
+	var decimals uint8
+	if tx.TokenDecimal != nil {
+		decimals = *tx.TokenDecimal
+	}
+	return &ManualTransaction{
+		ID:            tx.ID,
+		From:          tx.From,
+		To:            tx.To,
+		TokenAddress:  tx.TokenAddress,
+		TokenSymbol:   tx.TokenSymbol,
+		TokenDecimals: decimals,
+		Amount:        FormatDecimal(tx.Amount, int(decimals)),
+		Type:          string(tx.Type),
+		Direction:     string(tx.Direction),
+		Price:         FormatDecimal(tx.Price, price.CurrencyDecimal),
+		Currency:      tx.PriceCurrency,
+		Timestamp:     tx.Timestamp,
+	}
+}
+
+// ToHTTPManualTransactions converts domain manual transactions to HTTP ManualTransactions
+func ToHTTPManualTransactions(txs []*transaction.Transaction) []*ManualTransaction {
+	result := make([]*ManualTransaction, 0, len(txs))
+	for _, tx := range txs {
+		result = append(result, ToHTTPManualTransaction(tx))
+	}
+	return result
+}
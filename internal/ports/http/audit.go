@@ -0,0 +1,43 @@
+package http
+
+import (
+	"encoding/json"
+	"time"
+
+	"testtask/internal/domain/audit"
+)
+
+// AuditEntry represents a recorded state change. Before is omitted for creations and
+// After for deletions.
+type AuditEntry struct {
+	ID           string          `json:"id"`
+	ActorID      string          `json:"actor_id"`
+	Action       string          `json:"action"`
+	ResourceType string          `json:"resource_type"`
+	ResourceID   string          `json:"resource_id"`
+	PortfolioID  string          `json:"portfolio_id,omitempty"`
+	Before       json.RawMessage `json:"before,omitempty"`
+	After        json.RawMessage `json:"after,omitempty"`
+	RequestID    string          `json:"request_id,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// ToHTTPAuditEntries converts domain audit entries to HTTP AuditEntries
+func ToHTTPAuditEntries(entries []*audit.Entry) []*AuditEntry {
+	result := make([]*AuditEntry, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, &AuditEntry{
+			ID:           e.ID,
+			ActorID:      e.ActorID,
+			Action:       e.Action,
+			ResourceType: e.ResourceType,
+			ResourceID:   e.ResourceID,
+			PortfolioID:  e.PortfolioID,
+			Before:       e.Before,
+			After:        e.After,
+			RequestID:    e.RequestID,
+			CreatedAt:    e.CreatedAt,
+		})
+	}
+	return result
+}
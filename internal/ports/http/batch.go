@@ -0,0 +1,200 @@
+package http
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"mime"
+	"strings"
+
+	"testtask/internal/domain/holding"
+	"testtask/internal/domain/token"
+)
+
+// HoldingBatchRequest represents the request body for applying holding operations atomically
+type HoldingBatchRequest struct {
+	Operations []HoldingOperation `json:"operations"`
+	DryRun     bool               `json:"dry_run"`
+}
+
+// HoldingOperation is one batch operation. op is add, set, update or delete; add and set
+// address the holding by token_address, update and delete by holding_id. amount is an
+// integer in token base units. version is optional.
+type HoldingOperation struct {
+	Op           string `json:"op"`
+	HoldingID    string `json:"holding_id,omitempty"`
+	TokenAddress string `json:"token_address,omitempty"`
+	Amount       string `json:"amount,omitempty"`
+	Version      int64  `json:"version,omitempty"`
+}
+
+// HoldingImportRow is one position of an import; amount is a decimal in token units
+type HoldingImportRow struct {
+	TokenAddress string `json:"token_address"`
+	Amount       string `json:"amount"`
+}
+
+// HoldingImportRequest represents a JSON import body
+type HoldingImportRequest struct {
+	Holdings []HoldingImportRow `json:"holdings"`
+}
+
+// HoldingBatchResponse reports the outcome of a batch or import
+type HoldingBatchResponse struct {
+	DryRun  bool                      `json:"dry_run"`
+	Applied bool                      `json:"applied"`
+	Failed  int                       `json:"failed"`
+	Results []*HoldingOperationResult `json:"results"`
+}
+
+// HoldingOperationResult reports the outcome of one operation
+type HoldingOperationResult struct {
+	Index   int      `json:"index"`
+	Op      string   `json:"op"`
+	Status  string   `json:"status"`
+	Holding *Holding `json:"holding,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// TokenResolver resolves a token address for the portfolio an operation applies to
+type TokenResolver func(address string) (*token.Token, bool)
+
+// ToDomainBatchOperations converts batch operations. Tokens that cannot be resolved are
+// left nil and reported by the operation's result.
+func ToDomainBatchOperations(ops []HoldingOperation, resolve TokenResolver) ([]*holding.BatchOperation, error) {
+	result := make([]*holding.BatchOperation, 0, len(ops))
+	for i, op := range ops {
+		o := &holding.BatchOperation{
+			Op:           holding.BatchOp(strings.ToLower(strings.TrimSpace(op.Op))),
+			HoldingID:    op.HoldingID,
+			TokenAddress: strings.ToLower(strings.TrimSpace(op.TokenAddress)),
+			Version:      op.Version,
+		}
+		if o.TokenAddress != "" {
+			o.Token, _ = resolve(o.TokenAddress)
+		}
+		if amount := strings.TrimSpace(op.Amount); amount != "" {
+			var ok bool
+			if o.Amount, ok = new(big.Int).SetString(amount, 10); !ok {
+				return nil, fmt.Errorf("%w: operations[%d]: amount must be an integer in token base units", holding.ErrInvalidBatch, i)
+			}
+		}
+		result = append(result, o)
+	}
+	return result, nil
+}
+
+// ParseHoldingImport reads import rows from a CSV body with token_address and amount
+// columns, or from a JSON HoldingImportRequest
+func ParseHoldingImport(contentType string, body io.Reader) ([]HoldingImportRow, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv", "application/csv":
+		return parseHoldingCSV(body)
+	case "application/json", "":
+		var req HoldingImportRequest
+		if err := json.NewDecoder(body).Decode(&req); err != nil {
+			return nil, fmt.Errorf("%w: invalid JSON: %v", holding.ErrInvalidBatch, err)
+		}
+		return req.Holdings, nil
+	}
+	return nil, fmt.Errorf("%w: unsupported content type %q, use text/csv or application/json", holding.ErrInvalidBatch, mediaType)
+}
+
+func parseHoldingCSV(body io.Reader) ([]HoldingImportRow, error) {
+	r := csv.NewReader(body)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: empty CSV", holding.ErrInvalidBatch)
+	} else if err != nil {
+		return nil, fmt.Errorf("%w: %v", holding.ErrInvalidBatch, err)
+	}
+
+	addressCol, amountCol := -1, -1
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))) {
+		case "token_address":
+			addressCol = i
+		case "amount":
+			amountCol = i
+		}
+	}
+	if addressCol < 0 || amountCol < 0 {
+		return nil, fmt.Errorf("%w: CSV header must contain token_address and amount", holding.ErrInvalidBatch)
+	}
+
+	var rows []HoldingImportRow
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("%w: %v", holding.ErrInvalidBatch, err)
+		}
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		line, _ := r.FieldPos(0)
+		if len(record) <= addressCol || len(record) <= amountCol {
+			return nil, fmt.Errorf("%w: line %d: missing columns", holding.ErrInvalidBatch, line)
+		}
+		rows = append(rows, HoldingImportRow{
+			TokenAddress: strings.TrimSpace(record[addressCol]),
+			Amount:       strings.TrimSpace(record[amountCol]),
+		})
+	}
+	return rows, nil
+}
+
+// ToDomainImportOperations turns import rows into set operations. Amounts are parsed with
+// the decimals of the resolved token; rows of unsupported tokens keep a nil token.
+func ToDomainImportOperations(rows []HoldingImportRow, resolve TokenResolver) ([]*holding.BatchOperation, error) {
+	result := make([]*holding.BatchOperation, 0, len(rows))
+	for i, row := range rows {
+		o := &holding.BatchOperation{
+			Op:           holding.BatchSet,
+			TokenAddress: strings.ToLower(row.TokenAddress),
+		}
+		if o.TokenAddress != "" {
+			o.Token, _ = resolve(o.TokenAddress)
+		}
+		if o.Token != nil {
+			amount, err := ParseDecimal(row.Amount, int(o.Token.Decimal))
+			if err != nil {
+				return nil, fmt.Errorf("%w: holdings[%d]: %v", holding.ErrInvalidBatch, i, err)
+			}
+			o.Amount = amount
+		}
+		result = append(result, o)
+	}
+	return result, nil
+}
+
+// ToHTTPHoldingBatch converts batch results
+func ToHTTPHoldingBatch(results []*holding.BatchResult, dryRun bool) *HoldingBatchResponse {
+	resp := &HoldingBatchResponse{
+		DryRun:  dryRun,
+		Results: make([]*HoldingOperationResult, 0, len(results)),
+	}
+	for _, r := range results {
+		item := &HoldingOperationResult{
+			Index:   r.Index,
+			Op:      string(r.Op),
+			Status:  string(r.Status),
+			Holding: ToHTTPHolding(r.Holding),
+		}
+		if r.Err != nil {
+			item.Error = r.Err.Error()
+			resp.Failed++
+		}
+		resp.Results = append(resp.Results, item)
+	}
+	resp.Applied = !dryRun && resp.Failed == 0
+	return resp
+}
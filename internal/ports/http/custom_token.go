@@ -0,0 +1,73 @@
+package http
+
+import (
+	"time"
+
+	"testtask/internal/domain/price"
+	"testtask/internal/domain/token"
+)
+
+// CreateCustomTokenRequest represents the request body for registering a custom token.
+// Price is an optional decimal string; it requires currency (default usd).
+type CreateCustomTokenRequest struct {
+	Address     string `json:"address"`
+	ChainID     string `json:"chain_id"`
+	Name        string `json:"name"`
+	Symbol      string `json:"symbol"`
+	Decimals    uint8  `json:"decimals"`
+	PortfolioID string `json:"portfolio_id,omitempty"`
+	Price       string `json:"price,omitempty"`
+	Currency    string `json:"currency,omitempty"`
+}
+
+// SetCustomTokenPriceRequest sets or, with an empty price, removes a manual price feed
+type SetCustomTokenPriceRequest struct {
+	Price    string `json:"price"`
+	Currency string `json:"currency,omitempty"`
+}
+
+// CustomToken represents a user-defined token
+type CustomToken struct {
+	ID             string     `json:"id"`
+	PortfolioID    string     `json:"portfolio_id,omitempty"`
+	OwnerID        string     `json:"owner_id,omitempty"`
+	ChainID        string     `json:"chain_id"`
+	Token          *TokenInfo `json:"token"`
+	Price          string     `json:"price,omitempty"`
+	PriceCurrency  string     `json:"price_currency,omitempty"`
+	PriceUpdatedAt *time.Time `json:"price_updated_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// ToHTTPCustomToken converts a domain custom token to HTTP CustomToken
+func ToHTTPCustomToken(c *token.CustomToken) *CustomToken {
+	if c == nil {
+		return nil
+	}
+	result := &CustomToken{
+		ID:          c.ID,
+		PortfolioID: c.PortfolioID,
+		OwnerID:     c.OwnerID,
+		ChainID:     c.ChainID,
+		Token:       ToHTTPTokenInfo(c.Token),
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+	}
+	if c.ManualPrice != nil {
+		updatedAt := c.PriceUpdatedAt
+		result.Price = FormatDecimal(c.ManualPrice, price.CurrencyDecimal)
+		result.PriceCurrency = c.PriceCurrency
+		result.PriceUpdatedAt = &updatedAt
+	}
+	return result
+}
+
+// ToHTTPCustomTokens converts domain custom tokens to HTTP CustomTokens
+func ToHTTPCustomTokens(tokens []*token.CustomToken) []*CustomToken {
+	result := make([]*CustomToken, 0, len(tokens))
+	for _, c := range tokens {
+		result = append(result, ToHTTPCustomToken(c))
+	}
+	return result
+}
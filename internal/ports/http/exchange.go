@@ -0,0 +1,181 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+	"time"
+
+	"testtask/internal/domain/exchange"
+	"testtask/internal/domain/price"
+)
+
+// StatementImportRequest represents a JSON statement import. content is the CSV export;
+// mapping is required for the generic exchange.
+type StatementImportRequest struct {
+	Exchange string            `json:"exchange"`
+	Mapping  *exchange.Mapping `json:"mapping,omitempty"`
+	Content  string            `json:"content"`
+}
+
+// StatementImport is a parsed import request
+type StatementImport struct {
+	Exchange exchange.Exchange
+	Mapping  *exchange.Mapping
+	Body     io.Reader
+}
+
+// StatementImportResponse reports the outcome of a statement import
+type StatementImportResponse struct {
+	Exchange   string `json:"exchange"`
+	Parsed     int    `json:"parsed"`
+	Imported   int    `json:"imported"`
+	Duplicates int    `json:"duplicates"`
+}
+
+// ExchangeRecord represents a normalized balance change on an exchange; amount is a
+// signed decimal in asset units and price a decimal unit price in currency
+type ExchangeRecord struct {
+	ID         string    `json:"id"`
+	Exchange   string    `json:"exchange"`
+	ExternalID string    `json:"external_id"`
+	TradeID    string    `json:"trade_id,omitempty"`
+	Kind       string    `json:"kind"`
+	Asset      string    `json:"asset"`
+	Amount     string    `json:"amount"`
+	Price      string    `json:"price,omitempty"`
+	Currency   string    `json:"currency,omitempty"`
+	Note       string    `json:"note,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+	ImportedAt time.Time `json:"imported_at"`
+}
+
+// ParseStatementImport reads an import from a CSV body with the exchange given by the
+// exchange query parameter, or from a JSON StatementImportRequest
+func ParseStatementImport(contentType, exchangeParam string, body io.Reader) (*StatementImport, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv", "application/csv", "text/plain":
+		e, err := exchange.ParseExchange(exchangeParam)
+		if err != nil {
+			return nil, err
+		}
+		if e == exchange.Generic {
+			return nil, fmt.Errorf("%w: generic statements are imported as JSON with a mapping", exchange.ErrInvalidStatement)
+		}
+		return &StatementImport{Exchange: e, Body: body}, nil
+	case "application/json", "":
+		var req StatementImportRequest
+		if err := json.NewDecoder(body).Decode(&req); err != nil {
+			return nil, fmt.Errorf("%w: invalid JSON: %v", exchange.ErrInvalidStatement, err)
+		}
+		if req.Exchange == "" {
+			req.Exchange = exchangeParam
+		}
+		e, err := exchange.ParseExchange(req.Exchange)
+		if err != nil {
+			return nil, err
+		}
+		return &StatementImport{Exchange: e, Mapping: req.Mapping, Body: strings.NewReader(req.Content)}, nil
+	}
+	return nil, fmt.Errorf("%w: unsupported content type %q, use text/csv or application/json", exchange.ErrInvalidStatement, mediaType)
+}
+
+// ToHTTPStatementImport converts an import result
+func ToHTTPStatementImport(r *exchange.ImportResult) *StatementImportResponse {
+	return &StatementImportResponse{
+		Exchange:   string(r.Exchange),
+		Parsed:     r.Parsed,
+		Imported:   r.Imported,
+		Duplicates: r.Duplicates,
+	}
+}
+
+// ToHTTPExchangeRecords converts domain exchange records to HTTP ExchangeRecords
+func ToHTTPExchangeRecords(records []*exchange.Record) []*ExchangeRecord {
+	result := make([]*ExchangeRecord, 0, len(records))
+	for _, r := range records {
+		record := &ExchangeRecord{
+			ID:         r.ID,
+			Exchange:   string(r.Exchange),
+			ExternalID: r.ExternalID,
+			TradeID:    r.TradeID,
+			Kind:       string(r.Kind),
+			Asset:      r.Asset,
+			Amount:     trimDecimal(FormatDecimal(r.Amount, exchange.AmountDecimals)),
+			Currency:   r.Currency,
+			Note:       r.Note,
+			Timestamp:  r.OccurredAt,
+			ImportedAt: r.ImportedAt,
+		}
+		if r.Price != nil {
+			record.Price = FormatDecimal(r.Price, price.CurrencyDecimal)
+		}
+		result = append(result, record)
+	}
+	return result
+}
+
+// trimDecimal drops trailing fractional zeros, e.g. "1.500000" becomes "1.5"
+func trimDecimal(s string) string {
+	if !strings.Contains(s, ".") {
+		return s
+	}
+	return strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+}
+
+// CreateConnectionRequest represents the request body for connecting an exchange account.
+// The API key must be read-only; the secret is stored encrypted and never returned.
+type CreateConnectionRequest struct {
+	Exchange  string `json:"exchange"`
+	Label     string `json:"label"`
+	APIKey    string `json:"api_key"`
+	APISecret string `json:"api_secret"`
+}
+
+// ExchangeConnection represents a connected exchange account without its credentials
+type ExchangeConnection struct {
+	ID           string     `json:"id"`
+	Exchange     string     `json:"exchange"`
+	Label        string     `json:"label,omitempty"`
+	APIKeyHint   string     `json:"api_key_hint"`
+	LastSyncedAt *time.Time `json:"last_synced_at,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// SyncResponse reports the outcome of a connection sync
+type SyncResponse struct {
+	Balances    int `json:"balances"`
+	Trades      int `json:"trades"`
+	Adjustments int `json:"adjustments"`
+}
+
+// ToHTTPExchangeConnection converts a domain connection to HTTP ExchangeConnection
+func ToHTTPExchangeConnection(c *exchange.Connection) *ExchangeConnection {
+	return &ExchangeConnection{
+		ID:           c.ID,
+		Exchange:     string(c.Exchange),
+		Label:        c.Label,
+		APIKeyHint:   c.KeyHint,
+		LastSyncedAt: c.LastSyncedAt,
+		LastError:    c.LastError,
+		CreatedAt:    c.CreatedAt,
+	}
+}
+
+// ToHTTPExchangeConnections converts domain connections to HTTP ExchangeConnections
+func ToHTTPExchangeConnections(connections []*exchange.Connection) []*ExchangeConnection {
+	result := make([]*ExchangeConnection, 0, len(connections))
+	for _, c := range connections {
+		result = append(result, ToHTTPExchangeConnection(c))
+	}
+	return result
+}
+
+// ToHTTPSyncResult converts a sync result
+func ToHTTPSyncResult(r *exchange.SyncResult) *SyncResponse {
+	return &SyncResponse{Balances: r.Balances, Trades: r.Trades, Adjustments: r.Adjustments}
+}
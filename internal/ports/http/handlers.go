@@ -8,14 +8,16 @@ import (
 )
 
 type TransactionFilters struct {
-	Address  *string    `json:"address"`
-	Type     *string    `json:"type"`
-	Status   *string    `json:"status"`
-	Token    *string    `json:"token"`
-	FromDate *time.Time `json:"from_date"`
-	ToDate   *time.Time `json:"to_date"`
-	Page     int        `json:"page"`
-	PageSize int        `json:"page_size"`
+	Address      *string    `json:"address"`
+	Type         *string    `json:"type"`
+	Status       *string    `json:"status"`
+	Token        *string    `json:"token"`
+	Tag          *string    `json:"tag"`
+	Counterparty *string    `json:"counterparty"` // part of the counterparty's label
+	FromDate     *time.Time `json:"from_date"`
+	ToDate       *time.Time `json:"to_date"`
+	Page         int        `json:"page"`
+	PageSize     int        `json:"page_size"`
 }
 
 type Transaction struct {
@@ -32,6 +34,19 @@ type Transaction struct {
 	Method       string    `json:"method"`
 	Timestamp    time.Time `json:"timestamp"`
 	BlockNumber  int64     `json:"block_number"`
+	Income       string    `json:"income,omitempty"` // income category of incoming transfers that are income
+	Source       string    `json:"source,omitempty"` // "manual" for transactions entered by hand
+	Note         string    `json:"note,omitempty"`
+	Tags         []string  `json:"tags,omitempty"`
+	Category     string    `json:"category,omitempty"`
+	// Labels of From and To from the caller's address book or the registry of well-known
+	// addresses; CounterpartyLabel is the label of the other side of the transfer
+	FromLabel         string `json:"from_label,omitempty"`
+	ToLabel           string `json:"to_label,omitempty"`
+	CounterpartyLabel string `json:"counterparty_label,omitempty"`
+	// Verified primary ENS names of From and To
+	FromENS string `json:"from_ens,omitempty"`
+	ToENS   string `json:"to_ens,omitempty"`
 }
 
 type Holding struct {
@@ -39,6 +54,7 @@ type Holding struct {
 	TokenAddress string   `json:"token_address"`
 	TokenSymbol  string   `json:"token_symbol"`
 	Amount       *big.Int `json:"amount"`
+	Version      int64    `json:"version"`
 }
 
 // ToDomainFilterOptions maps HTTP transaction filters to domain filter options.
@@ -51,15 +67,10 @@ func ToDomainFilterOptions(f TransactionFilters) (transaction.FilterOptions, err
 
 	if f.Type != nil && *f.Type != "" {
 		t := transaction.TransactionType(*f.Type)
-		switch t {
-		case transaction.TransactionTypeSend,
-			transaction.TransactionTypeReceive,
-			transaction.TransactionTypeSwap,
-			transaction.TransactionTypeStake:
-			opts.Type = &t
-		default:
+		if !transaction.ValidType(t) {
 			return opts, fmt.Errorf("invalid transaction type: %s", *f.Type)
 		}
+		opts.Type = &t
 	}
 
 	if f.Status != nil && *f.Status != "" {
@@ -77,6 +88,12 @@ func ToDomainFilterOptions(f TransactionFilters) (transaction.FilterOptions, err
 	if f.Token != nil && *f.Token != "" {
 		opts.Token = f.Token
 	}
+	if f.Tag != nil && *f.Tag != "" {
+		opts.Tag = f.Tag
+	}
+	if f.Counterparty != nil && *f.Counterparty != "" {
+		opts.Counterparty = f.Counterparty
+	}
 	if f.FromDate != nil {
 		opts.FromDate = f.FromDate
 	}
@@ -91,8 +108,15 @@ func ToDomainFilterOptions(f TransactionFilters) (transaction.FilterOptions, err
 }
 
 type Portfolio struct {
-	ID       string     `json:"id"`
-	Holdings []*Holding `json:"holdingRepo"`
+	ID          string     `json:"id"`
+	Address     string     `json:"address"`
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Tags        []string   `json:"tags"`
+	Archived    bool       `json:"archived"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	Holdings    []*Holding `json:"holdingRepo"`
 }
 
 type Price struct {
@@ -116,11 +140,26 @@ type PaginatedResponse struct {
 
 // Asset represents an asset in the portfolio with its value
 type Asset struct {
-	Token    *TokenInfo `json:"token"`
-	Amount   *big.Int   `json:"amount"`
-	PriceUSD float64    `json:"price_usd"`
-	ValueUSD float64    `json:"value_usd"`
-	Source   string     `json:"source"` // "holding" or "transaction"
+	Token             *TokenInfo   `json:"token"`
+	Amount            *big.Int     `json:"amount"`
+	PriceUSD          float64      `json:"price_usd"`
+	ValueUSD          float64      `json:"value_usd"`
+	ValueChange24hUSD *float64     `json:"value_change_24h_usd,omitempty"`
+	Market            *MarketData  `json:"market,omitempty"`
+	Source            string       `json:"source"` // "holding", "transaction" or "manual"
+	PriceSource       string       `json:"price_source,omitempty"`
+	PriceOverridden   bool         `json:"price_overridden"`
+	PriceOverrideID   string       `json:"price_override_id,omitempty"`
+	Components        []*Asset     `json:"components,omitempty"` // assets combined into a merged asset
+	Manual            *ManualEntry `json:"manual,omitempty"`     // the entry behind a manual asset or liability
+}
+
+// MarketData represents market statistics of an asset price
+type MarketData struct {
+	Change24hPct *float64 `json:"change_24h_pct,omitempty"`
+	Change7dPct  *float64 `json:"change_7d_pct,omitempty"`
+	Volume24hUSD *float64 `json:"volume_24h_usd,omitempty"`
+	MarketCapUSD *float64 `json:"market_cap_usd,omitempty"`
 }
 
 // TokenInfo represents token information in the response
@@ -130,11 +169,24 @@ type TokenInfo struct {
 	Symbol  string `json:"symbol"`
 	Address string `json:"address"`
 	Decimal uint8  `json:"decimal"`
+	LogoURI string `json:"logo_uri,omitempty"`
 }
 
 // PortfolioAssets represents all assets in a portfolio with their values
 type PortfolioAssets struct {
+	PortfolioID    string   `json:"portfolio_id"`
+	Address        string   `json:"address"`
+	TotalValueUSD  float64  `json:"total_value_usd"` // net worth
+	GrossAssetsUSD float64  `json:"gross_assets_usd"`
+	LiabilitiesUSD float64  `json:"liabilities_usd"`
+	Change24hUSD   float64  `json:"change_24h_usd"`
+	Change24hPct   *float64 `json:"change_24h_pct,omitempty"`
+	Assets         []*Asset `json:"assets"`
+}
+
+// TopMovers represents the assets of a portfolio with the largest 24h price changes
+type TopMovers struct {
 	PortfolioID string   `json:"portfolio_id"`
-	Address     string   `json:"address"`
-	Assets      []*Asset `json:"assets"`
+	Gainers     []*Asset `json:"gainers"`
+	Losers      []*Asset `json:"losers"`
 }
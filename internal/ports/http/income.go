@@ -0,0 +1,72 @@
+package http
+
+import (
+	"time"
+
+	"testtask/internal/domain/income"
+)
+
+// IncomeClassificationRequest represents the request body for setting the income category of
+// a transaction. Category "none" marks a transfer detected as income as not income.
+type IncomeClassificationRequest struct {
+	Category string `json:"category"`
+	Note     string `json:"note,omitempty"`
+}
+
+// IncomeClassification represents an income category set by hand on a transaction
+type IncomeClassification struct {
+	PortfolioID   string    `json:"portfolio_id"`
+	TransactionID string    `json:"transaction_id"`
+	Category      string    `json:"category"`
+	Note          string    `json:"note,omitempty"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// IncomeAccrual represents rebasing token growth recorded as income
+type IncomeAccrual struct {
+	ID           string    `json:"id"`
+	TokenAddress string    `json:"token_address"`
+	Symbol       string    `json:"symbol"`
+	Quantity     string    `json:"quantity"`
+	Category     string    `json:"category"`
+	ObservedAt   time.Time `json:"observed_at"`
+}
+
+// ToHTTPIncomeClassification converts a domain classification to HTTP IncomeClassification
+func ToHTTPIncomeClassification(c *income.Classification) *IncomeClassification {
+	if c == nil {
+		return nil
+	}
+	return &IncomeClassification{
+		PortfolioID:   c.PortfolioID,
+		TransactionID: c.TransactionID,
+		Category:      string(c.Category),
+		Note:          c.Note,
+		UpdatedAt:     c.UpdatedAt,
+	}
+}
+
+// ToHTTPIncomeClassifications converts domain classifications to HTTP IncomeClassifications
+func ToHTTPIncomeClassifications(classifications []*income.Classification) []*IncomeClassification {
+	result := make([]*IncomeClassification, 0, len(classifications))
+	for _, c := range classifications {
+		result = append(result, ToHTTPIncomeClassification(c))
+	}
+	return result
+}
+
+// ToHTTPIncomeAccruals converts domain accruals to HTTP IncomeAccruals
+func ToHTTPIncomeAccruals(accruals []*income.Accrual) []*IncomeAccrual {
+	result := make([]*IncomeAccrual, 0, len(accruals))
+	for _, a := range accruals {
+		result = append(result, &IncomeAccrual{
+			ID:           a.ID,
+			TokenAddress: a.TokenAddress,
+			Symbol:       a.Symbol,
+			Quantity:     FormatDecimal(a.Quantity, int(a.Decimals)),
+			Category:     string(a.Category),
+			ObservedAt:   a.ObservedAt,
+		})
+	}
+	return result
+}
@@ -0,0 +1,110 @@
+package http
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"testtask/internal/domain/holding"
+	"testtask/internal/domain/price"
+)
+
+// LedgerEntryRequest represents the request body for recording a change of a holding.
+// quantity is an integer in token base units: a positive amount for deposits and
+// withdrawals, a signed change for adjustments and transfers. price is an optional
+// decimal unit price in currency (default usd); timestamp defaults to now.
+type LedgerEntryRequest struct {
+	TokenAddress string     `json:"token_address"`
+	Type         string     `json:"type"`
+	Quantity     string     `json:"quantity"`
+	Price        string     `json:"price,omitempty"`
+	Currency     string     `json:"currency,omitempty"`
+	Note         string     `json:"note,omitempty"`
+	Counterparty string     `json:"counterparty,omitempty"`
+	Timestamp    *time.Time `json:"timestamp,omitempty"`
+}
+
+// LedgerEntry represents one change of a holding; quantity is signed
+type LedgerEntry struct {
+	ID           string    `json:"id"`
+	HoldingID    string    `json:"holding_id"`
+	TokenAddress string    `json:"token_address"`
+	TokenSymbol  string    `json:"token_symbol"`
+	Type         string    `json:"type"`
+	Quantity     string    `json:"quantity"`
+	Price        string    `json:"price,omitempty"`
+	Currency     string    `json:"currency,omitempty"`
+	Note         string    `json:"note,omitempty"`
+	Counterparty string    `json:"counterparty,omitempty"`
+	Timestamp    time.Time `json:"timestamp"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// HoldingsAt represents the holdings of a portfolio at a point in time
+type HoldingsAt struct {
+	PortfolioID string     `json:"portfolio_id"`
+	At          time.Time  `json:"at"`
+	Holdings    []*Holding `json:"holdings"`
+}
+
+// ToDomainLedgerEntry converts the request to a draft entry; the token is resolved separately
+func ToDomainLedgerEntry(req LedgerEntryRequest) (*holding.LedgerEntry, error) {
+	entryType, err := holding.ParseEntryType(strings.ToLower(strings.TrimSpace(req.Type)))
+	if err != nil {
+		return nil, err
+	}
+
+	quantity, ok := new(big.Int).SetString(strings.TrimSpace(req.Quantity), 10)
+	if !ok {
+		return nil, fmt.Errorf("%w: quantity must be an integer in token base units", holding.ErrInvalidEntry)
+	}
+	if entryType == holding.EntryWithdrawal && quantity.Sign() > 0 {
+		quantity.Neg(quantity)
+	}
+
+	e := &holding.LedgerEntry{
+		Type:         entryType,
+		Quantity:     quantity,
+		Note:         req.Note,
+		Counterparty: req.Counterparty,
+	}
+	if req.Timestamp != nil {
+		e.OccurredAt = *req.Timestamp
+	}
+	if req.Price != "" {
+		if e.Price, err = ParseDecimal(req.Price, price.CurrencyDecimal); err != nil {
+			return nil, fmt.Errorf("%w: %v", holding.ErrInvalidEntry, err)
+		}
+		e.Currency = req.Currency
+		if e.Currency == "" {
+			e.Currency = "usd"
+		}
+	}
+	return e, nil
+}
+
+// ToHTTPLedgerEntries converts domain ledger entries to HTTP LedgerEntries
+func ToHTTPLedgerEntries(entries []*holding.LedgerEntry) []*LedgerEntry {
+	result := make([]*LedgerEntry, 0, len(entries))
+	for _, e := range entries {
+		entry := &LedgerEntry{
+			ID:           e.ID,
+			HoldingID:    e.HoldingID,
+			TokenAddress: e.Token.Address,
+			TokenSymbol:  e.Token.Symbol,
+			Type:         string(e.Type),
+			Quantity:     e.Quantity.String(),
+			Currency:     e.Currency,
+			Note:         e.Note,
+			Counterparty: e.Counterparty,
+			Timestamp:    e.OccurredAt,
+			CreatedAt:    e.CreatedAt,
+		}
+		if e.Price != nil {
+			entry.Price = FormatDecimal(e.Price, price.CurrencyDecimal)
+		}
+		result = append(result, entry)
+	}
+	return result
+}
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"time"
+
+	"testtask/internal/domain/portfolio"
+	"testtask/internal/domain/price"
+)
+
+// ManualEntryRequest represents the request body for creating or updating a manual asset or
+// liability. Amounts are decimal strings. Fixed valuation sets value; unit_price valuation sets
+// quantity and unit_price, both in currency; market valuation sets quantity and token_address.
+type ManualEntryRequest struct {
+	Name         string `json:"name"`
+	Side         string `json:"side"`
+	Category     string `json:"category,omitempty"`
+	Valuation    string `json:"valuation"`
+	Currency     string `json:"currency,omitempty"`
+	Value        string `json:"value,omitempty"`
+	Quantity     string `json:"quantity,omitempty"`
+	UnitPrice    string `json:"unit_price,omitempty"`
+	TokenAddress string `json:"token_address,omitempty"`
+	Note         string `json:"note,omitempty"`
+}
+
+// ManualEntry represents an off-chain asset or liability maintained by hand
+type ManualEntry struct {
+	ID           string    `json:"id"`
+	PortfolioID  string    `json:"portfolio_id"`
+	Name         string    `json:"name"`
+	Side         string    `json:"side"`
+	Category     string    `json:"category"`
+	Valuation    string    `json:"valuation"`
+	Currency     string    `json:"currency,omitempty"`
+	Value        string    `json:"value,omitempty"`
+	Quantity     string    `json:"quantity,omitempty"`
+	UnitPrice    string    `json:"unit_price,omitempty"`
+	TokenAddress string    `json:"token_address,omitempty"`
+	Note         string    `json:"note,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// ToHTTPManualEntry converts a domain manual entry to HTTP ManualEntry
+func ToHTTPManualEntry(e *portfolio.ManualEntry) *ManualEntry {
+	if e == nil {
+		return nil
+	}
+	return &ManualEntry{
+		ID:           e.ID,
+		PortfolioID:  e.PortfolioID,
+		Name:         e.Name,
+		Side:         string(e.Side),
+		Category:     string(e.Category),
+		Valuation:    string(e.Valuation),
+		Currency:     e.Currency,
+		Value:        FormatDecimal(e.Value, price.CurrencyDecimal),
+		Quantity:     FormatDecimal(e.Quantity, portfolio.ManualQuantityDecimals),
+		UnitPrice:    FormatDecimal(e.UnitPrice, price.CurrencyDecimal),
+		TokenAddress: e.TokenAddress,
+		Note:         e.Note,
+		CreatedAt:    e.CreatedAt,
+		UpdatedAt:    e.UpdatedAt,
+	}
+}
+
+// ToHTTPManualEntries converts domain manual entries to HTTP ManualEntries
+func ToHTTPManualEntries(entries []*portfolio.ManualEntry) []*ManualEntry {
+	result := make([]*ManualEntry, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, ToHTTPManualEntry(e))
+	}
+	return result
+}
@@ -6,6 +6,7 @@ import (
 
 	domainHolding "testtask/internal/domain/holding"
 	domainPortfolio "testtask/internal/domain/portfolio"
+	"testtask/internal/domain/price"
 	"testtask/internal/domain/transaction"
 )
 
@@ -25,6 +26,15 @@ func bigIntToUSDFloat(value *big.Int) float64 {
 	return math.Round(floatVal*100000000) / 100000000
 }
 
+// optionalUSDFloat converts an optional fixed-point value, keeping nil as nil
+func optionalUSDFloat(value *big.Int) *float64 {
+	if value == nil {
+		return nil
+	}
+	f := bigIntToUSDFloat(value)
+	return &f
+}
+
 func ToHTTPTransaction(t *transaction.Transaction) *Transaction {
 	if t == nil {
 		return nil
@@ -37,19 +47,29 @@ func ToHTTPTransaction(t *transaction.Transaction) *Transaction {
 	}
 
 	return &Transaction{
-		ID:           t.ID,
-		Hash:         t.Hash,
-		From:         t.From,
-		To:           t.To,
-		TokenAddress: t.TokenAddress,
-		TokenSymbol:  t.TokenSymbol,
-		Amount:       amountStr,
-		Type:         string(t.Type),
-		Status:       string(t.Status),
-		Direction:    string(t.Direction),
-		Method:       t.Method,
-		Timestamp:    t.Timestamp,
-		BlockNumber:  t.BlockNumber,
+		ID:                t.ID,
+		Hash:              t.Hash,
+		From:              t.From,
+		To:                t.To,
+		TokenAddress:      t.TokenAddress,
+		TokenSymbol:       t.TokenSymbol,
+		Amount:            amountStr,
+		Type:              string(t.Type),
+		Status:            string(t.Status),
+		Direction:         string(t.Direction),
+		Method:            t.Method,
+		Timestamp:         t.Timestamp,
+		BlockNumber:       t.BlockNumber,
+		Income:            string(t.Income),
+		Source:            t.Source,
+		Note:              t.Note,
+		Tags:              t.Tags,
+		Category:          t.Category,
+		FromLabel:         t.FromLabel,
+		ToLabel:           t.ToLabel,
+		CounterpartyLabel: t.CounterpartyLabel(),
+		FromENS:           t.FromENS,
+		ToENS:             t.ToENS,
 	}
 }
 
@@ -100,6 +120,15 @@ func ToDomainTransaction(t *Transaction) *transaction.Transaction {
 		Method:       t.Method,
 		Timestamp:    t.Timestamp,
 		BlockNumber:  t.BlockNumber,
+		Income:       transaction.IncomeCategory(t.Income),
+		Source:       t.Source,
+		Note:         t.Note,
+		Tags:         t.Tags,
+		Category:     t.Category,
+		FromLabel:    t.FromLabel,
+		ToLabel:      t.ToLabel,
+		FromENS:      t.FromENS,
+		ToENS:        t.ToENS,
 	}
 }
 
@@ -112,6 +141,7 @@ func ToHTTPHolding(h *domainHolding.Holding) *Holding {
 		TokenAddress: h.Token.Address,
 		TokenSymbol:  h.Token.Symbol,
 		Amount:       h.Amount,
+		Version:      h.Version,
 	}
 }
 
@@ -135,11 +165,7 @@ func ToDomainHolding(h *Holding) *domainHolding.Holding {
 }
 
 func ToHTTPPortfolios(p []*domainPortfolio.Portfolio) []*Portfolio {
-	if p == nil {
-		return nil
-	}
-
-	var result []*Portfolio
+	result := make([]*Portfolio, 0, len(p))
 	for _, portf := range p {
 		result = append(result, ToHTTPPortfolio(portf))
 	}
@@ -153,8 +179,15 @@ func ToHTTPPortfolio(p *domainPortfolio.Portfolio) *Portfolio {
 	}
 	holdings := ToHTTPHoldings(p.Holdings)
 	return &Portfolio{
-		ID:       p.ID,
-		Holdings: holdings,
+		ID:          p.ID,
+		Address:     p.Address,
+		Name:        p.Name,
+		Description: p.Description,
+		Tags:        p.Tags,
+		Archived:    p.Archived,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+		Holdings:    holdings,
 	}
 }
 
@@ -164,15 +197,50 @@ func ToHTTPPortfolioAssets(pa *domainPortfolio.Portfolio, a []*domainPortfolio.A
 		return nil
 	}
 
+	assets := ToHTTPAssets(a)
+	summary := domainPortfolio.SummarizeAssets(a)
+
+	return &PortfolioAssets{
+		PortfolioID:    pa.ID,
+		Address:        pa.Address,
+		TotalValueUSD:  bigIntToUSDFloat(summary.TotalValue),
+		GrossAssetsUSD: bigIntToUSDFloat(summary.GrossAssets),
+		LiabilitiesUSD: bigIntToUSDFloat(summary.Liabilities),
+		Change24hUSD:   bigIntToUSDFloat(summary.Change24h),
+		Change24hPct:   optionalUSDFloat(summary.Change24hPct),
+		Assets:         assets,
+	}
+}
+
+// ToHTTPAssets converts a slice of service Assets to HTTP Assets
+func ToHTTPAssets(a []*domainPortfolio.Asset) []*Asset {
 	assets := make([]*Asset, len(a))
 	for i, asset := range a {
 		assets[i] = ToHTTPAsset(asset)
 	}
+	return assets
+}
 
-	return &PortfolioAssets{
-		PortfolioID: pa.ID,
-		Address:     pa.Address,
-		Assets:      assets,
+// ToHTTPTopMovers converts top gainers and losers to HTTP TopMovers
+func ToHTTPTopMovers(portfolioID string, gainers, losers []*domainPortfolio.Asset) *TopMovers {
+	return &TopMovers{
+		PortfolioID: portfolioID,
+		Gainers:     ToHTTPAssets(gainers),
+		Losers:      ToHTTPAssets(losers),
+	}
+}
+
+// ToHTTPMarketData converts price market data to HTTP MarketData
+func ToHTTPMarketData(m *price.MarketData) *MarketData {
+	if m == nil {
+		return nil
+	}
+	// Percent changes share the 8 decimals scale with currency values
+	return &MarketData{
+		Change24hPct: optionalUSDFloat(m.Change24h),
+		Change7dPct:  optionalUSDFloat(m.Change7d),
+		Volume24hUSD: optionalUSDFloat(m.Volume24h),
+		MarketCapUSD: optionalUSDFloat(m.MarketCap),
 	}
 }
 
@@ -190,12 +258,17 @@ func ToHTTPAsset(a *domainPortfolio.Asset) *Asset {
 			Symbol:  a.Token.Symbol,
 			Address: a.Token.Address,
 			Decimal: a.Token.Decimal,
+			LogoURI: a.Token.LogoURI,
 		}
 	}
 
 	var priceUSD float64
+	var market *MarketData
+	var priceSource string
 	if a.Price != nil && a.Price.Value != nil {
 		priceUSD = bigIntToUSDFloat(a.Price.Value)
+		market = ToHTTPMarketData(a.Price.Market)
+		priceSource = a.Price.Source
 	}
 
 	var valueUSD float64
@@ -204,10 +277,24 @@ func ToHTTPAsset(a *domainPortfolio.Asset) *Asset {
 	}
 
 	return &Asset{
-		Token:    tokenInfo,
-		Amount:   a.Amount,
-		ValueUSD: valueUSD,
-		PriceUSD: priceUSD,
-		Source:   a.Source,
+		Token:             tokenInfo,
+		Amount:            a.Amount,
+		ValueUSD:          valueUSD,
+		PriceUSD:          priceUSD,
+		ValueChange24hUSD: optionalUSDFloat(a.ValueChange24h),
+		Market:            market,
+		Source:            a.Source,
+		PriceSource:       priceSource,
+		PriceOverridden:   a.Price.Overridden(),
+		PriceOverrideID:   overrideID(a.Price),
+		Components:        toHTTPComponents(a.Components),
+		Manual:            ToHTTPManualEntry(a.Manual),
+	}
+}
+
+func toHTTPComponents(components []*domainPortfolio.Asset) []*Asset {
+	if len(components) == 0 {
+		return nil
 	}
+	return ToHTTPAssets(components)
 }
@@ -0,0 +1,111 @@
+package http
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"testtask/internal/domain/price"
+	"testtask/internal/domain/token"
+)
+
+// TokenPrice represents a lossless decimal price of a token with its provenance
+type TokenPrice struct {
+	Token       *TokenInfo  `json:"token"`
+	Price       string      `json:"price"`
+	Currency    string      `json:"currency"`
+	Source      string      `json:"source"`
+	Cached      bool        `json:"cached"`
+	LastUpdated time.Time   `json:"last_updated"`
+	Market      *MarketData `json:"market,omitempty"`
+}
+
+// PricesResponse represents the response of the batch price query
+type PricesResponse struct {
+	Currency      string        `json:"currency"`
+	At            *time.Time    `json:"at,omitempty"`
+	Prices        []*TokenPrice `json:"prices"`
+	UnknownTokens []string      `json:"unknown_tokens,omitempty"`
+	Unpriced      []string      `json:"unpriced_tokens,omitempty"`
+}
+
+// FormatDecimal formats a fixed-point integer with the given number of
+// decimals as a decimal string without losing precision, e.g. 300012345678
+// with 8 decimals becomes "3000.12345678".
+func FormatDecimal(value *big.Int, decimals int) string {
+	if value == nil {
+		return ""
+	}
+
+	digits := new(big.Int).Abs(value).String()
+	if decimals > 0 {
+		if len(digits) <= decimals {
+			digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-decimals] + "." + digits[len(digits)-decimals:]
+	}
+
+	if value.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// ParseDecimal parses a decimal string such as "3000.12" into a fixed-point
+// integer with the given number of decimals. Extra fractional digits are rejected
+// rather than rounded.
+func ParseDecimal(s string, decimals int) (*big.Int, error) {
+	s = strings.TrimSpace(s)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" {
+		return nil, fmt.Errorf("invalid decimal %q", s)
+	}
+	if len(frac) > decimals {
+		return nil, fmt.Errorf("decimal %q has more than %d fractional digits", s, decimals)
+	}
+
+	digits := whole + frac + strings.Repeat("0", decimals-len(frac))
+	value, ok := new(big.Int).SetString(digits, 10)
+	if !ok || strings.ContainsAny(digits, "+-") {
+		return nil, fmt.Errorf("invalid decimal %q", s)
+	}
+	if negative {
+		value.Neg(value)
+	}
+	return value, nil
+}
+
+// ToHTTPTokenInfo converts a domain token to HTTP TokenInfo
+func ToHTTPTokenInfo(t *token.Token) *TokenInfo {
+	if t == nil {
+		return nil
+	}
+	return &TokenInfo{
+		ID:      t.ID,
+		Name:    t.Name,
+		Symbol:  t.Symbol,
+		Address: t.Address,
+		Decimal: t.Decimal,
+		LogoURI: t.LogoURI,
+	}
+}
+
+// ToHTTPTokenPrice converts a domain price to HTTP TokenPrice
+func ToHTTPTokenPrice(t *token.Token, p *price.Price) *TokenPrice {
+	if p == nil {
+		return nil
+	}
+	return &TokenPrice{
+		Token:       ToHTTPTokenInfo(t),
+		Price:       FormatDecimal(p.Value, price.CurrencyDecimal),
+		Currency:    p.Currency,
+		Source:      p.Source,
+		Cached:      p.Cached,
+		LastUpdated: p.LastUpdated,
+		Market:      ToHTTPMarketData(p.Market),
+	}
+}
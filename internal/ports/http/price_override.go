@@ -0,0 +1,106 @@
+package http
+
+import (
+	"time"
+
+	"testtask/internal/domain/price"
+)
+
+// PriceOverrideRequest represents the request body for creating or updating a price override.
+// Fixed overrides set value (decimal string) and currency (default usd); relative overrides
+// set reference_address and adjustment_bps, e.g. -2000 for a 20% discount.
+type PriceOverrideRequest struct {
+	TokenAddress     string     `json:"token_address"`
+	Kind             string     `json:"kind"`
+	Value            string     `json:"value,omitempty"`
+	Currency         string     `json:"currency,omitempty"`
+	ReferenceAddress string     `json:"reference_address,omitempty"`
+	AdjustmentBps    int64      `json:"adjustment_bps,omitempty"`
+	EffectiveFrom    *time.Time `json:"effective_from,omitempty"`
+	EffectiveTo      *time.Time `json:"effective_to,omitempty"`
+	Reason           string     `json:"reason,omitempty"`
+}
+
+// PriceOverride represents a portfolio price override
+type PriceOverride struct {
+	ID               string     `json:"id"`
+	PortfolioID      string     `json:"portfolio_id"`
+	TokenAddress     string     `json:"token_address"`
+	Kind             string     `json:"kind"`
+	Value            string     `json:"value,omitempty"`
+	Currency         string     `json:"currency,omitempty"`
+	ReferenceAddress string     `json:"reference_address,omitempty"`
+	AdjustmentBps    int64      `json:"adjustment_bps,omitempty"`
+	EffectiveFrom    time.Time  `json:"effective_from"`
+	EffectiveTo      *time.Time `json:"effective_to,omitempty"`
+	Reason           string     `json:"reason,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// PriceOverrideAuditEntry represents one change in the price override audit trail
+type PriceOverrideAuditEntry struct {
+	ID         string         `json:"id"`
+	OverrideID string         `json:"override_id"`
+	Action     string         `json:"action"`
+	Before     *PriceOverride `json:"before,omitempty"`
+	After      *PriceOverride `json:"after,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+// ToHTTPPriceOverride converts a domain override to HTTP PriceOverride
+func ToHTTPPriceOverride(o *price.Override) *PriceOverride {
+	if o == nil {
+		return nil
+	}
+	result := &PriceOverride{
+		ID:               o.ID,
+		PortfolioID:      o.PortfolioID,
+		TokenAddress:     o.TokenAddress,
+		Kind:             string(o.Kind),
+		Currency:         o.Currency,
+		ReferenceAddress: o.ReferenceAddress,
+		AdjustmentBps:    o.AdjustmentBps,
+		EffectiveFrom:    o.EffectiveFrom,
+		EffectiveTo:      o.EffectiveTo,
+		Reason:           o.Reason,
+		CreatedAt:        o.CreatedAt,
+		UpdatedAt:        o.UpdatedAt,
+	}
+	if o.Value != nil {
+		result.Value = FormatDecimal(o.Value, price.CurrencyDecimal)
+	}
+	return result
+}
+
+// ToHTTPPriceOverrides converts domain overrides to HTTP PriceOverrides
+func ToHTTPPriceOverrides(overrides []*price.Override) []*PriceOverride {
+	result := make([]*PriceOverride, 0, len(overrides))
+	for _, o := range overrides {
+		result = append(result, ToHTTPPriceOverride(o))
+	}
+	return result
+}
+
+// ToHTTPPriceOverrideAudit converts domain audit entries to HTTP PriceOverrideAuditEntries
+func ToHTTPPriceOverrideAudit(entries []*price.OverrideAuditEntry) []*PriceOverrideAuditEntry {
+	result := make([]*PriceOverrideAuditEntry, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, &PriceOverrideAuditEntry{
+			ID:         e.ID,
+			OverrideID: e.OverrideID,
+			Action:     e.Action,
+			Before:     ToHTTPPriceOverride(e.Before),
+			After:      ToHTTPPriceOverride(e.After),
+			CreatedAt:  e.CreatedAt,
+		})
+	}
+	return result
+}
+
+func overrideID(p *price.Price) string {
+	if p == nil {
+		return ""
+	}
+	return p.OverrideID
+}
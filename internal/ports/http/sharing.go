@@ -0,0 +1,102 @@
+package http
+
+import (
+	"time"
+
+	"testtask/internal/domain/portfolio"
+)
+
+// AddMemberRequest represents the request body for sharing a portfolio with a user
+type AddMemberRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// UpdateMemberRequest represents the request body for changing a member's role
+type UpdateMemberRequest struct {
+	Role string `json:"role"`
+}
+
+// CreateShareTokenRequest represents the request body for creating a read-only link.
+// expires_at defaults to 30 days from now and may be at most one year ahead.
+type CreateShareTokenRequest struct {
+	Name      string     `json:"name,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Member represents a user with shared access to a portfolio
+type Member struct {
+	UserID    string    `json:"user_id"`
+	Email     string    `json:"email,omitempty"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ShareToken represents a read-only link without its secret
+type ShareToken struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name,omitempty"`
+	Prefix     string     `json:"prefix"`
+	Active     bool       `json:"active"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreateShareTokenResponse is returned once per link; the token cannot be retrieved again
+type CreateShareTokenResponse struct {
+	ShareToken *ShareToken `json:"share_token"`
+	Token      string      `json:"token"`
+	URL        string      `json:"url"`
+}
+
+// ToHTTPMember converts a domain member to HTTP Member
+func ToHTTPMember(m *portfolio.Member) *Member {
+	if m == nil {
+		return nil
+	}
+	return &Member{
+		UserID:    m.UserID,
+		Email:     m.Email,
+		Role:      string(m.Role),
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+// ToHTTPMembers converts domain members to HTTP Members
+func ToHTTPMembers(members []*portfolio.Member) []*Member {
+	result := make([]*Member, 0, len(members))
+	for _, m := range members {
+		result = append(result, ToHTTPMember(m))
+	}
+	return result
+}
+
+// ToHTTPShareToken converts a domain share token to HTTP ShareToken
+func ToHTTPShareToken(t *portfolio.ShareToken, now time.Time) *ShareToken {
+	if t == nil {
+		return nil
+	}
+	return &ShareToken{
+		ID:         t.ID,
+		Name:       t.Name,
+		Prefix:     t.Prefix,
+		Active:     t.Active(now),
+		ExpiresAt:  t.ExpiresAt,
+		RevokedAt:  t.RevokedAt,
+		LastUsedAt: t.LastUsedAt,
+		CreatedAt:  t.CreatedAt,
+	}
+}
+
+// ToHTTPShareTokens converts domain share tokens to HTTP ShareTokens
+func ToHTTPShareTokens(tokens []*portfolio.ShareToken, now time.Time) []*ShareToken {
+	result := make([]*ShareToken, 0, len(tokens))
+	for _, t := range tokens {
+		result = append(result, ToHTTPShareToken(t, now))
+	}
+	return result
+}
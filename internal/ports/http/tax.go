@@ -0,0 +1,306 @@
+package http
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"time"
+
+	"testtask/internal/domain/price"
+	"testtask/internal/domain/tax"
+)
+
+// Jurisdiction represents the capital gains rules of a jurisdiction
+type Jurisdiction struct {
+	Code           string   `json:"code"`
+	Name           string   `json:"name"`
+	Currency       string   `json:"currency"`
+	Method         string   `json:"method"`
+	AllowedMethods []string `json:"allowed_methods"`
+	LongTermYears  int      `json:"long_term_years"`
+	LongTermExempt bool     `json:"long_term_exempt"`
+	TaxYearStart   string   `json:"tax_year_start"` // MM-DD
+}
+
+// TaxReport represents a capital gains report for one tax year. Amounts are decimal strings
+// in the report currency.
+type TaxReport struct {
+	PortfolioID  string       `json:"portfolio_id"`
+	Jurisdiction string       `json:"jurisdiction"`
+	Method       string       `json:"method"`
+	Currency     string       `json:"currency"`
+	Year         int          `json:"year"`
+	From         time.Time    `json:"from"`
+	To           time.Time    `json:"to"`
+	Summary      *TaxSummary  `json:"summary"`
+	Gains        []*TaxGain   `json:"gains"`
+	Income       []*TaxIncome `json:"income"`
+	Warnings     []string     `json:"warnings,omitempty"`
+	GeneratedAt  time.Time    `json:"generated_at"`
+}
+
+// TaxSummary represents the totals of a tax report
+type TaxSummary struct {
+	Disposals     int    `json:"disposals"`
+	Proceeds      string `json:"proceeds"`
+	CostBasis     string `json:"cost_basis"`
+	NetGain       string `json:"net_gain"`
+	ShortTermGain string `json:"short_term_gain"`
+	LongTermGain  string `json:"long_term_gain"`
+	ExemptGain    string `json:"exempt_gain"`
+	TaxableGain   string `json:"taxable_gain"`
+
+	Income           string            `json:"income"`
+	IncomeByCategory map[string]string `json:"income_by_category"`
+}
+
+// TaxGain represents the gain of a disposal matched against one lot
+type TaxGain struct {
+	TokenAddress string     `json:"token_address"`
+	Symbol       string     `json:"symbol"`
+	Quantity     string     `json:"quantity"`
+	AcquiredAt   *time.Time `json:"acquired_at,omitempty"` // absent when the cost basis is pooled or unknown
+	DisposedAt   time.Time  `json:"disposed_at"`
+	Proceeds     string     `json:"proceeds"`
+	CostBasis    string     `json:"cost_basis"`
+	Gain         string     `json:"gain"`
+	Term         string     `json:"term,omitempty"`
+	Exempt       bool       `json:"exempt"`
+	Match        string     `json:"match"`
+	Reference    string     `json:"reference,omitempty"`
+}
+
+// TaxIncome represents income received, valued at receipt; the value is also the cost basis
+// of the lot it opens
+type TaxIncome struct {
+	TokenAddress string    `json:"token_address"`
+	Symbol       string    `json:"symbol"`
+	Quantity     string    `json:"quantity"`
+	ReceivedAt   time.Time `json:"received_at"`
+	Category     string    `json:"category"`
+	Value        *string   `json:"value"` // null when no price was found
+	Source       string    `json:"source"`
+	Reference    string    `json:"reference,omitempty"`
+}
+
+// ToHTTPJurisdictions converts jurisdiction rules to HTTP Jurisdictions
+func ToHTTPJurisdictions(rules []*tax.Rules) []*Jurisdiction {
+	result := make([]*Jurisdiction, 0, len(rules))
+	for _, r := range rules {
+		allowed := []string{string(r.Method)}
+		for _, m := range r.AllowedMethods {
+			if m != r.Method {
+				allowed = append(allowed, string(m))
+			}
+		}
+		result = append(result, &Jurisdiction{
+			Code:           r.Code,
+			Name:           r.Name,
+			Currency:       r.Currency,
+			Method:         string(r.Method),
+			AllowedMethods: allowed,
+			LongTermYears:  r.LongTermYears,
+			LongTermExempt: r.LongTermExempt,
+			TaxYearStart:   fmt.Sprintf("%02d-%02d", int(r.YearStartMonth), r.YearStartDay),
+		})
+	}
+	return result
+}
+
+// ToHTTPTaxReport converts a domain tax report to HTTP TaxReport
+func ToHTTPTaxReport(r *tax.Report) *TaxReport {
+	if r == nil {
+		return nil
+	}
+	gains := make([]*TaxGain, 0, len(r.Gains))
+	for _, g := range r.Gains {
+		gains = append(gains, &TaxGain{
+			TokenAddress: g.Asset,
+			Symbol:       g.Symbol,
+			Quantity:     FormatDecimal(g.Quantity, int(g.Decimals)),
+			AcquiredAt:   g.AcquiredAt,
+			DisposedAt:   g.DisposedAt,
+			Proceeds:     FormatDecimal(g.Proceeds, price.CurrencyDecimal),
+			CostBasis:    FormatDecimal(g.CostBasis, price.CurrencyDecimal),
+			Gain:         FormatDecimal(g.Gain, price.CurrencyDecimal),
+			Term:         string(g.Term),
+			Exempt:       g.Exempt,
+			Match:        string(g.Match),
+			Reference:    g.Reference,
+		})
+	}
+	received := make([]*TaxIncome, 0, len(r.Income))
+	for _, e := range r.Income {
+		item := &TaxIncome{
+			TokenAddress: e.Asset,
+			Symbol:       e.Symbol,
+			Quantity:     FormatDecimal(e.Quantity, int(e.Decimals)),
+			ReceivedAt:   e.At,
+			Category:     string(e.Income),
+			Source:       e.Source,
+			Reference:    e.Reference,
+		}
+		if e.Value != nil {
+			value := FormatDecimal(e.Value, price.CurrencyDecimal)
+			item.Value = &value
+		}
+		received = append(received, item)
+	}
+	s := r.Summary
+	byCategory := make(map[string]string, len(s.IncomeByCategory))
+	for category, total := range s.IncomeByCategory {
+		byCategory[string(category)] = FormatDecimal(total, price.CurrencyDecimal)
+	}
+	return &TaxReport{
+		PortfolioID:  r.PortfolioID,
+		Jurisdiction: r.Jurisdiction,
+		Method:       string(r.Method),
+		Currency:     r.Currency,
+		Year:         r.Year,
+		From:         r.From,
+		To:           r.To,
+		Summary: &TaxSummary{
+			Disposals:     s.Disposals,
+			Proceeds:      FormatDecimal(s.Proceeds, price.CurrencyDecimal),
+			CostBasis:     FormatDecimal(s.CostBasis, price.CurrencyDecimal),
+			NetGain:       FormatDecimal(s.NetGain, price.CurrencyDecimal),
+			ShortTermGain: FormatDecimal(s.ShortTermGain, price.CurrencyDecimal),
+			LongTermGain:  FormatDecimal(s.LongTermGain, price.CurrencyDecimal),
+			ExemptGain:    FormatDecimal(s.ExemptGain, price.CurrencyDecimal),
+			TaxableGain:   FormatDecimal(s.TaxableGain, price.CurrencyDecimal),
+
+			Income:           FormatDecimal(s.Income, price.CurrencyDecimal),
+			IncomeByCategory: byCategory,
+		},
+		Gains:       gains,
+		Income:      received,
+		Warnings:    r.Warnings,
+		GeneratedAt: r.GeneratedAt,
+	}
+}
+
+// WriteTaxReportCSV writes the gains of a report as CSV, one row per matched lot, with
+// amounts rounded to cents
+func WriteTaxReportCSV(w io.Writer, r *tax.Report) error {
+	rows := [][]string{{
+		"disposed_at", "acquired_at", "token_address", "symbol", "quantity",
+		"proceeds", "cost_basis", "gain", "currency", "term", "exempt", "match", "reference",
+	}}
+	for _, g := range r.Gains {
+		acquired := ""
+		if g.AcquiredAt != nil {
+			acquired = g.AcquiredAt.UTC().Format(time.RFC3339)
+		}
+		rows = append(rows, []string{
+			g.DisposedAt.UTC().Format(time.RFC3339),
+			acquired,
+			g.Asset,
+			g.Symbol,
+			trimDecimal(FormatDecimal(g.Quantity, int(g.Decimals))),
+			formatCents(g.Proceeds),
+			formatCents(g.CostBasis),
+			formatCents(g.Gain),
+			r.Currency,
+			string(g.Term),
+			strconv.FormatBool(g.Exempt),
+			string(g.Match),
+			g.Reference,
+		})
+	}
+	return writeCSV(w, rows)
+}
+
+// WriteIncomeCSV writes the income of a report as CSV, one row per receipt, with values
+// rounded to cents; the value of unpriced income is left empty
+func WriteIncomeCSV(w io.Writer, r *tax.Report) error {
+	rows := [][]string{{
+		"received_at", "category", "token_address", "symbol", "quantity", "value", "currency", "source", "reference",
+	}}
+	for _, e := range r.Income {
+		value := ""
+		if e.Value != nil {
+			value = formatCents(e.Value)
+		}
+		rows = append(rows, []string{
+			e.At.UTC().Format(time.RFC3339),
+			string(e.Income),
+			e.Asset,
+			e.Symbol,
+			trimDecimal(FormatDecimal(e.Quantity, int(e.Decimals))),
+			value,
+			r.Currency,
+			e.Source,
+			e.Reference,
+		})
+	}
+	return writeCSV(w, rows)
+}
+
+// WriteForm8949CSV writes the gains in the layout of IRS Form 8949: short-term gains in
+// Part I and long-term gains in Part II, each followed by its totals. Reports of
+// jurisdictions that do not classify terms cannot be written.
+func WriteForm8949CSV(w io.Writer, r *tax.Report) error {
+	parts := map[tax.Term][]*tax.Gain{}
+	for _, g := range r.Gains {
+		if g.Term != tax.TermShort && g.Term != tax.TermLong {
+			return fmt.Errorf("%w: Form 8949 needs gains classified as short or long-term", tax.ErrInvalidReport)
+		}
+		parts[g.Term] = append(parts[g.Term], g)
+	}
+
+	rows := [][]string{{
+		"Part", "(a) Description of property", "(b) Date acquired", "(c) Date sold or disposed of",
+		"(d) Proceeds", "(e) Cost or other basis", "(f) Code(s)", "(g) Amount of adjustment", "(h) Gain or (loss)",
+	}}
+	for _, part := range []struct {
+		name string
+		term tax.Term
+	}{{"I", tax.TermShort}, {"II", tax.TermLong}} {
+		gains := parts[part.term]
+		if len(gains) == 0 {
+			continue
+		}
+		for _, g := range gains {
+			acquired := "VARIOUS"
+			if g.AcquiredAt != nil {
+				acquired = g.AcquiredAt.UTC().Format("01/02/2006")
+			}
+			rows = append(rows, []string{
+				part.name,
+				trimDecimal(FormatDecimal(g.Quantity, int(g.Decimals))) + " " + g.Symbol,
+				acquired,
+				g.DisposedAt.UTC().Format("01/02/2006"),
+				formatCents(g.Proceeds),
+				formatCents(g.CostBasis),
+				"",
+				"",
+				formatCents(g.Gain),
+			})
+		}
+		s := tax.Summarize(gains)
+		rows = append(rows, []string{part.name, "Totals", "", "", formatCents(s.Proceeds), formatCents(s.CostBasis), "", "", formatCents(s.NetGain)})
+	}
+	return writeCSV(w, rows)
+}
+
+func writeCSV(w io.Writer, rows [][]string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+// formatCents rounds a currency amount scaled by price.CurrencyDecimal to cents, half away from zero
+func formatCents(v *big.Int) string {
+	divisor := new(big.Int).Exp(big.NewInt(10), big.NewInt(price.CurrencyDecimal-2), nil)
+	half := new(big.Int).Quo(divisor, big.NewInt(2))
+	cents := new(big.Int).Abs(v)
+	cents.Add(cents, half).Quo(cents, divisor)
+	if v.Sign() < 0 {
+		cents.Neg(cents)
+	}
+	return FormatDecimal(cents, 2)
+}
@@ -0,0 +1,92 @@
+package http
+
+import (
+	"time"
+
+	"testtask/internal/domain/user"
+)
+
+// RegisterRequest represents the request body for creating a user
+type RegisterRequest struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+// CreateAPIKeyRequest represents the request body for issuing an API key
+type CreateAPIKeyRequest struct {
+	Name string `json:"name,omitempty"`
+}
+
+// User represents an API user
+type User struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name,omitempty"`
+	Admin     bool      `json:"admin,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// APIKey represents an API key without its secret
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// RegisterResponse is returned once on registration; the API key cannot be retrieved again
+type RegisterResponse struct {
+	User   *User  `json:"user"`
+	APIKey string `json:"api_key"`
+}
+
+// CreateAPIKeyResponse is returned once per issued key; the secret cannot be retrieved again
+type CreateAPIKeyResponse struct {
+	Key    *APIKey `json:"key"`
+	APIKey string  `json:"api_key"`
+}
+
+// AccessTokenResponse represents an issued access token
+type AccessTokenResponse struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// ToHTTPUser converts a domain user to HTTP User
+func ToHTTPUser(u *user.User) *User {
+	if u == nil {
+		return nil
+	}
+	return &User{
+		ID:        u.ID,
+		Email:     u.Email,
+		Name:      u.Name,
+		Admin:     u.Admin,
+		CreatedAt: u.CreatedAt,
+	}
+}
+
+// ToHTTPAPIKey converts a domain API key to HTTP APIKey
+func ToHTTPAPIKey(k *user.APIKey) *APIKey {
+	if k == nil {
+		return nil
+	}
+	return &APIKey{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		CreatedAt:  k.CreatedAt,
+		LastUsedAt: k.LastUsedAt,
+	}
+}
+
+// ToHTTPAPIKeys converts domain API keys to HTTP APIKeys
+func ToHTTPAPIKeys(keys []*user.APIKey) []*APIKey {
+	result := make([]*APIKey, 0, len(keys))
+	for _, k := range keys {
+		result = append(result, ToHTTPAPIKey(k))
+	}
+	return result
+}
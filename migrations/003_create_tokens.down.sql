@@ -0,0 +1,7 @@
+-- Migration: Drop tokens registry table
+-- Rollback: Remove token registry
+
+DROP INDEX IF EXISTS idx_tokens_refreshed_at;
+DROP INDEX IF EXISTS idx_tokens_symbol;
+
+DROP TABLE IF EXISTS tokens;
@@ -0,0 +1,18 @@
+-- Migration: Create tokens registry table
+-- Created: Persist token registry and tokens discovered from transfer history
+
+CREATE TABLE IF NOT EXISTS tokens (
+    address TEXT PRIMARY KEY,
+    coingecko_id TEXT NOT NULL DEFAULT '',
+    name TEXT NOT NULL DEFAULT '',
+    symbol TEXT NOT NULL,
+    decimals INTEGER NOT NULL,
+    source TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    refreshed_at DATETIME
+);
+
+-- Create indexes for better query performance
+CREATE INDEX IF NOT EXISTS idx_tokens_symbol ON tokens(symbol);
+CREATE INDEX IF NOT EXISTS idx_tokens_refreshed_at ON tokens(refreshed_at);
@@ -0,0 +1,6 @@
+-- Migration: Drop custom tokens table
+-- Rollback: Remove custom tokens and their manual prices
+
+DROP INDEX IF EXISTS idx_custom_tokens_portfolio_id;
+DROP INDEX IF EXISTS idx_custom_tokens_address;
+DROP TABLE IF EXISTS custom_tokens;
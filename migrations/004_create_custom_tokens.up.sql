@@ -0,0 +1,22 @@
+-- Migration: Create custom tokens table
+-- Created: User-defined tokens with optional manual price feed
+
+CREATE TABLE IF NOT EXISTS custom_tokens (
+    id TEXT PRIMARY KEY,
+    portfolio_id TEXT,
+    chain_id TEXT NOT NULL,
+    address TEXT NOT NULL,
+    name TEXT NOT NULL,
+    symbol TEXT NOT NULL,
+    decimals INTEGER NOT NULL,
+    manual_price TEXT,
+    price_currency TEXT NOT NULL DEFAULT '',
+    price_updated_at DATETIME,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    FOREIGN KEY (portfolio_id) REFERENCES portfolios(id) ON DELETE CASCADE
+);
+
+-- A contract address can only be defined once, globally or for one portfolio
+CREATE UNIQUE INDEX IF NOT EXISTS idx_custom_tokens_address ON custom_tokens(address);
+CREATE INDEX IF NOT EXISTS idx_custom_tokens_portfolio_id ON custom_tokens(portfolio_id);
@@ -0,0 +1,8 @@
+-- Migration: Drop price overrides and their audit trail
+-- Rollback: Remove price overrides
+
+DROP INDEX IF EXISTS idx_price_override_audit_portfolio;
+DROP INDEX IF EXISTS idx_price_overrides_portfolio_token;
+
+DROP TABLE IF EXISTS price_override_audit;
+DROP TABLE IF EXISTS price_overrides;
@@ -0,0 +1,33 @@
+-- Migration: Create price overrides and their audit trail
+-- Created: Manual prices for illiquid positions, scoped to a portfolio
+
+CREATE TABLE IF NOT EXISTS price_overrides (
+    id TEXT PRIMARY KEY,
+    portfolio_id TEXT NOT NULL,
+    token_address TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    value TEXT,
+    currency TEXT NOT NULL DEFAULT '',
+    reference_address TEXT NOT NULL DEFAULT '',
+    adjustment_bps INTEGER NOT NULL DEFAULT 0,
+    effective_from DATETIME NOT NULL,
+    effective_to DATETIME,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    FOREIGN KEY (portfolio_id) REFERENCES portfolios(id) ON DELETE CASCADE
+);
+
+-- Append-only; rows are never updated or deleted by the application
+CREATE TABLE IF NOT EXISTS price_override_audit (
+    id TEXT PRIMARY KEY,
+    override_id TEXT NOT NULL,
+    portfolio_id TEXT NOT NULL,
+    action TEXT NOT NULL,
+    before_state TEXT,
+    after_state TEXT,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_overrides_portfolio_token ON price_overrides(portfolio_id, token_address, effective_from);
+CREATE INDEX IF NOT EXISTS idx_price_override_audit_portfolio ON price_override_audit(portfolio_id, created_at);
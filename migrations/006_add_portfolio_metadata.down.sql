@@ -0,0 +1,10 @@
+-- Migration: Remove portfolio metadata columns
+-- Rollback: Portfolio CRUD completion
+
+DROP INDEX IF EXISTS idx_portfolios_archived;
+
+ALTER TABLE portfolios DROP COLUMN created_at;
+ALTER TABLE portfolios DROP COLUMN archived;
+ALTER TABLE portfolios DROP COLUMN tags;
+ALTER TABLE portfolios DROP COLUMN description;
+ALTER TABLE portfolios DROP COLUMN name;
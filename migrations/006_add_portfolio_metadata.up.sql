@@ -0,0 +1,13 @@
+-- Migration: Add name, description, tags and archived flag to portfolios
+-- Created: Portfolio CRUD completion
+
+ALTER TABLE portfolios ADD COLUMN name TEXT NOT NULL DEFAULT '';
+ALTER TABLE portfolios ADD COLUMN description TEXT NOT NULL DEFAULT '';
+-- JSON array of lowercase tags
+ALTER TABLE portfolios ADD COLUMN tags TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE portfolios ADD COLUMN archived INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE portfolios ADD COLUMN created_at DATETIME;
+
+UPDATE portfolios SET created_at = updated_at WHERE created_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_portfolios_archived ON portfolios(archived, updated_at);
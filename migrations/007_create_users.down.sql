@@ -0,0 +1,9 @@
+-- Migration: Drop users and API keys, remove portfolio ownership
+-- Rollback: Authentication and per-user portfolio ownership
+
+DROP INDEX IF EXISTS idx_portfolios_owner_id;
+ALTER TABLE portfolios DROP COLUMN owner_id;
+
+DROP INDEX IF EXISTS idx_api_keys_user_id;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,29 @@
+-- Migration: Create users and API keys, add portfolio ownership
+-- Created: Authentication and per-user portfolio ownership
+
+CREATE TABLE IF NOT EXISTS users (
+    id TEXT PRIMARY KEY,
+    email TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL
+);
+
+-- Only the SHA-256 hash of a key is stored; prefix identifies it in listings
+CREATE TABLE IF NOT EXISTS api_keys (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    prefix TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    created_at DATETIME NOT NULL,
+    last_used_at DATETIME,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys(user_id);
+
+-- Portfolios created before this migration have no owner and are not visible to any
+-- user until assigned: UPDATE portfolios SET owner_id = '<user id>' WHERE owner_id IS NULL;
+ALTER TABLE portfolios ADD COLUMN owner_id TEXT REFERENCES users(id);
+
+CREATE INDEX IF NOT EXISTS idx_portfolios_owner_id ON portfolios(owner_id);
@@ -0,0 +1,8 @@
+-- Migration: Drop portfolio members and share tokens
+-- Rollback: Portfolio sharing with roles and read-only links
+
+DROP INDEX IF EXISTS idx_share_tokens_portfolio_id;
+DROP TABLE IF EXISTS share_tokens;
+
+DROP INDEX IF EXISTS idx_portfolio_members_user_id;
+DROP TABLE IF EXISTS portfolio_members;
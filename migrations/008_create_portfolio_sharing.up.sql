@@ -0,0 +1,33 @@
+-- Migration: Create portfolio members and share tokens
+-- Created: Portfolio sharing with roles and read-only links
+
+-- Roles granted to users on portfolios they do not own: viewer, editor or admin
+CREATE TABLE IF NOT EXISTS portfolio_members (
+    portfolio_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    role TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    PRIMARY KEY (portfolio_id, user_id),
+    FOREIGN KEY (portfolio_id) REFERENCES portfolios(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_portfolio_members_user_id ON portfolio_members(user_id);
+
+-- Read-only links; only the SHA-256 hash of a token is stored
+CREATE TABLE IF NOT EXISTS share_tokens (
+    id TEXT PRIMARY KEY,
+    portfolio_id TEXT NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    prefix TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    created_by TEXT NOT NULL,
+    expires_at DATETIME NOT NULL,
+    revoked_at DATETIME,
+    last_used_at DATETIME,
+    created_at DATETIME NOT NULL,
+    FOREIGN KEY (portfolio_id) REFERENCES portfolios(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_share_tokens_portfolio_id ON share_tokens(portfolio_id);
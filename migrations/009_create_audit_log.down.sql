@@ -0,0 +1,8 @@
+-- Migration: Drop audit log
+-- Rollback: Append-only log of state-changing operations
+
+DROP TRIGGER IF EXISTS audit_log_no_delete;
+DROP TRIGGER IF EXISTS audit_log_no_update;
+DROP INDEX IF EXISTS idx_audit_log_actor_id;
+DROP INDEX IF EXISTS idx_audit_log_portfolio_id;
+DROP TABLE IF EXISTS audit_log;
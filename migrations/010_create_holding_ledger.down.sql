@@ -0,0 +1,6 @@
+-- Migration: Drop holding ledger
+-- Rollback: Ledger-based holdings; holdings keep their last projected amount
+
+DROP INDEX IF EXISTS idx_holding_ledger_holding_id;
+DROP INDEX IF EXISTS idx_holding_ledger_portfolio_id;
+DROP TABLE IF EXISTS holding_ledger;
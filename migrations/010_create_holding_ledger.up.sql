@@ -0,0 +1,32 @@
+-- Migration: Create holding ledger
+-- Created: Ledger-based holdings; holdings.amount becomes the sum of the entries
+
+-- Append-only changes of manual positions. quantity is the signed change in token base
+-- units; token columns are copied so history survives deleted holdings.
+CREATE TABLE IF NOT EXISTS holding_ledger (
+    id TEXT PRIMARY KEY,
+    portfolio_id TEXT NOT NULL,
+    holding_id TEXT NOT NULL,
+    chain_id INTEGER NOT NULL,
+    token_id TEXT NOT NULL,
+    token_symbol TEXT NOT NULL,
+    token_address TEXT NOT NULL,
+    entry_type TEXT NOT NULL,
+    quantity TEXT NOT NULL,
+    price TEXT,
+    currency TEXT NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    counterparty TEXT NOT NULL DEFAULT '',
+    occurred_at DATETIME NOT NULL,
+    created_at DATETIME NOT NULL,
+    FOREIGN KEY (portfolio_id) REFERENCES portfolios(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_holding_ledger_portfolio_id ON holding_ledger(portfolio_id, occurred_at);
+CREATE INDEX IF NOT EXISTS idx_holding_ledger_holding_id ON holding_ledger(holding_id);
+
+-- Open the ledger of existing holdings with their current amount
+INSERT INTO holding_ledger (id, portfolio_id, holding_id, chain_id, token_id, token_symbol, token_address, entry_type, quantity, note, occurred_at, created_at)
+SELECT lower(hex(randomblob(16))), portfolio_id, id, chain_id, token_id, token_symbol, token_address, 'adjustment', amount, 'opening balance', created_at, created_at
+FROM holdings
+WHERE amount != '0';
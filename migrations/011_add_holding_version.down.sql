@@ -0,0 +1,6 @@
+-- Migration: Remove holding version
+-- Rollback: Atomic, concurrency-safe holding updates; merged duplicate holdings stay merged
+
+DROP INDEX IF EXISTS idx_holdings_portfolio_token;
+
+ALTER TABLE holdings DROP COLUMN version;
@@ -0,0 +1,53 @@
+-- Migration: Add holding version and one holding per token
+-- Created: Atomic, concurrency-safe holding updates
+
+-- Incremented on every write for optimistic concurrency
+ALTER TABLE holdings ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+-- Concurrent adds could create several rows for one token. They are merged into the
+-- oldest row. Amounts are summed in two parts, the last 15 digits and the rest, so that
+-- each partial sum fits into a 64-bit integer.
+CREATE TEMP TABLE holding_merges AS
+SELECT
+    portfolio_id,
+    lower(token_address) AS token_address,
+    MIN(rowid) AS keep_rowid,
+    SUM(CAST(CASE WHEN length(amount) > 15 THEN substr(amount, 1, length(amount) - 15) ELSE '0' END AS INTEGER)) AS high,
+    SUM(CAST(substr(amount, -15) AS INTEGER)) AS low
+FROM holdings
+GROUP BY portfolio_id, lower(token_address)
+HAVING COUNT(*) > 1;
+
+UPDATE holding_ledger
+SET holding_id = (
+    SELECT h.id FROM holdings h JOIN holding_merges m ON h.rowid = m.keep_rowid
+    WHERE m.portfolio_id = holding_ledger.portfolio_id AND m.token_address = lower(holding_ledger.token_address)
+)
+WHERE EXISTS (
+    SELECT 1 FROM holding_merges m
+    WHERE m.portfolio_id = holding_ledger.portfolio_id AND m.token_address = lower(holding_ledger.token_address)
+);
+
+UPDATE holdings
+SET
+    amount = (
+        SELECT CASE
+            WHEN m.high + m.low / 1000000000000000 > 0
+                THEN printf('%d%015d', m.high + m.low / 1000000000000000, m.low % 1000000000000000)
+            ELSE printf('%d', m.low)
+        END
+        FROM holding_merges m WHERE m.keep_rowid = holdings.rowid
+    ),
+    version = version + 1
+WHERE rowid IN (SELECT keep_rowid FROM holding_merges);
+
+DELETE FROM holdings
+WHERE rowid NOT IN (SELECT keep_rowid FROM holding_merges)
+  AND EXISTS (
+    SELECT 1 FROM holding_merges m
+    WHERE m.portfolio_id = holdings.portfolio_id AND m.token_address = lower(holdings.token_address)
+  );
+
+DROP TABLE holding_merges;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_holdings_portfolio_token ON holdings(portfolio_id, lower(token_address));
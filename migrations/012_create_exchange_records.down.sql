@@ -0,0 +1,6 @@
+-- Migration: Drop exchange records
+-- Rollback: Exchange statement imports
+
+DROP INDEX IF EXISTS idx_exchange_records_portfolio_id;
+DROP INDEX IF EXISTS idx_exchange_records_external_id;
+DROP TABLE IF EXISTS exchange_records;
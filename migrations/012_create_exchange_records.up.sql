@@ -0,0 +1,25 @@
+-- Migration: Create exchange records
+-- Created: Exchange statement imports normalized into trades, deposits, withdrawals and fees
+
+-- Balance changes on exchange accounts. amount is the signed change scaled by 10^18;
+-- price is an optional unit price scaled by 10^8 in currency.
+CREATE TABLE IF NOT EXISTS exchange_records (
+    id TEXT PRIMARY KEY,
+    portfolio_id TEXT NOT NULL,
+    exchange TEXT NOT NULL,
+    external_id TEXT NOT NULL,
+    trade_id TEXT NOT NULL DEFAULT '',
+    kind TEXT NOT NULL,
+    asset TEXT NOT NULL,
+    amount TEXT NOT NULL,
+    price TEXT,
+    currency TEXT NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    occurred_at DATETIME NOT NULL,
+    imported_at DATETIME NOT NULL,
+    FOREIGN KEY (portfolio_id) REFERENCES portfolios(id) ON DELETE CASCADE
+);
+
+-- Re-importing an overlapping statement skips rows that are already stored
+CREATE UNIQUE INDEX IF NOT EXISTS idx_exchange_records_external_id ON exchange_records(portfolio_id, exchange, external_id);
+CREATE INDEX IF NOT EXISTS idx_exchange_records_portfolio_id ON exchange_records(portfolio_id, occurred_at);
@@ -0,0 +1,5 @@
+-- Migration: Drop exchange connections
+-- Rollback: Read-only exchange API connections
+
+DROP INDEX IF EXISTS idx_exchange_connections_portfolio_exchange;
+DROP TABLE IF EXISTS exchange_connections;
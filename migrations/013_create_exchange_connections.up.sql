@@ -0,0 +1,23 @@
+-- Migration: Create exchange connections
+-- Created: Read-only exchange API credentials synced into portfolios in the background
+
+-- credentials holds the API key and secret sealed with EXCHANGE_CREDENTIALS_KEY;
+-- api_key_hint keeps the last characters of the key for display. cursor is a JSON
+-- object with the trade history position per market.
+CREATE TABLE IF NOT EXISTS exchange_connections (
+    id TEXT PRIMARY KEY,
+    portfolio_id TEXT NOT NULL,
+    exchange TEXT NOT NULL,
+    label TEXT NOT NULL DEFAULT '',
+    api_key_hint TEXT NOT NULL DEFAULT '',
+    credentials TEXT NOT NULL,
+    cursor TEXT NOT NULL DEFAULT '{}',
+    last_synced_at DATETIME,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    FOREIGN KEY (portfolio_id) REFERENCES portfolios(id) ON DELETE CASCADE
+);
+
+-- One account per exchange and portfolio, which its synced balances are matched with
+CREATE UNIQUE INDEX IF NOT EXISTS idx_exchange_connections_portfolio_exchange ON exchange_connections(portfolio_id, exchange);
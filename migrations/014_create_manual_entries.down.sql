@@ -0,0 +1,5 @@
+-- Migration: Drop manual entries
+-- Rollback: Manual off-chain assets and liabilities
+
+DROP INDEX IF EXISTS idx_manual_entries_portfolio_id;
+DROP TABLE IF EXISTS manual_entries;
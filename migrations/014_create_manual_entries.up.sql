@@ -0,0 +1,25 @@
+-- Migration: Create manual entries
+-- Created: Off-chain assets and liabilities maintained by hand, such as cash, custodian positions and loans
+
+-- value and unit_price are scaled by 10^8 in currency; quantity is scaled by 10^18.
+-- Which columns are set depends on valuation: fixed uses value, unit_price uses
+-- quantity and unit_price, market uses quantity of token_address at its market price.
+CREATE TABLE IF NOT EXISTS manual_entries (
+    id TEXT PRIMARY KEY,
+    portfolio_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    side TEXT NOT NULL,
+    category TEXT NOT NULL,
+    valuation TEXT NOT NULL,
+    currency TEXT NOT NULL DEFAULT '',
+    value TEXT,
+    quantity TEXT,
+    unit_price TEXT,
+    token_address TEXT NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    FOREIGN KEY (portfolio_id) REFERENCES portfolios(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_manual_entries_portfolio_id ON manual_entries(portfolio_id);
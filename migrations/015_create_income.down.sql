@@ -0,0 +1,6 @@
+-- Migration: Drop income classifications and accruals
+-- Rollback: Income categories and rebasing token accruals
+
+DROP INDEX IF EXISTS idx_income_accruals_portfolio_id;
+DROP TABLE IF EXISTS income_accruals;
+DROP TABLE IF EXISTS income_classifications;
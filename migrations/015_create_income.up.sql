@@ -0,0 +1,28 @@
+-- Migration: Create income classifications and accruals
+-- Created: Income categories set by hand on transactions and rebasing token growth observed as income
+
+-- transaction_id is the ID of an on-chain transfer or an exchange statement row
+CREATE TABLE IF NOT EXISTS income_classifications (
+    portfolio_id TEXT NOT NULL,
+    transaction_id TEXT NOT NULL,
+    category TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    updated_at DATETIME NOT NULL,
+    PRIMARY KEY (portfolio_id, transaction_id),
+    FOREIGN KEY (portfolio_id) REFERENCES portfolios(id) ON DELETE CASCADE
+);
+
+-- quantity is in base units of the token
+CREATE TABLE IF NOT EXISTS income_accruals (
+    id TEXT PRIMARY KEY,
+    portfolio_id TEXT NOT NULL,
+    token_address TEXT NOT NULL,
+    symbol TEXT NOT NULL DEFAULT '',
+    decimals INTEGER NOT NULL,
+    quantity TEXT NOT NULL,
+    category TEXT NOT NULL,
+    observed_at DATETIME NOT NULL,
+    FOREIGN KEY (portfolio_id) REFERENCES portfolios(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_income_accruals_portfolio_id ON income_accruals(portfolio_id, token_address);
@@ -0,0 +1,6 @@
+-- Migration: Drop transaction annotations and manual transactions
+-- Rollback: Transaction annotations and manual transactions
+
+DROP INDEX IF EXISTS idx_manual_transactions_portfolio_id;
+DROP TABLE IF EXISTS manual_transactions;
+DROP TABLE IF EXISTS transaction_annotations;
@@ -0,0 +1,37 @@
+-- Migration: Create transaction annotations and manual transactions
+-- Created: Notes, tags, categories and type overrides kept apart from provider data, and transactions entered by hand
+
+-- transaction_id is the ID of an on-chain transfer, an exchange statement row or a manual transaction;
+-- tags is a JSON array of lowercase tags
+CREATE TABLE IF NOT EXISTS transaction_annotations (
+    portfolio_id TEXT NOT NULL,
+    transaction_id TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    tags TEXT NOT NULL DEFAULT '[]',
+    category TEXT NOT NULL DEFAULT '',
+    type_override TEXT NOT NULL DEFAULT '',
+    updated_at DATETIME NOT NULL,
+    PRIMARY KEY (portfolio_id, transaction_id),
+    FOREIGN KEY (portfolio_id) REFERENCES portfolios(id) ON DELETE CASCADE
+);
+
+-- amount is in base units of the token; price is scaled like statement prices
+CREATE TABLE IF NOT EXISTS manual_transactions (
+    id TEXT PRIMARY KEY,
+    portfolio_id TEXT NOT NULL,
+    token_address TEXT NOT NULL,
+    token_symbol TEXT NOT NULL DEFAULT '',
+    token_decimal INTEGER NOT NULL,
+    amount TEXT NOT NULL,
+    type TEXT NOT NULL,
+    direction TEXT NOT NULL,
+    from_address TEXT NOT NULL DEFAULT '',
+    to_address TEXT NOT NULL DEFAULT '',
+    price TEXT NOT NULL DEFAULT '',
+    price_currency TEXT NOT NULL DEFAULT '',
+    timestamp DATETIME NOT NULL,
+    created_at DATETIME NOT NULL,
+    FOREIGN KEY (portfolio_id) REFERENCES portfolios(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_manual_transactions_portfolio_id ON manual_transactions(portfolio_id, timestamp);
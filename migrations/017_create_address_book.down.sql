@@ -0,0 +1,4 @@
+-- Migration: Drop per-user address book
+-- Rollback: Per-user address book
+
+DROP TABLE IF EXISTS address_book;
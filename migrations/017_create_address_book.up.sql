@@ -0,0 +1,15 @@
+-- Migration: Create per-user address book
+-- Created: Labels users give to counterparty addresses
+
+-- Addresses are stored lowercase; a user labels each address once
+CREATE TABLE IF NOT EXISTS address_book (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    address TEXT NOT NULL,
+    label TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    UNIQUE (user_id, address),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,30 @@
+-- Migration: Make portfolio addresses globally unique again
+-- Rollback: Authentication and per-user portfolio ownership; fails while two owners track the same address
+
+PRAGMA foreign_keys = OFF;
+
+BEGIN;
+
+CREATE TABLE portfolios_old (
+    id TEXT PRIMARY KEY,
+    address TEXT UNIQUE NOT NULL,
+    updated_at DATETIME NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    tags TEXT NOT NULL DEFAULT '[]',
+    archived INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME,
+    owner_id TEXT REFERENCES users(id)
+);
+
+INSERT INTO portfolios_old (id, address, updated_at, name, description, tags, archived, created_at, owner_id)
+SELECT id, address, updated_at, name, description, tags, archived, created_at, owner_id FROM portfolios;
+
+DROP TABLE portfolios;
+ALTER TABLE portfolios_old RENAME TO portfolios;
+
+CREATE INDEX IF NOT EXISTS idx_portfolios_address ON portfolios(address);
+CREATE INDEX IF NOT EXISTS idx_portfolios_archived ON portfolios(archived, updated_at);
+CREATE INDEX IF NOT EXISTS idx_portfolios_owner_id ON portfolios(owner_id);
+
+COMMIT;
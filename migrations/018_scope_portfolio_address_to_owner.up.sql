@@ -0,0 +1,33 @@
+-- Migration: Make portfolio addresses unique per owner instead of globally
+-- Created: Authentication and per-user portfolio ownership
+
+-- SQLite cannot drop a column constraint, so the table is rebuilt without it.
+-- Foreign keys must be off so dropping the old table does not cascade to holdings.
+PRAGMA foreign_keys = OFF;
+
+BEGIN;
+
+CREATE TABLE portfolios_new (
+    id TEXT PRIMARY KEY,
+    address TEXT NOT NULL,
+    updated_at DATETIME NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    tags TEXT NOT NULL DEFAULT '[]',
+    archived INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME,
+    owner_id TEXT REFERENCES users(id)
+);
+
+INSERT INTO portfolios_new (id, address, updated_at, name, description, tags, archived, created_at, owner_id)
+SELECT id, address, updated_at, name, description, tags, archived, created_at, owner_id FROM portfolios;
+
+DROP TABLE portfolios;
+ALTER TABLE portfolios_new RENAME TO portfolios;
+
+CREATE INDEX IF NOT EXISTS idx_portfolios_address ON portfolios(address);
+CREATE INDEX IF NOT EXISTS idx_portfolios_archived ON portfolios(archived, updated_at);
+CREATE INDEX IF NOT EXISTS idx_portfolios_owner_id ON portfolios(owner_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_portfolios_owner_address ON portfolios(owner_id, address);
+
+COMMIT;
@@ -0,0 +1,4 @@
+-- Migration: Remove the creator of custom tokens
+-- Rollback: Portfolio sharing with roles
+
+ALTER TABLE custom_tokens DROP COLUMN owner_id;
@@ -0,0 +1,5 @@
+-- Migration: Record the creator of custom tokens
+-- Created: Portfolio sharing with roles; global tokens are managed by their creator or an admin
+
+-- Tokens created before this migration have no owner; only admins manage global ones
+ALTER TABLE custom_tokens ADD COLUMN owner_id TEXT REFERENCES users(id);
@@ -0,0 +1,6 @@
+-- Migration: Make custom token addresses unique across portfolios and chains again
+-- Rollback: Scope custom token addresses to chain and portfolio; fails while an address is defined more than once
+
+DROP INDEX IF EXISTS idx_custom_tokens_chain_address_global;
+DROP INDEX IF EXISTS idx_custom_tokens_chain_address_portfolio;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_custom_tokens_address ON custom_tokens(address);
@@ -0,0 +1,9 @@
+-- Migration: Scope custom token addresses to chain and portfolio
+-- Created: A contract address was unique across all tenants and chains, so one portfolio's token blocked everyone else
+
+DROP INDEX IF EXISTS idx_custom_tokens_address;
+
+-- An address is defined once per chain in each portfolio; NULL portfolio_ids never collide here
+CREATE UNIQUE INDEX IF NOT EXISTS idx_custom_tokens_chain_address_portfolio ON custom_tokens(chain_id, lower(address), portfolio_id);
+-- ... and once per chain globally
+CREATE UNIQUE INDEX IF NOT EXISTS idx_custom_tokens_chain_address_global ON custom_tokens(chain_id, lower(address)) WHERE portfolio_id IS NULL;